@@ -0,0 +1,189 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/nacos_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/http_agent"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_request"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_response"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// upstreamClient is the shared client of agent keeping the configs in memory
+type upstreamClient struct {
+	config_client.IConfigClient
+	mutex     sync.Mutex
+	contents  map[string]string
+	listeners map[string]func(namespace, group, dataId, data string)
+	listens   int
+	published []vo.ConfigParam
+}
+
+func newUpstreamClient() *upstreamClient {
+	return &upstreamClient{contents: map[string]string{}, listeners: map[string]func(namespace, group, dataId, data string){}}
+}
+
+func (c *upstreamClient) GetConfig(param vo.ConfigParam) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.contents[param.DataId], nil
+}
+
+func (c *upstreamClient) ListenConfig(param vo.ConfigParam) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.listens++
+	c.listeners[param.DataId] = param.OnChange
+	return nil
+}
+
+func (c *upstreamClient) CancelListenConfig(param vo.ConfigParam) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.listeners, param.DataId)
+	return nil
+}
+
+func (c *upstreamClient) PublishConfig(param vo.ConfigParam) (bool, error) {
+	c.mutex.Lock()
+	c.published = append(c.published, param)
+	c.mutex.Unlock()
+	c.change(param.DataId, param.Content)
+	return true, nil
+}
+
+func (c *upstreamClient) change(dataId, content string) {
+	c.mutex.Lock()
+	c.contents[dataId] = content
+	onChange := c.listeners[dataId]
+	c.mutex.Unlock()
+	if onChange != nil {
+		onChange("", "group", dataId, content)
+	}
+}
+
+func startAgent(t *testing.T, upstream *upstreamClient) (*Server, string) {
+	dir, err := os.MkdirTemp("", "agent")
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+	socketPath := filepath.Join(dir, "agent.sock")
+	server := NewServer(upstream, time.Minute)
+	go func() {
+		_ = server.ListenAndServe(socketPath)
+	}()
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(socketPath)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	t.Cleanup(func() {
+		_ = server.Close()
+	})
+	return server, socketPath
+}
+
+func TestAgent_MultiplexListen(t *testing.T) {
+	upstream := newUpstreamClient()
+	upstream.contents["app.yaml"] = "v1"
+	_, socketPath := startAgent(t, upstream)
+	proxy, err := NewConfigProxyFactory(socketPath)(context.Background(), nil, constant.ClientConfig{TimeoutMs: 3000}, nil)
+	assert.Nil(t, err)
+
+	response, err := proxy.QueryConfig("app.yaml", "group", "", 3000, false, nil)
+	assert.Nil(t, err)
+	assert.True(t, response.IsSuccess())
+	assert.Equal(t, "v1", response.Content)
+	response, err = proxy.QueryConfig("missing.yaml", "group", "", 3000, false, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, constant.CONFIG_NOT_FOUND, response.GetErrorCode())
+
+	// the processes listening the same config share one listen on server
+	listen := func() <-chan []model.ConfigContext {
+		result := make(chan []model.ConfigContext, 1)
+		go func() {
+			request := rpc_request.NewConfigBatchListenRequest(1)
+			request.ConfigListenContexts = append(request.ConfigListenContexts,
+				model.ConfigListenContext{DataId: "app.yaml", Group: "group", Md5: util.Md5("v1")})
+			response, err := proxy.RequestProxy(nil, request, 3000)
+			assert.Nil(t, err)
+			result <- response.(*rpc_response.ConfigChangeBatchListenResponse).ChangedConfigs
+		}()
+		return result
+	}
+	first, second := listen(), listen()
+	assert.Eventually(t, func() bool {
+		upstream.mutex.Lock()
+		defer upstream.mutex.Unlock()
+		return upstream.listeners["app.yaml"] != nil
+	}, time.Second, 10*time.Millisecond)
+	upstream.change("app.yaml", "v2")
+	for _, result := range []<-chan []model.ConfigContext{first, second} {
+		select {
+		case changed := <-result:
+			assert.Equal(t, []model.ConfigContext{{DataId: "app.yaml", Group: "group"}}, changed)
+		case <-time.After(2 * time.Second):
+			t.Fatal("listen is not responded on change")
+		}
+	}
+	assert.Equal(t, 1, upstream.listens)
+
+	// the listened config is served from agent
+	response, err = proxy.QueryConfig("app.yaml", "group", "", 3000, false, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "v2", response.Content)
+}
+
+func TestAgent_ConfigClient(t *testing.T) {
+	upstream := newUpstreamClient()
+	upstream.contents["app.yaml"] = "v1"
+	_, socketPath := startAgent(t, upstream)
+
+	nc := nacos_client.NacosClient{}
+	_ = nc.SetServerConfig([]constant.ServerConfig{*constant.NewServerConfig("127.0.0.1", 8848)})
+	_ = nc.SetClientConfig(*constant.NewClientConfig(constant.WithNotLoadCacheAtStart(true), constant.WithCacheDir(os.TempDir())))
+	_ = nc.SetHttpAgent(&http_agent.HttpAgent{})
+	client, err := config_client.NewConfigClientWithProxy(&nc, NewConfigProxyFactory(socketPath))
+	assert.Nil(t, err)
+	defer client.CloseClient()
+
+	content, err := client.GetConfig(vo.ConfigParam{DataId: "app.yaml", Group: "group"})
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", content)
+
+	published, err := client.PublishConfig(vo.ConfigParam{DataId: "app.yaml", Group: "group", Content: "v2", Type: "yaml"})
+	assert.Nil(t, err)
+	assert.True(t, published)
+	assert.Equal(t, "yaml", upstream.published[0].Type)
+	content, err = client.GetConfig(vo.ConfigParam{DataId: "app.yaml", Group: "group"})
+	assert.Nil(t, err)
+	assert.Equal(t, "v2", content)
+}
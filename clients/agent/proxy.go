@@ -0,0 +1,168 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/http_agent"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_request"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_response"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// the host of the urls sent over the unix socket, it's not resolved
+const agentHost = "http://agent"
+
+// NewConfigProxyFactory return the transport of ConfigClient talking to the agent on the unix socket, use it with
+// clients.NewConfigClientWithProxy. The server configs are ignored, the agent connects to server on behalf of clients
+func NewConfigProxyFactory(socketPath string) config_client.ConfigProxyFactory {
+	return func(ctx context.Context, serverConfig []constant.ServerConfig, clientConfig constant.ClientConfig,
+		httpAgent http_agent.IHttpAgent) (config_client.IConfigProxy, error) {
+		dialer := net.Dialer{}
+		return &configProxy{
+			timeout: time.Duration(clientConfig.TimeoutMs) * time.Millisecond,
+			httpClient: &http.Client{Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			}},
+		}, nil
+	}
+}
+
+// configProxy is the transport to the agent, it has no rpc client
+type configProxy struct {
+	httpClient *http.Client
+	timeout    time.Duration
+}
+
+func (p *configProxy) QueryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *config_client.ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+	if group == "" {
+		group = constant.DEFAULT_GROUP
+	}
+	response := &rpc_response.ConfigQueryResponse{}
+	err := p.call(http.MethodGet, pathConfig, configQuery(dataId, group, tenant), nil, time.Duration(timeout)*time.Millisecond, response)
+	if err != nil {
+		return nil, err
+	}
+	if response.Response == nil {
+		return nil, errors.New("[agent] ConfigQueryRequest returns empty response")
+	}
+	return response, nil
+}
+
+func (p *configProxy) SearchConfigProxy(param vo.SearchConfigParam, tenant, accessKey, secretKey string) (*model.ConfigPage, error) {
+	page := &model.ConfigPage{}
+	err := p.call(http.MethodPost, pathSearch, nil, searchRequest{Param: param, Tenant: tenant}, p.timeout, page)
+	return page, err
+}
+
+func (p *configProxy) RequestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	timeout := time.Duration(timeoutMills) * time.Millisecond
+	switch req := request.(type) {
+	case *rpc_request.ConfigPublishRequest:
+		response := &rpc_response.ConfigPublishResponse{}
+		if err := p.call(http.MethodPost, pathConfig, nil, req, timeout, response); err != nil {
+			return nil, err
+		}
+		return response, nil
+	case *rpc_request.ConfigRemoveRequest:
+		response := &rpc_response.ConfigRemoveResponse{}
+		if err := p.call(http.MethodDelete, pathConfig, configQuery(req.DataId, req.Group, req.Tenant), nil, timeout, response); err != nil {
+			return nil, err
+		}
+		return response, nil
+	case *rpc_request.ConfigBatchListenRequest:
+		response := &rpc_response.ConfigChangeBatchListenResponse{}
+		// the configs no longer listened are evicted by the agent when they are idle
+		if !req.Listen {
+			response.Response = &rpc_response.Response{ResultCode: constant.RESPONSE_CODE_SUCCESS, Success: true}
+			return response, nil
+		}
+		query := url.Values{"timeout": []string{strconv.FormatUint(timeoutMills, 10)}}
+		if err := p.call(http.MethodPost, pathListen, query, req.ConfigListenContexts, timeout, response); err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+	return nil, errors.Errorf("[agent] %s is not supported by agent", request.GetRequestType())
+}
+
+func (p *configProxy) CreateRpcClient(ctx context.Context, taskId string, client *config_client.ConfigClient) *rpc.RpcClient {
+	return nil
+}
+
+func (p *configProxy) GetRpcClient(client *config_client.ConfigClient) *rpc.RpcClient {
+	return nil
+}
+
+func configQuery(dataId, group, tenant string) url.Values {
+	return url.Values{"dataId": []string{dataId}, "group": []string{group}, "tenant": []string{tenant}}
+}
+
+// call send the request to agent and decode the json response into result
+func (p *configProxy) call(method, path string, query url.Values, body interface{}, timeout time.Duration, result interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	request, err := http.NewRequestWithContext(ctx, method, agentHost+path+"?"+query.Encode(), reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := p.httpClient.Do(request)
+	if err != nil {
+		return errors.Wrapf(err, "[agent] request %s failed", path)
+	}
+	defer response.Body.Close()
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return errors.Wrapf(err, "[agent] read response of %s failed", path)
+	}
+	if response.StatusCode != http.StatusOK {
+		return errors.Errorf("[agent] request %s failed, status:%d, body:%s", path, response.StatusCode, data)
+	}
+	return json.Unmarshal(data, result)
+}
@@ -0,0 +1,372 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package agent shares one config client among the processes on a host. The agent daemon serves the processes over
+// a unix socket, and multiplexes their listens of the same config into one listen on server, so the dense hosts
+// don't open a connection set and long-poll for every process. The processes create their config clients with
+// NewConfigProxyFactory to talk to the agent instead of server
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_request"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_response"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// The api paths served by the agent, the bodies are the json of rpc requests and responses
+const (
+	pathConfig = "/v1/cs/config"
+	pathListen = "/v1/cs/listen"
+	pathSearch = "/v1/cs/search"
+)
+
+const (
+	defaultIdleTimeout = 5 * time.Minute
+	// the margin left for the response of listen before the timeout of the process
+	listenTimeoutMargin = 500 * time.Millisecond
+	responseCodeFail    = 500
+)
+
+// Server is the agent serving the processes on a host with the shared config client
+type Server struct {
+	client      config_client.IConfigClient
+	idleTimeout time.Duration
+	httpServer  *http.Server
+
+	mutex   sync.Mutex
+	watches map[string]*watch
+	changed chan struct{} // closed and replaced when any watched config changed
+	closed  chan struct{}
+}
+
+// watch is a config listened on server on behalf of the processes
+type watch struct {
+	param    vo.ConfigParam
+	ready    chan struct{} // closed when the content is loaded
+	err      error
+	content  string
+	md5      string
+	lastUsed time.Time
+}
+
+// NewServer create the agent with the shared client. The configs not listened by any process for idleTimeout are
+// no longer listened on server, default value of idleTimeout is 5m
+func NewServer(client config_client.IConfigClient, idleTimeout time.Duration) *Server {
+	if idleTimeout <= 0 {
+		idleTimeout = defaultIdleTimeout
+	}
+	s := &Server{
+		client:      client,
+		idleTimeout: idleTimeout,
+		watches:     make(map[string]*watch),
+		changed:     make(chan struct{}),
+		closed:      make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(pathConfig, s.handleConfig)
+	mux.HandleFunc(pathListen, s.handleListen)
+	mux.HandleFunc(pathSearch, s.handleSearch)
+	s.httpServer = &http.Server{Handler: mux}
+	return s
+}
+
+// ListenAndServe serve the processes on the unix socket, the stale socket file left by the previous agent is removed
+func (s *Server) ListenAndServe(socketPath string) error {
+	if err := os.Remove(socketPath); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "[agent] remove stale socket %s failed", socketPath)
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return errors.Wrapf(err, "[agent] listen on %s failed", socketPath)
+	}
+	return s.Serve(listener)
+}
+
+// Serve serve the processes on listener until Close
+func (s *Server) Serve(listener net.Listener) error {
+	go s.evictIdle()
+	if err := s.httpServer.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Close stop serving and cancel the listens on server, the shared client is not closed
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	select {
+	case <-s.closed:
+	default:
+		close(s.closed)
+	}
+	watches := s.watches
+	s.watches = make(map[string]*watch)
+	s.mutex.Unlock()
+	for _, w := range watches {
+		_ = s.client.CancelListenConfig(w.param)
+	}
+	return s.httpServer.Close()
+}
+
+// watchOf return the loaded watch of the config, the config is listened on server on first use
+func (s *Server) watchOf(dataId, group, tenant string) (*watch, error) {
+	key := util.GetConfigCacheKey(dataId, group, tenant)
+	s.mutex.Lock()
+	w, ok := s.watches[key]
+	if !ok {
+		w = &watch{param: vo.ConfigParam{DataId: dataId, Group: group, NamespaceId: tenant}, ready: make(chan struct{})}
+		s.watches[key] = w
+	}
+	w.lastUsed = time.Now()
+	s.mutex.Unlock()
+	if ok {
+		<-w.ready
+		return w, w.err
+	}
+
+	content, err := s.client.GetConfig(w.param)
+	if err == nil {
+		param := w.param
+		param.OnChange = func(namespace, group, dataId, data string) {
+			s.update(key, data)
+		}
+		err = s.client.ListenConfig(param)
+	}
+	s.mutex.Lock()
+	if err != nil {
+		w.err = err
+		delete(s.watches, key)
+	} else {
+		w.content, w.md5 = content, util.Md5(content)
+	}
+	s.mutex.Unlock()
+	close(w.ready)
+	return w, err
+}
+
+func (s *Server) update(key, content string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	w, ok := s.watches[key]
+	if !ok || w.md5 == util.Md5(content) {
+		return
+	}
+	w.content, w.md5 = content, util.Md5(content)
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// changedConfigs return the configs of which the md5 differs from the process, and the channel closed on next change
+func (s *Server) changedConfigs(contexts []model.ConfigListenContext) ([]model.ConfigContext, <-chan struct{}) {
+	changed := make([]model.ConfigContext, 0)
+	for _, c := range contexts {
+		w, err := s.watchOf(c.DataId, c.Group, c.Tenant)
+		if err != nil {
+			logger.Warnf("[agent] listen config dataId:%s group:%s tenant:%s failed, err:%v", c.DataId, c.Group, c.Tenant, err)
+			continue
+		}
+		s.mutex.Lock()
+		if w.md5 != c.Md5 {
+			changed = append(changed, model.ConfigContext{DataId: c.DataId, Group: c.Group, Tenant: c.Tenant})
+		}
+		s.mutex.Unlock()
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return changed, s.changed
+}
+
+func (s *Server) evictIdle() {
+	ticker := time.NewTicker(s.idleTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.closed:
+			return
+		case <-ticker.C:
+		}
+		var idle []vo.ConfigParam
+		s.mutex.Lock()
+		for key, w := range s.watches {
+			if time.Since(w.lastUsed) >= s.idleTimeout && isClosed(w.ready) {
+				idle = append(idle, w.param)
+				delete(s.watches, key)
+			}
+		}
+		s.mutex.Unlock()
+		for _, param := range idle {
+			logger.Infof("[agent] cancel idle listen of config dataId:%s group:%s tenant:%s", param.DataId, param.Group, param.NamespaceId)
+			_ = s.client.CancelListenConfig(param)
+		}
+	}
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	switch r.Method {
+	case http.MethodGet:
+		s.queryConfig(w, query.Get("dataId"), query.Get("group"), query.Get("tenant"))
+	case http.MethodPost:
+		request := &rpc_request.ConfigPublishRequest{}
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		published, err := s.client.PublishConfig(publishParam(request))
+		writeResponse(w, &rpc_response.ConfigPublishResponse{Response: newResponse(published, err)})
+	case http.MethodDelete:
+		deleted, err := s.client.DeleteConfig(vo.ConfigParam{DataId: query.Get("dataId"), Group: query.Get("group"), NamespaceId: query.Get("tenant")})
+		writeResponse(w, &rpc_response.ConfigRemoveResponse{Response: newResponse(deleted, err)})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// queryConfig serve the config from the watch if it's listened, otherwise from the shared client
+func (s *Server) queryConfig(w http.ResponseWriter, dataId, group, tenant string) {
+	var content string
+	s.mutex.Lock()
+	watch, ok := s.watches[util.GetConfigCacheKey(dataId, group, tenant)]
+	if ok && isClosed(watch.ready) && watch.err == nil {
+		content = watch.content
+	}
+	s.mutex.Unlock()
+	if !ok || len(content) == 0 {
+		var err error
+		if content, err = s.client.GetConfig(vo.ConfigParam{DataId: dataId, Group: group, NamespaceId: tenant}); err != nil {
+			writeResponse(w, &rpc_response.ConfigQueryResponse{Response: newResponse(false, err)})
+			return
+		}
+	}
+	response := &rpc_response.ConfigQueryResponse{Response: newResponse(true, nil), Content: content, Md5: util.Md5(content)}
+	// the content of config is never empty on server, so it's regarded as not found
+	if len(content) == 0 {
+		response.Response = &rpc_response.Response{ResultCode: responseCodeFail, ErrorCode: constant.CONFIG_NOT_FOUND, Message: "config not found"}
+	}
+	writeResponse(w, response)
+}
+
+// handleListen respond the changed configs, it holds the request until any config changed or the timeout of request
+func (s *Server) handleListen(w http.ResponseWriter, r *http.Request) {
+	var contexts []model.ConfigListenContext
+	if err := json.NewDecoder(r.Body).Decode(&contexts); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	timeoutMills, _ := strconv.ParseInt(r.URL.Query().Get("timeout"), 10, 64)
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(timeoutMills)*time.Millisecond-listenTimeoutMargin)
+	defer cancel()
+	for {
+		changed, next := s.changedConfigs(contexts)
+		if len(changed) > 0 {
+			writeResponse(w, &rpc_response.ConfigChangeBatchListenResponse{Response: newResponse(true, nil), ChangedConfigs: changed})
+			return
+		}
+		select {
+		case <-next:
+		case <-ctx.Done():
+			writeResponse(w, &rpc_response.ConfigChangeBatchListenResponse{Response: newResponse(true, nil), ChangedConfigs: changed})
+			return
+		case <-s.closed:
+			http.Error(w, "agent closed", http.StatusServiceUnavailable)
+			return
+		}
+	}
+}
+
+type searchRequest struct {
+	Param  vo.SearchConfigParam `json:"param"`
+	Tenant string               `json:"tenant"`
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	request := searchRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	request.Param.NamespaceId = request.Tenant
+	page, err := s.client.SearchConfig(request.Param)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeResponse(w, page)
+}
+
+// publishParam restore the param of the publish request built by ConfigClient
+func publishParam(request *rpc_request.ConfigPublishRequest) vo.ConfigParam {
+	return vo.ConfigParam{
+		DataId:           request.DataId,
+		Group:            request.Group,
+		NamespaceId:      request.Tenant,
+		Content:          request.Content,
+		CasMd5:           request.CasMd5,
+		Tag:              request.AdditionMap["tag"],
+		AppName:          request.AdditionMap["appName"],
+		BetaIps:          request.AdditionMap["betaIps"],
+		Type:             request.AdditionMap["type"],
+		SrcUser:          request.AdditionMap["src_user"],
+		EncryptedDataKey: request.AdditionMap["encryptedDataKey"],
+		Desc:             request.AdditionMap["desc"],
+		Use:              request.AdditionMap["use"],
+		Effect:           request.AdditionMap["effect"],
+		Schema:           request.AdditionMap["schema"],
+		ConfigTags:       request.AdditionMap["config_tags"],
+	}
+}
+
+func newResponse(success bool, err error) *rpc_response.Response {
+	if err != nil {
+		return &rpc_response.Response{ResultCode: responseCodeFail, ErrorCode: responseCodeFail, Message: err.Error()}
+	}
+	if !success {
+		return &rpc_response.Response{ResultCode: responseCodeFail, ErrorCode: responseCodeFail, Message: "refused by server"}
+	}
+	return &rpc_response.Response{ResultCode: constant.RESPONSE_CODE_SUCCESS, Success: true}
+}
+
+func writeResponse(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Warnf("[agent] write response failed, err:%v", err)
+	}
+}
@@ -92,6 +92,23 @@ func (m ConcurrentMap) Upsert(key string, value interface{}, cb UpsertCb) (res i
 	return res
 }
 
+// Callback to return the element replacing the existing one
+// It is called while lock is held, the same restriction as UpsertCb applies
+type UpdateCb func(valueInMap interface{}) interface{}
+
+// Updates the existing element using UpdateCb, nothing is inserted if the key is absent.
+// Returns whether the element existed
+func (m ConcurrentMap) Update(key string, cb UpdateCb) bool {
+	shard := m.GetShard(key)
+	shard.Lock()
+	defer shard.Unlock()
+	v, ok := shard.items[key]
+	if ok {
+		shard.items[key] = cb(v)
+	}
+	return ok
+}
+
 // Sets the given value under the specified key if no value was associated with it.
 func (m ConcurrentMap) SetIfAbsent(key string, value interface{}) bool {
 	// Get map shard.
@@ -148,6 +165,24 @@ func (m ConcurrentMap) Remove(key string) {
 	shard.Unlock()
 }
 
+// Callback to decide whether the element is removed
+// It is called while lock is held, the same restriction as UpsertCb applies
+type RemoveCb func(key string, v interface{}, exists bool) bool
+
+// Removes the element if RemoveCb returns true, the callback is called even if the key is absent.
+// Returns the value returned by the callback
+func (m ConcurrentMap) RemoveCb(key string, cb RemoveCb) bool {
+	shard := m.GetShard(key)
+	shard.Lock()
+	defer shard.Unlock()
+	v, ok := shard.items[key]
+	remove := cb(key, v, ok)
+	if remove && ok {
+		delete(shard.items, key)
+	}
+	return remove
+}
+
 // Removes an element from the map and returns it
 func (m ConcurrentMap) Pop(key string) (v interface{}, exists bool) {
 	// Try to get shard.
@@ -296,7 +331,7 @@ func (m ConcurrentMap) Keys() []string {
 	return keys
 }
 
-//Reviles ConcurrentMap "private" variables to json marshal.
+// Reviles ConcurrentMap "private" variables to json marshal.
 func (m ConcurrentMap) MarshalJSON() ([]byte, error) {
 	// Create a temporary map, which will hold all item spread across shards.
 	tmp := make(map[string]interface{})
@@ -0,0 +1,126 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/file"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/pkg/errors"
+)
+
+const configHistoryDir = "history"
+
+func getConfigHistoryDir(cacheKey string, cacheDir string) string {
+	return cacheDir + string(os.PathSeparator) + configHistoryDir + string(os.PathSeparator) + cacheKey
+}
+
+// WriteConfigHistory save the content as a new historical snapshot named by the unix nano timestamp,
+// nothing is saved when the content is the same as the latest snapshot. Only the latest maxSize snapshots are kept.
+// The snapshots are created with the secret file mode, the past contents may have held the secrets since rotated
+func WriteConfigHistory(cacheKey string, cacheDir string, content string, maxSize int) {
+	if maxSize <= 0 {
+		return
+	}
+	dir := getConfigHistoryDir(cacheKey, cacheDir)
+	if err := file.MkdirIfNecessary(dir); err != nil {
+		logger.Errorf("mkdir config history dir failed,dir:%s,err:%v", dir, err)
+		return
+	}
+	versions := listConfigHistoryVersions(dir)
+	if len(versions) > 0 {
+		if latest, err := ioutil.ReadFile(GetFileName(strconv.FormatInt(versions[len(versions)-1], 10), dir)); err == nil &&
+			string(latest) == content {
+			return
+		}
+	}
+	version := time.Now().UnixNano()
+	if len(versions) > 0 && version <= versions[len(versions)-1] {
+		version = versions[len(versions)-1] + 1
+	}
+	fileName := GetFileName(strconv.FormatInt(version, 10), dir)
+	if err := file.WriteFile(fileName, []byte(content), file.FileMode(true)); err != nil {
+		logger.Errorf("failed to write config history:%s ,err:%v", fileName, err)
+		return
+	}
+	versions = append(versions, version)
+	for len(versions) > maxSize {
+		if err := os.Remove(GetFileName(strconv.FormatInt(versions[0], 10), dir)); err != nil {
+			logger.Errorf("failed to delete config history:%d of %s ,err:%v", versions[0], cacheKey, err)
+		}
+		versions = versions[1:]
+	}
+}
+
+// ListConfigHistory list historical snapshots of the config, the oldest is the first one
+func ListConfigHistory(cacheKey string, cacheDir string) ([]model.ConfigSnapshot, error) {
+	dir := getConfigHistoryDir(cacheKey, cacheDir)
+	var snapshots []model.ConfigSnapshot
+	for _, version := range listConfigHistoryVersions(dir) {
+		b, err := ioutil.ReadFile(GetFileName(strconv.FormatInt(version, 10), dir))
+		if err != nil {
+			return nil, errors.Wrapf(err, "read config history %d of %s failed", version, cacheKey)
+		}
+		snapshots = append(snapshots, model.ConfigSnapshot{
+			Time: time.Unix(0, version),
+			Md5:  util.Md5(string(b)),
+			Size: len(b),
+		})
+	}
+	return snapshots, nil
+}
+
+// ReadConfigHistory read the snapshot which was in effect at the given time
+func ReadConfigHistory(cacheKey string, cacheDir string, at time.Time) (string, error) {
+	dir := getConfigHistoryDir(cacheKey, cacheDir)
+	versions := listConfigHistoryVersions(dir)
+	i := sort.Search(len(versions), func(i int) bool {
+		return versions[i] > at.UnixNano()
+	})
+	if i == 0 {
+		return "", errors.Errorf("no config history of %s found before %s", cacheKey, at.Format(time.RFC3339))
+	}
+	b, err := ioutil.ReadFile(GetFileName(strconv.FormatInt(versions[i-1], 10), dir))
+	if err != nil {
+		return "", errors.Wrapf(err, "read config history %d of %s failed", versions[i-1], cacheKey)
+	}
+	return string(b), nil
+}
+
+func listConfigHistoryVersions(dir string) []int64 {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var versions []int64
+	for _, f := range files {
+		if version, err := strconv.ParseInt(f.Name(), 10, 64); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i] < versions[j]
+	})
+	return versions
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/file"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nacos-history")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	cacheKey := util.GetConfigCacheKey("dataId", "group", "tenant")
+
+	WriteConfigHistory(cacheKey, dir, "v1", 2)
+	WriteConfigHistory(cacheKey, dir, "v1", 2)
+	snapshots, err := ListConfigHistory(cacheKey, dir)
+	assert.Nil(t, err)
+	assert.Len(t, snapshots, 1)
+	info, err := os.Stat(GetFileName(strconv.FormatInt(snapshots[0].Time.UnixNano(), 10), getConfigHistoryDir(cacheKey, dir)))
+	assert.Nil(t, err)
+	assert.Equal(t, file.DefaultSecretFileMode, info.Mode().Perm())
+	afterV1 := time.Now()
+
+	WriteConfigHistory(cacheKey, dir, "v2", 2)
+	WriteConfigHistory(cacheKey, dir, "v3", 2)
+	snapshots, err = ListConfigHistory(cacheKey, dir)
+	assert.Nil(t, err)
+	assert.Len(t, snapshots, 2)
+	assert.Equal(t, util.Md5("v3"), snapshots[1].Md5)
+
+	content, err := ReadConfigHistory(cacheKey, dir, time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, "v3", content)
+	// v1 is pruned
+	_, err = ReadConfigHistory(cacheKey, dir, afterV1)
+	assert.Error(t, err)
+}
@@ -18,9 +18,14 @@ package cache
 
 import (
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/file"
@@ -30,6 +35,13 @@ import (
 	"github.com/pkg/errors"
 )
 
+const quarantineDir = ".quarantine"
+
+// isSecretCacheKey tell whether the snapshot is of an encrypted config, which is written with the secret file mode
+func isSecretCacheKey(cacheKey string) bool {
+	return strings.HasPrefix(cacheKey, "cipher-")
+}
+
 func GetFileName(cacheKey string, cacheDir string) string {
 	return cacheDir + string(os.PathSeparator) + cacheKey
 }
@@ -42,7 +54,7 @@ func WriteServicesToFile(service *model.Service, cacheKey, cacheDir string) {
 	}
 	bytes, _ := json.Marshal(service)
 	domFileName := GetFileName(cacheKey, cacheDir)
-	err = ioutil.WriteFile(domFileName, bytes, 0666)
+	err = file.WriteFile(domFileName, bytes, file.FileMode(false))
 	if err != nil {
 		logger.Errorf("failed to write name cache:%s ,value:%s ,err:%v", domFileName, string(bytes), err)
 	}
@@ -56,6 +68,9 @@ func ReadServicesFromFile(cacheDir string) map[string]model.Service {
 	}
 	serviceMap := map[string]model.Service{}
 	for _, f := range files {
+		if !f.Mode().IsRegular() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
 		fileName := GetFileName(f.Name(), cacheDir)
 		b, err := ioutil.ReadFile(fileName)
 		if err != nil {
@@ -67,6 +82,7 @@ func ReadServicesFromFile(cacheDir string) map[string]model.Service {
 		service := util.JsonToService(s)
 
 		if service == nil {
+			QuarantineFile(cacheDir, f.Name(), "invalid service json")
 			continue
 		}
 		cacheKey := util.GetServiceCacheKey(util.GetGroupName(service.Name, service.GroupName), service.Clusters)
@@ -82,16 +98,105 @@ func WriteConfigToFile(cacheKey string, cacheDir string, content string) {
 	fileName := GetFileName(cacheKey, cacheDir)
 	if len(content) == 0 {
 		// delete config snapshot
-		if err := os.Remove(fileName); err != nil {
+		if err := os.Remove(fileName); err != nil && !os.IsNotExist(err) {
 			logger.Errorf("failed to delete config file,cache:%s ,value:%s ,err:%v", fileName, content, err)
 		}
 		return
 	}
-	err := ioutil.WriteFile(fileName, []byte(content), 0666)
+	if err := WriteConfigStreamToFile(cacheKey, cacheDir, strings.NewReader(content)); err != nil {
+		logger.Errorf("failed to write config  cache:%s ,value:%s ,err:%v", fileName, util.TruncateContent(content), err)
+	}
+}
+
+// WriteConfigStreamToFile write the content read from r as the config snapshot, the content is streamed to a
+// temporary file which is renamed afterwards, so a large content is not copied in memory and a partial snapshot is never read
+func WriteConfigStreamToFile(cacheKey string, cacheDir string, r io.Reader) error {
+	if err := file.MkdirIfNecessary(cacheDir); err != nil {
+		return errors.Wrapf(err, "mkdir cacheDir failed,cacheDir:%s", cacheDir)
+	}
+	fileName := GetFileName(cacheKey, cacheDir)
+	mode := file.FileMode(isSecretCacheKey(cacheKey))
+	tmp, err := createTempFile(fileName, mode)
 	if err != nil {
-		logger.Errorf("failed to write config  cache:%s ,value:%s ,err:%v", fileName, content, err)
+		return err
+	}
+	if _, err = io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
 	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = file.ApplyPerm(tmp.Name(), mode); err != nil {
+		logger.Warnf("failed to apply permission of config cache:%s ,err:%v", tmp.Name(), err)
+	}
+	return os.Rename(tmp.Name(), fileName)
+}
 
+var tempFileSeq uint64
+
+// createTempFile create the temporary file next to fileName, perm is masked by the umask like ioutil.WriteFile
+func createTempFile(fileName string, perm os.FileMode) (*os.File, error) {
+	for {
+		name := fileName + ".tmp" + strconv.FormatUint(atomic.AddUint64(&tempFileSeq, 1), 10)
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+		if os.IsExist(err) {
+			continue
+		}
+		return f, err
+	}
+}
+
+// partialSnapshotPattern matches the temporary files left by WriteConfigStreamToFile when the process is killed
+var partialSnapshotPattern = regexp.MustCompile(constant.CONFIG_INFO_SPLITER + `.*\.tmp\d+$`)
+
+// RepairConfigCache quarantine the partial and empty config snapshots of cacheDir, they are left by
+// interrupted writes and would otherwise be served as the config content. The quarantined names are returned
+func RepairConfigCache(cacheDir string) []string {
+	files, err := ioutil.ReadDir(cacheDir)
+	if err != nil {
+		return nil
+	}
+	var quarantined []string
+	for _, f := range files {
+		name := f.Name()
+		if !f.Mode().IsRegular() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, constant.FAILOVER_FILE_SUFFIX) {
+			continue
+		}
+		var reason string
+		switch {
+		case partialSnapshotPattern.MatchString(name):
+			reason = "partial snapshot"
+		case f.Size() == 0:
+			reason = "empty snapshot"
+		default:
+			continue
+		}
+		if QuarantineFile(cacheDir, name, reason) {
+			quarantined = append(quarantined, name)
+		}
+	}
+	return quarantined
+}
+
+// QuarantineFile move the corrupted file of cacheDir into <cacheDir>/.quarantine, it's kept for inspection
+// but no longer loaded. The file is removed when it can't be moved
+func QuarantineFile(cacheDir, name, reason string) bool {
+	fileName := GetFileName(name, cacheDir)
+	dir := GetFileName(quarantineDir, cacheDir)
+	target := GetFileName(name+"."+strconv.FormatInt(time.Now().UnixNano(), 10), dir)
+	err := file.MkdirIfNecessary(dir)
+	if err == nil {
+		err = os.Rename(fileName, target)
+	}
+	if err != nil {
+		logger.Warnf("cache file corrupted, file=%s, reason=%s, quarantine failed and removed, err=%v", fileName, reason, err)
+		return os.Remove(fileName) == nil
+	}
+	logger.Warnf("cache file corrupted, file=%s, reason=%s, quarantined to %s", fileName, reason, target)
+	return true
 }
 
 func ReadConfigFromFile(cacheKey string, cacheDir string) (string, error) {
@@ -104,6 +209,41 @@ func ReadConfigFromFile(cacheKey string, cacheDir string) (string, error) {
 	return string(b), nil
 }
 
+// OpenConfigCacheFile open the config snapshot of cacheKey to read it as a stream
+func OpenConfigCacheFile(cacheKey string, cacheDir string) (*os.File, error) {
+	fileName := GetFileName(cacheKey, cacheDir)
+	f, err := os.Open(fileName)
+	if err != nil {
+		logger.Errorf("open config cache, cacheKey:%s, cacheDir:%s, error:%v ", cacheKey, cacheDir, err)
+		return nil, errors.Errorf("failed to open config cache file:%s, cacheDir:%s, err:%v ", fileName, cacheDir, err)
+	}
+	return f, nil
+}
+
+// ConfigCacheModTime return the time when the config cache file of cacheKey was written
+func ConfigCacheModTime(cacheKey string, cacheDir string) (time.Time, error) {
+	info, err := os.Stat(GetFileName(cacheKey, cacheDir))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// OpenFailover open the failover file to read it as a stream, nil is returned when there is no failover content
+func OpenFailover(key, dir string) *os.File {
+	filePath := dir + string(os.PathSeparator) + key + constant.FAILOVER_FILE_SUFFIX
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil
+	}
+	if info, err := f.Stat(); err != nil || info.Size() == 0 {
+		_ = f.Close()
+		return nil
+	}
+	logger.Warnf("reading failover content from path:%s", filePath)
+	return f
+}
+
 // GetFailover , get failover content
 func GetFailover(key, dir string) string {
 	filePath := dir + string(os.PathSeparator) + key + constant.FAILOVER_FILE_SUFFIX
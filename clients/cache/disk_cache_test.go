@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,3 +37,61 @@ func TestGetFailover(t *testing.T) {
 func writeFileContent(filepath, content string) error {
 	return ioutil.WriteFile(filepath, []byte(content), 0666)
 }
+
+func TestWriteConfigStreamToFile(t *testing.T) {
+	dir := t.TempDir()
+	content := strings.Repeat("0123456789", 1024*100)
+	assert.Nil(t, WriteConfigStreamToFile("stream@@group@@", dir, strings.NewReader(content)))
+	cached, err := ReadConfigFromFile("stream@@group@@", dir)
+	assert.Nil(t, err)
+	assert.Equal(t, content, cached)
+	files, _ := ioutil.ReadDir(dir)
+	assert.Len(t, files, 1)
+
+	assert.Nil(t, OpenFailover("stream@@group@@", dir))
+	WriteConfigToFile("stream@@group@@", dir, "")
+	_, err = ReadConfigFromFile("stream@@group@@", dir)
+	assert.NotNil(t, err)
+}
+
+func TestRepairConfigCache(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, writeFileContent(GetFileName("ok.tmp@@group@@", dir), "content"))
+	assert.Nil(t, writeFileContent(GetFileName("partial@@group@@.tmp123456", dir), "cont"))
+	assert.Nil(t, writeFileContent(GetFileName("empty@@group@@", dir), ""))
+	assert.Nil(t, writeFileContent(GetFileName("empty@@group@@_failover", dir), ""))
+	assert.Nil(t, os.MkdirAll(GetFileName(subscriptionDir, dir), 0755))
+
+	quarantined := RepairConfigCache(dir)
+	assert.ElementsMatch(t, []string{"partial@@group@@.tmp123456", "empty@@group@@"}, quarantined)
+	content, err := ReadConfigFromFile("ok.tmp@@group@@", dir)
+	assert.Nil(t, err)
+	assert.Equal(t, "content", content)
+	files, _ := ioutil.ReadDir(GetFileName(quarantineDir, dir))
+	assert.Len(t, files, 2)
+	assert.Empty(t, RepairConfigCache(dir))
+}
+
+func TestReadServicesFromFile_Quarantine(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, writeFileContent(GetFileName("DEFAULT_GROUP@@demo", dir), `{"name":"DEFAULT_GROUP@@demo","hosts":[]}`))
+	assert.Nil(t, writeFileContent(GetFileName("DEFAULT_GROUP@@broken", dir), `{"name":"DEFAULT_GR`))
+
+	services := ReadServicesFromFile(dir)
+	assert.Len(t, services, 1)
+	files, _ := ioutil.ReadDir(GetFileName(quarantineDir, dir))
+	assert.Len(t, files, 1)
+	assert.Len(t, ReadServicesFromFile(dir), 1)
+}
+
+func TestWriteConfigStreamToFile_SecretMode(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, WriteConfigStreamToFile("cipher-db@@group@@", dir, strings.NewReader("password")))
+	assert.Nil(t, WriteConfigStreamToFile("db@@group@@", dir, strings.NewReader("url")))
+	info, err := os.Stat(GetFileName("cipher-db@@group@@", dir))
+	assert.Nil(t, err)
+	assert.Equal(t, file.DefaultSecretFileMode, info.Mode().Perm())
+	info, err = os.Stat(GetFileName("db@@group@@", dir))
+	assert.Nil(t, err)
+	assert.Equal(t, file.DefaultFileMode, info.Mode().Perm())
+}
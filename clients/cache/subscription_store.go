@@ -0,0 +1,82 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/file"
+	"github.com/pkg/errors"
+)
+
+const subscriptionDir = "subscriptions"
+
+// ConfigSubscription is a listened config persisted on disk
+type ConfigSubscription struct {
+	DataId      string `json:"dataId"`
+	Group       string `json:"group"`
+	NamespaceId string `json:"namespaceId,omitempty"`
+}
+
+// ServiceSubscription is a subscribed service persisted on disk
+type ServiceSubscription struct {
+	ServiceName string   `json:"serviceName"`
+	GroupName   string   `json:"groupName"`
+	Clusters    []string `json:"clusters,omitempty"`
+}
+
+func getSubscriptionFileName(cacheDir, namespace string) string {
+	if len(namespace) == 0 {
+		namespace = constant.DEFAULT_NAMESPACE_ID
+	}
+	return cacheDir + string(os.PathSeparator) + subscriptionDir + string(os.PathSeparator) + namespace + ".json"
+}
+
+// WriteSubscriptionsToFile replace the persisted subscriptions of the namespace,
+// the file is written to a temporary file first so a crash never leaves it half written
+func WriteSubscriptionsToFile(cacheDir, namespace string, subscriptions interface{}) error {
+	fileName := getSubscriptionFileName(cacheDir, namespace)
+	if err := file.MkdirIfNecessary(cacheDir + string(os.PathSeparator) + subscriptionDir); err != nil {
+		return errors.Wrapf(err, "mkdir subscription dir of %s failed", fileName)
+	}
+	b, err := json.Marshal(subscriptions)
+	if err != nil {
+		return err
+	}
+	tmpFileName := fileName + ".tmp"
+	if err = file.WriteFile(tmpFileName, b, file.FileMode(false)); err != nil {
+		return errors.Wrapf(err, "failed to write subscriptions:%s", fileName)
+	}
+	return errors.Wrapf(os.Rename(tmpFileName, fileName), "failed to write subscriptions:%s", fileName)
+}
+
+// ReadSubscriptionsFromFile read the persisted subscriptions of the namespace into subscriptions,
+// nothing is read when no subscription has been persisted
+func ReadSubscriptionsFromFile(cacheDir, namespace string, subscriptions interface{}) error {
+	fileName := getSubscriptionFileName(cacheDir, namespace)
+	b, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to read subscriptions:%s", fileName)
+	}
+	return errors.Wrapf(json.Unmarshal(b, subscriptions), "failed to parse subscriptions:%s", fileName)
+}
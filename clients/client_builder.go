@@ -0,0 +1,115 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clients
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/http_agent"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_server"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// NacosClientBuilder builds the config and naming clients from one configuration, the clients share the http agent,
+// the server list refreshed from endpoint, the auth and identity tokens, the logger and the metrics registry
+type NacosClientBuilder struct {
+	param         vo.NacosClientParam
+	withoutConfig bool
+	withoutNaming bool
+}
+
+// NacosClients is the clients built by NacosClientBuilder, the client not built is nil
+type NacosClients struct {
+	Config config_client.IConfigClient
+	Naming naming_client.INamingClient
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+// NewNacosClientBuilder create the builder of the clients configured by param
+func NewNacosClientBuilder(param vo.NacosClientParam) *NacosClientBuilder {
+	return &NacosClientBuilder{param: param}
+}
+
+// WithoutConfig skip building the config client
+func (b *NacosClientBuilder) WithoutConfig() *NacosClientBuilder {
+	b.withoutConfig = true
+	return b
+}
+
+// WithoutNaming skip building the naming client
+func (b *NacosClientBuilder) WithoutNaming() *NacosClientBuilder {
+	b.withoutNaming = true
+	return b
+}
+
+// Build create the clients, the clients built are closed when any of them fails
+func (b *NacosClientBuilder) Build() (*NacosClients, error) {
+	nacosClient, err := setConfig(b.param)
+	if err != nil {
+		return nil, err
+	}
+	clientConfig, _ := nacosClient.GetClientConfig()
+	serverConfigs, _ := nacosClient.GetServerConfig()
+	httpAgent, _ := nacosClient.GetHttpAgent()
+
+	clients := &NacosClients{}
+	var ctx context.Context
+	ctx, clients.cancel = context.WithCancel(context.Background())
+	nacosServer, err := nacos_server.NewNacosServer(ctx, serverConfigs, clientConfig, httpAgent, clientConfig.TimeoutMs, clientConfig.Endpoint)
+	if err != nil {
+		clients.Close()
+		return nil, err
+	}
+	if !b.withoutConfig {
+		config, err := config_client.NewConfigClientWithProxy(nacosClient, func(ctx context.Context, serverConfig []constant.ServerConfig,
+			clientConfig constant.ClientConfig, httpAgent http_agent.IHttpAgent) (config_client.IConfigProxy, error) {
+			return config_client.NewConfigProxyWithServer(nacosServer, clientConfig), nil
+		})
+		if err != nil {
+			clients.Close()
+			return nil, err
+		}
+		clients.Config = config
+	}
+	if !b.withoutNaming {
+		naming, err := naming_client.NewNamingClientWithServer(nacosClient, nacosServer)
+		if err != nil {
+			clients.Close()
+			return nil, err
+		}
+		clients.Naming = naming
+	}
+	return clients, nil
+}
+
+// Close close the clients and stop refreshing the shared server list and tokens
+func (c *NacosClients) Close() {
+	c.once.Do(func() {
+		if c.Config != nil {
+			c.Config.CloseClient()
+		}
+		if c.Naming != nil {
+			c.Naming.CloseClient()
+		}
+		c.cancel()
+	})
+}
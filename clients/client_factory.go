@@ -24,6 +24,7 @@ import (
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/nacos_client"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/dns"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/http_agent"
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
 )
@@ -53,6 +54,20 @@ func NewConfigClient(param vo.NacosClientParam) (iClient config_client.IConfigCl
 	return
 }
 
+// NewConfigClientWithProxy create config client sending requests through the transport created by newProxy
+func NewConfigClientWithProxy(param vo.NacosClientParam, newProxy config_client.ConfigProxyFactory) (iClient config_client.IConfigClient, err error) {
+	nacosClient, err := setConfig(param)
+	if err != nil {
+		return
+	}
+	config, err := config_client.NewConfigClientWithProxy(nacosClient, newProxy)
+	if err != nil {
+		return
+	}
+	iClient = config
+	return
+}
+
 func NewNamingClient(param vo.NacosClientParam) (iClient naming_client.INamingClient, err error) {
 	nacosClient, err := setConfig(param)
 	if err != nil {
@@ -98,7 +113,7 @@ func setConfig(param vo.NacosClientParam) (iClient nacos_client.INacosClient, er
 
 	if len(param.ServerConfigs) == 0 {
 		clientConfig, _ := client.GetClientConfig()
-		if len(clientConfig.Endpoint) <= 0 {
+		if len(clientConfig.Endpoint) <= 0 && len(clientConfig.ServerDnsName) <= 0 {
 			err = errors.New("server configs not found in properties")
 			return nil, err
 		}
@@ -117,7 +132,19 @@ func setConfig(param vo.NacosClientParam) (iClient nacos_client.INacosClient, er
 
 	if _, _err := client.GetHttpAgent(); _err != nil {
 		if clientCfg, err := client.GetClientConfig(); err == nil {
-			_ = client.SetHttpAgent(&http_agent.HttpAgent{TlsConfig: clientCfg.TLSCfg})
+			agent := &http_agent.HttpAgent{
+				TlsConfig: clientCfg.TLSCfg,
+				Cache:     http_agent.NewResponseCache(clientCfg.HttpCacheSize),
+			}
+			var next http_agent.DialFunc
+			if clientCfg.DnsCacheCfg != nil {
+				agent.Resolver = dns.NewResolver(*clientCfg.DnsCacheCfg, clientCfg.Clock)
+				next = agent.Resolver.DialContext
+			}
+			if agent.Dialer, err = http_agent.NewServerDialer(param.ServerConfigs, next); err != nil {
+				return nil, err
+			}
+			_ = client.SetHttpAgent(agent)
 		}
 	}
 	iClient = client
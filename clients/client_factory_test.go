@@ -5,11 +5,22 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/mock"
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
 	"github.com/stretchr/testify/assert"
 )
 
+// the concrete clients and the mocks must keep up with the method sets of the interfaces
+var (
+	_ config_client.IConfigClient = (*config_client.ConfigClient)(nil)
+	_ config_client.IConfigClient = (*mock.MockIConfigClient)(nil)
+	_ naming_client.INamingClient = (*naming_client.NamingClient)(nil)
+	_ naming_client.INamingClient = (*mock.MockINamingClient)(nil)
+)
+
 func getIntranetIP() string {
 	addrs, err := net.InterfaceAddrs()
 	if err != nil {
@@ -66,3 +77,8 @@ func TestSetConfigClient(t *testing.T) {
 		assert.True(t, reflect.DeepEqual(nacosClientFromMap, nacosClientFromStruct))
 	})
 }
+
+func TestNacosClientBuilder_Build(t *testing.T) {
+	_, err := NewNacosClientBuilder(vo.NacosClientParam{}).Build()
+	assert.Equal(t, "server configs not found in properties", err.Error())
+}
@@ -0,0 +1,315 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package compat is the common part of the shims exposing a subset of the interfaces of other systems backed by
+// Nacos, so that the applications migrating from them can switch incrementally, see the consul and etcd packages.
+// The keys are stored as the configs of a group, of which the slashes are mapped to colons in dataId. Since the
+// configs have no revisions, the revisions are counted by the shim when the changes are observed, so they are
+// process-local: they start from 0 in each process, differ between processes and are lost on restart, and they
+// must not be persisted or shared. The compare-and-put compares with the md5 of the value last observed at the
+// revision, and since the server can't compare with an absent config, creating the key only if it's absent is not
+// supported. The empty values are regarded as absent like the configs
+package compat
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+const defaultPageSize = 100
+
+var (
+	keyPattern = regexp.MustCompile(`^[a-zA-Z0-9\-_./]*$`)
+	errRefused = errors.New("server refused")
+	// ErrCreateUnsupported is returned by the compare-and-put with revision 0, the key can't be created only if
+	// it's absent
+	ErrCreateUnsupported = errors.New("create if absent is not supported")
+)
+
+// DataId return the dataId of key, the slashes are mapped to colons and the other characters not allowed in
+// dataId are refused
+func DataId(key string) (string, error) {
+	if !keyPattern.MatchString(key) {
+		return "", errors.Errorf("key %q has the characters not allowed, only letters, digits and -_./ are allowed", key)
+	}
+	return strings.ReplaceAll(key, "/", ":"), nil
+}
+
+// Key return the key of dataId
+func Key(dataId string) string {
+	return strings.ReplaceAll(dataId, ":", "/")
+}
+
+// Entry is a key and value with the revisions counted by Store, the revisions are process-local
+type Entry struct {
+	Key            string
+	Value          string
+	CreateRevision int64 // the revision the key was created at
+	ModRevision    int64 // the revision the key was last modified at
+	Version        int64 // the times the key was modified since it was created
+}
+
+// Change is a change of key observed by Store.Watch, Entry is nil when the key is deleted
+type Change struct {
+	Key   string
+	Entry *Entry
+	Prev  *Entry
+}
+
+// Store is the keys stored as the configs of a group
+type Store struct {
+	client   config_client.IConfigClient
+	param    vo.CompatParam
+	mux      sync.Mutex
+	revision int64
+	entries  map[string]Entry
+}
+
+// NewStore create the store of the configs of param.Group
+func NewStore(client config_client.IConfigClient, param vo.CompatParam) *Store {
+	if len(param.Group) == 0 {
+		param.Group = constant.DEFAULT_GROUP
+	}
+	if param.PageSize <= 0 {
+		param.PageSize = defaultPageSize
+	}
+	return &Store{client: client, param: param, entries: make(map[string]Entry)}
+}
+
+// Revision return the revision of the last change observed
+func (s *Store) Revision() int64 {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.revision
+}
+
+// Get return the entry of key, it's nil when the key is absent
+func (s *Store) Get(key string) (*Entry, error) {
+	param, err := s.configParam(key)
+	if err != nil {
+		return nil, err
+	}
+	content, err := s.client.GetConfig(param)
+	if err != nil {
+		return nil, err
+	}
+	return s.observe(key, content), nil
+}
+
+// List return the entries of the keys with prefix, sorted by key
+func (s *Store) List(prefix string) ([]*Entry, error) {
+	dataId, err := DataId(prefix)
+	if err != nil {
+		return nil, err
+	}
+	it := config_client.NewConfigIterator(s.client, vo.SearchConfigParam{
+		Search:      "blur",
+		DataId:      dataId + "*",
+		Group:       s.param.Group,
+		NamespaceId: s.param.NamespaceId,
+		PageSize:    s.param.PageSize,
+	})
+	var entries []*Entry
+	listed := make(map[string]bool)
+	for it.Next() {
+		item := it.Item()
+		key := Key(item.DataId)
+		// the search on server is blur, so the group and prefix are matched exactly here
+		if item.Group != s.param.Group || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		listed[key] = true
+		if entry := s.observe(key, item.Content); entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+	if it.Err() != nil {
+		return nil, errors.Wrapf(it.Err(), "list the keys with prefix %q failed", prefix)
+	}
+	s.observeAbsent(prefix, listed)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries, nil
+}
+
+// Put set the value of key, the empty value is refused since it's regarded as absent
+func (s *Store) Put(key, value string) (*Entry, error) {
+	return s.put(key, value, "")
+}
+
+// CompareAndPut set the value of key only if its ModRevision is still modRevision, the revision must be the one
+// observed by this Store. It returns false when the key has been changed, including the changes not observed yet.
+// The modRevision 0 is refused with ErrCreateUnsupported
+func (s *Store) CompareAndPut(key, value string, modRevision int64) (bool, *Entry, error) {
+	if modRevision <= 0 {
+		return false, nil, errors.Wrapf(ErrCreateUnsupported, "compare and put key %q", key)
+	}
+	s.mux.Lock()
+	entry, ok := s.entries[key]
+	s.mux.Unlock()
+	if !ok || entry.ModRevision != modRevision {
+		return false, nil, nil
+	}
+	next, err := s.put(key, value, util.Md5(entry.Value))
+	if err != nil {
+		if errors.Cause(err) == errRefused {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	return true, next, nil
+}
+
+func (s *Store) put(key, value, casMd5 string) (*Entry, error) {
+	if len(value) == 0 {
+		return nil, errors.Errorf("the value of key %q can not be empty", key)
+	}
+	param, err := s.configParam(key)
+	if err != nil {
+		return nil, err
+	}
+	param.Content = value
+	param.CasMd5 = casMd5
+	published, err := s.client.PublishConfig(param)
+	if err != nil {
+		return nil, err
+	}
+	if !published {
+		return nil, errors.Wrapf(errRefused, "put key %q failed", key)
+	}
+	return s.observe(key, value), nil
+}
+
+// Delete delete the key, it returns the entry before deletion which is nil when the key is absent
+func (s *Store) Delete(key string) (*Entry, error) {
+	prev, err := s.Get(key)
+	if err != nil || prev == nil {
+		return nil, err
+	}
+	param, _ := s.configParam(key)
+	deleted, err := s.client.DeleteConfig(param)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, errors.Wrapf(errRefused, "delete key %q failed", key)
+	}
+	s.observe(key, "")
+	return prev, nil
+}
+
+// Watch stream the changes of key after the current entry through the returned channel, the channel is closed
+// after ctx is done
+func (s *Store) Watch(ctx context.Context, key string) (<-chan Change, error) {
+	current, err := s.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	param, _ := s.configParam(key)
+	events, err := s.client.Tail(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan Change)
+	go func() {
+		defer close(ch)
+		prev := current
+		for event := range events {
+			content := event.Content
+			if event.Type == model.ConfigEventDelete {
+				content = ""
+			}
+			entry := s.observe(key, content)
+			if sameEntry(prev, entry) {
+				continue
+			}
+			select {
+			case ch <- Change{Key: key, Entry: entry, Prev: prev}:
+			case <-ctx.Done():
+				return
+			}
+			prev = entry
+		}
+	}()
+	return ch, nil
+}
+
+func sameEntry(a, b *Entry) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.ModRevision == b.ModRevision
+}
+
+func (s *Store) configParam(key string) (vo.ConfigParam, error) {
+	dataId, err := DataId(key)
+	if err != nil {
+		return vo.ConfigParam{}, err
+	}
+	if len(dataId) == 0 {
+		return vo.ConfigParam{}, errors.New("key can not be empty")
+	}
+	return vo.ConfigParam{DataId: dataId, Group: s.param.Group, NamespaceId: s.param.NamespaceId}, nil
+}
+
+// observe record the content of key, the revision is increased when it's changed. The empty content means absent
+func (s *Store) observe(key, content string) *Entry {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	entry, ok := s.entries[key]
+	if len(content) == 0 {
+		if ok {
+			s.revision++
+			delete(s.entries, key)
+		}
+		return nil
+	}
+	if !ok || entry.Value != content {
+		s.revision++
+		if !ok {
+			entry = Entry{Key: key, CreateRevision: s.revision}
+		}
+		entry.Value = content
+		entry.ModRevision = s.revision
+		entry.Version++
+		s.entries[key] = entry
+	}
+	return &entry
+}
+
+// observeAbsent record the keys with prefix which are not listed as deleted
+func (s *Store) observeAbsent(prefix string, listed map[string]bool) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for key := range s.entries {
+		if strings.HasPrefix(key, prefix) && !listed[key] {
+			s.revision++
+			delete(s.entries, key)
+		}
+	}
+}
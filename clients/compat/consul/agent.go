@@ -0,0 +1,287 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consul
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+const (
+	HealthPassing  = "passing"
+	HealthCritical = "critical"
+
+	// MetadataId is the metadata key of instance keeping the ID of service registration
+	MetadataId = "consul-id"
+	// MetadataTags is the metadata key of instance keeping the comma separated tags of service registration
+	MetadataTags = "consul-tags"
+)
+
+// AgentWeights is the weights of service, Passing is the weight of instance
+type AgentWeights struct {
+	Passing int
+	Warning int
+}
+
+// AgentServiceRegistration is the service registered by Agent, it's registered as an ephemeral instance
+type AgentServiceRegistration struct {
+	ID      string // optional, default is Name
+	Name    string
+	Tags    []string
+	Port    int
+	Address string // optional, default is the detected ip of client
+	Meta    map[string]string
+	Weights *AgentWeights
+}
+
+// AgentService is a service instance
+type AgentService struct {
+	ID      string
+	Service string
+	Tags    []string
+	Meta    map[string]string
+	Port    int
+	Address string
+	Weights AgentWeights
+}
+
+// HealthCheck is the health check of service instance, it's derived from the health of instance
+type HealthCheck struct {
+	CheckID     string
+	Name        string
+	Status      string
+	ServiceID   string
+	ServiceName string
+}
+
+// HealthChecks is a list of HealthCheck
+type HealthChecks []*HealthCheck
+
+// AggregatedStatus return passing only if all the checks are passing
+func (c HealthChecks) AggregatedStatus() string {
+	for _, check := range c {
+		if check.Status != HealthPassing {
+			return HealthCritical
+		}
+	}
+	return HealthPassing
+}
+
+// ServiceEntry is the service instance with its health checks
+type ServiceEntry struct {
+	Service *AgentService
+	Checks  HealthChecks
+}
+
+// Agent is the shim of the agent api, it keeps the services registered by itself
+type Agent struct {
+	client   naming_client.INamingClient
+	param    vo.CompatParam
+	mux      sync.Mutex
+	services map[string]AgentServiceRegistration
+}
+
+func newAgent(client naming_client.INamingClient, param vo.CompatParam) *Agent {
+	if len(param.Group) == 0 {
+		param.Group = constant.DEFAULT_GROUP
+	}
+	return &Agent{client: client, param: param, services: make(map[string]AgentServiceRegistration)}
+}
+
+// ServiceRegister register the service as an ephemeral instance, the ID and tags are kept in the metadata
+func (a *Agent) ServiceRegister(reg *AgentServiceRegistration) error {
+	if reg == nil || len(reg.Name) == 0 {
+		return errors.New("[consul.ServiceRegister] service name can not be empty")
+	}
+	r := *reg
+	if len(r.ID) == 0 {
+		r.ID = r.Name
+	}
+	metadata := make(map[string]string, len(r.Meta)+2)
+	for k, v := range r.Meta {
+		metadata[k] = v
+	}
+	metadata[MetadataId] = r.ID
+	if len(r.Tags) > 0 {
+		metadata[MetadataTags] = strings.Join(r.Tags, ",")
+	}
+	weight := 1.0
+	if r.Weights != nil && r.Weights.Passing > 0 {
+		weight = float64(r.Weights.Passing)
+	}
+	registered, err := a.client.RegisterInstance(vo.RegisterInstanceParam{
+		Ip:          r.Address,
+		Port:        uint64(r.Port),
+		Weight:      weight,
+		Enable:      true,
+		Healthy:     true,
+		Metadata:    metadata,
+		ServiceName: r.Name,
+		GroupName:   a.param.Group,
+		Ephemeral:   true,
+	})
+	if err != nil {
+		return err
+	}
+	if !registered {
+		return errors.Errorf("[consul.ServiceRegister] register service %s failed", r.ID)
+	}
+	a.mux.Lock()
+	a.services[r.ID] = r
+	a.mux.Unlock()
+	return nil
+}
+
+// ServiceDeregister deregister the service registered by the agent
+func (a *Agent) ServiceDeregister(serviceID string) error {
+	a.mux.Lock()
+	r, ok := a.services[serviceID]
+	a.mux.Unlock()
+	if !ok {
+		return errors.Errorf("[consul.ServiceDeregister] service %s is not registered by the agent", serviceID)
+	}
+	deregistered, err := a.client.DeregisterInstance(vo.DeregisterInstanceParam{
+		Ip:          r.Address,
+		Port:        uint64(r.Port),
+		ServiceName: r.Name,
+		GroupName:   a.param.Group,
+		Ephemeral:   true,
+	})
+	if err != nil {
+		return err
+	}
+	if !deregistered {
+		return errors.Errorf("[consul.ServiceDeregister] deregister service %s failed", serviceID)
+	}
+	a.mux.Lock()
+	delete(a.services, serviceID)
+	a.mux.Unlock()
+	return nil
+}
+
+// Services return the services registered by the agent, keyed by ID
+func (a *Agent) Services() (map[string]*AgentService, error) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	services := make(map[string]*AgentService, len(a.services))
+	for id, r := range a.services {
+		service := &AgentService{ID: id, Service: r.Name, Tags: r.Tags, Meta: r.Meta, Port: r.Port, Address: r.Address}
+		if r.Weights != nil {
+			service.Weights = *r.Weights
+		}
+		services[id] = service
+	}
+	return services, nil
+}
+
+// Health is the shim of the health api
+type Health struct {
+	agent *Agent
+}
+
+// Service return the instances of service with tag, only the healthy ones are returned when passingOnly is true.
+// The blocking query is not supported, q is ignored
+func (h *Health) Service(service, tag string, passingOnly bool, q *QueryOptions) ([]*ServiceEntry, *QueryMeta, error) {
+	start := time.Now()
+	var (
+		instances []model.Instance
+		err       error
+	)
+	if passingOnly {
+		instances, err = h.agent.client.SelectInstances(vo.SelectInstancesParam{
+			ServiceName: service,
+			GroupName:   h.agent.param.Group,
+			HealthyOnly: true,
+		})
+	} else {
+		instances, err = h.agent.client.SelectAllInstances(vo.SelectAllInstancesParam{
+			ServiceName: service,
+			GroupName:   h.agent.param.Group,
+		})
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	entries := make([]*ServiceEntry, 0, len(instances))
+	for _, instance := range instances {
+		entry := toServiceEntry(service, instance)
+		if len(tag) == 0 || hasTag(entry.Service.Tags, tag) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Service.ID < entries[j].Service.ID
+	})
+	return entries, &QueryMeta{RequestTime: time.Since(start)}, nil
+}
+
+func toServiceEntry(service string, instance model.Instance) *ServiceEntry {
+	id := instance.Metadata[MetadataId]
+	if len(id) == 0 {
+		id = instance.InstanceId
+	}
+	var tags []string
+	if len(instance.Metadata[MetadataTags]) > 0 {
+		tags = strings.Split(instance.Metadata[MetadataTags], ",")
+	}
+	meta := make(map[string]string, len(instance.Metadata))
+	for k, v := range instance.Metadata {
+		if k != MetadataId && k != MetadataTags {
+			meta[k] = v
+		}
+	}
+	status := HealthPassing
+	if !instance.Healthy || !instance.Enable {
+		status = HealthCritical
+	}
+	return &ServiceEntry{
+		Service: &AgentService{
+			ID:      id,
+			Service: service,
+			Tags:    tags,
+			Meta:    meta,
+			Port:    int(instance.Port),
+			Address: instance.Ip,
+			Weights: AgentWeights{Passing: int(instance.Weight), Warning: 1},
+		},
+		Checks: HealthChecks{{
+			CheckID:     "service:" + id,
+			Name:        "Nacos instance health",
+			Status:      status,
+			ServiceID:   id,
+			ServiceName: service,
+		}},
+	}
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
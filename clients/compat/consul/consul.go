@@ -0,0 +1,265 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package consul is the shim of a subset of the KV, agent and health api of Consul backed by Nacos, the keys are
+// stored as configs and the services are registered as instances, see the compat package for the mapping
+package consul
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/compat"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// defaultWaitTime is the wait time of blocking queries like Consul
+const defaultWaitTime = 5 * time.Minute
+
+// QueryOptions is the options of queries, the blocking query is supported by KV.Get
+type QueryOptions struct {
+	WaitIndex uint64        // block until the index is larger than it
+	WaitTime  time.Duration // the max time to block, default is 5m
+	ctx       context.Context
+}
+
+// WithContext return the copy of options with ctx, the blocking query stops after ctx is done
+func (o *QueryOptions) WithContext(ctx context.Context) *QueryOptions {
+	o2 := *o
+	o2.ctx = ctx
+	return &o2
+}
+
+// WriteOptions is the options of writes, it's kept for the compatible signatures
+type WriteOptions struct{}
+
+// QueryMeta is the meta of queries
+type QueryMeta struct {
+	LastIndex   uint64
+	RequestTime time.Duration
+}
+
+// WriteMeta is the meta of writes
+type WriteMeta struct {
+	RequestTime time.Duration
+}
+
+// KVPair is a key and value, the indexes are counted by the client and only comparable within the process
+type KVPair struct {
+	Key         string
+	CreateIndex uint64
+	ModifyIndex uint64
+	Value       []byte
+}
+
+// KVPairs is a list of KVPair
+type KVPairs []*KVPair
+
+// Client is the shim of the client of Consul
+type Client struct {
+	kv     *KV
+	agent  *Agent
+	health *Health
+}
+
+// NewClient create the client, either configClient or namingClient can be nil when KV or Agent and Health are
+// not used
+func NewClient(configClient config_client.IConfigClient, namingClient naming_client.INamingClient, param vo.CompatParam) (*Client, error) {
+	if configClient == nil && namingClient == nil {
+		return nil, errors.New("[consul.NewClient] config client and naming client can not be both nil")
+	}
+	c := &Client{}
+	if configClient != nil {
+		c.kv = &KV{store: compat.NewStore(configClient, param)}
+	}
+	if namingClient != nil {
+		c.agent = newAgent(namingClient, param)
+		c.health = &Health{agent: c.agent}
+	}
+	return c, nil
+}
+
+// KV return the KV api, it's nil when the config client is nil
+func (c *Client) KV() *KV {
+	return c.kv
+}
+
+// Agent return the agent api, it's nil when the naming client is nil
+func (c *Client) Agent() *Agent {
+	return c.agent
+}
+
+// Health return the health api, it's nil when the naming client is nil
+func (c *Client) Health() *Health {
+	return c.health
+}
+
+// KV is the shim of the KV api
+type KV struct {
+	store *compat.Store
+}
+
+// Get get the key, the pair is nil when it's absent. When q.WaitIndex is set, it blocks until the ModifyIndex of
+// key is larger than WaitIndex or q.WaitTime passes
+func (k *KV) Get(key string, q *QueryOptions) (*KVPair, *QueryMeta, error) {
+	start := time.Now()
+	entry, err := k.store.Get(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if q != nil && q.WaitIndex > 0 && modifyIndex(entry) <= q.WaitIndex {
+		if entry, err = k.wait(key, entry, q); err != nil {
+			return nil, nil, err
+		}
+	}
+	meta := &QueryMeta{LastIndex: modifyIndex(entry), RequestTime: time.Since(start)}
+	if meta.LastIndex == 0 {
+		meta.LastIndex = uint64(k.store.Revision())
+	}
+	return toPair(entry), meta, nil
+}
+
+// wait block until the key is changed after entry, it returns the last entry when the wait time passes
+func (k *KV) wait(key string, entry *compat.Entry, q *QueryOptions) (*compat.Entry, error) {
+	ctx := q.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	waitTime := q.WaitTime
+	if waitTime <= 0 {
+		waitTime = defaultWaitTime
+	}
+	ctx, cancel := context.WithTimeout(ctx, waitTime)
+	defer cancel()
+	changes, err := k.store.Watch(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	for change := range changes {
+		entry = change.Entry
+		if change.Entry == nil || modifyIndex(change.Entry) > q.WaitIndex {
+			break
+		}
+	}
+	return entry, nil
+}
+
+// List list the pairs with prefix, sorted by key
+func (k *KV) List(prefix string, q *QueryOptions) (KVPairs, *QueryMeta, error) {
+	start := time.Now()
+	entries, err := k.store.List(prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	pairs := make(KVPairs, 0, len(entries))
+	for _, entry := range entries {
+		pairs = append(pairs, toPair(entry))
+	}
+	return pairs, &QueryMeta{LastIndex: uint64(k.store.Revision()), RequestTime: time.Since(start)}, nil
+}
+
+// Keys list the keys with prefix, the keys are truncated after the first separator following the prefix
+// when separator is not empty, like the directories
+func (k *KV) Keys(prefix, separator string, q *QueryOptions) ([]string, *QueryMeta, error) {
+	pairs, meta, err := k.List(prefix, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	var keys []string
+	seen := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		key := pair.Key
+		if len(separator) > 0 {
+			if i := strings.Index(key[len(prefix):], separator); i >= 0 {
+				key = key[:len(prefix)+i+len(separator)]
+			}
+		}
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys, meta, nil
+}
+
+// Put set the value of p.Key
+func (k *KV) Put(p *KVPair, q *WriteOptions) (*WriteMeta, error) {
+	start := time.Now()
+	if _, err := k.store.Put(p.Key, string(p.Value)); err != nil {
+		return nil, err
+	}
+	return &WriteMeta{RequestTime: time.Since(start)}, nil
+}
+
+// CAS set the value of p.Key only if its ModifyIndex is still p.ModifyIndex, the indexes are process-local so
+// p.ModifyIndex must be read by this client. Unlike Consul, the ModifyIndex 0 is refused with
+// compat.ErrCreateUnsupported since the key can't be created only if it's absent
+func (k *KV) CAS(p *KVPair, q *WriteOptions) (bool, *WriteMeta, error) {
+	start := time.Now()
+	done, _, err := k.store.CompareAndPut(p.Key, string(p.Value), int64(p.ModifyIndex))
+	if err != nil {
+		return false, nil, err
+	}
+	return done, &WriteMeta{RequestTime: time.Since(start)}, nil
+}
+
+// Delete delete the key
+func (k *KV) Delete(key string, w *WriteOptions) (*WriteMeta, error) {
+	start := time.Now()
+	if _, err := k.store.Delete(key); err != nil {
+		return nil, err
+	}
+	return &WriteMeta{RequestTime: time.Since(start)}, nil
+}
+
+// DeleteTree delete the keys with prefix
+func (k *KV) DeleteTree(prefix string, w *WriteOptions) (*WriteMeta, error) {
+	start := time.Now()
+	entries, err := k.store.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if _, err = k.store.Delete(entry.Key); err != nil {
+			return nil, err
+		}
+	}
+	return &WriteMeta{RequestTime: time.Since(start)}, nil
+}
+
+func modifyIndex(entry *compat.Entry) uint64 {
+	if entry == nil {
+		return 0
+	}
+	return uint64(entry.ModRevision)
+}
+
+func toPair(entry *compat.Entry) *KVPair {
+	if entry == nil {
+		return nil
+	}
+	return &KVPair{
+		Key:         entry.Key,
+		CreateIndex: uint64(entry.CreateRevision),
+		ModifyIndex: uint64(entry.ModRevision),
+		Value:       []byte(entry.Value),
+	}
+}
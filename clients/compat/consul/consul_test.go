@@ -0,0 +1,256 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consul
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/compat"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// memoryConfigClient keeps the configs in memory and streams their changes to Tail
+type memoryConfigClient struct {
+	config_client.IConfigClient
+	mux     sync.Mutex
+	configs map[string]string
+	tails   map[string][]chan model.ConfigChangeEvent
+}
+
+func newMemoryConfigClient() *memoryConfigClient {
+	return &memoryConfigClient{configs: map[string]string{}, tails: map[string][]chan model.ConfigChangeEvent{}}
+}
+
+func (c *memoryConfigClient) GetConfig(param vo.ConfigParam) (string, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.configs[param.DataId], nil
+}
+
+func (c *memoryConfigClient) PublishConfig(param vo.ConfigParam) (bool, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if len(param.CasMd5) > 0 && util.Md5(c.configs[param.DataId]) != param.CasMd5 {
+		return false, nil
+	}
+	c.configs[param.DataId] = param.Content
+	for _, ch := range c.tails[param.DataId] {
+		ch <- model.ConfigChangeEvent{Type: model.ConfigEventChange, DataId: param.DataId, Content: param.Content}
+	}
+	return true, nil
+}
+
+func (c *memoryConfigClient) DeleteConfig(param vo.ConfigParam) (bool, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	delete(c.configs, param.DataId)
+	return true, nil
+}
+
+func (c *memoryConfigClient) SearchConfig(param vo.SearchConfigParam) (*model.ConfigPage, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	page := &model.ConfigPage{PageNumber: 1, PagesAvailable: 1}
+	for dataId, content := range c.configs {
+		if strings.HasPrefix(dataId, strings.TrimSuffix(param.DataId, "*")) {
+			page.PageItems = append(page.PageItems, model.ConfigItem{DataId: dataId, Group: param.Group, Content: content})
+		}
+	}
+	return page, nil
+}
+
+func (c *memoryConfigClient) Tail(ctx context.Context, param vo.ConfigParam) (<-chan model.ConfigChangeEvent, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	ch := make(chan model.ConfigChangeEvent, 10)
+	c.tails[param.DataId] = append(c.tails[param.DataId], ch)
+	go func() {
+		<-ctx.Done()
+		c.mux.Lock()
+		defer c.mux.Unlock()
+		delete(c.tails, param.DataId)
+		close(ch)
+	}()
+	return ch, nil
+}
+
+// memoryNamingClient keeps the registered instances in memory
+type memoryNamingClient struct {
+	naming_client.INamingClient
+	mux       sync.Mutex
+	instances map[string]model.Instance
+}
+
+func (c *memoryNamingClient) RegisterInstance(param vo.RegisterInstanceParam) (bool, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.instances[fmt.Sprintf("%s:%d", param.Ip, param.Port)] = model.Instance{
+		InstanceId:  fmt.Sprintf("%s#%d", param.Ip, param.Port),
+		Ip:          param.Ip,
+		Port:        param.Port,
+		Weight:      param.Weight,
+		Healthy:     param.Healthy,
+		Enable:      param.Enable,
+		ServiceName: param.ServiceName,
+		Metadata:    param.Metadata,
+	}
+	return true, nil
+}
+
+func (c *memoryNamingClient) DeregisterInstance(param vo.DeregisterInstanceParam) (bool, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	delete(c.instances, fmt.Sprintf("%s:%d", param.Ip, param.Port))
+	return true, nil
+}
+
+func (c *memoryNamingClient) SelectAllInstances(param vo.SelectAllInstancesParam) ([]model.Instance, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	var instances []model.Instance
+	for _, instance := range c.instances {
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+func (c *memoryNamingClient) SelectInstances(param vo.SelectInstancesParam) ([]model.Instance, error) {
+	all, _ := c.SelectAllInstances(vo.SelectAllInstancesParam{})
+	var instances []model.Instance
+	for _, instance := range all {
+		if instance.Healthy == param.HealthyOnly {
+			instances = append(instances, instance)
+		}
+	}
+	return instances, nil
+}
+
+func TestKV(t *testing.T) {
+	configClient := newMemoryConfigClient()
+	client, err := NewClient(configClient, nil, vo.CompatParam{})
+	assert.Nil(t, err)
+	assert.Nil(t, client.Agent())
+	kv := client.KV()
+
+	_, err = kv.Put(&KVPair{Key: "app/db/url", Value: []byte("mysql://a")}, nil)
+	assert.Nil(t, err)
+	_, err = kv.Put(&KVPair{Key: "app/name", Value: []byte("demo")}, nil)
+	assert.Nil(t, err)
+
+	pair, meta, err := kv.Get("app/db/url", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "mysql://a", string(pair.Value))
+	assert.Equal(t, pair.ModifyIndex, meta.LastIndex)
+
+	done, _, err := kv.CAS(&KVPair{Key: "app/db/url", Value: []byte("mysql://b"), ModifyIndex: pair.ModifyIndex}, nil)
+	assert.Nil(t, err)
+	assert.True(t, done)
+	done, _, err = kv.CAS(&KVPair{Key: "app/db/url", Value: []byte("mysql://c"), ModifyIndex: pair.ModifyIndex}, nil)
+	assert.Nil(t, err)
+	assert.False(t, done)
+	done, _, err = kv.CAS(&KVPair{Key: "app/new", Value: []byte("other")}, nil)
+	assert.Equal(t, compat.ErrCreateUnsupported, errors.Cause(err))
+	assert.False(t, done)
+
+	keys, _, err := kv.Keys("app/", "/", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"app/db/", "app/name"}, keys)
+	pairs, _, err := kv.List("app/", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(pairs))
+	assert.Equal(t, "mysql://b", string(pairs[0].Value))
+
+	_, err = kv.DeleteTree("app/db/", nil)
+	assert.Nil(t, err)
+	pair, _, err = kv.Get("app/db/url", nil)
+	assert.Nil(t, err)
+	assert.Nil(t, pair)
+}
+
+func TestKV_BlockingGet(t *testing.T) {
+	configClient := newMemoryConfigClient()
+	client, err := NewClient(configClient, nil, vo.CompatParam{})
+	assert.Nil(t, err)
+	kv := client.KV()
+	_, err = kv.Put(&KVPair{Key: "flag", Value: []byte("on")}, nil)
+	assert.Nil(t, err)
+	pair, meta, err := kv.Get("flag", nil)
+	assert.Nil(t, err)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = configClient.PublishConfig(vo.ConfigParam{DataId: "flag", Content: "off"})
+	}()
+	pair, next, err := kv.Get("flag", &QueryOptions{WaitIndex: meta.LastIndex, WaitTime: time.Second})
+	assert.Nil(t, err)
+	assert.Equal(t, "off", string(pair.Value))
+	assert.True(t, next.LastIndex > meta.LastIndex)
+
+	pair, timeout, err := kv.Get("flag", &QueryOptions{WaitIndex: next.LastIndex, WaitTime: 50 * time.Millisecond})
+	assert.Nil(t, err)
+	assert.Equal(t, "off", string(pair.Value))
+	assert.Equal(t, next.LastIndex, timeout.LastIndex)
+}
+
+func TestAgent_Health(t *testing.T) {
+	namingClient := &memoryNamingClient{instances: map[string]model.Instance{}}
+	client, err := NewClient(nil, namingClient, vo.CompatParam{})
+	assert.Nil(t, err)
+	assert.Nil(t, client.KV())
+
+	err = client.Agent().ServiceRegister(&AgentServiceRegistration{ID: "web-1", Name: "web", Tags: []string{"v1", "primary"},
+		Address: "10.0.0.1", Port: 8080, Meta: map[string]string{"zone": "a"}})
+	assert.Nil(t, err)
+	err = client.Agent().ServiceRegister(&AgentServiceRegistration{ID: "web-2", Name: "web", Tags: []string{"v2"},
+		Address: "10.0.0.2", Port: 8080, Weights: &AgentWeights{Passing: 3}})
+	assert.Nil(t, err)
+	services, err := client.Agent().Services()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(services))
+
+	entries, _, err := client.Health().Service("web", "", true, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "web-1", entries[0].Service.ID)
+	assert.Equal(t, []string{"v1", "primary"}, entries[0].Service.Tags)
+	assert.Equal(t, map[string]string{"zone": "a"}, entries[0].Service.Meta)
+	assert.Equal(t, HealthPassing, entries[0].Checks.AggregatedStatus())
+	assert.Equal(t, 3, entries[1].Service.Weights.Passing)
+
+	entries, _, err = client.Health().Service("web", "v2", false, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "10.0.0.2", entries[0].Service.Address)
+
+	assert.Nil(t, client.Agent().ServiceDeregister("web-1"))
+	assert.NotNil(t, client.Agent().ServiceDeregister("web-1"))
+	entries, _, err = client.Health().Service("web", "", false, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+}
@@ -0,0 +1,338 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package etcd is the shim of a subset of the KV and Watcher interfaces of etcd clientv3 backed by Nacos, the keys
+// are stored as configs, see the compat package for the mapping. The watch of prefix is not supported
+package etcd
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/compat"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+type EventType int
+
+const (
+	PUT EventType = iota
+	DELETE
+)
+
+func (t EventType) String() string {
+	if t == DELETE {
+		return "DELETE"
+	}
+	return "PUT"
+}
+
+// KeyValue is a key and value with revisions, the revisions are counted by the client and only comparable
+// within the process
+type KeyValue struct {
+	Key            []byte
+	Value          []byte
+	CreateRevision int64
+	ModRevision    int64
+	Version        int64
+}
+
+// ResponseHeader is the header of responses, Revision is the revision of the last change observed
+type ResponseHeader struct {
+	Revision int64
+}
+
+type PutResponse struct {
+	Header *ResponseHeader
+	PrevKv *KeyValue
+}
+
+type GetResponse struct {
+	Header *ResponseHeader
+	Kvs    []*KeyValue
+	More   bool // there are more keys than the limit
+	Count  int64
+}
+
+type DeleteResponse struct {
+	Header  *ResponseHeader
+	Deleted int64
+	PrevKvs []*KeyValue
+}
+
+// Event is a change of key, Kv only has the key for DELETE
+type Event struct {
+	Type   EventType
+	Kv     *KeyValue
+	PrevKv *KeyValue
+}
+
+// WatchResponse is the events of watch, the watch is canceled when Canceled is true
+type WatchResponse struct {
+	Header   ResponseHeader
+	Events   []*Event
+	Canceled bool
+	err      error
+}
+
+// Err return the reason why the watch is canceled
+func (wr *WatchResponse) Err() error {
+	return wr.err
+}
+
+type WatchChan <-chan WatchResponse
+
+// Op is the options of operations
+type Op struct {
+	prefix   bool
+	prevKV   bool
+	keysOnly bool
+	limit    int64
+}
+
+type OpOption func(*Op)
+
+// WithPrefix operate on the keys with the key as prefix
+func WithPrefix() OpOption {
+	return func(op *Op) { op.prefix = true }
+}
+
+// WithPrevKV return the key-values before Put and Delete, and the previous key-values of watch events
+func WithPrevKV() OpOption {
+	return func(op *Op) { op.prevKV = true }
+}
+
+// WithKeysOnly return only the keys of Get
+func WithKeysOnly() OpOption {
+	return func(op *Op) { op.keysOnly = true }
+}
+
+// WithLimit limit the number of keys of Get
+func WithLimit(limit int64) OpOption {
+	return func(op *Op) { op.limit = limit }
+}
+
+func newOp(opts []OpOption) Op {
+	var op Op
+	for _, opt := range opts {
+		opt(&op)
+	}
+	return op
+}
+
+// KV is the subset of the KV interface of clientv3
+type KV interface {
+	Put(ctx context.Context, key, val string, opts ...OpOption) (*PutResponse, error)
+	Get(ctx context.Context, key string, opts ...OpOption) (*GetResponse, error)
+	Delete(ctx context.Context, key string, opts ...OpOption) (*DeleteResponse, error)
+}
+
+// Watcher is the subset of the Watcher interface of clientv3
+type Watcher interface {
+	Watch(ctx context.Context, key string, opts ...OpOption) WatchChan
+	Close() error
+}
+
+// Client is the shim of the client of clientv3
+type Client struct {
+	KV
+	Watcher
+}
+
+// NewClient create the client of the configs of param.Group
+func NewClient(configClient config_client.IConfigClient, param vo.CompatParam) (*Client, error) {
+	if configClient == nil {
+		return nil, errors.New("[etcd.NewClient] config client can not be nil")
+	}
+	store := compat.NewStore(configClient, param)
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Client{
+		KV:      &kv{store: store},
+		Watcher: &watcher{store: store, ctx: ctx, cancel: cancel},
+	}, nil
+}
+
+type kv struct {
+	store *compat.Store
+}
+
+func (k *kv) Put(ctx context.Context, key, val string, opts ...OpOption) (*PutResponse, error) {
+	op := newOp(opts)
+	resp := &PutResponse{}
+	if op.prevKV {
+		prev, err := k.store.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		resp.PrevKv = toKeyValue(prev, false)
+	}
+	if _, err := k.store.Put(key, val); err != nil {
+		return nil, err
+	}
+	resp.Header = k.header()
+	return resp, nil
+}
+
+func (k *kv) Get(ctx context.Context, key string, opts ...OpOption) (*GetResponse, error) {
+	op := newOp(opts)
+	entries, err := k.entries(key, op)
+	if err != nil {
+		return nil, err
+	}
+	resp := &GetResponse{Header: k.header(), Count: int64(len(entries))}
+	if op.limit > 0 && int64(len(entries)) > op.limit {
+		entries, resp.More = entries[:op.limit], true
+	}
+	for _, entry := range entries {
+		resp.Kvs = append(resp.Kvs, toKeyValue(entry, op.keysOnly))
+	}
+	return resp, nil
+}
+
+func (k *kv) Delete(ctx context.Context, key string, opts ...OpOption) (*DeleteResponse, error) {
+	op := newOp(opts)
+	entries, err := k.entries(key, op)
+	if err != nil {
+		return nil, err
+	}
+	resp := &DeleteResponse{}
+	for _, entry := range entries {
+		prev, err := k.store.Delete(entry.Key)
+		if err != nil {
+			return nil, err
+		}
+		if prev == nil {
+			continue
+		}
+		resp.Deleted++
+		if op.prevKV {
+			resp.PrevKvs = append(resp.PrevKvs, toKeyValue(prev, false))
+		}
+	}
+	resp.Header = k.header()
+	return resp, nil
+}
+
+// entries return the entries of key, or the ones with key as prefix
+func (k *kv) entries(key string, op Op) ([]*compat.Entry, error) {
+	if op.prefix {
+		return k.store.List(key)
+	}
+	entry, err := k.store.Get(key)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	return []*compat.Entry{entry}, nil
+}
+
+func (k *kv) header() *ResponseHeader {
+	return &ResponseHeader{Revision: k.store.Revision()}
+}
+
+type watcher struct {
+	store  *compat.Store
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Watch stream the changes of key after now, the channel is closed after ctx is done or the watcher is closed
+func (w *watcher) Watch(ctx context.Context, key string, opts ...OpOption) WatchChan {
+	op := newOp(opts)
+	ch := make(chan WatchResponse, 1)
+	if op.prefix {
+		ch <- WatchResponse{Canceled: true, err: errors.New("[etcd.Watch] the watch of prefix is not supported")}
+		close(ch)
+		return ch
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	changes, err := w.store.Watch(ctx, key)
+	if err != nil {
+		cancel()
+		ch <- WatchResponse{Canceled: true, err: err}
+		close(ch)
+		return ch
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer close(ch)
+		defer cancel()
+		for {
+			select {
+			case change, ok := <-changes:
+				if !ok {
+					return
+				}
+				select {
+				case ch <- WatchResponse{Header: *w.header(), Events: []*Event{toEvent(change, op.prevKV)}}:
+				case <-ctx.Done():
+					return
+				case <-w.ctx.Done():
+					return
+				}
+			case <-w.ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// Close cancel all the watches
+func (w *watcher) Close() error {
+	w.cancel()
+	w.wg.Wait()
+	return nil
+}
+
+func (w *watcher) header() *ResponseHeader {
+	return &ResponseHeader{Revision: w.store.Revision()}
+}
+
+func toEvent(change compat.Change, prevKV bool) *Event {
+	event := &Event{Type: PUT, Kv: toKeyValue(change.Entry, false)}
+	if change.Entry == nil {
+		event.Type = DELETE
+		event.Kv = &KeyValue{Key: []byte(change.Key)}
+		if change.Prev != nil {
+			event.Kv.CreateRevision = change.Prev.CreateRevision
+		}
+	}
+	if prevKV {
+		event.PrevKv = toKeyValue(change.Prev, false)
+	}
+	return event
+}
+
+func toKeyValue(entry *compat.Entry, keysOnly bool) *KeyValue {
+	if entry == nil {
+		return nil
+	}
+	kv := &KeyValue{
+		Key:            []byte(entry.Key),
+		CreateRevision: entry.CreateRevision,
+		ModRevision:    entry.ModRevision,
+		Version:        entry.Version,
+	}
+	if !keysOnly {
+		kv.Value = []byte(entry.Value)
+	}
+	return kv
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package etcd
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// memoryConfigClient keeps the configs in memory and streams their changes to Tail
+type memoryConfigClient struct {
+	config_client.IConfigClient
+	mux     sync.Mutex
+	configs map[string]string
+	tails   map[string][]chan model.ConfigChangeEvent
+}
+
+func newMemoryConfigClient() *memoryConfigClient {
+	return &memoryConfigClient{configs: map[string]string{}, tails: map[string][]chan model.ConfigChangeEvent{}}
+}
+
+func (c *memoryConfigClient) GetConfig(param vo.ConfigParam) (string, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.configs[param.DataId], nil
+}
+
+func (c *memoryConfigClient) PublishConfig(param vo.ConfigParam) (bool, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if len(param.CasMd5) > 0 && util.Md5(c.configs[param.DataId]) != param.CasMd5 &&
+		!(len(c.configs[param.DataId]) == 0 && param.CasMd5 == "d41d8cd98f00b204e9800998ecf8427e") {
+		return false, nil
+	}
+	c.configs[param.DataId] = param.Content
+	c.notify(model.ConfigChangeEvent{Type: model.ConfigEventChange, DataId: param.DataId, Content: param.Content})
+	return true, nil
+}
+
+func (c *memoryConfigClient) DeleteConfig(param vo.ConfigParam) (bool, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	content := c.configs[param.DataId]
+	delete(c.configs, param.DataId)
+	c.notify(model.ConfigChangeEvent{Type: model.ConfigEventDelete, DataId: param.DataId, Content: content})
+	return true, nil
+}
+
+func (c *memoryConfigClient) SearchConfig(param vo.SearchConfigParam) (*model.ConfigPage, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	page := &model.ConfigPage{PageNumber: 1, PagesAvailable: 1}
+	for dataId, content := range c.configs {
+		if strings.HasPrefix(dataId, strings.TrimSuffix(param.DataId, "*")) {
+			page.PageItems = append(page.PageItems, model.ConfigItem{DataId: dataId, Group: param.Group, Content: content})
+		}
+	}
+	page.TotalCount = len(page.PageItems)
+	return page, nil
+}
+
+func (c *memoryConfigClient) Tail(ctx context.Context, param vo.ConfigParam) (<-chan model.ConfigChangeEvent, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	ch := make(chan model.ConfigChangeEvent, 10)
+	c.tails[param.DataId] = append(c.tails[param.DataId], ch)
+	go func() {
+		<-ctx.Done()
+		c.mux.Lock()
+		defer c.mux.Unlock()
+		tails := c.tails[param.DataId]
+		for i := range tails {
+			if tails[i] == ch {
+				c.tails[param.DataId] = append(tails[:i], tails[i+1:]...)
+				break
+			}
+		}
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func (c *memoryConfigClient) notify(event model.ConfigChangeEvent) {
+	for _, ch := range c.tails[event.DataId] {
+		ch <- event
+	}
+}
+
+func TestClient_KV(t *testing.T) {
+	configClient := newMemoryConfigClient()
+	client, err := NewClient(configClient, vo.CompatParam{})
+	assert.Nil(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	_, err = client.Put(ctx, "app/db/url", "mysql://a")
+	assert.Nil(t, err)
+	_, err = client.Put(ctx, "app/db/user", "root")
+	assert.Nil(t, err)
+	_, err = client.Put(ctx, "other", "x")
+	assert.Nil(t, err)
+	assert.Equal(t, "mysql://a", configClient.configs["app:db:url"])
+
+	put, err := client.Put(ctx, "app/db/url", "mysql://b", WithPrevKV())
+	assert.Nil(t, err)
+	assert.Equal(t, "mysql://a", string(put.PrevKv.Value))
+
+	get, err := client.Get(ctx, "app/db/url")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(get.Kvs))
+	assert.Equal(t, "mysql://b", string(get.Kvs[0].Value))
+	assert.Equal(t, int64(2), get.Kvs[0].Version)
+	assert.True(t, get.Kvs[0].ModRevision > get.Kvs[0].CreateRevision)
+
+	get, err = client.Get(ctx, "app/", WithPrefix(), WithKeysOnly(), WithLimit(1))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), get.Count)
+	assert.True(t, get.More)
+	assert.Equal(t, "app/db/url", string(get.Kvs[0].Key))
+	assert.Nil(t, get.Kvs[0].Value)
+
+	del, err := client.Delete(ctx, "app/", WithPrefix(), WithPrevKV())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), del.Deleted)
+	assert.Equal(t, 2, len(del.PrevKvs))
+	get, err = client.Get(ctx, "app/", WithPrefix())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(get.Kvs))
+
+	_, err = client.Put(ctx, "a b", "x")
+	assert.NotNil(t, err)
+}
+
+func TestClient_Watch(t *testing.T) {
+	configClient := newMemoryConfigClient()
+	client, err := NewClient(configClient, vo.CompatParam{})
+	assert.Nil(t, err)
+	ctx := context.Background()
+	_, err = client.Put(ctx, "app/flag", "on")
+	assert.Nil(t, err)
+
+	watch := client.Watch(ctx, "app/flag", WithPrevKV())
+	_, err = client.Put(ctx, "app/flag", "off")
+	assert.Nil(t, err)
+	_, err = client.Delete(ctx, "app/flag")
+	assert.Nil(t, err)
+
+	var events []*Event
+	for len(events) < 2 {
+		select {
+		case resp := <-watch:
+			assert.Nil(t, resp.Err())
+			events = append(events, resp.Events...)
+		case <-time.After(time.Second):
+			t.Fatal("no watch response")
+		}
+	}
+	assert.Equal(t, PUT, events[0].Type)
+	assert.Equal(t, "off", string(events[0].Kv.Value))
+	assert.Equal(t, "on", string(events[0].PrevKv.Value))
+	assert.Equal(t, DELETE, events[1].Type)
+	assert.Equal(t, "app/flag", string(events[1].Kv.Key))
+
+	resp := <-client.Watch(ctx, "app/", WithPrefix())
+	assert.True(t, resp.Canceled)
+	assert.NotNil(t, resp.Err())
+
+	assert.Nil(t, client.Close())
+	_, ok := <-watch
+	assert.False(t, ok)
+}
@@ -0,0 +1,220 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/monitor"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+type cacheDataListener struct {
+	listener          vo.Listener
+	listenerErr       func(namespace, group, dataId, data string) error
+	breaker           *listenerBreaker
+	onDelete          vo.DeleteListener
+	lastMd5           string
+	deliverLatestOnly bool
+	// ordered deliver every change one by one in the order they are received
+	ordered      bool
+	deliverMutex sync.Mutex
+	delivering   bool
+	pending      []*listenerEvent
+	// deadline is the deadline of the context the listener is added with, zero means no deadline
+	deadline time.Time
+	// cancelable means the listener is added by ListenConfig and removed by CancelListenConfig, the others are
+	// bound to a context and removed when it's done
+	cancelable bool
+	// deliverCurrent deliver the current content as the first event, instead of the changes after it's added
+	deliverCurrent bool
+	// clock observes the listener lag, nil means the system clock
+	clock clock.Clock
+}
+
+type listenerEvent struct {
+	namespace string
+	group     string
+	dataId    string
+	content   string
+	deleted   bool
+	// changedAt is the time the config was changed on server, the listener lag is observed when it's set
+	changedAt time.Time
+}
+
+func (l *cacheDataListener) getLastMd5() string {
+	l.deliverMutex.Lock()
+	defer l.deliverMutex.Unlock()
+	return l.lastMd5
+}
+
+func (l *cacheDataListener) setLastMd5(md5 string) {
+	l.deliverMutex.Lock()
+	defer l.deliverMutex.Unlock()
+	l.lastMd5 = md5
+}
+
+// restoreLastMd5 set the last md5 back to prev, unless the listener is notified with another md5 meanwhile
+func (l *cacheDataListener) restoreLastMd5(md5, prev string) {
+	l.deliverMutex.Lock()
+	defer l.deliverMutex.Unlock()
+	if l.lastMd5 == md5 {
+		l.lastMd5 = prev
+	}
+}
+
+// deliver invoke the listener asynchronously, when deliverLatestOnly is set and the listener is still
+// processing a previous change, only the newest pending change is kept and delivered afterwards
+func (l *cacheDataListener) deliver(namespace, group, dataId, content string) {
+	l.deliverEvent(&listenerEvent{namespace: namespace, group: group, dataId: dataId, content: content})
+}
+
+func (l *cacheDataListener) invoke(event *listenerEvent) {
+	if l.breaker != nil && !l.breaker.allow(event) {
+		return
+	}
+	if !event.changedAt.IsZero() {
+		defer observeListenerLag(l.clock, event)
+	}
+	err := callListener(func() error {
+		content := event.content
+		if event.deleted {
+			if l.onDelete != nil {
+				l.onDelete(event.namespace, event.group, event.dataId, event.content)
+				return nil
+			}
+			content = ""
+		}
+		if l.listenerErr != nil {
+			return l.listenerErr(event.namespace, event.group, event.dataId, content)
+		}
+		l.listener(event.namespace, event.group, event.dataId, content)
+		return nil
+	})
+	if l.breaker != nil {
+		l.breaker.record(err, l.deliverEvent)
+	}
+}
+
+// observeListenerLag observe the time between the config changed on server and the listener completed,
+// the negative lag caused by clock skew between server and client is observed as 0
+func observeListenerLag(clk clock.Clock, event *listenerEvent) {
+	lag := clock.OrReal(clk).Since(event.changedAt)
+	if lag < 0 {
+		lag = 0
+	}
+	monitor.GetListenerLagMonitor(event.namespace, event.group, event.dataId).Observe(lag.Seconds())
+}
+
+func (l *cacheDataListener) deliverEvent(event *listenerEvent) {
+	if !l.deliverLatestOnly && !l.ordered {
+		go l.invoke(event)
+		return
+	}
+	l.deliverMutex.Lock()
+	defer l.deliverMutex.Unlock()
+	if l.delivering {
+		if l.deliverLatestOnly {
+			l.pending = l.pending[:0]
+		}
+		l.pending = append(l.pending, event)
+		return
+	}
+	l.delivering = true
+	go func() {
+		for event != nil {
+			l.invoke(event)
+			l.deliverMutex.Lock()
+			event = nil
+			if len(l.pending) > 0 {
+				event, l.pending = l.pending[0], l.pending[1:]
+			} else {
+				l.delivering = false
+			}
+			l.deliverMutex.Unlock()
+		}
+	}()
+}
+
+// cacheDataListeners is the set of listeners registered on the same config
+type cacheDataListeners struct {
+	mutex     sync.RWMutex
+	listeners []*cacheDataListener
+}
+
+func (ls *cacheDataListeners) add(l *cacheDataListener) {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+	ls.listeners = append(ls.listeners, l)
+}
+
+// remove the listener and return the number of remaining listeners
+func (ls *cacheDataListeners) remove(l *cacheDataListener) int {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+	for i, item := range ls.listeners {
+		if item == l {
+			ls.listeners = append(ls.listeners[:i:i], ls.listeners[i+1:]...)
+			break
+		}
+	}
+	return len(ls.listeners)
+}
+
+// removeCancelable remove the listeners added by ListenConfig and return the number of remaining listeners
+func (ls *cacheDataListeners) removeCancelable() int {
+	ls.mutex.Lock()
+	defer ls.mutex.Unlock()
+	remaining := make([]*cacheDataListener, 0, len(ls.listeners))
+	for _, item := range ls.listeners {
+		if !item.cancelable {
+			remaining = append(remaining, item)
+		}
+	}
+	ls.listeners = remaining
+	return len(ls.listeners)
+}
+
+// deadline return the latest deadline of the listeners, the requests made for them should not outlive it.
+// It returns false if any listener has no deadline
+func (ls *cacheDataListeners) deadline() (time.Time, bool) {
+	if ls == nil {
+		return time.Time{}, false
+	}
+	ls.mutex.RLock()
+	defer ls.mutex.RUnlock()
+	var latest time.Time
+	for _, l := range ls.listeners {
+		if l.deadline.IsZero() {
+			return time.Time{}, false
+		}
+		if l.deadline.After(latest) {
+			latest = l.deadline
+		}
+	}
+	return latest, !latest.IsZero()
+}
+
+func (ls *cacheDataListeners) items() []*cacheDataListener {
+	ls.mutex.RLock()
+	defer ls.mutex.RUnlock()
+	items := make([]*cacheDataListener, len(ls.listeners))
+	copy(items, ls.listeners)
+	return items
+}
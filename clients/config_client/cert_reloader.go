@@ -0,0 +1,215 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"sync"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/monitor"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"github.com/pkg/errors"
+)
+
+// CertReloader keep the certificate, private key and CA bundle stored in configs, and reload them on change,
+// so that the tls.Config built by it picks up the rotated certificate without restarting
+type CertReloader struct {
+	client IConfigClient
+	param  vo.CertParam
+
+	mux      sync.RWMutex
+	contents map[string]string
+	cert     *tls.Certificate
+	caPool   *x509.CertPool
+	notAfter time.Time
+}
+
+// NewCertReloader load the certificate from configs and listen them, the certificate and private key must be valid at start
+func NewCertReloader(client IConfigClient, param vo.CertParam) (*CertReloader, error) {
+	if len(param.CertDataId) == 0 || len(param.KeyDataId) == 0 {
+		return nil, errors.New("[NewCertReloader] CertDataId and KeyDataId can not be empty")
+	}
+	if len(param.Group) == 0 {
+		param.Group = constant.DEFAULT_GROUP
+	}
+	r := &CertReloader{client: client, param: param, contents: make(map[string]string, 3)}
+	for _, dataId := range r.dataIds() {
+		content, err := client.GetConfig(vo.ConfigParam{DataId: dataId, Group: param.Group, NamespaceId: param.NamespaceId})
+		if err != nil {
+			return nil, errors.Wrapf(err, "get certificate config %s failed", dataId)
+		}
+		r.contents[dataId] = content
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	for _, dataId := range r.dataIds() {
+		if err := client.ListenConfig(vo.ConfigParam{
+			DataId:      dataId,
+			Group:       param.Group,
+			NamespaceId: param.NamespaceId,
+			OnChange:    r.onChange,
+		}); err != nil {
+			r.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *CertReloader) dataIds() []string {
+	dataIds := []string{r.param.CertDataId, r.param.KeyDataId}
+	if len(r.param.CaDataId) > 0 {
+		dataIds = append(dataIds, r.param.CaDataId)
+	}
+	return dataIds
+}
+
+func (r *CertReloader) onChange(namespace, group, dataId, data string) {
+	r.mux.Lock()
+	if r.contents[dataId] == data {
+		r.mux.Unlock()
+		return
+	}
+	r.contents[dataId] = data
+	r.mux.Unlock()
+	if err := r.reload(); err != nil {
+		// the certificate and private key may be rotated one after another, the previous pair is kept until both match
+		logger.Warnf("reload certificate from %s fail, the previous one is kept, err:%v", dataId, err)
+	}
+}
+
+// reload build the certificate from the current contents, the previous certificate is kept when they are invalid
+func (r *CertReloader) reload() (err error) {
+	r.mux.Lock()
+	defer func() {
+		cert := r.cert
+		r.mux.Unlock()
+		if r.param.OnReload != nil {
+			r.param.OnReload(cert, err)
+		}
+	}()
+	cert, err := tls.X509KeyPair([]byte(r.contents[r.param.CertDataId]), []byte(r.contents[r.param.KeyDataId]))
+	if err != nil {
+		return errors.Wrapf(err, "invalid certificate %s or private key %s", r.param.CertDataId, r.param.KeyDataId)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return errors.Wrapf(err, "invalid certificate %s", r.param.CertDataId)
+	}
+	cert.Leaf = leaf
+	var caPool *x509.CertPool
+	if len(r.param.CaDataId) > 0 {
+		caPool = x509.NewCertPool()
+		if !caPool.AppendCertsFromPEM([]byte(r.contents[r.param.CaDataId])) {
+			return errors.Errorf("invalid CA bundle %s", r.param.CaDataId)
+		}
+	}
+	r.cert, r.caPool, r.notAfter = &cert, caPool, leaf.NotAfter
+	monitor.GetCertExpiryMonitor(r.param.CertDataId).Set(float64(leaf.NotAfter.Unix()))
+	logger.Infof("certificate %s loaded, subject:%s, notAfter:%s", r.param.CertDataId, leaf.Subject, leaf.NotAfter)
+	return nil
+}
+
+// Certificate return the current certificate
+func (r *CertReloader) Certificate() *tls.Certificate {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	return r.cert
+}
+
+// CAPool return the current CA pool, it's nil when CaDataId is not set
+func (r *CertReloader) CAPool() *x509.CertPool {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	return r.caPool
+}
+
+// NotAfter return the expiry time of the current certificate
+func (r *CertReloader) NotAfter() time.Time {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	return r.notAfter
+}
+
+// GetCertificate can be used as tls.Config.GetCertificate
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+// GetClientCertificate can be used as tls.Config.GetClientCertificate
+func (r *CertReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.Certificate(), nil
+}
+
+// ServerTLSConfig return the tls.Config of server, client certificates are verified by the current CA pool
+// according to clientAuth when CaDataId is set
+func (r *CertReloader) ServerTLSConfig(clientAuth tls.ClientAuthType) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.Certificate()},
+				ClientAuth:   clientAuth,
+				ClientCAs:    r.CAPool(),
+			}, nil
+		},
+	}
+}
+
+// ClientTLSConfig return the tls.Config of client presenting the current certificate, the server certificate is
+// verified by the current CA pool when CaDataId is set, otherwise by the system roots
+func (r *CertReloader) ClientTLSConfig(serverName string) *tls.Config {
+	config := &tls.Config{
+		MinVersion:           tls.VersionTLS12,
+		ServerName:           serverName,
+		GetClientCertificate: r.GetClientCertificate,
+	}
+	if len(r.param.CaDataId) == 0 {
+		return config
+	}
+	// the CA pool is rotated as well, so the verification is done against the pool of each handshake
+	config.InsecureSkipVerify = true
+	config.VerifyConnection = func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return errors.New("no certificate presented by server")
+		}
+		opts := x509.VerifyOptions{
+			Roots:         r.CAPool(),
+			DNSName:       state.ServerName,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, cert := range state.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := state.PeerCertificates[0].Verify(opts)
+		return err
+	}
+	return config
+}
+
+// Close stop listening the configs of certificate
+func (r *CertReloader) Close() {
+	for _, dataId := range r.dataIds() {
+		_ = r.client.CancelListenConfig(vo.ConfigParam{DataId: dataId, Group: r.param.Group, NamespaceId: r.param.NamespaceId})
+	}
+}
@@ -0,0 +1,223 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/file"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+const (
+	defaultAckTimeout     = 10 * time.Second
+	defaultAckMaxAttempts = 10
+	ackPendingDir         = "ack-pending"
+)
+
+// ackListener deliver the change to OnChangeAck until it's acknowledged, only the latest change of the config is
+// pending, and it's persisted so that it survives the restart of the client
+type ackListener struct {
+	client      *ConfigClient
+	param       vo.ConfigParam
+	onChange    func(delivery *model.ConfigDelivery)
+	timeout     time.Duration
+	maxAttempts int
+	clock       clock.Clock
+	path        string
+	closed      <-chan struct{}
+
+	mutex   sync.Mutex
+	pending *model.ConfigDelivery
+	timer   clock.Timer
+}
+
+// ackRecord is the unacknowledged delivery persisted, the content is not written to disk since it may be the
+// decrypted content of cipher- config, it's read again after the restart and matched by md5
+type ackRecord struct {
+	NamespaceId string `json:"namespaceId"`
+	Group       string `json:"group"`
+	DataId      string `json:"dataId"`
+	Md5         string `json:"md5"`
+	Attempt     int    `json:"attempt"`
+}
+
+// ackOrdinals count the OnChangeAck listeners of each config, the ordinal is the id of the listener without AckId
+type ackOrdinals struct {
+	mutex sync.Mutex
+	next  map[string]int
+}
+
+func (o *ackOrdinals) take(cacheKey string) int {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.next == nil {
+		o.next = make(map[string]int)
+	}
+	ordinal := o.next[cacheKey]
+	o.next[cacheKey]++
+	return ordinal
+}
+
+// newAckListener adapt OnChangeAck of param into OnChangeErr, the change persisted but not acknowledged before
+// the restart is delivered again
+func (client *ConfigClient) newAckListener(param vo.ConfigParam) func(namespace, group, dataId, data string) error {
+	cacheKey := util.GetConfigCacheKey(param.DataId, param.Group, client.tenantOf(param))
+	id := param.AckId
+	if len(id) == 0 {
+		id = strconv.Itoa(client.ackOrdinals.take(cacheKey))
+	}
+	l := &ackListener{
+		client:      client,
+		param:       param,
+		onChange:    param.OnChangeAck,
+		timeout:     param.AckTimeout,
+		maxAttempts: param.AckMaxAttempts,
+		clock:       client.clock,
+		path:        filepath.Join(client.configCacheDir, ackPendingDir, cacheKey+constant.CONFIG_INFO_SPLITER+url.PathEscape(id)),
+		closed:      client.ctx.Done(),
+	}
+	if l.timeout <= 0 {
+		l.timeout = defaultAckTimeout
+	}
+	if l.maxAttempts == 0 {
+		l.maxAttempts = defaultAckMaxAttempts
+	}
+	if data, err := ioutil.ReadFile(l.path); err == nil {
+		record := &ackRecord{}
+		if err = json.Unmarshal(data, record); err == nil {
+			go l.recover(record)
+		}
+	}
+	return func(namespace, group, dataId, data string) error {
+		l.deliver(&model.ConfigDelivery{NamespaceId: namespace, Group: group, DataId: dataId, Content: data})
+		return nil
+	}
+}
+
+// recover deliver the persisted change again when the config is still the same, the newer change is delivered by
+// the listening instead
+func (l *ackListener) recover(record *ackRecord) {
+	content, err := l.client.GetConfig(vo.ConfigParam{DataId: record.DataId, Group: record.Group, NamespaceId: l.param.NamespaceId})
+	if err != nil {
+		logger.Warnf("read the unacknowledged change of config dataId:%s group:%s tenant:%s failed, err:%v",
+			record.DataId, record.Group, record.NamespaceId, err)
+		return
+	}
+	if util.Md5(content) != record.Md5 {
+		logger.Infof("the unacknowledged change of config dataId:%s group:%s tenant:%s is replaced by a newer one",
+			record.DataId, record.Group, record.NamespaceId)
+		l.removePersisted()
+		return
+	}
+	logger.Infof("deliver the unacknowledged change of config dataId:%s group:%s tenant:%s again",
+		record.DataId, record.Group, record.NamespaceId)
+	l.deliver(&model.ConfigDelivery{NamespaceId: record.NamespaceId, Group: record.Group, DataId: record.DataId,
+		Content: content, Attempt: record.Attempt})
+}
+
+// deliver replace the pending delivery with the change and deliver it
+func (l *ackListener) deliver(delivery *model.ConfigDelivery) {
+	delivery.Ack = func() {
+		l.ack(delivery)
+	}
+	l.mutex.Lock()
+	if l.timer != nil {
+		l.timer.Stop()
+	}
+	l.pending = delivery
+	l.mutex.Unlock()
+	l.attempt(delivery)
+}
+
+// attempt deliver the pending delivery once more, and schedule the next attempt unless it's acknowledged
+func (l *ackListener) attempt(delivery *model.ConfigDelivery) {
+	l.mutex.Lock()
+	if l.pending != delivery {
+		l.mutex.Unlock()
+		return
+	}
+	if l.maxAttempts > 0 && delivery.Attempt >= l.maxAttempts {
+		l.pending = nil
+		l.mutex.Unlock()
+		logger.Errorf("give up the change of config dataId:%s group:%s tenant:%s, it's not acknowledged after %d attempts",
+			delivery.DataId, delivery.Group, delivery.NamespaceId, delivery.Attempt)
+		l.removePersisted()
+		return
+	}
+	delivery.Attempt++
+	l.persist(delivery)
+	l.timer = l.clock.AfterFunc(l.timeout, func() {
+		select {
+		case <-l.closed:
+		default:
+			l.attempt(delivery)
+		}
+	})
+	attempt := *delivery
+	l.mutex.Unlock()
+	if err := callListener(func() error {
+		l.onChange(&attempt)
+		return nil
+	}); err != nil {
+		logger.Warnf("deliver the change of config dataId:%s group:%s tenant:%s failed, attempt:%d, err:%v",
+			delivery.DataId, delivery.Group, delivery.NamespaceId, attempt.Attempt, err)
+	}
+}
+
+func (l *ackListener) ack(delivery *model.ConfigDelivery) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.pending != delivery {
+		return
+	}
+	l.pending = nil
+	if l.timer != nil {
+		l.timer.Stop()
+	}
+	l.removePersisted()
+}
+
+// persist must be called with mutex held, so that the acknowledged delivery is never persisted afterwards
+func (l *ackListener) persist(delivery *model.ConfigDelivery) {
+	data, err := json.Marshal(ackRecord{NamespaceId: delivery.NamespaceId, Group: delivery.Group, DataId: delivery.DataId,
+		Md5: util.Md5(delivery.Content), Attempt: delivery.Attempt})
+	if err == nil {
+		err = file.WriteFileAtomic(l.path, data, 0600)
+	}
+	if err != nil {
+		logger.Warnf("persist the unacknowledged change of config dataId:%s failed, err:%v", delivery.DataId, err)
+	}
+}
+
+func (l *ackListener) removePersisted() {
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		logger.Warnf("remove the acknowledged change %s failed, err:%v", l.path, err)
+	}
+}
@@ -0,0 +1,33 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"github.com/nacos-group/nacos-sdk-go/v2/common/audit"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+)
+
+// audit record the operation on the config in the audit trail, target is the md5 of the published content
+func (client *ConfigClient) audit(op, tenant, group, dataId, target string, done bool, err error) {
+	if client.auditor == nil {
+		return
+	}
+	record := audit.Record{Op: op, Namespace: tenant, Group: group, Key: dataId, Target: target}
+	if auditErr := client.auditor.Record(record, done, err); auditErr != nil {
+		logger.With(logger.ConfigContext(tenant, group, dataId)).Warnf("write audit record of %s failed, err:%v", op, auditErr)
+	}
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// PublishBinaryConfig publish the binary data such as certificates and keystores, the data is encoded as base64
+// content marked by constant.BINARY_CONTENT_PREFIX, the Content of param is ignored
+func (client *ConfigClient) PublishBinaryConfig(param vo.ConfigParam, data []byte) (bool, error) {
+	param.Content = util.EncodeBinaryContent(data)
+	return client.PublishConfig(param)
+}
+
+// GetBinaryConfig get the binary data published by PublishBinaryConfig,
+// the content which is not marked as binary is returned as is
+func (client *ConfigClient) GetBinaryConfig(param vo.ConfigParam) ([]byte, error) {
+	content, err := client.GetConfig(param)
+	if err != nil {
+		return nil, err
+	}
+	return util.DecodeBinaryContent(content)
+}
+
+// binaryListener adapt OnBinaryChange to the listener of content, the content failed to decode is not delivered
+func binaryListener(onBinaryChange func(namespace, group, dataId string, data []byte)) func(namespace, group, dataId, data string) {
+	return func(namespace, group, dataId, content string) {
+		data, err := util.DecodeBinaryContent(content)
+		if err != nil {
+			logger.Errorf("decode binary config fail, dataId=%s, group=%s, tenant=%s, err:%v", dataId, group, namespace, err)
+			return
+		}
+		onBinaryChange(namespace, group, dataId, data)
+	}
+}
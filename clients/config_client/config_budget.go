@@ -0,0 +1,168 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/cache"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+)
+
+// contentBudget bounds the total size of the content of cached configs, the least recently used contents over
+// budget are evicted with md5 kept, and read again from snapshot or server on access
+type contentBudget struct {
+	mux       sync.Mutex
+	limit     int
+	used      int
+	evictions int
+	lru       *list.List // of *budgetEntry, the most recently used one at front
+	items     map[string]*list.Element
+}
+
+type budgetEntry struct {
+	key  string
+	md5  string
+	size int
+}
+
+type contentBudgetSnapshot struct {
+	Limit     int `json:"limit"`
+	Used      int `json:"used"`
+	Count     int `json:"count"`
+	Evictions int `json:"evictions"`
+}
+
+// newContentBudget return nil when limit is not positive, which means no limit
+func newContentBudget(limit int) *contentBudget {
+	if limit <= 0 {
+		return nil
+	}
+	return &contentBudget{limit: limit, lru: list.New(), items: make(map[string]*list.Element)}
+}
+
+// use record the access of the content and return the entries evicted, the one just used is kept even if
+// it's over budget alone
+func (b *contentBudget) use(key, md5 string, size int) []budgetEntry {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if e, ok := b.items[key]; ok {
+		entry := e.Value.(*budgetEntry)
+		b.used += size - entry.size
+		entry.md5, entry.size = md5, size
+		b.lru.MoveToFront(e)
+	} else {
+		b.items[key] = b.lru.PushFront(&budgetEntry{key: key, md5: md5, size: size})
+		b.used += size
+	}
+	var evicted []budgetEntry
+	for b.used > b.limit && b.lru.Len() > 1 {
+		entry := b.lru.Remove(b.lru.Back()).(*budgetEntry)
+		delete(b.items, entry.key)
+		b.used -= entry.size
+		b.evictions++
+		evicted = append(evicted, *entry)
+	}
+	return evicted
+}
+
+func (b *contentBudget) remove(key string) {
+	if b == nil {
+		return
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if e, ok := b.items[key]; ok {
+		b.used -= b.lru.Remove(e).(*budgetEntry).size
+		delete(b.items, key)
+	}
+}
+
+func (b *contentBudget) snapshot() *contentBudgetSnapshot {
+	if b == nil {
+		return nil
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return &contentBudgetSnapshot{Limit: b.limit, Used: b.used, Count: b.lru.Len(), Evictions: b.evictions}
+}
+
+// useContent record the access of the content of cached config, and evict the least recently used contents
+// over budget. The tombstones of deleted configs are not evicted since they can't be read again
+func (client *ConfigClient) useContent(data cacheData) {
+	if client.contentBudget == nil || data.evicted {
+		return
+	}
+	if data.deleted {
+		client.contentBudget.remove(data.cacheKey)
+		return
+	}
+	for _, entry := range client.contentBudget.use(data.cacheKey, data.md5, len(data.content)) {
+		entry := entry
+		// the content changed since it's used is accounted again by its own use
+		client.updateCacheData(entry.key, func(d *cacheData) {
+			if !d.evicted && !d.deleted && d.md5 == entry.md5 {
+				d.content, d.evicted = "", true
+			}
+		})
+		logger.Debugf("[config_budget] evict the content of %s, size=%d", entry.key, entry.size)
+	}
+}
+
+// contentOf return the content of cached config, the evicted content is read again and cached
+func (client *ConfigClient) contentOf(data cacheData) (string, error) {
+	if !data.evicted || data.deleted {
+		return data.content, nil
+	}
+	content, err := client.loadContent(data)
+	if err != nil {
+		return "", err
+	}
+	if contentMd5(content) == data.md5 {
+		client.updateCacheData(data.cacheKey, func(d *cacheData) {
+			if d.evicted && d.md5 == data.md5 {
+				d.content, d.evicted = content, false
+			}
+		})
+		data.content, data.evicted = content, false
+		client.useContent(data)
+	}
+	return content, nil
+}
+
+// loadContent read the evicted content from snapshot, or from server when the snapshot is missing or stale
+func (client *ConfigClient) loadContent(data cacheData) (string, error) {
+	if content, ok := client.snapshotContent(data); ok {
+		return content, nil
+	}
+	response, err := client.configProxy.QueryConfig(data.dataId, data.group, data.tenant,
+		client.requestTimeoutMills(data.listeners), false, client)
+	if err != nil {
+		return "", errors.Wrapf(err, "load evicted content dataId=%s, group=%s, tenant=%s failed",
+			data.dataId, data.group, data.tenant)
+	}
+	return response.Content, nil
+}
+
+// snapshotContent read the content from snapshot, it's ok only when the md5 matches the cached config
+func (client *ConfigClient) snapshotContent(data cacheData) (string, bool) {
+	content, err := cache.ReadConfigFromFile(data.cacheKey, client.configCacheDir)
+	return content, err == nil && contentMd5(content) == data.md5
+}
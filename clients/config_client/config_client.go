@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -27,12 +28,19 @@ import (
 	"github.com/aliyun/alibaba-cloud-sdk-go/services/kms"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/cache"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/nacos_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/audit"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/file"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/introspection"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/monitor"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/quota"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_request"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_response"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/security"
 	"github.com/nacos-group/nacos-sdk-go/v2/inner/uuid"
 	"github.com/nacos-group/nacos-sdk-go/v2/model"
 	"github.com/nacos-group/nacos-sdk-go/v2/util"
@@ -41,59 +49,211 @@ import (
 )
 
 const (
-	perTaskConfigSize = 3000
-	executorErrDelay  = 5 * time.Second
+	executorErrDelay = 5 * time.Second
+	emptyContentMd5  = "d41d8cd98f00b204e9800998ecf8427e"
+	// minRequestTimeout is the lower bound of the timeout derived from deadline
+	minRequestTimeout = 100 * time.Millisecond
+	// listenContextOverhead is the size of json keys and punctuation of each serialized listen context
+	listenContextOverhead = len(`{"group":"","md5":"","dataId":"","tenant":""},`)
 )
 
 type ConfigClient struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 	nacos_client.INacosClient
-	kmsClient       *kms.Client
-	localConfigs    []vo.ConfigParam
-	mutex           sync.Mutex
-	configProxy     IConfigProxy
-	configCacheDir  string
-	lastAllSyncTime time.Time
-	cacheMap        cache.ConcurrentMap
-	uid             string
-	listenExecute   chan struct{}
+	kmsClient          *kms.Client
+	localConfigs       []vo.ConfigParam
+	mutex              sync.Mutex
+	configProxy        IConfigProxy
+	configCacheDir     string
+	lastAllSyncTime    time.Time
+	cacheMap           cache.ConcurrentMap
+	uid                string
+	listenExecute      chan struct{}
+	signCfg            *constant.ConfigSignConfig
+	contentSigner      *security.ContentSigner
+	contentVerifier    *security.ContentVerifier
+	signatures         listenedSignatures
+	errorRecorder      introspection.ErrorRecorder
+	listenStatus       sync.Map
+	publishIdempotency publishIdempotency
+	writeQueue         configWriteQueue
+	subscriptions      *configSubscriptions
+	fetchConcurrency   int
+	clock              clock.Clock
+	listenerBreakerCfg *constant.ListenerBreakerConfig
+	// listenBatchSize is the number of configs sharing a listen task, each task has its own connection
+	listenBatchSize int
+	// listenBatchMaxBytes caps the serialized listen contexts of each request, 0 means no cap
+	listenBatchMaxBytes int
+	// contentBudget bounds the memory of the cached content, nil means no limit
+	contentBudget *contentBudget
+	// quota enforces the client-side quotas, nil means no limit
+	quota *quota.Guard
+	// auditor records the mutating operations, nil means disabled
+	auditor *audit.Recorder
+	// ackOrdinals identify the OnChangeAck listeners without AckId
+	ackOrdinals ackOrdinals
+	// listenMutex serialize adding the configs to cacheMap, so that they are counted against the quota atomically
+	listenMutex sync.Mutex
 }
 
 type cacheData struct {
-	isInitializing    bool
-	dataId            string
-	group             string
-	content           string
-	contentType       string
-	tenant            string
-	cacheDataListener *cacheDataListener
-	md5               string
-	appName           string
-	taskId            int
-	configClient      *ConfigClient
-	isSyncWithServer  bool
+	// cacheKey is built once when the config is listened instead of on every listen cycle
+	cacheKey         string
+	isInitializing   bool
+	dataId           string
+	group            string
+	content          string
+	contentType      string
+	tenant           string
+	listeners        *cacheDataListeners
+	md5              string
+	appName          string
+	taskId           int
+	configClient     *ConfigClient
+	isSyncWithServer bool
+	// deleted means the config does not exist on server, either not created yet or removed
+	deleted bool
+	// evicted means the content is evicted by ConfigMemoryBudget with md5 kept, see contentOf
+	evicted bool
+	// changedAt is the time the config was changed on server, it's set only on the copy refreshed by listening
+	// and never stored, so the lag of the listeners added later is not observed
+	changedAt time.Time
 }
 
-type cacheDataListener struct {
-	listener vo.Listener
-	lastMd5  string
+// The cached configs are immutable values, every change is applied to a copy which is swapped into cacheMap
+// under the lock of its shard. The listeners are shared by the copies and guarded by their own mutex.
+
+// removeCacheDataListener remove the listener, and the cached config when no listener remains. The config may be
+// listened again or canceled meanwhile, so only the entry holding the listeners is changed
+func (client *ConfigClient) removeCacheDataListener(key string, listeners *cacheDataListeners, listener *cacheDataListener) {
+	removed := client.cacheMap.RemoveCb(key, func(key string, v interface{}, exists bool) bool {
+		return exists && v.(cacheData).listeners == listeners && listeners.remove(listener) == 0
+	})
+	if removed {
+		client.contentBudget.remove(key)
+		client.signatures.remove(key)
+	}
+}
+
+// updateCacheData apply update to a copy of the cached config and swap it in, the config canceled meanwhile
+// is not put back. It returns false if the config is not listened
+func (client *ConfigClient) updateCacheData(key string, update func(data *cacheData)) bool {
+	return client.cacheMap.Update(key, func(v interface{}) interface{} {
+		data := v.(cacheData)
+		update(&data)
+		return data
+	})
+}
+
+// addCacheDataListener add the listener to the cached config, or cache the config if it's not listened yet
+// addCacheDataListener fail with QuotaExceededError when the config is not listened yet and MaxListenedKeys is reached
+func (client *ConfigClient) addCacheDataListener(key string, param vo.ConfigParam, tenant string, listener *cacheDataListener) (cacheData, error) {
+	if listener.breaker == nil {
+		listener.breaker = newListenerBreaker(client.listenerBreakerCfg, client.clock, tenant, param.Group, param.DataId)
+	}
+	listener.clock = client.clock
+	// built outside of the lock of map since it reads the local cache file and counts the map
+	newData := client.newCacheData(key, param, tenant, listener)
+	// the configs are counted and added under listenMutex, so the concurrent listens can't exceed MaxListenedKeys
+	client.listenMutex.Lock()
+	defer client.listenMutex.Unlock()
+	if _, ok := client.cacheMap.Get(key); !ok {
+		if err := client.quota.CheckListenedKeys(key, client.cacheMap.Count()+1); err != nil {
+			return cacheData{}, err
+		}
+	}
+	data := client.cacheMap.Upsert(key, newData, func(exist bool, valueInMap interface{}, newValue interface{}) interface{} {
+		if !exist {
+			return newValue
+		}
+		data := valueInMap.(cacheData)
+		data.isInitializing = true
+		if !listener.deliverCurrent {
+			listener.setLastMd5(data.md5)
+		}
+		data.listeners.add(listener)
+		return data
+	}).(cacheData)
+	client.useContent(data)
+	return data, nil
+}
+
+// log return the logger adding the namespace, group and dataId of the config to the lines
+func (cacheData *cacheData) log() logger.Logger {
+	return logger.With(logger.ConfigContext(cacheData.tenant, cacheData.group, cacheData.dataId))
+}
+
+// needNotify return true when any listener has not been notified with the current md5
+func (cacheData *cacheData) needNotify() bool {
+	for _, l := range cacheData.listeners.items() {
+		if l.getLastMd5() != cacheData.md5 {
+			return true
+		}
+	}
+	return false
 }
 
 func (cacheData *cacheData) executeListener() {
-	cacheData.cacheDataListener.lastMd5 = cacheData.md5
-	cacheData.configClient.cacheMap.Set(util.GetConfigCacheKey(cacheData.dataId, cacheData.group, cacheData.tenant), *cacheData)
+	var (
+		listeners []*cacheDataListener
+		lastMd5s  []string
+	)
+	for _, l := range cacheData.listeners.items() {
+		if last := l.getLastMd5(); last != cacheData.md5 {
+			l.setLastMd5(cacheData.md5)
+			listeners = append(listeners, l)
+			lastMd5s = append(lastMd5s, last)
+		}
+	}
+	changedAt := cacheData.changedAt
 
-	decryptedContent, err := cacheData.configClient.decrypt(cacheData.dataId, cacheData.content)
+	content, err := cacheData.configClient.contentOf(*cacheData)
 	if err != nil {
-		logger.Errorf("decrypt content fail ,dataId=%s,group=%s,tenant=%s,err:%+v ", cacheData.dataId,
+		cacheData.log().Errorf("load content fail ,dataId=%s,group=%s,tenant=%s,err:%+v ", cacheData.dataId,
 			cacheData.group, cacheData.tenant, err)
+		cacheData.configClient.errorRecorder.Record(err)
 		return
 	}
-	go cacheData.cacheDataListener.listener(cacheData.tenant, cacheData.group, cacheData.dataId, decryptedContent)
+	decryptedContent, err := cacheData.configClient.decrypt(cacheData.dataId, content)
+	if cacheData.deleted {
+		if err != nil {
+			// the deletion is still delivered, only the last known content is lost
+			cacheData.log().Errorf("decrypt last content of deleted config fail ,dataId=%s,group=%s,tenant=%s,err:%+v ",
+				cacheData.dataId, cacheData.group, cacheData.tenant, err)
+		}
+		for _, l := range listeners {
+			l.deliverEvent(&listenerEvent{namespace: cacheData.tenant, group: cacheData.group, dataId: cacheData.dataId,
+				content: decryptedContent, deleted: true, changedAt: changedAt})
+		}
+		return
+	}
+	if err != nil {
+		cacheData.log().Errorf("decrypt content fail ,dataId=%s,group=%s,tenant=%s,err:%+v ", cacheData.dataId,
+			cacheData.group, cacheData.tenant, err)
+		return
+	}
+	if !cacheData.configClient.verifyListened(cacheData, decryptedContent) {
+		// notified again in the following listen cycles until the signature arrives
+		for i, l := range listeners {
+			l.restoreLastMd5(cacheData.md5, lastMd5s[i])
+		}
+		return
+	}
+	for _, l := range listeners {
+		l.deliverEvent(&listenerEvent{namespace: cacheData.tenant, group: cacheData.group, dataId: cacheData.dataId,
+			content: decryptedContent, changedAt: changedAt})
+	}
 }
 
 func NewConfigClient(nc nacos_client.INacosClient) (*ConfigClient, error) {
+	return NewConfigClientWithProxy(nc, NewConfigProxy)
+}
+
+// NewConfigClientWithProxy create the config client sending requests through the proxy created by newProxy,
+// instead of the grpc proxy of NewConfigProxy
+func NewConfigClientWithProxy(nc nacos_client.INacosClient, newProxy ConfigProxyFactory) (*ConfigClient, error) {
 	config := &ConfigClient{}
 	config.ctx, config.cancel = context.WithCancel(context.Background())
 	config.INacosClient = nc
@@ -110,13 +270,34 @@ func NewConfigClient(nc nacos_client.INacosClient) (*ConfigClient, error) {
 		return nil, err
 	}
 
+	if clientConfig.FilePermCfg != nil {
+		file.SetPermConfig(clientConfig.FilePermCfg)
+	}
+	dirErrs := file.PrepareDirs(clientConfig.CacheDir, clientConfig.LogDir)
 	if err = initLogger(clientConfig); err != nil {
 		return nil, err
 	}
+	for _, dirErr := range dirErrs {
+		logger.Warnf("%v", dirErr)
+	}
+	if clientConfig.IpDetectCfg != nil {
+		util.SetIpDetectConfig(clientConfig.IpDetectCfg)
+	}
 	clientConfig.CacheDir = clientConfig.CacheDir + string(os.PathSeparator) + "config"
 	config.configCacheDir = clientConfig.CacheDir
+	cache.RepairConfigCache(config.configCacheDir)
+	config.fetchConcurrency = clientConfig.ConfigFetchThreadNum
+	config.listenBatchSize = listenBatchSize(clientConfig.ListenBatchSize)
+	config.listenBatchMaxBytes = clientConfig.ListenMaxBytes
+	config.contentBudget = newContentBudget(clientConfig.ConfigMemoryBudget)
+	config.quota = quota.NewGuard(clientConfig.QuotaCfg)
+	config.clock = clock.OrReal(clientConfig.Clock)
+	config.publishIdempotency.clock = config.clock
+	config.auditor = audit.NewRecorder(clientConfig.AuditSink, clientConfig.Identity(), clientConfig.AppName, config.clock)
+	config.listenerBreakerCfg = clientConfig.ListenerBreakerCfg
+	config.subscriptions = newConfigSubscriptions(clientConfig.PersistSubscriptions, config.configCacheDir, clientConfig.NamespaceId)
 
-	if config.configProxy, err = NewConfigProxy(config.ctx, serverConfig, clientConfig, httpAgent); err != nil {
+	if config.configProxy, err = newProxy(config.ctx, serverConfig, clientConfig, httpAgent); err != nil {
 		return nil, err
 	}
 
@@ -128,6 +309,10 @@ func NewConfigClient(nc nacos_client.INacosClient) (*ConfigClient, error) {
 		config.kmsClient = kmsClient
 	}
 
+	if err = config.initContentSign(clientConfig.ConfigSignCfg); err != nil {
+		return nil, err
+	}
+
 	uid, err := uuid.NewV4()
 	if err != nil {
 		return nil, err
@@ -137,6 +322,7 @@ func NewConfigClient(nc nacos_client.INacosClient) (*ConfigClient, error) {
 	config.cacheMap = cache.NewConcurrentMap()
 	config.listenExecute = make(chan struct{})
 	config.startInternal()
+	introspection.Register(config)
 	return config, err
 }
 
@@ -145,11 +331,50 @@ func initLogger(clientConfig constant.ClientConfig) error {
 }
 
 func (client *ConfigClient) GetConfig(param vo.ConfigParam) (content string, err error) {
-	content, err = client.getConfigInner(param)
+	if len(param.FallbackGroups) > 0 {
+		content, _, err = client.getConfigFallback(param)
+		return content, err
+	}
+	tenant := client.tenantOf(param)
+	content, err = client.getConfigInner(param, tenant)
 	if err != nil {
 		return "", err
 	}
-	return client.decrypt(param.DataId, content)
+	if content, err = client.decrypt(param.DataId, content); err != nil {
+		return "", err
+	}
+	param.Group = client.GroupOf(param.DataId, param.Group)
+	if err = client.verifyContent(tenant, param.Group, param.DataId, content); err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+// GroupOf return the group of the config, it's resolved by ClientConfig.GroupResolver and then ClientConfig.DefaultGroup
+// when the param doesn't give it, DEFAULT_GROUP is the last resort
+func (client *ConfigClient) GroupOf(dataId, group string) string {
+	if len(group) > 0 {
+		return group
+	}
+	clientConfig, _ := client.GetClientConfig()
+	if clientConfig.GroupResolver != nil && len(dataId) > 0 {
+		if group = clientConfig.GroupResolver(dataId); len(group) > 0 {
+			return group
+		}
+	}
+	if len(clientConfig.DefaultGroup) > 0 {
+		return clientConfig.DefaultGroup
+	}
+	return constant.DEFAULT_GROUP
+}
+
+// tenantOf return the namespace of the config, it's the namespace of client unless the param overrides it
+func (client *ConfigClient) tenantOf(param vo.ConfigParam) string {
+	if len(param.NamespaceId) > 0 {
+		return param.NamespaceId
+	}
+	clientConfig, _ := client.GetClientConfig()
+	return clientConfig.NamespaceId
 }
 
 func (client *ConfigClient) decrypt(dataId, content string) (string, error) {
@@ -185,45 +410,105 @@ func (client *ConfigClient) encrypt(dataId, content string) (string, error) {
 	return content, nil
 }
 
-func (client *ConfigClient) getConfigInner(param vo.ConfigParam) (content string, err error) {
+// getConfigInner get the config of the tenant from server, the failover and snapshot of the tenant are used when needed
+func (client *ConfigClient) getConfigInner(param vo.ConfigParam, tenant string) (content string, err error) {
 	if len(param.DataId) <= 0 {
 		err = errors.New("[client.GetConfig] param.dataId can not be empty")
 		return "", err
 	}
-	if len(param.Group) <= 0 {
-		param.Group = constant.DEFAULT_GROUP
-	}
+	param.Group = client.GroupOf(param.DataId, param.Group)
 
-	clientConfig, _ := client.GetClientConfig()
-	cacheKey := util.GetConfigCacheKey(param.DataId, param.Group, clientConfig.NamespaceId)
+	cacheKey := util.GetConfigCacheKey(param.DataId, param.Group, tenant)
 	content = cache.GetFailover(cacheKey, client.configCacheDir)
 	if len(content) > 0 {
-		logger.Warnf("%s %s %s is using failover content!", clientConfig.NamespaceId, param.Group, param.DataId)
+		logger.With(logger.ConfigContext(tenant, param.Group, param.DataId)).Warnf("%s %s %s is using failover content!", tenant, param.Group, param.DataId)
+		if err = client.quota.CheckContentSize(param.DataId, len(content)); err != nil {
+			return "", err
+		}
 		return content, nil
 	}
-	response, err := client.configProxy.queryConfig(param.DataId, param.Group, clientConfig.NamespaceId,
-		clientConfig.TimeoutMs, false, client)
+	response, err := client.queryConfig(param, tenant)
 	if err != nil {
-		logger.Errorf("get config from server error:%v, dataId=%s, group=%s, namespaceId=%s", err,
-			param.DataId, param.Group, clientConfig.NamespaceId)
-
-		if clientConfig.DisableUseSnapShot {
-			return "", errors.Errorf("get config from remote nacos server fail, and is not allowed to read local file, err:%v", err)
+		logger.With(logger.ConfigContext(tenant, param.Group, param.DataId)).Errorf("get config from server error:%v, dataId=%s, group=%s, namespaceId=%s", err,
+			param.DataId, param.Group, tenant)
+		if err = client.snapshotAllowed(param, tenant, cacheKey, err); err != nil {
+			return "", err
 		}
 
 		cacheContent, cacheErr := cache.ReadConfigFromFile(cacheKey, client.configCacheDir)
 		if cacheErr != nil {
 			return "", errors.Errorf("read config from both server and cache fail, err=%v，dataId=%s, group=%s, namespaceId=%s",
-				cacheErr, param.DataId, param.Group, clientConfig.NamespaceId)
+				cacheErr, param.DataId, param.Group, tenant)
 		}
 
-		logger.Warnf("read config from cache success, dataId=%s, group=%s, namespaceId=%s", param.DataId, param.Group, clientConfig.NamespaceId)
+		if err = client.quota.CheckContentSize(param.DataId, len(cacheContent)); err != nil {
+			return "", err
+		}
+		logger.With(logger.ConfigContext(tenant, param.Group, param.DataId)).Warnf("read config from cache success, dataId=%s, group=%s, namespaceId=%s", param.DataId, param.Group, tenant)
 		return cacheContent, nil
 	}
+	if err = client.quota.CheckContentSize(param.DataId, len(response.Content)); err != nil {
+		return "", err
+	}
 	return response.Content, nil
 }
 
+// snapshotAllowed record the query error of the config and return nil when the snapshot can be read instead,
+// otherwise the error to return is given back
+func (client *ConfigClient) snapshotAllowed(param vo.ConfigParam, tenant, cacheKey string, err error) error {
+	client.errorRecorder.Record(errors.Wrapf(err, "get config dataId=%s, group=%s failed", param.DataId, param.Group))
+	if _, ok := err.(*nacos_error.PermissionDeniedError); ok {
+		return err
+	}
+	clientConfig, _ := client.GetClientConfig()
+	if clientConfig.DisableUseSnapShot {
+		return errors.Errorf("get config from remote nacos server fail, and is not allowed to read local file, err:%v", err)
+	}
+	if clientConfig.StrictRead {
+		if modTime, statErr := cache.ConfigCacheModTime(cacheKey, client.configCacheDir); statErr == nil {
+			return &nacos_error.StaleCacheError{DataId: param.DataId, Group: param.Group, Tenant: tenant,
+				Age: client.clock.Since(modTime), Err: err}
+		}
+	}
+	return nil
+}
+
+// queryConfig query the config from server with the timeout and priority of param
+func (client *ConfigClient) queryConfig(param vo.ConfigParam, tenant string) (*rpc_response.ConfigQueryResponse, error) {
+	timeout := param.TimeoutMs
+	if timeout == 0 {
+		clientConfig, _ := client.GetClientConfig()
+		timeout = clientConfig.TimeoutMs
+	}
+	if proxy, ok := client.configProxy.(IPriorityConfigProxy); ok {
+		return proxy.QueryConfigWithPriority(param.DataId, param.Group, tenant, timeout, false, param.Priority, client)
+	}
+	return client.configProxy.QueryConfig(param.DataId, param.Group, tenant, timeout, false, client)
+}
+
+// requestTimeout return the timeout of the publish and delete of param
+func requestTimeout(param vo.ConfigParam) uint64 {
+	if param.TimeoutMs > 0 {
+		return param.TimeoutMs
+	}
+	return constant.DEFAULT_TIMEOUT_MILLS
+}
+
 func (client *ConfigClient) PublishConfig(param vo.ConfigParam) (published bool, err error) {
+	return client.publishConfig(param, nil)
+}
+
+// PublishConfigWithResult publish the config and return the md5 and modified time on server, the CasMd5 used and
+// whether the config is created or updated. The config is queried before and after publishing to tell them
+func (client *ConfigClient) PublishConfigWithResult(param vo.ConfigParam) (model.PublishResult, error) {
+	var result model.PublishResult
+	published, err := client.publishConfig(param, &result)
+	result.Published = published
+	return result, err
+}
+
+// publishConfig publish the config, result is filled when it's not nil
+func (client *ConfigClient) publishConfig(param vo.ConfigParam, result *model.PublishResult) (published bool, err error) {
 	if len(param.DataId) <= 0 {
 		err = errors.New("[client.PublishConfig] param.dataId can not be empty")
 		return
@@ -232,118 +517,359 @@ func (client *ConfigClient) PublishConfig(param vo.ConfigParam) (published bool,
 		err = errors.New("[client.PublishConfig] param.content can not be empty")
 		return
 	}
+	if err = client.quota.CheckContentSize(param.DataId, len(param.Content)); err != nil {
+		return
+	}
 
-	if len(param.Group) <= 0 {
-		param.Group = constant.DEFAULT_GROUP
+	param.Group = client.GroupOf(param.DataId, param.Group)
+	if param.Stage {
+		param.DataId, param.Stage = client.stagingDataId(param.DataId), false
+	}
+	tenant := client.tenantOf(param)
+	// the content and its signature are written in the same turn, so that they always match each other
+	turn := client.writeQueue.acquire(util.GetConfigCacheKey(param.DataId, param.Group, tenant))
+	contentMd5 := util.Md5(param.Content)
+	defer func() {
+		ownErr := err
+		// the outcome of the publish queued before is reported along with this one
+		if err = turn.combine(err); err == nil && published && result != nil {
+			result.Overwrote = turn.overwrote(contentMd5)
+		}
+		turn.finish(contentMd5, published, ownErr)
+	}()
+	defer func() {
+		client.audit(audit.OpPublishConfig, tenant, param.Group, param.DataId, contentMd5, published, err)
+	}()
+	if result != nil {
+		// filled while the write turn is still held, the content and CasMd5 of param are the ones sent to server
+		defer func() {
+			if published && err == nil {
+				client.fillPublishResult(result, param, tenant)
+			}
+		}()
+	}
+	if len(param.IdempotencyKey) > 0 {
+		// checked and recorded in the write turn, the concurrent retries with the same key wait for the result
+		if published, err = client.checkPublishIdempotency(&param); published || err != nil {
+			return
+		}
+		defer func() {
+			client.recordPublish(param, contentMd5, published && err == nil)
+		}()
 	}
+	if result != nil {
+		result.Operation = client.publishOperation(param, tenant)
+	}
+	signature, err := client.signContent(tenant, param)
+	if err != nil {
+		return
+	}
+	plainParam := param
 	if param.Content, err = client.encrypt(param.DataId, param.Content); err != nil {
 		return
 	}
 
-	clientConfig, _ := client.GetClientConfig()
-	request := rpc_request.NewConfigPublishRequest(param.Group, param.DataId, clientConfig.NamespaceId, param.Content, param.CasMd5)
+	request := rpc_request.NewConfigPublishRequest(param.Group, param.DataId, tenant, param.Content, param.CasMd5)
 	request.AdditionMap["tag"] = param.Tag
 	request.AdditionMap["appName"] = param.AppName
 	request.AdditionMap["betaIps"] = param.BetaIps
 	request.AdditionMap["type"] = param.Type
 	request.AdditionMap["src_user"] = param.SrcUser
 	request.AdditionMap["encryptedDataKey"] = param.EncryptedDataKey
-	rpcClient := client.configProxy.getRpcClient(client)
-	response, err := client.configProxy.requestProxy(rpcClient, request, constant.DEFAULT_TIMEOUT_MILLS)
-	if response != nil {
-		return response.IsSuccess(), err
+	request.AdditionMap["desc"] = param.Desc
+	request.AdditionMap["use"] = param.Use
+	request.AdditionMap["effect"] = param.Effect
+	request.AdditionMap["schema"] = param.Schema
+	request.AdditionMap["config_tags"] = param.ConfigTags
+	rpcClient := client.configProxy.GetRpcClient(client)
+	response, err := client.configProxy.RequestProxy(rpcClient, request, requestTimeout(param))
+	if response == nil {
+		return false, err
 	}
-	return false, err
+	if published = response.IsSuccess(); !published || err != nil {
+		return published, err
+	}
+	// the content is published before its signature, the listeners hold it until the signature arrives
+	if err = client.publishSignature(plainParam, signature); err != nil {
+		err = errors.Wrapf(err, "content of dataId=%s, group=%s is published without signature", param.DataId, param.Group)
+	}
+	return published, err
 }
 
 func (client *ConfigClient) DeleteConfig(param vo.ConfigParam) (deleted bool, err error) {
 	if len(param.DataId) <= 0 {
 		err = errors.New("[client.DeleteConfig] param.dataId can not be empty")
 	}
-	if len(param.Group) <= 0 {
-		param.Group = constant.DEFAULT_GROUP
-	}
+	param.Group = client.GroupOf(param.DataId, param.Group)
 	if err != nil {
 		return false, err
 	}
-	clientConfig, _ := client.GetClientConfig()
-	request := rpc_request.NewConfigRemoveRequest(param.Group, param.DataId, clientConfig.NamespaceId)
-	rpcClient := client.configProxy.getRpcClient(client)
-	response, err := client.configProxy.requestProxy(rpcClient, request, constant.DEFAULT_TIMEOUT_MILLS)
+	tenant := client.tenantOf(param)
+	defer client.writeQueue.acquire(util.GetConfigCacheKey(param.DataId, param.Group, tenant)).release()
+	defer func() {
+		client.audit(audit.OpDeleteConfig, tenant, param.Group, param.DataId, "", deleted, err)
+	}()
+	request := rpc_request.NewConfigRemoveRequest(param.Group, param.DataId, tenant)
+	rpcClient := client.configProxy.GetRpcClient(client)
+	response, err := client.configProxy.RequestProxy(rpcClient, request, requestTimeout(param))
+	if err == nil {
+		client.deleteSignature(param)
+	}
 	if response != nil {
 		return response.IsSuccess(), err
 	}
 	return false, err
 }
 
-// Cancel Listen Config
+// CancelListenConfig remove the listeners added by ListenConfig, the config is no longer listened once no listener remains
 func (client *ConfigClient) CancelListenConfig(param vo.ConfigParam) (err error) {
-	clientConfig, err := client.GetClientConfig()
-	if err != nil {
+	if _, err = client.GetClientConfig(); err != nil {
 		logger.Errorf("[checkConfigInfo.GetClientConfig] failed,err:%+v", err)
 		return
 	}
-	client.cacheMap.Remove(util.GetConfigCacheKey(param.DataId, param.Group, clientConfig.NamespaceId))
-	logger.Infof("Cancel listen config DataId:%s Group:%s", param.DataId, param.Group)
+	for _, group := range client.groupChain(param) {
+		key := util.GetConfigCacheKey(param.DataId, group, client.tenantOf(param))
+		// the streams bound to a context, such as Tail, are kept until their context is done
+		removed := client.cacheMap.RemoveCb(key, func(key string, v interface{}, exists bool) bool {
+			return exists && v.(cacheData).listeners.removeCancelable() == 0
+		})
+		if removed {
+			client.contentBudget.remove(key)
+			client.signatures.remove(key)
+		}
+		client.subscriptions.remove(key)
+		logger.With(logger.ConfigContext(client.tenantOf(param), group, param.DataId)).Infof("Cancel listen config DataId:%s Group:%s", param.DataId, group)
+	}
 	return err
 }
 
 func (client *ConfigClient) ListenConfig(param vo.ConfigParam) (err error) {
-	if len(param.DataId) <= 0 {
-		err = errors.New("[client.ListenConfig] DataId can not be empty")
+	if len(param.FallbackGroups) > 0 {
+		return client.listenConfigFallback(param)
+	}
+	if err = client.checkListenParam("ListenConfig", &param); err != nil {
 		return err
 	}
-	if len(param.Group) <= 0 {
-		err = errors.New("[client.ListenConfig] Group can not be empty")
+	tenant := client.tenantOf(param)
+	key := util.GetConfigCacheKey(param.DataId, param.Group, tenant)
+	if _, err = client.addCacheDataListener(key, param, tenant, &cacheDataListener{
+		cancelable:        true,
+		listener:          param.OnChange,
+		listenerErr:       param.OnChangeErr,
+		onDelete:          param.OnDelete,
+		deliverLatestOnly: param.DeliverLatestOnly,
+	}); err != nil {
+		return err
+	}
+	client.subscriptions.add(key, cache.ConfigSubscription{DataId: param.DataId, Group: param.Group, NamespaceId: param.NamespaceId})
+	return
+}
+
+// ListenConfigWithContext listen the config until ctx is done. The listener is not persisted by PersistSubscriptions
+// since it's bound to ctx
+func (client *ConfigClient) ListenConfigWithContext(ctx context.Context, param vo.ConfigParam) (err error) {
+	if err = client.checkListenParam("ListenConfigWithContext", &param); err != nil {
 		return err
 	}
-	clientConfig, err := client.GetClientConfig()
+	listener := &cacheDataListener{
+		listener:          param.OnChange,
+		listenerErr:       param.OnChangeErr,
+		onDelete:          param.OnDelete,
+		deliverLatestOnly: param.DeliverLatestOnly,
+	}
+	listener.deadline, _ = ctx.Deadline()
+	tenant := client.tenantOf(param)
+	key := util.GetConfigCacheKey(param.DataId, param.Group, tenant)
+	cData, err := client.addCacheDataListener(key, param, tenant, listener)
 	if err != nil {
-		err = errors.New("[checkConfigInfo.GetClientConfig] failed")
 		return err
 	}
+	go func() {
+		<-ctx.Done()
+		client.removeCacheDataListener(key, cData.listeners, listener)
+		logger.With(logger.ConfigContext(tenant, param.Group, param.DataId)).Infof("Stop listen config DataId:%s Group:%s, %v", param.DataId, param.Group, ctx.Err())
+	}()
+	return nil
+}
 
-	key := util.GetConfigCacheKey(param.DataId, param.Group, clientConfig.NamespaceId)
-	var cData cacheData
-	if v, ok := client.cacheMap.Get(key); ok {
-		cData = v.(cacheData)
-		cData.isInitializing = true
-	} else {
-		var (
-			content string
-			md5Str  string
-		)
-		content, _ = cache.ReadConfigFromFile(key, client.configCacheDir)
-		if len(content) > 0 {
-			md5Str = util.Md5(content)
-		}
-		listener := &cacheDataListener{
-			listener: param.OnChange,
-			lastMd5:  md5Str,
-		}
-
-		cData = cacheData{
-			isInitializing:    true,
-			dataId:            param.DataId,
-			group:             param.Group,
-			tenant:            clientConfig.NamespaceId,
-			content:           content,
-			md5:               md5Str,
-			cacheDataListener: listener,
-			taskId:            client.cacheMap.Count() / perTaskConfigSize,
-			configClient:      client,
-		}
-	}
-	client.cacheMap.Set(key, cData)
-	return
+func (client *ConfigClient) checkListenParam(method string, param *vo.ConfigParam) error {
+	if len(param.DataId) <= 0 {
+		return errors.New("[client." + method + "] DataId can not be empty")
+	}
+	if len(param.Group) <= 0 {
+		return errors.New("[client." + method + "] Group can not be empty")
+	}
+	if _, err := client.GetClientConfig(); err != nil {
+		return errors.New("[checkConfigInfo.GetClientConfig] failed")
+	}
+	if param.OnChange == nil && param.OnBinaryChange != nil {
+		param.OnChange = binaryListener(param.OnBinaryChange)
+	}
+	if param.OnChangeAck != nil {
+		param.OnChangeErr, param.OnChangeAck = client.newAckListener(*param), nil
+	}
+	return nil
+}
+
+// Tail listen the config and stream every change through the returned channel in order,
+// the channel is closed after ctx is done
+func (client *ConfigClient) Tail(ctx context.Context, param vo.ConfigParam) (<-chan model.ConfigChangeEvent, error) {
+	if len(param.DataId) <= 0 {
+		return nil, errors.New("[client.Tail] DataId can not be empty")
+	}
+	if len(param.Group) <= 0 {
+		return nil, errors.New("[client.Tail] Group can not be empty")
+	}
+	if _, err := client.GetClientConfig(); err != nil {
+		return nil, errors.New("[checkConfigInfo.GetClientConfig] failed")
+	}
+
+	var (
+		mutex  sync.Mutex
+		closed bool
+	)
+	ch := make(chan model.ConfigChangeEvent)
+	send := func(event model.ConfigChangeEvent) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if closed {
+			return
+		}
+		select {
+		case ch <- event:
+		case <-ctx.Done():
+		}
+	}
+	listener := &cacheDataListener{
+		ordered: true,
+		// the current content is delivered as the first event
+		deliverCurrent: true,
+		listener: func(namespace, group, dataId, data string) {
+			send(model.ConfigChangeEvent{Type: model.ConfigEventChange, Namespace: namespace, Group: group, DataId: dataId, Content: data})
+		},
+		onDelete: func(namespace, group, dataId, lastContent string) {
+			send(model.ConfigChangeEvent{Type: model.ConfigEventDelete, Namespace: namespace, Group: group, DataId: dataId, Content: lastContent})
+		},
+	}
+
+	tenant := client.tenantOf(param)
+	key := util.GetConfigCacheKey(param.DataId, param.Group, tenant)
+	cData, err := client.addCacheDataListener(key, param, tenant, listener)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		client.removeCacheDataListener(key, cData.listeners, listener)
+		mutex.Lock()
+		closed = true
+		close(ch)
+		mutex.Unlock()
+		logger.With(logger.ConfigContext(tenant, param.Group, param.DataId)).Infof("Stop tail config DataId:%s Group:%s", param.DataId, param.Group)
+	}()
+	return ch, nil
+}
+
+func (client *ConfigClient) newCacheData(key string, param vo.ConfigParam, tenant string, listener *cacheDataListener) cacheData {
+	var (
+		content string
+		md5Str  string
+	)
+	content, _ = cache.ReadConfigFromFile(key, client.configCacheDir)
+	if client.quota.CheckContentSize(param.DataId, len(content)) != nil {
+		// the oversized snapshot is not cached, the content is refreshed from server and checked again
+		content = ""
+	}
+	if len(content) > 0 {
+		md5Str = util.Md5(content)
+	}
+	if !listener.deliverCurrent {
+		listener.lastMd5 = md5Str
+	}
+	listeners := &cacheDataListeners{}
+	listeners.add(listener)
+	return cacheData{
+		cacheKey:       key,
+		isInitializing: true,
+		dataId:         param.DataId,
+		group:          param.Group,
+		tenant:         tenant,
+		content:        content,
+		md5:            md5Str,
+		listeners:      listeners,
+		taskId:         client.cacheMap.Count() / client.listenBatchSize,
+		configClient:   client,
+	}
 }
 
 func (client *ConfigClient) SearchConfig(param vo.SearchConfigParam) (*model.ConfigPage, error) {
 	return client.searchConfigInner(param)
 }
 
+// ListConfigKeys list the dataId, group and md5 of configs without content, it projects the pages of blur search
+// since server has no keys-only listing, so server still reads and sends the content of each page and only the
+// memory held by the caller is saved
+func (client *ConfigClient) ListConfigKeys(param vo.ListConfigKeysParam) (*model.ConfigKeyPage, error) {
+	page, err := client.searchConfigInner(vo.SearchConfigParam{
+		Search:      "blur",
+		Group:       param.Group,
+		NamespaceId: param.NamespaceId,
+		PageNo:      param.PageNo,
+		PageSize:    param.PageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	keyPage := &model.ConfigKeyPage{
+		TotalCount:     page.TotalCount,
+		PageNumber:     page.PageNumber,
+		PagesAvailable: page.PagesAvailable,
+		PageItems:      make([]model.ConfigKey, 0, len(page.PageItems)),
+	}
+	for _, item := range page.PageItems {
+		md5Str := item.Md5
+		if len(md5Str) == 0 && len(item.Content) > 0 {
+			md5Str = util.Md5(item.Content)
+		}
+		keyPage.PageItems = append(keyPage.PageItems, model.ConfigKey{
+			DataId: item.DataId,
+			Group:  item.Group,
+			Md5:    md5Str,
+			Tenant: item.Tenant,
+			Type:   item.Type,
+		})
+	}
+	return keyPage, nil
+}
+
+// ListConfigHistory list the historical snapshots of the config kept on local disk
+func (client *ConfigClient) ListConfigHistory(param vo.ConfigParam) ([]model.ConfigSnapshot, error) {
+	if len(param.DataId) <= 0 {
+		return nil, errors.New("[client.ListConfigHistory] param.dataId can not be empty")
+	}
+	param.Group = client.GroupOf(param.DataId, param.Group)
+	return cache.ListConfigHistory(util.GetConfigCacheKey(param.DataId, param.Group, client.tenantOf(param)), client.configCacheDir)
+}
+
+// GetConfigHistory read the content of the config which was in effect at the given time from local snapshots
+func (client *ConfigClient) GetConfigHistory(param vo.ConfigParam, at time.Time) (string, error) {
+	if len(param.DataId) <= 0 {
+		return "", errors.New("[client.GetConfigHistory] param.dataId can not be empty")
+	}
+	param.Group = client.GroupOf(param.DataId, param.Group)
+	content, err := cache.ReadConfigHistory(util.GetConfigCacheKey(param.DataId, param.Group, client.tenantOf(param)), client.configCacheDir, at)
+	if err != nil {
+		return "", err
+	}
+	return client.decrypt(param.DataId, content)
+}
+
 func (client *ConfigClient) CloseClient() {
-	client.configProxy.getRpcClient(client).Shutdown()
+	introspection.Deregister(client)
+	if rpcClient := client.configProxy.GetRpcClient(client); rpcClient != nil {
+		rpcClient.Shutdown()
+	}
 	client.cancel()
 }
 
@@ -358,16 +884,27 @@ func (client *ConfigClient) searchConfigInner(param vo.SearchConfigParam) (*mode
 		param.PageSize = 10
 	}
 	clientConfig, _ := client.GetClientConfig()
-	configItems, err := client.configProxy.searchConfigProxy(param, clientConfig.NamespaceId, clientConfig.AccessKey, clientConfig.SecretKey)
+	tenant := clientConfig.NamespaceId
+	if len(param.NamespaceId) > 0 {
+		tenant = param.NamespaceId
+	}
+	configItems, err := client.configProxy.SearchConfigProxy(param, tenant, clientConfig.AccessKey, clientConfig.SecretKey)
 	if err != nil {
 		logger.Errorf("search config from server error:%+v ", err)
+		client.errorRecorder.Record(errors.Wrap(err, "search config failed"))
 		if _, ok := err.(*nacos_error.NacosError); ok {
 			nacosErr := err.(*nacos_error.NacosError)
 			if nacosErr.ErrorCode() == "404" {
 				return nil, errors.New("config not found")
 			}
 			if nacosErr.ErrorCode() == "403" {
-				return nil, errors.New("get config forbidden")
+				return nil, nacos_error.NewPermissionDeniedError(nacosErr.ErrMsg(), nacos_error.PermissionDeniedError{
+					Namespace: tenant,
+					Group:     param.Group,
+					DataId:    param.DataId,
+					Action:    nacos_error.ActionRead,
+					Identity:  identity(clientConfig),
+				})
 			}
 		}
 		return nil, err
@@ -377,13 +914,13 @@ func (client *ConfigClient) searchConfigInner(param vo.SearchConfigParam) (*mode
 
 func (client *ConfigClient) startInternal() {
 	go func() {
-		timer := time.NewTimer(executorErrDelay)
+		timer := client.clock.NewTimer(executorErrDelay)
 		defer timer.Stop()
 		for {
 			select {
 			case <-client.listenExecute:
 				client.executeConfigListen()
-			case <-timer.C:
+			case <-timer.C():
 				client.executeConfigListen()
 			case <-client.ctx.Done():
 				return
@@ -395,7 +932,7 @@ func (client *ConfigClient) startInternal() {
 
 func (client *ConfigClient) executeConfigListen() {
 	var (
-		needAllSync    = time.Since(client.lastAllSyncTime) >= constant.ALL_SYNC_INTERNAL
+		needAllSync    = client.clock.Since(client.lastAllSyncTime) >= constant.ALL_SYNC_INTERNAL
 		hasChangedKeys = false
 	)
 
@@ -404,65 +941,193 @@ func (client *ConfigClient) executeConfigListen() {
 		return
 	}
 
-	for taskId, caches := range listenTaskMap {
-		request := buildConfigBatchListenRequest(caches)
-		rpcClient := client.configProxy.createRpcClient(client.ctx, fmt.Sprintf("%d", taskId), client)
-		iResponse, err := client.configProxy.requestProxy(rpcClient, request, 3000)
-		if err != nil {
-			logger.Warnf("ConfigBatchListenRequest failure, err:%v", err)
-			continue
+	for task, taskCaches := range listenTaskMap {
+		rpcClient := client.configProxy.CreateRpcClient(client.ctx, fmt.Sprintf("%d", task.taskId), client)
+		for _, caches := range splitListenBatch(taskCaches, client.listenBatchMaxBytes) {
+			if client.listenBatch(rpcClient, task, caches) {
+				hasChangedKeys = true
+			}
 		}
-		if iResponse == nil {
-			logger.Warnf("ConfigBatchListenRequest failure, response is nil")
-			continue
+	}
+	if needAllSync {
+		client.lastAllSyncTime = client.clock.Now()
+	}
+
+	if hasChangedKeys {
+		client.asyncNotifyListenConfig()
+	}
+	monitor.GetListenConfigCountMonitor().Set(float64(client.cacheMap.Count()))
+}
+
+// listenBatch send one batch listen request and refresh the changed configs, it returns true if any config is changed
+func (client *ConfigClient) listenBatch(rpcClient *rpc.RpcClient, task listenTaskKey, caches []cacheData) (hasChangedKeys bool) {
+	request := buildConfigBatchListenRequest(task.tenant, caches)
+	iResponse, err := client.configProxy.RequestProxy(rpcClient, request, client.listenTimeoutMills(caches))
+	if err != nil {
+		logger.Warnf("ConfigBatchListenRequest failure, tenant:%s, err:%v", task.tenant, err)
+		err = errors.Wrapf(err, "ConfigBatchListenRequest of tenant %s failure", task.tenant)
+		client.errorRecorder.Record(err)
+		client.recordListenStatus(task.tenant, err)
+		return false
+	}
+	if iResponse == nil {
+		logger.Warnf("ConfigBatchListenRequest failure, tenant:%s, response is nil", task.tenant)
+		client.recordListenStatus(task.tenant, errors.New("ConfigBatchListenRequest failure, response is nil"))
+		return false
+	}
+	if !iResponse.IsSuccess() {
+		logger.Warnf("ConfigBatchListenRequest failure, tenant:%s, error code:%d", task.tenant, iResponse.GetErrorCode())
+		err = errors.Errorf("ConfigBatchListenRequest of tenant %s failure, error code:%d", task.tenant, iResponse.GetErrorCode())
+		client.errorRecorder.Record(err)
+		client.recordListenStatus(task.tenant, err)
+		return false
+	}
+	response, ok := iResponse.(*rpc_response.ConfigChangeBatchListenResponse)
+	if !ok {
+		return false
+	}
+	client.recordListenStatus(task.tenant, nil)
+
+	if len(response.ChangedConfigs) > 0 {
+		hasChangedKeys = true
+	}
+	changeKeys := make(map[string]struct{}, len(response.ChangedConfigs))
+	changed := make([]cacheData, 0, len(response.ChangedConfigs))
+	for _, v := range response.ChangedConfigs {
+		// the tenant of changed config may be omitted by server, it's always the tenant of the task
+		tenant := v.Tenant
+		if len(tenant) == 0 {
+			tenant = task.tenant
 		}
-		if !iResponse.IsSuccess() {
-			logger.Warnf("ConfigBatchListenRequest failure, error code:%d", iResponse.GetErrorCode())
+		changeKey := util.GetConfigCacheKey(v.DataId, v.Group, tenant)
+		if _, ok := changeKeys[changeKey]; ok {
 			continue
 		}
-		response, ok := iResponse.(*rpc_response.ConfigChangeBatchListenResponse)
-		if !ok {
-			continue
+		changeKeys[changeKey] = struct{}{}
+		if value, ok := client.cacheMap.Get(changeKey); ok {
+			changed = append(changed, value.(cacheData))
 		}
+	}
+	client.refreshChangedConfigs(changed)
 
-		if len(response.ChangedConfigs) > 0 {
-			hasChangedKeys = true
-		}
-		changeKeys := make(map[string]struct{}, len(response.ChangedConfigs))
-		for _, v := range response.ChangedConfigs {
-			changeKey := util.GetConfigCacheKey(v.DataId, v.Group, v.Tenant)
-			changeKeys[changeKey] = struct{}{}
-			if value, ok := client.cacheMap.Get(changeKey); ok {
-				cData := value.(cacheData)
-				client.refreshContentAndCheck(cData, !cData.isInitializing)
-			}
+	for _, c := range caches {
+		_, changed := changeKeys[c.cacheKey]
+		if !changed && c.isSyncWithServer {
+			// nothing to update for the configs which stay in sync, it's the common case of each cycle
+			continue
 		}
-
-		for _, v := range client.cacheMap.Items() {
-			data := v.(cacheData)
-			changeKey := util.GetConfigCacheKey(data.dataId, data.group, data.tenant)
-			if _, ok := changeKeys[changeKey]; !ok {
+		client.updateCacheData(c.cacheKey, func(data *cacheData) {
+			if !changed {
 				data.isSyncWithServer = true
-				client.cacheMap.Set(changeKey, data)
-				continue
+				return
 			}
 			data.isInitializing = true
-			client.cacheMap.Set(changeKey, data)
+		})
+	}
+	return hasChangedKeys
+}
+
+// listenTimeoutMills return the timeout of the batch listen request, it's bounded by the deadlines of the listeners
+// when all of the configs are listened with deadline
+func (client *ConfigClient) listenTimeoutMills(caches []cacheData) uint64 {
+	var latest time.Time
+	for _, c := range caches {
+		deadline, ok := c.listeners.deadline()
+		if !ok {
+			return constant.DEFAULT_TIMEOUT_MILLS
 		}
+		if deadline.After(latest) {
+			latest = deadline
+		}
+	}
+	return client.timeoutMillsUntil(latest)
+}
 
+// requestTimeoutMills return the timeout of the requests made for the listeners
+func (client *ConfigClient) requestTimeoutMills(listeners *cacheDataListeners) uint64 {
+	if deadline, ok := listeners.deadline(); ok {
+		return client.timeoutMillsUntil(deadline)
 	}
-	if needAllSync {
-		client.lastAllSyncTime = time.Now()
+	return constant.DEFAULT_TIMEOUT_MILLS
+}
+
+// timeoutMillsUntil return the time until deadline capped by DEFAULT_TIMEOUT_MILLS, and at least minRequestTimeout
+// so that the request made right before the deadline does not fail immediately
+func (client *ConfigClient) timeoutMillsUntil(deadline time.Time) uint64 {
+	timeout := client.clock.Until(deadline)
+	if timeout < minRequestTimeout {
+		timeout = minRequestTimeout
+	}
+	if mills := uint64(timeout / time.Millisecond); mills < constant.DEFAULT_TIMEOUT_MILLS {
+		return mills
 	}
+	return constant.DEFAULT_TIMEOUT_MILLS
+}
 
-	if hasChangedKeys {
-		client.asyncNotifyListenConfig()
+// listenBatchSize bound the configured batch size, the too small one creates a connection for every few configs
+// and the too large one produces the request body rejected by gateways
+func listenBatchSize(size int) int {
+	switch {
+	case size <= 0:
+		return constant.DEFAULT_LISTEN_BATCH_SIZE
+	case size < constant.MIN_LISTEN_BATCH_SIZE:
+		logger.Warnf("ListenBatchSize %d is too small, %d is used", size, constant.MIN_LISTEN_BATCH_SIZE)
+		return constant.MIN_LISTEN_BATCH_SIZE
+	case size > constant.MAX_LISTEN_BATCH_SIZE:
+		logger.Warnf("ListenBatchSize %d is too large, %d is used", size, constant.MAX_LISTEN_BATCH_SIZE)
+		return constant.MAX_LISTEN_BATCH_SIZE
 	}
-	monitor.GetListenConfigCountMonitor().Set(float64(client.cacheMap.Count()))
+	return size
 }
 
-func buildConfigBatchListenRequest(caches []cacheData) *rpc_request.ConfigBatchListenRequest {
+// splitListenBatch split the configs of a listen task into batches whose serialized listen contexts do not exceed
+// maxBytes, a config larger than maxBytes by itself is sent alone
+func splitListenBatch(caches []cacheData, maxBytes int) [][]cacheData {
+	if maxBytes <= 0 {
+		return [][]cacheData{caches}
+	}
+	var (
+		batches [][]cacheData
+		start   int
+		size    int
+	)
+	for i, c := range caches {
+		contextSize := listenContextOverhead + len(c.group) + len(c.md5) + len(c.dataId) + len(c.tenant)
+		if i > start && size+contextSize > maxBytes {
+			batches = append(batches, caches[start:i])
+			start, size = i, 0
+		}
+		size += contextSize
+	}
+	return append(batches, caches[start:])
+}
+
+// refreshChangedConfigs fetch the changed configs concurrently, at most fetchConcurrency at a time.
+// Each key is fetched once and the listen cycle waits for all of them, so the changes of a key are still applied in order
+func (client *ConfigClient) refreshChangedConfigs(changed []cacheData) {
+	if len(changed) == 1 || client.fetchConcurrency <= 1 {
+		for _, cData := range changed {
+			client.refreshContentAndCheck(cData, !cData.isInitializing)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	semaphore := util.NewSemaphore(client.fetchConcurrency)
+	for _, cData := range changed {
+		semaphore.Acquire()
+		wg.Add(1)
+		go func(cData cacheData) {
+			defer wg.Done()
+			defer semaphore.Release()
+			client.refreshContentAndCheck(cData, !cData.isInitializing)
+		}(cData)
+	}
+	wg.Wait()
+}
+
+func buildConfigBatchListenRequest(tenant string, caches []cacheData) *rpc_request.ConfigBatchListenRequest {
 	request := rpc_request.NewConfigBatchListenRequest(len(caches))
+	request.Tenant = tenant
 	for _, cache := range caches {
 		request.ConfigListenContexts = append(request.ConfigListenContexts,
 			model.ConfigListenContext{Group: cache.group, Md5: cache.md5, DataId: cache.dataId, Tenant: cache.tenant})
@@ -470,50 +1135,155 @@ func buildConfigBatchListenRequest(caches []cacheData) *rpc_request.ConfigBatchL
 	return request
 }
 
-func (client *ConfigClient) refreshContentAndCheck(cacheData cacheData, notify bool) {
-	configQueryResponse, err := client.configProxy.queryConfig(cacheData.dataId, cacheData.group, cacheData.tenant,
-		constant.DEFAULT_TIMEOUT_MILLS, notify, client)
+func (client *ConfigClient) refreshContentAndCheck(cacheData cacheData, notify bool) error {
+	if cacheData.evicted {
+		// read the tombstone before the snapshot is cleared by the query of deleted config
+		if content, ok := client.snapshotContent(cacheData); ok {
+			cacheData.content, cacheData.evicted = content, false
+		}
+	}
+	configQueryResponse, err := client.configProxy.QueryConfig(cacheData.dataId, cacheData.group, cacheData.tenant,
+		client.requestTimeoutMills(cacheData.listeners), notify, client)
 	if err != nil {
-		logger.Errorf("refresh content and check md5 fail ,dataId=%s,group=%s,tenant=%s ", cacheData.dataId,
+		cacheData.log().Errorf("refresh content and check md5 fail ,dataId=%s,group=%s,tenant=%s ", cacheData.dataId,
 			cacheData.group, cacheData.tenant)
-		return
+		err = errors.Wrapf(err, "refresh content dataId=%s, group=%s, tenant=%s failed",
+			cacheData.dataId, cacheData.group, cacheData.tenant)
+		client.errorRecorder.Record(err)
+		return err
+	}
+	cacheData.deleted = configQueryResponse.GetErrorCode() == constant.CONFIG_NOT_FOUND
+	if !cacheData.deleted {
+		if err = client.quota.CheckContentSize(cacheData.dataId, len(configQueryResponse.Content)); err != nil {
+			// the cached content and the listeners are kept on the last content within the quota
+			client.errorRecorder.Record(err)
+			return err
+		}
+		// the last known content is kept as tombstone of deleted config, and delivered along with the delete event
+		cacheData.content = configQueryResponse.Content
+		cacheData.contentType = configQueryResponse.ContentType
+		cacheData.evicted = false
 	}
-	cacheData.content = configQueryResponse.Content
-	cacheData.contentType = configQueryResponse.ContentType
 	if notify {
-		logger.Infof("[config_rpc_client] [data-received] dataId=%s, group=%s, tenant=%s, md5=%s, content=%s, type=%s",
+		// the deleted config has no modified time, the time it's detected is the closest one
+		cacheData.changedAt = client.clock.Now()
+		if configQueryResponse.LastModified > 0 {
+			cacheData.changedAt = time.UnixMilli(configQueryResponse.LastModified)
+		}
+		cacheData.log().Infof("[config_rpc_client] [data-received] dataId=%s, group=%s, tenant=%s, md5=%s, content=%s, type=%s",
 			cacheData.dataId, cacheData.group, cacheData.tenant, cacheData.md5,
 			util.TruncateContent(cacheData.content), cacheData.contentType)
 	}
-	cacheData.md5 = util.Md5(cacheData.content)
-	if cacheData.md5 != cacheData.cacheDataListener.lastMd5 {
+	if cacheData.deleted {
+		cacheData.md5 = ""
+	} else {
+		cacheData.md5 = contentMd5(cacheData.content)
+	}
+	if !client.storeContent(cacheData) {
+		// canceled while refreshing
+		return nil
+	}
+	client.useContent(cacheData)
+	if cacheData.needNotify() {
 		cacheDataPtr := &cacheData
 		cacheDataPtr.executeListener()
 	}
+	return nil
 }
 
-func (client *ConfigClient) buildListenTask(needAllSync bool) map[int][]cacheData {
-	listenTaskMap := make(map[int][]cacheData, 8)
+// storeContent store the refreshed content to the cached config, the other fields may be changed meanwhile
+func (client *ConfigClient) storeContent(refreshed cacheData) bool {
+	return client.updateCacheData(refreshed.cacheKey, func(data *cacheData) {
+		data.content = refreshed.content
+		data.contentType = refreshed.contentType
+		data.md5 = refreshed.md5
+		data.deleted = refreshed.deleted
+		data.evicted = refreshed.evicted
+	})
+}
 
-	for _, v := range client.cacheMap.Items() {
+// contentMd5 returns the md5 of empty content as well, unlike util.Md5,
+// so that a config with empty content is distinguished from a deleted one whose md5 is empty
+func contentMd5(content string) string {
+	if content == "" {
+		return emptyContentMd5
+	}
+	return util.Md5(content)
+}
+
+// listenTaskKey shard the listened configs by tenant, so that each batch listen request only contains configs of one tenant
+type listenTaskKey struct {
+	tenant string
+	taskId int
+}
+
+func (client *ConfigClient) buildListenTask(needAllSync bool) map[listenTaskKey][]cacheData {
+	listenTaskMap := make(map[listenTaskKey][]cacheData, 8)
+
+	// collect the values without copying the whole map, the listeners can not be executed
+	// inside IterCb since executeListener writes the map
+	values := make([]interface{}, 0, client.cacheMap.Count())
+	client.cacheMap.IterCb(func(key string, v interface{}) {
+		values = append(values, v)
+	})
+	for _, v := range values {
 		data, ok := v.(cacheData)
 		if !ok {
 			continue
 		}
 
 		if data.isSyncWithServer {
-			if data.md5 != data.cacheDataListener.lastMd5 {
+			if data.needNotify() {
 				data.executeListener()
 			}
 			if !needAllSync {
 				continue
 			}
 		}
-		listenTaskMap[data.taskId] = append(listenTaskMap[data.taskId], data)
+		key := listenTaskKey{tenant: data.tenant, taskId: data.taskId}
+		listenTaskMap[key] = append(listenTaskMap[key], data)
 	}
 	return listenTaskMap
 }
 
+func (client *ConfigClient) recordListenStatus(tenant string, err error) {
+	status := model.ConfigListenStatus{NamespaceId: tenant}
+	if v, ok := client.listenStatus.Load(tenant); ok {
+		status = v.(model.ConfigListenStatus)
+	}
+	if err != nil {
+		status.LastFailTime = client.clock.Now()
+		status.LastError = err.Error()
+	} else {
+		status.LastSuccessTime = client.clock.Now()
+	}
+	client.listenStatus.Store(tenant, status)
+	if !status.LastSuccessTime.IsZero() {
+		monitor.GetListenStalenessMonitor(tenant).Set(client.clock.Since(status.LastSuccessTime).Seconds())
+	}
+}
+
+// GetListenStatus return the listen status of each namespace
+func (client *ConfigClient) GetListenStatus() []model.ConfigListenStatus {
+	counts := make(map[string]int)
+	for _, v := range client.cacheMap.Items() {
+		counts[v.(cacheData).tenant]++
+	}
+	statuses := make([]model.ConfigListenStatus, 0, len(counts))
+	for tenant, count := range counts {
+		status := model.ConfigListenStatus{NamespaceId: tenant}
+		if v, ok := client.listenStatus.Load(tenant); ok {
+			status = v.(model.ConfigListenStatus)
+		}
+		status.ListenedCount = count
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].NamespaceId < statuses[j].NamespaceId
+	})
+	return statuses
+}
+
 func (client *ConfigClient) asyncNotifyListenConfig() {
 	go func() {
 		client.listenExecute <- struct{}{}
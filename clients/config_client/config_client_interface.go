@@ -17,54 +17,197 @@
 package config_client
 
 import (
+	"context"
+	"io"
+	"time"
+
 	"github.com/nacos-group/nacos-sdk-go/v2/model"
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
 )
 
 //go:generate mockgen -destination ../../mock/mock_config_client_interface.go -package mock -source=./config_client_interface.go
 
+// IConfigClient interface for config client, it's implemented by ConfigClient and returned by
+// clients.NewConfigClient, so the applications can mock or decorate it without depending on ConfigClient
 type IConfigClient interface {
 	// GetConfig use to get config from nacos server
 	// dataId  require
 	// group   require
-	// tenant ==>nacos.namespace optional
+	// namespaceId option,override the namespace of client
 	GetConfig(param vo.ConfigParam) (string, error)
 
+	// WaitForConfig use to block until the config exists and is not empty, such as when the application boots before
+	// its config is published, the query is retried with backoff and it fails when ctx is done first
+	// dataId  require
+	// group   require
+	// namespaceId option,override the namespace of client
+	WaitForConfig(ctx context.Context, param vo.ConfigParam) (string, error)
+
+	// GetConfigStream use to get config from nacos server as a stream, it's preferred for large configs,
+	// the stream must be closed after read
+	// dataId  require
+	// group   require
+	// namespaceId option,override the namespace of client
+	GetConfigStream(param vo.ConfigParam) (io.ReadCloser, error)
+
 	// PublishConfig use to publish config to nacos server
+	// the concurrent writes of the same config are served in order, the failure reports the publish queued before it
 	// dataId  require
 	// group   require
 	// content require
 	// tenant ==>nacos.namespace optional
 	PublishConfig(param vo.ConfigParam) (bool, error)
 
+	// PublishConfigWithResult use to publish config like PublishConfig and return the md5 and modified time on server,
+	// the CasMd5 used and whether the config is created or updated, so the CAS publishes can be chained
+	// dataId  require
+	// group   require
+	// content require
+	// tenant ==>nacos.namespace optional
+	PublishConfigWithResult(param vo.ConfigParam) (model.PublishResult, error)
+
+	// PublishConfigAt use to hold the config and publish it at effectiveTime with retries, such as cutover at low-traffic window
+	// dataId  require
+	// group   require
+	// content require
+	// onScheduleEvent option,called with the audit events of the publication
+	PublishConfigAt(param vo.ConfigParam, effectiveTime time.Time) (*ScheduledPublish, error)
+
+	// Promote use to move the staged content published with Stage to the live config with compare-and-swap,
+	// the Approve hook of ClientConfig.PublishWorkflowCfg gates the promotion
+	// dataId  require
+	// group   require
+	// casMd5 option,the md5 of live config when the staged content was reviewed, default is the md5 right before approval
+	Promote(param vo.ConfigParam) (bool, error)
+
+	// PublishConfigStream use to publish the content read from r to nacos server, the Content of param is ignored
+	// dataId  require
+	// group   require
+	PublishConfigStream(param vo.ConfigParam, r io.Reader) (bool, error)
+
+	// PublishBinaryConfig use to publish binary data such as certificates, the data is encoded as marked base64 content
+	// dataId  require
+	// group   require
+	PublishBinaryConfig(param vo.ConfigParam, data []byte) (bool, error)
+
+	// GetBinaryConfig use to get the binary data published by PublishBinaryConfig
+	// dataId  require
+	// group   require
+	// namespaceId option,override the namespace of client
+	GetBinaryConfig(param vo.ConfigParam) ([]byte, error)
+
 	// DeleteConfig use to delete config
 	// dataId  require
 	// group   require
 	// tenant ==>nacos.namespace optional
 	DeleteConfig(param vo.ConfigParam) (bool, error)
 
-	// ListenConfig use to listen config change,it will callback OnChange() when config change
+	// ListenConfig use to listen config change,it will callback OnChange() when config change. Listening the config
+	// already listened adds another listener, every listener is called on change
 	// dataId  require
 	// group   require
 	// onchange require
-	// tenant ==>nacos.namespace optional
+	// namespaceId option,override the namespace of client
 	ListenConfig(params vo.ConfigParam) (err error)
 
-	//CancelListenConfig use to cancel listen config change
+	// ListenConfigWithContext use to listen config change until ctx is done, the deadline of ctx also bounds the timeout
+	// of listen and fetch requests made for the config, so they never outlive the caller
 	// dataId  require
 	// group   require
-	// tenant ==>nacos.namespace optional
+	// onchange require
+	// namespaceId option,override the namespace of client
+	ListenConfigWithContext(ctx context.Context, params vo.ConfigParam) (err error)
+
+	// ListenConfigPath use to listen the subtree of json or yaml config selected by the JSONPath expression such as
+	// $.features.checkout.*, it will callback OnChange() with the json of selected value only when the value changes
+	// dataId  require
+	// group   require
+	// onchange require
+	// namespaceId option,override the namespace of client
+	ListenConfigPath(params vo.ConfigParam, path string) (err error)
+
+	//CancelListenConfig use to cancel listen config change, all the listeners added by ListenConfig are removed,
+	// the ones bound to a context such as Tail and ListenConfigWithContext are kept until their context is done
+	// dataId  require
+	// group   require
+	// namespaceId option,override the namespace of client
 	CancelListenConfig(params vo.ConfigParam) (err error)
 
+	// Tail use to stream config change in order through the returned channel until ctx is done,
+	// the current content will be sent as the first event
+	// dataId  require
+	// group   require
+	// tenant ==>nacos.namespace optional
+	Tail(ctx context.Context, param vo.ConfigParam) (<-chan model.ConfigChangeEvent, error)
+
+	// GetProperties use to get the properties or yaml configs merged to the flat properties, the yaml keys are joined
+	// by dot and the list items are indexed such as servers[0], so the configs of Spring services can be consumed unchanged
+	// configs require,merged in order, the type is detected from the extension of dataId when Type is empty
+	// resolvePlaceholders option,resolve the placeholders such as ${key:default} against the merged properties
+	// lookupEnv option,the environment variables override the properties when resolving placeholders
+	GetProperties(param vo.PropertiesParam) (map[string]string, error)
+
 	// SearchConfig use to search nacos config
 	// search  require search=accurate--精确搜索  search=blur--模糊搜索
 	// group   option
 	// dataId  option
 	// tenant ==>nacos.namespace optional
+	// namespaceId option,override the namespace of client
+	// appName option
+	// configTags option,comma separated
+	// content option,keyword of config content
 	// pageNo  option,default is 1
 	// pageSize option,default is 10
 	SearchConfig(param vo.SearchConfigParam) (*model.ConfigPage, error)
 
+	// SearchConfigIterator use to iterate over all pages of the search result
+	// the param is the same as SearchConfig, pageNo is the page to start from
+	SearchConfigIterator(param vo.SearchConfigParam) *ConfigIterator
+
+	// ListConfigKeys use to list dataId, group and md5 of configs without content
+	// the keys are projected from pages of blur search, server still reads and sends the content of each page
+	// namespaceId option,override the namespace of client
+	// group option,list configs of all groups when empty
+	// pageNo  option,default is 1
+	// pageSize option,default is 10
+	ListConfigKeys(param vo.ListConfigKeysParam) (*model.ConfigKeyPage, error)
+
+	// ListConfigHistory use to list the historical snapshots kept on local disk, ConfigHistorySize must be set
+	// dataId  require
+	// group   require
+	ListConfigHistory(param vo.ConfigParam) ([]model.ConfigSnapshot, error)
+
+	// GetConfigHistory use to read the content which was in effect at the given time from local snapshots
+	// dataId  require
+	// group   require
+	GetConfigHistory(param vo.ConfigParam, at time.Time) (string, error)
+
+	// RestoreSubscriptions use to listen the configs persisted by the previous process again, PersistSubscriptions must be set
+	// param is the template of the listeners, OnChange require
+	RestoreSubscriptions(param vo.ConfigParam) ([]vo.ConfigParam, error)
+
+	// GetListenStatus use to get the listen status of each namespace,
+	// a namespace whose lastSuccessTime is far behind the others is lagging
+	GetListenStatus() []model.ConfigListenStatus
+
+	// GetNamespaceChecksum use to compute the aggregate checksum of sorted dataId:md5 pairs of the configs on server,
+	// compare it with the manifest of each node by CompareConfigManifest to verify the fleet is consistent
+	// namespace option,override the namespace of client
+	// group option,default is DEFAULT_GROUP
+	GetNamespaceChecksum(namespace, group string) (*model.ConfigManifest, error)
+
+	// LocalConfigManifest use to build the manifest of the listened configs, it's what this node is running
+	// namespace option,override the namespace of client
+	// group option,default is DEFAULT_GROUP
+	LocalConfigManifest(namespace, group string) *model.ConfigManifest
+
+	// GroupOf return the group the client uses when group is empty, it's resolved by GroupResolver and then
+	// DefaultGroup of ClientConfig, DEFAULT_GROUP is the last resort, a non-empty group is returned as it is
+	GroupOf(dataId, group string) string
+
+	// InstanceId return the unique id of the client instance, it is the key of the client in introspection handler
+	InstanceId() string
+
 	// CloseClient Close the GRPC client
 	CloseClient()
 }
@@ -18,8 +18,25 @@ package config_client
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
 	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/nacos-group/nacos-sdk-go/v2/util"
 
@@ -28,10 +45,18 @@ import (
 	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_response"
 	"github.com/nacos-group/nacos-sdk-go/v2/model"
 
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/cache"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/nacos_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/audit"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/dataid"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/http_agent"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/quota"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/security"
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -50,9 +75,17 @@ var localConfigTest = vo.ConfigParam{
 }
 
 func createConfigClientTest() *ConfigClient {
+	return createConfigClientWithClockTest(nil)
+}
+
+// createConfigClientWithClockTest create the client on the clock, it's set before the client starts the goroutines
+// reading it
+func createConfigClientWithClockTest(clk clock.Clock) *ConfigClient {
+	clientConfig := *clientConfigWithOptions
+	clientConfig.Clock = clk
 	nc := nacos_client.NacosClient{}
 	_ = nc.SetServerConfig([]constant.ServerConfig{*serverConfigWithOptions})
-	_ = nc.SetClientConfig(*clientConfigWithOptions)
+	_ = nc.SetClientConfig(clientConfig)
 	_ = nc.SetHttpAgent(&http_agent.HttpAgent{})
 	client, _ := NewConfigClient(&nc)
 	client.configProxy = &MockConfigProxy{}
@@ -62,26 +95,47 @@ func createConfigClientTest() *ConfigClient {
 type MockConfigProxy struct {
 }
 
-func (m *MockConfigProxy) queryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+func (m *MockConfigProxy) QueryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
 	cacheKey := util.GetConfigCacheKey(dataId, group, tenant)
 	if IsLimited(cacheKey) {
 		return nil, errors.New("request is limited")
 	}
 	return &rpc_response.ConfigQueryResponse{Content: "hello world"}, nil
 }
-func (m *MockConfigProxy) searchConfigProxy(param vo.SearchConfigParam, tenant, accessKey, secretKey string) (*model.ConfigPage, error) {
+func (m *MockConfigProxy) SearchConfigProxy(param vo.SearchConfigParam, tenant, accessKey, secretKey string) (*model.ConfigPage, error) {
 	return &model.ConfigPage{TotalCount: 1}, nil
 }
-func (m *MockConfigProxy) requestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+func (m *MockConfigProxy) RequestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
 	return &rpc_response.MockResponse{Response: &rpc_response.Response{Success: true}}, nil
 }
-func (m *MockConfigProxy) createRpcClient(ctx context.Context, taskId string, client *ConfigClient) *rpc.RpcClient {
+func (m *MockConfigProxy) CreateRpcClient(ctx context.Context, taskId string, client *ConfigClient) *rpc.RpcClient {
 	return &rpc.RpcClient{}
 }
-func (m *MockConfigProxy) getRpcClient(client *ConfigClient) *rpc.RpcClient {
+func (m *MockConfigProxy) GetRpcClient(client *ConfigClient) *rpc.RpcClient {
 	return &rpc.RpcClient{}
 }
 
+func Test_NewConfigClientWithProxy(t *testing.T) {
+	nc := nacos_client.NacosClient{}
+	_ = nc.SetServerConfig([]constant.ServerConfig{*serverConfigWithOptions})
+	_ = nc.SetClientConfig(*clientConfigWithOptions)
+	_ = nc.SetHttpAgent(&http_agent.HttpAgent{})
+	proxy := &recordConfigProxy{}
+	client, err := NewConfigClientWithProxy(&nc, func(ctx context.Context, serverConfig []constant.ServerConfig,
+		clientConfig constant.ClientConfig, httpAgent http_agent.IHttpAgent) (IConfigProxy, error) {
+		return proxy, nil
+	})
+	assert.Nil(t, err)
+	defer client.CloseClient()
+
+	content, err := client.GetConfig(vo.ConfigParam{DataId: "dataId", Group: "group"})
+	assert.Nil(t, err)
+	assert.Equal(t, "hello world", content)
+	_, err = client.PublishConfig(vo.ConfigParam{DataId: "dataId", Group: "group", Content: "content"})
+	assert.Nil(t, err)
+	assert.Len(t, proxy.requests, 1)
+}
+
 func Test_GetConfig(t *testing.T) {
 	client := createConfigClientTest()
 	success, err := client.PublishConfig(vo.ConfigParam{
@@ -234,3 +288,2300 @@ func TestCancelListenConfig(t *testing.T) {
 		assert.Nil(t, err)
 	})
 }
+
+func TestCacheDataListener_DeliverLatestOnly(t *testing.T) {
+	var (
+		mux       sync.Mutex
+		delivered []string
+	)
+	block := make(chan struct{})
+	done := make(chan struct{})
+	l := &cacheDataListener{
+		deliverLatestOnly: true,
+		listener: func(namespace, group, dataId, data string) {
+			if data == "v1" {
+				<-block
+			}
+			mux.Lock()
+			delivered = append(delivered, data)
+			mux.Unlock()
+			if data == "v4" {
+				close(done)
+			}
+		},
+	}
+	l.deliver("", "group", "dataId", "v1")
+	l.deliver("", "group", "dataId", "v2")
+	l.deliver("", "group", "dataId", "v3")
+	l.deliver("", "group", "dataId", "v4")
+	close(block)
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("latest content is not delivered")
+	}
+	mux.Lock()
+	defer mux.Unlock()
+	assert.Equal(t, []string{"v1", "v4"}, delivered)
+}
+
+func TestCacheDataListener_Ordered(t *testing.T) {
+	var delivered []string
+	done := make(chan struct{})
+	l := &cacheDataListener{
+		ordered: true,
+		listener: func(namespace, group, dataId, data string) {
+			delivered = append(delivered, data)
+			if data == "v3" {
+				close(done)
+			}
+		},
+	}
+	l.deliver("", "group", "dataId", "v1")
+	l.deliver("", "group", "dataId", "v2")
+	l.deliver("", "group", "dataId", "v3")
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("content is not delivered")
+	}
+	assert.Equal(t, []string{"v1", "v2", "v3"}, delivered)
+}
+
+func TestCacheDataListener_ObserveLag(t *testing.T) {
+	done := make(chan struct{})
+	l := &cacheDataListener{
+		ordered: true,
+		listener: func(namespace, group, dataId, data string) {
+			close(done)
+		},
+	}
+	// the summary is global, so the delta is asserted for the repeated runs
+	count, sum := listenerLagSummary(t, "lag")
+	l.deliverEvent(&listenerEvent{namespace: "lag", group: "group", dataId: "dataId", content: "v1",
+		changedAt: time.Now().Add(-time.Second)})
+	<-done
+	assert.Eventually(t, func() bool {
+		newCount, newSum := listenerLagSummary(t, "lag")
+		return newCount == count+1 && newSum-sum >= 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCacheDataListener_ObserveLagOnClock(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	done := make(chan struct{})
+	l := &cacheDataListener{
+		ordered: true,
+		clock:   fakeClock,
+		listener: func(namespace, group, dataId, data string) {
+			close(done)
+		},
+	}
+	count, sum := listenerLagSummary(t, "lag-clock")
+	changedAt := fakeClock.Now()
+	fakeClock.Advance(time.Minute)
+	l.deliverEvent(&listenerEvent{namespace: "lag-clock", group: "group", dataId: "dataId", content: "v1",
+		changedAt: changedAt})
+	<-done
+	assert.Eventually(t, func() bool {
+		newCount, newSum := listenerLagSummary(t, "lag-clock")
+		return newCount == count+1 && newSum-sum == time.Minute.Seconds()
+	}, time.Second, 10*time.Millisecond)
+}
+
+// listenerLagSummary return the sample count and sum of the listener lag summary of the namespace
+func listenerLagSummary(t *testing.T, namespace string) (uint64, float64) {
+	families, err := prometheus.DefaultGatherer.Gather()
+	assert.Nil(t, err)
+	for _, family := range families {
+		if family.GetName() != "nacos_config_listener_lag_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if metric.GetLabel()[2].GetValue() == namespace {
+				return metric.GetSummary().GetSampleCount(), metric.GetSummary().GetSampleSum()
+			}
+		}
+	}
+	return 0, 0
+}
+
+func TestTail(t *testing.T) {
+	client := createConfigClientTest()
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := client.Tail(ctx, vo.ConfigParam{
+		DataId: localConfigTest.DataId + "tail",
+		Group:  localConfigTest.Group,
+	})
+	assert.Nil(t, err)
+	key := util.GetConfigCacheKey(localConfigTest.DataId+"tail", localConfigTest.Group, clientConfigWithOptions.NamespaceId)
+	assert.True(t, client.cacheMap.Has(key))
+
+	cancel()
+	for range ch {
+	}
+	assert.False(t, client.cacheMap.Has(key))
+
+	_, err = client.Tail(context.Background(), vo.ConfigParam{Group: localConfigTest.Group})
+	assert.Error(t, err)
+}
+
+func TestTail_KeptByCancelListenConfig(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	param := vo.ConfigParam{DataId: localConfigTest.DataId + "tail-cancel", Group: localConfigTest.Group}
+	key := util.GetConfigCacheKey(param.DataId, param.Group, clientConfigWithOptions.NamespaceId)
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := client.Tail(ctx, param)
+	assert.Nil(t, err)
+	listenParam := param
+	listenParam.OnChange = func(namespace, group, dataId, data string) {}
+	assert.Nil(t, client.ListenConfig(listenParam))
+	assert.Nil(t, client.ListenConfig(listenParam))
+	v, _ := client.cacheMap.Get(key)
+	assert.Len(t, v.(cacheData).listeners.items(), 3)
+
+	// only the listeners of ListenConfig are removed
+	assert.Nil(t, client.CancelListenConfig(param))
+	v, ok := client.cacheMap.Get(key)
+	assert.True(t, ok)
+	listeners := v.(cacheData).listeners.items()
+	assert.Len(t, listeners, 1)
+	assert.True(t, listeners[0].deliverCurrent)
+	assert.Equal(t, "", listeners[0].getLastMd5())
+
+	cancel()
+	for range ch {
+	}
+	assert.False(t, client.cacheMap.Has(key))
+}
+
+type recordConfigProxy struct {
+	MockConfigProxy
+	requests []rpc_request.IRequest
+}
+
+func (m *recordConfigProxy) RequestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	m.requests = append(m.requests, request)
+	return m.MockConfigProxy.RequestProxy(rpcClient, request, timeoutMills)
+}
+
+func Test_PublishConfigWithMetadata(t *testing.T) {
+	client := createConfigClientTest()
+	proxy := &recordConfigProxy{}
+	client.configProxy = proxy
+	success, err := client.PublishConfig(vo.ConfigParam{
+		DataId:     localConfigTest.DataId,
+		Group:      localConfigTest.Group,
+		Content:    "hello world",
+		Desc:       "desc",
+		Use:        "use",
+		Effect:     "effect",
+		Schema:     "schema",
+		ConfigTags: "a,b",
+	})
+	assert.Nil(t, err)
+	assert.True(t, success)
+	assert.Len(t, proxy.requests, 1)
+	request := proxy.requests[0].(*rpc_request.ConfigPublishRequest)
+	assert.Equal(t, "desc", request.AdditionMap["desc"])
+	assert.Equal(t, "use", request.AdditionMap["use"])
+	assert.Equal(t, "effect", request.AdditionMap["effect"])
+	assert.Equal(t, "schema", request.AdditionMap["schema"])
+	assert.Equal(t, "a,b", request.AdditionMap["config_tags"])
+}
+
+type pagingConfigProxy struct {
+	MockConfigProxy
+	tenants []string
+}
+
+func (m *pagingConfigProxy) SearchConfigProxy(param vo.SearchConfigParam, tenant, accessKey, secretKey string) (*model.ConfigPage, error) {
+	m.tenants = append(m.tenants, tenant)
+	page := &model.ConfigPage{TotalCount: 3, PageNumber: param.PageNo, PagesAvailable: 2}
+	if param.PageNo == 1 {
+		page.PageItems = []model.ConfigItem{{DataId: "a"}, {DataId: "b"}}
+	} else {
+		page.PageItems = []model.ConfigItem{{DataId: "c"}}
+	}
+	return page, nil
+}
+
+func Test_SearchConfigIterator(t *testing.T) {
+	client := createConfigClientTest()
+	proxy := &pagingConfigProxy{}
+	client.configProxy = proxy
+	it := client.SearchConfigIterator(vo.SearchConfigParam{Search: "blur", PageSize: 2, NamespaceId: "ns"})
+	var dataIds []string
+	for it.Next() {
+		dataIds = append(dataIds, it.Item().DataId)
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, []string{"a", "b", "c"}, dataIds)
+	assert.Equal(t, []string{"ns", "ns"}, proxy.tenants)
+
+	it = client.SearchConfigIterator(vo.SearchConfigParam{Search: "unknown"})
+	assert.False(t, it.Next())
+	assert.Error(t, it.Err())
+}
+
+type throttledConfigProxy struct {
+	pagingConfigProxy
+	throttles int
+}
+
+func (m *throttledConfigProxy) SearchConfigProxy(param vo.SearchConfigParam, tenant, accessKey, secretKey string) (*model.ConfigPage, error) {
+	if param.PageNo == 2 && m.throttles > 0 {
+		m.throttles--
+		return nil, &nacos_error.RateLimitedError{RetryAfter: 2 * time.Second}
+	}
+	return m.pagingConfigProxy.SearchConfigProxy(param, tenant, accessKey, secretKey)
+}
+
+func Test_SearchConfigIteratorBackoff(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	client := createConfigClientWithClockTest(fakeClock)
+	defer client.CloseClient()
+	client.configProxy = &throttledConfigProxy{throttles: 1}
+	it := client.SearchConfigIterator(vo.SearchConfigParam{Search: "blur", PageSize: 2})
+	go func() {
+		// the timer of listen executor and the backoff of the iterator
+		fakeClock.BlockUntil(2)
+		fakeClock.Advance(2 * time.Second)
+	}()
+	var dataIds []string
+	for it.Next() {
+		dataIds = append(dataIds, it.Item().DataId)
+	}
+	assert.Nil(t, it.Err())
+	assert.Equal(t, []string{"a", "b", "c"}, dataIds)
+
+	client.configProxy = &throttledConfigProxy{throttles: 2}
+	it = client.SearchConfigIterator(vo.SearchConfigParam{Search: "blur", PageSize: 2, MaxRetries: 1})
+	go func() {
+		// the timer of listen executor and the backoff of the iterator
+		fakeClock.BlockUntil(2)
+		fakeClock.Advance(2 * time.Second)
+	}()
+	assert.True(t, it.Next())
+	assert.Equal(t, 1, it.PageNo())
+	assert.True(t, it.Next())
+	assert.False(t, it.Next())
+	_, rateLimited := nacos_error.IsRateLimited(it.Err())
+	assert.True(t, rateLimited)
+	assert.Equal(t, 2, it.PageNo())
+
+	it = client.SearchConfigIterator(vo.SearchConfigParam{Search: "blur", PageSize: 2, PageNo: it.PageNo()})
+	assert.True(t, it.Next())
+	assert.Equal(t, "c", it.Item().DataId)
+	assert.False(t, it.Next())
+	assert.Nil(t, it.Err())
+}
+
+func Test_ListConfigKeys(t *testing.T) {
+	client := createConfigClientTest()
+	proxy := &pagingConfigProxy{}
+	client.configProxy = proxy
+	page, err := client.ListConfigKeys(vo.ListConfigKeysParam{NamespaceId: "ns", PageSize: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, page.TotalCount)
+	assert.Equal(t, []model.ConfigKey{{DataId: "a"}, {DataId: "b"}}, page.PageItems)
+	assert.Equal(t, []string{"ns"}, proxy.tenants)
+}
+
+func TestBuildListenTask_ShardByTenant(t *testing.T) {
+	client := createConfigClientTest()
+	for _, tenant := range []string{"a", "a", "b"} {
+		dataId := "dataId" + strconv.Itoa(client.cacheMap.Count())
+		client.cacheMap.Set(util.GetConfigCacheKey(dataId, "group", tenant), cacheData{
+			dataId:    dataId,
+			group:     "group",
+			tenant:    tenant,
+			listeners: &cacheDataListeners{},
+		})
+	}
+	tasks := client.buildListenTask(true)
+	assert.Len(t, tasks, 2)
+	assert.Len(t, tasks[listenTaskKey{tenant: "a"}], 2)
+	assert.Len(t, tasks[listenTaskKey{tenant: "b"}], 1)
+	assert.Equal(t, "b", buildConfigBatchListenRequest("b", tasks[listenTaskKey{tenant: "b"}]).Tenant)
+
+	client.recordListenStatus("a", errors.New("listen failed"))
+	client.recordListenStatus("b", nil)
+	statuses := client.GetListenStatus()
+	assert.Len(t, statuses, 2)
+	assert.Equal(t, "a", statuses[0].NamespaceId)
+	assert.Equal(t, 2, statuses[0].ListenedCount)
+	assert.Equal(t, "listen failed", statuses[0].LastError)
+	assert.True(t, statuses[0].LastSuccessTime.IsZero())
+	assert.False(t, statuses[1].LastSuccessTime.IsZero())
+}
+
+func TestSplitListenBatch(t *testing.T) {
+	var caches []cacheData
+	for i := 0; i < 10; i++ {
+		caches = append(caches, cacheData{dataId: "dataId" + strconv.Itoa(i), group: "group", md5: util.Md5(strconv.Itoa(i))})
+	}
+	data, err := json.Marshal(buildConfigBatchListenRequest("", caches[:1]).ConfigListenContexts)
+	assert.Nil(t, err)
+	contextSize := len(data) - len("[]")
+	// the size estimation is exact, the trailing comma of the last context makes up for the brackets
+	assert.Equal(t, contextSize+1, listenContextOverhead+len("dataId0")+len("group")+32)
+
+	assert.Len(t, splitListenBatch(caches, 0), 1)
+	batches := splitListenBatch(caches, 3*(contextSize+1))
+	assert.Len(t, batches, 4)
+	assert.Len(t, batches[0], 3)
+	assert.Len(t, batches[3], 1)
+	// the config larger than the limit is sent alone
+	assert.Len(t, splitListenBatch(caches, 1), 10)
+
+	assert.Equal(t, constant.DEFAULT_LISTEN_BATCH_SIZE, listenBatchSize(0))
+	assert.Equal(t, constant.MIN_LISTEN_BATCH_SIZE, listenBatchSize(1))
+	assert.Equal(t, constant.MAX_LISTEN_BATCH_SIZE, listenBatchSize(1000000))
+	assert.Equal(t, 500, listenBatchSize(500))
+}
+
+func Test_GroupOf(t *testing.T) {
+	client := createConfigClientTest()
+	assert.Equal(t, constant.DEFAULT_GROUP, client.GroupOf("dataId", ""))
+	assert.Equal(t, "group", client.GroupOf("dataId", "group"))
+
+	clientConfig := *clientConfigWithOptions
+	clientConfig.DefaultGroup = "default"
+	clientConfig.GroupResolver = dataid.Default.GroupResolver(func(key dataid.Key) string {
+		return strings.ToUpper(key.Env) + "_GROUP"
+	})
+	_ = client.SetClientConfig(clientConfig)
+	assert.Equal(t, "PROD_GROUP", client.GroupOf("prod-order-db.yaml", ""))
+	assert.Equal(t, "default", client.GroupOf("application.yaml", ""))
+	assert.Equal(t, "group", client.GroupOf("prod-order-db.yaml", "group"))
+}
+
+type failingConfigProxy struct {
+	recordConfigProxy
+	fail bool
+}
+
+func (m *failingConfigProxy) RequestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	if m.fail {
+		m.requests = append(m.requests, request)
+		return nil, errors.New("request timeout")
+	}
+	return m.recordConfigProxy.RequestProxy(rpcClient, request, timeoutMills)
+}
+
+func Test_PublishConfigIdempotency(t *testing.T) {
+	client := createConfigClientTest()
+	proxy := &failingConfigProxy{}
+	client.configProxy = proxy
+	param := vo.ConfigParam{
+		DataId:         localConfigTest.DataId,
+		Group:          localConfigTest.Group,
+		Content:        "content",
+		IdempotencyKey: "key-1",
+	}
+	success, err := client.PublishConfig(param)
+	assert.Nil(t, err)
+	assert.True(t, success)
+	success, err = client.PublishConfig(param)
+	assert.Nil(t, err)
+	assert.True(t, success)
+	assert.Len(t, proxy.requests, 1)
+
+	param.Content = "changed"
+	_, err = client.PublishConfig(param)
+	assert.Error(t, err)
+
+	// the ambiguous publish is considered done when the content on server is the same
+	param.IdempotencyKey = "key-2"
+	param.Content = "hello world"
+	proxy.fail = true
+	_, err = client.PublishConfig(param)
+	assert.Error(t, err)
+	proxy.fail = false
+	success, err = client.PublishConfig(param)
+	assert.Nil(t, err)
+	assert.True(t, success)
+	assert.Len(t, proxy.requests, 2)
+
+	// the retry of ambiguous publish uses the md5 on server as casMd5
+	param.IdempotencyKey = "key-3"
+	param.Content = "new content"
+	proxy.fail = true
+	_, _ = client.PublishConfig(param)
+	proxy.fail = false
+	success, err = client.PublishConfig(param)
+	assert.Nil(t, err)
+	assert.True(t, success)
+	request := proxy.requests[len(proxy.requests)-1].(*rpc_request.ConfigPublishRequest)
+	assert.Equal(t, util.Md5("hello world"), request.CasMd5)
+}
+
+func Test_PublishConfigIdempotencyExpire(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	client := createConfigClientWithClockTest(fakeClock)
+	defer client.CloseClient()
+	proxy := &failingConfigProxy{}
+	client.configProxy = proxy
+	param := vo.ConfigParam{
+		DataId:         localConfigTest.DataId,
+		Group:          localConfigTest.Group,
+		Content:        "content",
+		IdempotencyKey: "key-1",
+	}
+	_, err := client.PublishConfig(param)
+	assert.Nil(t, err)
+	fakeClock.Advance(constant.PUBLISH_IDEMPOTENCY_TTL)
+	_, err = client.PublishConfig(param)
+	assert.Nil(t, err)
+	assert.Len(t, proxy.requests, 1)
+
+	// the key is forgotten after the ttl on the clock of client
+	fakeClock.Advance(time.Second)
+	_, err = client.PublishConfig(param)
+	assert.Nil(t, err)
+	assert.Len(t, proxy.requests, 2)
+}
+
+// countingPublishProxy counts the publish requests, each takes a while so that the concurrent ones overlap
+type countingPublishProxy struct {
+	MockConfigProxy
+	published int32
+}
+
+func (m *countingPublishProxy) RequestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	if _, ok := request.(*rpc_request.ConfigPublishRequest); ok {
+		atomic.AddInt32(&m.published, 1)
+		time.Sleep(10 * time.Millisecond)
+	}
+	return m.MockConfigProxy.RequestProxy(rpcClient, request, timeoutMills)
+}
+
+func Test_PublishConfigIdempotencyConcurrent(t *testing.T) {
+	client := createConfigClientTest()
+	proxy := &countingPublishProxy{}
+	client.configProxy = proxy
+	param := vo.ConfigParam{
+		DataId:         localConfigTest.DataId,
+		Group:          localConfigTest.Group,
+		Content:        "content",
+		IdempotencyKey: "key-1",
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			success, err := client.PublishConfig(param)
+			assert.Nil(t, err)
+			assert.True(t, success)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&proxy.published))
+}
+
+type deniedConfigProxy struct {
+	MockConfigProxy
+}
+
+func (m *deniedConfigProxy) QueryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+	proxy := ConfigProxy{clientConfig: constant.ClientConfig{Username: "nacos"}}
+	return nil, proxy.permissionDenied(rpc_request.NewConfigQueryRequest(group, dataId, tenant), "authorization failed!")
+}
+
+func Test_GetConfigPermissionDenied(t *testing.T) {
+	client := createConfigClientTest()
+	client.configProxy = &deniedConfigProxy{}
+	_, err := client.GetConfig(vo.ConfigParam{DataId: localConfigTest.DataId, Group: localConfigTest.Group})
+	deniedErr, ok := err.(*nacos_error.PermissionDeniedError)
+	assert.True(t, ok)
+	assert.Equal(t, localConfigTest.DataId, deniedErr.DataId)
+	assert.Equal(t, localConfigTest.Group, deniedErr.Group)
+	assert.Equal(t, nacos_error.ActionRead, deniedErr.Action)
+	assert.Equal(t, "nacos", deniedErr.Identity)
+}
+
+type existenceConfigProxy struct {
+	MockConfigProxy
+	content *string
+}
+
+func (m *existenceConfigProxy) QueryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+	if m.content == nil {
+		return &rpc_response.ConfigQueryResponse{Response: &rpc_response.Response{ErrorCode: constant.CONFIG_NOT_FOUND}}, nil
+	}
+	return &rpc_response.ConfigQueryResponse{Response: &rpc_response.Response{Success: true}, Content: *m.content}, nil
+}
+
+func Test_ListenConfigNotExist(t *testing.T) {
+	client := createConfigClientTest()
+	proxy := &existenceConfigProxy{}
+	client.configProxy = proxy
+	changed := make(chan string, 1)
+	deleted := make(chan string, 1)
+	param := vo.ConfigParam{
+		DataId: "not-exist-yet",
+		Group:  localConfigTest.Group,
+		OnChange: func(namespace, group, dataId, data string) {
+			changed <- data
+		},
+		OnDelete: func(namespace, group, dataId, lastContent string) {
+			deleted <- dataId
+		},
+	}
+	assert.Nil(t, client.ListenConfig(param))
+	refresh := func() {
+		v, ok := client.cacheMap.Get(util.GetConfigCacheKey(param.DataId, param.Group, ""))
+		assert.True(t, ok)
+		client.refreshContentAndCheck(v.(cacheData), true)
+	}
+
+	refresh()
+	select {
+	case <-changed:
+		t.Fatal("OnChange should not be called before the config is created")
+	case <-deleted:
+		t.Fatal("OnDelete should not be called before the config is created")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	content := ""
+	proxy.content = &content
+	refresh()
+	select {
+	case data := <-changed:
+		assert.Equal(t, "", data)
+	case <-time.After(3 * time.Second):
+		t.Fatal("OnChange is not called when the config is created with empty content")
+	}
+
+	proxy.content = nil
+	refresh()
+	select {
+	case dataId := <-deleted:
+		assert.Equal(t, param.DataId, dataId)
+	case <-changed:
+		t.Fatal("OnChange should not be called when the config is deleted")
+	case <-time.After(3 * time.Second):
+		t.Fatal("OnDelete is not called when the config is deleted")
+	}
+}
+
+func Test_TailConfigDeleted(t *testing.T) {
+	client := createConfigClientTest()
+	content := "v1"
+	proxy := &existenceConfigProxy{content: &content}
+	client.configProxy = proxy
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	param := vo.ConfigParam{DataId: "tail-deleted", Group: localConfigTest.Group}
+	events, err := client.Tail(ctx, param)
+	assert.Nil(t, err)
+	refresh := func() {
+		v, ok := client.cacheMap.Get(util.GetConfigCacheKey(param.DataId, param.Group, ""))
+		assert.True(t, ok)
+		client.refreshContentAndCheck(v.(cacheData), true)
+	}
+	refresh()
+	proxy.content = nil
+	refresh()
+	var received []model.ConfigChangeEvent
+	for len(received) < 2 {
+		select {
+		case event := <-events:
+			received = append(received, event)
+		case <-time.After(3 * time.Second):
+			t.Fatalf("received %d events", len(received))
+		}
+	}
+	assert.Equal(t, model.ConfigEventChange, received[0].Type)
+	assert.Equal(t, "v1", received[0].Content)
+	assert.Equal(t, model.ConfigEventDelete, received[1].Type)
+	assert.Equal(t, "v1", received[1].Content)
+}
+
+func Test_ListenConfigDeletedWithoutOnDelete(t *testing.T) {
+	client := createConfigClientTest()
+	content := "v1"
+	proxy := &existenceConfigProxy{content: &content}
+	client.configProxy = proxy
+	changed := make(chan string, 2)
+	param := vo.ConfigParam{
+		DataId: "deleted-without-on-delete",
+		Group:  localConfigTest.Group,
+		OnChange: func(namespace, group, dataId, data string) {
+			changed <- data
+		},
+	}
+	assert.Nil(t, client.ListenConfig(param))
+	v, _ := client.cacheMap.Get(util.GetConfigCacheKey(param.DataId, param.Group, ""))
+	client.refreshContentAndCheck(v.(cacheData), true)
+	assert.Equal(t, "v1", <-changed)
+	proxy.content = nil
+	v, _ = client.cacheMap.Get(util.GetConfigCacheKey(param.DataId, param.Group, ""))
+	client.refreshContentAndCheck(v.(cacheData), true)
+	select {
+	case data := <-changed:
+		assert.Equal(t, "", data)
+	case <-time.After(3 * time.Second):
+		t.Fatal("OnChange is not called when the config is deleted")
+	}
+}
+
+func TestConfigWriteQueue_Order(t *testing.T) {
+	var q configWriteQueue
+	turn := q.acquire("key")
+	var (
+		mux   sync.Mutex
+		order []int
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer q.acquire("key").release()
+			mux.Lock()
+			order = append(order, i)
+			mux.Unlock()
+		}(i)
+		for q.queued("key") != i+2 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	// writers of the other keys are not blocked
+	q.acquire("other").release()
+	turn.release()
+	wg.Wait()
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, order)
+	assert.Empty(t, q.keys)
+}
+
+func TestConfigWriteQueue_Outcome(t *testing.T) {
+	var q configWriteQueue
+	first := q.acquire("key")
+	assert.Nil(t, first.prev)
+	turns := make(chan *writeTurn)
+	go func() {
+		turns <- q.acquire("key")
+	}()
+	for q.queued("key") != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	first.finish("md5-a", true, nil)
+	second := <-turns
+	assert.Equal(t, "md5-a", second.overwrote("md5-b"))
+	assert.Equal(t, "", second.overwrote("md5-a"))
+	assert.Contains(t, second.combine(errors.New("timeout")).Error(), "md5=md5-a")
+	assert.Nil(t, second.combine(nil))
+
+	go func() {
+		turns <- q.acquire("key")
+	}()
+	for q.queued("key") != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	second.finish("md5-b", false, errors.New("timeout"))
+	third := <-turns
+	assert.Equal(t, "", third.overwrote("md5-c"))
+	assert.Contains(t, third.combine(errors.New("denied")).Error(), "failed as well, err:timeout")
+	third.release()
+	assert.Empty(t, q.keys)
+
+	// the outcomes are kept only while the writers are queued
+	assert.Nil(t, q.acquire("key").prev)
+}
+
+type concurrencyConfigProxy struct {
+	MockConfigProxy
+	mux      sync.Mutex
+	running  int
+	maxCount int
+}
+
+func (m *concurrencyConfigProxy) RequestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	m.mux.Lock()
+	m.running++
+	if m.running > m.maxCount {
+		m.maxCount = m.running
+	}
+	m.mux.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	m.mux.Lock()
+	m.running--
+	m.mux.Unlock()
+	return m.MockConfigProxy.RequestProxy(rpcClient, request, timeoutMills)
+}
+
+func Test_PublishConfigSerialized(t *testing.T) {
+	client := createConfigClientTest()
+	proxy := &concurrencyConfigProxy{}
+	client.configProxy = proxy
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := client.PublishConfig(vo.ConfigParam{
+				DataId:  localConfigTest.DataId,
+				Group:   localConfigTest.Group,
+				Content: "content-" + strconv.Itoa(i),
+			})
+			assert.Nil(t, err)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 1, proxy.maxCount)
+}
+
+// gatedPublishProxy holds the publishes until the gate is closed, the publish of failContent fails
+type gatedPublishProxy struct {
+	storeConfigProxy
+	entered     chan struct{}
+	gate        chan struct{}
+	failContent string
+}
+
+func (m *gatedPublishProxy) RequestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	if r, ok := request.(*rpc_request.ConfigPublishRequest); ok {
+		m.entered <- struct{}{}
+		<-m.gate
+		if r.Content == m.failContent {
+			return nil, errors.New("request timeout")
+		}
+	}
+	return m.storeConfigProxy.RequestProxy(rpcClient, request, timeoutMills)
+}
+
+func Test_PublishConfigQueuedOutcome(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	proxy := &gatedPublishProxy{storeConfigProxy: storeConfigProxy{contents: map[string]string{}},
+		entered: make(chan struct{}, 3), gate: make(chan struct{}), failContent: "c"}
+	client.configProxy = proxy
+	param := vo.ConfigParam{DataId: "dataId", Group: "group"}
+	key := util.GetConfigCacheKey(param.DataId, param.Group, "")
+	var (
+		wg      sync.WaitGroup
+		result  model.PublishResult
+		errB    error
+		errC    error
+		publish = func(content string, queued int, run func(param vo.ConfigParam)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				param.Content = content
+				run(param)
+			}()
+			for client.writeQueue.queued(key) != queued {
+				time.Sleep(time.Millisecond)
+			}
+		}
+	)
+	publish("a", 1, func(param vo.ConfigParam) {
+		_, err := client.PublishConfig(param)
+		assert.Nil(t, err)
+	})
+	<-proxy.entered
+	publish("b", 2, func(param vo.ConfigParam) {
+		result, errB = client.PublishConfigWithResult(param)
+	})
+	publish("c", 3, func(param vo.ConfigParam) {
+		_, errC = client.PublishConfig(param)
+	})
+	close(proxy.gate)
+	wg.Wait()
+
+	// b overwrote the queued publish of a, and the failed c reports that the config keeps b
+	assert.Nil(t, errB)
+	assert.Equal(t, util.Md5("a"), result.Overwrote)
+	assert.NotNil(t, errC)
+	assert.Contains(t, errC.Error(), "request timeout")
+	assert.Contains(t, errC.Error(), "md5="+util.Md5("b"))
+	assert.Equal(t, "b", proxy.contents["dataId"])
+}
+
+func Test_RestoreSubscriptions(t *testing.T) {
+	dir := t.TempDir()
+	client := createConfigClientTest()
+	client.subscriptions = newConfigSubscriptions(true, dir, "")
+	onChange := func(namespace, group, dataId, data string) {}
+	assert.Nil(t, client.ListenConfig(vo.ConfigParam{DataId: "a", Group: "g1", OnChange: onChange}))
+	assert.Nil(t, client.ListenConfig(vo.ConfigParam{DataId: "b", Group: "g1", OnChange: onChange}))
+	assert.Nil(t, client.ListenConfig(vo.ConfigParam{DataId: "c", Group: "g2", OnChange: onChange}))
+	assert.Nil(t, client.CancelListenConfig(vo.ConfigParam{DataId: "b", Group: "g1"}))
+	client.CloseClient()
+
+	restarted := createConfigClientTest()
+	defer restarted.CloseClient()
+	restarted.subscriptions = newConfigSubscriptions(true, dir, "")
+	_, err := restarted.RestoreSubscriptions(vo.ConfigParam{})
+	assert.NotNil(t, err)
+	restored, err := restarted.RestoreSubscriptions(vo.ConfigParam{OnChange: onChange})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(restored))
+	assert.Equal(t, "a", restored[0].DataId)
+	assert.Equal(t, "c", restored[1].DataId)
+	assert.Equal(t, "g2", restored[1].Group)
+	assert.True(t, restarted.cacheMap.Has(util.GetConfigCacheKey("c", "g2", clientConfigWithOptions.NamespaceId)))
+}
+
+func Test_RefreshConfigs(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	content := "v1"
+	client.configProxy = &existenceConfigProxy{content: &content}
+	changed := make(chan string, 2)
+	onChange := func(namespace, group, dataId, data string) {
+		changed <- dataId + ":" + data
+	}
+	assert.Nil(t, client.ListenConfig(vo.ConfigParam{DataId: "refresh-a", Group: "refresh", OnChange: onChange}))
+	assert.Nil(t, client.ListenConfig(vo.ConfigParam{DataId: "refresh-b", Group: "refresh", OnChange: onChange}))
+
+	count, err := client.RefreshConfigs("refresh-a", "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	select {
+	case data := <-changed:
+		assert.Equal(t, "refresh-a:v1", data)
+	case <-time.After(3 * time.Second):
+		t.Fatal("OnChange is not called after refresh")
+	}
+
+	count, err = client.RefreshConfigs("", "other")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+}
+
+type fetchConcurrencyConfigProxy struct {
+	concurrencyConfigProxy
+	fetched int
+}
+
+func (m *fetchConcurrencyConfigProxy) QueryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+	m.mux.Lock()
+	m.running++
+	m.fetched++
+	if m.running > m.maxCount {
+		m.maxCount = m.running
+	}
+	m.mux.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	m.mux.Lock()
+	m.running--
+	m.mux.Unlock()
+	return &rpc_response.ConfigQueryResponse{Response: &rpc_response.Response{Success: true}, Content: "content of " + dataId}, nil
+}
+
+func Test_RefreshChangedConfigsConcurrently(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	proxy := &fetchConcurrencyConfigProxy{}
+	client.configProxy = proxy
+	client.fetchConcurrency = 3
+	var changed []cacheData
+	for i := 0; i < 10; i++ {
+		key := util.GetConfigCacheKey("fetch-"+strconv.Itoa(i), "fetch", "")
+		changed = append(changed, client.newCacheData(key, vo.ConfigParam{DataId: "fetch-" + strconv.Itoa(i), Group: "fetch"}, "",
+			&cacheDataListener{listener: func(namespace, group, dataId, data string) {}}))
+	}
+	client.refreshChangedConfigs(changed)
+	assert.Equal(t, 10, proxy.fetched)
+	assert.Equal(t, 3, proxy.maxCount)
+}
+
+type tenantConfigProxy struct {
+	MockConfigProxy
+	mux           sync.Mutex
+	changedTenant string
+	queried       []string
+}
+
+func (m *tenantConfigProxy) QueryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+	m.mux.Lock()
+	m.queried = append(m.queried, tenant)
+	m.mux.Unlock()
+	return &rpc_response.ConfigQueryResponse{Response: &rpc_response.Response{Success: true}, Content: "content of " + tenant}, nil
+}
+
+func (m *tenantConfigProxy) RequestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	listenRequest, ok := request.(*rpc_request.ConfigBatchListenRequest)
+	if !ok {
+		return m.MockConfigProxy.RequestProxy(rpcClient, request, timeoutMills)
+	}
+	response := &rpc_response.ConfigChangeBatchListenResponse{Response: &rpc_response.Response{Success: true}}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if listenRequest.Tenant == m.changedTenant {
+		// the change is reported once
+		m.changedTenant = ""
+		for _, c := range listenRequest.ConfigListenContexts {
+			// the tenant is omitted like some servers do
+			response.ChangedConfigs = append(response.ChangedConfigs, model.ConfigContext{DataId: c.DataId, Group: c.Group})
+		}
+	}
+	return response, nil
+}
+
+func Test_ListenConfigMultiNamespace(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	proxy := &tenantConfigProxy{changedTenant: "ns-b"}
+	client.configProxy = proxy
+	changed := map[string]chan string{"ns-a": make(chan string, 1), "ns-b": make(chan string, 1)}
+	for namespace, ch := range changed {
+		ch := ch
+		assert.Nil(t, client.ListenConfig(vo.ConfigParam{
+			DataId:      "multi-namespace",
+			Group:       "group",
+			NamespaceId: namespace,
+			OnChange: func(namespace, group, dataId, data string) {
+				ch <- namespace + ":" + data
+			},
+		}))
+	}
+	client.executeConfigListen()
+	select {
+	case data := <-changed["ns-b"]:
+		assert.Equal(t, "ns-b:content of ns-b", data)
+	case <-time.After(3 * time.Second):
+		t.Fatal("OnChange of ns-b is not called")
+	}
+	select {
+	case data := <-changed["ns-a"]:
+		t.Fatalf("OnChange of ns-a should not be called, got %s", data)
+	case <-time.After(100 * time.Millisecond):
+	}
+	proxy.mux.Lock()
+	assert.Equal(t, []string{"ns-b"}, proxy.queried)
+	proxy.mux.Unlock()
+
+	assert.Nil(t, client.CancelListenConfig(vo.ConfigParam{DataId: "multi-namespace", Group: "group", NamespaceId: "ns-a"}))
+	assert.False(t, client.cacheMap.Has(util.GetConfigCacheKey("multi-namespace", "group", "ns-a")))
+	assert.True(t, client.cacheMap.Has(util.GetConfigCacheKey("multi-namespace", "group", "ns-b")))
+}
+
+func Test_GetConfigWithNamespace(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	client.configProxy = &tenantConfigProxy{}
+	content, err := client.GetConfig(vo.ConfigParam{DataId: "with-namespace", Group: "group", NamespaceId: "ns-c"})
+	assert.Nil(t, err)
+	assert.Equal(t, "content of ns-c", content)
+}
+
+func Test_PublishAndDeleteConfigWithNamespace(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	proxy := &recordConfigProxy{}
+	client.configProxy = proxy
+	success, err := client.PublishConfig(vo.ConfigParam{DataId: "with-namespace", Group: "group", Content: "hello", NamespaceId: "ns-d"})
+	assert.Nil(t, err)
+	assert.True(t, success)
+	success, err = client.DeleteConfig(vo.ConfigParam{DataId: "with-namespace", Group: "group", NamespaceId: "ns-d"})
+	assert.Nil(t, err)
+	assert.True(t, success)
+	assert.Len(t, proxy.requests, 2)
+	assert.Equal(t, "ns-d", proxy.requests[0].(*rpc_request.ConfigPublishRequest).Tenant)
+	assert.Equal(t, "ns-d", proxy.requests[1].(*rpc_request.ConfigRemoveRequest).Tenant)
+}
+
+func Test_GetConfigStream(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	stream, err := client.GetConfigStream(vo.ConfigParam{DataId: "stream", Group: "group"})
+	assert.Nil(t, err)
+	b, err := ioutil.ReadAll(stream)
+	assert.Nil(t, err)
+	assert.Nil(t, stream.Close())
+	assert.Equal(t, "hello world", string(b))
+
+	cacheKey := util.GetConfigCacheKey("stream", "group", clientConfigWithOptions.NamespaceId)
+	failoverFile := client.configCacheDir + string(os.PathSeparator) + cacheKey + constant.FAILOVER_FILE_SUFFIX
+	assert.Nil(t, os.MkdirAll(client.configCacheDir, 0755))
+	assert.Nil(t, ioutil.WriteFile(failoverFile, []byte("failover content"), 0644))
+	defer os.Remove(failoverFile)
+	stream, err = client.GetConfigStream(vo.ConfigParam{DataId: "stream", Group: "group"})
+	assert.Nil(t, err)
+	_, isFile := stream.(*os.File)
+	assert.True(t, isFile)
+	b, err = ioutil.ReadAll(stream)
+	assert.Nil(t, err)
+	assert.Nil(t, stream.Close())
+	assert.Equal(t, "failover content", string(b))
+}
+
+func Test_GetConfigStreamFromSnapshot(t *testing.T) {
+	cfg := *clientConfigWithOptions
+	cfg.CacheDir = t.TempDir()
+	nc := nacos_client.NacosClient{}
+	_ = nc.SetServerConfig([]constant.ServerConfig{*serverConfigWithOptions})
+	_ = nc.SetClientConfig(cfg)
+	_ = nc.SetHttpAgent(&http_agent.HttpAgent{})
+	client, err := NewConfigClient(&nc)
+	assert.Nil(t, err)
+	defer client.CloseClient()
+	client.configProxy = &unavailableConfigProxy{}
+
+	param := vo.ConfigParam{DataId: "geo-ip", Group: "group"}
+	_, err = client.GetConfigStream(param)
+	assert.NotNil(t, err)
+
+	cache.WriteConfigToFile(util.GetConfigCacheKey(param.DataId, param.Group, ""), client.configCacheDir, "snapshot content")
+	stream, err := client.GetConfigStream(param)
+	assert.Nil(t, err)
+	_, isFile := stream.(*os.File)
+	assert.True(t, isFile)
+	b, err := ioutil.ReadAll(stream)
+	assert.Nil(t, err)
+	assert.Nil(t, stream.Close())
+	assert.Equal(t, "snapshot content", string(b))
+}
+
+func Test_PublishConfigStream(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	proxy := &recordConfigProxy{}
+	client.configProxy = proxy
+	content := strings.Repeat("geo-ip-entry\n", 1024)
+	published, err := client.PublishConfigStream(vo.ConfigParam{DataId: "stream", Group: "group"}, strings.NewReader(content))
+	assert.Nil(t, err)
+	assert.True(t, published)
+	assert.Equal(t, content, proxy.requests[len(proxy.requests)-1].(*rpc_request.ConfigPublishRequest).Content)
+
+	client.quota = quota.NewGuard(&constant.QuotaConfig{MaxContentSize: 1024})
+	_, err = client.PublishConfigStream(vo.ConfigParam{DataId: "stream", Group: "group"}, strings.NewReader(content))
+	_, exceeded := nacos_error.IsQuotaExceeded(err)
+	assert.True(t, exceeded)
+}
+
+func Test_BinaryConfig(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	data := []byte{0x30, 0x82, 0x00, 0xff}
+	recorder := &recordConfigProxy{}
+	client.configProxy = recorder
+	published, err := client.PublishBinaryConfig(vo.ConfigParam{DataId: "keystore", Group: "group"}, data)
+	assert.Nil(t, err)
+	assert.True(t, published)
+	content := recorder.requests[len(recorder.requests)-1].(*rpc_request.ConfigPublishRequest).Content
+	assert.Equal(t, util.EncodeBinaryContent(data), content)
+
+	client.configProxy = &existenceConfigProxy{content: &content}
+	binary, err := client.GetBinaryConfig(vo.ConfigParam{DataId: "keystore", Group: "group"})
+	assert.Nil(t, err)
+	assert.Equal(t, data, binary)
+
+	changed := make(chan []byte, 1)
+	assert.Nil(t, client.ListenConfig(vo.ConfigParam{DataId: "keystore", Group: "group",
+		OnBinaryChange: func(namespace, group, dataId string, data []byte) {
+			changed <- data
+		}}))
+	v, _ := client.cacheMap.Get(util.GetConfigCacheKey("keystore", "group", clientConfigWithOptions.NamespaceId))
+	client.refreshContentAndCheck(v.(cacheData), true)
+	select {
+	case binary = <-changed:
+		assert.Equal(t, data, binary)
+	case <-time.After(3 * time.Second):
+		t.Fatal("OnBinaryChange is not called")
+	}
+}
+
+type mapConfigProxy struct {
+	MockConfigProxy
+	mux      sync.Mutex
+	contents map[string]string
+}
+
+func (m *mapConfigProxy) set(dataId, content string) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.contents[dataId] = content
+}
+
+func (m *mapConfigProxy) QueryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return &rpc_response.ConfigQueryResponse{Response: &rpc_response.Response{Success: true}, Content: m.contents[dataId]}, nil
+}
+
+func generateTestCert(t *testing.T, serial int64) (certPem, keyPem string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "nacos-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Duration(serial) * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
+
+func Test_CertReloader(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	certPem, keyPem := generateTestCert(t, 1)
+	proxy := &mapConfigProxy{contents: map[string]string{"tls.crt": certPem, "tls.key": keyPem, "ca.crt": certPem}}
+	client.configProxy = proxy
+	reloaded := make(chan error, 4)
+	reloader, err := NewCertReloader(client, vo.CertParam{CertDataId: "tls.crt", KeyDataId: "tls.key", CaDataId: "ca.crt",
+		OnReload: func(cert *tls.Certificate, err error) {
+			reloaded <- err
+		}})
+	assert.Nil(t, err)
+	defer reloader.Close()
+	assert.Nil(t, <-reloaded)
+	assert.Equal(t, int64(1), reloader.Certificate().Leaf.SerialNumber.Int64())
+	assert.NotNil(t, reloader.CAPool())
+	assert.NotNil(t, reloader.ClientTLSConfig("nacos-test").VerifyConnection)
+
+	refresh := func(dataId string) {
+		v, ok := client.cacheMap.Get(util.GetConfigCacheKey(dataId, constant.DEFAULT_GROUP, clientConfigWithOptions.NamespaceId))
+		assert.True(t, ok)
+		client.refreshContentAndCheck(v.(cacheData), true)
+	}
+	newCertPem, newKeyPem := generateTestCert(t, 2)
+	// the certificate is rotated before the private key, the previous pair is kept in between
+	proxy.set("tls.crt", newCertPem)
+	refresh("tls.crt")
+	assert.NotNil(t, <-reloaded)
+	cert, err := reloader.GetCertificate(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), cert.Leaf.SerialNumber.Int64())
+
+	proxy.set("tls.key", newKeyPem)
+	refresh("tls.key")
+	assert.Nil(t, <-reloaded)
+	assert.Equal(t, int64(2), reloader.Certificate().Leaf.SerialNumber.Int64())
+	assert.True(t, reloader.NotAfter().After(time.Now().Add(time.Hour)))
+
+	_, err = NewCertReloader(client, vo.CertParam{CertDataId: "tls.crt"})
+	assert.NotNil(t, err)
+}
+
+func Test_GetProperties(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	client.configProxy = &mapConfigProxy{contents: map[string]string{
+		"application.yaml":            "server:\n  port: 8080\nspring:\n  application:\n    name: demo\n",
+		"application-prod.properties": "server.port=9090\ngreeting=hello ${spring.application.name} on ${server.port}",
+	}}
+	configs := []vo.ConfigParam{{DataId: "application.yaml", Group: "DEFAULT_GROUP"}, {DataId: "application-prod.properties", Group: "DEFAULT_GROUP"}}
+	props, err := client.GetProperties(vo.PropertiesParam{Configs: configs})
+	assert.Nil(t, err)
+	assert.Equal(t, "9090", props["server.port"])
+	assert.Equal(t, "hello ${spring.application.name} on ${server.port}", props["greeting"])
+
+	props, err = client.GetProperties(vo.PropertiesParam{Configs: configs, ResolvePlaceholders: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "hello demo on 9090", props["greeting"])
+
+	_, err = client.GetProperties(vo.PropertiesParam{})
+	assert.NotNil(t, err)
+}
+
+func Test_SpringConfigReader(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	client.configProxy = &mapConfigProxy{contents: map[string]string{
+		"common.yaml":          "server:\n  port: 8000\nlog:\n  level: info\ncommon: shared\n",
+		"datasource.yaml":      "db:\n  url: mysql://shared\n",
+		"demo.yaml":            "server:\n  port: 8080\n",
+		"demo-prod.yaml":       "server:\n  port: 9090\ndb:\n  url: mysql://prod\ngreeting: hello ${common}\n",
+		"demo-prod.properties": "server.port=1",
+	}}
+	_, err := NewSpringConfigReader(client, vo.SpringConfigParam{})
+	assert.NotNil(t, err)
+	reader, err := NewSpringConfigReader(client, vo.SpringConfigParam{
+		Application:      "demo",
+		Profiles:         []string{"prod"},
+		FileExtension:    "yaml",
+		SharedConfigs:    []vo.ConfigParam{{DataId: "common.yaml"}},
+		ExtensionConfigs: []vo.ConfigParam{{DataId: "datasource.yaml", Group: "db"}},
+	})
+	assert.Nil(t, err)
+
+	var dataIds []string
+	for _, config := range reader.Configs() {
+		dataIds = append(dataIds, config.DataId)
+	}
+	assert.Equal(t, []string{"common.yaml", "datasource.yaml", "demo", "demo.yaml", "demo-prod.yaml"}, dataIds)
+
+	env, err := reader.Environment()
+	assert.Nil(t, err)
+	assert.Equal(t, "demo", env.Name)
+	var names []string
+	for _, source := range env.PropertySources {
+		names = append(names, source.Name)
+	}
+	assert.Equal(t, []string{"demo-prod.yaml,DEFAULT_GROUP", "demo.yaml,DEFAULT_GROUP", "datasource.yaml,db", "common.yaml,DEFAULT_GROUP"}, names)
+
+	props, err := reader.Properties()
+	assert.Nil(t, err)
+	assert.Equal(t, "9090", props["server.port"])
+	assert.Equal(t, "mysql://prod", props["db.url"])
+	assert.Equal(t, "info", props["log.level"])
+	assert.Equal(t, "hello ${common}", props["greeting"])
+
+	reader.param.ResolvePlaceholders = true
+	props, err = reader.Properties()
+	assert.Nil(t, err)
+	assert.Equal(t, "hello shared", props["greeting"])
+}
+
+type manifestConfigProxy struct {
+	MockConfigProxy
+	items []model.ConfigItem
+}
+
+func (m *manifestConfigProxy) SearchConfigProxy(param vo.SearchConfigParam, tenant, accessKey, secretKey string) (*model.ConfigPage, error) {
+	return &model.ConfigPage{TotalCount: len(m.items), PageNumber: 1, PagesAvailable: 1, PageItems: m.items}, nil
+}
+
+func Test_NamespaceChecksum(t *testing.T) {
+	client := createConfigClientTest()
+	client.configProxy = &manifestConfigProxy{items: []model.ConfigItem{
+		{DataId: "b", Group: "group", Md5: "md5-b"},
+		{DataId: "a", Group: "group", Md5: "md5-a"},
+		{DataId: "c", Group: "group-other", Md5: "md5-c"},
+	}}
+	remote, err := client.GetNamespaceChecksum("", "group")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"a": "md5-a", "b": "md5-b"}, remote.Md5s)
+	assert.Equal(t, util.Md5("a:md5-a\nb:md5-b\n"), remote.Checksum)
+
+	local := &model.ConfigManifest{Checksum: manifestChecksum(map[string]string{"a": "md5-a", "b": "md5-b"}),
+		Md5s: map[string]string{"a": "md5-a", "b": "md5-b"}}
+	assert.True(t, CompareConfigManifest(local, remote).Consistent())
+
+	local.Md5s = map[string]string{"a": "md5-x", "d": "md5-d"}
+	local.Checksum = manifestChecksum(local.Md5s)
+	diff := CompareConfigManifest(local, remote)
+	assert.False(t, diff.Consistent())
+	assert.Equal(t, []string{"b"}, diff.Missing)
+	assert.Equal(t, []string{"d"}, diff.Extra)
+	assert.Equal(t, []string{"a"}, diff.Mismatch)
+}
+
+type storeConfigProxy struct {
+	MockConfigProxy
+	mux      sync.Mutex
+	contents map[string]string
+	casMd5s  []string
+	// publishedIds is the dataIds published in order
+	publishedIds []string
+	// lastModified is returned as the modified time of every config
+	lastModified int64
+}
+
+func (m *storeConfigProxy) QueryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	content, ok := m.contents[dataId]
+	if !ok {
+		return &rpc_response.ConfigQueryResponse{Response: &rpc_response.Response{ErrorCode: constant.CONFIG_NOT_FOUND}}, nil
+	}
+	return &rpc_response.ConfigQueryResponse{Response: &rpc_response.Response{Success: true}, Content: content, LastModified: m.lastModified}, nil
+}
+
+func (m *storeConfigProxy) RequestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	switch r := request.(type) {
+	case *rpc_request.ConfigPublishRequest:
+		m.casMd5s = append(m.casMd5s, r.CasMd5)
+		m.publishedIds = append(m.publishedIds, r.DataId)
+		m.contents[r.DataId] = r.Content
+	case *rpc_request.ConfigRemoveRequest:
+		delete(m.contents, r.DataId)
+	}
+	return &rpc_response.MockResponse{Response: &rpc_response.Response{Success: true}}, nil
+}
+
+func Test_PromoteConfig(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	approveErr := errors.New("not approved")
+	var promotedErr error
+	clientConfig, _ := client.GetClientConfig()
+	clientConfig.PublishWorkflowCfg = &constant.PublishWorkflowConfig{
+		Approve: func(namespace, group, dataId, content string) error {
+			return approveErr
+		},
+		OnPromoted: func(namespace, group, dataId string, err error) {
+			promotedErr = err
+		},
+	}
+	_ = client.SetClientConfig(clientConfig)
+	proxy := &storeConfigProxy{contents: map[string]string{"dataId": "v1"}}
+	client.configProxy = proxy
+
+	published, err := client.PublishConfig(vo.ConfigParam{DataId: "dataId", Group: "group", Content: "v2", Stage: true})
+	assert.Nil(t, err)
+	assert.True(t, published)
+	assert.Equal(t, map[string]string{"dataId": "v1", "dataId.staging": "v2"}, proxy.contents)
+
+	promoted, err := client.Promote(vo.ConfigParam{DataId: "dataId", Group: "group"})
+	assert.ErrorIs(t, err, approveErr)
+	assert.ErrorIs(t, promotedErr, approveErr)
+	assert.False(t, promoted)
+	assert.Equal(t, "v1", proxy.contents["dataId"])
+
+	approveErr = nil
+	promoted, err = client.Promote(vo.ConfigParam{DataId: "dataId", Group: "group"})
+	assert.Nil(t, err)
+	assert.Nil(t, promotedErr)
+	assert.True(t, promoted)
+	assert.Equal(t, map[string]string{"dataId": "v2"}, proxy.contents)
+	assert.Equal(t, util.Md5("v1"), proxy.casMd5s[len(proxy.casMd5s)-1])
+
+	_, err = client.Promote(vo.ConfigParam{DataId: "dataId", Group: "group"})
+	assert.Error(t, err)
+}
+
+func Test_PublishConfigWithResult(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	proxy := &storeConfigProxy{contents: map[string]string{}, lastModified: 1700000000000}
+	client.configProxy = proxy
+
+	result, err := client.PublishConfigWithResult(vo.ConfigParam{DataId: "dataId", Group: "group", Content: "v1"})
+	assert.Nil(t, err)
+	assert.True(t, result.Published)
+	assert.Equal(t, model.PublishCreate, result.Operation)
+	assert.Equal(t, util.Md5("v1"), result.Md5)
+	assert.Empty(t, result.CasMd5)
+	assert.Equal(t, time.UnixMilli(1700000000000), result.LastModified)
+	assert.Equal(t, "group", result.Group)
+
+	// chain the CAS publish by the md5 of the previous result
+	result, err = client.PublishConfigWithResult(vo.ConfigParam{DataId: "dataId", Group: "group", Content: "v2", CasMd5: result.Md5})
+	assert.Nil(t, err)
+	assert.Equal(t, model.PublishUpdate, result.Operation)
+	assert.Equal(t, util.Md5("v1"), result.CasMd5)
+	assert.Equal(t, util.Md5("v2"), result.Md5)
+	assert.Equal(t, util.Md5("v1"), proxy.casMd5s[1])
+}
+
+func Test_AuditConfigOperations(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	client.configProxy = &storeConfigProxy{contents: map[string]string{}}
+	var records []audit.Record
+	client.auditor = audit.NewRecorder(audit.SinkFunc(func(record audit.Record) error {
+		records = append(records, record)
+		return nil
+	}), "nacos", "app", nil)
+
+	_, err := client.PublishConfig(vo.ConfigParam{DataId: "dataId", Group: "group", Content: "v1"})
+	assert.Nil(t, err)
+	_, err = client.DeleteConfig(vo.ConfigParam{DataId: "dataId", Group: "group"})
+	assert.Nil(t, err)
+	// the invalid params are not sent, so they are not audited
+	_, err = client.PublishConfig(vo.ConfigParam{DataId: "dataId", Group: "group"})
+	assert.Error(t, err)
+
+	assert.Equal(t, 2, len(records))
+	assert.Equal(t, audit.OpPublishConfig, records[0].Op)
+	assert.Equal(t, "nacos", records[0].Who)
+	assert.Equal(t, "group", records[0].Group)
+	assert.Equal(t, "dataId", records[0].Key)
+	assert.Equal(t, util.Md5("v1"), records[0].Target)
+	assert.Equal(t, audit.ResultOk, records[0].Result)
+	assert.Equal(t, audit.OpDeleteConfig, records[1].Op)
+	assert.Equal(t, audit.ResultOk, records[1].Result)
+}
+
+func Test_PublishConfigAt(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	proxy := &storeConfigProxy{contents: map[string]string{}}
+	client.configProxy = proxy
+
+	var (
+		mux    sync.Mutex
+		events []model.ScheduledPublishEventType
+	)
+	effectiveTime := time.Now().Add(50 * time.Millisecond)
+	s, err := client.PublishConfigAt(vo.ConfigParam{DataId: "dataId", Group: "group", Content: "v1",
+		OnScheduleEvent: func(event model.ScheduledPublishEvent) {
+			mux.Lock()
+			defer mux.Unlock()
+			events = append(events, event.Type)
+		}}, effectiveTime)
+	assert.Nil(t, err)
+	select {
+	case <-s.Done():
+	case <-time.After(3 * time.Second):
+		t.Fatal("config is not published")
+	}
+	assert.Nil(t, s.Err())
+	assert.False(t, time.Now().Before(effectiveTime))
+	assert.Equal(t, "v1", proxy.contents["dataId"])
+	mux.Lock()
+	assert.Equal(t, []model.ScheduledPublishEventType{model.ScheduledPublishScheduled, model.ScheduledPublishPublished}, events)
+	mux.Unlock()
+
+	s, err = client.PublishConfigAt(vo.ConfigParam{DataId: "dataId", Group: "group", Content: "v2"}, time.Now().Add(time.Hour))
+	assert.Nil(t, err)
+	s.Cancel()
+	<-s.Done()
+	assert.Equal(t, ErrScheduledPublishCanceled, s.Err())
+	assert.Equal(t, model.ScheduledPublishCanceled, s.Events()[1].Type)
+	assert.Equal(t, "v1", proxy.contents["dataId"])
+}
+
+// changingConfigProxy reports every listened config as changed with a new content each time
+type changingConfigProxy struct {
+	MockConfigProxy
+	version int64
+}
+
+func (m *changingConfigProxy) QueryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+	content := "content-" + strconv.FormatInt(atomic.AddInt64(&m.version, 1), 10)
+	return &rpc_response.ConfigQueryResponse{Response: &rpc_response.Response{Success: true}, Content: content}, nil
+}
+
+func (m *changingConfigProxy) RequestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	listenRequest, ok := request.(*rpc_request.ConfigBatchListenRequest)
+	if !ok {
+		return m.MockConfigProxy.RequestProxy(rpcClient, request, timeoutMills)
+	}
+	response := &rpc_response.ConfigChangeBatchListenResponse{Response: &rpc_response.Response{Success: true}}
+	for _, c := range listenRequest.ConfigListenContexts {
+		response.ChangedConfigs = append(response.ChangedConfigs, model.ConfigContext{DataId: c.DataId, Group: c.Group, Tenant: c.Tenant})
+	}
+	return response, nil
+}
+
+func Test_ConcurrentListenCancelPoll(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	client.configProxy = &changingConfigProxy{}
+	param := vo.ConfigParam{DataId: "concurrent", Group: localConfigTest.Group}
+	key := util.GetConfigCacheKey(param.DataId, param.Group, "")
+
+	stop := make(chan struct{})
+	polled := make(chan struct{})
+	go func() {
+		defer close(polled)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				client.executeConfigListen()
+			}
+		}
+	}()
+
+	t.Run("no listener is lost", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p := param
+				p.OnChange = func(namespace, group, dataId, data string) {}
+				assert.Nil(t, client.ListenConfig(p))
+			}()
+		}
+		wg.Wait()
+		v, ok := client.cacheMap.Get(key)
+		assert.True(t, ok)
+		assert.Len(t, v.(cacheData).listeners.items(), 20)
+	})
+
+	t.Run("canceled config is not put back", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			ctx, cancel := context.WithCancel(context.Background())
+			_, err := client.Tail(ctx, param)
+			assert.Nil(t, err)
+			cancel()
+			assert.Nil(t, client.CancelListenConfig(param))
+			// the tail is removed once ctx is done
+			assert.Eventually(t, func() bool {
+				return !client.cacheMap.Has(key)
+			}, time.Second, time.Millisecond)
+		}
+	})
+
+	close(stop)
+	<-polled
+}
+
+// timeoutConfigProxy record the timeout of listen and query requests
+type timeoutConfigProxy struct {
+	changingConfigProxy
+	mux            sync.Mutex
+	listenTimeouts []uint64
+	queryTimeouts  []uint64
+}
+
+func (m *timeoutConfigProxy) QueryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+	m.mux.Lock()
+	m.queryTimeouts = append(m.queryTimeouts, timeout)
+	m.mux.Unlock()
+	return m.changingConfigProxy.QueryConfig(dataId, group, tenant, timeout, notify, client)
+}
+
+func (m *timeoutConfigProxy) RequestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	if _, ok := request.(*rpc_request.ConfigBatchListenRequest); ok {
+		m.mux.Lock()
+		m.listenTimeouts = append(m.listenTimeouts, timeoutMills)
+		m.mux.Unlock()
+	}
+	return m.changingConfigProxy.RequestProxy(rpcClient, request, timeoutMills)
+}
+
+// priorityConfigProxy record the priorities of queries and the timeouts of publish and delete requests
+type priorityConfigProxy struct {
+	timeoutConfigProxy
+	priorities      []vo.ConfigPriority
+	requestTimeouts []uint64
+}
+
+func (m *priorityConfigProxy) QueryConfigWithPriority(dataId, group, tenant string, timeout uint64, notify bool,
+	priority vo.ConfigPriority, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+	m.mux.Lock()
+	m.priorities = append(m.priorities, priority)
+	m.mux.Unlock()
+	return m.timeoutConfigProxy.QueryConfig(dataId, group, tenant, timeout, notify, client)
+}
+
+func (m *priorityConfigProxy) RequestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+	switch request.(type) {
+	case *rpc_request.ConfigPublishRequest, *rpc_request.ConfigRemoveRequest:
+		m.mux.Lock()
+		m.requestTimeouts = append(m.requestTimeouts, timeoutMills)
+		m.mux.Unlock()
+	}
+	return m.timeoutConfigProxy.RequestProxy(rpcClient, request, timeoutMills)
+}
+
+func Test_ConfigParamTimeoutAndPriority(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	proxy := &priorityConfigProxy{}
+	client.configProxy = proxy
+	param := vo.ConfigParam{DataId: "critical", Group: localConfigTest.Group}
+
+	_, err := client.GetConfig(param)
+	assert.Nil(t, err)
+	param.TimeoutMs = 500
+	param.Priority = vo.ConfigPriorityHigh
+	_, err = client.GetConfig(param)
+	assert.Nil(t, err)
+	clientConfig, _ := client.GetClientConfig()
+	assert.Equal(t, []uint64{clientConfig.TimeoutMs, 500}, proxy.queryTimeouts)
+	assert.Equal(t, []vo.ConfigPriority{vo.ConfigPriorityNormal, vo.ConfigPriorityHigh}, proxy.priorities)
+
+	param.Content = "content"
+	_, err = client.PublishConfig(param)
+	assert.Nil(t, err)
+	_, err = client.DeleteConfig(vo.ConfigParam{DataId: param.DataId, Group: param.Group})
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{500, constant.DEFAULT_TIMEOUT_MILLS}, proxy.requestTimeouts)
+}
+
+func Test_ConfigQuota(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	var events []model.QuotaEvent
+	client.quota = quota.NewGuard(&constant.QuotaConfig{
+		MaxContentSize:  5,
+		MaxListenedKeys: 2,
+		WarnRatio:       0.5,
+		OnEvent: func(event model.QuotaEvent) {
+			events = append(events, event)
+		},
+	})
+
+	_, err := client.PublishConfig(vo.ConfigParam{DataId: "quota", Group: localConfigTest.Group, Content: "too large"})
+	exceeded, ok := nacos_error.IsQuotaExceeded(err)
+	assert.True(t, ok)
+	assert.Equal(t, 9, exceeded.Used)
+	_, err = client.GetConfig(vo.ConfigParam{DataId: "quota", Group: localConfigTest.Group})
+	_, ok = nacos_error.IsQuotaExceeded(err)
+	assert.True(t, ok)
+
+	onChange := func(namespace, group, dataId, data string) {}
+	assert.Nil(t, client.ListenConfig(vo.ConfigParam{DataId: "quota-1", Group: localConfigTest.Group, OnChange: onChange}))
+	assert.Nil(t, client.ListenConfig(vo.ConfigParam{DataId: "quota-1", Group: localConfigTest.Group, OnChange: onChange}))
+	assert.Nil(t, client.ListenConfig(vo.ConfigParam{DataId: "quota-2", Group: localConfigTest.Group, OnChange: onChange}))
+	err = client.ListenConfig(vo.ConfigParam{DataId: "quota-3", Group: localConfigTest.Group, OnChange: onChange})
+	exceeded, ok = nacos_error.IsQuotaExceeded(err)
+	assert.True(t, ok)
+	assert.Equal(t, string(model.QuotaListenedKeys), exceeded.Quota)
+	_, err = client.Tail(context.Background(), vo.ConfigParam{DataId: "quota-3", Group: localConfigTest.Group})
+	assert.NotNil(t, err)
+
+	assert.Len(t, events, 5)
+	assert.Equal(t, model.QuotaListenedKeys, events[2].Kind)
+	assert.Equal(t, 1, events[2].Used)
+	assert.False(t, events[2].Exceeded)
+	assert.True(t, events[3].Exceeded)
+}
+
+func Test_ConfigQuotaConcurrentListen(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	client.quota = quota.NewGuard(&constant.QuotaConfig{MaxListenedKeys: 4})
+	var (
+		wg       sync.WaitGroup
+		exceeded int32
+	)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := client.ListenConfig(vo.ConfigParam{DataId: "quota-" + strconv.Itoa(i), Group: localConfigTest.Group,
+				OnChange: func(namespace, group, dataId, data string) {}})
+			if _, ok := nacos_error.IsQuotaExceeded(err); ok {
+				atomic.AddInt32(&exceeded, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 4, client.cacheMap.Count())
+	assert.Equal(t, int32(12), atomic.LoadInt32(&exceeded))
+}
+
+func Test_ConfigQuotaContentSizeOfCache(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	client.quota = quota.NewGuard(&constant.QuotaConfig{MaxContentSize: 8})
+	param := vo.ConfigParam{DataId: "quota-snapshot", Group: localConfigTest.Group, OnChange: func(namespace, group, dataId, data string) {}}
+	cacheKey := util.GetConfigCacheKey(param.DataId, param.Group, "")
+	cache.WriteConfigToFile(cacheKey, client.configCacheDir, "oversized snapshot")
+	defer os.Remove(cache.GetFileName(cacheKey, client.configCacheDir))
+
+	// the oversized snapshot is not cached
+	assert.Nil(t, client.ListenConfig(param))
+	v, ok := client.cacheMap.Get(cacheKey)
+	assert.True(t, ok)
+	assert.Equal(t, "", v.(cacheData).content)
+	assert.Equal(t, "", v.(cacheData).md5)
+
+	// the oversized content from server is not cached by the refresh
+	content := "oversized content"
+	client.configProxy = &existenceConfigProxy{content: &content}
+	err := client.refreshContentAndCheck(v.(cacheData), true)
+	_, ok = nacos_error.IsQuotaExceeded(err)
+	assert.True(t, ok)
+	v, _ = client.cacheMap.Get(cacheKey)
+	assert.Equal(t, "", v.(cacheData).content)
+
+	// nor is it returned from the snapshot when the server fails
+	client.configProxy = &unavailableConfigProxy{}
+	_, err = client.GetConfig(vo.ConfigParam{DataId: param.DataId, Group: param.Group})
+	_, ok = nacos_error.IsQuotaExceeded(err)
+	assert.True(t, ok)
+}
+
+func Test_SnapshotNotCreateRpcClient(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	client.configProxy = &ConfigProxy{}
+	snapshot := client.Snapshot().(configClientSnapshot)
+	client.configProxy = &MockConfigProxy{}
+	assert.False(t, snapshot.ServerHealthy)
+	_, ok := rpc.LookupClient(rpcClientName("0", client))
+	assert.False(t, ok)
+}
+
+type pendingConfigProxy struct {
+	MockConfigProxy
+	mux     sync.Mutex
+	queries int
+	pending int
+}
+
+func (m *pendingConfigProxy) QueryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.queries++; m.queries <= m.pending {
+		return &rpc_response.ConfigQueryResponse{}, nil
+	}
+	return &rpc_response.ConfigQueryResponse{Content: "ready"}, nil
+}
+
+func Test_WaitForConfig(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	client := createConfigClientWithClockTest(fakeClock)
+	defer client.CloseClient()
+	proxy := &pendingConfigProxy{pending: 2}
+	client.configProxy = proxy
+	param := vo.ConfigParam{DataId: "bootstrap", Group: localConfigTest.Group}
+	done := make(chan struct{})
+	go func() {
+		// the listen executor may wait on the clock as well, so keep advancing until done
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+				fakeClock.Advance(constant.WAIT_CONFIG_BACKOFF_MAX)
+			}
+		}
+	}()
+	content, err := client.WaitForConfig(context.Background(), param)
+	close(done)
+	assert.Nil(t, err)
+	assert.Equal(t, "ready", content)
+	assert.Equal(t, 3, proxy.queries)
+
+	client.configProxy = &pendingConfigProxy{pending: 1 << 30}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = client.WaitForConfig(ctx, param)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func Test_ListenConfigWithContext(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	proxy := &timeoutConfigProxy{}
+	client.configProxy = proxy
+	param := vo.ConfigParam{DataId: "deadline", Group: localConfigTest.Group, OnChange: func(namespace, group, dataId, data string) {}}
+	key := util.GetConfigCacheKey(param.DataId, param.Group, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, client.ListenConfigWithContext(ctx, param))
+	client.executeConfigListen()
+	proxy.mux.Lock()
+	assert.Len(t, proxy.listenTimeouts, 1)
+	assert.True(t, proxy.listenTimeouts[0] > 0 && proxy.listenTimeouts[0] <= 1000)
+	assert.Len(t, proxy.queryTimeouts, 1)
+	assert.True(t, proxy.queryTimeouts[0] > 0 && proxy.queryTimeouts[0] <= 1000)
+	proxy.mux.Unlock()
+
+	// the listener without deadline lifts the bound
+	assert.Nil(t, client.ListenConfig(param))
+	client.executeConfigListen()
+	proxy.mux.Lock()
+	assert.Equal(t, uint64(constant.DEFAULT_TIMEOUT_MILLS), proxy.listenTimeouts[len(proxy.listenTimeouts)-1])
+	proxy.mux.Unlock()
+	assert.Nil(t, client.CancelListenConfig(param))
+	// the listener bound to ctx is kept by CancelListenConfig
+	assert.True(t, client.cacheMap.Has(key))
+	cancel()
+
+	// the listener is removed once ctx is done
+	ctx, cancel = context.WithCancel(context.Background())
+	assert.Nil(t, client.ListenConfigWithContext(ctx, param))
+	assert.True(t, client.cacheMap.Has(key))
+	cancel()
+	assert.Eventually(t, func() bool {
+		return !client.cacheMap.Has(key)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_ListenConfigPath(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	content := `{"features":{"checkout":{"enabled":true},"search":{"enabled":true}}}`
+	client.configProxy = &existenceConfigProxy{content: &content}
+	changed := make(chan string, 4)
+	param := vo.ConfigParam{DataId: "features.json", Group: "path", OnChange: func(namespace, group, dataId, data string) {
+		changed <- data
+	}}
+	assert.NotNil(t, client.ListenConfigPath(param, "features.checkout"))
+	assert.Nil(t, client.ListenConfigPath(param, "$.features.checkout.*"))
+	refresh := func() {
+		_, err := client.RefreshConfigs("features.json", "path")
+		assert.Nil(t, err)
+	}
+
+	// neither the current value nor the change of other subtree is delivered
+	refresh()
+	content = `{"features":{"checkout":{"enabled":true},"search":{"enabled":false}}}`
+	refresh()
+	content = `{"features":{"checkout":{"enabled":false},"search":{"enabled":false}}}`
+	refresh()
+	select {
+	case data := <-changed:
+		assert.Equal(t, "[false]", data)
+	case <-time.After(3 * time.Second):
+		t.Fatal("OnChange is not called after the selected value changed")
+	}
+	select {
+	case data := <-changed:
+		t.Fatalf("OnChange should be called once, got %s", data)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+type groupConfigProxy struct {
+	MockConfigProxy
+	mux      sync.Mutex
+	contents map[string]string
+}
+
+func (m *groupConfigProxy) set(group, content string) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if len(content) == 0 {
+		delete(m.contents, group)
+		return
+	}
+	m.contents[group] = content
+}
+
+func (m *groupConfigProxy) QueryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	content, ok := m.contents[group]
+	if !ok {
+		return &rpc_response.ConfigQueryResponse{Response: &rpc_response.Response{ErrorCode: constant.CONFIG_NOT_FOUND}}, nil
+	}
+	return &rpc_response.ConfigQueryResponse{Response: &rpc_response.Response{Success: true}, Content: content}, nil
+}
+
+func Test_ConfigFallbackGroups(t *testing.T) {
+	client := createConfigClientTest()
+	defer client.CloseClient()
+	proxy := &groupConfigProxy{contents: map[string]string{"global": "global content"}}
+	client.configProxy = proxy
+	param := vo.ConfigParam{DataId: "fallback", Group: "service", FallbackGroups: []string{"team", "global"}}
+	content, err := client.GetConfig(param)
+	assert.Nil(t, err)
+	assert.Equal(t, "global content", content)
+
+	changed := make(chan string, 4)
+	param.OnChange = func(namespace, group, dataId, data string) {
+		changed <- group + ":" + data
+	}
+	assert.Nil(t, client.ListenConfig(param))
+	expect := func(want string) {
+		_, err := client.RefreshConfigs("fallback", "")
+		assert.Nil(t, err)
+		select {
+		case data := <-changed:
+			assert.Equal(t, want, data)
+		case <-time.After(3 * time.Second):
+			t.Fatalf("OnChange is not called with %s", want)
+		}
+	}
+	// the change of the group overridden is not delivered
+	proxy.set("team", "team content")
+	expect("team:team content")
+	proxy.set("global", "global content changed")
+	proxy.set("service", "service content")
+	expect("service:service content")
+	proxy.set("service", "")
+	expect("team:team content")
+	select {
+	case data := <-changed:
+		t.Fatalf("OnChange should not be called, got %s", data)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	assert.Nil(t, client.CancelListenConfig(param))
+	assert.Equal(t, 0, client.cacheMap.Count())
+}
+
+func Test_ListenConfigAck(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "ack")
+	assert.Nil(t, err)
+	defer os.RemoveAll(cacheDir)
+	newClient := func(content *string, deliveries chan *model.ConfigDelivery) (*ConfigClient, *clock.FakeClock) {
+		fakeClock := clock.NewFakeClock(time.Now())
+		clientConfig := *clientConfigWithOptions
+		clientConfig.Clock, clientConfig.CacheDir = fakeClock, cacheDir
+		nc := nacos_client.NacosClient{}
+		_ = nc.SetServerConfig([]constant.ServerConfig{*serverConfigWithOptions})
+		_ = nc.SetClientConfig(clientConfig)
+		_ = nc.SetHttpAgent(&http_agent.HttpAgent{})
+		client, err := NewConfigClient(&nc)
+		assert.Nil(t, err)
+		client.configProxy = &existenceConfigProxy{content: content}
+		assert.Nil(t, client.ListenConfig(vo.ConfigParam{
+			DataId:         "ack",
+			Group:          "group",
+			AckTimeout:     time.Minute,
+			AckMaxAttempts: 3,
+			OnChangeAck: func(delivery *model.ConfigDelivery) {
+				deliveries <- delivery
+			},
+		}))
+		return client, fakeClock
+	}
+	receive := func(deliveries chan *model.ConfigDelivery) *model.ConfigDelivery {
+		select {
+		case delivery := <-deliveries:
+			return delivery
+		case <-time.After(3 * time.Second):
+			t.Fatal("the change is not delivered")
+			return nil
+		}
+	}
+	content := "v1"
+	deliveries := make(chan *model.ConfigDelivery, 4)
+	client, fakeClock := newClient(&content, deliveries)
+	defer client.CloseClient()
+	content = "v2"
+	_, err = client.RefreshConfigs("ack", "group")
+	assert.Nil(t, err)
+	delivery := receive(deliveries)
+	assert.Equal(t, "v2", delivery.Content)
+	assert.Equal(t, 1, delivery.Attempt)
+	fakeClock.Advance(time.Minute)
+	assert.Equal(t, 2, receive(deliveries).Attempt)
+	pendingFile := filepath.Join(client.configCacheDir, ackPendingDir, util.GetConfigCacheKey("ack", "group", clientConfigWithOptions.NamespaceId)+"@@0")
+	assert.FileExists(t, pendingFile)
+	// only the md5 of the change is persisted
+	persisted, err := ioutil.ReadFile(pendingFile)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(persisted), `"v2"`)
+	assert.Contains(t, string(persisted), util.Md5("v2"))
+
+	// the unacknowledged change is delivered again after restart
+	restartDeliveries := make(chan *model.ConfigDelivery, 4)
+	restarted, restartedClock := newClient(&content, restartDeliveries)
+	defer restarted.CloseClient()
+	delivery = receive(restartDeliveries)
+	assert.Equal(t, "v2", delivery.Content)
+	assert.Equal(t, 3, delivery.Attempt)
+	delivery.Ack()
+	assert.NoFileExists(t, pendingFile)
+	restartedClock.Advance(time.Minute)
+
+	// the change is given up after AckMaxAttempts
+	fakeClock.Advance(time.Minute)
+	assert.Equal(t, 3, receive(deliveries).Attempt)
+	fakeClock.Advance(time.Minute)
+	assert.NoFileExists(t, pendingFile)
+	select {
+	case delivery = <-deliveries:
+		t.Fatalf("the change should not be delivered again, got attempt %d", delivery.Attempt)
+	case delivery = <-restartDeliveries:
+		t.Fatalf("the acknowledged change should not be delivered again, got attempt %d", delivery.Attempt)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// the unacknowledged change replaced by a newer one is not delivered after restart
+	stale, _ := json.Marshal(ackRecord{Group: "group", DataId: "ack", Md5: util.Md5("v1"), Attempt: 1})
+	assert.Nil(t, ioutil.WriteFile(pendingFile, stale, 0600))
+	staleDeliveries := make(chan *model.ConfigDelivery, 4)
+	staleClient, _ := newClient(&content, staleDeliveries)
+	defer staleClient.CloseClient()
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(pendingFile)
+		return os.IsNotExist(err)
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, len(staleDeliveries))
+}
+
+func TestCacheDataListener_Breaker(t *testing.T) {
+	clk := clock.NewFakeClock(time.Now())
+	var (
+		mutex  sync.Mutex
+		states []model.ListenerBreakerState
+		fail   int32 = 1
+	)
+	cfg := &constant.ListenerBreakerConfig{FailureThreshold: 2, OpenDuration: time.Minute, OnStateChange: func(health model.ListenerHealth) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		states = append(states, health.State)
+	}}
+	delivered := make(chan string, 4)
+	l := &cacheDataListener{
+		ordered: true,
+		listenerErr: func(namespace, group, dataId, data string) error {
+			if data == "panic" {
+				panic("boom")
+			}
+			if atomic.LoadInt32(&fail) == 1 {
+				return errors.New("reload failed")
+			}
+			delivered <- data
+			return nil
+		},
+		breaker: newListenerBreaker(cfg, clk, "", "group", "dataId"),
+	}
+	event := func(content string) *listenerEvent {
+		return &listenerEvent{group: "group", dataId: "dataId", content: content}
+	}
+
+	l.invoke(event("v1"))
+	l.invoke(event("panic"))
+	health := l.breaker.getHealth()
+	assert.Equal(t, model.ListenerBreakerOpen, health.State)
+	assert.Equal(t, 2, health.ConsecutiveFailures)
+	assert.Equal(t, "listener panic: boom", health.LastError)
+
+	// the changes are held while open, and the latest is retried after OpenDuration
+	atomic.StoreInt32(&fail, 0)
+	l.invoke(event("v2"))
+	l.invoke(event("v3"))
+	assert.Len(t, delivered, 0)
+	clk.Advance(time.Minute)
+	select {
+	case data := <-delivered:
+		assert.Equal(t, "v3", data)
+	case <-time.After(3 * time.Second):
+		t.Fatal("the held change is not retried")
+	}
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(states) == 3
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, model.ListenerBreakerClosed, l.breaker.getHealth().State)
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, []model.ListenerBreakerState{model.ListenerBreakerOpen, model.ListenerBreakerHalfOpen, model.ListenerBreakerClosed}, states)
+	assert.Equal(t, int64(2), l.breaker.getHealth().TotalFailures)
+}
+
+type budgetConfigProxy struct {
+	MockConfigProxy
+	mux      sync.Mutex
+	contents map[string]string
+	queries  int
+}
+
+func (p *budgetConfigProxy) QueryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.queries++
+	// the snapshot is written like ConfigProxy does
+	cache.WriteConfigToFile(util.GetConfigCacheKey(dataId, group, tenant), client.configCacheDir, p.contents[dataId])
+	return &rpc_response.ConfigQueryResponse{Response: &rpc_response.Response{Success: true}, Content: p.contents[dataId]}, nil
+}
+
+func Test_ConfigMemoryBudget(t *testing.T) {
+	cfg := *clientConfigWithOptions
+	cfg.ConfigMemoryBudget = 25
+	cfg.CacheDir = t.TempDir()
+	nc := nacos_client.NacosClient{}
+	_ = nc.SetServerConfig([]constant.ServerConfig{*serverConfigWithOptions})
+	_ = nc.SetClientConfig(cfg)
+	_ = nc.SetHttpAgent(&http_agent.HttpAgent{})
+	client, err := NewConfigClient(&nc)
+	assert.Nil(t, err)
+	defer client.CloseClient()
+	proxy := &budgetConfigProxy{contents: map[string]string{"a": "aaaaaaaaaa", "b": "bbbbbbbbbb", "c": "cccccccccc"}}
+	client.configProxy = proxy
+
+	received := make(chan string, 8)
+	for _, dataId := range []string{"a", "b", "c"} {
+		assert.Nil(t, client.ListenConfig(vo.ConfigParam{DataId: dataId, Group: "budget", OnChange: func(namespace, group, dataId, data string) {
+			received <- data
+		}}))
+		v, _ := client.cacheMap.Get(util.GetConfigCacheKey(dataId, "budget", ""))
+		assert.Nil(t, client.refreshContentAndCheck(v.(cacheData), true))
+	}
+	get := func(dataId string) cacheData {
+		v, _ := client.cacheMap.Get(util.GetConfigCacheKey(dataId, "budget", ""))
+		return v.(cacheData)
+	}
+	// the least recently used one is evicted with md5 kept
+	a := get("a")
+	assert.True(t, a.evicted)
+	assert.Equal(t, "", a.content)
+	assert.Equal(t, util.Md5("aaaaaaaaaa"), a.md5)
+	assert.Equal(t, "bbbbbbbbbb", get("b").content)
+	snapshot := client.Snapshot().(configClientSnapshot)
+	assert.Equal(t, &contentBudgetSnapshot{Limit: 25, Used: 20, Count: 2, Evictions: 1}, snapshot.ContentBudget)
+
+	// the evicted content is read again on access, and the least recently used one is evicted instead
+	queries := proxy.queries
+	content, err := client.contentOf(a)
+	assert.Nil(t, err)
+	assert.Equal(t, "aaaaaaaaaa", content)
+	assert.Equal(t, queries, proxy.queries, "read from snapshot")
+	assert.False(t, get("a").evicted)
+	assert.True(t, get("b").evicted)
+
+	// the changed config is delivered with the content refreshed
+	proxy.contents["b"] = "bbbbb"
+	assert.Nil(t, client.refreshContentAndCheck(get("b"), true))
+	for data := range received {
+		if data == "bbbbb" {
+			break
+		}
+	}
+	assert.Equal(t, "bbbbb", get("b").content)
+}
+
+type unavailableConfigProxy struct {
+	MockConfigProxy
+}
+
+func (p *unavailableConfigProxy) QueryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+	return nil, errors.New("server unavailable")
+}
+
+func Test_GetConfigStrictRead(t *testing.T) {
+	now := time.Now()
+	cfg := *clientConfigWithOptions
+	cfg.CacheDir = t.TempDir()
+	cfg.Clock = clock.NewFakeClock(now)
+	nc := nacos_client.NacosClient{}
+	_ = nc.SetServerConfig([]constant.ServerConfig{*serverConfigWithOptions})
+	_ = nc.SetClientConfig(cfg)
+	_ = nc.SetHttpAgent(&http_agent.HttpAgent{})
+	client, err := NewConfigClient(&nc)
+	assert.Nil(t, err)
+	defer client.CloseClient()
+	client.configProxy = &unavailableConfigProxy{}
+
+	param := vo.ConfigParam{DataId: "risk-rules", Group: "payment"}
+	cacheKey := util.GetConfigCacheKey(param.DataId, param.Group, "")
+	cache.WriteConfigToFile(cacheKey, client.configCacheDir, "rules-v1")
+	assert.Nil(t, os.Chtimes(cache.GetFileName(cacheKey, client.configCacheDir), now, now.Add(-10*time.Minute)))
+
+	// the snapshot is served silently by default
+	content, err := client.GetConfig(param)
+	assert.Nil(t, err)
+	assert.Equal(t, "rules-v1", content)
+
+	clientConfig, _ := client.GetClientConfig()
+	clientConfig.StrictRead = true
+	assert.Nil(t, client.SetClientConfig(clientConfig))
+	content, err = client.GetConfig(param)
+	assert.Equal(t, "", content)
+	stale, ok := nacos_error.IsStaleCache(err)
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Minute, stale.Age)
+	assert.Equal(t, "payment", stale.Group)
+	assert.Contains(t, stale.Error(), "server unavailable")
+
+	// without snapshot it fails as before
+	_, err = client.GetConfig(vo.ConfigParam{DataId: "other", Group: "payment"})
+	assert.NotNil(t, err)
+	_, ok = nacos_error.IsStaleCache(err)
+	assert.False(t, ok)
+}
+
+func writeSignKeys(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	privateBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.Nil(t, err)
+	publicBytes, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.Nil(t, err)
+	privateFile := filepath.Join(dir, "private.pem")
+	publicFile := filepath.Join(dir, "public.pem")
+	assert.Nil(t, ioutil.WriteFile(privateFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateBytes}), 0600))
+	assert.Nil(t, ioutil.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes}), 0600))
+	return privateFile, publicFile
+}
+
+func createSignConfigClientTest(t *testing.T, onVerifyFail func(namespace, group, dataId string, err error)) (*ConfigClient, *clock.FakeClock) {
+	privateFile, publicFile := writeSignKeys(t, t.TempDir())
+	fakeClock := clock.NewFakeClock(time.Now())
+	cfg := *clientConfigWithOptions
+	cfg.CacheDir = t.TempDir()
+	cfg.Clock = fakeClock
+	cfg.ConfigSignCfg = &constant.ConfigSignConfig{PrivateKeyFile: privateFile, PublicKeyFiles: []string{publicFile},
+		RejectUnsigned: true, OnVerifyFail: onVerifyFail}
+	nc := nacos_client.NacosClient{}
+	_ = nc.SetServerConfig([]constant.ServerConfig{*serverConfigWithOptions})
+	_ = nc.SetClientConfig(cfg)
+	_ = nc.SetHttpAgent(&http_agent.HttpAgent{})
+	client, err := NewConfigClient(&nc)
+	assert.Nil(t, err)
+	return client, fakeClock
+}
+
+func Test_GetConfigVerifySignature(t *testing.T) {
+	verifyFailed := make(chan string, 4)
+	client, _ := createSignConfigClientTest(t, func(namespace, group, dataId string, err error) {
+		verifyFailed <- dataId
+	})
+	defer client.CloseClient()
+	proxy := &storeConfigProxy{contents: map[string]string{}}
+	client.configProxy = proxy
+	param := vo.ConfigParam{DataId: "rules", Group: "group", Content: "v1"}
+
+	published, err := client.PublishConfig(param)
+	assert.Nil(t, err)
+	assert.True(t, published)
+	// the content is published before its signature
+	assert.Equal(t, []string{"rules", "rules" + constant.SIGNATURE_DATAID_SUFFIX}, proxy.publishedIds)
+	content, err := client.GetConfig(vo.ConfigParam{DataId: "rules", Group: "group"})
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", content)
+
+	proxy.contents["rules"] = "tampered"
+	_, err = client.GetConfig(vo.ConfigParam{DataId: "rules", Group: "group"})
+	assert.ErrorIs(t, err, security.ErrSignatureInvalid)
+	assert.Equal(t, "rules", <-verifyFailed)
+
+	// the signature is bound to the namespace
+	proxy.contents["rules"] = "v1"
+	_, err = client.GetConfig(vo.ConfigParam{DataId: "rules", Group: "group", NamespaceId: "other"})
+	assert.ErrorIs(t, err, security.ErrSignatureInvalid)
+}
+
+func Test_ListenConfigVerifySignature(t *testing.T) {
+	verifyFailed := make(chan string, 4)
+	client, fakeClock := createSignConfigClientTest(t, func(namespace, group, dataId string, err error) {
+		verifyFailed <- dataId
+	})
+	defer client.CloseClient()
+	proxy := &storeConfigProxy{contents: map[string]string{}}
+	client.configProxy = proxy
+	_, err := client.PublishConfig(vo.ConfigParam{DataId: "rules", Group: "group", Content: "v1"})
+	assert.Nil(t, err)
+
+	changes := make(chan string, 4)
+	assert.Nil(t, client.ListenConfig(vo.ConfigParam{DataId: "rules", Group: "group",
+		OnChange: func(namespace, group, dataId, data string) {
+			changes <- data
+		}}))
+	key := util.GetConfigCacheKey("rules", "group", "")
+	v, _ := client.cacheMap.Get(key)
+	// the signature is fetched in background, the listener is notified once it arrives
+	assert.Nil(t, client.refreshContentAndCheck(v.(cacheData), true))
+	notify := func() bool {
+		v, _ := client.cacheMap.Get(key)
+		if data := v.(cacheData); data.needNotify() {
+			data.executeListener()
+		}
+		return len(changes) > 0
+	}
+	assert.Eventually(t, notify, time.Second, time.Millisecond)
+	assert.Equal(t, "v1", <-changes)
+
+	// the new content waits for its signature published afterwards
+	proxy.mux.Lock()
+	proxy.contents["rules"] = "v2"
+	proxy.mux.Unlock()
+	v, _ = client.cacheMap.Get(key)
+	assert.Nil(t, client.refreshContentAndCheck(v.(cacheData), true))
+	assert.Never(t, notify, 50*time.Millisecond, time.Millisecond)
+	signature, err := client.contentSigner.Sign("", "rules", "group", "v2")
+	assert.Nil(t, err)
+	proxy.mux.Lock()
+	proxy.contents["rules"+constant.SIGNATURE_DATAID_SUFFIX] = signature
+	proxy.mux.Unlock()
+	fakeClock.Advance(signatureRetryInterval)
+	assert.Eventually(t, notify, time.Second, time.Millisecond)
+	assert.Equal(t, "v2", <-changes)
+	assert.Len(t, verifyFailed, 0)
+
+	// the content still not matching the signature fetched afterwards is reported
+	proxy.mux.Lock()
+	proxy.contents["rules"] = "tampered"
+	proxy.mux.Unlock()
+	v, _ = client.cacheMap.Get(key)
+	assert.Nil(t, client.refreshContentAndCheck(v.(cacheData), true))
+	assert.Eventually(t, func() bool {
+		fakeClock.Advance(signatureRetryInterval)
+		return notify() || len(verifyFailed) > 0
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, "rules", <-verifyFailed)
+	assert.Len(t, changes, 0)
+}
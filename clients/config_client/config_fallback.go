@@ -0,0 +1,114 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"sync"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// groupChain return the groups of param in the order of precedence, the duplicated groups are dropped
+func (client *ConfigClient) groupChain(param vo.ConfigParam) []string {
+	chain := []string{client.GroupOf(param.DataId, param.Group)}
+	seen := map[string]bool{chain[0]: true}
+	for _, group := range param.FallbackGroups {
+		if len(group) > 0 && !seen[group] {
+			seen[group] = true
+			chain = append(chain, group)
+		}
+	}
+	return chain
+}
+
+// getConfigFallback return the content of the config in the first group of the chain where it exists, the error of
+// any group stops the fallback, since the config may exist in the group of higher precedence
+func (client *ConfigClient) getConfigFallback(param vo.ConfigParam) (content, group string, err error) {
+	for _, group = range client.groupChain(param) {
+		p := param
+		p.Group, p.FallbackGroups = group, nil
+		if content, err = client.GetConfig(p); err != nil || len(content) > 0 {
+			return content, group, err
+		}
+	}
+	return "", "", nil
+}
+
+// listenConfigFallback listen the config in every group of the chain, OnChange is called with the group and content
+// in effect whenever they change, such as the config of higher precedence is created or the one in effect is deleted
+func (client *ConfigClient) listenConfigFallback(param vo.ConfigParam) error {
+	if err := client.checkListenParam("ListenConfig", &param); err != nil {
+		return err
+	}
+	onChange := param.OnChangeErr
+	if listener := param.OnChange; onChange == nil {
+		onChange = func(namespace, group, dataId, data string) error {
+			listener(namespace, group, dataId, data)
+			return nil
+		}
+	}
+	chain := client.groupChain(param)
+	var (
+		mutex       sync.Mutex
+		contents    = make([]string, len(chain))
+		activeGroup string
+		active      string
+	)
+	// the content in effect is the base of changes, so the changes of the groups overridden are not delivered
+	for i, group := range chain {
+		p := param
+		p.Group, p.FallbackGroups = group, nil
+		contents[i], _ = client.GetConfig(p)
+	}
+	effective := func() (string, string) {
+		for i, content := range contents {
+			if len(content) > 0 {
+				return chain[i], content
+			}
+		}
+		return "", ""
+	}
+	activeGroup, active = effective()
+
+	for i, group := range chain {
+		i := i
+		p := param
+		p.Group, p.FallbackGroups = group, nil
+		p.OnChange, p.OnDelete, p.OnBinaryChange = nil, nil, nil
+		p.OnChangeErr = func(namespace, _, dataId, data string) error {
+			mutex.Lock()
+			contents[i] = data
+			lastGroup, lastContent := activeGroup, active
+			effectiveGroup, content := effective()
+			changed := effectiveGroup != activeGroup || content != active
+			activeGroup, active = effectiveGroup, content
+			mutex.Unlock()
+			if !changed {
+				return nil
+			}
+			if len(content) == 0 && param.OnDelete != nil {
+				param.OnDelete(namespace, lastGroup, dataId, lastContent)
+				return nil
+			}
+			return onChange(namespace, effectiveGroup, dataId, content)
+		}
+		if err := client.ListenConfig(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"github.com/pkg/errors"
+)
+
+type publishRecord struct {
+	md5       string
+	published bool
+	time      time.Time
+}
+
+// publishIdempotency remember the result of recent publishes by idempotency key
+type publishIdempotency struct {
+	mux     sync.Mutex
+	records map[string]publishRecord
+	// clock expires the records, nil means the system clock
+	clock clock.Clock
+}
+
+func (p *publishIdempotency) get(key string) (publishRecord, bool) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	record, ok := p.records[key]
+	if ok && clock.OrReal(p.clock).Since(record.time) > constant.PUBLISH_IDEMPOTENCY_TTL {
+		delete(p.records, key)
+		return record, false
+	}
+	return record, ok
+}
+
+func (p *publishIdempotency) put(key string, record publishRecord) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.records == nil {
+		p.records = make(map[string]publishRecord)
+	}
+	clk := clock.OrReal(p.clock)
+	for k, v := range p.records {
+		if clk.Since(v.time) > constant.PUBLISH_IDEMPOTENCY_TTL {
+			delete(p.records, k)
+		}
+	}
+	record.time = clk.Now()
+	p.records[key] = record
+}
+
+func publishIdempotencyKey(param vo.ConfigParam, tenant string) string {
+	return param.IdempotencyKey + constant.CONFIG_INFO_SPLITER + util.GetConfigCacheKey(param.DataId, param.Group, tenant)
+}
+
+// checkPublishIdempotency return true when the publish with the same idempotency key has already succeeded.
+// When the previous result is ambiguous, the content on server is compared, and the md5 on server is used
+// as casMd5 of the retry so that it will not override a change made by others. It must be called in the write turn
+// of the config and the result recorded before the turn is released, so that the check and the publish are atomic
+// for the concurrent retries with the same key
+func (client *ConfigClient) checkPublishIdempotency(param *vo.ConfigParam) (bool, error) {
+	tenant := client.tenantOf(*param)
+	key := publishIdempotencyKey(*param, tenant)
+	record, ok := client.publishIdempotency.get(key)
+	if !ok {
+		return false, nil
+	}
+	if record.md5 != util.Md5(param.Content) {
+		return false, errors.Errorf("[client.PublishConfig] idempotency key %s is already used with different content", param.IdempotencyKey)
+	}
+	if record.published {
+		logger.Infof("publish dataId:%s group:%s with idempotency key %s is already done", param.DataId, param.Group, param.IdempotencyKey)
+		return true, nil
+	}
+	response, err := client.queryConfig(*param, tenant)
+	if err != nil {
+		return false, errors.Wrapf(err, "[client.PublishConfig] check the result of publish with idempotency key %s failed", param.IdempotencyKey)
+	}
+	if len(response.Content) == 0 {
+		return false, nil
+	}
+	content, err := client.decrypt(param.DataId, response.Content)
+	if err != nil {
+		return false, err
+	}
+	if content == param.Content {
+		client.publishIdempotency.put(key, publishRecord{md5: record.md5, published: true})
+		logger.Infof("publish dataId:%s group:%s with idempotency key %s is already done", param.DataId, param.Group, param.IdempotencyKey)
+		return true, nil
+	}
+	if len(param.CasMd5) == 0 {
+		param.CasMd5 = util.Md5(response.Content)
+	}
+	return false, nil
+}
+
+func (client *ConfigClient) recordPublish(param vo.ConfigParam, contentMd5 string, published bool) {
+	client.publishIdempotency.put(publishIdempotencyKey(param, client.tenantOf(param)), publishRecord{md5: contentMd5, published: published})
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// ConfigIterator iterate over all the configs matching the search param page by page
+type ConfigIterator struct {
+	search  func(param vo.SearchConfigParam) (*model.ConfigPage, error)
+	clock   clock.Clock
+	closed  <-chan struct{}
+	param   vo.SearchConfigParam
+	items   []model.ConfigItem
+	current model.ConfigItem
+	done    bool
+	err     error
+}
+
+// Next load the next config item, the next page is fetched when the current one is exhausted.
+// When the server throttles the search with 429, the page is fetched again after the delay the server asks for,
+// or the exponential backoff when it doesn't tell. It returns false when there are no more items or an error occurs
+func (it *ConfigIterator) Next() bool {
+	for len(it.items) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		page, err := it.fetch()
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.items = page.PageItems
+		if len(page.PageItems) == 0 || it.param.PageNo >= page.PagesAvailable {
+			it.done = true
+		}
+		it.param.PageNo++
+	}
+	it.current, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+func (it *ConfigIterator) fetch() (*model.ConfigPage, error) {
+	maxRetries := it.param.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = constant.SEARCH_RATE_LIMIT_RETRY
+	}
+	backoff := constant.SEARCH_BACKOFF_BASE
+	for retries := 0; ; retries++ {
+		page, err := it.search(it.param)
+		rateLimited, ok := nacos_error.IsRateLimited(err)
+		if !ok || retries >= maxRetries {
+			return page, err
+		}
+		delay := rateLimited.RetryAfter
+		if delay <= 0 {
+			delay = backoff
+		}
+		if backoff *= 2; backoff > constant.SEARCH_BACKOFF_MAX {
+			backoff = constant.SEARCH_BACKOFF_MAX
+		}
+		logger.Warnf("search config page %d is rate limited, retry after %s", it.param.PageNo, delay)
+		timer := it.clock.NewTimer(delay)
+		select {
+		case <-timer.C():
+		case <-it.closed:
+			timer.Stop()
+			return nil, errors.Wrap(err, "[client.SearchConfigIterator] client is closed while backing off")
+		}
+	}
+}
+
+// Item return the current config item
+func (it *ConfigIterator) Item() model.ConfigItem {
+	return it.current
+}
+
+// Err return the error occurs while fetching pages
+func (it *ConfigIterator) Err() error {
+	return it.err
+}
+
+// PageNo return the page to resume the iteration from, such as after Err or a restart of the process, by passing
+// it as PageNo of the param of SearchConfigIterator. The items of the current page up to Item are iterated again
+func (it *ConfigIterator) PageNo() int {
+	if len(it.items) > 0 {
+		return it.param.PageNo - 1
+	}
+	return it.param.PageNo
+}
+
+// SearchConfigIterator return an iterator over all pages of search result
+func (client *ConfigClient) SearchConfigIterator(param vo.SearchConfigParam) *ConfigIterator {
+	it := NewConfigIterator(client, param)
+	it.search, it.clock, it.closed = client.searchConfigInner, client.clock, client.ctx.Done()
+	return it
+}
+
+// NewConfigIterator return an iterator over all pages of search result of any IConfigClient, such as the decorated one
+func NewConfigIterator(client IConfigClient, param vo.SearchConfigParam) *ConfigIterator {
+	if param.PageNo <= 0 {
+		param.PageNo = 1
+	}
+	return &ConfigIterator{search: client.SearchConfig, clock: clock.Real, param: param}
+}
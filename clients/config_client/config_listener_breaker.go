@@ -0,0 +1,162 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
+const (
+	defaultListenerFailureThreshold = 5
+	defaultListenerOpenDuration     = 30 * time.Second
+)
+
+var listenerIdSeq int64
+
+// listenerBreaker track the health of a listener, and hold the changes while the listener keeps failing when
+// ListenerBreakerCfg is set. The held changes are coalesced into the latest one, which is retried after OpenDuration
+type listenerBreaker struct {
+	enabled       bool
+	threshold     int
+	openDuration  time.Duration
+	onStateChange func(model.ListenerHealth)
+	clock         clock.Clock
+
+	mutex  sync.Mutex
+	health model.ListenerHealth
+	held   *listenerEvent
+}
+
+func newListenerBreaker(cfg *constant.ListenerBreakerConfig, clk clock.Clock, namespace, group, dataId string) *listenerBreaker {
+	b := &listenerBreaker{
+		clock: clock.OrReal(clk),
+		health: model.ListenerHealth{
+			ListenerId:  atomic.AddInt64(&listenerIdSeq, 1),
+			NamespaceId: namespace,
+			Group:       group,
+			DataId:      dataId,
+			State:       model.ListenerBreakerClosed,
+		},
+	}
+	if cfg != nil {
+		b.enabled = true
+		b.threshold = cfg.FailureThreshold
+		if b.threshold <= 0 {
+			b.threshold = defaultListenerFailureThreshold
+		}
+		b.openDuration = cfg.OpenDuration
+		if b.openDuration <= 0 {
+			b.openDuration = defaultListenerOpenDuration
+		}
+		b.onStateChange = cfg.OnStateChange
+	}
+	return b
+}
+
+func (b *listenerBreaker) getHealth() model.ListenerHealth {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.health
+}
+
+// allow returns false and holds the event when the breaker is open
+func (b *listenerBreaker) allow(event *listenerEvent) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.health.State == model.ListenerBreakerOpen {
+		b.held = event
+		return false
+	}
+	return true
+}
+
+// record the result of calling the listener, retry is called with the held event after the breaker opened
+// for OpenDuration
+func (b *listenerBreaker) record(err error, retry func(*listenerEvent)) {
+	b.mutex.Lock()
+	if err == nil {
+		b.health.ConsecutiveFailures = 0
+		changed := b.transit(model.ListenerBreakerClosed)
+		b.mutex.Unlock()
+		b.emit(changed)
+		return
+	}
+	b.health.ConsecutiveFailures++
+	b.health.TotalFailures++
+	b.health.LastError = err.Error()
+	b.health.LastFailTime = b.clock.Now()
+	opened := false
+	if b.enabled && b.health.State != model.ListenerBreakerOpen &&
+		(b.health.State == model.ListenerBreakerHalfOpen || b.health.ConsecutiveFailures >= b.threshold) {
+		opened = b.transit(model.ListenerBreakerOpen)
+	}
+	health := b.health
+	b.mutex.Unlock()
+	if !opened {
+		return
+	}
+	logger.Warnf("open the breaker of listener %d of config dataId:%s group:%s tenant:%s after %d failures, last err:%v",
+		health.ListenerId, health.DataId, health.Group, health.NamespaceId, health.ConsecutiveFailures, err)
+	b.emit(true)
+	b.clock.AfterFunc(b.openDuration, func() {
+		b.mutex.Lock()
+		changed := b.transit(model.ListenerBreakerHalfOpen)
+		held := b.held
+		b.held = nil
+		b.mutex.Unlock()
+		b.emit(changed)
+		if held != nil {
+			retry(held)
+		}
+	})
+}
+
+// transit must be called with mutex held, it returns whether the state changed
+func (b *listenerBreaker) transit(state model.ListenerBreakerState) bool {
+	if b.health.State == state {
+		return false
+	}
+	b.health.State = state
+	return true
+}
+
+func (b *listenerBreaker) emit(changed bool) {
+	if changed && b.onStateChange != nil {
+		b.onStateChange(b.getHealth())
+	}
+}
+
+// callListener call the listener and convert its panic into error, so that it does not crash the process
+func callListener(call func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("listener panic: %v", r)
+			logger.Errorf("config listener panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return call()
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"github.com/pkg/errors"
+)
+
+const manifestPageSize = 100
+
+// GetNamespaceChecksum compute the aggregate checksum of the configs of the group on server,
+// fleet tooling compares it against the manifest of each node to verify they are running consistent configs
+func (client *ConfigClient) GetNamespaceChecksum(namespace, group string) (*model.ConfigManifest, error) {
+	group = client.GroupOf("", group)
+	md5s := make(map[string]string)
+	for pageNo := 1; ; pageNo++ {
+		page, err := client.ListConfigKeys(vo.ListConfigKeysParam{
+			NamespaceId: namespace,
+			Group:       group,
+			PageNo:      pageNo,
+			PageSize:    manifestPageSize,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "[client.GetNamespaceChecksum] list configs of group %s failed", group)
+		}
+		for _, key := range page.PageItems {
+			// the search on server is blur, so the group is matched exactly here
+			if key.Group == group {
+				md5s[key.DataId] = key.Md5
+			}
+		}
+		if pageNo >= page.PagesAvailable || len(page.PageItems) == 0 {
+			break
+		}
+	}
+	return NewConfigManifest(client.tenantOf(vo.ConfigParam{NamespaceId: namespace}), group, md5s), nil
+}
+
+// LocalConfigManifest build the manifest of the listened configs of the group, it's what this node is running
+func (client *ConfigClient) LocalConfigManifest(namespace, group string) *model.ConfigManifest {
+	group = client.GroupOf("", group)
+	tenant := client.tenantOf(vo.ConfigParam{NamespaceId: namespace})
+	md5s := make(map[string]string)
+	for _, v := range client.cacheMap.Items() {
+		data := v.(cacheData)
+		if data.tenant == tenant && data.group == group && !data.deleted {
+			md5s[data.dataId] = data.md5
+		}
+	}
+	return NewConfigManifest(tenant, group, md5s)
+}
+
+// NewConfigManifest build the manifest of the md5s keyed by dataId
+func NewConfigManifest(namespace, group string, md5s map[string]string) *model.ConfigManifest {
+	return &model.ConfigManifest{
+		Namespace: namespace,
+		Group:     group,
+		Checksum:  manifestChecksum(md5s),
+		Md5s:      md5s,
+	}
+}
+
+// manifestChecksum return the md5 of the dataId:md5 pairs sorted by dataId, one pair per line
+func manifestChecksum(md5s map[string]string) string {
+	dataIds := make([]string, 0, len(md5s))
+	for dataId := range md5s {
+		dataIds = append(dataIds, dataId)
+	}
+	sort.Strings(dataIds)
+	var sb strings.Builder
+	for _, dataId := range dataIds {
+		sb.WriteString(dataId)
+		sb.WriteByte(':')
+		sb.WriteString(md5s[dataId])
+		sb.WriteByte('\n')
+	}
+	return util.Md5(sb.String())
+}
+
+// CompareConfigManifest compare the local manifest against the one on server, the dataIds in the result are sorted.
+// The checksum is compared first, so the consistent manifests of thousands of configs are compared cheaply
+func CompareConfigManifest(local, remote *model.ConfigManifest) model.ConfigManifestDiff {
+	var diff model.ConfigManifestDiff
+	if local.Checksum == remote.Checksum {
+		return diff
+	}
+	for dataId, md5Str := range remote.Md5s {
+		localMd5, ok := local.Md5s[dataId]
+		if !ok {
+			diff.Missing = append(diff.Missing, dataId)
+		} else if localMd5 != md5Str {
+			diff.Mismatch = append(diff.Mismatch, dataId)
+		}
+	}
+	for dataId := range local.Md5s {
+		if _, ok := remote.Md5s[dataId]; !ok {
+			diff.Extra = append(diff.Extra, dataId)
+		}
+	}
+	sort.Strings(diff.Missing)
+	sort.Strings(diff.Mismatch)
+	sort.Strings(diff.Extra)
+	return diff
+}
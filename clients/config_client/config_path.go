@@ -0,0 +1,75 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// ListenConfigPath listen the subtree of json or yaml config selected by the JSONPath expression, OnChange is called
+// with the json of the selected value only when it differs from the last one, which is computed on client from the
+// full content. The definite path gives the value or null when absent, and the path with wildcard or recursive descent
+// gives the array of matched values
+func (client *ConfigClient) ListenConfigPath(param vo.ConfigParam, path string) (err error) {
+	jsonPath, err := util.CompileJsonPath(path)
+	if err != nil {
+		return errors.Wrap(err, "[client.ListenConfigPath]")
+	}
+	if err = client.checkListenParam("ListenConfigPath", &param); err != nil {
+		return err
+	}
+	onChange := param.OnChangeErr
+	if listener := param.OnChange; onChange == nil {
+		onChange = func(namespace, group, dataId, data string) error {
+			listener(namespace, group, dataId, data)
+			return nil
+		}
+	}
+	var (
+		mutex sync.Mutex
+		last  string
+		known bool
+	)
+	// the current value is the base of changes, so that the first notification of unrelated changes is not delivered
+	if content, getErr := client.GetConfig(param); getErr == nil {
+		last, getErr = jsonPath.SelectContent(content)
+		known = getErr == nil
+	}
+	param.OnChange = nil
+	param.OnChangeErr = func(namespace, group, dataId, content string) error {
+		selected, err := jsonPath.SelectContent(content)
+		if err != nil {
+			logger.Errorf("select %s of config fail, dataId=%s, group=%s, tenant=%s, err:%v", path, dataId, group, namespace, err)
+			return nil
+		}
+		mutex.Lock()
+		changed := !known || selected != last
+		last, known = selected, true
+		mutex.Unlock()
+		if !changed {
+			return nil
+		}
+		return onChange(namespace, group, dataId, selected)
+	}
+	return client.ListenConfig(param)
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"github.com/pkg/errors"
+)
+
+// the staged content of a config is published to a companion config whose dataId is suffixed with the staging suffix,
+// it's moved to the live config by Promote
+func (client *ConfigClient) stagingDataId(dataId string) string {
+	clientConfig, _ := client.GetClientConfig()
+	if clientConfig.PublishWorkflowCfg != nil && len(clientConfig.PublishWorkflowCfg.StagingSuffix) > 0 {
+		return dataId + clientConfig.PublishWorkflowCfg.StagingSuffix
+	}
+	return dataId + constant.STAGING_DATAID_SUFFIX
+}
+
+// Promote move the staged content of the config to the live config with compare-and-swap, the staging config is removed
+// after it's promoted. The Approve hook of PublishWorkflowCfg gates the promotion, and the live config changed since
+// the staged content was reviewed is not overwritten when CasMd5 of param is set
+func (client *ConfigClient) Promote(param vo.ConfigParam) (promoted bool, err error) {
+	if len(param.DataId) <= 0 {
+		return false, errors.New("[client.Promote] param.dataId can not be empty")
+	}
+	param.Group = client.GroupOf(param.DataId, param.Group)
+	clientConfig, _ := client.GetClientConfig()
+	tenant := client.tenantOf(param)
+	workflowCfg := clientConfig.PublishWorkflowCfg
+	if workflowCfg != nil && workflowCfg.OnPromoted != nil {
+		defer func() {
+			workflowCfg.OnPromoted(tenant, param.Group, param.DataId, err)
+		}()
+	}
+
+	stagingDataId := client.stagingDataId(param.DataId)
+	staged, err := client.configProxy.QueryConfig(stagingDataId, param.Group, tenant,
+		clientConfig.TimeoutMs, false, client)
+	if err != nil {
+		return false, errors.Wrapf(err, "[client.Promote] get staged config dataId=%s failed", stagingDataId)
+	}
+	if staged.GetErrorCode() == constant.CONFIG_NOT_FOUND {
+		return false, errors.Errorf("[client.Promote] no staged content of dataId=%s, group=%s", param.DataId, param.Group)
+	}
+	content, err := client.decrypt(stagingDataId, staged.Content)
+	if err != nil {
+		return false, err
+	}
+	if err = client.verifyContent(tenant, param.Group, stagingDataId, content); err != nil {
+		return false, err
+	}
+
+	if len(param.CasMd5) <= 0 {
+		// the live config is read right before approval, so the one changed during approval is not overwritten
+		live, err := client.configProxy.QueryConfig(param.DataId, param.Group, tenant,
+			clientConfig.TimeoutMs, false, client)
+		if err != nil {
+			return false, errors.Wrapf(err, "[client.Promote] get live config dataId=%s failed", param.DataId)
+		}
+		if live.GetErrorCode() != constant.CONFIG_NOT_FOUND {
+			param.CasMd5 = live.Md5
+			if len(param.CasMd5) <= 0 {
+				param.CasMd5 = util.Md5(live.Content)
+			}
+		}
+	}
+	if workflowCfg != nil && workflowCfg.Approve != nil {
+		if err = workflowCfg.Approve(tenant, param.Group, param.DataId, content); err != nil {
+			return false, errors.Wrapf(err, "[client.Promote] promotion of dataId=%s, group=%s is not approved", param.DataId, param.Group)
+		}
+	}
+
+	param.Content = content
+	param.Stage = false
+	if promoted, err = client.PublishConfig(param); err != nil || !promoted {
+		return promoted, err
+	}
+	if _, deleteErr := client.DeleteConfig(vo.ConfigParam{DataId: stagingDataId, Group: param.Group, NamespaceId: param.NamespaceId}); deleteErr != nil {
+		logger.Warnf("delete staged config fail, dataId=%s, group=%s, err:%v", stagingDataId, param.Group, deleteErr)
+	}
+	return true, nil
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// GetProperties get the properties or yaml configs and merge them to the flat properties, the type of config is
+// Type of param or detected from the extension of dataId, the properties format is used by default
+func (client *ConfigClient) GetProperties(param vo.PropertiesParam) (map[string]string, error) {
+	if len(param.Configs) == 0 {
+		return nil, errors.New("[client.GetProperties] Configs can not be empty")
+	}
+	sets := make([]map[string]string, 0, len(param.Configs))
+	for _, config := range param.Configs {
+		content, err := client.GetConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		props, err := decodeConfigProperties(config, content)
+		if err != nil {
+			return nil, errors.Wrap(err, "[client.GetProperties]")
+		}
+		sets = append(sets, props)
+	}
+	return resolveProperties(util.MergeProperties(sets...), param.ResolvePlaceholders, param.LookupEnv)
+}
+
+// decodeConfigProperties decode the content of config to the flat properties, the type of config is Type of param
+// or detected from the extension of dataId, the properties format is used by default
+func decodeConfigProperties(config vo.ConfigParam, content string) (map[string]string, error) {
+	configType := config.Type
+	if len(configType) == 0 {
+		configType = strings.TrimPrefix(filepath.Ext(config.DataId), ".")
+		if configType != "yaml" && configType != "yml" {
+			configType = "properties"
+		}
+	}
+	props, err := util.DecodeProperties(configType, content)
+	return props, errors.Wrapf(err, "decode config %s failed", config.DataId)
+}
+
+func resolveProperties(merged map[string]string, resolvePlaceholders, env bool) (map[string]string, error) {
+	if !resolvePlaceholders {
+		return merged, nil
+	}
+	var lookupEnv func(key string) (string, bool)
+	if env {
+		lookupEnv = os.LookupEnv
+	}
+	return util.ResolvePlaceholders(merged, lookupEnv)
+}
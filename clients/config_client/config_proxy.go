@@ -35,6 +35,7 @@ import (
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/http_agent"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_server"
 	"github.com/nacos-group/nacos-sdk-go/v2/model"
 	"github.com/nacos-group/nacos-sdk-go/v2/util"
@@ -54,18 +55,50 @@ func NewConfigProxy(ctx context.Context, serverConfig []constant.ServerConfig, c
 	return &proxy, err
 }
 
-func (cp *ConfigProxy) requestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
+// NewConfigProxyWithServer create the proxy sending requests through nacosServer, which may be shared with the naming client
+func NewConfigProxyWithServer(nacosServer *nacos_server.NacosServer, clientConfig constant.ClientConfig) IConfigProxy {
+	return &ConfigProxy{nacosServer: nacosServer, clientConfig: clientConfig}
+}
+
+func (cp *ConfigProxy) RequestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error) {
 	start := time.Now()
 	cp.nacosServer.InjectSecurityInfo(request.GetHeaders())
+	cp.nacosServer.InjectIdentity(request.GetHeaders())
 	cp.injectCommHeader(request.GetHeaders())
 	cp.nacosServer.InjectSkAk(request.GetHeaders(), cp.clientConfig)
 	signHeaders := nacos_server.GetSignHeadersFromRequest(request.(rpc_request.IConfigRequest), cp.clientConfig.SecretKey)
 	request.PutAllHeaders(signHeaders)
 	response, err := rpcClient.Request(request, int64(timeoutMills))
 	monitor.GetConfigRequestMonitor(constant.GRPC, request.GetRequestType(), rpc_response.GetGrpcResponseStatusCode(response)).Observe(float64(time.Now().Nanosecond() - start.Nanosecond()))
+	if err == nil && response != nil && response.GetErrorCode() == constant.NO_RIGHT {
+		err = cp.permissionDenied(request.(rpc_request.IConfigRequest), response.GetMessage())
+	}
 	return response, err
 }
 
+func (cp *ConfigProxy) permissionDenied(request rpc_request.IConfigRequest, message string) *nacos_error.PermissionDeniedError {
+	action := nacos_error.ActionRead
+	switch request.(type) {
+	case *rpc_request.ConfigPublishRequest, *rpc_request.ConfigRemoveRequest:
+		action = nacos_error.ActionWrite
+	}
+	return nacos_error.NewPermissionDeniedError(message, nacos_error.PermissionDeniedError{
+		Namespace: request.GetTenant(),
+		Group:     request.GetGroup(),
+		DataId:    request.GetDataId(),
+		Action:    action,
+		Identity:  identity(cp.clientConfig),
+	})
+}
+
+// identity returns the identity the client authenticates with.
+func identity(clientConfig constant.ClientConfig) string {
+	if clientConfig.Username != "" {
+		return clientConfig.Username
+	}
+	return clientConfig.AccessKey
+}
+
 func (cp *ConfigProxy) injectCommHeader(param map[string]string) {
 	now := strconv.FormatInt(util.CurrentMillis(), 10)
 	param[constant.CLIENT_APPNAME_HEADER] = cp.clientConfig.AppName
@@ -75,7 +108,7 @@ func (cp *ConfigProxy) injectCommHeader(param map[string]string) {
 	param[constant.CHARSET_KEY] = "utf-8"
 }
 
-func (cp *ConfigProxy) searchConfigProxy(param vo.SearchConfigParam, tenant, accessKey, secretKey string) (*model.ConfigPage, error) {
+func (cp *ConfigProxy) SearchConfigProxy(param vo.SearchConfigParam, tenant, accessKey, secretKey string) (*model.ConfigPage, error) {
 	params := util.TransformObject2Param(param)
 	if len(tenant) > 0 {
 		params["tenant"] = tenant
@@ -101,7 +134,14 @@ func (cp *ConfigProxy) searchConfigProxy(param vo.SearchConfigParam, tenant, acc
 	return &configPage, nil
 }
 
-func (cp *ConfigProxy) queryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+func (cp *ConfigProxy) QueryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
+	return cp.QueryConfigWithPriority(dataId, group, tenant, timeout, notify, vo.ConfigPriorityNormal, client)
+}
+
+// QueryConfigWithPriority query the config from server, the queries of high priority wait for the token of rate
+// limiter up to timeout ahead of the normal ones, which are refused when limited
+func (cp *ConfigProxy) QueryConfigWithPriority(dataId, group, tenant string, timeout uint64, notify bool,
+	priority vo.ConfigPriority, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error) {
 	if group == "" {
 		group = constant.DEFAULT_GROUP
 	}
@@ -109,11 +149,14 @@ func (cp *ConfigProxy) queryConfig(dataId, group, tenant string, timeout uint64,
 	configQueryRequest.Headers["notify"] = strconv.FormatBool(notify)
 	cacheKey := util.GetConfigCacheKey(dataId, group, tenant)
 	// use the same key of config file as the limit checker's key
-	if IsLimited(cacheKey) {
+	if priority < vo.ConfigPriorityHigh && IsLimited(cacheKey) {
 		// return error when check limited
 		return nil, errors.New("ConfigQueryRequest is limited")
 	}
-	iResponse, err := cp.requestProxy(cp.getRpcClient(client), configQueryRequest, timeout)
+	if priority >= vo.ConfigPriorityHigh && waitLimited(cacheKey, time.Duration(timeout)*time.Millisecond) {
+		return nil, errors.New("ConfigQueryRequest is limited")
+	}
+	iResponse, err := cp.RequestProxy(cp.GetRpcClient(client), configQueryRequest, timeout)
 	if err != nil {
 		return nil, err
 	}
@@ -123,6 +166,7 @@ func (cp *ConfigProxy) queryConfig(dataId, group, tenant string, timeout uint64,
 	}
 	if response.IsSuccess() {
 		cache.WriteConfigToFile(cacheKey, cp.clientConfig.CacheDir, response.Content)
+		cache.WriteConfigHistory(cacheKey, cp.clientConfig.CacheDir, response.Content, cp.clientConfig.ConfigHistorySize)
 		//todo LocalConfigInfoProcessor.saveEncryptDataKeySnapshot
 		if response.ContentType == "" {
 			response.ContentType = "text"
@@ -130,8 +174,10 @@ func (cp *ConfigProxy) queryConfig(dataId, group, tenant string, timeout uint64,
 		return response, nil
 	}
 
-	if response.GetErrorCode() == 300 {
-		cache.WriteConfigToFile(cacheKey, cp.clientConfig.CacheDir, "")
+	if response.GetErrorCode() == constant.CONFIG_NOT_FOUND {
+		if !cp.clientConfig.KeepSnapshotOnDelete {
+			cache.WriteConfigToFile(cacheKey, cp.clientConfig.CacheDir, "")
+		}
 		//todo LocalConfigInfoProcessor.saveEncryptDataKeySnapshot
 		return response, nil
 	}
@@ -144,7 +190,7 @@ func (cp *ConfigProxy) queryConfig(dataId, group, tenant string, timeout uint64,
 	}
 
 	if response.GetErrorCode() > 0 {
-		logger.Errorf("[config_rpc_client] [sub-server-error]  dataId=%s, group=%s, tenant=%s, code=%+v", dataId, group,
+		logger.With(logger.ConfigContext(tenant, group, dataId)).Errorf("[config_rpc_client] [sub-server-error]  dataId=%s, group=%s, tenant=%s, code=%+v", dataId, group,
 			tenant, response)
 	}
 	return response, nil
@@ -158,7 +204,7 @@ func appName(client *ConfigClient) string {
 	return "unknown"
 }
 
-func (cp *ConfigProxy) createRpcClient(ctx context.Context, taskId string, client *ConfigClient) *rpc.RpcClient {
+func (cp *ConfigProxy) CreateRpcClient(ctx context.Context, taskId string, client *ConfigClient) *rpc.RpcClient {
 	labels := map[string]string{
 		constant.LABEL_SOURCE:   constant.LABEL_SOURCE_SDK,
 		constant.LABEL_MODULE:   constant.LABEL_MODULE_CONFIG,
@@ -166,7 +212,7 @@ func (cp *ConfigProxy) createRpcClient(ctx context.Context, taskId string, clien
 		"taskId":                taskId,
 	}
 
-	iRpcClient, _ := rpc.CreateClient(ctx, "config-"+taskId+"-"+client.uid, rpc.GRPC, labels, cp.nacosServer)
+	iRpcClient, _ := rpc.CreateClient(ctx, rpcClientName(taskId, client), rpc.GRPC, labels, cp.nacosServer)
 	rpcClient := iRpcClient.GetRpcClient()
 	if rpcClient.IsInitialized() {
 		rpcClient.RegisterServerRequestHandler(func() rpc_request.IRequest {
@@ -179,8 +225,20 @@ func (cp *ConfigProxy) createRpcClient(ctx context.Context, taskId string, clien
 	return rpcClient
 }
 
-func (cp *ConfigProxy) getRpcClient(client *ConfigClient) *rpc.RpcClient {
-	return cp.createRpcClient(client.ctx, "0", client)
+func (cp *ConfigProxy) GetRpcClient(client *ConfigClient) *rpc.RpcClient {
+	return cp.CreateRpcClient(client.ctx, "0", client)
+}
+
+// LookupRpcClient return the rpc client of GetRpcClient without creating it, nil when it's not created yet
+func (cp *ConfigProxy) LookupRpcClient(client *ConfigClient) *rpc.RpcClient {
+	if iRpcClient, ok := rpc.LookupClient(rpcClientName("0", client)); ok {
+		return iRpcClient.GetRpcClient()
+	}
+	return nil
+}
+
+func rpcClientName(taskId string, client *ConfigClient) string {
+	return "config-" + taskId + "-" + client.uid
 }
 
 type ConfigChangeNotifyRequestHandler struct {
@@ -196,18 +254,17 @@ func (c *ConfigChangeNotifyRequestHandler) RequestReply(request rpc_request.IReq
 	if !ok {
 		return nil
 	}
-	logger.Infof("%s [server-push] config changed. dataId=%s, group=%s,tenant=%s", rpcClient.Name(),
-		configChangeNotifyRequest.DataId, configChangeNotifyRequest.Group, configChangeNotifyRequest.Tenant)
+	logger.With(logger.ConfigContext(configChangeNotifyRequest.Tenant, configChangeNotifyRequest.Group, configChangeNotifyRequest.DataId)).
+		Infof("%s [server-push] config changed. dataId=%s, group=%s,tenant=%s", rpcClient.Name(),
+			configChangeNotifyRequest.DataId, configChangeNotifyRequest.Group, configChangeNotifyRequest.Tenant)
 
 	cacheKey := util.GetConfigCacheKey(configChangeNotifyRequest.DataId, configChangeNotifyRequest.Group,
 		configChangeNotifyRequest.Tenant)
-	data, ok := c.client.cacheMap.Get(cacheKey)
-	if !ok {
+	if !c.client.updateCacheData(cacheKey, func(data *cacheData) {
+		data.isSyncWithServer = false
+	}) {
 		return nil
 	}
-	cData := data.(cacheData)
-	cData.isSyncWithServer = false
-	c.client.cacheMap.Set(cacheKey, cData)
 	c.client.asyncNotifyListenConfig()
 	return &rpc_response.NotifySubscriberResponse{
 		Response: &rpc_response.Response{ResultCode: constant.RESPONSE_CODE_SUCCESS},
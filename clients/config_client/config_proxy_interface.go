@@ -3,6 +3,9 @@ package config_client
 import (
 	"context"
 
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/http_agent"
+
 	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_request"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_response"
@@ -10,10 +13,35 @@ import (
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
 )
 
+// IConfigProxy is the transport of ConfigClient to server, the default one is ConfigProxy over grpc. The alternative
+// transports, such as a local agent or recorded fixtures, may return nil rpc clients and serve the requests by type
 type IConfigProxy interface {
-	queryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error)
-	searchConfigProxy(param vo.SearchConfigParam, tenant, accessKey, secretKey string) (*model.ConfigPage, error)
-	requestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error)
-	createRpcClient(ctx context.Context, taskId string, client *ConfigClient) *rpc.RpcClient
-	getRpcClient(client *ConfigClient) *rpc.RpcClient
+	// QueryConfig query the config from server
+	QueryConfig(dataId, group, tenant string, timeout uint64, notify bool, client *ConfigClient) (*rpc_response.ConfigQueryResponse, error)
+	// SearchConfigProxy search the configs from server
+	SearchConfigProxy(param vo.SearchConfigParam, tenant, accessKey, secretKey string) (*model.ConfigPage, error)
+	// RequestProxy send the request, such as ConfigPublishRequest and ConfigBatchListenRequest, through rpcClient
+	RequestProxy(rpcClient *rpc.RpcClient, request rpc_request.IRequest, timeoutMills uint64) (rpc_response.IResponse, error)
+	// CreateRpcClient return the rpc client of the listen task, it's created on first use
+	CreateRpcClient(ctx context.Context, taskId string, client *ConfigClient) *rpc.RpcClient
+	// GetRpcClient return the rpc client of the requests other than listening
+	GetRpcClient(client *ConfigClient) *rpc.RpcClient
+}
+
+// IPriorityConfigProxy is implemented by the transports applying the client side rate limiter to queries, the queries
+// of high priority are queued for the tokens ahead of the others. The other transports are queried by QueryConfig
+// regardless of priority
+type IPriorityConfigProxy interface {
+	QueryConfigWithPriority(dataId, group, tenant string, timeout uint64, notify bool, priority vo.ConfigPriority,
+		client *ConfigClient) (*rpc_response.ConfigQueryResponse, error)
+}
+
+// ConfigProxyFactory create the transport of ConfigClient, NewConfigProxy is the default one
+type ConfigProxyFactory func(ctx context.Context, serverConfig []constant.ServerConfig, clientConfig constant.ClientConfig,
+	httpAgent http_agent.IHttpAgent) (IConfigProxy, error)
+
+// IRpcClientLookup is implemented by the transports keeping the rpc clients, so that the state of the client is read
+// by Snapshot without creating it
+type IRpcClientLookup interface {
+	LookupRpcClient(client *ConfigClient) *rpc.RpcClient
 }
@@ -0,0 +1,58 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// publishOperation tell whether the publish creates or updates the config by querying it before publishing,
+// it's empty when the query fails
+func (client *ConfigClient) publishOperation(param vo.ConfigParam, tenant string) model.PublishOperation {
+	response, err := client.queryConfig(param, tenant)
+	if err != nil {
+		logger.Warnf("query config dataId=%s, group=%s before publishing failed, err:%v", param.DataId, param.Group, err)
+		return ""
+	}
+	if len(response.Content) == 0 {
+		return model.PublishCreate
+	}
+	return model.PublishUpdate
+}
+
+// fillPublishResult fill the result of the published param, whose content is the one stored on server. The modified
+// time is taken from the config queried afterwards, it's left zero when the config is changed again in between
+func (client *ConfigClient) fillPublishResult(result *model.PublishResult, param vo.ConfigParam, tenant string) {
+	result.DataId = param.DataId
+	result.Group = param.Group
+	result.NamespaceId = tenant
+	result.Md5 = util.Md5(param.Content)
+	result.CasMd5 = param.CasMd5
+	response, err := client.queryConfig(param, tenant)
+	if err != nil {
+		logger.Warnf("query config dataId=%s, group=%s after publishing failed, err:%v", param.DataId, param.Group, err)
+		return
+	}
+	if util.Md5(response.Content) == result.Md5 && response.LastModified > 0 {
+		result.LastModified = time.UnixMilli(response.LastModified)
+	}
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/inner/uuid"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"github.com/pkg/errors"
+)
+
+const scheduledPublishRetryDelay = time.Second
+
+// ErrScheduledPublishCanceled is returned by ScheduledPublish.Err when it's canceled or the client is closed before publishing
+var ErrScheduledPublishCanceled = errors.New("scheduled publish is canceled")
+
+// ScheduledPublish is the publication held by the client until its effective time
+type ScheduledPublish struct {
+	id            string
+	param         vo.ConfigParam
+	effectiveTime time.Time
+	cancel        context.CancelFunc
+	done          chan struct{}
+	mux           sync.Mutex
+	events        []model.ScheduledPublishEvent
+	err           error
+}
+
+// PublishConfigAt hold the config and publish it at effectiveTime, the failed attempt is retried with backoff
+// at most constant.SCHEDULED_PUBLISH_ATTEMPTS times. The publication is lost when the client is closed before it
+func (client *ConfigClient) PublishConfigAt(param vo.ConfigParam, effectiveTime time.Time) (*ScheduledPublish, error) {
+	if len(param.DataId) <= 0 {
+		return nil, errors.New("[client.PublishConfigAt] param.dataId can not be empty")
+	}
+	if len(param.Content) <= 0 {
+		return nil, errors.New("[client.PublishConfigAt] param.content can not be empty")
+	}
+	param.Group = client.GroupOf(param.DataId, param.Group)
+	uid, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	if len(param.IdempotencyKey) <= 0 {
+		// the retry of an attempt whose result is ambiguous does not publish twice
+		param.IdempotencyKey = uid.String()
+	}
+	ctx, cancel := context.WithCancel(client.ctx)
+	s := &ScheduledPublish{
+		id:            uid.String(),
+		param:         param,
+		effectiveTime: effectiveTime,
+		cancel:        cancel,
+		done:          make(chan struct{}),
+	}
+	s.record(model.ScheduledPublishScheduled, 0, nil)
+	go s.run(ctx, client)
+	return s, nil
+}
+
+func (s *ScheduledPublish) run(ctx context.Context, client *ConfigClient) {
+	defer close(s.done)
+	defer s.cancel()
+	timer := client.clock.NewTimer(client.clock.Until(s.effectiveTime))
+	defer timer.Stop()
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			s.finish(ErrScheduledPublishCanceled)
+			s.record(model.ScheduledPublishCanceled, attempt-1, nil)
+			return
+		}
+		published, err := client.PublishConfig(s.param)
+		if err == nil && !published {
+			err = errors.New("publish config failed")
+		}
+		if err == nil {
+			s.finish(nil)
+			s.record(model.ScheduledPublishPublished, attempt, nil)
+			return
+		}
+		s.record(model.ScheduledPublishFailed, attempt, err)
+		if attempt >= constant.SCHEDULED_PUBLISH_ATTEMPTS {
+			s.finish(err)
+			s.record(model.ScheduledPublishAborted, attempt, err)
+			return
+		}
+		timer.Reset(scheduledPublishRetryDelay * time.Duration(attempt))
+	}
+}
+
+func (s *ScheduledPublish) finish(err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.err = err
+}
+
+func (s *ScheduledPublish) record(eventType model.ScheduledPublishEventType, attempt int, err error) {
+	event := model.ScheduledPublishEvent{
+		Type:          eventType,
+		Id:            s.id,
+		DataId:        s.param.DataId,
+		Group:         s.param.Group,
+		EffectiveTime: s.effectiveTime,
+		Attempt:       attempt,
+		Time:          time.Now(),
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	s.mux.Lock()
+	s.events = append(s.events, event)
+	s.mux.Unlock()
+	if err != nil {
+		logger.Warnf("scheduled publish %s %s, dataId=%s, group=%s, attempt=%d, err:%v", s.id, eventType,
+			s.param.DataId, s.param.Group, attempt, err)
+	} else {
+		logger.Infof("scheduled publish %s %s, dataId=%s, group=%s, effectiveTime=%s", s.id, eventType,
+			s.param.DataId, s.param.Group, s.effectiveTime.Format(time.RFC3339))
+	}
+	if s.param.OnScheduleEvent != nil {
+		s.param.OnScheduleEvent(event)
+	}
+}
+
+// Id return the unique id of the scheduled publication
+func (s *ScheduledPublish) Id() string {
+	return s.id
+}
+
+// Cancel abort the publication if it's not published yet
+func (s *ScheduledPublish) Cancel() {
+	s.cancel()
+}
+
+// Done return a channel which is closed after the config is published, canceled or all the attempts failed
+func (s *ScheduledPublish) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err return nil after the config is published, or the error of the last attempt, it's nil before Done
+func (s *ScheduledPublish) Err() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.err
+}
+
+// Events return the audit events of the publication in order
+func (s *ScheduledPublish) Events() []model.ScheduledPublishEvent {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	events := make([]model.ScheduledPublishEvent, len(s.events))
+	copy(events, s.events)
+	return events
+}
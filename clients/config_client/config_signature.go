@@ -0,0 +1,240 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/security"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// the signature of a config is stored in a companion config whose dataId is suffixed with constant.SIGNATURE_DATAID_SUFFIX
+func signatureDataId(dataId string) string {
+	return dataId + constant.SIGNATURE_DATAID_SUFFIX
+}
+
+func isSignatureDataId(dataId string) bool {
+	return strings.HasSuffix(dataId, constant.SIGNATURE_DATAID_SUFFIX)
+}
+
+func (client *ConfigClient) initContentSign(signCfg *constant.ConfigSignConfig) (err error) {
+	if signCfg == nil {
+		return nil
+	}
+	if len(signCfg.PrivateKeyFile) > 0 {
+		if client.contentSigner, err = security.NewContentSigner(signCfg.PrivateKeyFile); err != nil {
+			return err
+		}
+	}
+	if len(signCfg.PublicKeyFiles) > 0 {
+		if client.contentVerifier, err = security.NewContentVerifier(signCfg.PublicKeyFiles); err != nil {
+			return err
+		}
+	}
+	client.signCfg = signCfg
+	return nil
+}
+
+// signContent sign the plain content of param, it returns empty signature when no private key is configured
+func (client *ConfigClient) signContent(tenant string, param vo.ConfigParam) (string, error) {
+	if client.contentSigner == nil || isSignatureDataId(param.DataId) {
+		return "", nil
+	}
+	signature, err := client.contentSigner.Sign(tenant, param.DataId, param.Group, param.Content)
+	if err != nil {
+		return "", errors.Wrap(err, "sign content failed")
+	}
+	return signature, nil
+}
+
+// publishSignature publish the signature after the content. A content published without its signature stays
+// unverified instead of turning the current content unverified, the listeners hold the new content until the
+// signature arrives, see verifyListened
+func (client *ConfigClient) publishSignature(param vo.ConfigParam, signature string) error {
+	if len(signature) <= 0 {
+		return nil
+	}
+	published, err := client.PublishConfig(vo.ConfigParam{
+		DataId:      signatureDataId(param.DataId),
+		Group:       param.Group,
+		Content:     signature,
+		AppName:     param.AppName,
+		SrcUser:     param.SrcUser,
+		NamespaceId: param.NamespaceId,
+	})
+	if err != nil {
+		return errors.Wrap(err, "publish content signature failed")
+	}
+	if !published {
+		return errors.New("publish content signature failed")
+	}
+	return nil
+}
+
+func (client *ConfigClient) deleteSignature(param vo.ConfigParam) {
+	if client.contentSigner == nil || isSignatureDataId(param.DataId) {
+		return
+	}
+	if _, err := client.DeleteConfig(vo.ConfigParam{DataId: signatureDataId(param.DataId), Group: param.Group, NamespaceId: param.NamespaceId}); err != nil {
+		logger.Warnf("delete content signature fail, dataId=%s, group=%s, err:%v", param.DataId, param.Group, err)
+	}
+}
+
+// verifyContent check the content against its companion signature, it does nothing when no public key is configured
+func (client *ConfigClient) verifyContent(tenant, group, dataId, content string) error {
+	if client.contentVerifier == nil || isSignatureDataId(dataId) {
+		return nil
+	}
+	signature, err := client.getConfigInner(vo.ConfigParam{DataId: signatureDataId(dataId), Group: group}, tenant)
+	if err != nil {
+		signature = ""
+	}
+	if err = client.checkSignature(tenant, group, dataId, content, signature); err != nil {
+		client.verifyFailed(tenant, group, dataId, err)
+		return err
+	}
+	return nil
+}
+
+// checkSignature return nil when the signature matches the content, or the content is unsigned and it's allowed
+func (client *ConfigClient) checkSignature(tenant, group, dataId, content, signature string) error {
+	err := client.contentVerifier.Verify(tenant, dataId, group, content, strings.TrimSpace(signature))
+	if err == security.ErrContentUnsigned && !client.signCfg.RejectUnsigned {
+		return nil
+	}
+	return err
+}
+
+func (client *ConfigClient) verifyFailed(tenant, group, dataId string, err error) {
+	logger.Errorf("verify content signature fail, dataId=%s, group=%s, tenant=%s, err:%v", dataId, group, tenant, err)
+	if client.signCfg.OnVerifyFail != nil {
+		client.signCfg.OnVerifyFail(tenant, group, dataId, err)
+	}
+}
+
+const (
+	// signatureRetryInterval is the interval the signature of a listened config is fetched again while the content
+	// is not verified, the signature is published after the content so it arrives a little later
+	signatureRetryInterval = time.Second
+	// signatureRecheckInterval is the interval after the verification failure is reported
+	signatureRecheckInterval = 30 * time.Second
+)
+
+// listenedSignatures hold the signatures of the listened configs, they are fetched in background so the listen
+// loop never waits for them
+type listenedSignatures struct {
+	mux     sync.Mutex
+	entries map[string]*listenedSignature
+}
+
+type listenedSignature struct {
+	signature string
+	fetched   bool
+	fetching  bool
+	fetchedAt time.Time
+	// pendingMd5 is the md5 of the content waiting for its signature since pendingAt
+	pendingMd5 string
+	pendingAt  time.Time
+	// reportedMd5 is the md5 of the content whose verification failure is reported, it's reported once
+	reportedMd5 string
+}
+
+func (s *listenedSignatures) remove(key string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	delete(s.entries, key)
+}
+
+// verifyListened check the content of the listened config against the signature fetched in background. It returns
+// false when the content is not verified yet, the listeners are notified again in the following listen cycles, and
+// the signature is fetched again in background. The failure is reported once the signature fetched a while after the
+// content arrived still doesn't match it
+func (client *ConfigClient) verifyListened(data *cacheData, content string) bool {
+	if client.contentVerifier == nil || isSignatureDataId(data.dataId) {
+		return true
+	}
+	signatures := &client.signatures
+	signatures.mux.Lock()
+	defer signatures.mux.Unlock()
+	if signatures.entries == nil {
+		signatures.entries = make(map[string]*listenedSignature)
+	}
+	entry, ok := signatures.entries[data.cacheKey]
+	if !ok {
+		entry = &listenedSignature{}
+		signatures.entries[data.cacheKey] = entry
+	}
+	var err error
+	if entry.fetched {
+		if err = client.checkSignature(data.tenant, data.group, data.dataId, content, entry.signature); err == nil {
+			entry.pendingMd5 = ""
+			return true
+		}
+	}
+	now := client.clock.Now()
+	if entry.pendingMd5 != data.md5 {
+		entry.pendingMd5, entry.pendingAt = data.md5, now
+		// the signature fetched before the content arrived is stale
+		entry.fetchedAt = time.Time{}
+	}
+	if err != nil && entry.reportedMd5 != data.md5 && entry.fetchedAt.Sub(entry.pendingAt) >= signatureRetryInterval {
+		entry.reportedMd5 = data.md5
+		go client.verifyFailed(data.tenant, data.group, data.dataId, err)
+	}
+	interval := signatureRetryInterval
+	if entry.reportedMd5 == data.md5 {
+		interval = signatureRecheckInterval
+	}
+	if !entry.fetching && now.Sub(entry.fetchedAt) >= interval {
+		entry.fetching = true
+		go client.fetchSignature(*data)
+	}
+	return false
+}
+
+// fetchSignature fetch the signature of the listened config, and notify the listeners again when it's changed
+func (client *ConfigClient) fetchSignature(data cacheData) {
+	signature, err := client.getConfigInner(vo.ConfigParam{DataId: signatureDataId(data.dataId), Group: data.group}, data.tenant)
+	signatures := &client.signatures
+	signatures.mux.Lock()
+	entry, ok := signatures.entries[data.cacheKey]
+	if !ok {
+		// canceled meanwhile
+		signatures.mux.Unlock()
+		return
+	}
+	entry.fetching = false
+	entry.fetchedAt = client.clock.Now()
+	changed := err == nil && (!entry.fetched || entry.signature != signature)
+	if err == nil {
+		entry.signature, entry.fetched = signature, true
+	}
+	signatures.mux.Unlock()
+	if err != nil {
+		data.log().Warnf("fetch content signature fail, dataId=%s, group=%s, tenant=%s, err:%v", data.dataId, data.group, data.tenant, err)
+	}
+	if changed {
+		client.asyncNotifyListenConfig()
+	}
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/introspection"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
+type configClientSnapshot struct {
+	InstanceId    string                      `json:"instanceId"`
+	Type          string                      `json:"type"`
+	NamespaceId   string                      `json:"namespaceId"`
+	ServerHealthy bool                        `json:"serverHealthy"`
+	ListenStatus  []model.ConfigListenStatus  `json:"listenStatus"`
+	Listeners     []configListenerSnapshot    `json:"listeners"`
+	Health        []model.ListenerHealth      `json:"listenerHealth"`
+	RecentErrors  []introspection.ErrorRecord `json:"recentErrors"`
+	Abilities     *rpc.AbilityState           `json:"abilities,omitempty"`
+	ContentBudget *contentBudgetSnapshot      `json:"contentBudget,omitempty"`
+}
+
+type configListenerSnapshot struct {
+	DataId           string `json:"dataId"`
+	Group            string `json:"group"`
+	Tenant           string `json:"tenant"`
+	Md5              string `json:"md5"`
+	ContentType      string `json:"contentType"`
+	ContentSize      int    `json:"contentSize"`
+	ListenerCount    int    `json:"listenerCount"`
+	TaskId           int    `json:"taskId"`
+	IsSyncWithServer bool   `json:"isSyncWithServer"`
+	ContentEvicted   bool   `json:"contentEvicted,omitempty"`
+}
+
+// InstanceId return the unique id of the client instance
+func (client *ConfigClient) InstanceId() string {
+	return client.uid
+}
+
+// Snapshot dump the runtime state of the client, content of configs is not included
+func (client *ConfigClient) Snapshot() interface{} {
+	clientConfig, _ := client.GetClientConfig()
+	var rpcClient *rpc.RpcClient
+	if lookup, ok := client.configProxy.(IRpcClientLookup); ok {
+		rpcClient = lookup.LookupRpcClient(client)
+	}
+	snapshot := configClientSnapshot{
+		InstanceId:    client.uid,
+		Type:          "config",
+		NamespaceId:   clientConfig.NamespaceId,
+		ServerHealthy: rpcClient != nil && rpcClient.IsRunning(),
+		ListenStatus:  client.GetListenStatus(),
+		Listeners:     make([]configListenerSnapshot, 0, client.cacheMap.Count()),
+		Health:        []model.ListenerHealth{},
+		RecentErrors:  client.errorRecorder.Recent(),
+		ContentBudget: client.contentBudget.snapshot(),
+	}
+	if rpcClient != nil {
+		abilities := rpcClient.Abilities()
+		snapshot.Abilities = &abilities
+	}
+	for _, v := range client.cacheMap.Items() {
+		data := v.(cacheData)
+		snapshot.Listeners = append(snapshot.Listeners, configListenerSnapshot{
+			DataId:           data.dataId,
+			Group:            data.group,
+			Tenant:           data.tenant,
+			Md5:              data.md5,
+			ContentType:      data.contentType,
+			ContentSize:      len(data.content),
+			ListenerCount:    len(data.listeners.items()),
+			TaskId:           data.taskId,
+			IsSyncWithServer: data.isSyncWithServer,
+			ContentEvicted:   data.evicted,
+		})
+		for _, l := range data.listeners.items() {
+			if l.breaker != nil {
+				snapshot.Health = append(snapshot.Health, l.breaker.getHealth())
+			}
+		}
+	}
+	sort.Slice(snapshot.Listeners, func(i, j int) bool {
+		a, b := snapshot.Listeners[i], snapshot.Listeners[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		return a.DataId < b.DataId
+	})
+	sort.Slice(snapshot.Health, func(i, j int) bool {
+		return snapshot.Health[i].ListenerId < snapshot.Health[j].ListenerId
+	})
+	return snapshot
+}
+
+// DumpState dump the full state of the client for reproducing it locally, including the content of configs
+func (client *ConfigClient) DumpState() introspection.ClientState {
+	clientConfig, _ := client.GetClientConfig()
+	state := introspection.ClientState{
+		InstanceId:   client.uid,
+		Type:         "config",
+		NamespaceId:  clientConfig.NamespaceId,
+		ClientConfig: introspection.RedactConfig(clientConfig),
+	}
+	state.Snapshot, _ = json.Marshal(client.Snapshot())
+	for _, v := range client.cacheMap.Items() {
+		data := v.(cacheData)
+		if data.evicted {
+			// read without caching it again, so that dumping doesn't evict the contents in use
+			data.content, _ = client.loadContent(data)
+		}
+		state.Configs = append(state.Configs, introspection.ConfigState{
+			DataId:        data.dataId,
+			Group:         data.group,
+			Tenant:        data.tenant,
+			Md5:           data.md5,
+			ContentType:   data.contentType,
+			Content:       data.content,
+			ListenerCount: len(data.listeners.items()),
+		})
+	}
+	sort.Slice(state.Configs, func(i, j int) bool {
+		a, b := state.Configs[i], state.Configs[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		return a.DataId < b.DataId
+	})
+	return state
+}
+
+// RefreshConfigs fetch the listened configs matching dataId and group from server again, empty dataId or group matches all,
+// the listeners are notified when the content changed. It's used by the admin handler of introspection
+func (client *ConfigClient) RefreshConfigs(dataId, group string) (count int, err error) {
+	for _, v := range client.cacheMap.Items() {
+		data := v.(cacheData)
+		if (dataId != "" && data.dataId != dataId) || (group != "" && data.group != group) {
+			continue
+		}
+		if refreshErr := client.refreshContentAndCheck(data, false); refreshErr != nil {
+			err = refreshErr
+			continue
+		}
+		count++
+	}
+	return count, err
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+const defaultSpringFileExtension = "properties"
+
+// SpringConfigReader reads the configs laid out in the convention of Spring Cloud Alibaba, so that the Go applications
+// share the configs with the Java ones. The configs from the lowest precedence are the shared configs, the extension
+// configs, and the application configs {application}, {application}.{ext} and {application}-{profile}.{ext}
+type SpringConfigReader struct {
+	client IConfigClient
+	param  vo.SpringConfigParam
+}
+
+// NewSpringConfigReader create the reader of the configs of param.Application
+func NewSpringConfigReader(client IConfigClient, param vo.SpringConfigParam) (*SpringConfigReader, error) {
+	if client == nil {
+		return nil, errors.New("[config_client.NewSpringConfigReader] client can not be nil")
+	}
+	if len(param.Application) == 0 {
+		return nil, errors.New("[config_client.NewSpringConfigReader] Application can not be empty")
+	}
+	if len(param.FileExtension) == 0 {
+		param.FileExtension = defaultSpringFileExtension
+	}
+	if len(param.Group) == 0 {
+		param.Group = constant.DEFAULT_GROUP
+	}
+	return &SpringConfigReader{client: client, param: param}, nil
+}
+
+// Configs return the configs read, from the lowest precedence to the highest
+func (r *SpringConfigReader) Configs() []vo.ConfigParam {
+	p := r.param
+	configs := make([]vo.ConfigParam, 0, len(p.SharedConfigs)+len(p.ExtensionConfigs)+2+len(p.Profiles))
+	for _, config := range append(append([]vo.ConfigParam{}, p.SharedConfigs...), p.ExtensionConfigs...) {
+		if len(config.Group) == 0 {
+			config.Group = constant.DEFAULT_GROUP
+		}
+		if len(config.NamespaceId) == 0 {
+			config.NamespaceId = p.NamespaceId
+		}
+		configs = append(configs, config)
+	}
+	application := func(dataId string) vo.ConfigParam {
+		return vo.ConfigParam{DataId: dataId, Group: p.Group, NamespaceId: p.NamespaceId, Type: p.FileExtension}
+	}
+	configs = append(configs, application(p.Application), application(p.Application+"."+p.FileExtension))
+	for _, profile := range p.Profiles {
+		configs = append(configs, application(p.Application+"-"+profile+"."+p.FileExtension))
+	}
+	return configs
+}
+
+// Environment return the property sources of the configs which exist, from the highest precedence to the lowest
+// like the environment endpoint of Spring Cloud Config. The placeholders are not resolved
+func (r *SpringConfigReader) Environment() (*model.SpringEnvironment, error) {
+	configs := r.Configs()
+	env := &model.SpringEnvironment{Name: r.param.Application, Profiles: r.param.Profiles}
+	for i := len(configs) - 1; i >= 0; i-- {
+		config := configs[i]
+		content, err := r.client.GetConfig(config)
+		if err != nil {
+			return nil, errors.Wrapf(err, "[SpringConfigReader] get config %s failed", config.DataId)
+		}
+		if len(content) == 0 {
+			continue
+		}
+		props, err := decodeConfigProperties(config, content)
+		if err != nil {
+			return nil, errors.Wrap(err, "[SpringConfigReader]")
+		}
+		env.PropertySources = append(env.PropertySources, model.PropertySource{
+			Name:   config.DataId + "," + config.Group,
+			Source: props,
+		})
+	}
+	return env, nil
+}
+
+// Properties return the properties of all configs flattened by precedence, the placeholders are resolved when
+// ResolvePlaceholders of param is set
+func (r *SpringConfigReader) Properties() (map[string]string, error) {
+	env, err := r.Environment()
+	if err != nil {
+		return nil, err
+	}
+	sets := make([]map[string]string, 0, len(env.PropertySources))
+	for i := len(env.PropertySources) - 1; i >= 0; i-- {
+		sets = append(sets, env.PropertySources[i].Source)
+	}
+	return resolveProperties(util.MergeProperties(sets...), r.param.ResolvePlaceholders, r.param.LookupEnv)
+}
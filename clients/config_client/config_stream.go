@@ -0,0 +1,119 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/cache"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"github.com/pkg/errors"
+)
+
+// GetConfigStream get the config as a stream, the failover file and the snapshot read when the server fails are
+// streamed from disk directly. The content from server arrives in a single message of the grpc protocol, it's read
+// without being copied again. The content is read as a whole when it's decrypted by kms, verified by signature or
+// looked up in the fallback groups
+func (client *ConfigClient) GetConfigStream(param vo.ConfigParam) (io.ReadCloser, error) {
+	if len(param.DataId) <= 0 {
+		return nil, errors.New("[client.GetConfigStream] param.dataId can not be empty")
+	}
+	if client.transformContent(param.DataId) || len(param.FallbackGroups) > 0 {
+		content, err := client.GetConfig(param)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.NopCloser(strings.NewReader(content)), nil
+	}
+	param.Group = client.GroupOf(param.DataId, param.Group)
+	tenant := client.tenantOf(param)
+	cacheKey := util.GetConfigCacheKey(param.DataId, param.Group, tenant)
+	if f := cache.OpenFailover(cacheKey, client.configCacheDir); f != nil {
+		if err := client.checkFileContentSize(param.DataId, f); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+	response, err := client.queryConfig(param, tenant)
+	if err != nil {
+		logger.With(logger.ConfigContext(tenant, param.Group, param.DataId)).Errorf("get config stream from server error:%v, dataId=%s, group=%s, namespaceId=%s", err,
+			param.DataId, param.Group, tenant)
+		if err = client.snapshotAllowed(param, tenant, cacheKey, err); err != nil {
+			return nil, err
+		}
+		f, cacheErr := cache.OpenConfigCacheFile(cacheKey, client.configCacheDir)
+		if cacheErr != nil {
+			return nil, errors.Errorf("read config from both server and cache fail, err=%v，dataId=%s, group=%s, namespaceId=%s",
+				cacheErr, param.DataId, param.Group, tenant)
+		}
+		if err = client.checkFileContentSize(param.DataId, f); err != nil {
+			return nil, err
+		}
+		logger.With(logger.ConfigContext(tenant, param.Group, param.DataId)).Warnf("read config stream from cache, dataId=%s, group=%s, namespaceId=%s", param.DataId, param.Group, tenant)
+		return f, nil
+	}
+	if err = client.quota.CheckContentSize(param.DataId, len(response.Content)); err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(strings.NewReader(response.Content)), nil
+}
+
+// checkFileContentSize check the size of the failover or snapshot file against MaxContentSize of quota, the file
+// is closed when it's exceeded
+func (client *ConfigClient) checkFileContentSize(dataId string, f *os.File) error {
+	info, err := f.Stat()
+	if err != nil {
+		return nil
+	}
+	if err = client.quota.CheckContentSize(dataId, int(info.Size())); err != nil {
+		f.Close()
+	}
+	return err
+}
+
+// PublishConfigStream publish the content read from r. The publish request of the grpc protocol carries the
+// content in a single message, so it's read into a buffer sized by the file when r is a file and handed to
+// PublishConfig without another copy. Reading stops once the content exceeds QuotaConfig.MaxContentSize
+func (client *ConfigClient) PublishConfigStream(param vo.ConfigParam, r io.Reader) (bool, error) {
+	if r == nil {
+		return false, errors.New("[client.PublishConfigStream] reader can not be nil")
+	}
+	var builder strings.Builder
+	if f, ok := r.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+			builder.Grow(int(info.Size()))
+		}
+	}
+	if limit := client.quota.MaxContentSize(); limit > 0 {
+		r = io.LimitReader(r, int64(limit)+1)
+	}
+	if _, err := io.Copy(&builder, r); err != nil {
+		return false, errors.Wrap(err, "[client.PublishConfigStream] read content failed")
+	}
+	param.Content = builder.String()
+	return client.PublishConfig(param)
+}
+
+// transformContent return true when the content is decrypted or verified, so it can not be streamed as is
+func (client *ConfigClient) transformContent(dataId string) bool {
+	return (client.kmsClient != nil && strings.HasPrefix(dataId, "cipher-")) || client.contentVerifier != nil
+}
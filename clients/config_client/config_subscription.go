@@ -0,0 +1,116 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/cache"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"github.com/pkg/errors"
+)
+
+// configSubscriptions track the listened configs, and persist them in cacheDir when enabled
+// so that a restarted process can listen them again by RestoreSubscriptions
+type configSubscriptions struct {
+	mux       sync.Mutex
+	persist   bool
+	cacheDir  string
+	namespace string
+	items     map[string]cache.ConfigSubscription
+}
+
+func newConfigSubscriptions(persist bool, cacheDir, namespace string) *configSubscriptions {
+	return &configSubscriptions{
+		persist:   persist,
+		cacheDir:  cacheDir,
+		namespace: namespace,
+		items:     make(map[string]cache.ConfigSubscription),
+	}
+}
+
+func (s *configSubscriptions) add(key string, subscription cache.ConfigSubscription) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if _, ok := s.items[key]; ok {
+		return
+	}
+	s.items[key] = subscription
+	s.save()
+}
+
+func (s *configSubscriptions) remove(key string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if _, ok := s.items[key]; !ok {
+		return
+	}
+	delete(s.items, key)
+	s.save()
+}
+
+func (s *configSubscriptions) save() {
+	if !s.persist {
+		return
+	}
+	subscriptions := make([]cache.ConfigSubscription, 0, len(s.items))
+	for _, subscription := range s.items {
+		subscriptions = append(subscriptions, subscription)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if subscriptions[i].Group != subscriptions[j].Group {
+			return subscriptions[i].Group < subscriptions[j].Group
+		}
+		return subscriptions[i].DataId < subscriptions[j].DataId
+	})
+	if err := cache.WriteSubscriptionsToFile(s.cacheDir, s.namespace, subscriptions); err != nil {
+		logger.Errorf("persist config subscriptions failed, err:%+v", err)
+	}
+}
+
+func (s *configSubscriptions) load() ([]cache.ConfigSubscription, error) {
+	var subscriptions []cache.ConfigSubscription
+	err := cache.ReadSubscriptionsFromFile(s.cacheDir, s.namespace, &subscriptions)
+	return subscriptions, err
+}
+
+// RestoreSubscriptions listen the configs persisted by the previous process again,
+// param is the template of listeners, the DataId and Group of it are ignored
+func (client *ConfigClient) RestoreSubscriptions(param vo.ConfigParam) ([]vo.ConfigParam, error) {
+	if param.OnChange == nil && param.OnChangeErr == nil && param.OnBinaryChange == nil {
+		return nil, errors.New("[client.RestoreSubscriptions] OnChange, OnChangeErr or OnBinaryChange can not be empty")
+	}
+	subscriptions, err := client.subscriptions.load()
+	if err != nil {
+		return nil, err
+	}
+	restored := make([]vo.ConfigParam, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		p := param
+		p.DataId = subscription.DataId
+		p.Group = subscription.Group
+		p.NamespaceId = subscription.NamespaceId
+		if err = client.ListenConfig(p); err != nil {
+			return restored, err
+		}
+		restored = append(restored, p)
+	}
+	logger.Infof("restored %d config subscriptions", len(restored))
+	return restored, nil
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// WaitForConfig get the config until it exists and is not empty, the failed and empty queries are retried with the
+// exponential backoff from constant.WAIT_CONFIG_BACKOFF_BASE to constant.WAIT_CONFIG_BACKOFF_MAX. The permission
+// denied and quota exceeded errors are returned at once since retrying doesn't help
+func (client *ConfigClient) WaitForConfig(ctx context.Context, param vo.ConfigParam) (string, error) {
+	if len(param.DataId) <= 0 {
+		return "", errors.New("[client.WaitForConfig] param.dataId can not be empty")
+	}
+	backoff := constant.WAIT_CONFIG_BACKOFF_BASE
+	for {
+		content, err := client.GetConfig(param)
+		if err == nil && len(content) > 0 {
+			return content, nil
+		}
+		switch errors.Cause(err).(type) {
+		case *nacos_error.PermissionDeniedError, *nacos_error.QuotaExceededError:
+			return "", err
+		}
+		logger.Infof("waiting for config dataId=%s, group=%s, retry after %s, err:%v", param.DataId, param.Group, backoff, err)
+		timer := client.clock.NewTimer(backoff)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return "", errors.Wrapf(ctx.Err(), "[client.WaitForConfig] config dataId=%s, group=%s is not available", param.DataId, param.Group)
+		case <-client.ctx.Done():
+			timer.Stop()
+			return "", errors.New("[client.WaitForConfig] client is closed")
+		}
+		if backoff *= 2; backoff > constant.WAIT_CONFIG_BACKOFF_MAX {
+			backoff = constant.WAIT_CONFIG_BACKOFF_MAX
+		}
+	}
+}
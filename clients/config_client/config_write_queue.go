@@ -0,0 +1,130 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_client
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// configWriteQueue serialize the writes on the same config, the writers are served in the order they arrive
+// so that concurrent publishes from several goroutines never interleave with each other
+type configWriteQueue struct {
+	mux  sync.Mutex
+	keys map[string]*writeBurst
+}
+
+// writeBurst is the writers queued on a config, it's removed once all of them have finished
+type writeBurst struct {
+	waiters []chan struct{}
+	// last is the outcome of the last publish of the burst, nil when it's not a publish
+	last *writeOutcome
+}
+
+type writeOutcome struct {
+	md5       string
+	published bool
+	err       error
+}
+
+// writeTurn is the turn of a writer, prev is the outcome of the publish queued right before it, nil when the
+// writer didn't wait for a publish
+type writeTurn struct {
+	queue *configWriteQueue
+	key   string
+	prev  *writeOutcome
+}
+
+// acquire block until all writers of the key queued before have finished, and returns the turn of the writer
+func (q *configWriteQueue) acquire(key string) *writeTurn {
+	q.mux.Lock()
+	if q.keys == nil {
+		q.keys = make(map[string]*writeBurst)
+	}
+	burst, held := q.keys[key]
+	if !held {
+		burst = &writeBurst{}
+		q.keys[key] = burst
+	}
+	ready := make(chan struct{})
+	burst.waiters = append(burst.waiters, ready)
+	if !held {
+		close(ready)
+	}
+	q.mux.Unlock()
+	<-ready
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	return &writeTurn{queue: q, key: key, prev: burst.last}
+}
+
+// release pass the turn to the next writer, the write is not a publish
+func (t *writeTurn) release() {
+	t.queue.release(t.key, nil)
+}
+
+// finish record the outcome of the publish for the next writer and pass the turn to it
+func (t *writeTurn) finish(md5 string, published bool, err error) {
+	t.queue.release(t.key, &writeOutcome{md5: md5, published: published && err == nil, err: err})
+}
+
+// combine report the publish queued before along with the failure of this one, since the config on server is left
+// with the content of the previous publish, or with neither of them when it failed as well
+func (t *writeTurn) combine(err error) error {
+	if err == nil || t.prev == nil {
+		return err
+	}
+	if t.prev.published {
+		return errors.Wrapf(err, "config keeps the content of the publish queued before, md5=%s", t.prev.md5)
+	}
+	if t.prev.err != nil {
+		return errors.Wrapf(err, "the publish queued before failed as well, err:%v", t.prev.err)
+	}
+	return errors.Wrap(err, "the publish queued before failed as well")
+}
+
+// overwrote return the md5 of the publish queued before, which is overwritten by this one
+func (t *writeTurn) overwrote(md5 string) string {
+	if t.prev == nil || !t.prev.published || t.prev.md5 == md5 {
+		return ""
+	}
+	return t.prev.md5
+}
+
+// queued return the number of writers of the key, including the one holding the turn
+func (q *configWriteQueue) queued(key string) int {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	if burst, ok := q.keys[key]; ok {
+		return len(burst.waiters)
+	}
+	return 0
+}
+
+func (q *configWriteQueue) release(key string, outcome *writeOutcome) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+	burst := q.keys[key]
+	burst.waiters = burst.waiters[1:]
+	if len(burst.waiters) == 0 {
+		delete(q.keys, key)
+		return
+	}
+	burst.last = outcome
+	close(burst.waiters[0])
+}
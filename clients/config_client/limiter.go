@@ -27,6 +27,7 @@ import (
 type rateLimiterCheck struct {
 	rateLimiterCache cache.ConcurrentMap // cache
 	mux              sync.Mutex
+	queued           map[string]int // the requests of high priority waiting for the token of each key
 }
 
 var checker rateLimiterCheck
@@ -35,22 +36,61 @@ func init() {
 	checker = rateLimiterCheck{
 		rateLimiterCache: cache.NewConcurrentMap(),
 		mux:              sync.Mutex{},
+		queued:           make(map[string]int),
 	}
 }
 
-// IsLimited return true when request is limited
-func IsLimited(checkKey string) bool {
+func getLimiter(checkKey string) *rate.Limiter {
 	checker.mux.Lock()
 	defer checker.mux.Unlock()
-	var limiter *rate.Limiter
 	lm, exist := checker.rateLimiterCache.Get(checkKey)
-	if !exist {
-		// define a new limiter,allow 5 times per second,and reserve stock is 5.
-		limiter = rate.NewLimiter(rate.Limit(5), 5)
-		checker.rateLimiterCache.Set(checkKey, limiter)
-	} else {
-		limiter = lm.(*rate.Limiter)
+	if exist {
+		return lm.(*rate.Limiter)
+	}
+	// define a new limiter,allow 5 times per second,and reserve stock is 5.
+	limiter := rate.NewLimiter(rate.Limit(5), 5)
+	checker.rateLimiterCache.Set(checkKey, limiter)
+	return limiter
+}
+
+// IsLimited return true when request is limited, it's limited while the requests of high priority are queued
+func IsLimited(checkKey string) bool {
+	if queued(checkKey) {
+		return true
 	}
 	add := time.Now().Add(time.Second)
-	return !limiter.AllowN(add, 1)
+	return !getLimiter(checkKey).AllowN(add, 1)
+}
+
+func queued(checkKey string) bool {
+	checker.mux.Lock()
+	defer checker.mux.Unlock()
+	return checker.queued[checkKey] > 0
+}
+
+// waitLimited queue the request of high priority for the next token instead of refusing it, the queued requests
+// take the tokens ahead of the normal ones. It returns true when the token isn't available within maxWait
+func waitLimited(checkKey string, maxWait time.Duration) bool {
+	limiter := getLimiter(checkKey)
+	checker.mux.Lock()
+	checker.queued[checkKey]++
+	checker.mux.Unlock()
+	defer func() {
+		checker.mux.Lock()
+		defer checker.mux.Unlock()
+		if checker.queued[checkKey]--; checker.queued[checkKey] == 0 {
+			delete(checker.queued, checkKey)
+		}
+	}()
+	r := limiter.Reserve()
+	if !r.OK() {
+		return true
+	}
+	delay := r.Delay()
+	if delay > maxWait {
+		r.Cancel()
+		return true
+	}
+	time.Sleep(delay)
+	return false
 }
@@ -18,6 +18,7 @@ package config_client
 
 import (
 	"testing"
+	"time"
 
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
 
@@ -46,3 +47,30 @@ func TestLimiter(t *testing.T) {
 		}
 	}
 }
+
+func TestLimiter_HighPriorityQueued(t *testing.T) {
+	key := "limiter-priority"
+	for i := 0; i < 10; i++ {
+		IsLimited(key)
+	}
+	assert.True(t, IsLimited(key))
+	// the high priority request waits for the next token rather than being refused
+	done := make(chan bool)
+	go func() {
+		done <- waitLimited(key, time.Second)
+	}()
+	assert.Eventually(t, func() bool {
+		return queued(key)
+	}, time.Second, time.Millisecond)
+	// the normal request doesn't take the token ahead of the queued one
+	assert.True(t, IsLimited(key))
+	select {
+	case limited := <-done:
+		assert.False(t, limited)
+	case <-time.After(3 * time.Second):
+		t.Fatal("the queued request is not served")
+	}
+	assert.False(t, queued(key))
+	// the wait is bounded by maxWait
+	assert.True(t, waitLimited(key, time.Millisecond))
+}
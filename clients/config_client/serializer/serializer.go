@@ -0,0 +1,178 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package serializer publishes and listens the configs as typed values, such as the protobuf messages for the teams
+// standardizing on proto for config schemas. The binary encoded values are stored as base64 binary content
+package serializer
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+const (
+	// Json encode the values by encoding/json
+	Json = "json"
+	// Protobuf encode the proto messages in the binary wire format
+	Protobuf = "protobuf"
+)
+
+// Serializer encode and decode the values of configs, the serializers of other formats such as flatbuffers
+// are added by Register
+type Serializer interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	// Binary returns whether the encoded data is stored as base64 binary content
+	Binary() bool
+}
+
+var (
+	mutex       sync.RWMutex
+	serializers = map[string]Serializer{
+		Json:     jsonSerializer{},
+		Protobuf: protobufSerializer{},
+	}
+)
+
+// Register add the serializer of name, the serializer registered before is replaced
+func Register(name string, serializer Serializer) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	serializers[name] = serializer
+}
+
+// Get returns the serializer of name
+func Get(name string) (Serializer, bool) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	serializer, ok := serializers[name]
+	return serializer, ok
+}
+
+func get(name string) (Serializer, error) {
+	serializer, ok := Get(name)
+	if !ok {
+		return nil, errors.Errorf("serializer %s is not registered", name)
+	}
+	return serializer, nil
+}
+
+// Encode encode v into the content of config by the serializer of name
+func Encode(name string, v interface{}) (string, error) {
+	serializer, err := get(name)
+	if err != nil {
+		return "", err
+	}
+	data, err := serializer.Marshal(v)
+	if err != nil {
+		return "", errors.Wrapf(err, "marshal by %s failed", name)
+	}
+	if serializer.Binary() {
+		return util.EncodeBinaryContent(data), nil
+	}
+	return string(data), nil
+}
+
+// Decode decode the content of config into v by the serializer of name
+func Decode(name string, content string, v interface{}) error {
+	serializer, err := get(name)
+	if err != nil {
+		return err
+	}
+	data := []byte(content)
+	if serializer.Binary() {
+		if data, err = util.DecodeBinaryContent(content); err != nil {
+			return err
+		}
+	}
+	return errors.Wrapf(serializer.Unmarshal(data, v), "unmarshal by %s failed", name)
+}
+
+// PublishConfigAs publish v encoded by the serializer of name, the Content of param is ignored
+func PublishConfigAs(client config_client.IConfigClient, param vo.ConfigParam, name string, v interface{}) (bool, error) {
+	content, err := Encode(name, v)
+	if err != nil {
+		return false, errors.Wrap(err, "[serializer.PublishConfigAs]")
+	}
+	param.Content = content
+	return client.PublishConfig(param)
+}
+
+// GetConfigAs get the config and decode it into v by the serializer of name
+func GetConfigAs(client config_client.IConfigClient, param vo.ConfigParam, name string, v interface{}) error {
+	content, err := client.GetConfig(param)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(Decode(name, content, v), "[serializer.GetConfigAs]")
+}
+
+// ListenConfigAs listen the config and deliver the values decoded by the serializer of name, a new value of
+// the type of prototype is decoded on each change, prototype must be a pointer such as &pb.AppConfig{}.
+// The content failed to decode is not delivered, the OnChange of param is replaced
+func ListenConfigAs(client config_client.IConfigClient, param vo.ConfigParam, name string, prototype interface{},
+	onChange func(namespace, group, dataId string, v interface{})) error {
+	if _, err := get(name); err != nil {
+		return errors.Wrap(err, "[serializer.ListenConfigAs]")
+	}
+	t := reflect.TypeOf(prototype)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return errors.New("[serializer.ListenConfigAs] prototype must be a pointer")
+	}
+	if onChange == nil {
+		return errors.New("[serializer.ListenConfigAs] onChange can not be nil")
+	}
+	param.OnChange = func(namespace, group, dataId, content string) {
+		v := reflect.New(t.Elem()).Interface()
+		if err := Decode(name, content, v); err != nil {
+			logger.Errorf("decode config by %s fail, dataId=%s, group=%s, tenant=%s, err:%v", name, dataId, group, namespace, err)
+			return
+		}
+		onChange(namespace, group, dataId, v)
+	}
+	param.OnBinaryChange = nil
+	return client.ListenConfig(param)
+}
+
+type protobufSerializer struct{}
+
+func (protobufSerializer) Marshal(v interface{}) ([]byte, error) {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return nil, errors.Errorf("%T is not a proto message", v)
+	}
+	return proto.Marshal(message)
+}
+
+func (protobufSerializer) Unmarshal(data []byte, v interface{}) error {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return errors.Errorf("%T is not a proto message", v)
+	}
+	return proto.Unmarshal(data, message)
+}
+
+func (protobufSerializer) Binary() bool {
+	return true
+}
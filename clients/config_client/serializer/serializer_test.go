@@ -0,0 +1,92 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package serializer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	nacos_grpc_service "github.com/nacos-group/nacos-sdk-go/v2/api/grpc"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+type mapConfigClient struct {
+	config_client.IConfigClient
+	configs   map[string]string
+	listeners map[string]func(namespace, group, dataId, data string)
+}
+
+func (c *mapConfigClient) PublishConfig(param vo.ConfigParam) (bool, error) {
+	c.configs[param.DataId] = param.Content
+	if listener, ok := c.listeners[param.DataId]; ok {
+		listener("", param.Group, param.DataId, param.Content)
+	}
+	return true, nil
+}
+
+func (c *mapConfigClient) GetConfig(param vo.ConfigParam) (string, error) {
+	return c.configs[param.DataId], nil
+}
+
+func (c *mapConfigClient) ListenConfig(param vo.ConfigParam) error {
+	c.listeners[param.DataId] = param.OnChange
+	return nil
+}
+
+func TestProtobuf(t *testing.T) {
+	client := &mapConfigClient{configs: map[string]string{}, listeners: map[string]func(namespace, group, dataId, data string){}}
+	param := vo.ConfigParam{DataId: "app.pb", Group: "DEFAULT_GROUP"}
+
+	var received []*nacos_grpc_service.Metadata
+	err := ListenConfigAs(client, param, Protobuf, &nacos_grpc_service.Metadata{}, func(namespace, group, dataId string, v interface{}) {
+		received = append(received, v.(*nacos_grpc_service.Metadata))
+	})
+	assert.Nil(t, err)
+
+	ok, err := PublishConfigAs(client, param, Protobuf, &nacos_grpc_service.Metadata{Type: "app", ClientIp: "10.0.0.1"})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(client.configs["app.pb"], constant.BINARY_CONTENT_PREFIX))
+
+	message := &nacos_grpc_service.Metadata{}
+	assert.Nil(t, GetConfigAs(client, param, Protobuf, message))
+	assert.Equal(t, "10.0.0.1", message.ClientIp)
+	assert.Len(t, received, 1)
+	assert.Equal(t, "app", received[0].Type)
+
+	// the content failed to decode is not delivered
+	_, _ = client.PublishConfig(vo.ConfigParam{DataId: "app.pb", Content: constant.BINARY_CONTENT_PREFIX + "!!"})
+	assert.Len(t, received, 1)
+
+	_, err = PublishConfigAs(client, param, Protobuf, map[string]string{})
+	assert.NotNil(t, err)
+	assert.NotNil(t, ListenConfigAs(client, param, "flatbuffers", &nacos_grpc_service.Metadata{}, nil))
+	assert.NotNil(t, ListenConfigAs(client, param, Protobuf, nacos_grpc_service.Metadata{}, nil))
+}
+
+func TestJson(t *testing.T) {
+	content, err := Encode(Json, map[string]int{"a": 1})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"a":1}`, content)
+	var v map[string]int
+	assert.Nil(t, Decode(Json, content, &v))
+	assert.Equal(t, 1, v["a"])
+}
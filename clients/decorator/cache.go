@@ -0,0 +1,249 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package decorator
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// purge the expired entries on put when the cache grows beyond it
+const cachePurgeSize = 1024
+
+// WithCache serve the queries of configs and services from cache within ttl, the errors are not cached. The cached
+// configs are evicted when they are written by this client, and the cached services are evicted when any instance
+// is written by this client. The changes made by others are seen after ttl at most
+func WithCache(ttl time.Duration) Decorator {
+	return &cacheDecorator{ttl: ttl, clock: clock.OrReal(nil)}
+}
+
+type cacheDecorator struct {
+	ttl   time.Duration
+	clock clock.Clock
+}
+
+func (d *cacheDecorator) Config(client config_client.IConfigClient) config_client.IConfigClient {
+	return &cachedConfigClient{IConfigClient: client, cache: newTtlCache(d.ttl, d.clock)}
+}
+
+func (d *cacheDecorator) Naming(client naming_client.INamingClient) naming_client.INamingClient {
+	return &cachedNamingClient{INamingClient: client, cache: newTtlCache(d.ttl, d.clock)}
+}
+
+type cacheEntry struct {
+	value    interface{}
+	expireAt time.Time
+}
+
+type ttlCache struct {
+	ttl     time.Duration
+	clock   clock.Clock
+	mutex   sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func newTtlCache(ttl time.Duration, clock clock.Clock) *ttlCache {
+	return &ttlCache{ttl: ttl, clock: clock, entries: make(map[string]cacheEntry)}
+}
+
+func (c *ttlCache) get(key string) (interface{}, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.clock.Now().Before(entry.expireAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *ttlCache) put(key string, value interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := c.clock.Now()
+	if len(c.entries) >= cachePurgeSize {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expireAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[key] = cacheEntry{value: value, expireAt: now.Add(c.ttl)}
+}
+
+func (c *ttlCache) remove(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.entries, key)
+}
+
+func (c *ttlCache) clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries = make(map[string]cacheEntry)
+}
+
+type cachedConfigClient struct {
+	config_client.IConfigClient
+	cache *ttlCache
+}
+
+func configCacheKey(param vo.ConfigParam) string {
+	return util.GetConfigCacheKey(param.DataId, param.Group, param.NamespaceId)
+}
+
+func (c *cachedConfigClient) GetConfig(param vo.ConfigParam) (string, error) {
+	key := configCacheKey(param)
+	if content, ok := c.cache.get(key); ok {
+		return content.(string), nil
+	}
+	content, err := c.IConfigClient.GetConfig(param)
+	if err == nil {
+		c.cache.put(key, content)
+	}
+	return content, err
+}
+
+func (c *cachedConfigClient) PublishConfig(param vo.ConfigParam) (bool, error) {
+	defer c.cache.remove(configCacheKey(param))
+	return c.IConfigClient.PublishConfig(param)
+}
+
+func (c *cachedConfigClient) PublishConfigStream(param vo.ConfigParam, r io.Reader) (bool, error) {
+	defer c.cache.remove(configCacheKey(param))
+	return c.IConfigClient.PublishConfigStream(param, r)
+}
+
+func (c *cachedConfigClient) PublishBinaryConfig(param vo.ConfigParam, data []byte) (bool, error) {
+	defer c.cache.remove(configCacheKey(param))
+	return c.IConfigClient.PublishBinaryConfig(param, data)
+}
+
+func (c *cachedConfigClient) Promote(param vo.ConfigParam) (bool, error) {
+	defer c.cache.remove(configCacheKey(param))
+	return c.IConfigClient.Promote(param)
+}
+
+func (c *cachedConfigClient) DeleteConfig(param vo.ConfigParam) (bool, error) {
+	defer c.cache.remove(configCacheKey(param))
+	return c.IConfigClient.DeleteConfig(param)
+}
+
+// cachedNamingClient returns copies of the cached instances, so the callers modifying them don't change the cache
+type cachedNamingClient struct {
+	naming_client.INamingClient
+	cache *ttlCache
+}
+
+func namingCacheKey(method string, param interface{}) string {
+	return fmt.Sprintf("%s%+v", method, param)
+}
+
+func copyInstances(instances []model.Instance) []model.Instance {
+	if instances == nil {
+		return nil
+	}
+	return append(make([]model.Instance, 0, len(instances)), instances...)
+}
+
+func (c *cachedNamingClient) GetService(param vo.GetServiceParam) (model.Service, error) {
+	key := namingCacheKey("GetService", param)
+	if service, ok := c.cache.get(key); ok {
+		service := service.(model.Service)
+		service.Hosts = copyInstances(service.Hosts)
+		return service, nil
+	}
+	service, err := c.INamingClient.GetService(param)
+	if err == nil {
+		cached := service
+		cached.Hosts = copyInstances(service.Hosts)
+		c.cache.put(key, cached)
+	}
+	return service, err
+}
+
+func (c *cachedNamingClient) SelectAllInstances(param vo.SelectAllInstancesParam) ([]model.Instance, error) {
+	key := namingCacheKey("SelectAllInstances", param)
+	if instances, ok := c.cache.get(key); ok {
+		return copyInstances(instances.([]model.Instance)), nil
+	}
+	instances, err := c.INamingClient.SelectAllInstances(param)
+	if err == nil {
+		c.cache.put(key, copyInstances(instances))
+	}
+	return instances, err
+}
+
+func (c *cachedNamingClient) SelectInstances(param vo.SelectInstancesParam) ([]model.Instance, error) {
+	key := namingCacheKey("SelectInstances", param)
+	if instances, ok := c.cache.get(key); ok {
+		return copyInstances(instances.([]model.Instance)), nil
+	}
+	instances, err := c.INamingClient.SelectInstances(param)
+	if err == nil {
+		c.cache.put(key, copyInstances(instances))
+	}
+	return instances, err
+}
+
+func (c *cachedNamingClient) GetAllServicesInfo(param vo.GetAllServiceInfoParam) (model.ServiceList, error) {
+	key := namingCacheKey("GetAllServicesInfo", param)
+	if services, ok := c.cache.get(key); ok {
+		services := services.(model.ServiceList)
+		services.Doms = append([]string(nil), services.Doms...)
+		return services, nil
+	}
+	services, err := c.INamingClient.GetAllServicesInfo(param)
+	if err == nil {
+		cached := services
+		cached.Doms = append([]string(nil), services.Doms...)
+		c.cache.put(key, cached)
+	}
+	return services, err
+}
+
+func (c *cachedNamingClient) RegisterInstance(param vo.RegisterInstanceParam) (bool, error) {
+	defer c.cache.clear()
+	return c.INamingClient.RegisterInstance(param)
+}
+
+func (c *cachedNamingClient) BatchRegisterInstance(param vo.BatchRegisterInstanceParam) (bool, error) {
+	defer c.cache.clear()
+	return c.INamingClient.BatchRegisterInstance(param)
+}
+
+func (c *cachedNamingClient) DeregisterInstance(param vo.DeregisterInstanceParam) (bool, error) {
+	defer c.cache.clear()
+	return c.INamingClient.DeregisterInstance(param)
+}
+
+func (c *cachedNamingClient) UpdateInstance(param vo.UpdateInstanceParam) (bool, error) {
+	defer c.cache.clear()
+	return c.INamingClient.UpdateInstance(param)
+}
@@ -0,0 +1,399 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package decorator wraps IConfigClient and INamingClient with cross-cutting behaviors, such as retrying, caching,
+// metrics and logging, without forking the client internals:
+//
+//	configClient = decorator.ConfigClient(configClient, decorator.WithMetrics(prometheus.DefaultRegisterer),
+//		decorator.WithRetry(decorator.RetryConfig{}), decorator.WithCache(time.Second))
+//
+// The first decorator is the outermost one, so the metrics above observe the retries and the cache hits as one call.
+package decorator
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// Decorator wraps the config client and the naming client with the same behavior
+type Decorator interface {
+	Config(client config_client.IConfigClient) config_client.IConfigClient
+	Naming(client naming_client.INamingClient) naming_client.INamingClient
+}
+
+// ConfigClient wrap the client with decorators, the first decorator is the outermost one
+func ConfigClient(client config_client.IConfigClient, decorators ...Decorator) config_client.IConfigClient {
+	for i := len(decorators) - 1; i >= 0; i-- {
+		client = decorators[i].Config(client)
+	}
+	return client
+}
+
+// NamingClient wrap the client with decorators, the first decorator is the outermost one
+func NamingClient(client naming_client.INamingClient, decorators ...Decorator) naming_client.INamingClient {
+	for i := len(decorators) - 1; i >= 0; i-- {
+		client = decorators[i].Naming(client)
+	}
+	return client
+}
+
+// interceptor is called around the methods returning error, call invokes the method of the wrapped client
+type interceptor func(method string, call func() error) error
+
+// interceptorDecorator decorates the clients by an interceptor
+type interceptorDecorator interceptor
+
+func (d interceptorDecorator) Config(client config_client.IConfigClient) config_client.IConfigClient {
+	return &interceptedConfigClient{IConfigClient: client, intercept: interceptor(d)}
+}
+
+func (d interceptorDecorator) Naming(client naming_client.INamingClient) naming_client.INamingClient {
+	return &interceptedNamingClient{INamingClient: client, intercept: interceptor(d)}
+}
+
+// interceptedConfigClient intercepts the methods returning error, the others are called on the wrapped client directly
+type interceptedConfigClient struct {
+	config_client.IConfigClient
+	intercept interceptor
+}
+
+func (c *interceptedConfigClient) GetConfig(param vo.ConfigParam) (content string, err error) {
+	err = c.intercept("GetConfig", func() error {
+		content, err = c.IConfigClient.GetConfig(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) WaitForConfig(ctx context.Context, param vo.ConfigParam) (content string, err error) {
+	err = c.intercept("WaitForConfig", func() error {
+		content, err = c.IConfigClient.WaitForConfig(ctx, param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) GetConfigStream(param vo.ConfigParam) (reader io.ReadCloser, err error) {
+	err = c.intercept("GetConfigStream", func() error {
+		reader, err = c.IConfigClient.GetConfigStream(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) PublishConfig(param vo.ConfigParam) (published bool, err error) {
+	err = c.intercept("PublishConfig", func() error {
+		published, err = c.IConfigClient.PublishConfig(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) PublishConfigWithResult(param vo.ConfigParam) (result model.PublishResult, err error) {
+	err = c.intercept("PublishConfigWithResult", func() error {
+		result, err = c.IConfigClient.PublishConfigWithResult(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) PublishConfigAt(param vo.ConfigParam, effectiveTime time.Time) (scheduled *config_client.ScheduledPublish, err error) {
+	err = c.intercept("PublishConfigAt", func() error {
+		scheduled, err = c.IConfigClient.PublishConfigAt(param, effectiveTime)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) Promote(param vo.ConfigParam) (promoted bool, err error) {
+	err = c.intercept("Promote", func() error {
+		promoted, err = c.IConfigClient.Promote(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) PublishConfigStream(param vo.ConfigParam, r io.Reader) (published bool, err error) {
+	err = c.intercept("PublishConfigStream", func() error {
+		published, err = c.IConfigClient.PublishConfigStream(param, r)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) PublishBinaryConfig(param vo.ConfigParam, data []byte) (published bool, err error) {
+	err = c.intercept("PublishBinaryConfig", func() error {
+		published, err = c.IConfigClient.PublishBinaryConfig(param, data)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) GetBinaryConfig(param vo.ConfigParam) (data []byte, err error) {
+	err = c.intercept("GetBinaryConfig", func() error {
+		data, err = c.IConfigClient.GetBinaryConfig(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) DeleteConfig(param vo.ConfigParam) (deleted bool, err error) {
+	err = c.intercept("DeleteConfig", func() error {
+		deleted, err = c.IConfigClient.DeleteConfig(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) ListenConfig(param vo.ConfigParam) error {
+	return c.intercept("ListenConfig", func() error {
+		return c.IConfigClient.ListenConfig(param)
+	})
+}
+
+func (c *interceptedConfigClient) ListenConfigWithContext(ctx context.Context, param vo.ConfigParam) error {
+	return c.intercept("ListenConfigWithContext", func() error {
+		return c.IConfigClient.ListenConfigWithContext(ctx, param)
+	})
+}
+
+func (c *interceptedConfigClient) ListenConfigPath(param vo.ConfigParam, path string) error {
+	return c.intercept("ListenConfigPath", func() error {
+		return c.IConfigClient.ListenConfigPath(param, path)
+	})
+}
+
+func (c *interceptedConfigClient) CancelListenConfig(param vo.ConfigParam) error {
+	return c.intercept("CancelListenConfig", func() error {
+		return c.IConfigClient.CancelListenConfig(param)
+	})
+}
+
+func (c *interceptedConfigClient) Tail(ctx context.Context, param vo.ConfigParam) (events <-chan model.ConfigChangeEvent, err error) {
+	err = c.intercept("Tail", func() error {
+		events, err = c.IConfigClient.Tail(ctx, param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) GetProperties(param vo.PropertiesParam) (properties map[string]string, err error) {
+	err = c.intercept("GetProperties", func() error {
+		properties, err = c.IConfigClient.GetProperties(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) SearchConfig(param vo.SearchConfigParam) (page *model.ConfigPage, err error) {
+	err = c.intercept("SearchConfig", func() error {
+		page, err = c.IConfigClient.SearchConfig(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) ListConfigKeys(param vo.ListConfigKeysParam) (page *model.ConfigKeyPage, err error) {
+	err = c.intercept("ListConfigKeys", func() error {
+		page, err = c.IConfigClient.ListConfigKeys(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) ListConfigHistory(param vo.ConfigParam) (snapshots []model.ConfigSnapshot, err error) {
+	err = c.intercept("ListConfigHistory", func() error {
+		snapshots, err = c.IConfigClient.ListConfigHistory(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) GetConfigHistory(param vo.ConfigParam, at time.Time) (content string, err error) {
+	err = c.intercept("GetConfigHistory", func() error {
+		content, err = c.IConfigClient.GetConfigHistory(param, at)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) RestoreSubscriptions(param vo.ConfigParam) (restored []vo.ConfigParam, err error) {
+	err = c.intercept("RestoreSubscriptions", func() error {
+		restored, err = c.IConfigClient.RestoreSubscriptions(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedConfigClient) GetNamespaceChecksum(namespace, group string) (manifest *model.ConfigManifest, err error) {
+	err = c.intercept("GetNamespaceChecksum", func() error {
+		manifest, err = c.IConfigClient.GetNamespaceChecksum(namespace, group)
+		return err
+	})
+	return
+}
+
+// interceptedNamingClient intercepts the methods returning error, the others are called on the wrapped client directly
+type interceptedNamingClient struct {
+	naming_client.INamingClient
+	intercept interceptor
+}
+
+func (c *interceptedNamingClient) RegisterInstance(param vo.RegisterInstanceParam) (registered bool, err error) {
+	err = c.intercept("RegisterInstance", func() error {
+		registered, err = c.INamingClient.RegisterInstance(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedNamingClient) RegisterInstanceWithTTL(param vo.RegisterInstanceWithTTLParam) (lease *naming_client.Lease, err error) {
+	err = c.intercept("RegisterInstanceWithTTL", func() error {
+		lease, err = c.INamingClient.RegisterInstanceWithTTL(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedNamingClient) NewMutex(param vo.MutexParam) (mutex *naming_client.Mutex, err error) {
+	err = c.intercept("NewMutex", func() error {
+		mutex, err = c.INamingClient.NewMutex(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedNamingClient) BatchRegisterInstance(param vo.BatchRegisterInstanceParam) (registered bool, err error) {
+	err = c.intercept("BatchRegisterInstance", func() error {
+		registered, err = c.INamingClient.BatchRegisterInstance(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedNamingClient) DeregisterInstance(param vo.DeregisterInstanceParam) (deregistered bool, err error) {
+	err = c.intercept("DeregisterInstance", func() error {
+		deregistered, err = c.INamingClient.DeregisterInstance(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedNamingClient) UpdateInstance(param vo.UpdateInstanceParam) (updated bool, err error) {
+	err = c.intercept("UpdateInstance", func() error {
+		updated, err = c.INamingClient.UpdateInstance(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedNamingClient) GetService(param vo.GetServiceParam) (service model.Service, err error) {
+	err = c.intercept("GetService", func() error {
+		service, err = c.INamingClient.GetService(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedNamingClient) CompareAndGetService(param vo.GetServiceParam, lastEpoch uint64) (service model.Service, changed bool, err error) {
+	err = c.intercept("CompareAndGetService", func() error {
+		service, changed, err = c.INamingClient.CompareAndGetService(param, lastEpoch)
+		return err
+	})
+	return
+}
+
+func (c *interceptedNamingClient) SelectAllInstances(param vo.SelectAllInstancesParam) (instances []model.Instance, err error) {
+	err = c.intercept("SelectAllInstances", func() error {
+		instances, err = c.INamingClient.SelectAllInstances(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedNamingClient) SelectInstances(param vo.SelectInstancesParam) (instances []model.Instance, err error) {
+	err = c.intercept("SelectInstances", func() error {
+		instances, err = c.INamingClient.SelectInstances(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedNamingClient) SelectOneHealthyInstance(param vo.SelectOneHealthInstanceParam) (instance *model.Instance, err error) {
+	err = c.intercept("SelectOneHealthyInstance", func() error {
+		instance, err = c.INamingClient.SelectOneHealthyInstance(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedNamingClient) Subscribe(param *vo.SubscribeParam) error {
+	return c.intercept("Subscribe", func() error {
+		return c.INamingClient.Subscribe(param)
+	})
+}
+
+func (c *interceptedNamingClient) Unsubscribe(param *vo.SubscribeParam) error {
+	return c.intercept("Unsubscribe", func() error {
+		return c.INamingClient.Unsubscribe(param)
+	})
+}
+
+func (c *interceptedNamingClient) WaitForService(ctx context.Context, param vo.WaitForServiceParam) (instances []model.Instance, err error) {
+	err = c.intercept("WaitForService", func() error {
+		instances, err = c.INamingClient.WaitForService(ctx, param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedNamingClient) Watch(ctx context.Context, param vo.WatchParam) (events <-chan model.InstanceChangeEvent, err error) {
+	err = c.intercept("Watch", func() error {
+		events, err = c.INamingClient.Watch(ctx, param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedNamingClient) RestoreSubscriptions(param vo.SubscribeParam) (restored []*vo.SubscribeParam, err error) {
+	err = c.intercept("RestoreSubscriptions", func() error {
+		restored, err = c.INamingClient.RestoreSubscriptions(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedNamingClient) GetAllServicesInfo(param vo.GetAllServiceInfoParam) (services model.ServiceList, err error) {
+	err = c.intercept("GetAllServicesInfo", func() error {
+		services, err = c.INamingClient.GetAllServicesInfo(param)
+		return err
+	})
+	return
+}
+
+func (c *interceptedNamingClient) ListGroups(namespace string) (groups []string, err error) {
+	err = c.intercept("ListGroups", func() error {
+		groups, err = c.INamingClient.ListGroups(namespace)
+		return err
+	})
+	return
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package decorator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
+	"github.com/nacos-group/nacos-sdk-go/v2/mock"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+var errServer = errors.New("server error")
+
+func TestWithRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	configClient := mock.NewMockIConfigClient(ctrl)
+	param := vo.ConfigParam{DataId: "a", Group: "b"}
+	gomock.InOrder(
+		configClient.EXPECT().GetConfig(param).Return("", errServer).Times(2),
+		configClient.EXPECT().GetConfig(param).Return("content", nil),
+	)
+	configClient.EXPECT().ListenConfig(param).Return(errServer).Times(1)
+
+	client := ConfigClient(configClient, WithRetry(RetryConfig{Backoff: time.Millisecond}))
+	content, err := client.GetConfig(param)
+	assert.Nil(t, err)
+	assert.Equal(t, "content", content)
+	// ListenConfig is not idempotent
+	assert.Equal(t, errServer, client.ListenConfig(param))
+
+	namingClient := mock.NewMockINamingClient(ctrl)
+	namingClient.EXPECT().GetService(gomock.Any()).Return(model.Service{}, errServer).Times(2)
+	_, err = NamingClient(namingClient, WithRetry(RetryConfig{MaxAttempts: 2, Backoff: time.Millisecond})).
+		GetService(vo.GetServiceParam{ServiceName: "demo"})
+	assert.Equal(t, errServer, err)
+}
+
+func TestWithCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	fakeClock := clock.NewFakeClock(time.Now())
+	cache := &cacheDecorator{ttl: time.Second, clock: fakeClock}
+
+	configClient := mock.NewMockIConfigClient(ctrl)
+	param := vo.ConfigParam{DataId: "a", Group: "b"}
+	configClient.EXPECT().GetConfig(param).Return("", errServer).Times(1)
+	configClient.EXPECT().GetConfig(param).Return("v1", nil).Times(1)
+	configClient.EXPECT().PublishConfig(gomock.Any()).Return(true, nil).Times(1)
+	configClient.EXPECT().GetConfig(param).Return("v2", nil).Times(2)
+	client := ConfigClient(configClient, cache)
+
+	// the errors are not cached
+	_, err := client.GetConfig(param)
+	assert.Equal(t, errServer, err)
+	for i := 0; i < 2; i++ {
+		content, err := client.GetConfig(param)
+		assert.Nil(t, err)
+		assert.Equal(t, "v1", content)
+	}
+	// evicted on write
+	_, _ = client.PublishConfig(vo.ConfigParam{DataId: "a", Group: "b", Content: "v2"})
+	content, _ := client.GetConfig(param)
+	assert.Equal(t, "v2", content)
+	// expired after ttl
+	fakeClock.Advance(time.Second)
+	content, _ = client.GetConfig(param)
+	assert.Equal(t, "v2", content)
+
+	namingClient := mock.NewMockINamingClient(ctrl)
+	selectParam := vo.SelectInstancesParam{ServiceName: "demo", HealthyOnly: true}
+	namingClient.EXPECT().SelectInstances(selectParam).Return([]model.Instance{{Ip: "127.0.0.1"}}, nil).Times(1)
+	cachedNaming := NamingClient(namingClient, cache)
+	instances, _ := cachedNaming.SelectInstances(selectParam)
+	instances[0].Ip = "modified"
+	instances, _ = cachedNaming.SelectInstances(selectParam)
+	assert.Equal(t, "127.0.0.1", instances[0].Ip)
+}
+
+func TestWithMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	configClient := mock.NewMockIConfigClient(ctrl)
+	configClient.EXPECT().GetConfig(gomock.Any()).Return("", errServer).Times(2)
+	configClient.EXPECT().GetConfig(gomock.Any()).Return("content", nil).Times(1)
+
+	reg := prometheus.NewRegistry()
+	// the metrics observe the retried calls as one
+	client := ConfigClient(configClient, WithMetrics(reg), WithRetry(RetryConfig{Backoff: time.Millisecond}))
+	_, err := client.GetConfig(vo.ConfigParam{DataId: "a", Group: "b"})
+	assert.Nil(t, err)
+	count, err := testutil.GatherAndCount(reg, "nacos_client_call_duration_seconds")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+
+	// the histogram is shared by the decorators registered to the same registerer
+	namingClient := mock.NewMockINamingClient(ctrl)
+	namingClient.EXPECT().Subscribe(gomock.Any()).Return(nil)
+	assert.Nil(t, NamingClient(namingClient, WithMetrics(reg), WithLogging("debug")).Subscribe(&vo.SubscribeParam{}))
+	count, err = testutil.GatherAndCount(reg, "nacos_client_call_duration_seconds")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+}
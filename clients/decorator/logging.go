@@ -0,0 +1,51 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package decorator
+
+import (
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+)
+
+// WithLogging log the calls with their duration at level, which is one of debug, info, warn and error. The failed
+// calls are logged at warn level at least
+func WithLogging(level string) Decorator {
+	logf := logger.Infof
+	switch level {
+	case "debug":
+		logf = logger.Debugf
+	case "warn":
+		logf = logger.Warnf
+	case "error":
+		logf = logger.Errorf
+	}
+	failf := logger.Warnf
+	if level == "error" {
+		failf = logger.Errorf
+	}
+	return interceptorDecorator(func(method string, call func() error) error {
+		start := time.Now()
+		err := call()
+		if err != nil {
+			failf("[decorator] %s failed, cost:%s, err:%v", method, time.Since(start), err)
+		} else {
+			logf("[decorator] %s succeeded, cost:%s", method, time.Since(start))
+		}
+		return err
+	})
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package decorator
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+)
+
+// WithMetrics observe the duration of the calls in the histogram nacos_client_call_duration_seconds registered to reg,
+// labeled by client, method and result. The histogram is shared when it's already registered to reg
+func WithMetrics(reg prometheus.Registerer) Decorator {
+	histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "nacos_client_call_duration_seconds",
+		Help: "the duration of the calls to the decorated clients",
+	}, []string{"client", "method", "result"})
+	if err := reg.Register(histogram); err != nil {
+		if registered, ok := err.(prometheus.AlreadyRegisteredError); ok {
+			histogram = registered.ExistingCollector.(*prometheus.HistogramVec)
+		} else {
+			logger.Warnf("[decorator] register metrics failed, err:%v", err)
+		}
+	}
+	return &metricsDecorator{histogram: histogram}
+}
+
+type metricsDecorator struct {
+	histogram *prometheus.HistogramVec
+}
+
+func (d *metricsDecorator) Config(client config_client.IConfigClient) config_client.IConfigClient {
+	return d.intercept("config").Config(client)
+}
+
+func (d *metricsDecorator) Naming(client naming_client.INamingClient) naming_client.INamingClient {
+	return d.intercept("naming").Naming(client)
+}
+
+func (d *metricsDecorator) intercept(client string) interceptorDecorator {
+	return func(method string, call func() error) error {
+		start := time.Now()
+		err := call()
+		result := "success"
+		if err != nil {
+			result = "error"
+		}
+		d.histogram.WithLabelValues(client, method, result).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package decorator
+
+import (
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
+)
+
+const (
+	defaultRetryAttempts = 3
+	defaultRetryBackoff  = 100 * time.Millisecond
+	defaultMaxBackoff    = 2 * time.Second
+)
+
+// retryableMethods are the methods safe to be called again, the others such as ListenConfig and Subscribe register
+// local state and are never retried
+var retryableMethods = map[string]bool{
+	"GetConfig":               true,
+	"PublishConfig":           true,
+	"PublishConfigWithResult": true,
+	"PublishBinaryConfig":     true,
+	"GetBinaryConfig":         true,
+	"DeleteConfig":            true,
+	"GetProperties":           true,
+	"SearchConfig":            true,
+	"ListConfigKeys":          true,
+	"ListConfigHistory":       true,
+	"GetConfigHistory":        true,
+	"GetNamespaceChecksum":    true,
+	"RegisterInstance":        true,
+	"BatchRegisterInstance":   true,
+	"DeregisterInstance":      true,
+	"UpdateInstance":          true,
+	"GetService":              true,
+	"CompareAndGetService":    true,
+	"SelectAllInstances":      true,
+	"SelectInstances":         true,
+	"GetAllServicesInfo":      true,
+	"ListGroups":              true,
+}
+
+// RetryConfig is the config of WithRetry
+type RetryConfig struct {
+	MaxAttempts int                                 // the max attempts including the first one, default value is 3
+	Backoff     time.Duration                       // the wait before the first retry, it's doubled on each retry, default value is 100ms
+	MaxBackoff  time.Duration                       // the max wait between the attempts, default value is 2s
+	Retryable   func(method string, err error) bool // whether the failed call is retried, default is all errors of the idempotent methods
+	Clock       clock.Clock                         // the clock of backoff, default is the system clock
+}
+
+// WithRetry retry the failed calls of the idempotent methods with exponential backoff
+func WithRetry(cfg RetryConfig) Decorator {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultRetryAttempts
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = defaultRetryBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	if cfg.Retryable == nil {
+		cfg.Retryable = func(method string, err error) bool {
+			return retryableMethods[method]
+		}
+	}
+	cfg.Clock = clock.OrReal(cfg.Clock)
+	return interceptorDecorator(func(method string, call func() error) error {
+		backoff := cfg.Backoff
+		for attempt := 1; ; attempt++ {
+			err := call()
+			if err == nil || attempt >= cfg.MaxAttempts || !cfg.Retryable(method, err) {
+				return err
+			}
+			timer := cfg.Clock.NewTimer(backoff)
+			<-timer.C()
+			if backoff *= 2; backoff > cfg.MaxBackoff {
+				backoff = cfg.MaxBackoff
+			}
+		}
+	})
+}
@@ -0,0 +1,318 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package election implements the leader election on a lock config, the leadership is acquired and renewed
+// by publishing the lock record with compare-and-swap, so that only one candidate holds it at a time
+package election
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/inner/uuid"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+const (
+	defaultTTL = 15 * time.Second
+	// emptyContentMd5 is used as the cas md5 of absent lock config, so that it's created only if it's still absent
+	emptyContentMd5 = "d41d8cd98f00b204e9800998ecf8427e"
+)
+
+// Record is the content of lock config
+type Record struct {
+	Holder      string `json:"holder"`      // the identity of leader, empty means the leadership is released
+	TTLMs       int64  `json:"ttlMs"`       // the ttl of leadership in milliseconds
+	AcquireTime int64  `json:"acquireTime"` // the time in milliseconds when the holder acquired the leadership
+	RenewTime   int64  `json:"renewTime"`   // the time in milliseconds when the holder renewed the leadership
+	Term        uint64 `json:"term"`        // increased each time the leadership is acquired by a new holder
+}
+
+func (r Record) ttl(defaultValue time.Duration) time.Duration {
+	if r.TTLMs <= 0 {
+		return defaultValue
+	}
+	return time.Duration(r.TTLMs) * time.Millisecond
+}
+
+// Elector campaigns for the leadership and observes the leader. The expiry of a record is judged by the local
+// clock since the record is observed changed, so the clocks of candidates don't need to be synchronized
+type Elector struct {
+	client config_client.IConfigClient
+	param  vo.ElectionParam
+
+	mux             sync.Mutex
+	leader          string
+	leading         bool
+	observedContent string
+	observedTime    time.Time
+}
+
+// NewElector create the elector of the lock config, the campaign starts by Campaign
+func NewElector(client config_client.IConfigClient, param vo.ElectionParam) (*Elector, error) {
+	if client == nil {
+		return nil, errors.New("[election.NewElector] client can not be nil")
+	}
+	if len(param.DataId) == 0 {
+		return nil, errors.New("[election.NewElector] DataId can not be empty")
+	}
+	if len(param.Group) == 0 {
+		param.Group = constant.DEFAULT_GROUP
+	}
+	if param.TTL <= 0 {
+		param.TTL = defaultTTL
+	}
+	if param.RenewInterval <= 0 {
+		param.RenewInterval = param.TTL / 3
+	}
+	if param.RenewInterval >= param.TTL {
+		return nil, errors.New("[election.NewElector] RenewInterval must be less than TTL")
+	}
+	if len(param.Identity) == 0 {
+		uid, err := uuid.NewV4()
+		if err != nil {
+			return nil, err
+		}
+		hostname, _ := os.Hostname()
+		param.Identity = hostname + "-" + uid.String()[:8]
+	}
+	return &Elector{client: client, param: param}, nil
+}
+
+// Identity return the identity of the candidate
+func (e *Elector) Identity() string {
+	return e.param.Identity
+}
+
+// IsLeader return true if the candidate holds the leadership
+func (e *Elector) IsLeader() bool {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	return e.leading
+}
+
+// Leader return the identity of the observed leader, empty means there is no leader
+func (e *Elector) Leader() string {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	return e.leader
+}
+
+// Campaign try to acquire the leadership and renew it every RenewInterval until ctx is done,
+// the leadership is released before return. The leadership is lost when it's taken by others,
+// or it's not renewed within ttl such as the server is unreachable
+func (e *Elector) Campaign(ctx context.Context) error {
+	ticker := time.NewTicker(e.param.RenewInterval)
+	defer ticker.Stop()
+	var (
+		lastRenew  time.Time
+		stopLeader context.CancelFunc
+	)
+	for {
+		if e.tryAcquireOrRenew() {
+			lastRenew = time.Now()
+			if stopLeader == nil {
+				stopLeader = e.startLeading(ctx)
+			}
+		} else if stopLeader != nil && (e.Leader() != e.param.Identity || time.Since(lastRenew) >= e.param.TTL) {
+			logger.Warnf("[election] %s lost the leadership of %s, current leader:%s", e.param.Identity, e.param.DataId, e.Leader())
+			e.stopLeading(stopLeader)
+			stopLeader = nil
+		}
+		select {
+		case <-ctx.Done():
+			if stopLeader != nil {
+				e.release()
+				e.stopLeading(stopLeader)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Observe stream the identity of leader each time it changed through the returned channel, it's used by the
+// followers which don't campaign. Empty identity means there is no leader, the channel is closed after ctx is done
+func (e *Elector) Observe(ctx context.Context) <-chan string {
+	ch := make(chan string, 1)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(e.param.RenewInterval)
+		defer ticker.Stop()
+		var (
+			last string
+			sent bool
+		)
+		for {
+			if _, _, err := e.refresh(); err != nil {
+				logger.Warnf("[election] observe the leader of %s failed, err:%v", e.param.DataId, err)
+			} else if leader := e.Leader(); !sent || leader != last {
+				select {
+				case ch <- leader:
+					last, sent = leader, true
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return ch
+}
+
+func (e *Elector) startLeading(ctx context.Context) context.CancelFunc {
+	logger.Infof("[election] %s acquired the leadership of %s", e.param.Identity, e.param.DataId)
+	leaderCtx, cancel := context.WithCancel(ctx)
+	e.mux.Lock()
+	e.leading = true
+	e.mux.Unlock()
+	if e.param.OnStartedLeading != nil {
+		go e.param.OnStartedLeading(leaderCtx)
+	}
+	return cancel
+}
+
+func (e *Elector) stopLeading(cancel context.CancelFunc) {
+	cancel()
+	e.mux.Lock()
+	e.leading = false
+	e.mux.Unlock()
+	if e.param.OnStoppedLeading != nil {
+		e.param.OnStoppedLeading()
+	}
+}
+
+// refresh get the lock record from server, the holder is regarded as absent when the record is not changed within ttl
+func (e *Elector) refresh() (record Record, content string, err error) {
+	content, err = e.client.GetConfig(e.configParam())
+	if err != nil {
+		return
+	}
+	if len(content) > 0 {
+		if err = json.Unmarshal([]byte(content), &record); err != nil {
+			err = errors.Wrapf(err, "[election] invalid lock record of %s", e.param.DataId)
+			return
+		}
+	}
+	now := time.Now()
+	e.mux.Lock()
+	if content != e.observedContent || e.observedTime.IsZero() {
+		e.observedContent = content
+		e.observedTime = now
+	}
+	holder := record.Holder
+	if len(holder) > 0 && now.Sub(e.observedTime) >= record.ttl(e.param.TTL) {
+		holder = ""
+	}
+	e.mux.Unlock()
+	e.setLeader(holder)
+	return
+}
+
+func (e *Elector) tryAcquireOrRenew() bool {
+	record, content, err := e.refresh()
+	if err != nil {
+		logger.Warnf("[election] get the lock record of %s failed, err:%v", e.param.DataId, err)
+		return false
+	}
+	if leader := e.Leader(); len(leader) > 0 && leader != e.param.Identity {
+		return false
+	}
+	now := time.Now()
+	next := Record{
+		Holder:      e.param.Identity,
+		TTLMs:       e.param.TTL.Milliseconds(),
+		AcquireTime: record.AcquireTime,
+		RenewTime:   now.UnixNano() / int64(time.Millisecond),
+		Term:        record.Term,
+	}
+	if record.Holder != e.param.Identity {
+		next.AcquireTime = next.RenewTime
+		next.Term++
+	}
+	if !e.publish(next, content) {
+		return false
+	}
+	e.setLeader(e.param.Identity)
+	return true
+}
+
+// release give up the leadership so that the followers don't need to wait for the expiry
+func (e *Elector) release() {
+	e.mux.Lock()
+	content := e.observedContent
+	e.mux.Unlock()
+	var record Record
+	if err := json.Unmarshal([]byte(content), &record); err != nil || record.Holder != e.param.Identity {
+		return
+	}
+	record.Holder = ""
+	record.RenewTime = time.Now().UnixNano() / int64(time.Millisecond)
+	if e.publish(record, content) {
+		e.setLeader("")
+	}
+}
+
+// publish write the record only if the lock config is still the previous content
+func (e *Elector) publish(record Record, previous string) bool {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return false
+	}
+	param := e.configParam()
+	param.Content = string(data)
+	param.CasMd5 = util.Md5(previous)
+	if len(param.CasMd5) == 0 {
+		param.CasMd5 = emptyContentMd5
+	}
+	published, err := e.client.PublishConfig(param)
+	if err != nil || !published {
+		logger.Debugf("[election] %s publish the lock record of %s failed, err:%v", e.param.Identity, e.param.DataId, err)
+		return false
+	}
+	e.mux.Lock()
+	e.observedContent = param.Content
+	e.observedTime = time.Now()
+	e.mux.Unlock()
+	return true
+}
+
+func (e *Elector) setLeader(identity string) {
+	e.mux.Lock()
+	changed := e.leader != identity
+	e.leader = identity
+	e.mux.Unlock()
+	if changed && e.param.OnNewLeader != nil {
+		e.param.OnNewLeader(identity)
+	}
+}
+
+func (e *Elector) configParam() vo.ConfigParam {
+	return vo.ConfigParam{DataId: e.param.DataId, Group: e.param.Group, NamespaceId: e.param.NamespaceId}
+}
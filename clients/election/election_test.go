@@ -0,0 +1,154 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package election
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// casConfigClient keeps the lock config in memory and publishes it with the cas semantics of server
+type casConfigClient struct {
+	config_client.IConfigClient
+	mux     sync.Mutex
+	content string
+	down    map[string]bool
+}
+
+func (c *casConfigClient) GetConfig(param vo.ConfigParam) (string, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.content, nil
+}
+
+func (c *casConfigClient) PublishConfig(param vo.ConfigParam) (bool, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	var record Record
+	_ = json.Unmarshal([]byte(param.Content), &record)
+	if c.down[record.Holder] {
+		return false, nil
+	}
+	if len(c.content) > 0 && util.Md5(c.content) != param.CasMd5 {
+		return false, nil
+	}
+	c.content = param.Content
+	return true, nil
+}
+
+func (c *casConfigClient) setDown(identity string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.down[identity] = true
+}
+
+func newTestElector(t *testing.T, client *casConfigClient, identity string, leading chan string) *Elector {
+	elector, err := NewElector(client, vo.ElectionParam{
+		DataId:        "leader.lock",
+		Identity:      identity,
+		TTL:           300 * time.Millisecond,
+		RenewInterval: 50 * time.Millisecond,
+		OnStartedLeading: func(ctx context.Context) {
+			leading <- identity
+		},
+	})
+	assert.Nil(t, err)
+	return elector
+}
+
+func TestElector_Campaign(t *testing.T) {
+	client := &casConfigClient{down: map[string]bool{}}
+	leading := make(chan string, 4)
+	a := newTestElector(t, client, "a", leading)
+	b := newTestElector(t, client, "b", leading)
+
+	ctxA, cancelA := context.WithCancel(context.Background())
+	doneA := make(chan error)
+	go func() { doneA <- a.Campaign(ctxA) }()
+	assert.Equal(t, "a", <-leading)
+	assert.True(t, a.IsLeader())
+
+	ctxB, cancelB := context.WithCancel(context.Background())
+	defer cancelB()
+	go func() { _ = b.Campaign(ctxB) }()
+	observer := newTestElector(t, client, "observer", leading)
+	leaders := observer.Observe(ctxB)
+	assert.Equal(t, "a", <-leaders)
+	time.Sleep(100 * time.Millisecond)
+	assert.False(t, b.IsLeader())
+	assert.Equal(t, "a", b.Leader())
+
+	// the leadership is released on exit, b takes it over without waiting for the expiry
+	cancelA()
+	assert.Equal(t, context.Canceled, <-doneA)
+	assert.False(t, a.IsLeader())
+	start := time.Now()
+	assert.Equal(t, "b", <-leading)
+	assert.True(t, time.Since(start) < 300*time.Millisecond)
+	for leader := range leaders {
+		if leader == "b" {
+			break
+		}
+	}
+
+	var record Record
+	content, _ := client.GetConfig(vo.ConfigParam{})
+	assert.Nil(t, json.Unmarshal([]byte(content), &record))
+	assert.Equal(t, uint64(2), record.Term)
+}
+
+func TestElector_Expiry(t *testing.T) {
+	client := &casConfigClient{down: map[string]bool{}}
+	leading := make(chan string, 4)
+	a := newTestElector(t, client, "a", leading)
+	stopped := make(chan struct{})
+	a.param.OnStoppedLeading = func() { close(stopped) }
+	b := newTestElector(t, client, "b", leading)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = a.Campaign(ctx) }()
+	assert.Equal(t, "a", <-leading)
+	go func() { _ = b.Campaign(ctx) }()
+
+	// a can not renew anymore, it steps down after ttl and b takes over once the record is expired
+	client.setDown("a")
+	<-stopped
+	assert.Equal(t, "b", <-leading)
+	assert.False(t, a.IsLeader())
+	assert.True(t, b.IsLeader())
+}
+
+func TestNewElector(t *testing.T) {
+	client := &casConfigClient{}
+	_, err := NewElector(client, vo.ElectionParam{})
+	assert.NotNil(t, err)
+	_, err = NewElector(client, vo.ElectionParam{DataId: "lock", TTL: time.Second, RenewInterval: time.Second})
+	assert.NotNil(t, err)
+	elector, err := NewElector(client, vo.ElectionParam{DataId: "lock"})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, elector.Identity())
+	assert.Equal(t, 5*time.Second, elector.param.RenewInterval)
+}
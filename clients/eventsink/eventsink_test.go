@@ -0,0 +1,121 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eventsink
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+type watchNamingClient struct {
+	naming_client.INamingClient
+	changes      chan model.InstanceChangeEvent
+	defaultGroup string
+}
+
+func (c *watchNamingClient) GroupOf(groupName string) string {
+	if len(groupName) > 0 {
+		return groupName
+	}
+	return c.defaultGroup
+}
+
+func (c *watchNamingClient) Watch(ctx context.Context, param vo.WatchParam) (<-chan model.InstanceChangeEvent, error) {
+	go func() {
+		<-ctx.Done()
+		close(c.changes)
+	}()
+	return c.changes, nil
+}
+
+type kafkaMessage struct {
+	topic string
+	key   string
+	value []byte
+}
+
+type fakeKafkaProducer chan kafkaMessage
+
+func (p fakeKafkaProducer) SendMessage(ctx context.Context, topic string, key, value []byte) error {
+	p <- kafkaMessage{topic: topic, key: string(key), value: value}
+	return nil
+}
+
+func TestForwarder(t *testing.T) {
+	client := &watchNamingClient{changes: make(chan model.InstanceChangeEvent), defaultGroup: "PROD_GROUP"}
+	producer := make(fakeKafkaProducer, 8)
+	forwarder, err := NewForwarder(client, NewKafkaSink(producer, "nacos-instances"), vo.EventForwardParam{
+		Services:     []vo.WatchParam{{ServiceName: "demo"}},
+		SkipSnapshot: true,
+	})
+	assert.Nil(t, err)
+	defer forwarder.Close()
+
+	a := model.Instance{Ip: "10.0.0.1", Port: 80, Healthy: true, Enable: true}
+	b := model.Instance{Ip: "10.0.0.2", Port: 80, Healthy: true, Enable: true}
+	client.changes <- model.InstanceChangeEvent{Instances: []model.Instance{a}, Added: []model.Instance{a}}
+	unhealthy := a
+	unhealthy.Healthy = false
+	client.changes <- model.InstanceChangeEvent{Instances: []model.Instance{unhealthy, b},
+		Added: []model.Instance{b}, Modified: []model.Instance{unhealthy}}
+	weighted := unhealthy
+	weighted.Weight = 2
+	client.changes <- model.InstanceChangeEvent{Instances: []model.Instance{weighted},
+		Modified: []model.Instance{weighted}, Removed: []model.Instance{b}}
+
+	var types []model.InstanceEventType
+	for i := 0; i < 4; i++ {
+		select {
+		case message := <-producer:
+			assert.Equal(t, "nacos-instances", message.topic)
+			assert.Equal(t, "PROD_GROUP@@demo", message.key)
+			var event model.InstanceEvent
+			assert.Nil(t, json.Unmarshal(message.value, &event))
+			types = append(types, event.Type)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the event is not sent")
+		}
+	}
+	assert.Equal(t, []model.InstanceEventType{model.InstanceUp, model.InstanceDown, model.InstanceModified, model.InstanceDown}, types)
+	assert.Equal(t, 0, len(producer))
+}
+
+func TestRocketMQSink(t *testing.T) {
+	var tag, key string
+	sink := NewRocketMQSink(rocketMQProducerFunc(func(ctx context.Context, topic, t, k string, body []byte) error {
+		tag, key = t, k
+		return nil
+	}), "nacos-instances")
+	err := sink.Send(context.Background(), model.InstanceEvent{Type: model.InstanceDown, ServiceName: "demo", GroupName: "group"})
+	assert.Nil(t, err)
+	assert.Equal(t, "down", tag)
+	assert.Equal(t, "group@@demo", key)
+}
+
+type rocketMQProducerFunc func(ctx context.Context, topic, tag, key string, body []byte) error
+
+func (f rocketMQProducerFunc) SendSync(ctx context.Context, topic, tag, key string, body []byte) error {
+	return f(ctx, topic, tag, key, body)
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eventsink
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// Forwarder watches the services and sends their instance events to the sink, the events of a service are sent
+// in order
+type Forwarder struct {
+	sink   EventSink
+	param  vo.EventForwardParam
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewForwarder watch the services of param and send their instance events to sink until Close
+func NewForwarder(client naming_client.INamingClient, sink EventSink, param vo.EventForwardParam) (*Forwarder, error) {
+	if client == nil || sink == nil {
+		return nil, errors.New("[eventsink.NewForwarder] client and sink can not be nil")
+	}
+	if len(param.Services) == 0 {
+		return nil, errors.New("[eventsink.NewForwarder] Services can not be empty")
+	}
+	f := &Forwarder{sink: sink, param: param}
+	f.ctx, f.cancel = context.WithCancel(context.Background())
+	for _, service := range param.Services {
+		service.GroupName = client.GroupOf(service.GroupName)
+		events, err := client.Watch(f.ctx, service)
+		if err != nil {
+			f.Close()
+			return nil, errors.Wrapf(err, "[eventsink.NewForwarder] watch service %s failed", service.ServiceName)
+		}
+		f.wg.Add(1)
+		go f.forward(service, events)
+	}
+	return f, nil
+}
+
+// Close stop watching the services, the events being sent are cancelled
+func (f *Forwarder) Close() {
+	f.cancel()
+	f.wg.Wait()
+}
+
+func (f *Forwarder) forward(service vo.WatchParam, changes <-chan model.InstanceChangeEvent) {
+	defer f.wg.Done()
+	// serving is the up or down state of the instances last seen
+	var serving map[string]bool
+	for change := range changes {
+		current := make(map[string]bool, len(change.Instances))
+		for _, instance := range change.Instances {
+			current[instanceKey(instance)] = isUp(instance)
+		}
+		snapshot := serving == nil
+		if !snapshot || !f.param.SkipSnapshot {
+			now := time.Now().UnixNano() / int64(time.Millisecond)
+			for _, instance := range change.Added {
+				f.send(service, instance, upOrDown(isUp(instance)), now)
+			}
+			for _, instance := range change.Modified {
+				eventType := upOrDown(isUp(instance))
+				if up, ok := serving[instanceKey(instance)]; ok && up == isUp(instance) {
+					eventType = model.InstanceModified
+				}
+				f.send(service, instance, eventType, now)
+			}
+			for _, instance := range change.Removed {
+				f.send(service, instance, model.InstanceDown, now)
+			}
+		}
+		serving = current
+	}
+}
+
+func (f *Forwarder) send(service vo.WatchParam, instance model.Instance, eventType model.InstanceEventType, timestamp int64) {
+	event := model.InstanceEvent{
+		Type:        eventType,
+		ServiceName: service.ServiceName,
+		GroupName:   service.GroupName,
+		Instance:    instance,
+		Timestamp:   timestamp,
+	}
+	if err := f.sink.Send(f.ctx, event); err != nil {
+		logger.Warnf("[eventsink] send %s event of instance %s:%d of service %s failed, err:%v", eventType,
+			instance.Ip, instance.Port, service.ServiceName, err)
+		if f.param.OnError != nil {
+			f.param.OnError(event, err)
+		}
+	}
+}
+
+func isUp(instance model.Instance) bool {
+	return instance.Healthy && instance.Enable
+}
+
+func upOrDown(up bool) model.InstanceEventType {
+	if up {
+		return model.InstanceUp
+	}
+	return model.InstanceDown
+}
+
+func instanceKey(instance model.Instance) string {
+	return instance.ClusterName + "#" + instance.Ip + ":" + strconv.FormatUint(instance.Port, 10)
+}
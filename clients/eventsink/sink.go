@@ -0,0 +1,79 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package eventsink forwards the instance events of subscriptions to message queues, so that the downstream
+// consumers such as CMDB sync and alerting are decoupled from the in-process callbacks
+package eventsink
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
+// EventSink is where the instance events are sent to
+type EventSink interface {
+	Send(ctx context.Context, event model.InstanceEvent) error
+}
+
+// SinkFunc adapts the function to EventSink
+type SinkFunc func(ctx context.Context, event model.InstanceEvent) error
+
+// Send call f(ctx, event)
+func (f SinkFunc) Send(ctx context.Context, event model.InstanceEvent) error {
+	return f(ctx, event)
+}
+
+// KafkaProducer is the producer the kafka sink sends messages with, it's implemented by wrapping the producer of
+// the kafka client in use, such as the SyncProducer of sarama
+type KafkaProducer interface {
+	SendMessage(ctx context.Context, topic string, key, value []byte) error
+}
+
+// RocketMQProducer is the producer the rocketmq sink sends messages with, it's implemented by wrapping the producer
+// of the rocketmq client in use, such as the Producer.SendSync of rocketmq-client-go
+type RocketMQProducer interface {
+	SendSync(ctx context.Context, topic, tag, key string, body []byte) error
+}
+
+// NewKafkaSink create the sink sending the events to topic in json, the messages are keyed by group and service name,
+// so that the events of a service are kept in order on one partition
+func NewKafkaSink(producer KafkaProducer, topic string) EventSink {
+	return SinkFunc(func(ctx context.Context, event model.InstanceEvent) error {
+		value, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		return producer.SendMessage(ctx, topic, []byte(eventKey(event)), value)
+	})
+}
+
+// NewRocketMQSink create the sink sending the events to topic in json, the messages are tagged by the event type
+// for the consumers to subscribe up or down events only, and keyed by group and service name
+func NewRocketMQSink(producer RocketMQProducer, topic string) EventSink {
+	return SinkFunc(func(ctx context.Context, event model.InstanceEvent) error {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		return producer.SendSync(ctx, topic, string(event.Type), eventKey(event), body)
+	})
+}
+
+func eventKey(event model.InstanceEvent) string {
+	return event.GroupName + "@@" + event.ServiceName
+}
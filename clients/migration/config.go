@@ -0,0 +1,452 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migration
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// ConfigClient reads the configs from the old cluster until they are cut over, and publishes and deletes them in
+// both clusters. The searches over a namespace are served by the old cluster, which has all the configs until the
+// migration is done
+type ConfigClient struct {
+	config_client.IConfigClient
+	newClient config_client.IConfigClient
+	param     vo.MigrationParam
+	cutover   *cutover
+	mutex     sync.Mutex
+	listens   map[string][]configListen
+	tails     map[*configTail]struct{}
+}
+
+// configListen is a listening moved on cutover
+type configListen struct {
+	param vo.ConfigParam
+	ctx   context.Context // the ctx of ListenConfigWithContext
+	path  string          // the JSONPath of ListenConfigPath
+}
+
+func (l configListen) listen(client config_client.IConfigClient) error {
+	switch {
+	case l.ctx != nil:
+		return client.ListenConfigWithContext(l.ctx, l.param)
+	case len(l.path) > 0:
+		return client.ListenConfigPath(l.param, l.path)
+	default:
+		return client.ListenConfig(l.param)
+	}
+}
+
+// NewConfigClient create the client migrating from oldClient to newClient
+func NewConfigClient(oldClient, newClient config_client.IConfigClient, param vo.MigrationParam) (*ConfigClient, error) {
+	if oldClient == nil || newClient == nil {
+		return nil, errors.New("[migration.NewConfigClient] old and new client can not be nil")
+	}
+	return &ConfigClient{
+		IConfigClient: oldClient,
+		newClient:     newClient,
+		param:         param,
+		cutover:       newCutover(param.CutoverKeys),
+		listens:       make(map[string][]configListen),
+		tails:         make(map[*configTail]struct{}),
+	}, nil
+}
+
+// key returns the cutover key of the config, the empty group is resolved by the client of old cluster
+func (c *ConfigClient) key(group, dataId string) string {
+	return Key(c.IConfigClient.GroupOf(dataId, group), dataId)
+}
+
+func (c *ConfigClient) route(param vo.ConfigParam) config_client.IConfigClient {
+	if c.cutover.isCut(c.key(param.Group, param.DataId)) {
+		return c.newClient
+	}
+	return c.IConfigClient
+}
+
+func (c *ConfigClient) dualWrite(param vo.ConfigParam, write func(config_client.IConfigClient) (bool, error)) (bool, error) {
+	key := c.key(param.Group, param.DataId)
+	return dualWrite(c.param, key, c.cutover.isCut(key), func() (bool, error) {
+		return write(c.IConfigClient)
+	}, func() (bool, error) {
+		return write(c.newClient)
+	})
+}
+
+// GetConfig get the config from the cluster it's read from
+func (c *ConfigClient) GetConfig(param vo.ConfigParam) (string, error) {
+	return c.route(param).GetConfig(param)
+}
+
+// WaitForConfig wait for the config on the cluster it's read from
+func (c *ConfigClient) WaitForConfig(ctx context.Context, param vo.ConfigParam) (string, error) {
+	return c.route(param).WaitForConfig(ctx, param)
+}
+
+// GetConfigStream get the config from the cluster it's read from as a stream
+func (c *ConfigClient) GetConfigStream(param vo.ConfigParam) (io.ReadCloser, error) {
+	return c.route(param).GetConfigStream(param)
+}
+
+// GetBinaryConfig get the binary config from the cluster it's read from
+func (c *ConfigClient) GetBinaryConfig(param vo.ConfigParam) ([]byte, error) {
+	return c.route(param).GetBinaryConfig(param)
+}
+
+// GetProperties get the properties from the cluster the configs are read from, the configs cut over and the ones
+// not are not merged across clusters
+func (c *ConfigClient) GetProperties(param vo.PropertiesParam) (map[string]string, error) {
+	if len(param.Configs) == 0 {
+		return c.IConfigClient.GetProperties(param)
+	}
+	client := c.route(param.Configs[0])
+	for _, config := range param.Configs[1:] {
+		if c.route(config) != client {
+			return nil, errors.Wrap(ErrUnsupported, "[migration.GetProperties] the configs are read from different clusters")
+		}
+	}
+	return client.GetProperties(param)
+}
+
+// PublishConfig publish the config to both clusters
+func (c *ConfigClient) PublishConfig(param vo.ConfigParam) (bool, error) {
+	return c.dualWrite(param, func(client config_client.IConfigClient) (bool, error) {
+		return client.PublishConfig(param)
+	})
+}
+
+// PublishConfigWithResult publish the config to both clusters, the result is the one of the cluster it's read from
+func (c *ConfigClient) PublishConfigWithResult(param vo.ConfigParam) (model.PublishResult, error) {
+	key := c.key(param.Group, param.DataId)
+	cut := c.cutover.isCut(key)
+	var result model.PublishResult
+	publish := func(client config_client.IConfigClient, primary bool) func() (bool, error) {
+		return func() (bool, error) {
+			r, err := client.PublishConfigWithResult(param)
+			if primary {
+				result = r
+			}
+			return r.Published, err
+		}
+	}
+	_, err := dualWrite(c.param, key, cut, publish(c.IConfigClient, !cut), publish(c.newClient, cut))
+	return result, err
+}
+
+// PublishConfigAt is unsupported, the publication scheduled in one cluster can't be kept in step with the other
+func (c *ConfigClient) PublishConfigAt(param vo.ConfigParam, effectiveTime time.Time) (*config_client.ScheduledPublish, error) {
+	return nil, errors.Wrap(ErrUnsupported, "[migration.PublishConfigAt] scheduled publication")
+}
+
+// Promote promote the staged content in both clusters
+func (c *ConfigClient) Promote(param vo.ConfigParam) (bool, error) {
+	return c.dualWrite(param, func(client config_client.IConfigClient) (bool, error) {
+		return client.Promote(param)
+	})
+}
+
+// PublishConfigStream publish the content read from r to both clusters, the content is buffered in memory to be
+// written twice
+func (c *ConfigClient) PublishConfigStream(param vo.ConfigParam, r io.Reader) (bool, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return false, errors.Wrapf(err, "[migration.PublishConfigStream] read content of %s failed", param.DataId)
+	}
+	return c.dualWrite(param, func(client config_client.IConfigClient) (bool, error) {
+		return client.PublishConfigStream(param, bytes.NewReader(content))
+	})
+}
+
+// PublishBinaryConfig publish the binary config to both clusters
+func (c *ConfigClient) PublishBinaryConfig(param vo.ConfigParam, data []byte) (bool, error) {
+	return c.dualWrite(param, func(client config_client.IConfigClient) (bool, error) {
+		return client.PublishBinaryConfig(param, data)
+	})
+}
+
+// DeleteConfig delete the config in both clusters
+func (c *ConfigClient) DeleteConfig(param vo.ConfigParam) (bool, error) {
+	return c.dualWrite(param, func(client config_client.IConfigClient) (bool, error) {
+		return client.DeleteConfig(param)
+	})
+}
+
+func (c *ConfigClient) listen(l configListen) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := l.listen(c.route(l.param)); err != nil {
+		return err
+	}
+	key := util.GetConfigCacheKey(l.param.DataId, l.param.Group, l.param.NamespaceId)
+	c.listens[key] = append(c.listens[key], l)
+	return nil
+}
+
+// ListenConfig listen the config on the cluster it's read from, the listening is moved on cutover
+func (c *ConfigClient) ListenConfig(param vo.ConfigParam) error {
+	return c.listen(configListen{param: param})
+}
+
+// ListenConfigWithContext listen the config on the cluster it's read from until ctx is done, the listening is
+// moved on cutover
+func (c *ConfigClient) ListenConfigWithContext(ctx context.Context, param vo.ConfigParam) error {
+	return c.listen(configListen{param: param, ctx: ctx})
+}
+
+// ListenConfigPath listen the subtree of config on the cluster it's read from, the listening is moved on cutover
+func (c *ConfigClient) ListenConfigPath(param vo.ConfigParam, path string) error {
+	return c.listen(configListen{param: param, path: path})
+}
+
+// CancelListenConfig cancel listening the config
+func (c *ConfigClient) CancelListenConfig(param vo.ConfigParam) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	// the listens bound to a context are kept until their context is done, like the config client does
+	key := util.GetConfigCacheKey(param.DataId, param.Group, param.NamespaceId)
+	var kept []configListen
+	for _, l := range c.listens[key] {
+		if l.ctx != nil {
+			kept = append(kept, l)
+		}
+	}
+	if len(kept) > 0 {
+		c.listens[key] = kept
+	} else {
+		delete(c.listens, key)
+	}
+	return c.route(param).CancelListenConfig(param)
+}
+
+// Tail stream the changes of config on the cluster it's read from, the stream is moved on cutover
+func (c *ConfigClient) Tail(ctx context.Context, param vo.ConfigParam) (<-chan model.ConfigChangeEvent, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	t := newConfigTail(ctx, param)
+	if err := t.attach(c.route(param)); err != nil {
+		return nil, err
+	}
+	c.tails[t] = struct{}{}
+	go func() {
+		<-ctx.Done()
+		c.mutex.Lock()
+		delete(c.tails, t)
+		c.mutex.Unlock()
+		t.close()
+	}()
+	return t.events, nil
+}
+
+// RestoreSubscriptions restore the subscriptions persisted by both clusters, the ones restored in the cluster
+// they aren't read from are moved
+func (c *ConfigClient) RestoreSubscriptions(param vo.ConfigParam) ([]vo.ConfigParam, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	restored := make(map[string]vo.ConfigParam)
+	var misplaced []vo.ConfigParam
+	for _, client := range []config_client.IConfigClient{c.IConfigClient, c.newClient} {
+		params, err := client.RestoreSubscriptions(param)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range params {
+			if c.route(p) != client {
+				_ = client.CancelListenConfig(p)
+				misplaced = append(misplaced, p)
+				continue
+			}
+			restored[util.GetConfigCacheKey(p.DataId, p.Group, p.NamespaceId)] = p
+		}
+	}
+	for _, p := range misplaced {
+		key := util.GetConfigCacheKey(p.DataId, p.Group, p.NamespaceId)
+		if _, ok := restored[key]; ok {
+			continue
+		}
+		if err := c.route(p).ListenConfig(p); err != nil {
+			return nil, errors.Wrapf(err, "[migration.RestoreSubscriptions] listen config %s failed", key)
+		}
+		restored[key] = p
+	}
+	params := make([]vo.ConfigParam, 0, len(restored))
+	for key, p := range restored {
+		c.listens[key] = append(c.listens[key], configListen{param: p})
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+// SearchConfig search the configs in the old cluster
+func (c *ConfigClient) SearchConfig(param vo.SearchConfigParam) (*model.ConfigPage, error) {
+	return c.IConfigClient.SearchConfig(param)
+}
+
+// SearchConfigIterator iterate over the configs in the old cluster
+func (c *ConfigClient) SearchConfigIterator(param vo.SearchConfigParam) *config_client.ConfigIterator {
+	return c.IConfigClient.SearchConfigIterator(param)
+}
+
+// ListConfigKeys list the config keys in the old cluster
+func (c *ConfigClient) ListConfigKeys(param vo.ListConfigKeysParam) (*model.ConfigKeyPage, error) {
+	return c.IConfigClient.ListConfigKeys(param)
+}
+
+// ListConfigHistory list the historical snapshots kept by the client of the cluster the config is read from
+func (c *ConfigClient) ListConfigHistory(param vo.ConfigParam) ([]model.ConfigSnapshot, error) {
+	return c.route(param).ListConfigHistory(param)
+}
+
+// GetConfigHistory read the historical snapshot kept by the client of the cluster the config is read from
+func (c *ConfigClient) GetConfigHistory(param vo.ConfigParam, at time.Time) (string, error) {
+	return c.route(param).GetConfigHistory(param, at)
+}
+
+// GetListenStatus get the listen status of both clusters
+func (c *ConfigClient) GetListenStatus() []model.ConfigListenStatus {
+	return append(c.IConfigClient.GetListenStatus(), c.newClient.GetListenStatus()...)
+}
+
+// GetNamespaceChecksum compute the checksum of the configs on server, the md5 of each config is the one in the
+// cluster it's read from
+func (c *ConfigClient) GetNamespaceChecksum(namespace, group string) (*model.ConfigManifest, error) {
+	oldManifest, err := c.IConfigClient.GetNamespaceChecksum(namespace, group)
+	if err != nil {
+		return nil, err
+	}
+	newManifest, err := c.newClient.GetNamespaceChecksum(namespace, group)
+	if err != nil {
+		return nil, err
+	}
+	return c.mergeManifest(oldManifest, newManifest), nil
+}
+
+// LocalConfigManifest build the manifest of the configs listened in both clusters
+func (c *ConfigClient) LocalConfigManifest(namespace, group string) *model.ConfigManifest {
+	return c.mergeManifest(c.IConfigClient.LocalConfigManifest(namespace, group), c.newClient.LocalConfigManifest(namespace, group))
+}
+
+// mergeManifest take the md5 of each config from the manifest of the cluster it's read from
+func (c *ConfigClient) mergeManifest(oldManifest, newManifest *model.ConfigManifest) *model.ConfigManifest {
+	md5s := make(map[string]string, len(oldManifest.Md5s))
+	for _, manifest := range []*model.ConfigManifest{oldManifest, newManifest} {
+		for dataId := range manifest.Md5s {
+			from := oldManifest
+			if c.cutover.isCut(c.key(oldManifest.Group, dataId)) {
+				from = newManifest
+			}
+			if md5, ok := from.Md5s[dataId]; ok {
+				md5s[dataId] = md5
+			}
+		}
+	}
+	return config_client.NewConfigManifest(oldManifest.Namespace, oldManifest.Group, md5s)
+}
+
+// InstanceId return the instance id of the client of old cluster
+func (c *ConfigClient) InstanceId() string {
+	return c.IConfigClient.InstanceId()
+}
+
+// GroupOf return the group resolved by the client of old cluster
+func (c *ConfigClient) GroupOf(dataId, group string) string {
+	return c.IConfigClient.GroupOf(dataId, group)
+}
+
+// Cutover set whether the config is read from the new cluster, the listening and tails of it are moved to the cluster
+func (c *ConfigClient) Cutover(group, dataId string, cut bool) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	key := c.key(group, dataId)
+	if !c.cutover.set(key, cut) {
+		return nil
+	}
+	from, to := c.IConfigClient, c.newClient
+	if !cut {
+		from, to = to, from
+	}
+	for cacheKey, listens := range c.listens {
+		if c.key(listens[0].param.Group, listens[0].param.DataId) != key {
+			continue
+		}
+		var kept []configListen
+		for _, l := range listens {
+			if l.ctx != nil && l.ctx.Err() != nil {
+				continue
+			}
+			if err := l.listen(to); err != nil {
+				return errors.Wrapf(err, "[migration.Cutover] listen config %s failed", key)
+			}
+			kept = append(kept, l)
+		}
+		_ = from.CancelListenConfig(listens[0].param)
+		if len(kept) == 0 {
+			delete(c.listens, cacheKey)
+			continue
+		}
+		c.listens[cacheKey] = kept
+	}
+	for t := range c.tails {
+		if c.key(t.param.Group, t.param.DataId) != key {
+			continue
+		}
+		if err := t.attach(to); err != nil {
+			return errors.Wrapf(err, "[migration.Cutover] tail config %s failed", key)
+		}
+	}
+	return nil
+}
+
+// Verify compare the content of configs in both clusters
+func (c *ConfigClient) Verify(params []vo.ConfigParam) *Report {
+	report := &Report{}
+	for _, param := range params {
+		key := c.key(param.Group, param.DataId)
+		report.Checked++
+		oldContent, err := c.IConfigClient.GetConfig(param)
+		if err != nil {
+			report.Inconsistent = append(report.Inconsistent, Inconsistency{Key: key, Reason: "get from old cluster failed: " + err.Error()})
+			continue
+		}
+		newContent, err := c.newClient.GetConfig(param)
+		if err != nil {
+			report.Inconsistent = append(report.Inconsistent, Inconsistency{Key: key, Reason: "get from new cluster failed: " + err.Error()})
+			continue
+		}
+		if oldContent != newContent {
+			report.Inconsistent = append(report.Inconsistent, Inconsistency{Key: key, Reason: "content differs"})
+		}
+	}
+	return report
+}
+
+// CloseClient close the clients of both clusters
+func (c *ConfigClient) CloseClient() {
+	c.IConfigClient.CloseClient()
+	c.newClient.CloseClient()
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package migration supports the live migration between two clusters without application changes. The publishes
+// and registrations are written to both the old and the new cluster, while the reads go to the old cluster until
+// the key is cut over to the new one. The consistency between clusters is checked by Verify before cutting over
+package migration
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+const (
+	// ClusterOld is the cluster migrated from
+	ClusterOld = "old"
+	// ClusterNew is the cluster migrated to
+	ClusterNew = "new"
+)
+
+var errRefused = errors.New("server refused")
+
+// ErrUnsupported is the cause of the error returned by the methods which can't be kept in step across clusters
+var ErrUnsupported = errors.New("unsupported during migration")
+
+// Inconsistency is a key of which the clusters differ
+type Inconsistency struct {
+	Key    string
+	Reason string
+}
+
+// Report is the outcome of Verify
+type Report struct {
+	Checked      int
+	Inconsistent []Inconsistency
+}
+
+// Consistent returns whether all the checked keys are the same in both clusters
+func (r *Report) Consistent() bool {
+	return len(r.Inconsistent) == 0
+}
+
+// Key returns the key of the cutover flag of a config or a service, the group is DEFAULT_GROUP when empty, the
+// clients resolve the empty group by DefaultGroup or DefaultServiceGroup of ClientConfig before building the key, so
+// the CutoverKeys of those configs and services should be built with the resolved group
+func Key(group, name string) string {
+	if len(group) == 0 {
+		group = constant.DEFAULT_GROUP
+	}
+	return util.GetGroupName(name, group)
+}
+
+// cutover keeps the keys read from the new cluster
+type cutover struct {
+	mutex sync.RWMutex
+	keys  map[string]bool
+}
+
+func newCutover(keys []string) *cutover {
+	c := &cutover{keys: make(map[string]bool, len(keys))}
+	for _, key := range keys {
+		c.keys[key] = true
+	}
+	return c
+}
+
+// set returns whether the flag is changed
+func (c *cutover) set(key string, cut bool) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.keys[key] == cut {
+		return false
+	}
+	if cut {
+		c.keys[key] = true
+	} else {
+		delete(c.keys, key)
+	}
+	return true
+}
+
+func (c *cutover) isCut(key string) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.keys[key]
+}
+
+// dualWrite write to the primary cluster which the key is read from, then write to the other one when it succeeds.
+// The failure of secondary is reported by OnWriteError instead of failing the write
+func dualWrite(param vo.MigrationParam, key string, cut bool, writeOld, writeNew func() (bool, error)) (bool, error) {
+	primary, secondary := writeOld, writeNew
+	secondaryCluster := ClusterNew
+	if cut {
+		primary, secondary = writeNew, writeOld
+		secondaryCluster = ClusterOld
+	}
+	ok, err := primary()
+	if err != nil || !ok {
+		return ok, err
+	}
+	if done, err := secondary(); err != nil || !done {
+		if err == nil {
+			err = errRefused
+		}
+		logger.Warnf("[migration] write %s to %s cluster failed, err:%v", key, secondaryCluster, err)
+		if param.OnWriteError != nil {
+			param.OnWriteError(secondaryCluster, key, err)
+		}
+	}
+	return ok, nil
+}
@@ -0,0 +1,283 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migration
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+type mapConfigClient struct {
+	config_client.IConfigClient
+	configs   map[string]string
+	listening map[string]bool
+	failWrite bool
+	// defaultGroup is the group of the configs published without group
+	defaultGroup string
+}
+
+func newMapConfigClient() *mapConfigClient {
+	return &mapConfigClient{configs: map[string]string{}, listening: map[string]bool{}}
+}
+
+func (c *mapConfigClient) GroupOf(dataId, group string) string {
+	if len(group) > 0 {
+		return group
+	}
+	if len(c.defaultGroup) > 0 {
+		return c.defaultGroup
+	}
+	return "DEFAULT_GROUP"
+}
+
+func (c *mapConfigClient) GetConfig(param vo.ConfigParam) (string, error) {
+	return c.configs[param.DataId], nil
+}
+
+func (c *mapConfigClient) PublishConfig(param vo.ConfigParam) (bool, error) {
+	if c.failWrite {
+		return false, errors.New("unavailable")
+	}
+	c.configs[param.DataId] = param.Content
+	return true, nil
+}
+
+func (c *mapConfigClient) ListenConfig(param vo.ConfigParam) error {
+	c.listening[param.DataId] = true
+	return nil
+}
+
+func (c *mapConfigClient) CancelListenConfig(param vo.ConfigParam) error {
+	delete(c.listening, param.DataId)
+	return nil
+}
+
+func (c *mapConfigClient) Tail(ctx context.Context, param vo.ConfigParam) (<-chan model.ConfigChangeEvent, error) {
+	ch := make(chan model.ConfigChangeEvent, 1)
+	ch <- model.ConfigChangeEvent{Type: model.ConfigEventChange, DataId: param.DataId, Content: c.configs[param.DataId]}
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func TestConfigClient_DualWriteAndCutover(t *testing.T) {
+	oldClient, newClient := newMapConfigClient(), newMapConfigClient()
+	var failed []string
+	client, err := NewConfigClient(oldClient, newClient, vo.MigrationParam{
+		OnWriteError: func(cluster string, key string, err error) {
+			failed = append(failed, cluster+":"+key)
+		},
+	})
+	assert.Nil(t, err)
+
+	ok, err := client.PublishConfig(vo.ConfigParam{DataId: "a", Content: "1"})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", oldClient.configs["a"])
+	assert.Equal(t, "1", newClient.configs["a"])
+	assert.True(t, client.Verify([]vo.ConfigParam{{DataId: "a"}}).Consistent())
+
+	// the failure of new cluster doesn't fail the publish before cutover
+	newClient.failWrite = true
+	ok, err = client.PublishConfig(vo.ConfigParam{DataId: "a", Content: "2"})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"new:DEFAULT_GROUP@@a"}, failed)
+	report := client.Verify([]vo.ConfigParam{{DataId: "a"}})
+	assert.Equal(t, []Inconsistency{{Key: "DEFAULT_GROUP@@a", Reason: "content differs"}}, report.Inconsistent)
+
+	assert.Nil(t, client.ListenConfig(vo.ConfigParam{DataId: "a"}))
+	assert.True(t, oldClient.listening["a"])
+	assert.Nil(t, client.Cutover("", "a", true))
+	assert.False(t, oldClient.listening["a"])
+	assert.True(t, newClient.listening["a"])
+	content, _ := client.GetConfig(vo.ConfigParam{DataId: "a"})
+	assert.Equal(t, "1", content)
+
+	// the new cluster is primary after cutover
+	ok, err = client.PublishConfig(vo.ConfigParam{DataId: "a", Content: "3"})
+	assert.False(t, ok)
+	assert.NotNil(t, err)
+	assert.Equal(t, "2", oldClient.configs["a"])
+}
+
+type mapNamingClient struct {
+	naming_client.INamingClient
+	instances  map[string][]model.Instance
+	subscribed map[string]bool
+	// defaultGroup is the group of the services registered without group
+	defaultGroup string
+}
+
+func newMapNamingClient() *mapNamingClient {
+	return &mapNamingClient{instances: map[string][]model.Instance{}, subscribed: map[string]bool{}}
+}
+
+func (c *mapNamingClient) GroupOf(groupName string) string {
+	if len(groupName) > 0 {
+		return groupName
+	}
+	if len(c.defaultGroup) > 0 {
+		return c.defaultGroup
+	}
+	return "DEFAULT_GROUP"
+}
+
+func (c *mapNamingClient) RegisterInstance(param vo.RegisterInstanceParam) (bool, error) {
+	c.instances[param.ServiceName] = append(c.instances[param.ServiceName],
+		model.Instance{Ip: param.Ip, Port: param.Port, Healthy: param.Healthy, Enable: param.Enable})
+	return true, nil
+}
+
+func (c *mapNamingClient) GetService(param vo.GetServiceParam) (model.Service, error) {
+	return model.Service{Name: param.ServiceName, Hosts: c.instances[param.ServiceName]}, nil
+}
+
+func (c *mapNamingClient) Subscribe(param *vo.SubscribeParam) error {
+	c.subscribed[param.ServiceName] = true
+	return nil
+}
+
+func (c *mapNamingClient) Unsubscribe(param *vo.SubscribeParam) error {
+	delete(c.subscribed, param.ServiceName)
+	return nil
+}
+
+func TestNamingClient_DualWriteAndCutover(t *testing.T) {
+	oldClient, newClient := newMapNamingClient(), newMapNamingClient()
+	client, err := NewNamingClient(oldClient, newClient, vo.MigrationParam{CutoverKeys: []string{Key("", "b")}})
+	assert.Nil(t, err)
+
+	ok, err := client.RegisterInstance(vo.RegisterInstanceParam{ServiceName: "a", Ip: "10.0.0.1", Port: 80, Healthy: true, Enable: true})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	assert.Len(t, newClient.instances["a"], 1)
+	assert.True(t, client.Verify([]vo.GetServiceParam{{ServiceName: "a"}}).Consistent())
+
+	oldClient.instances["a"] = append(oldClient.instances["a"], model.Instance{Ip: "10.0.0.2", Port: 80})
+	newClient.instances["a"][0].Healthy = false
+	report := client.Verify([]vo.GetServiceParam{{ServiceName: "a"}})
+	assert.Equal(t, 1, report.Checked)
+	assert.Equal(t, "missing in new cluster: 10.0.0.2:80; state differs: 10.0.0.1:80", report.Inconsistent[0].Reason)
+
+	// b is cut over by param
+	assert.Nil(t, client.Subscribe(&vo.SubscribeParam{ServiceName: "b"}))
+	assert.True(t, newClient.subscribed["b"])
+	param := &vo.SubscribeParam{ServiceName: "a"}
+	assert.Nil(t, client.Subscribe(param))
+	assert.True(t, oldClient.subscribed["a"])
+	assert.Nil(t, client.Cutover("DEFAULT_GROUP", "a", true))
+	assert.False(t, oldClient.subscribed["a"])
+	assert.True(t, newClient.subscribed["a"])
+	assert.Nil(t, client.Unsubscribe(param))
+	assert.False(t, newClient.subscribed["a"])
+}
+
+func TestClients_CutoverKeyResolvesDefaultGroup(t *testing.T) {
+	oldNaming, newNaming := newMapNamingClient(), newMapNamingClient()
+	oldNaming.defaultGroup = "PROD_GROUP"
+	namingClient, err := NewNamingClient(oldNaming, newNaming, vo.MigrationParam{CutoverKeys: []string{Key("PROD_GROUP", "b")}})
+	assert.Nil(t, err)
+	assert.Nil(t, namingClient.Subscribe(&vo.SubscribeParam{ServiceName: "b"}))
+	assert.True(t, newNaming.subscribed["b"])
+	assert.False(t, oldNaming.subscribed["b"])
+
+	oldConfig, newConfig := newMapConfigClient(), newMapConfigClient()
+	oldConfig.defaultGroup = "PROD_GROUP"
+	var failed []string
+	configClient, err := NewConfigClient(oldConfig, newConfig, vo.MigrationParam{
+		OnWriteError: func(cluster string, key string, err error) {
+			failed = append(failed, cluster+":"+key)
+		},
+	})
+	assert.Nil(t, err)
+	newConfig.failWrite = true
+	ok, err := configClient.PublishConfig(vo.ConfigParam{DataId: "a", Content: "1"})
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"new:PROD_GROUP@@a"}, failed)
+	newConfig.configs["a"] = "2"
+	assert.Nil(t, configClient.Cutover("PROD_GROUP", "a", true))
+	content, _ := configClient.GetConfig(vo.ConfigParam{DataId: "a"})
+	assert.Equal(t, "2", content)
+}
+
+// assertOverridden fails for the methods of iface promoted from the embedded client of old cluster,
+// they would skip both the dual-write and the cutover
+func assertOverridden(t *testing.T, client interface{}, iface reflect.Type) {
+	clientType := reflect.TypeOf(client)
+	for i := 0; i < iface.NumMethod(); i++ {
+		name := iface.Method(i).Name
+		method, ok := clientType.MethodByName(name)
+		if !assert.True(t, ok, name) {
+			continue
+		}
+		pc := method.Func.Pointer()
+		file, _ := runtime.FuncForPC(pc).FileLine(pc)
+		assert.NotEqual(t, "<autogenerated>", file, "%s is not overridden", name)
+	}
+}
+
+func TestClients_OverrideAllMethods(t *testing.T) {
+	assertOverridden(t, &ConfigClient{}, reflect.TypeOf((*config_client.IConfigClient)(nil)).Elem())
+	assertOverridden(t, &NamingClient{}, reflect.TypeOf((*naming_client.INamingClient)(nil)).Elem())
+}
+
+func TestClients_Unsupported(t *testing.T) {
+	configClient, _ := NewConfigClient(newMapConfigClient(), newMapConfigClient(), vo.MigrationParam{})
+	_, err := configClient.PublishConfigAt(vo.ConfigParam{DataId: "a"}, time.Now())
+	assert.True(t, errors.Is(err, ErrUnsupported))
+	assert.Nil(t, configClient.Cutover("", "b", true))
+	_, err = configClient.GetProperties(vo.PropertiesParam{Configs: []vo.ConfigParam{{DataId: "a"}, {DataId: "b"}}})
+	assert.True(t, errors.Is(err, ErrUnsupported))
+
+	namingClient, _ := NewNamingClient(newMapNamingClient(), newMapNamingClient(), vo.MigrationParam{})
+	_, err = namingClient.NewMutex(vo.MutexParam{})
+	assert.True(t, errors.Is(err, ErrUnsupported))
+	_, err = namingClient.RegisterInstanceWithTTL(vo.RegisterInstanceWithTTLParam{})
+	assert.True(t, errors.Is(err, ErrUnsupported))
+}
+
+func TestConfigClient_TailMovedOnCutover(t *testing.T) {
+	oldClient, newClient := newMapConfigClient(), newMapConfigClient()
+	oldClient.configs["a"] = "1"
+	newClient.configs["a"] = "2"
+	client, _ := NewConfigClient(oldClient, newClient, vo.MigrationParam{})
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := client.Tail(ctx, vo.ConfigParam{DataId: "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, "1", (<-events).Content)
+
+	assert.Nil(t, client.Cutover("", "a", true))
+	assert.Equal(t, "2", (<-events).Content)
+	cancel()
+	for range events {
+	}
+}
@@ -0,0 +1,362 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migration
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client/routing"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// NamingClient reads the services from the old cluster until they are cut over, and registers, updates and
+// deregisters the instances in both clusters. The listings over a namespace are served by the old cluster, which
+// has all the services until the migration is done
+type NamingClient struct {
+	naming_client.INamingClient
+	newClient  naming_client.INamingClient
+	param      vo.MigrationParam
+	cutover    *cutover
+	mutex      sync.Mutex
+	subscribes map[*vo.SubscribeParam]struct{}
+	watches    map[*serviceWatch]struct{}
+}
+
+// NewNamingClient create the client migrating from oldClient to newClient
+func NewNamingClient(oldClient, newClient naming_client.INamingClient, param vo.MigrationParam) (*NamingClient, error) {
+	if oldClient == nil || newClient == nil {
+		return nil, errors.New("[migration.NewNamingClient] old and new client can not be nil")
+	}
+	return &NamingClient{
+		INamingClient: oldClient,
+		newClient:     newClient,
+		param:         param,
+		cutover:       newCutover(param.CutoverKeys),
+		subscribes:    make(map[*vo.SubscribeParam]struct{}),
+		watches:       make(map[*serviceWatch]struct{}),
+	}, nil
+}
+
+// key returns the cutover key of the service, the empty group is resolved by the client of old cluster
+func (c *NamingClient) key(group, serviceName string) string {
+	return Key(c.INamingClient.GroupOf(group), serviceName)
+}
+
+func (c *NamingClient) route(group, serviceName string) naming_client.INamingClient {
+	if c.cutover.isCut(c.key(group, serviceName)) {
+		return c.newClient
+	}
+	return c.INamingClient
+}
+
+func (c *NamingClient) dualWrite(group, serviceName string, write func(naming_client.INamingClient) (bool, error)) (bool, error) {
+	key := c.key(group, serviceName)
+	return dualWrite(c.param, key, c.cutover.isCut(key), func() (bool, error) {
+		return write(c.INamingClient)
+	}, func() (bool, error) {
+		return write(c.newClient)
+	})
+}
+
+// RegisterInstance register the instance in both clusters
+func (c *NamingClient) RegisterInstance(param vo.RegisterInstanceParam) (bool, error) {
+	return c.dualWrite(param.GroupName, param.ServiceName, func(client naming_client.INamingClient) (bool, error) {
+		return client.RegisterInstance(param)
+	})
+}
+
+// RegisterInstanceWithTTL is unsupported, the lease can't be kept in both clusters by one handle
+func (c *NamingClient) RegisterInstanceWithTTL(param vo.RegisterInstanceWithTTLParam) (*naming_client.Lease, error) {
+	return nil, errors.Wrap(ErrUnsupported, "[migration.RegisterInstanceWithTTL] lease")
+}
+
+// NewMutex is unsupported, the holders in two clusters would be different
+func (c *NamingClient) NewMutex(param vo.MutexParam) (*naming_client.Mutex, error) {
+	return nil, errors.Wrap(ErrUnsupported, "[migration.NewMutex] mutex")
+}
+
+// BatchRegisterInstance register the instances in both clusters
+func (c *NamingClient) BatchRegisterInstance(param vo.BatchRegisterInstanceParam) (bool, error) {
+	return c.dualWrite(param.GroupName, param.ServiceName, func(client naming_client.INamingClient) (bool, error) {
+		return client.BatchRegisterInstance(param)
+	})
+}
+
+// DeregisterInstance deregister the instance in both clusters
+func (c *NamingClient) DeregisterInstance(param vo.DeregisterInstanceParam) (bool, error) {
+	return c.dualWrite(param.GroupName, param.ServiceName, func(client naming_client.INamingClient) (bool, error) {
+		return client.DeregisterInstance(param)
+	})
+}
+
+// UpdateInstance update the instance in both clusters
+func (c *NamingClient) UpdateInstance(param vo.UpdateInstanceParam) (bool, error) {
+	return c.dualWrite(param.GroupName, param.ServiceName, func(client naming_client.INamingClient) (bool, error) {
+		return client.UpdateInstance(param)
+	})
+}
+
+// GetService get the service from the cluster it's read from
+func (c *NamingClient) GetService(param vo.GetServiceParam) (model.Service, error) {
+	return c.route(param.GroupName, param.ServiceName).GetService(param)
+}
+
+// CompareAndGetService get the service from the cluster it's read from if it's changed, the epochs of clusters are
+// different so the service is regarded as changed after cutover
+func (c *NamingClient) CompareAndGetService(param vo.GetServiceParam, lastEpoch uint64) (model.Service, bool, error) {
+	return c.route(param.GroupName, param.ServiceName).CompareAndGetService(param, lastEpoch)
+}
+
+// SelectAllInstances select the instances from the cluster the service is read from
+func (c *NamingClient) SelectAllInstances(param vo.SelectAllInstancesParam) ([]model.Instance, error) {
+	return c.route(param.GroupName, param.ServiceName).SelectAllInstances(param)
+}
+
+// SelectInstances select the instances from the cluster the service is read from
+func (c *NamingClient) SelectInstances(param vo.SelectInstancesParam) ([]model.Instance, error) {
+	return c.route(param.GroupName, param.ServiceName).SelectInstances(param)
+}
+
+// SelectOneHealthyInstance select the instance from the cluster the service is read from
+func (c *NamingClient) SelectOneHealthyInstance(param vo.SelectOneHealthInstanceParam) (*model.Instance, error) {
+	return c.route(param.GroupName, param.ServiceName).SelectOneHealthyInstance(param)
+}
+
+// Subscribe subscribe the service on the cluster it's read from, the subscription is moved on cutover
+func (c *NamingClient) Subscribe(param *vo.SubscribeParam) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.route(param.GroupName, param.ServiceName).Subscribe(param); err != nil {
+		return err
+	}
+	c.subscribes[param] = struct{}{}
+	return nil
+}
+
+// WaitForService wait for the service on the cluster it's read from
+func (c *NamingClient) WaitForService(ctx context.Context, param vo.WaitForServiceParam) ([]model.Instance, error) {
+	return c.route(param.GroupName, param.ServiceName).WaitForService(ctx, param)
+}
+
+// Unsubscribe unsubscribe the service, param must be the one subscribed
+func (c *NamingClient) Unsubscribe(param *vo.SubscribeParam) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.subscribes, param)
+	return c.route(param.GroupName, param.ServiceName).Unsubscribe(param)
+}
+
+// Watch stream the instance diffs of the service on the cluster it's read from, the stream is moved on cutover
+func (c *NamingClient) Watch(ctx context.Context, param vo.WatchParam) (<-chan model.InstanceChangeEvent, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	w := newServiceWatch(ctx, param)
+	if err := w.attach(c.route(param.GroupName, param.ServiceName)); err != nil {
+		return nil, err
+	}
+	c.watches[w] = struct{}{}
+	go func() {
+		<-ctx.Done()
+		c.mutex.Lock()
+		delete(c.watches, w)
+		c.mutex.Unlock()
+		w.close()
+	}()
+	return w.events, nil
+}
+
+// RestoreSubscriptions restore the subscriptions persisted by both clusters, the ones restored in the cluster
+// they aren't read from are moved
+func (c *NamingClient) RestoreSubscriptions(param vo.SubscribeParam) ([]*vo.SubscribeParam, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	restored := make(map[string]*vo.SubscribeParam)
+	var misplaced []*vo.SubscribeParam
+	for _, client := range []naming_client.INamingClient{c.INamingClient, c.newClient} {
+		params, err := client.RestoreSubscriptions(param)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range params {
+			if c.route(p.GroupName, p.ServiceName) != client {
+				_ = client.Unsubscribe(p)
+				misplaced = append(misplaced, p)
+				continue
+			}
+			restored[subscriptionKey(p)] = p
+		}
+	}
+	for _, p := range misplaced {
+		if _, ok := restored[subscriptionKey(p)]; ok {
+			continue
+		}
+		if err := c.route(p.GroupName, p.ServiceName).Subscribe(p); err != nil {
+			return nil, errors.Wrapf(err, "[migration.RestoreSubscriptions] subscribe service %s failed", p.ServiceName)
+		}
+		restored[subscriptionKey(p)] = p
+	}
+	params := make([]*vo.SubscribeParam, 0, len(restored))
+	for _, p := range restored {
+		c.subscribes[p] = struct{}{}
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+func subscriptionKey(param *vo.SubscribeParam) string {
+	return Key(param.GroupName, param.ServiceName) + "@@" + strings.Join(param.Clusters, ",")
+}
+
+// GetAllServicesInfo list the services in the old cluster
+func (c *NamingClient) GetAllServicesInfo(param vo.GetAllServiceInfoParam) (model.ServiceList, error) {
+	return c.INamingClient.GetAllServicesInfo(param)
+}
+
+// ListGroups list the groups of services in the old cluster
+func (c *NamingClient) ListGroups(namespace string) ([]string, error) {
+	return c.INamingClient.ListGroups(namespace)
+}
+
+// SetRouter set the router of both clusters
+func (c *NamingClient) SetRouter(router *routing.Router) {
+	c.INamingClient.SetRouter(router)
+	c.newClient.SetRouter(router)
+}
+
+// GetRegistrations get the registrations of the client of old cluster, the ones of new cluster are the same
+// unless the writes to it failed
+func (c *NamingClient) GetRegistrations() []model.Registration {
+	return c.INamingClient.GetRegistrations()
+}
+
+// InstanceId return the instance id of the client of old cluster
+func (c *NamingClient) InstanceId() string {
+	return c.INamingClient.InstanceId()
+}
+
+// GroupOf return the group resolved by the client of old cluster
+func (c *NamingClient) GroupOf(groupName string) string {
+	return c.INamingClient.GroupOf(groupName)
+}
+
+// Cutover set whether the service is read from the new cluster, the subscriptions and watches of it are moved to
+// the cluster
+func (c *NamingClient) Cutover(group, serviceName string, cut bool) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	key := c.key(group, serviceName)
+	if !c.cutover.set(key, cut) {
+		return nil
+	}
+	from, to := c.INamingClient, c.newClient
+	if !cut {
+		from, to = to, from
+	}
+	for param := range c.subscribes {
+		if c.key(param.GroupName, param.ServiceName) != key {
+			continue
+		}
+		if err := to.Subscribe(param); err != nil {
+			return errors.Wrapf(err, "[migration.Cutover] subscribe service %s failed", key)
+		}
+		_ = from.Unsubscribe(param)
+	}
+	for w := range c.watches {
+		if c.key(w.param.GroupName, w.param.ServiceName) != key {
+			continue
+		}
+		if err := w.attach(to); err != nil {
+			return errors.Wrapf(err, "[migration.Cutover] watch service %s failed", key)
+		}
+	}
+	return nil
+}
+
+// Verify compare the instances of services in both clusters by ip, port, health and enabled state
+func (c *NamingClient) Verify(params []vo.GetServiceParam) *Report {
+	report := &Report{}
+	for _, param := range params {
+		key := c.key(param.GroupName, param.ServiceName)
+		report.Checked++
+		oldService, err := c.INamingClient.GetService(param)
+		if err != nil {
+			report.Inconsistent = append(report.Inconsistent, Inconsistency{Key: key, Reason: "get from old cluster failed: " + err.Error()})
+			continue
+		}
+		newService, err := c.newClient.GetService(param)
+		if err != nil {
+			report.Inconsistent = append(report.Inconsistent, Inconsistency{Key: key, Reason: "get from new cluster failed: " + err.Error()})
+			continue
+		}
+		if reason := diffInstances(oldService.Hosts, newService.Hosts); len(reason) > 0 {
+			report.Inconsistent = append(report.Inconsistent, Inconsistency{Key: key, Reason: reason})
+		}
+	}
+	return report
+}
+
+// CloseClient close the clients of both clusters
+func (c *NamingClient) CloseClient() {
+	c.INamingClient.CloseClient()
+	c.newClient.CloseClient()
+}
+
+func diffInstances(oldHosts, newHosts []model.Instance) string {
+	oldStates, newStates := instanceStates(oldHosts), instanceStates(newHosts)
+	var missing, extra, changed []string
+	for addr, state := range oldStates {
+		newState, ok := newStates[addr]
+		switch {
+		case !ok:
+			missing = append(missing, addr)
+		case newState != state:
+			changed = append(changed, addr)
+		}
+	}
+	for addr := range newStates {
+		if _, ok := oldStates[addr]; !ok {
+			extra = append(extra, addr)
+		}
+	}
+	var reasons []string
+	for _, diff := range []struct {
+		name  string
+		addrs []string
+	}{{"missing in new cluster", missing}, {"only in new cluster", extra}, {"state differs", changed}} {
+		if len(diff.addrs) > 0 {
+			sort.Strings(diff.addrs)
+			reasons = append(reasons, diff.name+": "+strings.Join(diff.addrs, ","))
+		}
+	}
+	return strings.Join(reasons, "; ")
+}
+
+func instanceStates(hosts []model.Instance) map[string]string {
+	states := make(map[string]string, len(hosts))
+	for _, host := range hosts {
+		states[fmt.Sprintf("%s:%d", host.Ip, host.Port)] = fmt.Sprintf("healthy=%t,enabled=%t", host.Healthy, host.Enable)
+	}
+	return states
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migration
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client/naming_cache"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// relay forwards the stream of the cluster a key is read from to the caller, it's attached to the other cluster
+// on cutover. Only the stream attached last is forwarded, so the events of both clusters are never interleaved
+type relay struct {
+	ctx     context.Context
+	mutex   sync.Mutex
+	gen     int
+	cancel  context.CancelFunc
+	sendMux sync.Mutex
+	wg      sync.WaitGroup
+}
+
+// attach start the stream by open and stop the one attached before
+func (r *relay) attach(open func(ctx context.Context) error, forward func(gen int)) error {
+	ctx, cancel := context.WithCancel(r.ctx)
+	if err := open(ctx); err != nil {
+		cancel()
+		return err
+	}
+	r.mutex.Lock()
+	r.gen++
+	gen, prev := r.gen, r.cancel
+	r.cancel = cancel
+	r.mutex.Unlock()
+	if prev != nil {
+		prev()
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		forward(gen)
+	}()
+	return nil
+}
+
+func (r *relay) current(gen int) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return gen == r.gen
+}
+
+// configTail is the Tail of a config moved on cutover, the first event of the cluster attached on cutover is
+// dropped when it's the same as the last one forwarded
+type configTail struct {
+	relay
+	param  vo.ConfigParam
+	events chan model.ConfigChangeEvent
+	last   *model.ConfigChangeEvent
+}
+
+func newConfigTail(ctx context.Context, param vo.ConfigParam) *configTail {
+	return &configTail{relay: relay{ctx: ctx}, param: param, events: make(chan model.ConfigChangeEvent)}
+}
+
+func (t *configTail) attach(client config_client.IConfigClient) error {
+	var ch <-chan model.ConfigChangeEvent
+	return t.relay.attach(func(ctx context.Context) (err error) {
+		ch, err = client.Tail(ctx, t.param)
+		return err
+	}, func(gen int) {
+		for event := range ch {
+			if !t.forward(gen, event) {
+				return
+			}
+		}
+	})
+}
+
+func (t *configTail) forward(gen int, event model.ConfigChangeEvent) bool {
+	t.sendMux.Lock()
+	defer t.sendMux.Unlock()
+	if !t.current(gen) {
+		return true
+	}
+	if t.last != nil && *t.last == event {
+		return true
+	}
+	select {
+	case t.events <- event:
+		t.last = &event
+		return true
+	case <-t.ctx.Done():
+		return false
+	}
+}
+
+// close the events after ctx is done and all the attached streams are stopped
+func (t *configTail) close() {
+	<-t.ctx.Done()
+	t.wg.Wait()
+	close(t.events)
+}
+
+// serviceWatch is the Watch of a service moved on cutover, the diffs of the cluster attached on cutover are
+// computed against the instances forwarded last, so the caller doesn't see all the instances added again
+type serviceWatch struct {
+	relay
+	param  vo.WatchParam
+	events chan model.InstanceChangeEvent
+	last   []model.Instance
+	first  bool
+}
+
+func newServiceWatch(ctx context.Context, param vo.WatchParam) *serviceWatch {
+	return &serviceWatch{relay: relay{ctx: ctx}, param: param, events: make(chan model.InstanceChangeEvent), first: true}
+}
+
+func (w *serviceWatch) attach(client naming_client.INamingClient) error {
+	var ch <-chan model.InstanceChangeEvent
+	return w.relay.attach(func(ctx context.Context) (err error) {
+		ch, err = client.Watch(ctx, w.param)
+		return err
+	}, func(gen int) {
+		for event := range ch {
+			if !w.forward(gen, event) {
+				return
+			}
+		}
+	})
+}
+
+func (w *serviceWatch) forward(gen int, event model.InstanceChangeEvent) bool {
+	w.sendMux.Lock()
+	defer w.sendMux.Unlock()
+	if !w.current(gen) {
+		return true
+	}
+	event.Added, event.Removed, event.Modified = naming_cache.DiffInstances(w.last, event.Instances)
+	if !w.first && len(event.Added) == 0 && len(event.Removed) == 0 && len(event.Modified) == 0 {
+		return true
+	}
+	select {
+	case w.events <- event:
+		w.first = false
+		w.last = event.Instances
+		return true
+	case <-w.ctx.Done():
+		return false
+	}
+}
+
+// close the events after ctx is done and all the attached streams are stopped
+func (w *serviceWatch) close() {
+	<-w.ctx.Done()
+	w.wg.Wait()
+	close(w.events)
+}
@@ -49,6 +49,10 @@ func (client *NacosClient) SetClientConfig(config constant.ClientConfig) (err er
 		config.UpdateThreadNum = 20
 	}
 
+	if config.ConfigFetchThreadNum <= 0 {
+		config.ConfigFetchThreadNum = 8
+	}
+
 	if len(config.LogLevel) == 0 {
 		config.LogLevel = "info"
 	}
@@ -0,0 +1,59 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package balancer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
+// DefaultBalancer is the name of the built-in weighted random balancer
+const DefaultBalancer = "weighted-random"
+
+// Balancer choose one instance from the healthy instances of a service.
+// Implementations must be safe for concurrent use
+type Balancer interface {
+	// Choose return the chosen instance, instances is never empty.
+	// ctx is the one passed to SelectOneHealthyInstance, it carries request scoped values such as headers
+	Choose(ctx context.Context, instances []model.Instance) (model.Instance, error)
+}
+
+// Func is an adapter to allow the use of ordinary functions as Balancer
+type Func func(ctx context.Context, instances []model.Instance) (model.Instance, error)
+
+// Choose calls f(ctx, instances)
+func (f Func) Choose(ctx context.Context, instances []model.Instance) (model.Instance, error) {
+	return f(ctx, instances)
+}
+
+var balancers sync.Map
+
+// Register make the balancer available by name, registering a name twice replaces the previous one
+func Register(name string, b Balancer) {
+	balancers.Store(name, b)
+}
+
+// Get return the balancer registered with the name
+func Get(name string) (Balancer, bool) {
+	b, ok := balancers.Load(name)
+	if !ok {
+		return nil, false
+	}
+	return b.(Balancer), true
+}
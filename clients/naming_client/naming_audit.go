@@ -0,0 +1,44 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/audit"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
+// audit record the operation on the instances of service in the audit trail
+func (sc *NamingClient) audit(op, serviceName, groupName string, instances []model.Instance, done bool, err error) {
+	if sc.auditor == nil {
+		return
+	}
+	addrs := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		addrs = append(addrs, instance.Ip+":"+strconv.FormatUint(instance.Port, 10))
+	}
+	clientConfig, _ := sc.GetClientConfig()
+	record := audit.Record{Op: op, Namespace: clientConfig.NamespaceId, Group: groupName, Key: serviceName,
+		Target: strings.Join(addrs, ",")}
+	if auditErr := sc.auditor.Record(record, done, err); auditErr != nil {
+		logger.With(logger.ServiceContext(clientConfig.NamespaceId, groupName, serviceName)).
+			Warnf("write audit record of %s failed, err:%v", op, auditErr)
+	}
+}
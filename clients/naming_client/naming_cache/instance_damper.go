@@ -0,0 +1,95 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_cache
+
+import (
+	"sync"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
+// instanceDamper damp the flapping of instances: an instance which goes down, by being absent or unhealthy, is kept
+// in the delivered list until it's observed down for threshold consecutive times, and vice versa for going up.
+// It prevents the connection pool churn caused by transient heartbeat blips
+type instanceDamper struct {
+	threshold int
+	mux       sync.Mutex
+	states    map[string]*dampState
+}
+
+type dampState struct {
+	delivered []model.Instance
+	// pending is the number of consecutive times the instance is observed in the opposite state of the delivered one
+	pending map[string]int
+}
+
+func newInstanceDamper(threshold int) *instanceDamper {
+	return &instanceDamper{threshold: threshold, states: make(map[string]*dampState)}
+}
+
+// isUp return true when the instance is present and able to serve
+func isUp(instance model.Instance, present bool) bool {
+	return present && instance.Healthy && instance.Enable
+}
+
+// stabilize return the instances delivered for the observed ones of the service, the changes of the instances
+// which stay up or down are delivered at once
+func (d *instanceDamper) stabilize(cacheKey string, observed []model.Instance) []model.Instance {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	state, ok := d.states[cacheKey]
+	if !ok {
+		d.states[cacheKey] = &dampState{delivered: observed, pending: map[string]int{}}
+		return observed
+	}
+	deliveredMap := make(map[string]model.Instance, len(state.delivered))
+	for _, instance := range state.delivered {
+		deliveredMap[instanceKey(instance)] = instance
+	}
+	observedKeys := make(map[string]struct{}, len(observed))
+	pending := make(map[string]int)
+	result := make([]model.Instance, 0, len(observed))
+	for _, instance := range observed {
+		key := instanceKey(instance)
+		observedKeys[key] = struct{}{}
+		old, had := deliveredMap[key]
+		if isUp(old, had) == isUp(instance, true) || state.pending[key]+1 >= d.threshold {
+			result = append(result, instance)
+			continue
+		}
+		pending[key] = state.pending[key] + 1
+		if had {
+			result = append(result, old)
+		}
+	}
+	for _, old := range state.delivered {
+		key := instanceKey(old)
+		if _, ok := observedKeys[key]; ok || !isUp(old, true) || state.pending[key]+1 >= d.threshold {
+			continue
+		}
+		pending[key] = state.pending[key] + 1
+		result = append(result, old)
+	}
+	state.delivered, state.pending = result, pending
+	return result
+}
+
+func (d *instanceDamper) remove(cacheKey string) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	delete(d.states, cacheKey)
+}
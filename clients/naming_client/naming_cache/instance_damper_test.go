@@ -0,0 +1,51 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_cache
+
+import (
+	"testing"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceDamper(t *testing.T) {
+	a := model.Instance{Ip: "10.0.0.1", Port: 80, Healthy: true, Enable: true}
+	b := model.Instance{Ip: "10.0.0.2", Port: 80, Healthy: true, Enable: true}
+	unhealthyB := b
+	unhealthyB.Healthy = false
+	c := model.Instance{Ip: "10.0.0.3", Port: 80, Healthy: true, Enable: true}
+	damper := newInstanceDamper(3)
+
+	assert.Equal(t, []model.Instance{a, b}, damper.stabilize("demo", []model.Instance{a, b}))
+	// b is kept until it's observed down for 3 times, a blip does not remove it
+	assert.Equal(t, []model.Instance{a, b}, damper.stabilize("demo", []model.Instance{a}))
+	assert.Equal(t, []model.Instance{a, b}, damper.stabilize("demo", []model.Instance{a, b}))
+	assert.Equal(t, []model.Instance{a, b}, damper.stabilize("demo", []model.Instance{a, unhealthyB}))
+	assert.Equal(t, []model.Instance{a, b}, damper.stabilize("demo", []model.Instance{a}))
+	assert.Equal(t, []model.Instance{a}, damper.stabilize("demo", []model.Instance{a}))
+
+	// c is added after it's observed up for 3 times
+	assert.Equal(t, []model.Instance{a}, damper.stabilize("demo", []model.Instance{a, c}))
+	assert.Equal(t, []model.Instance{a}, damper.stabilize("demo", []model.Instance{a, c}))
+	assert.Equal(t, []model.Instance{a, c}, damper.stabilize("demo", []model.Instance{a, c}))
+
+	// the change of the instance which stays up is delivered at once
+	weighted := c
+	weighted.Weight = 2
+	assert.Equal(t, []model.Instance{a, weighted}, damper.stabilize("demo", []model.Instance{a, weighted}))
+}
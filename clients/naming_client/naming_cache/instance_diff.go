@@ -0,0 +1,54 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_cache
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
+// DiffInstances compare two snapshots of instance list, instances are identified by cluster, ip and port
+func DiffInstances(oldInstances, newInstances []model.Instance) (added, removed, modified []model.Instance) {
+	oldMap := make(map[string]model.Instance, len(oldInstances))
+	for _, instance := range oldInstances {
+		oldMap[instanceKey(instance)] = instance
+	}
+	for _, instance := range newInstances {
+		key := instanceKey(instance)
+		old, ok := oldMap[key]
+		if !ok {
+			added = append(added, instance)
+			continue
+		}
+		delete(oldMap, key)
+		if !reflect.DeepEqual(old, instance) {
+			modified = append(modified, instance)
+		}
+	}
+	for _, instance := range oldInstances {
+		if _, ok := oldMap[instanceKey(instance)]; ok {
+			removed = append(removed, instance)
+		}
+	}
+	return
+}
+
+func instanceKey(instance model.Instance) string {
+	return instance.ClusterName + "#" + instance.Ip + ":" + strconv.FormatUint(instance.Port, 10)
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_cache
+
+import (
+	"testing"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffInstances(t *testing.T) {
+	kept := model.Instance{Ip: "10.0.0.1", Port: 80, Weight: 1}
+	removed := model.Instance{Ip: "10.0.0.2", Port: 80, Weight: 1}
+	modified := model.Instance{Ip: "10.0.0.3", Port: 80, Weight: 1}
+	added := model.Instance{Ip: "10.0.0.3", Port: 81, Weight: 1}
+	newModified := modified
+	newModified.Healthy = true
+
+	a, r, m := DiffInstances([]model.Instance{kept, removed, modified}, []model.Instance{kept, newModified, added})
+	assert.Equal(t, []model.Instance{added}, a)
+	assert.Equal(t, []model.Instance{removed}, r)
+	assert.Equal(t, []model.Instance{newModified}, m)
+
+	a, r, m = DiffInstances(nil, []model.Instance{kept})
+	assert.Equal(t, []model.Instance{kept}, a)
+	assert.Empty(t, r)
+	assert.Empty(t, m)
+}
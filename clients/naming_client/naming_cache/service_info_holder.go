@@ -23,6 +23,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/cache"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
@@ -31,18 +32,41 @@ import (
 	"github.com/nacos-group/nacos-sdk-go/v2/util"
 )
 
+// serviceEpoch is the last epoch assigned to the service snapshots, it starts from the current time so that the epochs
+// keep increasing across restarts
+var serviceEpoch = uint64(util.CurrentMillis())
+
+// NextServiceEpoch return a new epoch larger than all the epochs assigned before
+func NextServiceEpoch() uint64 {
+	return atomic.AddUint64(&serviceEpoch, 1)
+}
+
+// raiseServiceEpoch make the epochs assigned later larger than epoch, such as the ones of the services cached on disk
+func raiseServiceEpoch(epoch uint64) {
+	for {
+		current := atomic.LoadUint64(&serviceEpoch)
+		if current >= epoch || atomic.CompareAndSwapUint64(&serviceEpoch, current, epoch) {
+			return
+		}
+	}
+}
+
 type ServiceInfoHolder struct {
 	ServiceInfoMap       sync.Map
+	namespace            string
 	updateCacheWhenEmpty bool
 	cacheDir             string
 	notLoadCacheAtStart  bool
 	subCallback          *SubscribeCallback
 	UpdateTimeMap        sync.Map
+	damper               *instanceDamper
 }
 
-func NewServiceInfoHolder(namespace, cacheDir string, updateCacheWhenEmpty, notLoadCacheAtStart bool) *ServiceInfoHolder {
+// NewServiceInfoHolder create the holder of service info, the flapping instances are damped when flapDamping is larger than 1
+func NewServiceInfoHolder(namespace, cacheDir string, updateCacheWhenEmpty, notLoadCacheAtStart bool, flapDamping int) *ServiceInfoHolder {
 	cacheDir = cacheDir + string(os.PathSeparator) + "naming" + string(os.PathSeparator) + namespace
 	serviceInfoHolder := &ServiceInfoHolder{
+		namespace:            namespace,
 		updateCacheWhenEmpty: updateCacheWhenEmpty,
 		notLoadCacheAtStart:  notLoadCacheAtStart,
 		cacheDir:             cacheDir,
@@ -51,6 +75,9 @@ func NewServiceInfoHolder(namespace, cacheDir string, updateCacheWhenEmpty, notL
 		ServiceInfoMap:       sync.Map{},
 	}
 
+	if flapDamping > 1 {
+		serviceInfoHolder.damper = newInstanceDamper(flapDamping)
+	}
 	if !notLoadCacheAtStart {
 		serviceInfoHolder.loadCacheFromDisk()
 	}
@@ -63,10 +90,16 @@ func (s *ServiceInfoHolder) loadCacheFromDisk() {
 		return
 	}
 	for k, v := range serviceMap {
+		raiseServiceEpoch(v.Epoch)
 		s.ServiceInfoMap.Store(k, v)
 	}
 }
 
+// log return the logger adding the namespace, group and name of service to the lines
+func (s *ServiceInfoHolder) log(service *model.Service) logger.Logger {
+	return logger.With(logger.ServiceContext(s.namespace, service.GroupName, service.Name))
+}
+
 func (s *ServiceInfoHolder) ProcessServiceJson(data string) {
 	s.ProcessService(util.JsonToService(data))
 }
@@ -78,7 +111,7 @@ func (s *ServiceInfoHolder) ProcessService(service *model.Service) {
 	if !s.updateCacheWhenEmpty {
 		//if instance list is empty,not to update cache
 		if service.Hosts == nil || len(service.Hosts) == 0 {
-			logger.Warnf("instance list is empty, updateCacheWhenEmpty is set to false, callback is not triggered. service name:%s", service.Name)
+			s.log(service).Warnf("instance list is empty, updateCacheWhenEmpty is set to false, callback is not triggered. service name:%s", service.Name)
 			return
 		}
 	}
@@ -86,16 +119,32 @@ func (s *ServiceInfoHolder) ProcessService(service *model.Service) {
 	cacheKey := util.GetServiceCacheKey(util.GetGroupName(service.Name, service.GroupName), service.Clusters)
 	oldDomain, ok := s.ServiceInfoMap.Load(cacheKey)
 	if ok && oldDomain.(model.Service).LastRefTime >= service.LastRefTime {
-		logger.Warnf("out of date data received, old-t: %d, new-t: %d", oldDomain.(model.Service).LastRefTime, service.LastRefTime)
+		s.log(service).Warnf("out of date data received, old-t: %d, new-t: %d", oldDomain.(model.Service).LastRefTime, service.LastRefTime)
 		return
 	}
 
+	if s.damper != nil {
+		stabilized := *service
+		stabilized.Hosts = s.damper.stabilize(cacheKey, service.Hosts)
+		service = &stabilized
+	}
+	instanceChanged := !ok || checkInstanceChanged(oldDomain, *service)
+	infoChanged := !instanceChanged && isServiceInfoChanged(oldDomain.(model.Service), *service)
+	if instanceChanged || infoChanged {
+		service.Epoch = NextServiceEpoch()
+	} else {
+		service.Epoch = oldDomain.(model.Service).Epoch
+	}
 	s.UpdateTimeMap.Store(cacheKey, uint64(util.CurrentMillis()))
 	s.ServiceInfoMap.Store(cacheKey, *service)
-	if !ok || checkInstanceChanged(oldDomain, *service) {
-		logger.Infof("service key:%s was updated to:%s", cacheKey, util.ToJsonString(service))
+	if instanceChanged {
+		s.log(service).Infof("service key:%s was updated to:%s", cacheKey, util.ToJsonString(service))
 		cache.WriteServicesToFile(service, cacheKey, s.cacheDir)
 		s.subCallback.ServiceChanged(cacheKey, service)
+	} else if infoChanged {
+		s.log(service).Infof("service key:%s settings was updated to:%s", cacheKey, util.ToJsonString(service))
+		cache.WriteServicesToFile(service, cacheKey, s.cacheDir)
+		s.subCallback.ServiceInfoChanged(cacheKey, service)
 	}
 	var count int
 	s.ServiceInfoMap.Range(func(key, value interface{}) bool {
@@ -119,6 +168,14 @@ func (s *ServiceInfoHolder) RegisterCallback(serviceName string, clusters string
 	s.subCallback.AddCallbackFunc(serviceName, clusters, callbackFunc)
 }
 
+func (s *ServiceInfoHolder) RegisterServiceCallback(serviceName string, clusters string, callbackFunc *func(service model.Service, err error)) {
+	s.subCallback.AddServiceCallbackFunc(serviceName, clusters, callbackFunc)
+}
+
+func (s *ServiceInfoHolder) DeregisterServiceCallback(serviceName string, clusters string, callbackFunc *func(service model.Service, err error)) {
+	s.subCallback.RemoveServiceCallbackFunc(serviceName, clusters, callbackFunc)
+}
+
 func (s *ServiceInfoHolder) DeregisterCallback(serviceName string, clusters string, callbackFunc *func(services []model.Instance, err error)) {
 	s.subCallback.RemoveCallbackFunc(serviceName, clusters, callbackFunc)
 }
@@ -126,12 +183,30 @@ func (s *ServiceInfoHolder) DeregisterCallback(serviceName string, clusters stri
 func (s *ServiceInfoHolder) StopUpdateIfContain(serviceName, clusters string) {
 	cacheKey := util.GetServiceCacheKey(serviceName, clusters)
 	s.ServiceInfoMap.Delete(cacheKey)
+	if s.damper != nil {
+		s.damper.remove(cacheKey)
+	}
 }
 
 func (s *ServiceInfoHolder) IsSubscribed(serviceName, clusters string) bool {
 	return s.subCallback.IsSubscribed(serviceName, clusters)
 }
 
+// HasCallback return true when any callback of the service is still registered
+func (s *ServiceInfoHolder) HasCallback(serviceName, clusters string) bool {
+	return s.subCallback.HasCallback(serviceName, clusters)
+}
+
+// SubscribedCount return the number of services which have any callback registered
+func (s *ServiceInfoHolder) SubscribedCount() int {
+	return s.subCallback.SubscribedCount()
+}
+
+// SubscribedCallbackCount return the number of callbacks of each subscribed service
+func (s *ServiceInfoHolder) SubscribedCallbackCount() map[string]int {
+	return s.subCallback.CallbackCount()
+}
+
 func checkInstanceChanged(oldDomain interface{}, service model.Service) bool {
 	if oldDomain == nil {
 		return true
@@ -140,6 +215,21 @@ func checkInstanceChanged(oldDomain interface{}, service model.Service) bool {
 	return isServiceInstanceChanged(oldService, service)
 }
 
+// ServiceChanged return true when the instances or the service-level settings changed, the services are not modified
+func ServiceChanged(oldService, newService model.Service) bool {
+	oldService.Hosts = append([]model.Instance(nil), oldService.Hosts...)
+	return isServiceInstanceChanged(oldService, newService) || isServiceInfoChanged(oldService, newService)
+}
+
+// return true when the service-level settings changed, otherwise return false.
+func isServiceInfoChanged(oldService, newService model.Service) bool {
+	return oldService.ReachProtectionThreshold != newService.ReachProtectionThreshold ||
+		oldService.Valid != newService.Valid ||
+		oldService.AllIPs != newService.AllIPs ||
+		oldService.ProtectThreshold != newService.ProtectThreshold ||
+		!reflect.DeepEqual(oldService.Metadata, newService.Metadata)
+}
+
 // return true when service instance changed ,otherwise return false.
 func isServiceInstanceChanged(oldService, newService model.Service) bool {
 	oldHostsLen := len(oldService.Hosts)
@@ -150,3 +150,69 @@ func createRandomIp() string {
 func creatRandomPort() uint64 {
 	return rand.Uint64()
 }
+
+func TestServiceInfoHolder_ServiceInfoChanged(t *testing.T) {
+	holder := NewServiceInfoHolder("", t.TempDir(), false, true, 0)
+	var (
+		instanceCalls int
+		services      []model.Service
+	)
+	instanceCallback := func(services []model.Instance, err error) {
+		instanceCalls++
+	}
+	serviceCallback := func(service model.Service, err error) {
+		services = append(services, service)
+	}
+	holder.RegisterCallback("DEFAULT_GROUP@@demo", "", &instanceCallback)
+	holder.RegisterServiceCallback("DEFAULT_GROUP@@demo", "", &serviceCallback)
+	hosts := []model.Instance{{Ip: "10.0.0.1", Port: 80}}
+
+	holder.ProcessService(&model.Service{Name: "demo", GroupName: "DEFAULT_GROUP", LastRefTime: 1, Hosts: hosts})
+	assert.Equal(t, 1, instanceCalls)
+	assert.Len(t, services, 1)
+
+	// only the service-level setting changes
+	holder.ProcessService(&model.Service{Name: "demo", GroupName: "DEFAULT_GROUP", LastRefTime: 2, Hosts: hosts, ReachProtectionThreshold: true})
+	assert.Equal(t, 1, instanceCalls)
+	assert.Len(t, services, 2)
+	assert.True(t, services[1].ReachProtectionThreshold)
+
+	holder.ProcessService(&model.Service{Name: "demo", GroupName: "DEFAULT_GROUP", LastRefTime: 3, Hosts: hosts, ReachProtectionThreshold: true})
+	assert.Equal(t, 1, instanceCalls)
+	assert.Len(t, services, 2)
+
+	holder.DeregisterServiceCallback("DEFAULT_GROUP@@demo", "", &serviceCallback)
+	holder.ProcessService(&model.Service{Name: "demo", GroupName: "DEFAULT_GROUP", LastRefTime: 4, Hosts: hosts})
+	assert.Len(t, services, 2)
+}
+
+func TestServiceInfoHolder_Epoch(t *testing.T) {
+	holder := NewServiceInfoHolder("", t.TempDir(), false, true, 0)
+	hosts := []model.Instance{{Ip: "10.0.0.1", Port: 80}}
+	epochOf := func() uint64 {
+		service, ok := holder.GetServiceInfo("demo", "DEFAULT_GROUP", "")
+		assert.True(t, ok)
+		return service.Epoch
+	}
+
+	first := &model.Service{Name: "demo", GroupName: "DEFAULT_GROUP", LastRefTime: 1, Hosts: hosts}
+	holder.ProcessService(first)
+	epoch := epochOf()
+	assert.True(t, epoch > 0)
+	assert.Equal(t, epoch, first.Epoch)
+
+	// the same instances keep the epoch
+	holder.ProcessService(&model.Service{Name: "demo", GroupName: "DEFAULT_GROUP", LastRefTime: 2, Hosts: hosts})
+	assert.Equal(t, epoch, epochOf())
+
+	holder.ProcessService(&model.Service{Name: "demo", GroupName: "DEFAULT_GROUP", LastRefTime: 3, Hosts: hosts, ReachProtectionThreshold: true})
+	assert.True(t, epochOf() > epoch)
+	epoch = epochOf()
+
+	holder.ProcessService(&model.Service{Name: "demo", GroupName: "DEFAULT_GROUP", LastRefTime: 4, ReachProtectionThreshold: true,
+		Hosts: append([]model.Instance{{Ip: "10.0.0.2", Port: 80}}, hosts...)})
+	assert.True(t, epochOf() > epoch)
+
+	raiseServiceEpoch(NextServiceEpoch() + 100)
+	assert.True(t, NextServiceEpoch() > epochOf()+100)
+}
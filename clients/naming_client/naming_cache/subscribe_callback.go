@@ -28,20 +28,59 @@ import (
 )
 
 type SubscribeCallback struct {
-	callbackFuncMap cache.ConcurrentMap
-	mux             *sync.Mutex
+	callbackFuncMap        cache.ConcurrentMap
+	serviceCallbackFuncMap cache.ConcurrentMap
+	mux                    *sync.Mutex
 }
 
 func NewSubscribeCallback() *SubscribeCallback {
-	return &SubscribeCallback{callbackFuncMap: cache.NewConcurrentMap(), mux: new(sync.Mutex)}
+	return &SubscribeCallback{callbackFuncMap: cache.NewConcurrentMap(), serviceCallbackFuncMap: cache.NewConcurrentMap(), mux: new(sync.Mutex)}
 }
 
 func (ed *SubscribeCallback) IsSubscribed(serviceName, clusters string) bool {
 	key := util.GetServiceCacheKey(serviceName, clusters)
 	_, ok := ed.callbackFuncMap.Get(key)
+	if !ok {
+		_, ok = ed.serviceCallbackFuncMap.Get(key)
+	}
 	return ok
 }
 
+// HasCallback return true when any callback of the service is still registered
+func (ed *SubscribeCallback) HasCallback(serviceName, clusters string) bool {
+	key := util.GetServiceCacheKey(serviceName, clusters)
+	if funcs, ok := ed.callbackFuncMap.Get(key); ok && len(funcs.([]*func(services []model.Instance, err error))) > 0 {
+		return true
+	}
+	funcs, ok := ed.serviceCallbackFuncMap.Get(key)
+	return ok && len(funcs.([]*func(service model.Service, err error))) > 0
+}
+
+// SubscribedCount return the number of services which have any callback registered
+func (ed *SubscribeCallback) SubscribedCount() int {
+	keys := make(map[string]struct{})
+	for key, funcs := range ed.callbackFuncMap.Items() {
+		if len(funcs.([]*func(services []model.Instance, err error))) > 0 {
+			keys[key] = struct{}{}
+		}
+	}
+	for key, funcs := range ed.serviceCallbackFuncMap.Items() {
+		if len(funcs.([]*func(service model.Service, err error))) > 0 {
+			keys[key] = struct{}{}
+		}
+	}
+	return len(keys)
+}
+
+// CallbackCount return the number of callback functions of each subscribed service
+func (ed *SubscribeCallback) CallbackCount() map[string]int {
+	counts := make(map[string]int)
+	for key, funcs := range ed.callbackFuncMap.Items() {
+		counts[key] = len(funcs.([]*func(services []model.Instance, err error)))
+	}
+	return counts
+}
+
 func (ed *SubscribeCallback) AddCallbackFunc(serviceName string, clusters string, callbackFunc *func(services []model.Instance, err error)) {
 	key := util.GetServiceCacheKey(serviceName, clusters)
 	defer ed.mux.Unlock()
@@ -71,7 +110,49 @@ func (ed *SubscribeCallback) RemoveCallbackFunc(serviceName string, clusters str
 
 }
 
+// AddServiceCallbackFunc add the callback which receives the whole service, including its service-level settings
+func (ed *SubscribeCallback) AddServiceCallbackFunc(serviceName string, clusters string, callbackFunc *func(service model.Service, err error)) {
+	key := util.GetServiceCacheKey(serviceName, clusters)
+	defer ed.mux.Unlock()
+	ed.mux.Lock()
+	var funcSlice []*func(service model.Service, err error)
+	old, ok := ed.serviceCallbackFuncMap.Get(key)
+	if ok {
+		funcSlice = append(funcSlice, old.([]*func(service model.Service, err error))...)
+	}
+	funcSlice = append(funcSlice, callbackFunc)
+	ed.serviceCallbackFuncMap.Set(key, funcSlice)
+}
+
+func (ed *SubscribeCallback) RemoveServiceCallbackFunc(serviceName string, clusters string, callbackFunc *func(service model.Service, err error)) {
+	key := util.GetServiceCacheKey(serviceName, clusters)
+	defer ed.mux.Unlock()
+	ed.mux.Lock()
+	funcs, ok := ed.serviceCallbackFuncMap.Get(key)
+	if ok && funcs != nil {
+		var newFuncs []*func(service model.Service, err error)
+		for _, funcItem := range funcs.([]*func(service model.Service, err error)) {
+			if funcItem != callbackFunc {
+				newFuncs = append(newFuncs, funcItem)
+			}
+		}
+		ed.serviceCallbackFuncMap.Set(key, newFuncs)
+	}
+}
+
+// ServiceInfoChanged notify the service callbacks only, it's called when the service-level settings change
+// while the instances stay the same
+func (ed *SubscribeCallback) ServiceInfoChanged(cacheKey string, service *model.Service) {
+	funcs, ok := ed.serviceCallbackFuncMap.Get(cacheKey)
+	if ok {
+		for _, funcItem := range funcs.([]*func(service model.Service, err error)) {
+			(*funcItem)(*service, nil)
+		}
+	}
+}
+
 func (ed *SubscribeCallback) ServiceChanged(cacheKey string, service *model.Service) {
+	ed.ServiceInfoChanged(cacheKey, service)
 	funcs, ok := ed.callbackFuncMap.Get(cacheKey)
 	if ok {
 		for _, funcItem := range funcs.([]*func(services []model.Instance, err error)) {
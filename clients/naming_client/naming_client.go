@@ -21,15 +21,28 @@ import (
 	"math"
 	"math/rand"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
 
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/cache"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/nacos_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client/balancer"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client/naming_cache"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client/naming_proxy"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client/routing"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client/subset"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/audit"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/file"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/introspection"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_server"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/quota"
+	"github.com/nacos-group/nacos-sdk-go/v2/inner/uuid"
 	"github.com/nacos-group/nacos-sdk-go/v2/model"
 	"github.com/nacos-group/nacos-sdk-go/v2/util"
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
@@ -42,13 +55,46 @@ type NamingClient struct {
 	cancel            context.CancelFunc
 	serviceProxy      naming_proxy.INamingProxy
 	serviceInfoHolder *naming_cache.ServiceInfoHolder
+	instanceId        string
+	errorRecorder     introspection.ErrorRecorder
+	router            atomic.Value
+	routedCallbacks   sync.Map
+	subscriptions     *serviceSubscriptions
+	leases            sync.Map
+	leaseListenerOnce sync.Once
+	// metadataRefreshers keep the refresher of each registered instance with metadata provider
+	metadataRefreshers sync.Map
+	clock              clock.Clock
+	// serviceQueryCache is nil unless ServiceCacheTTL is set
+	serviceQueryCache *serviceQueryCache
+	subsetKey         string
+	registrations     *registrationTracker
+	// quota enforces the client-side quotas, nil means no limit
+	quota *quota.Guard
+	// auditor records the mutating operations, nil means disabled
+	auditor *audit.Recorder
+	// serviceInfos cache the service-level settings delivered to ServiceCallback
+	serviceInfos *serviceInfoCache
+	// subscribeMutex serialize registering the callbacks, so that the subscriptions are counted against the quota atomically
+	subscribeMutex sync.Mutex
 }
 
 // NewNamingClient ...
 func NewNamingClient(nc nacos_client.INacosClient) (*NamingClient, error) {
+	return NewNamingClientWithServer(nc, nil)
+}
+
+// NewNamingClientWithServer create the naming client sending requests through nacosServer, so that the server list,
+// auth and identity tokens are shared with the config client, a nil nacosServer is created for the client itself
+func NewNamingClientWithServer(nc nacos_client.INacosClient, nacosServer *nacos_server.NacosServer) (*NamingClient, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	rand.Seed(time.Now().UnixNano())
 	naming := &NamingClient{INacosClient: nc, ctx: ctx, cancel: cancel}
+	uid, err := uuid.NewV4()
+	if err != nil {
+		return naming, err
+	}
+	naming.instanceId = uid.String()
 	clientConfig, err := nc.GetClientConfig()
 	if err != nil {
 		return naming, err
@@ -64,18 +110,42 @@ func NewNamingClient(nc nacos_client.INacosClient) (*NamingClient, error) {
 		return naming, err
 	}
 
+	if clientConfig.FilePermCfg != nil {
+		file.SetPermConfig(clientConfig.FilePermCfg)
+	}
+	dirErrs := file.PrepareDirs(clientConfig.CacheDir, clientConfig.LogDir)
 	if err = initLogger(clientConfig); err != nil {
 		return naming, err
 	}
+	for _, dirErr := range dirErrs {
+		logger.Warnf("%v", dirErr)
+	}
+	if clientConfig.IpDetectCfg != nil {
+		util.SetIpDetectConfig(clientConfig.IpDetectCfg)
+	}
 
 	if clientConfig.NamespaceId == "" {
 		clientConfig.NamespaceId = constant.DEFAULT_NAMESPACE_ID
 	}
+	naming.clock = clock.OrReal(clientConfig.Clock)
+	naming.subsetKey = clientConfig.SubsetKey
+	naming.registrations = newRegistrationTracker(clientConfig.RegistrationHooks, naming.clock)
+	naming.quota = quota.NewGuard(clientConfig.QuotaCfg)
+	naming.auditor = audit.NewRecorder(clientConfig.AuditSink, clientConfig.Identity(), clientConfig.AppName, naming.clock)
 
+	naming.subscriptions = newServiceSubscriptions(clientConfig.PersistSubscriptions, clientConfig.CacheDir, clientConfig.NamespaceId)
 	naming.serviceInfoHolder = naming_cache.NewServiceInfoHolder(clientConfig.NamespaceId, clientConfig.CacheDir,
-		clientConfig.UpdateCacheWhenEmpty, clientConfig.NotLoadCacheAtStart)
+		clientConfig.UpdateCacheWhenEmpty, clientConfig.NotLoadCacheAtStart, clientConfig.InstanceFlapDamping)
 
-	naming.serviceProxy, err = NewNamingProxyDelegate(ctx, clientConfig, serverConfig, httpAgent, naming.serviceInfoHolder)
+	if nacosServer != nil {
+		naming.serviceProxy, err = NewNamingProxyDelegateWithServer(ctx, clientConfig, nacosServer, naming.serviceInfoHolder)
+	} else {
+		naming.serviceProxy, err = NewNamingProxyDelegate(ctx, clientConfig, serverConfig, httpAgent, naming.serviceInfoHolder)
+	}
+	naming.serviceInfos = newServiceInfoCache(naming.clock, naming.queryServiceInfo)
+	if clientConfig.ServiceCacheTTL > 0 {
+		naming.serviceQueryCache = newServiceQueryCache(clientConfig.ServiceCacheTTL, clientConfig.ServiceStaleTTL, naming.clock, naming.queryService)
+	}
 
 	if clientConfig.AsyncUpdateService {
 		go NewServiceInfoUpdater(ctx, naming.serviceInfoHolder, clientConfig.UpdateThreadNum, naming.serviceProxy).asyncUpdateService()
@@ -83,45 +153,82 @@ func NewNamingClient(nc nacos_client.INacosClient) (*NamingClient, error) {
 	if err != nil {
 		return naming, err
 	}
-
+	if clientConfig.ResyncCfg != nil && clientConfig.ResyncCfg.Interval > 0 {
+		go naming.resyncLoop(*clientConfig.ResyncCfg)
+	}
+	introspection.Register(naming)
 	return naming, nil
 }
 
+// instanceIp returns the detected ip of client when the ip of instance is not set
+func instanceIp(ip string) string {
+	if len(ip) == 0 {
+		return util.LocalIP()
+	}
+	return ip
+}
+
 func initLogger(clientConfig constant.ClientConfig) error {
 	return logger.InitLogger(logger.BuildLoggerConfig(clientConfig))
 }
 
+// GroupOf return the group of service, DefaultServiceGroup of ClientConfig is used when the param doesn't give it
+func (sc *NamingClient) GroupOf(groupName string) string {
+	if len(groupName) > 0 {
+		return groupName
+	}
+	if clientConfig, _ := sc.GetClientConfig(); len(clientConfig.DefaultServiceGroup) > 0 {
+		return clientConfig.DefaultServiceGroup
+	}
+	return constant.DEFAULT_GROUP
+}
+
 // RegisterInstance ...
 func (sc *NamingClient) RegisterInstance(param vo.RegisterInstanceParam) (bool, error) {
 	if param.ServiceName == "" {
 		return false, errors.New("serviceName cannot be empty!")
 	}
-	if len(param.GroupName) == 0 {
-		param.GroupName = constant.DEFAULT_GROUP
-	}
+	param.GroupName = sc.GroupOf(param.GroupName)
 	if param.Metadata == nil {
 		param.Metadata = make(map[string]string)
 	}
+	param.Ip = instanceIp(param.Ip)
+	metadata := param.Metadata
+	if param.MetadataProvider != nil {
+		metadata = provideMetadata(param)
+	}
 	instance := model.Instance{
 		Ip:          param.Ip,
 		Port:        param.Port,
-		Metadata:    param.Metadata,
+		Metadata:    metadata,
 		ClusterName: param.ClusterName,
 		Healthy:     param.Healthy,
 		Enable:      param.Enable,
 		Weight:      param.Weight,
 		Ephemeral:   param.Ephemeral,
 	}
-	return sc.serviceProxy.RegisterInstance(param.ServiceName, param.GroupName, instance)
+	tracker := sc.trackRegistrations()
+	if err := tracker.beforeRegister(param.ServiceName, param.GroupName, &instance); err != nil {
+		return false, err
+	}
+	registered, err := sc.serviceProxy.RegisterInstance(param.ServiceName, param.GroupName, instance)
+	sc.audit(audit.OpRegisterInstance, param.ServiceName, param.GroupName, []model.Instance{instance}, registered, err)
+	sc.errorRecorder.Record(errors.Wrapf(err, "register instance %s:%d of service %s failed", param.Ip, param.Port, param.ServiceName))
+	tracker.afterRegister(param.ServiceName, param.GroupName, instance, registered, err)
+	if registered && err == nil {
+		sc.observeRegistrations(param.ServiceName, param.GroupName)
+		if param.MetadataProvider != nil {
+			sc.startMetadataRefresher(param, metadata)
+		}
+	}
+	return registered, err
 }
 
 func (sc *NamingClient) BatchRegisterInstance(param vo.BatchRegisterInstanceParam) (bool, error) {
 	if param.ServiceName == "" {
 		return false, errors.New("serviceName cannot be empty!")
 	}
-	if len(param.GroupName) == 0 {
-		param.GroupName = constant.DEFAULT_GROUP
-	}
+	param.GroupName = sc.GroupOf(param.GroupName)
 	if len(param.Instances) == 0 {
 		return false, errors.New("instances cannot be empty!")
 	}
@@ -131,7 +238,7 @@ func (sc *NamingClient) BatchRegisterInstance(param vo.BatchRegisterInstancePara
 			return false, errors.Errorf("Batch registration does not allow persistent instance registration! instance:%+v", param)
 		}
 		modelInstances = append(modelInstances, model.Instance{
-			Ip:          param.Ip,
+			Ip:          instanceIp(param.Ip),
 			Port:        param.Port,
 			Metadata:    param.Metadata,
 			ClusterName: param.ClusterName,
@@ -141,22 +248,39 @@ func (sc *NamingClient) BatchRegisterInstance(param vo.BatchRegisterInstancePara
 			Ephemeral:   param.Ephemeral,
 		})
 	}
-
-	return sc.serviceProxy.BatchRegisterInstance(param.ServiceName, param.GroupName, modelInstances)
+	tracker := sc.trackRegistrations()
+	for i := range modelInstances {
+		if err := tracker.beforeRegister(param.ServiceName, param.GroupName, &modelInstances[i]); err != nil {
+			return false, err
+		}
+	}
+	registered, err := sc.serviceProxy.BatchRegisterInstance(param.ServiceName, param.GroupName, modelInstances)
+	sc.audit(audit.OpBatchRegisterInstance, param.ServiceName, param.GroupName, modelInstances, registered, err)
+	for _, instance := range modelInstances {
+		tracker.afterRegister(param.ServiceName, param.GroupName, instance, registered, err)
+	}
+	if registered && err == nil {
+		sc.observeRegistrations(param.ServiceName, param.GroupName)
+	}
+	return registered, err
 }
 
 // DeregisterInstance ...
 func (sc *NamingClient) DeregisterInstance(param vo.DeregisterInstanceParam) (bool, error) {
-	if len(param.GroupName) == 0 {
-		param.GroupName = constant.DEFAULT_GROUP
-	}
+	param.GroupName = sc.GroupOf(param.GroupName)
 	instance := model.Instance{
-		Ip:          param.Ip,
+		Ip:          instanceIp(param.Ip),
 		Port:        param.Port,
 		ClusterName: param.Cluster,
 		Ephemeral:   param.Ephemeral,
 	}
-	return sc.serviceProxy.DeregisterInstance(param.ServiceName, param.GroupName, instance)
+	sc.stopMetadataRefresher(vo.RegisterInstanceParam{Ip: instance.Ip, Port: param.Port, ClusterName: param.Cluster,
+		ServiceName: param.ServiceName, GroupName: param.GroupName})
+	deregistered, err := sc.serviceProxy.DeregisterInstance(param.ServiceName, param.GroupName, instance)
+	sc.audit(audit.OpDeregisterInstance, param.ServiceName, param.GroupName, []model.Instance{instance}, deregistered, err)
+	sc.errorRecorder.Record(errors.Wrapf(err, "deregister instance %s:%d of service %s failed", param.Ip, param.Port, param.ServiceName))
+	sc.registrations.afterDeregister(param.ServiceName, param.GroupName, instance, deregistered, err)
+	return deregistered, err
 }
 
 // UpdateInstance ...
@@ -164,14 +288,12 @@ func (sc *NamingClient) UpdateInstance(param vo.UpdateInstanceParam) (bool, erro
 	if param.ServiceName == "" {
 		return false, errors.New("serviceName cannot be empty!")
 	}
-	if len(param.GroupName) == 0 {
-		param.GroupName = constant.DEFAULT_GROUP
-	}
+	param.GroupName = sc.GroupOf(param.GroupName)
 	if param.Metadata == nil {
 		param.Metadata = make(map[string]string)
 	}
 	instance := model.Instance{
-		Ip:          param.Ip,
+		Ip:          instanceIp(param.Ip),
 		Port:        param.Port,
 		Metadata:    param.Metadata,
 		ClusterName: param.ClusterName,
@@ -181,29 +303,62 @@ func (sc *NamingClient) UpdateInstance(param vo.UpdateInstanceParam) (bool, erro
 		Ephemeral:   param.Ephemeral,
 	}
 
-	return sc.serviceProxy.RegisterInstance(param.ServiceName, param.GroupName, instance)
-
+	updated, err := sc.serviceProxy.RegisterInstance(param.ServiceName, param.GroupName, instance)
+	sc.audit(audit.OpUpdateInstance, param.ServiceName, param.GroupName, []model.Instance{instance}, updated, err)
+	sc.registrations.afterUpdate(param.ServiceName, param.GroupName, instance, updated, err)
+	return updated, err
 }
 
 // GetService Get service info by Group and DataId, clusters was optional
 func (sc *NamingClient) GetService(param vo.GetServiceParam) (service model.Service, err error) {
-	if len(param.GroupName) == 0 {
-		param.GroupName = constant.DEFAULT_GROUP
-	}
+	param.GroupName = sc.GroupOf(param.GroupName)
 	var ok bool
 	clusters := strings.Join(param.Clusters, ",")
 	service, ok = sc.serviceInfoHolder.GetServiceInfo(param.ServiceName, param.GroupName, clusters)
+	if sc.serviceQueryCache != nil {
+		// the subscribed service is kept up to date by push, the others are queried and cached for a while
+		serviceFullName := util.GetGroupName(param.ServiceName, param.GroupName)
+		if ok && sc.serviceInfoHolder.IsSubscribed(serviceFullName, clusters) {
+			return service, nil
+		}
+		return sc.serviceQueryCache.get(util.GetServiceCacheKey(serviceFullName, clusters), param.ServiceName, param.GroupName, clusters)
+	}
 	if !ok {
-		service, err = sc.serviceProxy.Subscribe(param.ServiceName, param.GroupName, clusters)
+		if service, err = sc.serviceProxy.Subscribe(param.ServiceName, param.GroupName, clusters); err == nil && service.Epoch == 0 {
+			// the epoch is assigned to the snapshot kept by the holder, such as the damped one
+			if held, ok := sc.serviceInfoHolder.GetServiceInfo(param.ServiceName, param.GroupName, clusters); ok {
+				service = held
+			}
+		}
 	}
 	return service, err
 }
 
+// CompareAndGetService get the service only if its epoch is not lastEpoch. When it's not changed, changed is false
+// and the service has no instances, so the callers polling the service skip comparing the instances
+func (sc *NamingClient) CompareAndGetService(param vo.GetServiceParam, lastEpoch uint64) (service model.Service, changed bool, err error) {
+	if service, err = sc.GetService(param); err != nil {
+		return model.Service{}, false, err
+	}
+	if lastEpoch > 0 && service.Epoch == lastEpoch {
+		service.Hosts = nil
+		return service, false, nil
+	}
+	return service, true, nil
+}
+
+// queryService query the service without subscription
+func (sc *NamingClient) queryService(serviceName, groupName, clusters string) (model.Service, error) {
+	service, err := sc.serviceProxy.QueryInstancesOfService(serviceName, groupName, clusters, 0, false)
+	if err != nil {
+		return model.Service{}, err
+	}
+	return *service, nil
+}
+
 // GetAllServicesInfo Get all instance by Namespace and Group with page
 func (sc *NamingClient) GetAllServicesInfo(param vo.GetAllServiceInfoParam) (model.ServiceList, error) {
-	if len(param.GroupName) == 0 {
-		param.GroupName = constant.DEFAULT_GROUP
-	}
+	param.GroupName = sc.GroupOf(param.GroupName)
 	clientConfig, _ := sc.GetClientConfig()
 	if len(param.NameSpace) == 0 {
 		if len(clientConfig.NamespaceId) == 0 {
@@ -216,47 +371,87 @@ func (sc *NamingClient) GetAllServicesInfo(param vo.GetAllServiceInfoParam) (mod
 	return services, err
 }
 
+// groupLister is implemented by the proxy which is able to list the groups of services
+type groupLister interface {
+	ListGroups(namespaceId string) ([]string, error)
+}
+
+// ListGroups list the groups having services in the namespace, the namespace of client is used when it's empty
+func (sc *NamingClient) ListGroups(namespace string) ([]string, error) {
+	if len(namespace) == 0 {
+		clientConfig, _ := sc.GetClientConfig()
+		namespace = clientConfig.NamespaceId
+	}
+	if len(namespace) == 0 {
+		namespace = constant.DEFAULT_NAMESPACE_ID
+	}
+	lister, ok := sc.serviceProxy.(groupLister)
+	if !ok {
+		return nil, errors.New("[client.ListGroups] listing groups is not supported by the naming proxy")
+	}
+	return lister.ListGroups(namespace)
+}
+
 // SelectAllInstances Get all instance by DataId 和 Group
 func (sc *NamingClient) SelectAllInstances(param vo.SelectAllInstancesParam) ([]model.Instance, error) {
-	if len(param.GroupName) == 0 {
-		param.GroupName = constant.DEFAULT_GROUP
-	}
+	param.GroupName = sc.GroupOf(param.GroupName)
 	clusters := strings.Join(param.Clusters, ",")
-	var (
-		service model.Service
-		ok      bool
-		err     error
-	)
-
-	service, ok = sc.serviceInfoHolder.GetServiceInfo(param.ServiceName, param.GroupName, clusters)
-	if !ok {
-		service, err = sc.serviceProxy.Subscribe(param.ServiceName, param.GroupName, clusters)
+	selector, err := parseLabelSelector(param.Selector)
+	if err != nil {
+		return nil, err
 	}
+	service, err := sc.getServiceWithSelector(param.ServiceName, param.GroupName, clusters, param.Selector)
 	if err != nil || service.Hosts == nil || len(service.Hosts) == 0 {
 		return []model.Instance{}, err
 	}
-	return service.Hosts, err
+	hosts := service.Hosts
+	if param.ExcludeSelf {
+		hosts = sc.excludeSelf(param.ServiceName, param.GroupName, hosts)
+	}
+	return sc.subset(param.SubsetSize, sc.route(param.ServiceName, param.GroupName, param.Labels, selector.filter(hosts))), err
 }
 
 // SelectInstances Get all instance by DataId, Group and Health
 func (sc *NamingClient) SelectInstances(param vo.SelectInstancesParam) ([]model.Instance, error) {
-	if len(param.GroupName) == 0 {
-		param.GroupName = constant.DEFAULT_GROUP
-	}
-	var (
-		service model.Service
-		ok      bool
-		err     error
-	)
+	param.GroupName = sc.GroupOf(param.GroupName)
 	clusters := strings.Join(param.Clusters, ",")
-	service, ok = sc.serviceInfoHolder.GetServiceInfo(param.ServiceName, param.GroupName, clusters)
-	if !ok {
-		service, err = sc.serviceProxy.Subscribe(param.ServiceName, param.GroupName, clusters)
+	selector, err := parseLabelSelector(param.Selector)
+	if err != nil {
+		return nil, err
+	}
+	service, err := sc.getServiceWithSelector(param.ServiceName, param.GroupName, clusters, param.Selector)
+	if err != nil {
+		return nil, err
+	}
+	instances, err := sc.selectInstances(service, param.HealthyOnly)
+	if err != nil {
+		return instances, err
+	}
+	if param.ExcludeSelf {
+		instances = sc.excludeSelf(param.ServiceName, param.GroupName, instances)
+	}
+	return sc.subset(param.SubsetSize, sc.route(param.ServiceName, param.GroupName, param.Labels, selector.filter(instances))), nil
+}
+
+// selectorQuerier is implemented by the proxy which is able to push the label selector down to server
+type selectorQuerier interface {
+	QueryInstancesWithSelector(serviceName, groupName, clusters string, healthyOnly bool, selector string) (*model.Service, error)
+}
+
+// getServiceWithSelector returns the cached service, otherwise the service is subscribed, or queried with the
+// selector pushed down when the selector is set, so that only the matched instances are transferred
+func (sc *NamingClient) getServiceWithSelector(serviceName, groupName, clusters, selector string) (model.Service, error) {
+	if service, ok := sc.serviceInfoHolder.GetServiceInfo(serviceName, groupName, clusters); ok {
+		return service, nil
+	}
+	if querier, ok := sc.serviceProxy.(selectorQuerier); ok && len(selector) > 0 {
+		service, err := querier.QueryInstancesWithSelector(serviceName, groupName, clusters, false, selector)
 		if err != nil {
-			return nil, err
+			return model.Service{}, err
 		}
+		return *service, nil
 	}
-	return sc.selectInstances(service, param.HealthyOnly)
+	return sc.serviceProxy.Subscribe(serviceName, groupName, clusters)
 }
 
 func (sc *NamingClient) selectInstances(service model.Service, healthy bool) ([]model.Instance, error) {
@@ -275,9 +470,7 @@ func (sc *NamingClient) selectInstances(service model.Service, healthy bool) ([]
 
 // SelectOneHealthyInstance Get one healthy instance by DataId and Group
 func (sc *NamingClient) SelectOneHealthyInstance(param vo.SelectOneHealthInstanceParam) (*model.Instance, error) {
-	if len(param.GroupName) == 0 {
-		param.GroupName = constant.DEFAULT_GROUP
-	}
+	param.GroupName = sc.GroupOf(param.GroupName)
 	var (
 		service model.Service
 		ok      bool
@@ -292,10 +485,22 @@ func (sc *NamingClient) SelectOneHealthyInstance(param vo.SelectOneHealthInstanc
 		}
 	}
 
-	return sc.selectOneHealthyInstances(service)
+	if param.ExcludeSelf {
+		service.Hosts = sc.excludeSelf(param.ServiceName, param.GroupName, service.Hosts)
+	}
+	ctx := param.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return sc.selectOneHealthyInstancesWith(ctx, service, param.Balancer, param.Labels, param.SubsetSize)
 }
 
 func (sc *NamingClient) selectOneHealthyInstances(service model.Service) (*model.Instance, error) {
+	return sc.selectOneHealthyInstancesWith(context.Background(), service, "", nil, 0)
+}
+
+func (sc *NamingClient) selectOneHealthyInstancesWith(ctx context.Context, service model.Service, balancerName string,
+	labels map[string]string, subsetSize int) (*model.Instance, error) {
 	if service.Hosts == nil || len(service.Hosts) == 0 {
 		return nil, errors.New("instance list is empty!")
 	}
@@ -315,35 +520,201 @@ func (sc *NamingClient) selectOneHealthyInstances(service model.Service) (*model
 		return nil, errors.New("healthy instance list is empty!")
 	}
 
-	instance := newChooser(result).pick()
+	result = sc.subset(subsetSize, sc.route(service.Name, service.GroupName, labels, result))
+	b, err := sc.resolveBalancer(service, result, balancerName)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := b.Choose(ctx, result)
+	if err != nil {
+		return nil, err
+	}
 	return &instance, nil
 }
 
+// resolveBalancer find the balancer in order of the name passed by caller, the metadata of instances,
+// the balancer configured for the service, the default balancer configured and the built-in one
+// serviceBalancerKey return the key of the service in ClientConfig.ServiceLoadBalancers, groupName@@serviceName.
+// The name of service from server may carry the group already
+func (sc *NamingClient) serviceBalancerKey(service model.Service) string {
+	if strings.Contains(service.Name, constant.SERVICE_INFO_SPLITER) {
+		return service.Name
+	}
+	return util.GetGroupName(service.Name, sc.GroupOf(service.GroupName))
+}
+
+func (sc *NamingClient) resolveBalancer(service model.Service, instances []model.Instance, name string) (balancer.Balancer, error) {
+	if len(name) == 0 {
+		for _, instance := range instances {
+			if v, ok := instance.Metadata[constant.LOAD_BALANCER]; ok && len(v) > 0 {
+				name = v
+				break
+			}
+		}
+	}
+	clientConfig, _ := sc.GetClientConfig()
+	if len(name) == 0 {
+		name = clientConfig.ServiceLoadBalancers[sc.serviceBalancerKey(service)]
+	}
+	if len(name) == 0 {
+		name = clientConfig.LoadBalancer
+	}
+	if len(name) == 0 {
+		name = balancer.DefaultBalancer
+	}
+	b, ok := balancer.Get(name)
+	if !ok {
+		return nil, errors.Errorf("load balancer %s is not registered", name)
+	}
+	return b, nil
+}
+
 // Subscribe ...
 func (sc *NamingClient) Subscribe(param *vo.SubscribeParam) error {
-	if len(param.GroupName) == 0 {
-		param.GroupName = constant.DEFAULT_GROUP
-	}
+	param.GroupName = sc.GroupOf(param.GroupName)
 	clusters := strings.Join(param.Clusters, ",")
-	sc.serviceInfoHolder.RegisterCallback(util.GetGroupName(param.ServiceName, param.GroupName), clusters, &param.SubscribeCallback)
+	serviceFullName := util.GetGroupName(param.ServiceName, param.GroupName)
+	if err := sc.registerCallbacks(param, serviceFullName, clusters); err != nil {
+		return err
+	}
 	_, err := sc.serviceProxy.Subscribe(param.ServiceName, param.GroupName, clusters)
+	sc.errorRecorder.Record(errors.Wrapf(err, "subscribe service %s failed", param.ServiceName))
+	if err == nil {
+		sc.subscriptions.add(util.GetServiceCacheKey(util.GetGroupName(param.ServiceName, param.GroupName), clusters),
+			cache.ServiceSubscription{ServiceName: param.ServiceName, GroupName: param.GroupName, Clusters: param.Clusters})
+	}
 	return err
 }
 
+// registerCallbacks register the callbacks of param, it fails with QuotaExceededError when the service is not
+// subscribed yet and MaxSubscriptions is reached. The service is counted and registered under subscribeMutex
+func (sc *NamingClient) registerCallbacks(param *vo.SubscribeParam, serviceFullName, clusters string) error {
+	sc.subscribeMutex.Lock()
+	defer sc.subscribeMutex.Unlock()
+	if !sc.serviceInfoHolder.HasCallback(serviceFullName, clusters) {
+		if err := sc.quota.CheckSubscriptions(util.GetServiceCacheKey(serviceFullName, clusters), sc.serviceInfoHolder.SubscribedCount()+1); err != nil {
+			return err
+		}
+	}
+	if param.SubscribeCallback != nil {
+		sc.serviceInfoHolder.RegisterCallback(serviceFullName, clusters, sc.routedCallback(param))
+	}
+	if param.ServiceCallback != nil {
+		sc.serviceInfoHolder.RegisterServiceCallback(serviceFullName, clusters, sc.serviceCallback(param))
+	}
+	return nil
+}
+
 // Unsubscribe ...
 func (sc *NamingClient) Unsubscribe(param *vo.SubscribeParam) (err error) {
+	param.GroupName = sc.GroupOf(param.GroupName)
 	clusters := strings.Join(param.Clusters, ",")
 	serviceFullName := util.GetGroupName(param.ServiceName, param.GroupName)
-	sc.serviceInfoHolder.DeregisterCallback(serviceFullName, clusters, &param.SubscribeCallback)
+	callback := &param.SubscribeCallback
+	if routed, ok := sc.routedCallbacks.LoadAndDelete(callback); ok {
+		callback = routed.(*func(services []model.Instance, err error))
+	}
+	sc.serviceInfoHolder.DeregisterCallback(serviceFullName, clusters, callback)
+	if wrapped, ok := sc.routedCallbacks.LoadAndDelete(&param.ServiceCallback); ok {
+		sc.serviceInfoHolder.DeregisterServiceCallback(serviceFullName, clusters, wrapped.(*func(service model.Service, err error)))
+	}
 	if sc.serviceInfoHolder.IsSubscribed(serviceFullName, clusters) {
 		err = sc.serviceProxy.Unsubscribe(param.ServiceName, param.GroupName, clusters)
 	}
+	if !sc.serviceInfoHolder.HasCallback(serviceFullName, clusters) {
+		sc.subscriptions.remove(util.GetServiceCacheKey(serviceFullName, clusters))
+		sc.serviceInfos.remove(serviceFullName)
+	}
 
 	return err
 }
 
+// SetRouter ...
+func (sc *NamingClient) SetRouter(router *routing.Router) {
+	sc.router.Store(router)
+}
+
+func (sc *NamingClient) route(serviceName, groupName string, labels map[string]string, instances []model.Instance) []model.Instance {
+	router, _ := sc.router.Load().(*routing.Router)
+	return router.Route(util.GetGroupName(serviceName, groupName), sc.instanceId, labels, instances)
+}
+
+// subset choose the stable subset of instances for this client when size is positive
+func (sc *NamingClient) subset(size int, instances []model.Instance) []model.Instance {
+	if size <= 0 {
+		return instances
+	}
+	key := sc.subsetKey
+	if len(key) == 0 {
+		key = util.LocalIP()
+	}
+	return subset.Subset(key, instances, size)
+}
+
+// routedCallback wrap the callback to route the instances when labels are set,
+// the wrapper is kept so that it can be found by Unsubscribe
+func (sc *NamingClient) routedCallback(param *vo.SubscribeParam) *func(services []model.Instance, err error) {
+	if len(param.Labels) == 0 && !param.ExcludeSelf {
+		return &param.SubscribeCallback
+	}
+	callback, labels, excludeSelf := param.SubscribeCallback, param.Labels, param.ExcludeSelf
+	routed := func(services []model.Instance, err error) {
+		if excludeSelf {
+			services = sc.excludeSelf(param.ServiceName, param.GroupName, services)
+		}
+		callback(sc.route(param.ServiceName, param.GroupName, labels, services), err)
+	}
+	sc.routedCallbacks.Store(&param.SubscribeCallback, &routed)
+	return &routed
+}
+
+// serviceInfoQuerier is implemented by the proxy which is able to query the service-level settings
+type serviceInfoQuerier interface {
+	QueryServiceInfo(serviceName, groupName string) (model.ServiceInfo, error)
+}
+
+// errServiceInfoUnsupported is returned when the proxy is not able to query the service-level settings
+var errServiceInfoUnsupported = errors.New("query service info is not supported by the naming proxy")
+
+func (sc *NamingClient) queryServiceInfo(serviceName, groupName string) (model.ServiceInfo, error) {
+	querier, ok := sc.serviceProxy.(serviceInfoQuerier)
+	if !ok {
+		return model.ServiceInfo{}, errServiceInfoUnsupported
+	}
+	return querier.QueryServiceInfo(serviceName, groupName)
+}
+
+// serviceCallback wrap the ServiceCallback to fill the service-level settings and route the instances,
+// the wrapper is kept so that it can be found by Unsubscribe. The settings are cached, see serviceInfoCache
+func (sc *NamingClient) serviceCallback(param *vo.SubscribeParam) *func(service model.Service, err error) {
+	callback, labels, excludeSelf := param.ServiceCallback, param.Labels, param.ExcludeSelf
+	wrapped := func(service model.Service, err error) {
+		if service.Metadata == nil {
+			serviceInfo, queryErr := sc.serviceInfos.get(util.GetGroupName(param.ServiceName, param.GroupName), param.ServiceName, param.GroupName)
+			if queryErr != nil && queryErr != errServiceInfoUnsupported {
+				clientConfig, _ := sc.GetClientConfig()
+				logger.With(logger.ServiceContext(clientConfig.NamespaceId, param.GroupName, param.ServiceName)).
+					Warnf("query service info of %s failed, err:%v", param.ServiceName, queryErr)
+			}
+			service.Metadata = serviceInfo.Metadata
+			service.ProtectThreshold = serviceInfo.ProtectThreshold
+		}
+		if excludeSelf {
+			service.Hosts = sc.excludeSelf(param.ServiceName, param.GroupName, service.Hosts)
+		}
+		if len(labels) > 0 {
+			service.Hosts = sc.route(param.ServiceName, param.GroupName, labels, service.Hosts)
+		}
+		callback(service, err)
+	}
+	sc.routedCallbacks.Store(&param.ServiceCallback, &wrapped)
+	return &wrapped
+}
+
 // CloseClient ...
 func (sc *NamingClient) CloseClient() {
+	introspection.Deregister(sc)
+	sc.closeLeases()
 	sc.serviceProxy.CloseClient()
 	sc.cancel()
 }
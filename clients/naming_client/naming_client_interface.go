@@ -17,13 +17,17 @@
 package naming_client
 
 import (
+	"context"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client/routing"
 	"github.com/nacos-group/nacos-sdk-go/v2/model"
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
 )
 
-//go:generate mockgen -destination ../../mock/mock_service_client_interface.go -package mock -source=./service_client_interface.go
+//go:generate mockgen -destination ../../mock/mock_naming_client_interface.go -package mock -source=./naming_client_interface.go
 
-// INamingClient interface for naming client
+// INamingClient interface for naming client, it's implemented by NamingClient and returned by
+// clients.NewNamingClient, so the applications can mock or decorate it without depending on NamingClient
 type INamingClient interface {
 
 	// RegisterInstance use to register instance
@@ -35,14 +39,32 @@ type INamingClient interface {
 	// Metadata  optional
 	// ClusterName  optional,default:DEFAULT
 	// ServiceName require
-	// GroupName optional,default:DEFAULT_GROUP
+	// GroupName optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
 	// Ephemeral optional
 	RegisterInstance(param vo.RegisterInstanceParam) (bool, error)
 
+	// RegisterInstanceWithTTL use to register ephemeral instance bound to a lease, the instance is deregistered
+	// when the lease is not renewed within the ttl or the connection to server is lost, and it's not registered
+	// again on reconnection. It's suitable for the singleton registrations which require stronger guarantee
+	// TTL require,the lease must be renewed within it
+	// AutoRenew optional,renew the lease by sdk, otherwise Lease.Renew must be called by the caller
+	// OnLeaseLost optional,called once when the lease is lost
+	// the other fields are the same as RegisterInstance
+	RegisterInstanceWithTTL(param vo.RegisterInstanceWithTTLParam) (*Lease, error)
+
+	// NewMutex use to create the coarse-grained distributed mutex on the registration of ephemeral instances,
+	// the holder and the fencing token returned on locking are kept in a record config written with compare-and-swap, see Mutex
+	// Name require,the serviceName of the instances registered by contenders
+	// Store require,the config client keeping the record, its dataId is Name with .mutex suffix
+	// GroupName optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
+	// TTL optional,the ttl of the lease of registration, default is 15s
+	// OnLost optional,called once when the held mutex is lost
+	NewMutex(param vo.MutexParam) (*Mutex, error)
+
 	// BatchRegisterInstance use to batch register instance
 	// ClusterName  optional,default:DEFAULT
 	// ServiceName require
-	// GroupName optional,default:DEFAULT_GROUP
+	// GroupName optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
 	// Instances require,batch register instance list (serviceName, groupName in instances do not need to be set)
 	BatchRegisterInstance(param vo.BatchRegisterInstanceParam) (bool, error)
 
@@ -52,7 +74,7 @@ type INamingClient interface {
 	// Tenant optional
 	// Cluster optional,default:DEFAULT
 	// ServiceName  require
-	// GroupName  optional,default:DEFAULT_GROUP
+	// GroupName  optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
 	// Ephemeral optional
 	DeregisterInstance(param vo.DeregisterInstanceParam) (bool, error)
 
@@ -65,26 +87,33 @@ type INamingClient interface {
 	// Metadata  optional
 	// ClusterName  optional,default:DEFAULT
 	// ServiceName require
-	// GroupName optional,default:DEFAULT_GROUP
+	// GroupName optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
 	// Ephemeral optional
 	UpdateInstance(param vo.UpdateInstanceParam) (bool, error)
 
 	// GetService use to get service
 	// ServiceName require
 	// Clusters optional,default:DEFAULT
-	// GroupName optional,default:DEFAULT_GROUP
+	// GroupName optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
 	GetService(param vo.GetServiceParam) (model.Service, error)
 
+	// CompareAndGetService use to get service only if its epoch is not lastEpoch, changed is false and the service has
+	// no instances when it's not changed
+	// ServiceName require
+	// Clusters optional,default:DEFAULT
+	// GroupName optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
+	CompareAndGetService(param vo.GetServiceParam, lastEpoch uint64) (model.Service, bool, error)
+
 	// SelectAllInstances return all instances,include healthy=false,enable=false,weight<=0
 	// ServiceName require
 	// Clusters optional,default:DEFAULT
-	// GroupName optional,default:DEFAULT_GROUP
+	// GroupName optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
 	SelectAllInstances(param vo.SelectAllInstancesParam) ([]model.Instance, error)
 
 	// SelectInstances only return the instances of healthy=${HealthyOnly},enable=true and weight>0
 	// ServiceName require
 	// Clusters optional,default:DEFAULT
-	// GroupName optional,default:DEFAULT_GROUP
+	// GroupName optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
 	// HealthyOnly optional
 	SelectInstances(param vo.SelectInstancesParam) ([]model.Instance, error)
 
@@ -92,26 +121,65 @@ type INamingClient interface {
 	// And the instance should be health=true,enable=true and weight>0
 	// ServiceName require
 	// Clusters optional,default:DEFAULT
-	// GroupName optional,default:DEFAULT_GROUP
+	// GroupName optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
 	SelectOneHealthyInstance(param vo.SelectOneHealthInstanceParam) (*model.Instance, error)
 
 	// Subscribe use to subscribe service change event
 	// ServiceName require
 	// Clusters optional,default:DEFAULT
-	// GroupName optional,default:DEFAULT_GROUP
+	// GroupName optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
 	// SubscribeCallback require
 	Subscribe(param *vo.SubscribeParam) error
 
 	// Unsubscribe use to unsubscribe service change event
 	// ServiceName require
 	// Clusters optional,default:DEFAULT
-	// GroupName optional,default:DEFAULT_GROUP
+	// GroupName optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
 	// SubscribeCallback require
 	Unsubscribe(param *vo.SubscribeParam) error
 
+	// Watch use to stream instance changes of the service through the returned channel until ctx is done,
+	// each event carries the current instances and the diff against the previous event
+	// ServiceName require
+	// Clusters optional,default:DEFAULT
+	// GroupName optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
+	Watch(ctx context.Context, param vo.WatchParam) (<-chan model.InstanceChangeEvent, error)
+
+	// WaitForService use to block until at least MinHealthy healthy instances of the service are known, such as before
+	// reporting ready when the service is a dependency, it fails when ctx is done first
+	// ServiceName require
+	// Clusters optional,default:DEFAULT
+	// GroupName optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
+	// MinHealthy optional,default:1
+	WaitForService(ctx context.Context, param vo.WaitForServiceParam) ([]model.Instance, error)
+
+	// RestoreSubscriptions use to subscribe the services persisted by the previous process again, PersistSubscriptions must be set
+	// param is the template of the subscriptions, SubscribeCallback or ServiceCallback require
+	// the returned params can be passed to Unsubscribe
+	RestoreSubscriptions(param vo.SubscribeParam) ([]*vo.SubscribeParam, error)
+
 	// GetAllServicesInfo use to get all service info by page
 	GetAllServicesInfo(param vo.GetAllServiceInfoParam) (model.ServiceList, error)
 
+	// ListGroups use to list the groups having services in the namespace, the namespace of client is used when it's empty
+	ListGroups(namespace string) ([]string, error)
+
+	// SetRouter use to set the router filtering instances by the labels passed to
+	// SelectAllInstances, SelectInstances, SelectOneHealthyInstance and Subscribe
+	SetRouter(router *routing.Router)
+
+	// GetRegistrations return the state of the instances registered by the client, the ephemeral instances are
+	// degraded when the connection keeping them alive is lost, and lost when it's not recovered within
+	// RegistrationHooks.LostTimeout, the deregistered instances are not returned
+	GetRegistrations() []model.Registration
+
+	// GroupOf return the group the client uses when groupName is empty, it's DefaultServiceGroup of ClientConfig
+	// or DEFAULT_GROUP, a non-empty groupName is returned as it is
+	GroupOf(groupName string) string
+
+	// InstanceId return the unique id of the client instance, it is the key of the client in introspection handler
+	InstanceId() string
+
 	//CloseClient close the GRPC client
 	CloseClient()
 }
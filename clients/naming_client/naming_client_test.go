@@ -17,15 +17,32 @@
 package naming_client
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/nacos-group/nacos-sdk-go/v2/common/http_agent"
 
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/nacos_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client/balancer"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client/routing"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client/subset"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/audit"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/quota"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc"
 	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
 	"github.com/nacos-group/nacos-sdk-go/v2/vo"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
 )
 
 var clientConfigTest = *constant.NewClientConfig(
@@ -140,6 +157,29 @@ func TestNamingProxy_DeregisterService_WithGroupName(t *testing.T) {
 	assert.Equal(t, true, success)
 }
 
+func TestNamingClient_AuditInstanceOperations(t *testing.T) {
+	client := NewTestNamingClient()
+	var records []audit.Record
+	client.auditor = audit.NewRecorder(audit.SinkFunc(func(record audit.Record) error {
+		records = append(records, record)
+		return nil
+	}), "nacos", "app", nil)
+
+	_, err := client.RegisterInstance(vo.RegisterInstanceParam{ServiceName: "DEMO", Ip: "10.0.0.10", Port: 80, Ephemeral: true})
+	assert.Nil(t, err)
+	_, err = client.DeregisterInstance(vo.DeregisterInstanceParam{ServiceName: "DEMO", Ip: "10.0.0.10", Port: 80, Ephemeral: true})
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, len(records))
+	assert.Equal(t, audit.OpRegisterInstance, records[0].Op)
+	assert.Equal(t, constant.DEFAULT_GROUP, records[0].Group)
+	assert.Equal(t, "DEMO", records[0].Key)
+	assert.Equal(t, "10.0.0.10:80", records[0].Target)
+	assert.Equal(t, audit.ResultOk, records[0].Result)
+	assert.Equal(t, audit.OpDeregisterInstance, records[1].Op)
+	assert.Equal(t, "10.0.0.10:80", records[1].Target)
+}
+
 func TestNamingClient_SelectOneHealthyInstance_SameWeight(t *testing.T) {
 	services := model.Service{
 		Name:        "DEFAULT_GROUP@@DEMO",
@@ -452,3 +492,1069 @@ func BenchmarkNamingClient_SelectOneHealthyInstances(b *testing.B) {
 	}
 
 }
+
+func TestNamingClient_Watch(t *testing.T) {
+	client := NewTestNamingClient()
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := client.Watch(ctx, vo.WatchParam{ServiceName: "watch"})
+	assert.Nil(t, err)
+
+	instance := model.Instance{Ip: "10.0.0.1", Port: 80, Weight: 1, Healthy: true, Enable: true}
+	client.serviceInfoHolder.ProcessService(&model.Service{
+		Name:        "watch",
+		GroupName:   constant.DEFAULT_GROUP,
+		LastRefTime: 1,
+		Hosts:       []model.Instance{instance},
+	})
+	event := <-events
+	assert.Equal(t, []model.Instance{instance}, event.Added)
+	assert.Empty(t, event.Removed)
+
+	changed := instance
+	changed.Weight = 2
+	added := model.Instance{Ip: "10.0.0.2", Port: 80, Weight: 1, Healthy: true, Enable: true}
+	client.serviceInfoHolder.ProcessService(&model.Service{
+		Name:        "watch",
+		GroupName:   constant.DEFAULT_GROUP,
+		LastRefTime: 2,
+		Hosts:       []model.Instance{changed, added},
+	})
+	event = <-events
+	assert.Equal(t, []model.Instance{added}, event.Added)
+	assert.Equal(t, []model.Instance{changed}, event.Modified)
+	assert.Len(t, event.Instances, 2)
+
+	cancel()
+	for range events {
+	}
+
+	_, err = client.Watch(context.Background(), vo.WatchParam{})
+	assert.Error(t, err)
+}
+
+func TestNamingClient_Snapshot(t *testing.T) {
+	client := NewTestNamingClient()
+	assert.NotEmpty(t, client.InstanceId())
+	client.serviceInfoHolder.ProcessService(&model.Service{
+		Name:        "snapshot",
+		GroupName:   constant.DEFAULT_GROUP,
+		LastRefTime: 1,
+		Hosts:       []model.Instance{{Ip: "10.0.0.1", Port: 80, Healthy: true}, {Ip: "10.0.0.2", Port: 80}},
+	})
+	snapshot := client.Snapshot().(namingClientSnapshot)
+	assert.Equal(t, client.InstanceId(), snapshot.InstanceId)
+	assert.True(t, snapshot.ServerHealthy)
+	var found bool
+	for _, s := range snapshot.Services {
+		if s.Key == "DEFAULT_GROUP@@snapshot" {
+			found = true
+			assert.Equal(t, 2, s.HostCount)
+			assert.Equal(t, 1, s.HealthyCount)
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestNamingClient_SelectOneHealthyInstance_Balancer(t *testing.T) {
+	type ctxKey struct{}
+	balancer.Register("test-by-ctx", balancer.Func(func(ctx context.Context, instances []model.Instance) (model.Instance, error) {
+		ip, _ := ctx.Value(ctxKey{}).(string)
+		for _, instance := range instances {
+			if instance.Ip == ip {
+				return instance, nil
+			}
+		}
+		return instances[0], nil
+	}))
+	balancer.Register("test-last", balancer.Func(func(ctx context.Context, instances []model.Instance) (model.Instance, error) {
+		return instances[len(instances)-1], nil
+	}))
+	client := NewTestNamingClient()
+	client.serviceInfoHolder.ProcessService(&model.Service{
+		Name:        "balancer",
+		GroupName:   constant.DEFAULT_GROUP,
+		LastRefTime: 1,
+		Hosts: []model.Instance{
+			{Ip: "10.0.0.1", Port: 80, Weight: 1, Healthy: true, Enable: true, Metadata: map[string]string{constant.LOAD_BALANCER: "test-last"}},
+			{Ip: "10.0.0.2", Port: 80, Weight: 1, Healthy: true, Enable: true},
+			{Ip: "10.0.0.3", Port: 80, Weight: 1, Healthy: true, Enable: true},
+		},
+	})
+
+	instance, err := client.SelectOneHealthyInstance(vo.SelectOneHealthInstanceParam{
+		ServiceName: "balancer",
+		Balancer:    "test-by-ctx",
+		Context:     context.WithValue(context.Background(), ctxKey{}, "10.0.0.2"),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.2", instance.Ip)
+
+	instance, err = client.SelectOneHealthyInstance(vo.SelectOneHealthInstanceParam{ServiceName: "balancer"})
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.3", instance.Ip)
+
+	_, err = client.SelectOneHealthyInstance(vo.SelectOneHealthInstanceParam{ServiceName: "balancer", Balancer: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestNamingClient_SelectOneHealthyInstance_ServiceLoadBalancers(t *testing.T) {
+	balancer.Register("test-first", balancer.Func(func(ctx context.Context, instances []model.Instance) (model.Instance, error) {
+		return instances[0], nil
+	}))
+	balancer.Register("test-final", balancer.Func(func(ctx context.Context, instances []model.Instance) (model.Instance, error) {
+		return instances[len(instances)-1], nil
+	}))
+	clientConfig := clientConfigTest
+	clientConfig.LoadBalancer = "test-first"
+	clientConfig.ServiceLoadBalancers = map[string]string{
+		"DEFAULT_GROUP@@balancer-a": "test-final",
+		"group-b@@balancer-b":       "test-final",
+	}
+	nc := nacos_client.NacosClient{}
+	_ = nc.SetServerConfig([]constant.ServerConfig{serverConfigTest})
+	_ = nc.SetClientConfig(clientConfig)
+	_ = nc.SetHttpAgent(&http_agent.HttpAgent{})
+	client, _ := NewNamingClient(&nc)
+	client.serviceProxy = &MockNamingProxy{}
+	hosts := []model.Instance{
+		{Ip: "10.0.0.1", Port: 80, Weight: 1, Healthy: true, Enable: true},
+		{Ip: "10.0.0.2", Port: 80, Weight: 1, Healthy: true, Enable: true},
+	}
+	for _, service := range []model.Service{
+		{Name: "balancer-a", GroupName: constant.DEFAULT_GROUP, LastRefTime: 1, Hosts: hosts},
+		{Name: "balancer-b", GroupName: "group-b", LastRefTime: 1, Hosts: hosts},
+		{Name: "balancer-c", GroupName: constant.DEFAULT_GROUP, LastRefTime: 1, Hosts: hosts},
+	} {
+		service := service
+		client.serviceInfoHolder.ProcessService(&service)
+	}
+
+	for _, c := range []struct {
+		param vo.SelectOneHealthInstanceParam
+		ip    string
+	}{
+		{vo.SelectOneHealthInstanceParam{ServiceName: "balancer-a"}, "10.0.0.2"},
+		{vo.SelectOneHealthInstanceParam{ServiceName: "balancer-b", GroupName: "group-b"}, "10.0.0.2"},
+		// the services without their own balancer use ClientConfig.LoadBalancer
+		{vo.SelectOneHealthInstanceParam{ServiceName: "balancer-c"}, "10.0.0.1"},
+	} {
+		instance, err := client.SelectOneHealthyInstance(c.param)
+		assert.Nil(t, err)
+		assert.Equal(t, c.ip, instance.Ip, c.param.ServiceName)
+	}
+
+	// the name from server carrying the group is used as the key as is, the empty group is the default one
+	assert.Equal(t, "group-b@@balancer-b", client.serviceBalancerKey(model.Service{Name: "group-b@@balancer-b", GroupName: "group-b"}))
+	assert.Equal(t, "DEFAULT_GROUP@@balancer-a", client.serviceBalancerKey(model.Service{Name: "balancer-a"}))
+}
+
+func TestNamingClient_Router(t *testing.T) {
+	client := NewTestNamingClient()
+	router := routing.NewRouter()
+	assert.Nil(t, router.Update(`{"rules": [{"match": {"user": "beta"}, "selector": {"version": "v2"}}]}`))
+	client.SetRouter(router)
+	hosts := []model.Instance{
+		{Ip: "10.0.0.1", Port: 80, Weight: 1, Healthy: true, Enable: true, Metadata: map[string]string{"version": "v1"}},
+		{Ip: "10.0.0.2", Port: 80, Weight: 1, Healthy: true, Enable: true, Metadata: map[string]string{"version": "v2"}},
+	}
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "router", GroupName: constant.DEFAULT_GROUP, LastRefTime: 1, Hosts: hosts})
+
+	instances, err := client.SelectInstances(vo.SelectInstancesParam{ServiceName: "router", HealthyOnly: true, Labels: map[string]string{"user": "beta"}})
+	assert.Nil(t, err)
+	assert.Equal(t, hosts[1:], instances)
+	instances, err = client.SelectAllInstances(vo.SelectAllInstancesParam{ServiceName: "router"})
+	assert.Nil(t, err)
+	assert.Len(t, instances, 2)
+	instance, err := client.SelectOneHealthyInstance(vo.SelectOneHealthInstanceParam{ServiceName: "router", Labels: map[string]string{"user": "beta"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.2", instance.Ip)
+
+	var routed []model.Instance
+	param := &vo.SubscribeParam{
+		ServiceName: "router",
+		Labels:      map[string]string{"user": "beta"},
+		SubscribeCallback: func(services []model.Instance, err error) {
+			routed = services
+		},
+	}
+	assert.Nil(t, client.Subscribe(param))
+	changed := append([]model.Instance{}, hosts...)
+	changed[0].Weight = 2
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "router", GroupName: constant.DEFAULT_GROUP, LastRefTime: 2, Hosts: changed})
+	assert.Len(t, routed, 1)
+	assert.Equal(t, "10.0.0.2", routed[0].Ip)
+	assert.Nil(t, client.Unsubscribe(param))
+	_, ok := client.routedCallbacks.Load(&param.SubscribeCallback)
+	assert.False(t, ok)
+}
+
+type groupNamingProxy struct {
+	MockNamingProxy
+	namespaces []string
+	groups     []string
+}
+
+func (m *groupNamingProxy) ListGroups(namespaceId string) ([]string, error) {
+	m.namespaces = append(m.namespaces, namespaceId)
+	return []string{"DEFAULT_GROUP", "gateway"}, nil
+}
+
+func (m *groupNamingProxy) RegisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	m.groups = append(m.groups, groupName)
+	return true, nil
+}
+
+func TestNamingClient_ListGroups(t *testing.T) {
+	client := NewTestNamingClient()
+	_, err := client.ListGroups("")
+	assert.Error(t, err)
+
+	proxy := &groupNamingProxy{}
+	client.serviceProxy = proxy
+	groups, err := client.ListGroups("")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"DEFAULT_GROUP", "gateway"}, groups)
+	_, _ = client.ListGroups("tenant")
+	assert.Equal(t, []string{constant.DEFAULT_NAMESPACE_ID, "tenant"}, proxy.namespaces)
+}
+
+func TestNamingClient_DefaultServiceGroup(t *testing.T) {
+	client := NewTestNamingClient()
+	clientConfig, _ := client.GetClientConfig()
+	clientConfig.DefaultServiceGroup = "gateway"
+	_ = client.SetClientConfig(clientConfig)
+	proxy := &groupNamingProxy{}
+	client.serviceProxy = proxy
+
+	_, err := client.RegisterInstance(vo.RegisterInstanceParam{ServiceName: "DEMO", Ip: "10.0.0.10", Port: 80, Ephemeral: true})
+	assert.Nil(t, err)
+	_, err = client.RegisterInstance(vo.RegisterInstanceParam{ServiceName: "DEMO", GroupName: "pay", Ip: "10.0.0.10", Port: 80, Ephemeral: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"gateway", "pay"}, proxy.groups)
+
+	// the subscription is keyed by the default group, so it's found by Unsubscribe without group either
+	param := &vo.SubscribeParam{ServiceName: "DEMO", SubscribeCallback: func(services []model.Instance, err error) {}}
+	assert.Nil(t, client.Subscribe(param))
+	assert.Equal(t, "gateway", param.GroupName)
+	assert.True(t, client.serviceInfoHolder.HasCallback(util.GetGroupName("DEMO", "gateway"), ""))
+	param.GroupName = ""
+	assert.Nil(t, client.Unsubscribe(param))
+	assert.False(t, client.serviceInfoHolder.HasCallback(util.GetGroupName("DEMO", "gateway"), ""))
+}
+
+type serviceInfoNamingProxy struct {
+	MockNamingProxy
+}
+
+func (m *serviceInfoNamingProxy) QueryServiceInfo(serviceName, groupName string) (model.ServiceInfo, error) {
+	return model.ServiceInfo{Name: serviceName, Group: groupName, Metadata: map[string]string{"owner": "pay"}, ProtectThreshold: 0.5}, nil
+}
+
+func TestNamingClient_SubscribeServiceCallback(t *testing.T) {
+	client := NewTestNamingClient()
+	client.serviceProxy = &serviceInfoNamingProxy{}
+	var services []model.Service
+	param := &vo.SubscribeParam{
+		ServiceName: "governed",
+		ServiceCallback: func(service model.Service, err error) {
+			services = append(services, service)
+		},
+	}
+	assert.Nil(t, client.Subscribe(param))
+	hosts := []model.Instance{{Ip: "10.0.0.1", Port: 80, Weight: 1, Healthy: true, Enable: true}}
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "governed", GroupName: constant.DEFAULT_GROUP, LastRefTime: 1, Hosts: hosts})
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "governed", GroupName: constant.DEFAULT_GROUP, LastRefTime: 2, Hosts: hosts, ReachProtectionThreshold: true})
+	assert.Len(t, services, 2)
+	assert.Equal(t, map[string]string{"owner": "pay"}, services[0].Metadata)
+	assert.Equal(t, 0.5, services[0].ProtectThreshold)
+	assert.Equal(t, hosts, services[0].Hosts)
+	assert.True(t, services[1].ReachProtectionThreshold)
+
+	assert.Nil(t, client.Unsubscribe(param))
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "governed", GroupName: constant.DEFAULT_GROUP, LastRefTime: 3, Hosts: hosts})
+	assert.Len(t, services, 2)
+}
+
+type countingServiceInfoNamingProxy struct {
+	MockNamingProxy
+	queries int32
+}
+
+func (m *countingServiceInfoNamingProxy) QueryServiceInfo(serviceName, groupName string) (model.ServiceInfo, error) {
+	owner := "owner-" + strconv.Itoa(int(atomic.AddInt32(&m.queries, 1)))
+	return model.ServiceInfo{Name: serviceName, Group: groupName, Metadata: map[string]string{"owner": owner}}, nil
+}
+
+func TestNamingClient_ServiceCallbackCachesServiceInfo(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &countingServiceInfoNamingProxy{}
+	client.serviceProxy = proxy
+	fake := clock.NewFakeClock(time.Now())
+	client.serviceInfos = newServiceInfoCache(fake, client.queryServiceInfo)
+	var (
+		mux    sync.Mutex
+		owners []string
+	)
+	param := &vo.SubscribeParam{
+		ServiceName: "governed",
+		ServiceCallback: func(service model.Service, err error) {
+			mux.Lock()
+			defer mux.Unlock()
+			owners = append(owners, service.Metadata["owner"])
+		},
+	}
+	assert.Nil(t, client.Subscribe(param))
+	push := func(version int) {
+		hosts := []model.Instance{{Ip: "10.0.0.1", Port: 80, Weight: float64(version), Healthy: true, Enable: true}}
+		client.serviceInfoHolder.ProcessService(&model.Service{Name: "governed", GroupName: constant.DEFAULT_GROUP, LastRefTime: uint64(version), Hosts: hosts})
+	}
+	for i := 1; i <= 3; i++ {
+		push(i)
+	}
+	// queried once for the pushes within ttl
+	assert.Equal(t, int32(1), atomic.LoadInt32(&proxy.queries))
+
+	// the stale settings are delivered while they're refreshed in background
+	fake.Advance(serviceInfoTtl)
+	push(4)
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&proxy.queries) == 2
+	}, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool {
+		info, _ := client.serviceInfos.get(util.GetGroupName("governed", constant.DEFAULT_GROUP), "governed", constant.DEFAULT_GROUP)
+		return info.Metadata["owner"] == "owner-2"
+	}, time.Second, time.Millisecond)
+	push(5)
+	mux.Lock()
+	assert.Equal(t, []string{"owner-1", "owner-1", "owner-1", "owner-1", "owner-2"}, owners)
+	mux.Unlock()
+}
+
+func TestNamingClient_RestoreSubscriptions(t *testing.T) {
+	dir := t.TempDir()
+	client := NewTestNamingClient()
+	client.subscriptions = newServiceSubscriptions(true, dir, constant.DEFAULT_NAMESPACE_ID)
+	callback := func(services []model.Instance, err error) {}
+	first := &vo.SubscribeParam{ServiceName: "first", Clusters: []string{"a"}, SubscribeCallback: callback}
+	second := &vo.SubscribeParam{ServiceName: "second", GroupName: "g", SubscribeCallback: callback}
+	assert.Nil(t, client.Subscribe(first))
+	assert.Nil(t, client.Subscribe(second))
+	assert.Nil(t, client.Unsubscribe(second))
+	client.CloseClient()
+
+	restarted := NewTestNamingClient()
+	defer restarted.CloseClient()
+	restarted.subscriptions = newServiceSubscriptions(true, dir, constant.DEFAULT_NAMESPACE_ID)
+	_, err := restarted.RestoreSubscriptions(vo.SubscribeParam{})
+	assert.NotNil(t, err)
+	restored, err := restarted.RestoreSubscriptions(vo.SubscribeParam{SubscribeCallback: callback})
+	assert.Nil(t, err)
+	assert.Len(t, restored, 1)
+	assert.Equal(t, "first", restored[0].ServiceName)
+	assert.Equal(t, constant.DEFAULT_GROUP, restored[0].GroupName)
+	assert.Equal(t, []string{"a"}, restored[0].Clusters)
+	assert.True(t, restarted.serviceInfoHolder.IsSubscribed(constant.DEFAULT_GROUP+constant.SERVICE_INFO_SPLITER+"first", "a"))
+	assert.Nil(t, restarted.Unsubscribe(restored[0]))
+	assert.False(t, restarted.serviceInfoHolder.HasCallback(constant.DEFAULT_GROUP+constant.SERVICE_INFO_SPLITER+"first", "a"))
+	subscriptions, err := restarted.subscriptions.load()
+	assert.Nil(t, err)
+	assert.Empty(t, subscriptions)
+}
+
+type resubscribeNamingProxy struct {
+	MockNamingProxy
+	resubscribed []string
+}
+
+func (m *resubscribeNamingProxy) Resubscribe(serviceName, groupName, clusters string) (model.Service, error) {
+	m.resubscribed = append(m.resubscribed, groupName+constant.SERVICE_INFO_SPLITER+serviceName)
+	return model.Service{Name: serviceName, GroupName: groupName, Clusters: clusters}, nil
+}
+
+func TestNamingClient_Resubscribe(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &resubscribeNamingProxy{}
+	client.serviceProxy = proxy
+	callback := func(services []model.Instance, err error) {}
+	assert.Nil(t, client.Subscribe(&vo.SubscribeParam{ServiceName: "first", SubscribeCallback: callback}))
+	assert.Nil(t, client.Subscribe(&vo.SubscribeParam{ServiceName: "second", GroupName: "g", SubscribeCallback: callback}))
+
+	count, err := client.Resubscribe("", "g")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []string{"g@@second"}, proxy.resubscribed)
+
+	count, err = client.Resubscribe("", "")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestParseLabelSelector(t *testing.T) {
+	selector, err := parseLabelSelector("version=v2, zone in (a, b),env!=test,canary,!deprecated")
+	assert.Nil(t, err)
+	assert.Len(t, selector, 5)
+	assert.True(t, selector.matches(map[string]string{"version": "v2", "zone": "b", "canary": "true"}))
+	assert.False(t, selector.matches(map[string]string{"version": "v2", "zone": "c", "canary": "true"}))
+	assert.False(t, selector.matches(map[string]string{"version": "v2", "zone": "a", "canary": "true", "env": "test"}))
+	assert.False(t, selector.matches(map[string]string{"version": "v2", "zone": "a"}))
+	assert.False(t, selector.matches(map[string]string{"version": "v2", "zone": "a", "canary": "true", "deprecated": "true"}))
+
+	selector, err = parseLabelSelector("zone notin (a,b)")
+	assert.Nil(t, err)
+	assert.True(t, selector.matches(map[string]string{"zone": "c"}))
+	assert.False(t, selector.matches(map[string]string{"zone": "a"}))
+
+	_, err = parseLabelSelector("zone in a,b")
+	assert.NotNil(t, err)
+	selector, err = parseLabelSelector("")
+	assert.Nil(t, err)
+	assert.Empty(t, selector)
+}
+
+type selectorNamingProxy struct {
+	MockNamingProxy
+	selector string
+}
+
+func (m *selectorNamingProxy) QueryInstancesWithSelector(serviceName, groupName, clusters string, healthyOnly bool, selector string) (*model.Service, error) {
+	m.selector = selector
+	// behaves like a server ignoring the selector
+	return &model.Service{Name: serviceName, GroupName: groupName, Hosts: []model.Instance{
+		{Ip: "10.0.0.1", Port: 80, Weight: 1, Healthy: true, Enable: true, Metadata: map[string]string{"version": "v1"}},
+		{Ip: "10.0.0.2", Port: 80, Weight: 1, Healthy: true, Enable: true, Metadata: map[string]string{"version": "v2"}},
+	}}, nil
+}
+
+func TestNamingClient_SelectInstancesWithSelector(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &selectorNamingProxy{}
+	client.serviceProxy = proxy
+	instances, err := client.SelectInstances(vo.SelectInstancesParam{ServiceName: "selector", HealthyOnly: true, Selector: "version=v2"})
+	assert.Nil(t, err)
+	assert.Equal(t, "version=v2", proxy.selector)
+	assert.Len(t, instances, 1)
+	assert.Equal(t, "10.0.0.2", instances[0].Ip)
+
+	instances, err = client.SelectAllInstances(vo.SelectAllInstancesParam{ServiceName: "selector", Selector: "version in (v1,v2)"})
+	assert.Nil(t, err)
+	assert.Len(t, instances, 2)
+
+	_, err = client.SelectAllInstances(vo.SelectAllInstancesParam{ServiceName: "selector", Selector: "version in v1"})
+	assert.NotNil(t, err)
+}
+
+type leaseNamingProxy struct {
+	MockNamingProxy
+	mux          sync.Mutex
+	registered   int
+	deregistered int
+	listeners    []rpc.IConnectionEventListener
+}
+
+func (m *leaseNamingProxy) RegisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.registered++
+	return true, nil
+}
+
+func (m *leaseNamingProxy) DeregisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.deregistered++
+	return true, nil
+}
+
+func (m *leaseNamingProxy) RegisterConnectionListener(listener rpc.IConnectionEventListener) {
+	m.listeners = append(m.listeners, listener)
+}
+
+func (m *leaseNamingProxy) disconnect() {
+	for _, listener := range m.listeners {
+		listener.OnDisConnect()
+	}
+}
+
+func (m *leaseNamingProxy) connect() {
+	for _, listener := range m.listeners {
+		listener.OnConnected()
+	}
+}
+
+func (m *leaseNamingProxy) counts() (int, int) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.registered, m.deregistered
+}
+
+func TestNamingClient_RegisterInstanceWithTTL(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &leaseNamingProxy{}
+	client.serviceProxy = proxy
+	lost := make(chan error, 1)
+	param := vo.RegisterInstanceWithTTLParam{
+		RegisterInstanceParam: vo.RegisterInstanceParam{ServiceName: "leader", Ip: "10.0.0.10", Port: 80, Weight: 1},
+		TTL:                   100 * time.Millisecond,
+		OnLeaseLost: func(param vo.RegisterInstanceParam, err error) {
+			lost <- err
+		},
+	}
+	_, err := client.RegisterInstanceWithTTL(vo.RegisterInstanceWithTTLParam{RegisterInstanceParam: param.RegisterInstanceParam})
+	assert.NotNil(t, err)
+
+	lease, err := client.RegisterInstanceWithTTL(param)
+	assert.Nil(t, err)
+	time.Sleep(60 * time.Millisecond)
+	assert.Nil(t, lease.Renew())
+	assert.True(t, lease.ExpireAt().After(time.Now().Add(50*time.Millisecond)))
+	assert.Equal(t, ErrLeaseExpired, <-lost)
+	<-lease.Done()
+	assert.Equal(t, ErrLeaseExpired, lease.Renew())
+	registered, deregistered := proxy.counts()
+	assert.Equal(t, 2, registered)
+	assert.Equal(t, 1, deregistered)
+
+	param.AutoRenew = true
+	lease, err = client.RegisterInstanceWithTTL(param)
+	assert.Nil(t, err)
+	time.Sleep(250 * time.Millisecond)
+	assert.Nil(t, lease.Err())
+	proxy.disconnect()
+	assert.Equal(t, ErrLeaseDisconnected, <-lost)
+
+	lease, err = client.RegisterInstanceWithTTL(param)
+	assert.Nil(t, err)
+	assert.Nil(t, lease.Revoke())
+	assert.Equal(t, ErrLeaseRevoked, lease.Err())
+	_, deregistered = proxy.counts()
+	assert.Equal(t, 3, deregistered)
+	assert.Empty(t, lost)
+}
+
+type mutexNamingProxy struct {
+	leaseNamingProxy
+	instances map[string]model.Instance
+}
+
+func (m *mutexNamingProxy) RegisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.instances[instance.ClusterName] = instance
+	return true, nil
+}
+
+func (m *mutexNamingProxy) DeregisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	delete(m.instances, instance.ClusterName)
+	return true, nil
+}
+
+func (m *mutexNamingProxy) Subscribe(serviceName, groupName, clusters string) (model.Service, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	service := model.Service{Name: serviceName, GroupName: groupName}
+	for _, instance := range m.instances {
+		service.Hosts = append(service.Hosts, instance)
+	}
+	return service, nil
+}
+
+// casConfigStore keeps the configs in memory and publishes them with compare-and-swap like server
+type casConfigStore struct {
+	mux      sync.Mutex
+	contents map[string]string
+}
+
+func (s *casConfigStore) GetConfig(param vo.ConfigParam) (string, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.contents[param.DataId], nil
+}
+
+func (s *casConfigStore) PublishConfig(param vo.ConfigParam) (bool, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	md5 := util.Md5(s.contents[param.DataId])
+	if len(md5) == 0 {
+		md5 = emptyContentMd5
+	}
+	if param.CasMd5 != md5 {
+		return false, nil
+	}
+	s.contents[param.DataId] = param.Content
+	return true, nil
+}
+
+func TestNamingClient_Mutex(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &mutexNamingProxy{instances: map[string]model.Instance{}}
+	client.serviceProxy = proxy
+	store := &casConfigStore{contents: map[string]string{}}
+	lost := make(chan error, 1)
+	newMutex := func(identity string) *Mutex {
+		mutex, err := client.NewMutex(vo.MutexParam{Name: "job", Store: store, Identity: identity, RetryInterval: 20 * time.Millisecond,
+			OnLost: func(name string, token int64, err error) {
+				lost <- err
+			}})
+		assert.Nil(t, err)
+		return mutex
+	}
+	_, err := client.NewMutex(vo.MutexParam{Name: "job"})
+	assert.NotNil(t, err)
+	first, second := newMutex("first"), newMutex("second")
+	assert.Equal(t, ErrMutexNotHeld, first.Unlock())
+
+	ctx := context.Background()
+	firstToken, acquired, err := first.TryLock(ctx)
+	assert.Nil(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, int64(1), firstToken)
+	assert.Equal(t, firstToken, first.Token())
+	_, acquired, err = second.TryLock(ctx)
+	assert.Nil(t, err)
+	assert.False(t, acquired)
+	assert.Len(t, proxy.instances, 1)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	_, err = second.Lock(timeoutCtx)
+	cancel()
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	tokens := make(chan int64)
+	go func() {
+		token, err := second.Lock(ctx)
+		assert.Nil(t, err)
+		tokens <- token
+	}()
+	time.Sleep(50 * time.Millisecond)
+	assert.Nil(t, first.Unlock())
+	secondToken := <-tokens
+	assert.Equal(t, firstToken+1, secondToken)
+	assert.Nil(t, first.Done())
+
+	// the holder finds out the mutex is taken by others, such as the one regarding it as dead during partition
+	done := second.Done()
+	store.mux.Lock()
+	store.contents["job"+mutexDataIdSuffix] = `{"holder":"intruder","owner":"intruder","token":3}`
+	store.mux.Unlock()
+	assert.Equal(t, ErrMutexTaken, <-lost)
+	<-done
+	assert.Equal(t, int64(0), second.Token())
+	assert.Equal(t, ErrMutexNotHeld, second.Unlock())
+
+	// the intruder without instance is regarded as dead, the token keeps increasing
+	token, err := first.Lock(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), token)
+	done = first.Done()
+	proxy.disconnect()
+	assert.Equal(t, ErrLeaseDisconnected, <-lost)
+	<-done
+	assert.Equal(t, ErrMutexNotHeld, first.Unlock())
+}
+
+type metadataNamingProxy struct {
+	MockNamingProxy
+	mux       sync.Mutex
+	metadatas []map[string]string
+}
+
+func (m *metadataNamingProxy) RegisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.metadatas = append(m.metadatas, instance.Metadata)
+	return true, nil
+}
+
+func (m *metadataNamingProxy) registered() []map[string]string {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return append([]map[string]string(nil), m.metadatas...)
+}
+
+func TestNamingClient_MetadataProvider(t *testing.T) {
+	client := NewTestNamingClient()
+	defer client.CloseClient()
+	proxy := &metadataNamingProxy{}
+	client.serviceProxy = proxy
+	var (
+		mux     sync.Mutex
+		version = "v1"
+	)
+	success, err := client.RegisterInstance(vo.RegisterInstanceParam{
+		ServiceName: "DEMO",
+		Ip:          "10.0.0.10",
+		Port:        80,
+		Weight:      1,
+		Metadata:    map[string]string{"zone": "a", "version": "v0"},
+		MetadataProvider: func() map[string]string {
+			mux.Lock()
+			defer mux.Unlock()
+			return map[string]string{"version": version}
+		},
+		MetadataRefreshInterval: 10 * time.Millisecond,
+	})
+	assert.Nil(t, err)
+	assert.True(t, success)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, []map[string]string{{"zone": "a", "version": "v1"}}, proxy.registered())
+
+	mux.Lock()
+	version = "v2"
+	mux.Unlock()
+	assert.Eventually(t, func() bool {
+		return len(proxy.registered()) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, map[string]string{"zone": "a", "version": "v2"}, proxy.registered()[1])
+
+	_, err = client.DeregisterInstance(vo.DeregisterInstanceParam{ServiceName: "DEMO", Ip: "10.0.0.10", Port: 80})
+	assert.Nil(t, err)
+	mux.Lock()
+	version = "v3"
+	mux.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, proxy.registered(), 2)
+}
+
+type queryNamingProxy struct {
+	MockNamingProxy
+	mux     sync.Mutex
+	queries uint64
+}
+
+func (m *queryNamingProxy) QueryInstancesOfService(serviceName, groupName, clusters string, udpPort int, healthyOnly bool) (*model.Service, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.queries++
+	return &model.Service{Name: serviceName, LastRefTime: m.queries}, nil
+}
+
+func (m *queryNamingProxy) count() uint64 {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.queries
+}
+
+func TestNamingClient_GetServiceStaleWhileRevalidate(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &queryNamingProxy{}
+	client.serviceProxy = proxy
+	fake := clock.NewFakeClock(time.Now())
+	client.serviceQueryCache = newServiceQueryCache(time.Second, time.Second, fake, client.queryService)
+	param := vo.GetServiceParam{ServiceName: "swr"}
+
+	service, err := client.GetService(param)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), service.LastRefTime)
+	// served from cache within ttl
+	service, _ = client.GetService(param)
+	assert.Equal(t, uint64(1), service.LastRefTime)
+	assert.Equal(t, uint64(1), proxy.count())
+
+	// the stale one is served while it's refreshed in background
+	fake.Advance(1500 * time.Millisecond)
+	service, _ = client.GetService(param)
+	assert.Equal(t, uint64(1), service.LastRefTime)
+	assert.Eventually(t, func() bool {
+		service, _ = client.GetService(param)
+		return service.LastRefTime == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// queried synchronously once it's expired
+	fake.Advance(3 * time.Second)
+	service, _ = client.GetService(param)
+	assert.Equal(t, uint64(3), service.LastRefTime)
+	assert.Equal(t, uint64(3), proxy.count())
+}
+
+type resyncNamingProxy struct {
+	MockNamingProxy
+	hosts []model.Instance
+}
+
+func (m *resyncNamingProxy) QueryInstancesOfService(serviceName, groupName, clusters string, udpPort int, healthyOnly bool) (*model.Service, error) {
+	return &model.Service{Name: serviceName, GroupName: groupName, Clusters: clusters, Hosts: m.hosts, LastRefTime: 1}, nil
+}
+
+func TestNamingClient_CompareAndGetService(t *testing.T) {
+	client := NewTestNamingClient()
+	a := model.Instance{Ip: "10.0.0.1", Port: 80, Healthy: true, Enable: true}
+	b := model.Instance{Ip: "10.0.0.2", Port: 80, Healthy: true, Enable: true}
+	proxy := &resyncNamingProxy{hosts: []model.Instance{a}}
+	client.serviceProxy = proxy
+	fake := clock.NewFakeClock(time.Now())
+	client.serviceQueryCache = newServiceQueryCache(time.Second, 0, fake, client.queryService)
+	param := vo.GetServiceParam{ServiceName: "epoch"}
+
+	service, changed, err := client.CompareAndGetService(param, 0)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.Len(t, service.Hosts, 1)
+	epoch := service.Epoch
+	assert.True(t, epoch > 0)
+
+	// queried again with the same instances
+	fake.Advance(2 * time.Second)
+	service, changed, err = client.CompareAndGetService(param, epoch)
+	assert.Nil(t, err)
+	assert.False(t, changed)
+	assert.Nil(t, service.Hosts)
+	assert.Equal(t, epoch, service.Epoch)
+
+	proxy.hosts = []model.Instance{a, b}
+	fake.Advance(2 * time.Second)
+	service, changed, err = client.CompareAndGetService(param, epoch)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+	assert.Len(t, service.Hosts, 2)
+	assert.True(t, service.Epoch > epoch)
+}
+
+func TestNamingClient_Resync(t *testing.T) {
+	client := NewTestNamingClient()
+	a := model.Instance{Ip: "10.0.0.1", Port: 80, Healthy: true, Enable: true}
+	b := model.Instance{Ip: "10.0.0.2", Port: 80, Healthy: true, Enable: true}
+	client.serviceProxy = &resyncNamingProxy{hosts: []model.Instance{a, b}}
+	var (
+		mux      sync.Mutex
+		received []model.Instance
+		drifts   []model.ServiceDriftEvent
+	)
+	err := client.Subscribe(&vo.SubscribeParam{ServiceName: "resync", SubscribeCallback: func(services []model.Instance, err error) {
+		mux.Lock()
+		defer mux.Unlock()
+		received = services
+	}})
+	assert.Nil(t, err)
+	// the push removing b is missed, and the cached one is newer than the one queried
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "resync", GroupName: constant.DEFAULT_GROUP,
+		Hosts: []model.Instance{a}, LastRefTime: 5})
+
+	cfg := constant.ResyncConfig{OnDrift: func(event model.ServiceDriftEvent) {
+		drifts = append(drifts, event)
+	}}
+	client.resync(cfg, rate.NewLimiter(rate.Inf, 1))
+	assert.Len(t, drifts, 1)
+	assert.Equal(t, []model.Instance{b}, drifts[0].Added)
+	assert.Eventually(t, func() bool {
+		mux.Lock()
+		defer mux.Unlock()
+		return len(received) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// no drift once corrected
+	client.resync(cfg, rate.NewLimiter(rate.Inf, 1))
+	assert.Len(t, drifts, 1)
+}
+
+func TestNamingClient_SelectInstancesSubset(t *testing.T) {
+	client := NewTestNamingClient()
+	client.subsetKey = "client-a"
+	var hosts []model.Instance
+	for i := 0; i < 20; i++ {
+		hosts = append(hosts, model.Instance{Ip: fmt.Sprintf("10.0.0.%d", i), Port: 80, Weight: 1, Healthy: true, Enable: true})
+	}
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "subset", GroupName: constant.DEFAULT_GROUP, Hosts: hosts, LastRefTime: 1})
+
+	instances, err := client.SelectInstances(vo.SelectInstancesParam{ServiceName: "subset", HealthyOnly: true, SubsetSize: 5})
+	assert.Nil(t, err)
+	assert.Len(t, instances, 5)
+	assert.Equal(t, subset.Subset("client-a", hosts, 5), instances)
+	instance, err := client.SelectOneHealthyInstance(vo.SelectOneHealthInstanceParam{ServiceName: "subset", SubsetSize: 5})
+	assert.Nil(t, err)
+	assert.Contains(t, instances, *instance)
+}
+
+func TestNamingClient_RegistrationHooks(t *testing.T) {
+	client := NewTestNamingClient()
+	proxy := &leaseNamingProxy{}
+	client.serviceProxy = proxy
+	fake := clock.NewFakeClock(time.Now())
+	var (
+		states      []model.RegistrationState
+		heartbeats  int
+		deregisters int
+	)
+	client.registrations = newRegistrationTracker(&constant.RegistrationHooks{
+		BeforeRegister: func(serviceName, groupName string, instance *model.Instance) error {
+			if instance.Port == 0 {
+				return errors.New("port is not ready")
+			}
+			instance.Metadata["registered-by"] = "hook"
+			return nil
+		},
+		OnHeartbeatFail: func(serviceName, groupName string, instance model.Instance, err error) {
+			heartbeats++
+		},
+		OnDeregister: func(serviceName, groupName string, instance model.Instance, err error) {
+			deregisters++
+		},
+		OnStateChange: func(registration model.Registration) {
+			states = append(states, registration.State)
+		},
+		LostTimeout: time.Minute,
+	}, fake)
+
+	_, err := client.RegisterInstance(vo.RegisterInstanceParam{ServiceName: "demo", Ip: "10.0.0.10", Weight: 1})
+	assert.NotNil(t, err)
+	_, err = client.RegisterInstance(vo.RegisterInstanceParam{ServiceName: "demo", Ip: "10.0.0.10", Port: 80, Weight: 1, Ephemeral: true})
+	assert.Nil(t, err)
+	registrations := client.GetRegistrations()
+	assert.Len(t, registrations, 1)
+	assert.Equal(t, model.RegistrationRegistered, registrations[0].State)
+	assert.Equal(t, "hook", registrations[0].Instance.Metadata["registered-by"])
+
+	// degraded on disconnection and recovered on reconnection
+	proxy.disconnect()
+	assert.Equal(t, model.RegistrationDegraded, client.GetRegistrations()[0].State)
+	proxy.connect()
+	assert.Equal(t, model.RegistrationRegistered, client.GetRegistrations()[0].State)
+
+	// lost when the connection is not recovered in time
+	proxy.disconnect()
+	fake.Advance(time.Minute)
+	assert.Equal(t, model.RegistrationLost, client.GetRegistrations()[0].State)
+	assert.Equal(t, 2, heartbeats)
+
+	_, err = client.DeregisterInstance(vo.DeregisterInstanceParam{ServiceName: "demo", Ip: "10.0.0.10", Port: 80, Ephemeral: true})
+	assert.Nil(t, err)
+	assert.Len(t, client.GetRegistrations(), 0)
+	assert.Equal(t, 1, deregisters)
+	assert.Equal(t, []model.RegistrationState{model.RegistrationRegistered, model.RegistrationDegraded,
+		model.RegistrationRegistered, model.RegistrationDegraded, model.RegistrationLost}, states)
+}
+
+func TestNamingClient_RegistrationConflict(t *testing.T) {
+	client := NewTestNamingClient()
+	var conflicts []model.RegistrationConflict
+	client.registrations = newRegistrationTracker(&constant.RegistrationHooks{
+		OnConflict: func(conflict model.RegistrationConflict) {
+			conflicts = append(conflicts, conflict)
+		},
+	}, clock.Real)
+	_, err := client.RegisterInstance(vo.RegisterInstanceParam{ServiceName: "conflict", Ip: "10.0.0.10", Port: 80, Weight: 1,
+		Ephemeral: true, Metadata: map[string]string{"version": "1"}})
+	assert.Nil(t, err)
+	push := func(refTime uint64, version string) {
+		client.serviceInfoHolder.ProcessService(&model.Service{Name: "conflict", GroupName: constant.DEFAULT_GROUP, LastRefTime: refTime,
+			Hosts: []model.Instance{{Ip: "10.0.0.10", Port: 80, ClusterName: "DEFAULT", Metadata: map[string]string{"version": version}}}})
+	}
+	push(1, "1")
+	assert.Len(t, conflicts, 0)
+	push(2, "2")
+	assert.Len(t, conflicts, 1)
+	assert.Equal(t, "conflict", conflicts[0].ServiceName)
+	assert.Equal(t, "1", conflicts[0].Registered.Metadata["version"])
+	assert.Equal(t, "2", conflicts[0].Observed.Metadata["version"])
+
+	// the instance updated by the client itself is not a conflict
+	_, err = client.UpdateInstance(vo.UpdateInstanceParam{ServiceName: "conflict", Ip: "10.0.0.10", Port: 80, Weight: 1,
+		Ephemeral: true, Metadata: map[string]string{"version": "3"}})
+	assert.Nil(t, err)
+	push(3, "3")
+	push(4, "4")
+	push(5, "3")
+	push(6, "4")
+	assert.Len(t, conflicts, 3)
+}
+
+func TestNamingClient_ExcludeSelf(t *testing.T) {
+	client := NewTestNamingClient()
+	_, err := client.RegisterInstance(vo.RegisterInstanceParam{ServiceName: "peer", Ip: "10.0.0.1", Port: 80, Weight: 1, Ephemeral: true})
+	assert.Nil(t, err)
+	var subscribed []model.Instance
+	assert.Nil(t, client.Subscribe(&vo.SubscribeParam{ServiceName: "peer", ExcludeSelf: true,
+		SubscribeCallback: func(services []model.Instance, err error) {
+			subscribed = services
+		}}))
+	hosts := []model.Instance{
+		{Ip: "10.0.0.1", Port: 80, Weight: 1, Healthy: true, Enable: true, ClusterName: "DEFAULT"},
+		{Ip: "10.0.0.2", Port: 80, Weight: 1, Healthy: true, Enable: true, ClusterName: "DEFAULT"},
+	}
+	client.serviceInfoHolder.ProcessService(&model.Service{Name: "peer", GroupName: constant.DEFAULT_GROUP, LastRefTime: 1, Hosts: hosts})
+
+	instances, err := client.SelectAllInstances(vo.SelectAllInstancesParam{ServiceName: "peer"})
+	assert.Nil(t, err)
+	assert.Len(t, instances, 2)
+	instances, err = client.SelectAllInstances(vo.SelectAllInstancesParam{ServiceName: "peer", ExcludeSelf: true})
+	assert.Nil(t, err)
+	assert.Equal(t, hosts[1:], instances)
+	instances, err = client.SelectInstances(vo.SelectInstancesParam{ServiceName: "peer", HealthyOnly: true, ExcludeSelf: true})
+	assert.Nil(t, err)
+	assert.Equal(t, hosts[1:], instances)
+	for i := 0; i < 10; i++ {
+		instance, err := client.SelectOneHealthyInstance(vo.SelectOneHealthInstanceParam{ServiceName: "peer", ExcludeSelf: true})
+		assert.Nil(t, err)
+		assert.Equal(t, "10.0.0.2", instance.Ip)
+	}
+	assert.Eventually(t, func() bool {
+		return len(subscribed) == 1 && subscribed[0].Ip == "10.0.0.2"
+	}, time.Second, 10*time.Millisecond)
+	// the cached service is not modified
+	instances, err = client.SelectAllInstances(vo.SelectAllInstancesParam{ServiceName: "peer"})
+	assert.Nil(t, err)
+	assert.Len(t, instances, 2)
+}
+
+func TestNamingClient_SubscribeQuota(t *testing.T) {
+	client := NewTestNamingClient()
+	client.quota = quota.NewGuard(&constant.QuotaConfig{MaxSubscriptions: 1})
+	callback := func(services []model.Instance, err error) {}
+	param := &vo.SubscribeParam{ServiceName: "quota-1", SubscribeCallback: callback}
+	assert.Nil(t, client.Subscribe(param))
+	// subscribed again with another callback of the same service
+	another := &vo.SubscribeParam{ServiceName: "quota-1", SubscribeCallback: callback}
+	assert.Nil(t, client.Subscribe(another))
+
+	err := client.Subscribe(&vo.SubscribeParam{ServiceName: "quota-2", SubscribeCallback: callback})
+	exceeded, ok := nacos_error.IsQuotaExceeded(err)
+	assert.True(t, ok)
+	assert.Equal(t, 2, exceeded.Used)
+
+	assert.Nil(t, client.Unsubscribe(param))
+	assert.Nil(t, client.Unsubscribe(another))
+	assert.Nil(t, client.Subscribe(&vo.SubscribeParam{ServiceName: "quota-2", SubscribeCallback: callback}))
+}
+
+func TestNamingClient_WaitForService(t *testing.T) {
+	client := NewTestNamingClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.WaitForService(ctx, vo.WaitForServiceParam{ServiceName: "warmup", MinHealthy: 2})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	healthy := model.Instance{Ip: "10.0.0.1", Port: 80, Weight: 1, Healthy: true, Enable: true}
+	unhealthy := model.Instance{Ip: "10.0.0.2", Port: 80, Weight: 1, Healthy: false, Enable: true}
+	client.serviceInfoHolder.ProcessService(&model.Service{
+		Name:        "warmup",
+		GroupName:   constant.DEFAULT_GROUP,
+		LastRefTime: 1,
+		Hosts:       []model.Instance{healthy, unhealthy},
+	})
+	result := make(chan []model.Instance, 1)
+	go func() {
+		instances, err := client.WaitForService(context.Background(), vo.WaitForServiceParam{ServiceName: "warmup", MinHealthy: 2})
+		assert.Nil(t, err)
+		result <- instances
+	}()
+	select {
+	case <-result:
+		t.Fatal("returned before 2 healthy instances are known")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unhealthy.Healthy = true
+	client.serviceInfoHolder.ProcessService(&model.Service{
+		Name:        "warmup",
+		GroupName:   constant.DEFAULT_GROUP,
+		LastRefTime: 2,
+		Hosts:       []model.Instance{healthy, unhealthy},
+	})
+	assert.Len(t, <-result, 2)
+
+	// the cached service satisfies the wait at once
+	instances, err := client.WaitForService(context.Background(), vo.WaitForServiceParam{ServiceName: "warmup"})
+	assert.Nil(t, err)
+	assert.Len(t, instances, 2)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/monitor"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_server"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_request"
@@ -85,15 +86,25 @@ func (proxy *NamingGrpcProxy) requestToServer(request rpc_request.IRequest) (rpc
 	start := time.Now()
 	proxy.nacosServer.InjectSign(request, request.GetHeaders(), proxy.clientConfig)
 	proxy.nacosServer.InjectSecurityInfo(request.GetHeaders())
+	proxy.nacosServer.InjectIdentity(request.GetHeaders())
 	response, err := proxy.rpcClient.GetRpcClient().Request(request, int64(proxy.clientConfig.TimeoutMs))
 	monitor.GetConfigRequestMonitor(constant.GRPC, request.GetRequestType(), rpc_response.GetGrpcResponseStatusCode(response)).Observe(float64(time.Now().Nanosecond() - start.Nanosecond()))
 	return response, err
 }
 
+// RegisterConnectionListener register the listener notified when the connection to server changed
+func (proxy *NamingGrpcProxy) RegisterConnectionListener(listener rpc.IConnectionEventListener) {
+	proxy.rpcClient.GetRpcClient().RegisterConnectionListener(listener)
+}
+
 // RegisterInstance ...
 func (proxy *NamingGrpcProxy) RegisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
-	logger.Infof("register instance namespaceId:<%s>,serviceName:<%s> with instance:<%s>",
+	logger.With(logger.ServiceContext(proxy.clientConfig.NamespaceId, groupName, serviceName)).Infof("register instance namespaceId:<%s>,serviceName:<%s> with instance:<%s>",
 		proxy.clientConfig.NamespaceId, serviceName, util.ToJsonString(instance))
+	if !instance.Ephemeral {
+		// the persistent instance is kept by server regardless of the connection, so it's not redone
+		return proxy.persistentInstanceRequest(serviceName, groupName, "registerInstance", instance)
+	}
 	proxy.eventListener.CacheInstanceForRedo(serviceName, groupName, instance)
 	instanceRequest := rpc_request.NewInstanceRequest(proxy.clientConfig.NamespaceId, serviceName, groupName, "registerInstance", instance)
 	response, err := proxy.requestToServer(instanceRequest)
@@ -103,23 +114,64 @@ func (proxy *NamingGrpcProxy) RegisterInstance(serviceName string, groupName str
 	return response.IsSuccess(), err
 }
 
+func (proxy *NamingGrpcProxy) persistentInstanceRequest(serviceName, groupName, Type string, instance model.Instance) (bool, error) {
+	request := rpc_request.NewPersistentInstanceRequest(proxy.clientConfig.NamespaceId, serviceName, groupName, Type, instance)
+	response, err := proxy.requestToServer(request)
+	if err != nil {
+		return false, err
+	}
+	return response.IsSuccess(), err
+}
+
+// SupportPersistentInstance return whether the persistent instances are registered through grpc, it's decided
+// by the abilities of the server currently connected
+func (proxy *NamingGrpcProxy) SupportPersistentInstance() bool {
+	return proxy.rpcClient.GetRpcClient().UseFeature(rpc.FeaturePersistentInstanceByGrpc, rpc.AbilityPersistentInstanceByGrpc)
+}
+
+// Abilities return the result of ability negotiation with the server currently connected
+func (proxy *NamingGrpcProxy) Abilities() rpc.AbilityState {
+	return proxy.rpcClient.GetRpcClient().Abilities()
+}
+
 // BatchRegisterInstance ...
 func (proxy *NamingGrpcProxy) BatchRegisterInstance(serviceName string, groupName string, instances []model.Instance) (bool, error) {
-	logger.Infof("batch register instance namespaceId:<%s>,serviceName:<%s> with instance:<%s>",
+	logger.With(logger.ServiceContext(proxy.clientConfig.NamespaceId, groupName, serviceName)).Infof("batch register instance namespaceId:<%s>,serviceName:<%s> with instance:<%s>",
 		proxy.clientConfig.NamespaceId, serviceName, util.ToJsonString(instances))
 	proxy.eventListener.CacheInstancesForRedo(serviceName, groupName, instances)
-	batchInstanceRequest := rpc_request.NewBatchInstanceRequest(proxy.clientConfig.NamespaceId, serviceName, groupName, "batchRegisterInstance", instances)
-	response, err := proxy.requestToServer(batchInstanceRequest)
-	if err != nil {
-		return false, err
+	rpcClient := proxy.rpcClient.GetRpcClient()
+	if !rpcClient.FeatureDegraded(rpc.FeatureBatchRegisterInstance) {
+		batchInstanceRequest := rpc_request.NewBatchInstanceRequest(proxy.clientConfig.NamespaceId, serviceName, groupName, "batchRegisterInstance", instances)
+		response, err := proxy.requestToServer(batchInstanceRequest)
+		if _, unsupported := nacos_error.IsUnsupportedRequest(err); !unsupported {
+			if err != nil {
+				return false, err
+			}
+			return response.IsSuccess(), err
+		}
+		rpcClient.DegradeFeature(rpc.FeatureBatchRegisterInstance, err.Error())
 	}
-	return response.IsSuccess(), err
+	// the server earlier than 2.1 doesn't support batch, the instances are registered one by one
+	for _, instance := range instances {
+		instanceRequest := rpc_request.NewInstanceRequest(proxy.clientConfig.NamespaceId, serviceName, groupName, "registerInstance", instance)
+		response, err := proxy.requestToServer(instanceRequest)
+		if err != nil {
+			return false, err
+		}
+		if !response.IsSuccess() {
+			return false, nil
+		}
+	}
+	return true, nil
 }
 
 // DeregisterInstance ...
 func (proxy *NamingGrpcProxy) DeregisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
-	logger.Infof("deregister instance namespaceId:<%s>,serviceName:<%s> with instance:<%s:%d@%s>",
+	logger.With(logger.ServiceContext(proxy.clientConfig.NamespaceId, groupName, serviceName)).Infof("deregister instance namespaceId:<%s>,serviceName:<%s> with instance:<%s:%d@%s>",
 		proxy.clientConfig.NamespaceId, serviceName, instance.Ip, instance.Port, instance.ClusterName)
+	if !instance.Ephemeral {
+		return proxy.persistentInstanceRequest(serviceName, groupName, "deregisterInstance", instance)
+	}
 	instanceRequest := rpc_request.NewInstanceRequest(proxy.clientConfig.NamespaceId, serviceName, groupName, "deregisterInstance", instance)
 	response, err := proxy.requestToServer(instanceRequest)
 	proxy.eventListener.RemoveInstanceForRedo(serviceName, groupName, instance)
@@ -168,13 +220,26 @@ func (proxy *NamingGrpcProxy) QueryInstancesOfService(serviceName, groupName, cl
 	return &queryServiceResponse.ServiceInfo, nil
 }
 
+// QueryInstancesWithSelector query the instances with the label selector pushed down to server,
+// the servers which do not support selector ignore it and return all instances
+func (proxy *NamingGrpcProxy) QueryInstancesWithSelector(serviceName, groupName, cluster string, healthyOnly bool, selector string) (*model.Service, error) {
+	request := rpc_request.NewServiceQueryRequest(proxy.clientConfig.NamespaceId, serviceName, groupName, cluster, healthyOnly, 0)
+	request.Selector = selector
+	response, err := proxy.requestToServer(request)
+	if err != nil {
+		return nil, err
+	}
+	queryServiceResponse := response.(*rpc_response.QueryServiceResponse)
+	return &queryServiceResponse.ServiceInfo, nil
+}
+
 func (proxy *NamingGrpcProxy) IsSubscribed(serviceName, groupName string, clusters string) bool {
 	return proxy.eventListener.IsSubscriberCached(util.GetServiceCacheKey(util.GetGroupName(serviceName, groupName), clusters))
 }
 
 // Subscribe ...
 func (proxy *NamingGrpcProxy) Subscribe(serviceName, groupName string, clusters string) (model.Service, error) {
-	logger.Infof("Subscribe Service namespaceId:<%s>, serviceName:<%s>, groupName:<%s>, clusters:<%s>",
+	logger.With(logger.ServiceContext(proxy.clientConfig.NamespaceId, groupName, serviceName)).Infof("Subscribe Service namespaceId:<%s>, serviceName:<%s>, groupName:<%s>, clusters:<%s>",
 		proxy.clientConfig.NamespaceId, serviceName, groupName, clusters)
 	proxy.eventListener.CacheSubscriberForRedo(util.GetGroupName(serviceName, groupName), clusters)
 	request := rpc_request.NewSubscribeServiceRequest(proxy.clientConfig.NamespaceId, serviceName,
@@ -190,7 +255,7 @@ func (proxy *NamingGrpcProxy) Subscribe(serviceName, groupName string, clusters
 
 // Unsubscribe ...
 func (proxy *NamingGrpcProxy) Unsubscribe(serviceName, groupName, clusters string) error {
-	logger.Infof("Unsubscribe Service namespaceId:<%s>, serviceName:<%s>, groupName:<%s>, clusters:<%s>",
+	logger.With(logger.ServiceContext(proxy.clientConfig.NamespaceId, groupName, serviceName)).Infof("Unsubscribe Service namespaceId:<%s>, serviceName:<%s>, groupName:<%s>, clusters:<%s>",
 		proxy.clientConfig.NamespaceId, serviceName, groupName, clusters)
 	proxy.eventListener.RemoveSubscriberForRedo(util.GetGroupName(serviceName, groupName), clusters)
 	_, err := proxy.requestToServer(rpc_request.NewSubscribeServiceRequest(proxy.clientConfig.NamespaceId, serviceName, groupName,
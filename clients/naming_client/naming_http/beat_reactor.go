@@ -31,12 +31,14 @@ import (
 
 	"github.com/buger/jsonparser"
 	"github.com/nacos-group/nacos-sdk-go/v2/clients/cache"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_server"
 	"github.com/nacos-group/nacos-sdk-go/v2/model"
 	"github.com/nacos-group/nacos-sdk-go/v2/util"
 	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
 )
 
 type BeatReactor struct {
@@ -48,6 +50,8 @@ type BeatReactor struct {
 	beatRecordMap       cache.ConcurrentMap
 	clientCfg           constant.ClientConfig
 	mux                 *sync.Mutex
+	clock               clock.Clock
+	beatLimiter         *rate.Limiter // spread the beats when BeatSmoothingCfg is set, nil means disabled
 }
 
 const DefaultBeatThreadNum = 20
@@ -62,6 +66,8 @@ func NewBeatReactor(ctx context.Context, clientCfg constant.ClientConfig, nacosS
 	br.beatRecordMap = cache.NewConcurrentMap()
 	br.beatThreadSemaphore = semaphore.NewWeighted(int64(br.beatThreadCount))
 	br.mux = new(sync.Mutex)
+	br.clock = clock.OrReal(clientCfg.Clock)
+	br.beatLimiter = newBeatLimiter(clientCfg.BeatSmoothingCfg)
 	return br
 }
 
@@ -81,6 +87,7 @@ func (br *BeatReactor) AddBeatInfo(serviceName string, beatInfo *model.BeatInfo)
 	}
 	br.beatMap.Set(k, beatInfo)
 	beatInfo.Metadata = util.DeepCopyMap(beatInfo.Metadata)
+	br.updateBeatRateLocked()
 	monitor.GetDom2BeatSizeMonitor().Set(float64(br.beatMap.Count()))
 	go br.sendInstanceBeat(k, beatInfo)
 }
@@ -97,13 +104,16 @@ func (br *BeatReactor) RemoveBeatInfo(serviceName string, ip string, port uint64
 	}
 	monitor.GetDom2BeatSizeMonitor().Set(float64(br.beatMap.Count()))
 	br.beatMap.Remove(k)
-
+	br.updateBeatRateLocked()
 }
 
 func (br *BeatReactor) sendInstanceBeat(k string, beatInfo *model.BeatInfo) {
-	t := time.NewTimer(beatInfo.Period)
+	t := br.clock.NewTimer(beatInfo.Period)
 	defer t.Stop()
 	for {
+		if !br.waitBeatToken() {
+			return
+		}
 		br.beatThreadSemaphore.Acquire(br.ctx, 1)
 		//如果当前实例注销，则进行停止心跳
 		if atomic.LoadInt32(&beatInfo.State) == int32(model.StateShutdown) {
@@ -117,19 +127,26 @@ func (br *BeatReactor) sendInstanceBeat(k string, beatInfo *model.BeatInfo) {
 		if err != nil {
 			logger.Errorf("beat to server return error:%+v", err)
 			br.beatThreadSemaphore.Release(1)
-			t := time.NewTimer(beatInfo.Period)
-			<-t.C
+			t.Reset(beatInfo.Period)
+			select {
+			case <-t.C():
+			case <-br.ctx.Done():
+				return
+			}
 			continue
 		}
-		if beatInterval > 0 {
-			beatInfo.Period = time.Duration(time.Millisecond.Nanoseconds() * beatInterval)
+		if period := time.Duration(time.Millisecond.Nanoseconds() * beatInterval); beatInterval > 0 && period != beatInfo.Period {
+			br.mux.Lock()
+			beatInfo.Period = period
+			br.updateBeatRateLocked()
+			br.mux.Unlock()
 		}
 
 		br.beatRecordMap.Set(k, util.CurrentMillis())
 		br.beatThreadSemaphore.Release(1)
 		t.Reset(beatInfo.Period)
 		select {
-		case <-t.C:
+		case <-t.C():
 		case <-br.ctx.Done():
 			return
 		}
@@ -18,13 +18,16 @@ package naming_http
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_server"
 	"github.com/nacos-group/nacos-sdk-go/v2/model"
 	"github.com/nacos-group/nacos-sdk-go/v2/util"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
 )
 
 func TestBeatReactor_AddBeatInfo(t *testing.T) {
@@ -76,3 +79,39 @@ func TestBeatReactor_RemoveBeatInfo(t *testing.T) {
 	assert.ObjectsAreEqual(result.(*model.BeatInfo), beatInfo2)
 
 }
+
+func TestBeatReactor_BeatSmoothing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	br := NewBeatReactor(ctx, constant.ClientConfig{BeatSmoothingCfg: &constant.BeatSmoothingConfig{}}, &nacos_server.NacosServer{})
+	serviceName := util.GetGroupName("Test", "public")
+	for i := 0; i < 10; i++ {
+		br.AddBeatInfo(serviceName, &model.BeatInfo{
+			Ip:          fmt.Sprintf("127.0.0.%d", i),
+			Port:        8080,
+			ServiceName: serviceName,
+			Period:      5 * time.Second,
+		})
+	}
+	// 10 instances beating every 5s are spread to 2 beats per second with headroom
+	assert.InDelta(t, 2*beatHeadroom, float64(br.beatLimiter.Limit()), 0.001)
+
+	br.RemoveBeatInfo(serviceName, "127.0.0.0", 8080)
+	assert.InDelta(t, 1.8*beatHeadroom, float64(br.beatLimiter.Limit()), 0.001)
+
+	// the rate set explicitly is kept
+	fixed := NewBeatReactor(ctx, constant.ClientConfig{BeatSmoothingCfg: &constant.BeatSmoothingConfig{Qps: 50, Burst: 5}}, &nacos_server.NacosServer{})
+	fixed.AddBeatInfo(serviceName, &model.BeatInfo{Ip: "127.0.0.1", Port: 8080, ServiceName: serviceName, Period: 5 * time.Second})
+	assert.Equal(t, rate.Limit(50), fixed.beatLimiter.Limit())
+	assert.Equal(t, 5, fixed.beatLimiter.Burst())
+
+	// the rate set explicitly is raised when the beats are due faster
+	slow := NewBeatReactor(ctx, constant.ClientConfig{BeatSmoothingCfg: &constant.BeatSmoothingConfig{Qps: 1}}, &nacos_server.NacosServer{})
+	for i := 0; i < 10; i++ {
+		slow.AddBeatInfo(serviceName, &model.BeatInfo{Ip: fmt.Sprintf("127.0.0.%d", i), Port: 8080, ServiceName: serviceName, Period: 5 * time.Second})
+	}
+	assert.InDelta(t, 2*beatHeadroom, float64(slow.beatLimiter.Limit()), 0.001)
+
+	disabled := NewBeatReactor(ctx, constant.ClientConfig{}, &nacos_server.NacosServer{})
+	assert.Nil(t, disabled.beatLimiter)
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_http
+
+import (
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"golang.org/x/time/rate"
+)
+
+// beatHeadroom is the ratio of the default rate to the rate the beats are due, the beats are delayed a little
+// by the token bucket and the headroom keeps them from lagging behind their interval
+const beatHeadroom = 1.1
+
+// newBeatLimiter create the token bucket of heartbeats, nil is returned when smoothing is disabled
+func newBeatLimiter(cfg *constant.BeatSmoothingConfig) *rate.Limiter {
+	if cfg == nil {
+		return nil
+	}
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+	// the limit of default rate is set by updateBeatRateLocked when the instances are added
+	return rate.NewLimiter(rate.Limit(cfg.Qps), burst)
+}
+
+// waitBeatToken block until the beat can be sent by the token bucket, false is returned when the reactor is stopped
+func (br *BeatReactor) waitBeatToken() bool {
+	if br.beatLimiter == nil {
+		return true
+	}
+	return br.beatLimiter.Wait(br.ctx) == nil
+}
+
+// updateBeatRateLocked set the rate of token bucket to the one the beats of the instances are due with headroom,
+// it's called with mux held when the instances or their intervals change. The rate set by Qps is kept unless it's
+// lower than that, the beats would lag behind their interval and the instances would be expired by server
+func (br *BeatReactor) updateBeatRateLocked() {
+	if br.beatLimiter == nil {
+		return
+	}
+	var due float64
+	for _, item := range br.beatMap.Items() {
+		if period := item.(*model.BeatInfo).Period; period > 0 {
+			due += 1 / period.Seconds()
+		}
+	}
+	qps := br.clientCfg.BeatSmoothingCfg.Qps
+	if qps > 0 {
+		if qps < due*beatHeadroom {
+			logger.Warnf("beat smoothing qps %.2f is lower than the %.2f beats per second due, raised to %.2f",
+				qps, due, due*beatHeadroom)
+			qps = due * beatHeadroom
+		}
+		br.beatLimiter.SetLimit(rate.Limit(qps))
+		return
+	}
+	if due <= 0 {
+		// no beat is due, the tokens are not limited until the instances are added
+		br.beatLimiter.SetLimit(rate.Inf)
+		return
+	}
+	br.beatLimiter.SetLimit(rate.Limit(due * beatHeadroom))
+}
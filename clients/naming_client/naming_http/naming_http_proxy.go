@@ -18,7 +18,9 @@ package naming_http
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"sort"
 	"strconv"
 	"time"
 
@@ -165,6 +167,50 @@ func (proxy *NamingHttpProxy) GetServiceList(pageNo uint32, pageSize uint32, gro
 	return serviceList, nil
 }
 
+// listGroupsPageSize is the number of services in a page of the catalog api
+const listGroupsPageSize = 500
+
+// ListGroups list the groups having services in the namespace, the services are paged through by the catalog api
+// since the naming api has no listing of groups
+func (proxy *NamingHttpProxy) ListGroups(namespaceId string) ([]string, error) {
+	api := constant.SERVICE_BASE_PATH + "/catalog/services"
+	seen := make(map[string]struct{})
+	var groups []string
+	for pageNo := 1; ; pageNo++ {
+		params := map[string]string{
+			"namespaceId":      namespaceId,
+			"pageNo":           strconv.Itoa(pageNo),
+			"pageSize":         strconv.Itoa(listGroupsPageSize),
+			"withInstances":    "false",
+			"serviceNameParam": "",
+			"groupNameParam":   "",
+		}
+		result, err := proxy.nacosServer.ReqApi(api, params, http.MethodGet, proxy.clientConfig)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Count       int `json:"count"`
+			ServiceList []struct {
+				GroupName string `json:"groupName"`
+			} `json:"serviceList"`
+		}
+		if err = json.Unmarshal([]byte(result), &page); err != nil {
+			return nil, errors.Wrapf(err, "namespaceId:<%s> unmarshal services of page %d from <%s> failed", namespaceId, pageNo, result)
+		}
+		for _, service := range page.ServiceList {
+			if _, ok := seen[service.GroupName]; !ok && len(service.GroupName) > 0 {
+				seen[service.GroupName] = struct{}{}
+				groups = append(groups, service.GroupName)
+			}
+		}
+		if len(page.ServiceList) == 0 || pageNo*listGroupsPageSize >= page.Count {
+			sort.Strings(groups)
+			return groups, nil
+		}
+	}
+}
+
 // ServerHealthy ...
 func (proxy *NamingHttpProxy) ServerHealthy() bool {
 	api := constant.SERVICE_BASE_PATH + "/operator/metrics"
@@ -203,6 +249,26 @@ func (proxy *NamingHttpProxy) QueryInstancesOfService(serviceName, groupName, cl
 
 }
 
+// QueryServiceInfo query the service-level settings such as metadata and protectThreshold
+func (proxy *NamingHttpProxy) QueryServiceInfo(serviceName, groupName string) (model.ServiceInfo, error) {
+	param := make(map[string]string)
+	param["namespaceId"] = proxy.clientConfig.NamespaceId
+	param["serviceName"] = serviceName
+	param["groupName"] = groupName
+	result, err := proxy.nacosServer.ReqApi(constant.SERVICE_INFO_PATH, param, http.MethodGet, proxy.clientConfig)
+	if err != nil {
+		return model.ServiceInfo{}, err
+	}
+	var serviceInfo model.ServiceInfo
+	if err = json.Unmarshal([]byte(result), &serviceInfo); err != nil {
+		return model.ServiceInfo{}, errors.Wrapf(err, "unmarshal service info of %s from <%s> failed", serviceName, result)
+	}
+	if serviceInfo.Group == "" {
+		serviceInfo.Group = groupName
+	}
+	return serviceInfo, nil
+}
+
 // Subscribe ...
 func (proxy *NamingHttpProxy) Subscribe(serviceName, groupName, clusters string) (model.Service, error) {
 	return model.Service{}, nil
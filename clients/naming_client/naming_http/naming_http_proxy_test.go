@@ -0,0 +1,63 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/http_agent"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_server"
+)
+
+func TestNamingHttpProxy_ListGroups(t *testing.T) {
+	groups := []string{"DEFAULT_GROUP", "pay", "DEFAULT_GROUP", "order", "pay"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/nacos/v1/ns/catalog/services", r.URL.Path)
+		assert.Equal(t, "tenant", r.URL.Query().Get("namespaceId"))
+		pageNo, _ := strconv.Atoi(r.URL.Query().Get("pageNo"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+		list := "["
+		for i := (pageNo - 1) * pageSize; i < len(groups) && i < pageNo*pageSize; i++ {
+			if len(list) > 1 {
+				list += ","
+			}
+			list += fmt.Sprintf(`{"name":"svc%d","groupName":"%s"}`, i, groups[i])
+		}
+		_, _ = fmt.Fprintf(w, `{"count":%d,"serviceList":%s]}`, len(groups), list)
+	}))
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	portNum, _ := strconv.ParseUint(port, 10, 64)
+	clientConfig := constant.ClientConfig{TimeoutMs: 1000}
+	nacosServer, err := nacos_server.NewNacosServer(context.Background(),
+		[]constant.ServerConfig{*constant.NewServerConfig(host, portNum)}, clientConfig, &http_agent.HttpAgent{}, 1000, "")
+	assert.Nil(t, err)
+
+	proxy := &NamingHttpProxy{clientConfig: clientConfig, nacosServer: nacosServer}
+	listed, err := proxy.ListGroups("tenant")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"DEFAULT_GROUP", "order", "pay"}, listed)
+}
@@ -17,12 +17,20 @@
 package naming_client
 
 import (
+	"context"
 	"math/rand"
 	"sort"
 
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client/balancer"
 	"github.com/nacos-group/nacos-sdk-go/v2/model"
 )
 
+func init() {
+	balancer.Register(balancer.DefaultBalancer, balancer.Func(func(ctx context.Context, instances []model.Instance) (model.Instance, error) {
+		return newChooser(instances).pick(), nil
+	}))
+}
+
 type Chooser struct {
 	data   []model.Instance
 	totals []int
@@ -0,0 +1,238 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+var (
+	// ErrLeaseExpired is the reason of the lost lease which is not renewed within the ttl
+	ErrLeaseExpired = errors.New("lease expired")
+	// ErrLeaseDisconnected is the reason of the lost lease whose connection to server is lost
+	ErrLeaseDisconnected = errors.New("lease lost by disconnection")
+	// ErrLeaseRevoked is the reason of the lease revoked by the caller or closed with the client
+	ErrLeaseRevoked = errors.New("lease revoked")
+)
+
+// Lease is the registration of an ephemeral instance which is kept only while it's renewed within the ttl.
+// The instance is deregistered once the lease is lost, and it's not registered again when the client reconnects
+type Lease struct {
+	client   *NamingClient
+	param    vo.RegisterInstanceParam
+	ttl      time.Duration
+	onLost   func(param vo.RegisterInstanceParam, err error)
+	mux      sync.Mutex
+	expireAt time.Time
+	timer    clock.Timer
+	err      error
+	done     chan struct{}
+}
+
+// connectionListenerRegistrar is implemented by the proxy which is able to notify the changes of the connection
+// which ephemeral instances are bound to
+type connectionListenerRegistrar interface {
+	RegisterConnectionListener(listener rpc.IConnectionEventListener)
+}
+
+// leaseConnectionListener loses all leases of the client on disconnection, since the server removes the
+// ephemeral instances of the connection
+type leaseConnectionListener struct {
+	client *NamingClient
+}
+
+func (l *leaseConnectionListener) OnConnected() {}
+
+func (l *leaseConnectionListener) OnDisConnect() {
+	l.client.leases.Range(func(key, _ interface{}) bool {
+		key.(*Lease).lose(ErrLeaseDisconnected)
+		return true
+	})
+}
+
+// RegisterInstanceWithTTL register the ephemeral instance bound to a lease, see INamingClient
+func (sc *NamingClient) RegisterInstanceWithTTL(param vo.RegisterInstanceWithTTLParam) (*Lease, error) {
+	if param.TTL <= 0 {
+		return nil, errors.New("ttl must be larger than 0")
+	}
+	param.GroupName = sc.GroupOf(param.GroupName)
+	param.Ip = instanceIp(param.Ip)
+	param.Ephemeral = true
+	sc.leaseListenerOnce.Do(func() {
+		if registrar, ok := sc.serviceProxy.(connectionListenerRegistrar); ok {
+			registrar.RegisterConnectionListener(&leaseConnectionListener{client: sc})
+		}
+	})
+	registered, err := sc.RegisterInstance(param.RegisterInstanceParam)
+	if err != nil {
+		return nil, err
+	}
+	if !registered {
+		return nil, errors.Errorf("register instance %s:%d of service %s failed", param.Ip, param.Port, param.ServiceName)
+	}
+	lease := &Lease{
+		client:   sc,
+		param:    param.RegisterInstanceParam,
+		ttl:      param.TTL,
+		onLost:   param.OnLeaseLost,
+		expireAt: sc.clock.Now().Add(param.TTL),
+		done:     make(chan struct{}),
+	}
+	lease.mux.Lock()
+	lease.timer = sc.clock.AfterFunc(param.TTL, lease.checkExpiry)
+	lease.mux.Unlock()
+	sc.leases.Store(lease, struct{}{})
+	if param.AutoRenew {
+		go lease.autoRenew()
+	}
+	return lease, nil
+}
+
+// closeLeases revoke the leases when the client is closed, the instances are removed with the connection
+func (sc *NamingClient) closeLeases() {
+	sc.leases.Range(func(key, _ interface{}) bool {
+		key.(*Lease).finish(ErrLeaseRevoked)
+		return true
+	})
+}
+
+// Renew register the instance again to confirm it's kept by server, and extend the lease by ttl
+func (l *Lease) Renew() error {
+	if err := l.Err(); err != nil {
+		return err
+	}
+	registered, err := l.client.RegisterInstance(l.param)
+	if err == nil && !registered {
+		err = errors.New("server refused")
+	}
+	if err != nil {
+		return errors.Wrapf(err, "renew lease of instance %s:%d failed", l.param.Ip, l.param.Port)
+	}
+	l.mux.Lock()
+	if l.err != nil {
+		l.mux.Unlock()
+		// the lease is lost while renewing, remove the instance registered again
+		_ = l.deregister()
+		return l.err
+	}
+	l.expireAt = l.client.clock.Now().Add(l.ttl)
+	l.timer.Reset(l.ttl)
+	l.mux.Unlock()
+	return nil
+}
+
+// Revoke release the lease and deregister the instance, OnLeaseLost is not called
+func (l *Lease) Revoke() error {
+	if !l.finish(ErrLeaseRevoked) {
+		return nil
+	}
+	return l.deregister()
+}
+
+// ExpireAt return the time the lease expires at unless it's renewed
+func (l *Lease) ExpireAt() time.Time {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.expireAt
+}
+
+// Done return the channel closed when the lease is lost or revoked
+func (l *Lease) Done() <-chan struct{} {
+	return l.done
+}
+
+// Err return the reason why the lease is not active, nil means it's still active
+func (l *Lease) Err() error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.err
+}
+
+func (l *Lease) checkExpiry() {
+	l.mux.Lock()
+	if remain := l.client.clock.Until(l.expireAt); remain > 0 && l.err == nil {
+		l.timer.Reset(remain)
+		l.mux.Unlock()
+		return
+	}
+	l.mux.Unlock()
+	l.lose(ErrLeaseExpired)
+}
+
+func (l *Lease) autoRenew() {
+	ticker := l.client.clock.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-l.client.ctx.Done():
+			return
+		case <-ticker.C():
+			if err := l.Renew(); err != nil && l.Err() == nil {
+				logger.Warnf("%+v", err)
+			}
+		}
+	}
+}
+
+// finish mark the lease inactive with reason, it returns false if the lease has been finished
+func (l *Lease) finish(reason error) bool {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if l.err != nil {
+		return false
+	}
+	l.err = reason
+	l.timer.Stop()
+	close(l.done)
+	l.client.leases.Delete(l)
+	return true
+}
+
+func (l *Lease) lose(reason error) {
+	if !l.finish(reason) {
+		return
+	}
+	logger.Warnf("lease of instance %s:%d of service %s is lost, err:%v", l.param.Ip, l.param.Port, l.param.ServiceName, reason)
+	if err := l.deregister(); err != nil {
+		logger.Warnf("deregister instance of lost lease failed, err:%v", err)
+	}
+	if l.onLost != nil {
+		l.onLost(l.param, reason)
+	}
+}
+
+func (l *Lease) deregister() error {
+	_, err := l.client.DeregisterInstance(vo.DeregisterInstanceParam{
+		Ip:          l.param.Ip,
+		Port:        l.param.Port,
+		Cluster:     l.param.ClusterName,
+		ServiceName: l.param.ServiceName,
+		GroupName:   l.param.GroupName,
+		Ephemeral:   true,
+	})
+	return err
+}
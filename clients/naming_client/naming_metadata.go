@@ -0,0 +1,135 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+const defaultMetadataRefreshInterval = 10 * time.Second
+
+// metadataRefresher poll the metadata provider of a registered instance, and update the instance when it changed
+type metadataRefresher struct {
+	client *NamingClient
+	mux    sync.Mutex
+	// param is the registration whose Metadata is the static one, the provided metadata overrides it
+	param   vo.RegisterInstanceParam
+	last    map[string]string
+	stopped bool
+	stop    chan struct{}
+}
+
+func metadataRefresherKey(param vo.RegisterInstanceParam) string {
+	return param.GroupName + constant.SERVICE_INFO_SPLITER + param.ServiceName + constant.NAMING_INSTANCE_ID_SPLITTER +
+		param.Ip + constant.NAMING_INSTANCE_ID_SPLITTER + strconv.FormatUint(param.Port, 10) +
+		constant.NAMING_INSTANCE_ID_SPLITTER + param.ClusterName
+}
+
+// provideMetadata merge the metadata returned by the provider into the static metadata of the registration
+func provideMetadata(param vo.RegisterInstanceParam) map[string]string {
+	metadata := make(map[string]string, len(param.Metadata))
+	for k, v := range param.Metadata {
+		metadata[k] = v
+	}
+	for k, v := range param.MetadataProvider() {
+		metadata[k] = v
+	}
+	return metadata
+}
+
+// startMetadataRefresher start polling the metadata provider of the registered instance, the running refresher of
+// the same instance is kept with the registration replaced, since the instance may be registered again such as renewing
+func (sc *NamingClient) startMetadataRefresher(param vo.RegisterInstanceParam, registered map[string]string) {
+	refresher := &metadataRefresher{client: sc, param: param, last: registered, stop: make(chan struct{})}
+	if v, loaded := sc.metadataRefreshers.LoadOrStore(metadataRefresherKey(param), refresher); loaded {
+		existing := v.(*metadataRefresher)
+		existing.mux.Lock()
+		existing.param, existing.last = param, registered
+		existing.mux.Unlock()
+		return
+	}
+	go refresher.run()
+}
+
+// stopMetadataRefresher stop polling the metadata provider of the instance to deregister, it waits for the running
+// refresh, so the instance is not registered again by the update after it's deregistered
+func (sc *NamingClient) stopMetadataRefresher(param vo.RegisterInstanceParam) {
+	if v, ok := sc.metadataRefreshers.LoadAndDelete(metadataRefresherKey(param)); ok {
+		refresher := v.(*metadataRefresher)
+		refresher.mux.Lock()
+		refresher.stopped = true
+		close(refresher.stop)
+		refresher.mux.Unlock()
+	}
+}
+
+func (r *metadataRefresher) run() {
+	interval := r.param.MetadataRefreshInterval
+	if interval <= 0 {
+		interval = defaultMetadataRefreshInterval
+	}
+	ticker := r.client.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-r.client.ctx.Done():
+			return
+		case <-ticker.C():
+			r.refresh()
+		}
+	}
+}
+
+func (r *metadataRefresher) refresh() {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.stopped {
+		return
+	}
+	param := r.param
+	metadata := provideMetadata(param)
+	if reflect.DeepEqual(metadata, r.last) {
+		return
+	}
+	updated, err := r.client.UpdateInstance(vo.UpdateInstanceParam{
+		Ip:          param.Ip,
+		Port:        param.Port,
+		Weight:      param.Weight,
+		Enable:      param.Enable,
+		Healthy:     param.Healthy,
+		Metadata:    metadata,
+		ClusterName: param.ClusterName,
+		ServiceName: param.ServiceName,
+		GroupName:   param.GroupName,
+		Ephemeral:   param.Ephemeral,
+	})
+	if err != nil || !updated {
+		logger.Warnf("update metadata of instance %s:%d of service %s failed, err:%v", param.Ip, param.Port, param.ServiceName, err)
+		return
+	}
+	logger.Infof("metadata of instance %s:%d of service %s is refreshed: %v", param.Ip, param.Port, param.ServiceName, metadata)
+	r.last = metadata
+}
@@ -0,0 +1,316 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/inner/uuid"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+const (
+	defaultMutexTTL           = 15 * time.Second
+	defaultMutexRetryInterval = time.Second
+	// mutexInstancePort is the port of the instances of contenders, they are distinguished by the cluster
+	mutexInstancePort = 1
+	mutexDataIdSuffix = ".mutex"
+	// emptyContentMd5 is used as the cas md5 of absent record, so that it's created only if it's still absent
+	emptyContentMd5 = "d41d8cd98f00b204e9800998ecf8427e"
+)
+
+var (
+	// ErrMutexNotHeld is returned by Unlock when the mutex is not held
+	ErrMutexNotHeld = errors.New("mutex is not held")
+	// ErrMutexTaken is passed to OnLost when the record shows another holder
+	ErrMutexTaken = errors.New("mutex is taken by another contender")
+)
+
+// mutexRecord is the content of the record config of mutex
+type mutexRecord struct {
+	Holder string `json:"holder"` // the cluster of the instance of holder, empty means it's released
+	Owner  string `json:"owner"`  // the identity of holder
+	Token  int64  `json:"token"`  // increased each time the mutex is acquired
+}
+
+// Mutex is a coarse-grained distributed mutex.
+//
+// The holder and the fencing token are kept in the record config written with compare-and-swap, so only one
+// contender acquires the mutex at a time and the token is increased on server each time it's acquired, whatever the
+// clocks of contenders are. Each contender registers an ephemeral instance kept by a lease, the holder whose instance
+// is gone is regarded as dead, so the mutex is released when the holder unlocks, stops renewing or loses the
+// connection to server.
+//
+// The instances are replicated with eventual consistency, so the death of holder is confirmed by two consecutive
+// observations, and the holder keeps watching the record to find out it's taken. The mutex is still advisory: a holder
+// paused longer than that may act before it finds out, the resources protected by it should reject the writes with a
+// token smaller than seen. It's not reentrant and must not be locked again before it's unlocked
+type Mutex struct {
+	client *NamingClient
+	param  vo.MutexParam
+
+	mux     sync.Mutex
+	lease   *Lease
+	cluster string // the cluster of the instance of holder
+	token   int64
+	held    bool
+	done    chan struct{}
+}
+
+// NewMutex create the distributed mutex, see INamingClient
+func (sc *NamingClient) NewMutex(param vo.MutexParam) (*Mutex, error) {
+	if len(param.Name) == 0 {
+		return nil, errors.New("[client.NewMutex] Name can not be empty")
+	}
+	if param.Store == nil {
+		return nil, errors.New("[client.NewMutex] Store can not be nil")
+	}
+	param.GroupName = sc.GroupOf(param.GroupName)
+	if param.TTL <= 0 {
+		param.TTL = defaultMutexTTL
+	}
+	if param.RetryInterval <= 0 {
+		param.RetryInterval = defaultMutexRetryInterval
+	}
+	if len(param.Identity) == 0 {
+		uid, err := uuid.NewV4()
+		if err != nil {
+			return nil, err
+		}
+		hostname, _ := os.Hostname()
+		param.Identity = hostname + "-" + uid.String()[:8]
+	}
+	return &Mutex{client: sc, param: param}, nil
+}
+
+// TryLock try to acquire the lock without waiting for the holder, the fencing token is returned if it's acquired
+func (m *Mutex) TryLock(ctx context.Context) (token int64, acquired bool, err error) {
+	return m.acquire(ctx, false)
+}
+
+// Lock acquire the lock and wait until it's released by the holder or ctx is done, the fencing token is returned
+func (m *Mutex) Lock(ctx context.Context) (int64, error) {
+	token, _, err := m.acquire(ctx, true)
+	return token, err
+}
+
+// Unlock release the lock in the record and deregister the instance of contender
+func (m *Mutex) Unlock() error {
+	m.mux.Lock()
+	lease, cluster, held := m.lease, m.cluster, m.held
+	if held {
+		m.held, m.lease = false, nil
+		close(m.done)
+	}
+	m.mux.Unlock()
+	if !held {
+		return ErrMutexNotHeld
+	}
+	// the contenders don't need to wait for the death of holder to be confirmed
+	if record, content, err := m.readRecord(); err == nil && record.Holder == cluster {
+		record.Holder, record.Owner = "", ""
+		m.publish(record, content)
+	}
+	return lease.Revoke()
+}
+
+// Token return the fencing token of the held lock, it's 0 if the lock is not held
+func (m *Mutex) Token() int64 {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if !m.held {
+		return 0
+	}
+	return m.token
+}
+
+// Done return the channel closed when the held lock is lost or released, it's nil if the lock is not held
+func (m *Mutex) Done() <-chan struct{} {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if !m.held {
+		return nil
+	}
+	return m.done
+}
+
+func (m *Mutex) acquire(ctx context.Context, wait bool) (int64, bool, error) {
+	uid, err := uuid.NewV4()
+	if err != nil {
+		return 0, false, err
+	}
+	cluster := strings.ReplaceAll(uid.String(), "-", "")
+	lease, err := m.client.RegisterInstanceWithTTL(vo.RegisterInstanceWithTTLParam{
+		RegisterInstanceParam: vo.RegisterInstanceParam{
+			Port:        mutexInstancePort,
+			Weight:      1,
+			Enable:      true,
+			Healthy:     true,
+			Metadata:    map[string]string{constant.MUTEX_METADATA_OWNER: m.param.Identity},
+			ClusterName: cluster,
+			ServiceName: m.param.Name,
+			GroupName:   m.param.GroupName,
+		},
+		TTL:       m.param.TTL,
+		AutoRenew: true,
+		OnLeaseLost: func(param vo.RegisterInstanceParam, err error) {
+			m.lost(cluster, err)
+		},
+	})
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "[client.Mutex] register contender of %s failed", m.param.Name)
+	}
+
+	ticker := m.client.clock.NewTicker(m.param.RetryInterval)
+	defer ticker.Stop()
+	// the holder is dead when its instance is absent in two consecutive observations of the same record,
+	// to tolerate the instance not visible yet
+	var (
+		absentContent string
+		absent        int
+	)
+	for {
+		record, content, err := m.readRecord()
+		if err == nil {
+			free := len(record.Holder) == 0
+			if !free {
+				alive, aliveErr := m.isAlive(record.Holder)
+				switch {
+				case aliveErr != nil || alive:
+					absent = 0
+				case absent > 0 && content == absentContent:
+					absent++
+				default:
+					absentContent, absent = content, 1
+				}
+				free = absent >= 2
+			}
+			if free {
+				next := mutexRecord{Holder: cluster, Owner: m.param.Identity, Token: record.Token + 1}
+				if m.publish(next, content) {
+					done := make(chan struct{})
+					m.mux.Lock()
+					m.lease, m.cluster, m.token, m.held, m.done = lease, cluster, next.Token, true, done
+					m.mux.Unlock()
+					go m.watch(cluster, next.Token, done)
+					return next.Token, true, nil
+				}
+			} else if !wait && absent == 0 {
+				_ = lease.Revoke()
+				return 0, false, nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			_ = lease.Revoke()
+			return 0, false, ctx.Err()
+		case <-lease.Done():
+			return 0, false, errors.Wrapf(lease.Err(), "[client.Mutex] contender of %s is lost", m.param.Name)
+		case <-ticker.C():
+		}
+	}
+}
+
+// watch the record until the held lock is released or lost, it's lost once the record shows another holder
+func (m *Mutex) watch(cluster string, token int64, done <-chan struct{}) {
+	ticker := m.client.clock.NewTicker(m.param.RetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C():
+		}
+		// the failure of reading is left to the lease, which is lost when the connection is lost
+		if record, _, err := m.readRecord(); err == nil && (record.Holder != cluster || record.Token != token) {
+			m.lost(cluster, ErrMutexTaken)
+			return
+		}
+	}
+}
+
+// isAlive check whether the instance of holder is visible
+func (m *Mutex) isAlive(cluster string) (bool, error) {
+	instances, err := m.client.SelectAllInstances(vo.SelectAllInstancesParam{ServiceName: m.param.Name, GroupName: m.param.GroupName})
+	if err != nil {
+		return false, err
+	}
+	for _, instance := range instances {
+		if instance.ClusterName == cluster {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *Mutex) recordParam() vo.ConfigParam {
+	return vo.ConfigParam{DataId: m.param.Name + mutexDataIdSuffix, Group: m.param.GroupName}
+}
+
+func (m *Mutex) readRecord() (record mutexRecord, content string, err error) {
+	content, err = m.param.Store.GetConfig(m.recordParam())
+	if err != nil || len(content) == 0 {
+		return
+	}
+	if err = json.Unmarshal([]byte(content), &record); err != nil {
+		err = errors.Wrapf(err, "[client.Mutex] invalid record of %s", m.param.Name)
+	}
+	return
+}
+
+// publish write the record only if it's still the previous content
+func (m *Mutex) publish(record mutexRecord, previous string) bool {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return false
+	}
+	param := m.recordParam()
+	param.Content = string(data)
+	param.CasMd5 = util.Md5(previous)
+	if len(param.CasMd5) == 0 {
+		param.CasMd5 = emptyContentMd5
+	}
+	published, err := m.param.Store.PublishConfig(param)
+	return err == nil && published
+}
+
+func (m *Mutex) lost(cluster string, err error) {
+	m.mux.Lock()
+	held := m.held && m.cluster == cluster
+	lease, token := m.lease, m.token
+	if held {
+		m.held, m.lease = false, nil
+		close(m.done)
+	}
+	m.mux.Unlock()
+	if !held {
+		return
+	}
+	_ = lease.Revoke()
+	if m.param.OnLost != nil {
+		m.param.OnLost(m.param.Name, token, err)
+	}
+}
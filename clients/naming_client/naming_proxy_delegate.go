@@ -26,6 +26,7 @@ import (
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/http_agent"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_server"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc"
 	"github.com/nacos-group/nacos-sdk-go/v2/model"
 	"github.com/nacos-group/nacos-sdk-go/v2/util"
 )
@@ -44,7 +45,13 @@ func NewNamingProxyDelegate(ctx context.Context, clientCfg constant.ClientConfig
 	if err != nil {
 		return nil, err
 	}
+	return NewNamingProxyDelegateWithServer(ctx, clientCfg, nacosServer, serviceInfoHolder)
+}
 
+// NewNamingProxyDelegateWithServer create the delegate sending requests through nacosServer, which may be shared
+// with the config client
+func NewNamingProxyDelegateWithServer(ctx context.Context, clientCfg constant.ClientConfig, nacosServer *nacos_server.NacosServer,
+	serviceInfoHolder *naming_cache.ServiceInfoHolder) (naming_proxy.INamingProxy, error) {
 	httpClientProxy, err := naming_http.NewNamingHttpProxy(ctx, clientCfg, nacosServer, serviceInfoHolder)
 	if err != nil {
 		return nil, err
@@ -62,8 +69,10 @@ func NewNamingProxyDelegate(ctx context.Context, clientCfg constant.ClientConfig
 	}, nil
 }
 
+// getExecuteClientProxy return the grpc proxy for ephemeral instances, the persistent instances are registered
+// through grpc only if the server has the ability, otherwise through http
 func (proxy *NamingProxyDelegate) getExecuteClientProxy(instance model.Instance) (namingProxy naming_proxy.INamingProxy) {
-	if instance.Ephemeral {
+	if instance.Ephemeral || proxy.grpcClientProxy.SupportPersistentInstance() {
 		namingProxy = proxy.grpcClientProxy
 	} else {
 		namingProxy = proxy.httpClientProxy
@@ -71,6 +80,11 @@ func (proxy *NamingProxyDelegate) getExecuteClientProxy(instance model.Instance)
 	return namingProxy
 }
 
+// Abilities return the result of ability negotiation with the server of the grpc connection
+func (proxy *NamingProxyDelegate) Abilities() rpc.AbilityState {
+	return proxy.grpcClientProxy.Abilities()
+}
+
 func (proxy *NamingProxyDelegate) RegisterInstance(serviceName string, groupName string, instance model.Instance) (bool, error) {
 	return proxy.getExecuteClientProxy(instance).RegisterInstance(serviceName, groupName, instance)
 }
@@ -95,6 +109,19 @@ func (proxy *NamingProxyDelegate) QueryInstancesOfService(serviceName, groupName
 	return proxy.grpcClientProxy.QueryInstancesOfService(serviceName, groupName, clusters, udpPort, healthyOnly)
 }
 
+func (proxy *NamingProxyDelegate) QueryInstancesWithSelector(serviceName, groupName, clusters string, healthyOnly bool, selector string) (*model.Service, error) {
+	return proxy.grpcClientProxy.QueryInstancesWithSelector(serviceName, groupName, clusters, healthyOnly, selector)
+}
+
+func (proxy *NamingProxyDelegate) QueryServiceInfo(serviceName, groupName string) (model.ServiceInfo, error) {
+	return proxy.httpClientProxy.QueryServiceInfo(serviceName, groupName)
+}
+
+// ListGroups list the groups having services in the namespace, the grpc api has no listing so it's done by http
+func (proxy *NamingProxyDelegate) ListGroups(namespaceId string) ([]string, error) {
+	return proxy.httpClientProxy.ListGroups(namespaceId)
+}
+
 func (proxy *NamingProxyDelegate) Subscribe(serviceName, groupName string, clusters string) (model.Service, error) {
 	var err error
 	isSubscribed := proxy.grpcClientProxy.IsSubscribed(serviceName, groupName, clusters)
@@ -112,6 +139,21 @@ func (proxy *NamingProxyDelegate) Subscribe(serviceName, groupName string, clust
 	return service, nil
 }
 
+// Resubscribe subscribe the service from server even if it has been subscribed, and process the latest service info
+func (proxy *NamingProxyDelegate) Resubscribe(serviceName, groupName, clusters string) (model.Service, error) {
+	service, err := proxy.grpcClientProxy.Subscribe(serviceName, groupName, clusters)
+	if err != nil {
+		return model.Service{}, err
+	}
+	proxy.serviceInfoHolder.ProcessService(&service)
+	return service, nil
+}
+
+// RegisterConnectionListener register the listener notified when the grpc connection which ephemeral instances are bound to changed
+func (proxy *NamingProxyDelegate) RegisterConnectionListener(listener rpc.IConnectionEventListener) {
+	proxy.grpcClientProxy.RegisterConnectionListener(listener)
+}
+
 func (proxy *NamingProxyDelegate) Unsubscribe(serviceName, groupName, clusters string) error {
 	proxy.serviceInfoHolder.StopUpdateIfContain(util.GetGroupName(serviceName, groupName), clusters)
 	return proxy.grpcClientProxy.Unsubscribe(serviceName, groupName, clusters)
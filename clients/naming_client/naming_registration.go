@@ -0,0 +1,320 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+)
+
+const (
+	defaultRegistrationLostTimeout = 30 * time.Second
+	// defaultClusterName is the cluster of the instances registered without cluster name on server
+	defaultClusterName = "DEFAULT"
+)
+
+// ErrRegistrationDisconnected is passed to OnHeartbeatFail when the connection keeping the ephemeral instances
+// alive is lost
+var ErrRegistrationDisconnected = errors.New("connection keeping the instance alive is lost")
+
+// registrationTracker keeps the state of the instances registered by the client and calls the hooks. The ephemeral
+// instances are degraded on disconnection, they're registered again by the proxy once reconnected, and they're lost
+// when the connection is not recovered within the lost timeout
+type registrationTracker struct {
+	hooks        constant.RegistrationHooks
+	clock        clock.Clock
+	listenerOnce sync.Once
+	mux          sync.Mutex
+	items        map[string]*model.Registration
+	lostTimer    clock.Timer
+	// observed is the services subscribed to detect the conflicts, conflicts is the metadata of the conflicting
+	// instances reported, so that the same conflict is reported once
+	observed  map[string]bool
+	conflicts map[string]string
+}
+
+func newRegistrationTracker(hooks *constant.RegistrationHooks, c clock.Clock) *registrationTracker {
+	t := &registrationTracker{
+		clock:     c,
+		items:     make(map[string]*model.Registration),
+		observed:  make(map[string]bool),
+		conflicts: make(map[string]string),
+	}
+	if hooks != nil {
+		t.hooks = *hooks
+	}
+	if t.hooks.LostTimeout <= 0 {
+		t.hooks.LostTimeout = defaultRegistrationLostTimeout
+	}
+	return t
+}
+
+func registrationKey(serviceName, groupName string, instance model.Instance) string {
+	clusterName := instance.ClusterName
+	if len(clusterName) == 0 {
+		clusterName = defaultClusterName
+	}
+	return util.GetGroupName(serviceName, groupName) + "#" + clusterName + "#" + instance.Ip + ":" +
+		strconv.FormatUint(instance.Port, 10)
+}
+
+func (t *registrationTracker) beforeRegister(serviceName, groupName string, instance *model.Instance) error {
+	if t.hooks.BeforeRegister == nil {
+		return nil
+	}
+	return errors.Wrapf(t.hooks.BeforeRegister(serviceName, groupName, instance), "register instance %s:%d of service %s is aborted",
+		instance.Ip, instance.Port, serviceName)
+}
+
+func (t *registrationTracker) afterRegister(serviceName, groupName string, instance model.Instance, registered bool, err error) {
+	if err == nil && !registered {
+		err = errors.Errorf("register instance %s:%d of service %s failed", instance.Ip, instance.Port, serviceName)
+	}
+	if err == nil {
+		registration := &model.Registration{ServiceName: serviceName, GroupName: groupName, Instance: instance}
+		t.mux.Lock()
+		t.items[registrationKey(serviceName, groupName, instance)] = registration
+		t.setState(registration, model.RegistrationRegistered)
+		t.mux.Unlock()
+	}
+	if t.hooks.AfterRegister != nil {
+		t.hooks.AfterRegister(serviceName, groupName, instance, err)
+	}
+}
+
+func (t *registrationTracker) afterDeregister(serviceName, groupName string, instance model.Instance, deregistered bool, err error) {
+	if err == nil && !deregistered {
+		err = errors.Errorf("deregister instance %s:%d of service %s failed", instance.Ip, instance.Port, serviceName)
+	}
+	if err == nil {
+		key := registrationKey(serviceName, groupName, instance)
+		t.mux.Lock()
+		delete(t.items, key)
+		delete(t.conflicts, key)
+		t.mux.Unlock()
+	}
+	if t.hooks.OnDeregister != nil {
+		t.hooks.OnDeregister(serviceName, groupName, instance, err)
+	}
+}
+
+// afterUpdate keep the metadata of the registered instance updated, so that it's not regarded as a conflict
+func (t *registrationTracker) afterUpdate(serviceName, groupName string, instance model.Instance, updated bool, err error) {
+	if err != nil || !updated {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if registration, ok := t.items[registrationKey(serviceName, groupName, instance)]; ok {
+		registration.Instance = instance
+	}
+}
+
+// observe compare the instances of the service on server with the registered ones, OnConflict is called once for
+// each distinct metadata of the instance observed differently from the registered one
+func (t *registrationTracker) observe(service model.Service) {
+	var conflicts []model.RegistrationConflict
+	t.mux.Lock()
+	for _, observed := range service.Hosts {
+		key := registrationKey(service.Name, service.GroupName, observed)
+		registration, ok := t.items[key]
+		if !ok {
+			continue
+		}
+		if metadataEqual(registration.Instance.Metadata, observed.Metadata) {
+			delete(t.conflicts, key)
+			continue
+		}
+		fingerprint := util.ToJsonString(observed.Metadata)
+		if t.conflicts[key] == fingerprint {
+			continue
+		}
+		t.conflicts[key] = fingerprint
+		conflicts = append(conflicts, model.RegistrationConflict{
+			ServiceName: registration.ServiceName,
+			GroupName:   registration.GroupName,
+			Registered:  registration.Instance,
+			Observed:    observed,
+			DetectedAt:  t.clock.Now(),
+		})
+	}
+	t.mux.Unlock()
+	for _, conflict := range conflicts {
+		logger.Warnf("instance %s:%d of service %s is registered with different metadata by another process, registered:%v, observed:%v",
+			conflict.Observed.Ip, conflict.Observed.Port, conflict.ServiceName, conflict.Registered.Metadata, conflict.Observed.Metadata)
+		t.hooks.OnConflict(conflict)
+	}
+}
+
+func metadataEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+// setState must be called with mux held, OnStateChange is called only when the state changes
+func (t *registrationTracker) setState(registration *model.Registration, state model.RegistrationState) {
+	if registration.State == state {
+		return
+	}
+	registration.State = state
+	registration.Since = t.clock.Now()
+	if t.hooks.OnStateChange != nil {
+		t.hooks.OnStateChange(*registration)
+	}
+}
+
+// list return the registrations sorted by service and instance
+func (t *registrationTracker) list() []model.Registration {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	keys := make([]string, 0, len(t.items))
+	for key := range t.items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	registrations := make([]model.Registration, 0, len(keys))
+	for _, key := range keys {
+		registrations = append(registrations, *t.items[key])
+	}
+	return registrations
+}
+
+func (t *registrationTracker) OnConnected() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.lostTimer != nil {
+		t.lostTimer.Stop()
+		t.lostTimer = nil
+	}
+	for _, registration := range t.items {
+		if registration.Instance.Ephemeral {
+			t.setState(registration, model.RegistrationRegistered)
+		}
+	}
+}
+
+func (t *registrationTracker) OnDisConnect() {
+	t.mux.Lock()
+	var degraded []model.Registration
+	for _, registration := range t.items {
+		if registration.Instance.Ephemeral && registration.State == model.RegistrationRegistered {
+			t.setState(registration, model.RegistrationDegraded)
+			degraded = append(degraded, *registration)
+		}
+	}
+	if t.lostTimer == nil && len(degraded) > 0 {
+		t.lostTimer = t.clock.AfterFunc(t.hooks.LostTimeout, t.lose)
+	}
+	t.mux.Unlock()
+	if t.hooks.OnHeartbeatFail != nil {
+		for _, registration := range degraded {
+			t.hooks.OnHeartbeatFail(registration.ServiceName, registration.GroupName, registration.Instance, ErrRegistrationDisconnected)
+		}
+	}
+}
+
+func (t *registrationTracker) lose() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.lostTimer == nil {
+		return
+	}
+	t.lostTimer = nil
+	for _, registration := range t.items {
+		if registration.State == model.RegistrationDegraded {
+			t.setState(registration, model.RegistrationLost)
+		}
+	}
+}
+
+// trackRegistrations register the tracker as the connection listener on the first registration
+func (sc *NamingClient) trackRegistrations() *registrationTracker {
+	sc.registrations.listenerOnce.Do(func() {
+		if registrar, ok := sc.serviceProxy.(connectionListenerRegistrar); ok {
+			registrar.RegisterConnectionListener(sc.registrations)
+		}
+	})
+	return sc.registrations
+}
+
+// observeRegistrations subscribe the service of the registered instances once when OnConflict is set, so that the
+// conflicting registrations are detected from the instances pushed by server
+func (sc *NamingClient) observeRegistrations(serviceName, groupName string) {
+	t := sc.registrations
+	if t.hooks.OnConflict == nil {
+		return
+	}
+	serviceFullName := util.GetGroupName(serviceName, groupName)
+	t.mux.Lock()
+	observed := t.observed[serviceFullName]
+	t.observed[serviceFullName] = true
+	t.mux.Unlock()
+	if observed {
+		return
+	}
+	callback := func(service model.Service, err error) {
+		if err == nil {
+			t.observe(service)
+		}
+	}
+	sc.serviceInfoHolder.RegisterServiceCallback(serviceFullName, "", &callback)
+	if _, err := sc.serviceProxy.Subscribe(serviceName, groupName, ""); err != nil {
+		logger.Warnf("subscribe service %s to detect the registration conflicts failed, err:%v", serviceName, err)
+	}
+	if service, ok := sc.serviceInfoHolder.GetServiceInfo(serviceName, groupName, ""); ok {
+		t.observe(service)
+	}
+}
+
+// excludeSelf return the instances except the ones registered by the client, the instances are not modified
+func (sc *NamingClient) excludeSelf(serviceName, groupName string, instances []model.Instance) []model.Instance {
+	t := sc.registrations
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if len(t.items) == 0 {
+		return instances
+	}
+	result := make([]model.Instance, 0, len(instances))
+	for _, instance := range instances {
+		if _, ok := t.items[registrationKey(serviceName, groupName, instance)]; !ok {
+			result = append(result, instance)
+		}
+	}
+	return result
+}
+
+// GetRegistrations return the state of the instances registered by the client, see INamingClient
+func (sc *NamingClient) GetRegistrations() []model.Registration {
+	return sc.registrations.list()
+}
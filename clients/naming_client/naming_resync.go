@@ -0,0 +1,101 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"math/rand"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client/naming_cache"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+)
+
+const defaultResyncQps = 10
+
+// resyncLoop reconciles the cached subscribed services with the ones on server every interval, catching the
+// pushes missed such as on reconnection
+func (sc *NamingClient) resyncLoop(cfg constant.ResyncConfig) {
+	qps := cfg.Qps
+	if qps <= 0 {
+		qps = defaultResyncQps
+	}
+	limiter := rate.NewLimiter(rate.Limit(qps), 1)
+	for {
+		next := cfg.Interval
+		if cfg.Jitter > 0 {
+			next += time.Duration(rand.Int63n(int64(cfg.Jitter)))
+		}
+		timer := sc.clock.NewTimer(next)
+		select {
+		case <-sc.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C():
+		}
+		sc.resync(cfg, limiter)
+	}
+}
+
+// resync query the subscribed services one by one within the qps, the cached service is replaced when it drifts
+// from the one on server
+func (sc *NamingClient) resync(cfg constant.ResyncConfig, limiter *rate.Limiter) {
+	var services []model.Service
+	sc.serviceInfoHolder.ServiceInfoMap.Range(func(key, value interface{}) bool {
+		service := value.(model.Service)
+		if sc.serviceInfoHolder.IsSubscribed(util.GetGroupName(service.Name, service.GroupName), service.Clusters) {
+			services = append(services, service)
+		}
+		return true
+	})
+	for _, cached := range services {
+		if err := limiter.Wait(sc.ctx); err != nil {
+			return
+		}
+		fresh, err := sc.serviceProxy.QueryInstancesOfService(cached.Name, cached.GroupName, cached.Clusters, 0, false)
+		if err != nil || fresh == nil {
+			logger.Warnf("[resync] query service %s clusters:%s failed, err:%v", cached.Name, cached.Clusters, err)
+			continue
+		}
+		added, removed, modified := naming_cache.DiffInstances(cached.Hosts, fresh.Hosts)
+		if len(added) == 0 && len(removed) == 0 && len(modified) == 0 {
+			continue
+		}
+		logger.Warnf("[resync] service %s clusters:%s drifted from server, added:%d removed:%d modified:%d",
+			cached.Name, cached.Clusters, len(added), len(removed), len(modified))
+		// the service on server is authoritative, it must not be dropped as out of date
+		if current, ok := sc.serviceInfoHolder.GetServiceInfo(cached.Name, cached.GroupName, cached.Clusters); ok &&
+			fresh.LastRefTime <= current.LastRefTime {
+			fresh.LastRefTime = current.LastRefTime + 1
+		}
+		sc.serviceInfoHolder.ProcessService(fresh)
+		if cfg.OnDrift != nil {
+			cfg.OnDrift(model.ServiceDriftEvent{
+				ServiceName: cached.Name,
+				GroupName:   cached.GroupName,
+				Clusters:    cached.Clusters,
+				Added:       added,
+				Removed:     removed,
+				Modified:    modified,
+			})
+		}
+	}
+}
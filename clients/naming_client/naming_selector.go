@@ -0,0 +1,160 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
+const (
+	selectorEquals    = "="
+	selectorNotEquals = "!="
+	selectorIn        = "in"
+	selectorNotIn     = "notin"
+	selectorExists    = "exists"
+	selectorNotExists = "!"
+)
+
+type labelRequirement struct {
+	key      string
+	operator string
+	values   []string
+}
+
+// labelSelector is a label expression on instance metadata, the requirements are separated by comma, such as
+// "version=v2,zone in (a,b),env!=test,canary,!deprecated", an instance matches when all requirements are satisfied
+type labelSelector []labelRequirement
+
+func parseLabelSelector(expression string) (labelSelector, error) {
+	var selector labelSelector
+	for _, item := range splitSelector(expression) {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		requirement, err := parseLabelRequirement(item)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid selector %s", expression)
+		}
+		selector = append(selector, requirement)
+	}
+	return selector, nil
+}
+
+// splitSelector split the expression by the commas outside of parentheses
+func splitSelector(expression string) []string {
+	var (
+		items []string
+		depth int
+		start int
+	)
+	for i, c := range expression {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, expression[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(items, expression[start:])
+}
+
+func parseLabelRequirement(item string) (labelRequirement, error) {
+	if fields := strings.Fields(item); len(fields) >= 2 && (fields[1] == selectorIn || fields[1] == selectorNotIn) {
+		rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(item[len(fields[0]):]), fields[1]))
+		if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+			return labelRequirement{}, errors.Errorf("values of %s must be enclosed in parentheses", fields[0])
+		}
+		var values []string
+		for _, v := range strings.Split(rest[1:len(rest)-1], ",") {
+			values = append(values, strings.TrimSpace(v))
+		}
+		return labelRequirement{key: fields[0], operator: fields[1], values: values}, nil
+	}
+	if idx := strings.Index(item, selectorNotEquals); idx > 0 {
+		return labelRequirement{key: strings.TrimSpace(item[:idx]), operator: selectorNotEquals,
+			values: []string{strings.TrimSpace(item[idx+len(selectorNotEquals):])}}, nil
+	}
+	if idx := strings.Index(item, selectorEquals); idx > 0 {
+		value := strings.TrimPrefix(item[idx+len(selectorEquals):], selectorEquals)
+		return labelRequirement{key: strings.TrimSpace(item[:idx]), operator: selectorEquals,
+			values: []string{strings.TrimSpace(value)}}, nil
+	}
+	if strings.ContainsAny(item, " ()") {
+		return labelRequirement{}, errors.Errorf("unknown requirement %s", item)
+	}
+	if strings.HasPrefix(item, selectorNotExists) {
+		return labelRequirement{key: strings.TrimSpace(item[1:]), operator: selectorNotExists}, nil
+	}
+	return labelRequirement{key: item, operator: selectorExists}, nil
+}
+
+func (r labelRequirement) matches(metadata map[string]string) bool {
+	value, ok := metadata[r.key]
+	switch r.operator {
+	case selectorEquals:
+		return ok && value == r.values[0]
+	case selectorNotEquals:
+		return !ok || value != r.values[0]
+	case selectorIn, selectorNotIn:
+		in := false
+		for _, v := range r.values {
+			if ok && value == v {
+				in = true
+				break
+			}
+		}
+		return in == (r.operator == selectorIn)
+	case selectorNotExists:
+		return !ok
+	default:
+		return ok
+	}
+}
+
+func (s labelSelector) matches(metadata map[string]string) bool {
+	for _, r := range s {
+		if !r.matches(metadata) {
+			return false
+		}
+	}
+	return true
+}
+
+// filter returns the instances matching the selector, it's applied on client side for the servers
+// which do not support selector pushdown
+func (s labelSelector) filter(instances []model.Instance) []model.Instance {
+	if len(s) == 0 {
+		return instances
+	}
+	result := make([]model.Instance, 0, len(instances))
+	for _, instance := range instances {
+		if s.matches(instance.Metadata) {
+			result = append(result, instance)
+		}
+	}
+	return result
+}
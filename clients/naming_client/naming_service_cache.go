@@ -0,0 +1,165 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client/naming_cache"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
+// serviceQueryCache cache the services queried by GetService without subscription. The service is served from
+// cache within ttl, and within the following staleTtl the stale one is served while it's refreshed in background.
+// The concurrent queries of the same service share one request
+type serviceQueryCache struct {
+	ttl      time.Duration
+	staleTtl time.Duration
+	clock    clock.Clock
+	query    func(serviceName, groupName, clusters string) (model.Service, error)
+	group    singleflight.Group
+	entries  sync.Map
+	// refreshing keep the keys being revalidated in background, so a hot stale service is refreshed once
+	refreshing sync.Map
+}
+
+type serviceQueryEntry struct {
+	service   model.Service
+	fetchedAt time.Time
+}
+
+func newServiceQueryCache(ttl, staleTtl time.Duration, clock clock.Clock,
+	query func(serviceName, groupName, clusters string) (model.Service, error)) *serviceQueryCache {
+	return &serviceQueryCache{ttl: ttl, staleTtl: staleTtl, clock: clock, query: query}
+}
+
+func (c *serviceQueryCache) get(key, serviceName, groupName, clusters string) (model.Service, error) {
+	if v, ok := c.entries.Load(key); ok {
+		entry := v.(serviceQueryEntry)
+		age := c.clock.Since(entry.fetchedAt)
+		if age < c.ttl {
+			return entry.service, nil
+		}
+		if age < c.ttl+c.staleTtl {
+			if _, loaded := c.refreshing.LoadOrStore(key, struct{}{}); loaded {
+				return entry.service, nil
+			}
+			go func() {
+				defer c.refreshing.Delete(key)
+				if _, err := c.fetch(key, serviceName, groupName, clusters); err != nil {
+					logger.Warnf("revalidate service %s@@%s with clusters %s failed, err:%v", groupName, serviceName, clusters, err)
+				}
+			}()
+			return entry.service, nil
+		}
+	}
+	return c.fetch(key, serviceName, groupName, clusters)
+}
+
+func (c *serviceQueryCache) fetch(key, serviceName, groupName, clusters string) (model.Service, error) {
+	v, err, _ := c.group.Do(key, func() (interface{}, error) {
+		service, err := c.query(serviceName, groupName, clusters)
+		if err != nil {
+			return nil, err
+		}
+		if v, ok := c.entries.Load(key); ok && !naming_cache.ServiceChanged(v.(serviceQueryEntry).service, service) {
+			service.Epoch = v.(serviceQueryEntry).service.Epoch
+		} else {
+			service.Epoch = naming_cache.NextServiceEpoch()
+		}
+		c.entries.Store(key, serviceQueryEntry{service: service, fetchedAt: c.clock.Now()})
+		return service, nil
+	})
+	if err != nil {
+		return model.Service{}, err
+	}
+	return v.(model.Service), nil
+}
+
+const (
+	// serviceInfoTtl is the time the service-level settings delivered to ServiceCallback are served from cache,
+	// they are refreshed in background afterwards so the pushes never wait for the query but the first one
+	serviceInfoTtl = 30 * time.Second
+	// serviceInfoRetry is the time the failed query of service-level settings is retried after
+	serviceInfoRetry = 5 * time.Second
+)
+
+// serviceInfoCache cache the service-level settings such as metadata and protectThreshold, which are not carried
+// by the pushes of instances
+type serviceInfoCache struct {
+	clock      clock.Clock
+	query      func(serviceName, groupName string) (model.ServiceInfo, error)
+	group      singleflight.Group
+	entries    sync.Map
+	refreshing sync.Map
+}
+
+type serviceInfoEntry struct {
+	info      model.ServiceInfo
+	err       error
+	fetchedAt time.Time
+}
+
+func newServiceInfoCache(clock clock.Clock, query func(serviceName, groupName string) (model.ServiceInfo, error)) *serviceInfoCache {
+	return &serviceInfoCache{clock: clock, query: query}
+}
+
+// get return the cached settings of the service, the stale ones are refreshed in background
+func (c *serviceInfoCache) get(key, serviceName, groupName string) (model.ServiceInfo, error) {
+	v, ok := c.entries.Load(key)
+	if !ok {
+		entry := c.fetch(key, serviceName, groupName)
+		return entry.info, entry.err
+	}
+	entry := v.(serviceInfoEntry)
+	age := c.clock.Since(entry.fetchedAt)
+	if age >= serviceInfoTtl || (entry.err != nil && age >= serviceInfoRetry) {
+		if _, loaded := c.refreshing.LoadOrStore(key, struct{}{}); !loaded {
+			go func() {
+				defer c.refreshing.Delete(key)
+				c.fetch(key, serviceName, groupName)
+			}()
+		}
+	}
+	return entry.info, entry.err
+}
+
+// fetch query the settings and cache the result, the failure is cached as well so it's retried in background
+func (c *serviceInfoCache) fetch(key, serviceName, groupName string) serviceInfoEntry {
+	v, _, _ := c.group.Do(key, func() (interface{}, error) {
+		info, err := c.query(serviceName, groupName)
+		entry := serviceInfoEntry{info: info, err: err, fetchedAt: c.clock.Now()}
+		if err != nil {
+			// the last known settings are kept
+			if v, ok := c.entries.Load(key); ok {
+				entry.info = v.(serviceInfoEntry).info
+			}
+		}
+		c.entries.Store(key, entry)
+		return entry, nil
+	})
+	return v.(serviceInfoEntry)
+}
+
+func (c *serviceInfoCache) remove(key string) {
+	c.entries.Delete(key)
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/introspection"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/pkg/errors"
+)
+
+type namingClientSnapshot struct {
+	InstanceId    string                      `json:"instanceId"`
+	Type          string                      `json:"type"`
+	NamespaceId   string                      `json:"namespaceId"`
+	ServerHealthy bool                        `json:"serverHealthy"`
+	Subscriptions map[string]int              `json:"subscriptions"`
+	Services      []serviceSnapshot           `json:"services"`
+	Registrations []model.Registration        `json:"registrations"`
+	RecentErrors  []introspection.ErrorRecord `json:"recentErrors"`
+	Abilities     *rpc.AbilityState           `json:"abilities,omitempty"`
+}
+
+// abilityReporter is implemented by the proxy which negotiates abilities with server
+type abilityReporter interface {
+	Abilities() rpc.AbilityState
+}
+
+type serviceSnapshot struct {
+	Key          string `json:"key"`
+	Checksum     string `json:"checksum"`
+	LastRefTime  uint64 `json:"lastRefTime"`
+	HostCount    int    `json:"hostCount"`
+	HealthyCount int    `json:"healthyCount"`
+}
+
+// InstanceId return the unique id of the client instance
+func (sc *NamingClient) InstanceId() string {
+	return sc.instanceId
+}
+
+// Snapshot dump the runtime state of the client, instance list of services is not included
+func (sc *NamingClient) Snapshot() interface{} {
+	clientConfig, _ := sc.GetClientConfig()
+	snapshot := namingClientSnapshot{
+		InstanceId:    sc.instanceId,
+		Type:          "naming",
+		NamespaceId:   clientConfig.NamespaceId,
+		ServerHealthy: sc.serviceProxy.ServerHealthy(),
+		Subscriptions: sc.serviceInfoHolder.SubscribedCallbackCount(),
+		Services:      []serviceSnapshot{},
+		Registrations: sc.registrations.list(),
+		RecentErrors:  sc.errorRecorder.Recent(),
+	}
+	if reporter, ok := sc.serviceProxy.(abilityReporter); ok {
+		abilities := reporter.Abilities()
+		snapshot.Abilities = &abilities
+	}
+	sc.serviceInfoHolder.ServiceInfoMap.Range(func(key, value interface{}) bool {
+		service := value.(model.Service)
+		s := serviceSnapshot{
+			Key:         key.(string),
+			Checksum:    service.Checksum,
+			LastRefTime: service.LastRefTime,
+			HostCount:   len(service.Hosts),
+		}
+		for _, host := range service.Hosts {
+			if host.Healthy {
+				s.HealthyCount++
+			}
+		}
+		snapshot.Services = append(snapshot.Services, s)
+		return true
+	})
+	sort.Slice(snapshot.Services, func(i, j int) bool {
+		return snapshot.Services[i].Key < snapshot.Services[j].Key
+	})
+	return snapshot
+}
+
+// DumpState dump the full state of the client for reproducing it locally, including the instances of services
+func (sc *NamingClient) DumpState() introspection.ClientState {
+	clientConfig, _ := sc.GetClientConfig()
+	state := introspection.ClientState{
+		InstanceId:   sc.instanceId,
+		Type:         "naming",
+		NamespaceId:  clientConfig.NamespaceId,
+		ClientConfig: introspection.RedactConfig(clientConfig),
+	}
+	state.Snapshot, _ = json.Marshal(sc.Snapshot())
+	sc.serviceInfoHolder.ServiceInfoMap.Range(func(key, value interface{}) bool {
+		state.Services = append(state.Services, value.(model.Service))
+		return true
+	})
+	sort.Slice(state.Services, func(i, j int) bool {
+		return util.GetGroupName(state.Services[i].Name, state.Services[i].GroupName) <
+			util.GetGroupName(state.Services[j].Name, state.Services[j].GroupName)
+	})
+	for _, subscription := range sc.subscriptions.list() {
+		state.Subscriptions = append(state.Subscriptions, introspection.SubscriptionState{
+			ServiceName: subscription.ServiceName,
+			GroupName:   subscription.GroupName,
+			Clusters:    subscription.Clusters,
+		})
+	}
+	return state
+}
+
+// resubscriber is implemented by the proxy which is able to subscribe the service again regardless of the local cache
+type resubscriber interface {
+	Resubscribe(serviceName, groupName, clusters string) (model.Service, error)
+}
+
+// Resubscribe subscribe the services matching serviceName and groupName from server again, empty serviceName or groupName
+// matches all, the callbacks are notified when the instances changed. It's used by the admin handler of introspection
+func (sc *NamingClient) Resubscribe(serviceName, groupName string) (count int, err error) {
+	for _, subscription := range sc.subscriptions.list() {
+		if (serviceName != "" && subscription.ServiceName != serviceName) || (groupName != "" && subscription.GroupName != groupName) {
+			continue
+		}
+		clusters := strings.Join(subscription.Clusters, ",")
+		var subscribeErr error
+		if proxy, ok := sc.serviceProxy.(resubscriber); ok {
+			_, subscribeErr = proxy.Resubscribe(subscription.ServiceName, subscription.GroupName, clusters)
+		} else {
+			_, subscribeErr = sc.serviceProxy.Subscribe(subscription.ServiceName, subscription.GroupName, clusters)
+		}
+		if subscribeErr != nil {
+			err = errors.Wrapf(subscribeErr, "resubscribe service %s failed", subscription.ServiceName)
+			sc.errorRecorder.Record(err)
+			continue
+		}
+		count++
+	}
+	return count, err
+}
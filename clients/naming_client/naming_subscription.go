@@ -0,0 +1,127 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/cache"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"github.com/pkg/errors"
+)
+
+// serviceSubscriptions track the subscribed services, and persist them in cacheDir when enabled
+// so that a restarted process can subscribe them again by RestoreSubscriptions
+type serviceSubscriptions struct {
+	mux       sync.Mutex
+	persist   bool
+	cacheDir  string
+	namespace string
+	items     map[string]cache.ServiceSubscription
+}
+
+func newServiceSubscriptions(persist bool, cacheDir, namespace string) *serviceSubscriptions {
+	return &serviceSubscriptions{
+		persist:   persist,
+		cacheDir:  cacheDir + string(os.PathSeparator) + "naming",
+		namespace: namespace,
+		items:     make(map[string]cache.ServiceSubscription),
+	}
+}
+
+func (s *serviceSubscriptions) add(key string, subscription cache.ServiceSubscription) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if _, ok := s.items[key]; ok {
+		return
+	}
+	s.items[key] = subscription
+	s.save()
+}
+
+func (s *serviceSubscriptions) remove(key string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if _, ok := s.items[key]; !ok {
+		return
+	}
+	delete(s.items, key)
+	s.save()
+}
+
+// list return the subscribed services sorted by key
+func (s *serviceSubscriptions) list() []cache.ServiceSubscription {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.sorted()
+}
+
+func (s *serviceSubscriptions) sorted() []cache.ServiceSubscription {
+	keys := make([]string, 0, len(s.items))
+	for key := range s.items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	subscriptions := make([]cache.ServiceSubscription, 0, len(keys))
+	for _, key := range keys {
+		subscriptions = append(subscriptions, s.items[key])
+	}
+	return subscriptions
+}
+
+func (s *serviceSubscriptions) save() {
+	if !s.persist {
+		return
+	}
+	if err := cache.WriteSubscriptionsToFile(s.cacheDir, s.namespace, s.sorted()); err != nil {
+		logger.Errorf("persist service subscriptions failed, err:%+v", err)
+	}
+}
+
+func (s *serviceSubscriptions) load() ([]cache.ServiceSubscription, error) {
+	var subscriptions []cache.ServiceSubscription
+	err := cache.ReadSubscriptionsFromFile(s.cacheDir, s.namespace, &subscriptions)
+	return subscriptions, err
+}
+
+// RestoreSubscriptions subscribe the services persisted by the previous process again,
+// param is the template of subscriptions, the ServiceName, GroupName and Clusters of it are ignored
+func (sc *NamingClient) RestoreSubscriptions(param vo.SubscribeParam) ([]*vo.SubscribeParam, error) {
+	if param.SubscribeCallback == nil && param.ServiceCallback == nil {
+		return nil, errors.New("[client.RestoreSubscriptions] SubscribeCallback or ServiceCallback can not be empty")
+	}
+	subscriptions, err := sc.subscriptions.load()
+	if err != nil {
+		return nil, err
+	}
+	restored := make([]*vo.SubscribeParam, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		p := param
+		p.ServiceName = subscription.ServiceName
+		p.GroupName = subscription.GroupName
+		p.Clusters = subscription.Clusters
+		if err = sc.Subscribe(&p); err != nil {
+			return restored, err
+		}
+		restored = append(restored, &p)
+	}
+	logger.Infof("restored %d service subscriptions", len(restored))
+	return restored, nil
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// WaitForService block until at least param.MinHealthy healthy instances of the service are known from the cache,
+// the initial query or a push, and return the healthy instances. It fails with the error of ctx when ctx is done first
+func (sc *NamingClient) WaitForService(ctx context.Context, param vo.WaitForServiceParam) ([]model.Instance, error) {
+	if len(param.ServiceName) == 0 {
+		return nil, errors.New("[client.WaitForService] ServiceName can not be empty")
+	}
+	param.GroupName = sc.GroupOf(param.GroupName)
+	if param.MinHealthy <= 0 {
+		param.MinHealthy = 1
+	}
+	ready := make(chan []model.Instance, 1)
+	check := func(services []model.Instance, err error) {
+		healthy, _ := sc.selectInstances(model.Service{Hosts: services}, true)
+		if len(healthy) < param.MinHealthy {
+			return
+		}
+		select {
+		case ready <- healthy:
+		default:
+		}
+	}
+	subscribeParam := &vo.SubscribeParam{
+		ServiceName:       param.ServiceName,
+		Clusters:          param.Clusters,
+		GroupName:         param.GroupName,
+		SubscribeCallback: check,
+	}
+	if err := sc.Subscribe(subscribeParam); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = sc.Unsubscribe(subscribeParam)
+	}()
+	// the callback is not triggered when the service is already cached
+	if service, ok := sc.serviceInfoHolder.GetServiceInfo(param.ServiceName, param.GroupName, strings.Join(param.Clusters, ",")); ok {
+		check(service.Hosts, nil)
+	}
+	select {
+	case instances := <-ready:
+		return instances, nil
+	case <-ctx.Done():
+		return nil, errors.Wrapf(ctx.Err(), "[client.WaitForService] %d healthy instances of service %s are not known",
+			param.MinHealthy, param.ServiceName)
+	}
+}
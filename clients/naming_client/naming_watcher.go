@@ -0,0 +1,123 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming_client
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client/naming_cache"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"github.com/pkg/errors"
+)
+
+// instanceWatcher turns the subscribe callback into a stream of instance diffs,
+// changes received while the consumer is busy are merged into one event
+type instanceWatcher struct {
+	param  vo.WatchParam
+	mux    sync.Mutex
+	latest []model.Instance
+	notify chan struct{}
+	events chan model.InstanceChangeEvent
+}
+
+func newInstanceWatcher(param vo.WatchParam) *instanceWatcher {
+	return &instanceWatcher{
+		param:  param,
+		notify: make(chan struct{}, 1),
+		events: make(chan model.InstanceChangeEvent),
+	}
+}
+
+func (w *instanceWatcher) onChange(services []model.Instance, err error) {
+	instances := make([]model.Instance, len(services))
+	copy(instances, services)
+	w.mux.Lock()
+	w.latest = instances
+	w.mux.Unlock()
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (w *instanceWatcher) run(ctx context.Context, stop func()) {
+	defer close(w.events)
+	defer stop()
+	var (
+		last  []model.Instance
+		first = true
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.notify:
+		}
+		w.mux.Lock()
+		current := w.latest
+		w.mux.Unlock()
+		added, removed, modified := naming_cache.DiffInstances(last, current)
+		if !first && len(added) == 0 && len(removed) == 0 && len(modified) == 0 {
+			continue
+		}
+		first = false
+		last = current
+		event := model.InstanceChangeEvent{
+			ServiceName: w.param.ServiceName,
+			GroupName:   w.param.GroupName,
+			Clusters:    strings.Join(w.param.Clusters, ","),
+			Instances:   current,
+			Added:       added,
+			Removed:     removed,
+			Modified:    modified,
+		}
+		select {
+		case w.events <- event:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Watch ...
+func (sc *NamingClient) Watch(ctx context.Context, param vo.WatchParam) (<-chan model.InstanceChangeEvent, error) {
+	if len(param.ServiceName) == 0 {
+		return nil, errors.New("[client.Watch] ServiceName can not be empty")
+	}
+	param.GroupName = sc.GroupOf(param.GroupName)
+	w := newInstanceWatcher(param)
+	subscribeParam := &vo.SubscribeParam{
+		ServiceName:       param.ServiceName,
+		Clusters:          param.Clusters,
+		GroupName:         param.GroupName,
+		SubscribeCallback: w.onChange,
+	}
+	if err := sc.Subscribe(subscribeParam); err != nil {
+		return nil, err
+	}
+	// the callback is not triggered when the service is already cached, so start with the cached snapshot
+	if service, ok := sc.serviceInfoHolder.GetServiceInfo(param.ServiceName, param.GroupName, strings.Join(param.Clusters, ",")); ok {
+		w.onChange(service.Hosts, nil)
+	}
+	go w.run(ctx, func() {
+		_ = sc.Unsubscribe(subscribeParam)
+	})
+	return w.events, nil
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package routing
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"github.com/pkg/errors"
+)
+
+// RouteRule route the requests carrying the matched labels to the instances carrying the selector metadata.
+//
+//	{"rules": [{"service": "DEFAULT_GROUP@@demo", "match": {"region": "hz"}, "selector": {"version": "v2"}, "percent": 10,
+//	  "weights": [{"selector": {"zone": "hz-a"}, "weight": 3}]}]}
+type RouteRule struct {
+	// Service is the groupName@@serviceName the rule applies to, empty means all services
+	Service string `json:"service"`
+	// Match is the request labels the rule applies to, empty matches any request
+	Match map[string]string `json:"match"`
+	// Selector is the metadata of instances the requests are routed to
+	Selector map[string]string `json:"selector"`
+	// Percent is the percentage of clients routed by the rule, 0 means all, used for canary release. The clients are
+	// bucketed by the hash of their id, so a client stays in or out of the canary until the percent changes
+	Percent int `json:"percent"`
+	// Weights scale the weight of the selected instances, the first matched one applies to each instance
+	Weights []WeightRule `json:"weights"`
+}
+
+// WeightRule scale the weight of the instances carrying the selector metadata, so that the weighted balancer
+// sends them the share of traffic accordingly
+type WeightRule struct {
+	// Selector is the metadata of instances the weight applies to
+	Selector map[string]string `json:"selector"`
+	// Weight is the factor the instance weight is multiplied by, 0 excludes the instances
+	Weight float64 `json:"weight"`
+}
+
+type RouteRules struct {
+	Rules []RouteRule `json:"rules"`
+}
+
+// ConfigListener is the part of config client used to load and watch the rules
+type ConfigListener interface {
+	GetConfig(param vo.ConfigParam) (string, error)
+	ListenConfig(param vo.ConfigParam) error
+}
+
+// Router filter instances according to the route rules, the first matched rule wins.
+// When no instance is selected by the rule, all instances are returned
+type Router struct {
+	mux   sync.RWMutex
+	rules []RouteRule
+}
+
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Update replace the rules by the json content, empty content clears the rules
+func (r *Router) Update(content string) error {
+	var rules RouteRules
+	if len(content) > 0 {
+		if err := json.Unmarshal([]byte(content), &rules); err != nil {
+			return errors.Wrap(err, "parse route rules failed")
+		}
+	}
+	r.mux.Lock()
+	r.rules = rules.Rules
+	r.mux.Unlock()
+	return nil
+}
+
+// Listen load the rules from the config and update the router when the config changes
+func (r *Router) Listen(client ConfigListener, dataId, group string) error {
+	content, err := client.GetConfig(vo.ConfigParam{DataId: dataId, Group: group})
+	if err != nil {
+		return err
+	}
+	if err = r.Update(content); err != nil {
+		return err
+	}
+	return client.ListenConfig(vo.ConfigParam{
+		DataId: dataId,
+		Group:  group,
+		OnChange: func(namespace, group, dataId, data string) {
+			if err := r.Update(data); err != nil {
+				logger.Errorf("update route rules of dataId:%s group:%s failed, err:%v", dataId, group, err)
+			}
+		},
+	})
+}
+
+// Route return the instances the request with labels is routed to, service is groupName@@serviceName and
+// clientId is the id the client is bucketed by for the canary percent
+func (r *Router) Route(service, clientId string, labels map[string]string, instances []model.Instance) []model.Instance {
+	if r == nil || len(instances) == 0 {
+		return instances
+	}
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	for _, rule := range r.rules {
+		if len(rule.Service) > 0 && rule.Service != service {
+			continue
+		}
+		if !containsAll(labels, rule.Match) {
+			continue
+		}
+		if rule.Percent > 0 && rule.Percent < 100 && bucket(clientId) >= rule.Percent {
+			continue
+		}
+		var result []model.Instance
+		for _, instance := range instances {
+			if containsAll(instance.Metadata, rule.Selector) {
+				result = append(result, instance)
+			}
+		}
+		if len(result) == 0 {
+			return instances
+		}
+		return weigh(result, rule.Weights)
+	}
+	return instances
+}
+
+// bucket return the stable bucket in [0, 100) of the client
+func bucket(clientId string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(clientId))
+	return int(h.Sum32() % 100)
+}
+
+// weigh return the copies of instances with the weights scaled, the instances given are shared by the cache and
+// never changed. When every instance is excluded, the instances are returned as is
+func weigh(instances []model.Instance, weights []WeightRule) []model.Instance {
+	if len(weights) == 0 {
+		return instances
+	}
+	result := make([]model.Instance, 0, len(instances))
+	for _, instance := range instances {
+		excluded := false
+		for _, w := range weights {
+			if containsAll(instance.Metadata, w.Selector) {
+				instance.Weight *= w.Weight
+				excluded = w.Weight <= 0
+				break
+			}
+		}
+		if !excluded {
+			result = append(result, instance)
+		}
+	}
+	if len(result) == 0 {
+		return instances
+	}
+	return result
+}
+
+func containsAll(m map[string]string, sub map[string]string) bool {
+	for k, v := range sub {
+		if m[k] != v {
+			return false
+		}
+	}
+	return true
+}
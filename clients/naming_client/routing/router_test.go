@@ -0,0 +1,114 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package routing
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"github.com/stretchr/testify/assert"
+)
+
+var routeInstances = []model.Instance{
+	{Ip: "10.0.0.1", Metadata: map[string]string{"version": "v1"}},
+	{Ip: "10.0.0.2", Metadata: map[string]string{"version": "v2"}},
+	{Ip: "10.0.0.3", Metadata: map[string]string{"version": "v2", "region": "hz"}},
+}
+
+type fakeConfigListener struct {
+	content  string
+	onChange func(namespace, group, dataId, data string)
+}
+
+func (f *fakeConfigListener) GetConfig(param vo.ConfigParam) (string, error) {
+	return f.content, nil
+}
+
+func (f *fakeConfigListener) ListenConfig(param vo.ConfigParam) error {
+	f.onChange = param.OnChange
+	return nil
+}
+
+func TestRouter_Route(t *testing.T) {
+	router := NewRouter()
+	assert.Equal(t, routeInstances, router.Route("DEFAULT_GROUP@@demo", "client", nil, routeInstances))
+
+	err := router.Update(`{"rules": [
+		{"service": "DEFAULT_GROUP@@demo", "match": {"user": "beta"}, "selector": {"version": "v2", "region": "hz"}},
+		{"service": "DEFAULT_GROUP@@demo", "selector": {"version": "v1"}},
+		{"selector": {"version": "v3"}}
+	]}`)
+	assert.Nil(t, err)
+	assert.Equal(t, routeInstances[2:], router.Route("DEFAULT_GROUP@@demo", "client", map[string]string{"user": "beta"}, routeInstances))
+	assert.Equal(t, routeInstances[:1], router.Route("DEFAULT_GROUP@@demo", "client", nil, routeInstances))
+	// fall back to all instances when no instance is selected
+	assert.Equal(t, routeInstances, router.Route("DEFAULT_GROUP@@other", "client", nil, routeInstances))
+
+	assert.Error(t, router.Update("invalid"))
+	var nilRouter *Router
+	assert.Equal(t, routeInstances, nilRouter.Route("DEFAULT_GROUP@@demo", "client", nil, routeInstances))
+}
+
+func TestRouter_Listen(t *testing.T) {
+	router := NewRouter()
+	listener := &fakeConfigListener{content: `{"rules": [{"selector": {"version": "v1"}}]}`}
+	assert.Nil(t, router.Listen(listener, "route-rules", "DEFAULT_GROUP"))
+	assert.Equal(t, routeInstances[:1], router.Route("DEFAULT_GROUP@@demo", "client", nil, routeInstances))
+
+	listener.onChange("", "DEFAULT_GROUP", "route-rules", "")
+	assert.Equal(t, routeInstances, router.Route("DEFAULT_GROUP@@demo", "client", nil, routeInstances))
+}
+
+func TestRouter_RoutePercent(t *testing.T) {
+	router := NewRouter()
+	assert.Nil(t, router.Update(`{"rules": [{"selector": {"version": "v2"}, "percent": 30}]}`))
+	routed := 0
+	for i := 0; i < 1000; i++ {
+		clientId := "client-" + strconv.Itoa(i)
+		result := router.Route("DEFAULT_GROUP@@demo", clientId, nil, routeInstances)
+		// the client stays in the same bucket
+		for j := 0; j < 5; j++ {
+			assert.Equal(t, result, router.Route("DEFAULT_GROUP@@demo", clientId, nil, routeInstances))
+		}
+		if len(result) == 2 {
+			routed++
+		}
+	}
+	assert.InDelta(t, 300, routed, 60)
+}
+
+func TestRouter_RouteWeights(t *testing.T) {
+	instances := []model.Instance{
+		{Ip: "10.0.0.1", Weight: 1, Metadata: map[string]string{"version": "v2", "zone": "hz-a"}},
+		{Ip: "10.0.0.2", Weight: 2, Metadata: map[string]string{"version": "v2", "zone": "hz-b"}},
+		{Ip: "10.0.0.3", Weight: 1, Metadata: map[string]string{"version": "v2", "zone": "hz-c"}},
+	}
+	router := NewRouter()
+	assert.Nil(t, router.Update(`{"rules": [{"selector": {"version": "v2"},
+		"weights": [{"selector": {"zone": "hz-a"}, "weight": 3}, {"selector": {"zone": "hz-c"}, "weight": 0}]}]}`))
+	result := router.Route("DEFAULT_GROUP@@demo", "client", nil, instances)
+	assert.Len(t, result, 2)
+	assert.Equal(t, 3.0, result[0].Weight)
+	assert.Equal(t, 2.0, result[1].Weight)
+	// the cached instances are not changed
+	assert.Equal(t, 1.0, instances[0].Weight)
+
+	assert.Nil(t, router.Update(`{"rules": [{"selector": {"version": "v2"}, "weights": [{"weight": 0}]}]}`))
+	assert.Equal(t, instances, router.Route("DEFAULT_GROUP@@demo", "client", nil, instances))
+}
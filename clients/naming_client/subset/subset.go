@@ -0,0 +1,89 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package subset chooses a stable subset of the instances of very large services for each client, so that each
+// client maintains fewer connections while the fleet of clients still covers all instances evenly
+package subset
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
+// Subset choose size instances for the client by weighted rendezvous hashing. The subset is deterministic for the
+// same client key and instances, an instance joining or leaving changes at most one instance of the subset, and the
+// chance of an instance being chosen is proportional to its weight. All instances are returned when size is not
+// positive or not less than the number of instances, the instances are returned in their original order
+func Subset(clientKey string, instances []model.Instance, size int) []model.Instance {
+	if size <= 0 || size >= len(instances) {
+		return instances
+	}
+	type scored struct {
+		index int
+		score float64
+	}
+	scores := make([]scored, 0, len(instances))
+	for i, instance := range instances {
+		scores = append(scores, scored{index: i, score: score(clientKey, instance)})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].score == scores[j].score {
+			return scores[i].index < scores[j].index
+		}
+		return scores[i].score > scores[j].score
+	})
+	chosen := make([]int, 0, size)
+	for _, s := range scores[:size] {
+		chosen = append(chosen, s.index)
+	}
+	sort.Ints(chosen)
+	result := make([]model.Instance, 0, size)
+	for _, i := range chosen {
+		result = append(result, instances[i])
+	}
+	return result
+}
+
+// score is -weight/ln(h) of the hash h of client and instance mapped into (0, 1), the instances of zero weight
+// are chosen at last
+func score(clientKey string, instance model.Instance) float64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(clientKey))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(instance.ClusterName + "#" + instance.Ip + ":" + strconv.FormatUint(instance.Port, 10)))
+	sum := mix(h.Sum64())
+	// the top 53 bits are mapped into (0, 1)
+	unit := (float64(sum>>11) + 0.5) / (1 << 53)
+	weight := instance.Weight
+	if weight <= 0 {
+		return math.Inf(-1)
+	}
+	return -weight / math.Log(unit)
+}
+
+// mix is the finalizer of murmur3, fnv alone is not uniform enough on the similar keys such as the ips of a subnet
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
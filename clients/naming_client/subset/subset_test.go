@@ -0,0 +1,85 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package subset
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
+func instances(n int) []model.Instance {
+	var result []model.Instance
+	for i := 0; i < n; i++ {
+		result = append(result, model.Instance{Ip: fmt.Sprintf("10.0.%d.%d", i/256, i%256), Port: 8080, Weight: 1})
+	}
+	return result
+}
+
+func TestSubset_Stable(t *testing.T) {
+	all := instances(100)
+	subset := Subset("client-a", all, 10)
+	assert.Len(t, subset, 10)
+	assert.Equal(t, subset, Subset("client-a", all, 10))
+	assert.NotEqual(t, subset, Subset("client-b", all, 10))
+	assert.Equal(t, all, Subset("client-a", all, 0))
+	assert.Equal(t, all[:5], Subset("client-a", all[:5], 10))
+
+	// removing an instance out of the subset keeps the subset
+	var rest []model.Instance
+	for _, instance := range all {
+		if instance.Ip != subset[0].Ip {
+			rest = append(rest, instance)
+		}
+	}
+	changed := Subset("client-a", rest, 10)
+	assert.Equal(t, subset[1:], intersect(subset, changed))
+}
+
+func TestSubset_Coverage(t *testing.T) {
+	all := instances(50)
+	all[0].Weight = 4
+	counts := make(map[string]int)
+	for i := 0; i < 2000; i++ {
+		for _, instance := range Subset(fmt.Sprintf("client-%d", i), all, 5) {
+			counts[instance.Ip]++
+		}
+	}
+	// every instance is chosen by 2000*5/50 = 200 clients on average
+	assert.Len(t, counts, 50)
+	for ip, count := range counts {
+		if ip != all[0].Ip {
+			assert.InDelta(t, 200, count, 80, ip)
+		}
+	}
+	assert.Greater(t, counts[all[0].Ip], 500)
+}
+
+func intersect(a, b []model.Instance) []model.Instance {
+	var result []model.Instance
+	for _, x := range a {
+		for _, y := range b {
+			if x.Ip == y.Ip {
+				result = append(result, x)
+			}
+		}
+	}
+	return result
+}
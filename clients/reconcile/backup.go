@@ -0,0 +1,330 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reconcile
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+const (
+	backupManifest   = "manifest.json"
+	backupConfigsDir = "configs/"
+)
+
+// RestoreResult is the outcome of Restore
+type RestoreResult struct {
+	Result
+	// Last is the key of the last action done before the first failure, pass it as ResumeAfter to resume the restore
+	Last string
+}
+
+// Backup write the configs of the namespace to dst as a tar.gz archive, the content of each config is stored in
+// configs/{group}/{dataId} and the manifest with the sha256 of each content is written last. The pages are
+// searched with the backoff on server throttling of ConfigIterator
+func Backup(client config_client.IConfigClient, namespaceId string, dst io.Writer) (*model.BackupManifest, error) {
+	if client == nil {
+		return nil, errors.New("[reconcile.Backup] client can not be nil")
+	}
+	manifest := &model.BackupManifest{NamespaceId: namespaceId, Time: time.Now()}
+	items, err := listBackupItems(client, namespaceId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[reconcile.Backup] search configs of namespace %s failed", namespaceId)
+	}
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	gw := gzip.NewWriter(dst)
+	tw := tar.NewWriter(gw)
+	for _, key := range keys {
+		item := items[key]
+		if err = writeTarFile(tw, backupConfigsDir+key, []byte(item.Content)); err != nil {
+			return nil, err
+		}
+		manifest.Entries = append(manifest.Entries, model.BackupEntry{
+			DataId: item.DataId,
+			Group:  item.Group,
+			Type:   item.Type,
+			Size:   len(item.Content),
+			Sha256: sha256Hex(item.Content),
+		})
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "[reconcile.Backup] encode manifest failed")
+	}
+	if err = writeTarFile(tw, backupManifest, data); err != nil {
+		return nil, err
+	}
+	if err = tw.Close(); err != nil {
+		return nil, errors.Wrap(err, "[reconcile.Backup] close archive failed")
+	}
+	if err = gw.Close(); err != nil {
+		return nil, errors.Wrap(err, "[reconcile.Backup] close archive failed")
+	}
+	logger.Infof("[reconcile] backup %d configs of namespace %s", len(manifest.Entries), namespaceId)
+	return manifest, nil
+}
+
+// Restore verify the archive written by Backup against the checksums of its manifest, and drive the configs on server
+// to the backup. Nothing is applied when the archive is corrupted. The actions are planned and applied in the order of
+// group and dataId like Reconciler.Apply, the configs unchanged since the backup are not written again
+func Restore(client config_client.IConfigClient, src io.Reader, param vo.RestoreParam) (*RestoreResult, error) {
+	if client == nil {
+		return nil, errors.New("[reconcile.Restore] client can not be nil")
+	}
+	manifest, contents, err := readBackup(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(param.NamespaceId) == 0 {
+		param.NamespaceId = manifest.NamespaceId
+	}
+	current, err := listBackupItems(client, param.NamespaceId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[reconcile.Restore] search configs of namespace %s failed", param.NamespaceId)
+	}
+	actions := planRestore(manifest, contents, current, param)
+	types := make(map[string]string, len(manifest.Entries))
+	for _, entry := range manifest.Entries {
+		types[backupKey(entry.Group, entry.DataId)] = entry.Type
+	}
+
+	result := &RestoreResult{Last: param.ResumeAfter}
+	var firstErr error
+	for _, action := range actions {
+		key := backupKey(action.Group, action.DataId)
+		if param.Diff != nil {
+			writeActionDiff(param.Diff, action, current[key].Content)
+		}
+		if param.DryRun || (param.Confirm != nil && !param.Confirm(action)) {
+			result.Skipped = append(result.Skipped, action)
+			continue
+		}
+		if err := applyAction(client, action, param.NamespaceId, types[key]); err != nil {
+			logger.Warnf("[reconcile] restore %s config dataId:%s group:%s failed, err:%v", action.Type, action.DataId, action.Group, err)
+			result.Failed = append(result.Failed, action)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		result.Applied = append(result.Applied, action)
+		if firstErr == nil {
+			result.Last = key
+		}
+	}
+	if firstErr != nil {
+		return result, errors.Wrapf(firstErr, "[reconcile.Restore] %d of %d actions failed", len(result.Failed), len(actions))
+	}
+	return result, nil
+}
+
+// readBackup read the archive and verify the size and checksum of each config, the contents are keyed by backupKey
+func readBackup(src io.Reader) (*model.BackupManifest, map[string]string, error) {
+	gr, err := gzip.NewReader(src)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "[reconcile.Restore] not a backup archive")
+	}
+	var manifest *model.BackupManifest
+	files := make(map[string]string)
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "[reconcile.Restore] read archive failed")
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "[reconcile.Restore] read %s failed", header.Name)
+		}
+		switch {
+		case header.Name == backupManifest:
+			manifest = &model.BackupManifest{}
+			if err = json.Unmarshal(data, manifest); err != nil {
+				return nil, nil, errors.Wrap(err, "[reconcile.Restore] decode manifest failed")
+			}
+		case strings.HasPrefix(header.Name, backupConfigsDir):
+			files[header.Name] = string(data)
+		}
+	}
+	if manifest == nil {
+		return nil, nil, errors.New("[reconcile.Restore] manifest is missing, the archive may be truncated")
+	}
+	contents := make(map[string]string, len(manifest.Entries))
+	for _, entry := range manifest.Entries {
+		name := backupConfigsDir + entry.Group + "/" + entry.DataId
+		content, ok := files[name]
+		if !ok {
+			return nil, nil, errors.Errorf("[reconcile.Restore] %s is missing", name)
+		}
+		if len(content) != entry.Size || sha256Hex(content) != entry.Sha256 {
+			return nil, nil, errors.Errorf("[reconcile.Restore] checksum of %s mismatch", name)
+		}
+		delete(files, name)
+		contents[backupKey(entry.Group, entry.DataId)] = content
+	}
+	for name := range files {
+		return nil, nil, errors.Errorf("[reconcile.Restore] %s is not in the manifest", name)
+	}
+	return manifest, contents, nil
+}
+
+// planRestore compute the actions to drive the current configs on server to the backup, sorted by backupKey and the
+// ones up to ResumeAfter are dropped
+func planRestore(manifest *model.BackupManifest, contents map[string]string, current map[string]model.ConfigItem,
+	param vo.RestoreParam) []model.ReconcileAction {
+	var actions []model.ReconcileAction
+	for _, entry := range manifest.Entries {
+		key := backupKey(entry.Group, entry.DataId)
+		content := contents[key]
+		item, ok := current[key]
+		switch {
+		case len(content) == 0:
+		case !ok:
+			actions = append(actions, model.ReconcileAction{Type: model.ReconcileCreate, DataId: entry.DataId, Group: entry.Group, Content: content})
+		case item.Content != content:
+			actions = append(actions, model.ReconcileAction{Type: model.ReconcileUpdate, DataId: entry.DataId, Group: entry.Group, Content: content, Md5: itemMd5(item)})
+		}
+	}
+	if param.Prune {
+		for key, item := range current {
+			if _, ok := contents[key]; !ok {
+				actions = append(actions, model.ReconcileAction{Type: model.ReconcileDelete, DataId: item.DataId, Group: item.Group, Md5: itemMd5(item)})
+			}
+		}
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return backupKey(actions[i].Group, actions[i].DataId) < backupKey(actions[j].Group, actions[j].DataId)
+	})
+	if len(param.ResumeAfter) > 0 {
+		i := sort.Search(len(actions), func(i int) bool {
+			return backupKey(actions[i].Group, actions[i].DataId) > param.ResumeAfter
+		})
+		actions = actions[i:]
+	}
+	return actions
+}
+
+// listBackupItems list the configs of the namespace with content, keyed by backupKey
+func listBackupItems(client config_client.IConfigClient, namespaceId string) (map[string]model.ConfigItem, error) {
+	items := make(map[string]model.ConfigItem)
+	it := config_client.NewConfigIterator(client, vo.SearchConfigParam{Search: "blur", NamespaceId: namespaceId, PageSize: defaultPageSize})
+	for it.Next() {
+		items[backupKey(it.Item().Group, it.Item().DataId)] = it.Item()
+	}
+	return items, it.Err()
+}
+
+// writeActionDiff write the action and the changed lines of the config on server, the removed lines are prefixed
+// with - and the added ones with +
+func writeActionDiff(w io.Writer, action model.ReconcileAction, before string) {
+	fmt.Fprintf(w, "%s %s/%s\n", action.Type, action.Group, action.DataId)
+	var a, b []string
+	if action.Type != model.ReconcileCreate {
+		a = strings.Split(before, "\n")
+	}
+	if action.Type != model.ReconcileDelete {
+		b = strings.Split(action.Content, "\n")
+	}
+	for _, line := range diffLines(a, b) {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// diffLines return the removed and added lines between a and b by the longest common subsequence
+func diffLines(a, b []string) []string {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case j >= len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "-"+a[i])
+			i++
+		default:
+			lines = append(lines, "+"+b[j])
+			j++
+		}
+	}
+	return lines
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}
+	if err := tw.WriteHeader(header); err != nil {
+		return errors.Wrapf(err, "write %s failed", name)
+	}
+	_, err := tw.Write(data)
+	return errors.Wrapf(err, "write %s failed", name)
+}
+
+func backupKey(group, dataId string) string {
+	return group + "/" + dataId
+}
+
+func itemMd5(item model.ConfigItem) string {
+	if len(item.Md5) > 0 {
+		return item.Md5
+	}
+	return util.Md5(item.Content)
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,240 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reconcile
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+const (
+	defaultScanInterval = time.Second
+	defaultDebounce     = 500 * time.Millisecond
+)
+
+// ErrConflict is reported when the config is changed by others since last sync and the local change is skipped
+var ErrConflict = errors.New("config is changed on server since last sync")
+
+// configTypes is the config type detected from the file extension, the others are text
+var configTypes = map[string]string{
+	".yaml":       "yaml",
+	".yml":        "yaml",
+	".json":       "json",
+	".xml":        "xml",
+	".html":       "html",
+	".htm":        "html",
+	".properties": "properties",
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+	md5     string
+}
+
+// DirSyncer watches a directory and publishes the changed files to the configs of the same dataId.
+// The files are scanned every Interval, so it works on any file system without native notification
+type DirSyncer struct {
+	client config_client.IConfigClient
+	param  vo.DirSyncParam
+	// files is the state of files on last scan, keyed by dataId
+	files map[string]fileState
+	// changed is the time when the pending file is observed changed, keyed by dataId
+	changed map[string]time.Time
+	// synced is the md5 of config on server after last sync, keyed by dataId
+	synced map[string]string
+}
+
+// NewDirSyncer create the syncer of param.Dir, the syncing starts by Run
+func NewDirSyncer(client config_client.IConfigClient, param vo.DirSyncParam) (*DirSyncer, error) {
+	if client == nil {
+		return nil, errors.New("[reconcile.NewDirSyncer] client can not be nil")
+	}
+	if len(param.Dir) == 0 {
+		return nil, errors.New("[reconcile.NewDirSyncer] Dir can not be empty")
+	}
+	if len(param.Group) == 0 {
+		param.Group = constant.DEFAULT_GROUP
+	}
+	if param.Interval <= 0 {
+		param.Interval = defaultScanInterval
+	}
+	if param.Debounce <= 0 {
+		param.Debounce = defaultDebounce
+	}
+	if len(param.ConflictPolicy) == 0 {
+		param.ConflictPolicy = model.ConflictOverwrite
+	}
+	return &DirSyncer{
+		client:  client,
+		param:   param,
+		files:   make(map[string]fileState),
+		changed: make(map[string]time.Time),
+		synced:  make(map[string]string),
+	}, nil
+}
+
+// Run scan the directory every Interval until ctx is done, all files are synced on the first scan
+func (s *DirSyncer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.param.Interval)
+	defer ticker.Stop()
+	for {
+		if err := s.scan(time.Now()); err != nil {
+			logger.Warnf("[reconcile] scan dir %s failed, err:%v", s.param.Dir, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan record the changed files and sync the ones which are not changed within debounce
+func (s *DirSyncer) scan(now time.Time) error {
+	entries, err := ioutil.ReadDir(s.param.Dir)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Mode().IsRegular() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		seen[name] = struct{}{}
+		prev, ok := s.files[name]
+		if ok && prev.modTime.Equal(entry.ModTime()) && prev.size == entry.Size() {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(s.param.Dir, name))
+		if err != nil {
+			logger.Warnf("[reconcile] read file %s failed, err:%v", name, err)
+			continue
+		}
+		state := fileState{modTime: entry.ModTime(), size: entry.Size(), md5: util.Md5(string(content))}
+		if !ok || prev.md5 != state.md5 {
+			s.changed[name] = now
+		}
+		s.files[name] = state
+	}
+	for name := range s.files {
+		if _, ok := seen[name]; !ok {
+			delete(s.files, name)
+			s.changed[name] = now
+		}
+	}
+	for name, at := range s.changed {
+		if now.Sub(at) >= s.param.Debounce || s.isFirstSync(name) {
+			delete(s.changed, name)
+			if err := s.sync(name); err != nil && err != ErrConflict {
+				// retried on the following scans
+				s.changed[name] = now
+			}
+		}
+	}
+	return nil
+}
+
+// isFirstSync is true for the files never synced, so that they are synced on the first scan without debounce
+func (s *DirSyncer) isFirstSync(name string) bool {
+	_, synced := s.synced[name]
+	_, exists := s.files[name]
+	return !synced && exists
+}
+
+func (s *DirSyncer) sync(name string) error {
+	param := vo.ConfigParam{DataId: name, Group: s.param.Group, NamespaceId: s.param.NamespaceId}
+	if _, exists := s.files[name]; !exists {
+		if !s.param.DeleteOnRemove {
+			return nil
+		}
+		action := model.ReconcileAction{Type: model.ReconcileDelete, DataId: name, Group: s.param.Group}
+		deleted, err := s.client.DeleteConfig(param)
+		if err == nil && !deleted {
+			err = errors.New("server refused")
+		}
+		if err == nil {
+			delete(s.synced, name)
+		}
+		return s.report(action, err)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(s.param.Dir, name))
+	if err != nil || len(content) == 0 {
+		return err
+	}
+	localMd5 := util.Md5(string(content))
+	remote, err := s.client.GetConfig(param)
+	if err != nil {
+		return s.report(model.ReconcileAction{Type: model.ReconcileUpdate, DataId: name, Group: s.param.Group}, err)
+	}
+	remoteMd5 := util.Md5(remote)
+	if remoteMd5 == localMd5 {
+		s.synced[name] = localMd5
+		return nil
+	}
+	action := model.ReconcileAction{Type: model.ReconcileUpdate, DataId: name, Group: s.param.Group, Content: string(content), Md5: remoteMd5}
+	if len(remote) == 0 {
+		action.Type = model.ReconcileCreate
+	}
+	last, synced := s.synced[name]
+	if len(remote) > 0 && (!synced || last != remoteMd5) && s.param.ConflictPolicy == model.ConflictSkip {
+		return s.report(action, ErrConflict)
+	}
+	param.Content = action.Content
+	param.Type = configTypeOf(name)
+	param.CasMd5 = remoteMd5
+	published, err := s.client.PublishConfig(param)
+	if err == nil && !published {
+		err = errors.New("server refused")
+	}
+	if err == nil {
+		s.synced[name] = localMd5
+	}
+	return s.report(action, err)
+}
+
+func (s *DirSyncer) report(action model.ReconcileAction, err error) error {
+	if err != nil {
+		logger.Warnf("[reconcile] sync file %s to config failed, err:%v", action.DataId, err)
+	} else {
+		logger.Infof("[reconcile] %s config dataId:%s group:%s from file", action.Type, action.DataId, action.Group)
+	}
+	if s.param.OnSync != nil {
+		s.param.OnSync(action, err)
+	}
+	return err
+}
+
+func configTypeOf(name string) string {
+	if typ, ok := configTypes[strings.ToLower(filepath.Ext(name))]; ok {
+		return typ
+	}
+	return "text"
+}
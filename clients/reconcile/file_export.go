@@ -0,0 +1,178 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/file"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+const defaultCommandTimeout = 30 * time.Second
+
+// FileExporter materializes the listened configs to files for the processes which only read files, such as
+// nginx and envoy. The files are replaced atomically on each change, and the process is notified by the signal
+// or the hook command afterwards. The file is kept when the config is deleted
+type FileExporter struct {
+	client config_client.IConfigClient
+	param  vo.FileExportParam
+	// mux serializes the writes and the notifications
+	mux sync.Mutex
+}
+
+// NewFileExporter export the configs of param to files, and listen them to export again on each change
+func NewFileExporter(client config_client.IConfigClient, param vo.FileExportParam) (*FileExporter, error) {
+	if client == nil {
+		return nil, errors.New("[reconcile.NewFileExporter] client can not be nil")
+	}
+	if len(param.Configs) == 0 {
+		return nil, errors.New("[reconcile.NewFileExporter] Configs can not be empty")
+	}
+	if len(param.PathTemplate) == 0 {
+		return nil, errors.New("[reconcile.NewFileExporter] PathTemplate can not be empty")
+	}
+	for i := range param.Configs {
+		config := &param.Configs[i]
+		if len(config.DataId) == 0 || strings.ContainsAny(config.DataId, `/\`) || config.DataId == ".." {
+			return nil, errors.Errorf("[reconcile.NewFileExporter] invalid dataId %q", config.DataId)
+		}
+		if len(config.Group) == 0 {
+			config.Group = constant.DEFAULT_GROUP
+		}
+	}
+	if param.FileMode == 0 {
+		param.FileMode = 0644
+	}
+	if param.Signal == nil {
+		param.Signal = syscall.SIGHUP
+	}
+	if param.CommandTimeout <= 0 {
+		param.CommandTimeout = defaultCommandTimeout
+	}
+	e := &FileExporter{client: client, param: param}
+	for _, config := range param.Configs {
+		content, err := client.GetConfig(vo.ConfigParam{DataId: config.DataId, Group: config.Group, NamespaceId: config.NamespaceId})
+		if err != nil {
+			return nil, errors.Wrapf(err, "[reconcile.NewFileExporter] get config %s failed", config.DataId)
+		}
+		e.export(config, content)
+	}
+	for _, config := range param.Configs {
+		config := config
+		err := client.ListenConfig(vo.ConfigParam{
+			DataId:      config.DataId,
+			Group:       config.Group,
+			NamespaceId: config.NamespaceId,
+			OnChange: func(namespace, group, dataId, data string) {
+				e.export(config, data)
+			},
+		})
+		if err != nil {
+			e.Close()
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// Path return the path of file which the config is exported to
+func (e *FileExporter) Path(config vo.ConfigParam) string {
+	return strings.NewReplacer("{dataId}", config.DataId, "{group}", config.Group, "{namespaceId}", config.NamespaceId).
+		Replace(e.param.PathTemplate)
+}
+
+// Close cancel the listening of configs, the exported files are kept
+func (e *FileExporter) Close() {
+	for _, config := range e.param.Configs {
+		_ = e.client.CancelListenConfig(vo.ConfigParam{DataId: config.DataId, Group: config.Group, NamespaceId: config.NamespaceId})
+	}
+}
+
+// export write the content to the file of config, the process is notified only if the file is changed
+func (e *FileExporter) export(config vo.ConfigParam, content string) {
+	if len(content) == 0 {
+		return
+	}
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	path := e.Path(config)
+	if current, err := ioutil.ReadFile(path); err == nil && bytes.Equal(current, []byte(content)) {
+		return
+	}
+	err := file.WriteFileAtomic(path, []byte(content), e.param.FileMode)
+	if err == nil {
+		logger.Infof("[reconcile] export config dataId:%s group:%s to file %s", config.DataId, config.Group, path)
+		err = e.notify()
+	}
+	if err != nil {
+		logger.Warnf("[reconcile] export config dataId:%s to file %s failed, err:%v", config.DataId, path, err)
+	}
+	if e.param.OnExport != nil {
+		e.param.OnExport(path, err)
+	}
+}
+
+// notify send the signal to the process of PidFile and execute the hook command
+func (e *FileExporter) notify() error {
+	if len(e.param.PidFile) > 0 {
+		data, err := ioutil.ReadFile(e.param.PidFile)
+		if err != nil {
+			return errors.Wrapf(err, "read pid file %s failed", e.param.PidFile)
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil {
+			return errors.Wrapf(err, "invalid pid file %s", e.param.PidFile)
+		}
+		process, err := os.FindProcess(pid)
+		if err == nil {
+			err = process.Signal(e.param.Signal)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "signal process %d failed", pid)
+		}
+	}
+	return runCommand(e.param.Command, e.param.CommandTimeout)
+}
+
+// runCommand execute the command if it's not empty, the output is carried by the error when it fails
+func runCommand(command []string, timeout time.Duration) error {
+	if len(command) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, command[0], command[1:]...).CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "execute command %s failed, output:%s", strings.Join(command, " "), output)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package reconcile drives the configs on server to the desired state, such as the files kept in a git repository.
+// The diff against server is planned as create, update and delete actions, which are applied after confirmation
+package reconcile
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+const defaultPageSize = 100
+
+// Reconciler reconciles the configs of a group
+type Reconciler struct {
+	client config_client.IConfigClient
+	param  vo.ReconcileParam
+}
+
+// Result is the outcome of Apply
+type Result struct {
+	Applied []model.ReconcileAction
+	Skipped []model.ReconcileAction // declined by Confirm or not applied in dry-run
+	Failed  []model.ReconcileAction
+}
+
+// NewReconciler create the reconciler of param.Group
+func NewReconciler(client config_client.IConfigClient, param vo.ReconcileParam) (*Reconciler, error) {
+	if client == nil {
+		return nil, errors.New("[reconcile.NewReconciler] client can not be nil")
+	}
+	if len(param.Group) == 0 {
+		param.Group = constant.DEFAULT_GROUP
+	}
+	if param.PageSize <= 0 {
+		param.PageSize = defaultPageSize
+	}
+	return &Reconciler{client: client, param: param}, nil
+}
+
+// LoadDir load the desired configs from the regular files of dir, the file name is the dataId and the hidden
+// files are ignored
+func LoadDir(dir string) (map[string]string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[reconcile.LoadDir] read dir %s failed", dir)
+	}
+	desired := make(map[string]string, len(files))
+	for _, f := range files {
+		if !f.Mode().IsRegular() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "[reconcile.LoadDir] read file %s failed", f.Name())
+		}
+		desired[f.Name()] = string(content)
+	}
+	return desired, nil
+}
+
+// Plan compute the actions to drive the configs on server to desired, keyed by dataId. The actions are sorted
+// by dataId, the configs with empty desired content are regarded as not desired
+func (r *Reconciler) Plan(desired map[string]string) ([]model.ReconcileAction, error) {
+	current, err := r.listCurrent()
+	if err != nil {
+		return nil, err
+	}
+	var actions []model.ReconcileAction
+	for dataId, content := range desired {
+		if len(content) == 0 {
+			continue
+		}
+		key, ok := current[dataId]
+		switch {
+		case !ok:
+			actions = append(actions, model.ReconcileAction{Type: model.ReconcileCreate, DataId: dataId, Group: r.param.Group, Content: content})
+		case key.Md5 != util.Md5(content):
+			actions = append(actions, model.ReconcileAction{Type: model.ReconcileUpdate, DataId: dataId, Group: r.param.Group, Content: content, Md5: key.Md5})
+		}
+	}
+	if r.param.Prune {
+		for dataId, key := range current {
+			if len(desired[dataId]) == 0 {
+				actions = append(actions, model.ReconcileAction{Type: model.ReconcileDelete, DataId: dataId, Group: r.param.Group, Md5: key.Md5})
+			}
+		}
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].DataId < actions[j].DataId
+	})
+	return actions, nil
+}
+
+// Apply plan the actions and apply the confirmed ones, the failed actions don't stop the others and
+// the first failure is returned. The updates are published with compare-and-swap against the planned md5,
+// so that the changes made on server after planning are not overwritten
+func (r *Reconciler) Apply(desired map[string]string) (*Result, error) {
+	actions, err := r.Plan(desired)
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{}
+	var firstErr error
+	for _, action := range actions {
+		if r.param.DryRun || (r.param.Confirm != nil && !r.param.Confirm(action)) {
+			result.Skipped = append(result.Skipped, action)
+			continue
+		}
+		if err := applyAction(r.client, action, r.param.NamespaceId, r.param.Type); err != nil {
+			logger.Warnf("[reconcile] %s config dataId:%s group:%s failed, err:%v", action.Type, action.DataId, action.Group, err)
+			result.Failed = append(result.Failed, action)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		logger.Infof("[reconcile] %s config dataId:%s group:%s", action.Type, action.DataId, action.Group)
+		result.Applied = append(result.Applied, action)
+	}
+	if firstErr != nil {
+		return result, errors.Wrapf(firstErr, "[reconcile] %d of %d actions failed", len(result.Failed), len(actions))
+	}
+	return result, nil
+}
+
+func applyAction(client config_client.IConfigClient, action model.ReconcileAction, namespaceId, configType string) error {
+	param := vo.ConfigParam{DataId: action.DataId, Group: action.Group, NamespaceId: namespaceId}
+	var (
+		done bool
+		err  error
+	)
+	switch action.Type {
+	case model.ReconcileDelete:
+		done, err = client.DeleteConfig(param)
+	default:
+		param.Content = action.Content
+		param.Type = configType
+		param.CasMd5 = action.Md5
+		done, err = client.PublishConfig(param)
+	}
+	if err == nil && !done {
+		err = errors.New("server refused")
+	}
+	return errors.Wrapf(err, "%s config %s failed", action.Type, action.DataId)
+}
+
+// listCurrent list the configs of the group on server, keyed by dataId
+func (r *Reconciler) listCurrent() (map[string]model.ConfigKey, error) {
+	current := make(map[string]model.ConfigKey)
+	for pageNo := 1; ; pageNo++ {
+		page, err := r.client.ListConfigKeys(vo.ListConfigKeysParam{
+			NamespaceId: r.param.NamespaceId,
+			Group:       r.param.Group,
+			PageNo:      pageNo,
+			PageSize:    r.param.PageSize,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "[reconcile] list configs of group %s failed", r.param.Group)
+		}
+		for _, key := range page.PageItems {
+			// the search on server is blur, so the group is matched exactly here
+			if key.Group == r.param.Group {
+				current[key.DataId] = key
+			}
+		}
+		if pageNo >= page.PagesAvailable || len(page.PageItems) == 0 {
+			return current, nil
+		}
+	}
+}
@@ -0,0 +1,344 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reconcile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// mapConfigClient keeps the configs of server in memory, the pages are listed in the order of dataId
+type mapConfigClient struct {
+	config_client.IConfigClient
+	configs   map[string]string
+	types     map[string]string
+	listeners map[string]vo.Listener
+}
+
+func (c *mapConfigClient) ListenConfig(param vo.ConfigParam) error {
+	c.listeners[param.DataId] = param.OnChange
+	return nil
+}
+
+func (c *mapConfigClient) CancelListenConfig(param vo.ConfigParam) error {
+	delete(c.listeners, param.DataId)
+	return nil
+}
+
+func (c *mapConfigClient) GetConfig(param vo.ConfigParam) (string, error) {
+	return c.configs[param.DataId], nil
+}
+
+func (c *mapConfigClient) ListConfigKeys(param vo.ListConfigKeysParam) (*model.ConfigKeyPage, error) {
+	var dataIds []string
+	for dataId := range c.configs {
+		dataIds = append(dataIds, dataId)
+	}
+	sort.Strings(dataIds)
+	page := &model.ConfigKeyPage{TotalCount: len(dataIds), PageNumber: param.PageNo,
+		PagesAvailable: (len(dataIds) + param.PageSize - 1) / param.PageSize}
+	for i := (param.PageNo - 1) * param.PageSize; i < len(dataIds) && i < param.PageNo*param.PageSize; i++ {
+		page.PageItems = append(page.PageItems, model.ConfigKey{DataId: dataIds[i], Group: param.Group, Md5: util.Md5(c.configs[dataIds[i]])})
+	}
+	return page, nil
+}
+
+func (c *mapConfigClient) SearchConfig(param vo.SearchConfigParam) (*model.ConfigPage, error) {
+	keys, err := c.ListConfigKeys(vo.ListConfigKeysParam{Group: "DEFAULT_GROUP", PageNo: param.PageNo, PageSize: param.PageSize})
+	if err != nil {
+		return nil, err
+	}
+	page := &model.ConfigPage{TotalCount: keys.TotalCount, PageNumber: keys.PageNumber, PagesAvailable: keys.PagesAvailable}
+	for _, key := range keys.PageItems {
+		page.PageItems = append(page.PageItems, model.ConfigItem{DataId: key.DataId, Group: key.Group, Content: c.configs[key.DataId]})
+	}
+	return page, nil
+}
+
+func (c *mapConfigClient) PublishConfig(param vo.ConfigParam) (bool, error) {
+	if len(param.CasMd5) > 0 && util.Md5(c.configs[param.DataId]) != param.CasMd5 {
+		return false, nil
+	}
+	c.configs[param.DataId] = param.Content
+	if c.types != nil {
+		c.types[param.DataId] = param.Type
+	}
+	return true, nil
+}
+
+func (c *mapConfigClient) DeleteConfig(param vo.ConfigParam) (bool, error) {
+	delete(c.configs, param.DataId)
+	return true, nil
+}
+
+func TestReconciler_Apply(t *testing.T) {
+	client := &mapConfigClient{configs: map[string]string{"same": "v1", "changed": "v1", "stale": "v1"}}
+	desired := map[string]string{"same": "v1", "changed": "v2", "created": "v1"}
+
+	reconciler, err := NewReconciler(client, vo.ReconcileParam{Prune: true, DryRun: true, PageSize: 2})
+	assert.Nil(t, err)
+	result, err := reconciler.Apply(desired)
+	assert.Nil(t, err)
+	assert.Equal(t, []model.ReconcileAction{
+		{Type: model.ReconcileUpdate, DataId: "changed", Group: "DEFAULT_GROUP", Content: "v2", Md5: util.Md5("v1")},
+		{Type: model.ReconcileCreate, DataId: "created", Group: "DEFAULT_GROUP", Content: "v1"},
+		{Type: model.ReconcileDelete, DataId: "stale", Group: "DEFAULT_GROUP", Md5: util.Md5("v1")},
+	}, result.Skipped)
+	assert.Len(t, client.configs, 3)
+
+	reconciler.param.DryRun = false
+	reconciler.param.Confirm = func(action model.ReconcileAction) bool {
+		return action.Type != model.ReconcileDelete
+	}
+	result, err = reconciler.Apply(desired)
+	assert.Nil(t, err)
+	assert.Len(t, result.Applied, 2)
+	assert.Equal(t, "stale", result.Skipped[0].DataId)
+	assert.Equal(t, map[string]string{"same": "v1", "changed": "v2", "created": "v1", "stale": "v1"}, client.configs)
+
+	reconciler.param.Confirm = nil
+	result, err = reconciler.Apply(desired)
+	assert.Nil(t, err)
+	assert.Equal(t, "stale", result.Applied[0].DataId)
+	actions, err := reconciler.Plan(desired)
+	assert.Nil(t, err)
+	assert.Empty(t, actions)
+}
+
+func TestReconciler_ApplyConflict(t *testing.T) {
+	client := &mapConfigClient{configs: map[string]string{"changed": "v1"}}
+	reconciler, _ := NewReconciler(client, vo.ReconcileParam{})
+	reconciler.param.Confirm = func(action model.ReconcileAction) bool {
+		// the config is changed by others after planning
+		client.configs["changed"] = "v3"
+		return true
+	}
+	result, err := reconciler.Apply(map[string]string{"changed": "v2"})
+	assert.NotNil(t, err)
+	assert.Len(t, result.Failed, 1)
+	assert.Equal(t, "v3", client.configs["changed"])
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	client := &mapConfigClient{configs: map[string]string{"a": "v1", "b": "line1\nline2", "c": "v1"}}
+	var archive bytes.Buffer
+	manifest, err := Backup(client, "ns", &archive)
+	assert.Nil(t, err)
+	assert.Equal(t, "ns", manifest.NamespaceId)
+	assert.Len(t, manifest.Entries, 3)
+	assert.Equal(t, "a", manifest.Entries[0].DataId)
+
+	client.configs = map[string]string{"b": "line1\nchanged", "c": "v1", "d": "v1"}
+	var diff bytes.Buffer
+	result, err := Restore(client, bytes.NewReader(archive.Bytes()), vo.RestoreParam{Prune: true, DryRun: true, Diff: &diff})
+	assert.Nil(t, err)
+	assert.Len(t, result.Skipped, 3)
+	assert.Equal(t, "create DEFAULT_GROUP/a\n+v1\nupdate DEFAULT_GROUP/b\n-changed\n+line2\ndelete DEFAULT_GROUP/d\n-v1\n", diff.String())
+
+	result, err = Restore(client, bytes.NewReader(archive.Bytes()), vo.RestoreParam{Prune: true, ResumeAfter: "DEFAULT_GROUP/a"})
+	assert.Nil(t, err)
+	assert.Len(t, result.Applied, 2)
+	assert.Equal(t, "DEFAULT_GROUP/d", result.Last)
+	assert.Equal(t, map[string]string{"b": "line1\nline2", "c": "v1"}, client.configs)
+
+	gr, err := gzip.NewReader(bytes.NewReader(archive.Bytes()))
+	assert.Nil(t, err)
+	raw, err := ioutil.ReadAll(gr)
+	assert.Nil(t, err)
+	raw = bytes.Replace(raw, []byte("line2"), []byte("line3"), 1)
+	var tampered bytes.Buffer
+	gw := gzip.NewWriter(&tampered)
+	_, _ = gw.Write(raw)
+	assert.Nil(t, gw.Close())
+	_, err = Restore(client, &tampered, vo.RestoreParam{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum of configs/DEFAULT_GROUP/b mismatch")
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "app.yaml"), []byte("port: 80"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, ".gitkeep"), nil, 0644))
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	desired, err := LoadDir(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"app.yaml": "port: 80"}, desired)
+	_, err = LoadDir(filepath.Join(dir, "absent"))
+	assert.NotNil(t, err)
+}
+
+func TestDirSyncer(t *testing.T) {
+	dir := t.TempDir()
+	client := &mapConfigClient{configs: map[string]string{"remote.json": "{}"}, types: map[string]string{}}
+	var synced []model.ReconcileAction
+	var errs []error
+	syncer, err := NewDirSyncer(client, vo.DirSyncParam{Dir: dir, Debounce: time.Second, ConflictPolicy: model.ConflictSkip, DeleteOnRemove: true,
+		OnSync: func(action model.ReconcileAction, err error) {
+			synced = append(synced, action)
+			errs = append(errs, err)
+		}})
+	assert.Nil(t, err)
+	modTime := time.Now()
+	write := func(name, content string) {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+		modTime = modTime.Add(time.Second)
+		assert.Nil(t, os.Chtimes(filepath.Join(dir, name), modTime, modTime))
+	}
+	write("app.yaml", "port: 80")
+	write("remote.json", `{"a":1}`)
+
+	// the files are synced on the first scan, the config changed by others is not overwritten
+	now := time.Now()
+	assert.Nil(t, syncer.scan(now))
+	assert.Equal(t, "port: 80", client.configs["app.yaml"])
+	assert.Equal(t, "yaml", client.types["app.yaml"])
+	assert.Equal(t, "{}", client.configs["remote.json"])
+	assert.Len(t, synced, 2)
+	assert.Contains(t, errs, ErrConflict)
+
+	// the change is published after debounce
+	write("app.yaml", "port: 81")
+	assert.Nil(t, syncer.scan(now.Add(time.Second)))
+	write("app.yaml", "port: 8080")
+	assert.Nil(t, syncer.scan(now.Add(1500*time.Millisecond)))
+	assert.Equal(t, "port: 80", client.configs["app.yaml"])
+	assert.Nil(t, syncer.scan(now.Add(3*time.Second)))
+	assert.Equal(t, "port: 8080", client.configs["app.yaml"])
+	assert.Equal(t, model.ReconcileUpdate, synced[2].Type)
+	assert.Nil(t, errs[2])
+
+	assert.Nil(t, os.Remove(filepath.Join(dir, "app.yaml")))
+	assert.Nil(t, syncer.scan(now.Add(4*time.Second)))
+	assert.Nil(t, syncer.scan(now.Add(5*time.Second)))
+	_, exists := client.configs["app.yaml"]
+	assert.False(t, exists)
+	assert.Equal(t, model.ReconcileDelete, synced[3].Type)
+}
+
+func TestFileExporter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the signal and the hook command are not supported on windows")
+	}
+	dir := t.TempDir()
+	client := &mapConfigClient{configs: map[string]string{"nginx.conf": "worker_processes 1;"}, listeners: map[string]vo.Listener{}}
+	pidFile := filepath.Join(dir, "nginx.pid")
+	assert.Nil(t, ioutil.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())), 0644))
+	signals := make(chan os.Signal, 4)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+	hookFile := filepath.Join(dir, "hook")
+	exported := make(chan error, 4)
+
+	_, err := NewFileExporter(client, vo.FileExportParam{Configs: []vo.ConfigParam{{DataId: "../passwd"}}, PathTemplate: dir + "/{dataId}"})
+	assert.NotNil(t, err)
+	exporter, err := NewFileExporter(client, vo.FileExportParam{
+		Configs:      []vo.ConfigParam{{DataId: "nginx.conf"}},
+		PathTemplate: filepath.Join(dir, "{group}", "{dataId}"),
+		PidFile:      pidFile,
+		Command:      []string{"sh", "-c", "echo reloaded >> " + hookFile},
+		OnExport: func(path string, err error) {
+			exported <- err
+		},
+	})
+	assert.Nil(t, err)
+	path := filepath.Join(dir, "DEFAULT_GROUP", "nginx.conf")
+	assert.Equal(t, path, exporter.Path(vo.ConfigParam{DataId: "nginx.conf", Group: "DEFAULT_GROUP"}))
+	assert.Nil(t, <-exported)
+	<-signals
+
+	client.listeners["nginx.conf"]("", "DEFAULT_GROUP", "nginx.conf", "worker_processes 2;")
+	assert.Nil(t, <-exported)
+	<-signals
+	// the process is not notified when the content is not changed
+	client.listeners["nginx.conf"]("", "DEFAULT_GROUP", "nginx.conf", "worker_processes 2;")
+	assert.Empty(t, exported)
+
+	content, _ := ioutil.ReadFile(path)
+	assert.Equal(t, "worker_processes 2;", string(content))
+	hook, _ := ioutil.ReadFile(hookFile)
+	assert.Equal(t, "reloaded\nreloaded\n", string(hook))
+	exporter.Close()
+	assert.Empty(t, client.listeners)
+}
+
+func TestTemplateRenderer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the check and reload commands are not supported on windows")
+	}
+	dir := t.TempDir()
+	client := &mapConfigClient{configs: map[string]string{
+		"upstreams.json": `{"servers":["10.0.0.1:80","10.0.0.2:80"]}`,
+		"listen":         "80",
+	}, listeners: map[string]vo.Listener{}}
+	dest := filepath.Join(dir, "nginx", "upstream.conf")
+	reloadFile := filepath.Join(dir, "reload")
+	rendered := make(chan error, 4)
+	renderer, err := NewTemplateRenderer(client, vo.TemplateParam{
+		Configs: []vo.ConfigParam{{DataId: "upstreams.json"}, {DataId: "listen"}},
+		Template: `upstream backend {
+{{- range (json (config "upstreams.json")).servers }}
+    server {{ . }};
+{{- end }}
+}
+listen {{ config "listen" "DEFAULT_GROUP" | trim }};`,
+		Dest:          dest,
+		CheckCommand:  []string{"grep", "-q", "server", "{{.src}}"},
+		ReloadCommand: []string{"sh", "-c", "echo reloaded >> " + reloadFile},
+		OnRender: func(dest string, err error) {
+			rendered <- err
+		},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, <-rendered)
+	content, _ := ioutil.ReadFile(dest)
+	assert.Equal(t, "upstream backend {\n    server 10.0.0.1:80;\n    server 10.0.0.2:80;\n}\nlisten 80;", string(content))
+
+	// the content failing the check is not placed
+	client.listeners["upstreams.json"]("", "DEFAULT_GROUP", "upstreams.json", `{"servers":[]}`)
+	assert.NotNil(t, <-rendered)
+	client.listeners["listen"]("", "DEFAULT_GROUP", "listen", "80")
+	assert.NotNil(t, <-rendered)
+	client.listeners["upstreams.json"]("", "DEFAULT_GROUP", "upstreams.json", `{"servers":["10.0.0.3:80"]}`)
+	assert.Nil(t, <-rendered)
+	content, _ = ioutil.ReadFile(dest)
+	assert.Contains(t, string(content), "server 10.0.0.3:80;")
+	reload, _ := ioutil.ReadFile(reloadFile)
+	assert.Equal(t, "reloaded\nreloaded\n", string(reload))
+	files, _ := ioutil.ReadDir(filepath.Dir(dest))
+	assert.Len(t, files, 1)
+	renderer.Close()
+
+	_, err = NewTemplateRenderer(client, vo.TemplateParam{Configs: []vo.ConfigParam{{DataId: "listen"}}, Dest: dest, Template: "{{ config \"absent\" }}"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reconcile
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// srcPlaceholder is replaced by the staged file in the check command
+const srcPlaceholder = "{{.src}}"
+
+// TemplateRenderer renders the template with the listened configs on each change, the rendered content is checked
+// by the check command before it replaces the destination file, and the reload command is executed afterwards.
+// It's the pipeline of confd and consul-template for the configs of Nacos
+type TemplateRenderer struct {
+	client config_client.IConfigClient
+	param  vo.TemplateParam
+	tmpl   *template.Template
+	// mux guards contents and serializes the renderings
+	mux sync.Mutex
+	// contents is the content of configs, keyed by the config cache key of dataId and group
+	contents map[string]string
+}
+
+// NewTemplateRenderer render the template with the configs of param, and listen them to render again on each change
+func NewTemplateRenderer(client config_client.IConfigClient, param vo.TemplateParam) (*TemplateRenderer, error) {
+	if client == nil {
+		return nil, errors.New("[reconcile.NewTemplateRenderer] client can not be nil")
+	}
+	if len(param.Configs) == 0 {
+		return nil, errors.New("[reconcile.NewTemplateRenderer] Configs can not be empty")
+	}
+	if len(param.Dest) == 0 {
+		return nil, errors.New("[reconcile.NewTemplateRenderer] Dest can not be empty")
+	}
+	for i := range param.Configs {
+		if len(param.Configs[i].DataId) == 0 {
+			return nil, errors.New("[reconcile.NewTemplateRenderer] DataId of configs can not be empty")
+		}
+		if len(param.Configs[i].Group) == 0 {
+			param.Configs[i].Group = constant.DEFAULT_GROUP
+		}
+	}
+	if param.FileMode == 0 {
+		param.FileMode = 0644
+	}
+	if param.CommandTimeout <= 0 {
+		param.CommandTimeout = defaultCommandTimeout
+	}
+	r := &TemplateRenderer{client: client, param: param, contents: make(map[string]string, len(param.Configs))}
+	tmpl, err := template.New(filepath.Base(param.Dest)).Funcs(r.funcs()).Parse(param.Template)
+	if err != nil {
+		return nil, errors.Wrap(err, "[reconcile.NewTemplateRenderer] parse template failed")
+	}
+	r.tmpl = tmpl
+
+	for _, config := range param.Configs {
+		content, err := client.GetConfig(vo.ConfigParam{DataId: config.DataId, Group: config.Group, NamespaceId: config.NamespaceId})
+		if err != nil {
+			return nil, errors.Wrapf(err, "[reconcile.NewTemplateRenderer] get config %s failed", config.DataId)
+		}
+		r.contents[util.GetConfigCacheKey(config.DataId, config.Group, "")] = content
+	}
+	if err = r.Render(); err != nil {
+		return nil, err
+	}
+	for _, config := range param.Configs {
+		config := config
+		err := client.ListenConfig(vo.ConfigParam{
+			DataId:      config.DataId,
+			Group:       config.Group,
+			NamespaceId: config.NamespaceId,
+			OnChange: func(namespace, group, dataId, data string) {
+				r.update(config, data)
+			},
+		})
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Close cancel the listening of configs, the destination file is kept
+func (r *TemplateRenderer) Close() {
+	for _, config := range r.param.Configs {
+		_ = r.client.CancelListenConfig(vo.ConfigParam{DataId: config.DataId, Group: config.Group, NamespaceId: config.NamespaceId})
+	}
+}
+
+// Render render the template with the current configs, the destination file is replaced and the reload
+// command is executed only if the rendered content changed
+func (r *TemplateRenderer) Render() error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	err := r.render()
+	if err != nil {
+		logger.Warnf("[reconcile] render template to %s failed, err:%v", r.param.Dest, err)
+	}
+	if r.param.OnRender != nil {
+		r.param.OnRender(r.param.Dest, err)
+	}
+	return err
+}
+
+func (r *TemplateRenderer) update(config vo.ConfigParam, content string) {
+	r.mux.Lock()
+	r.contents[util.GetConfigCacheKey(config.DataId, config.Group, "")] = content
+	r.mux.Unlock()
+	_ = r.Render()
+}
+
+func (r *TemplateRenderer) render() error {
+	var buf bytes.Buffer
+	if err := r.tmpl.Execute(&buf, nil); err != nil {
+		return errors.Wrap(err, "execute template failed")
+	}
+	if current, err := ioutil.ReadFile(r.param.Dest); err == nil && bytes.Equal(current, buf.Bytes()) {
+		return nil
+	}
+	dir := filepath.Dir(r.param.Dest)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	staged, err := ioutil.TempFile(dir, "."+filepath.Base(r.param.Dest)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(staged.Name())
+	_, err = staged.Write(buf.Bytes())
+	if closeErr := staged.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(staged.Name(), r.param.FileMode)
+	}
+	if err != nil {
+		return err
+	}
+	check := make([]string, len(r.param.CheckCommand))
+	for i, arg := range r.param.CheckCommand {
+		check[i] = strings.ReplaceAll(arg, srcPlaceholder, staged.Name())
+	}
+	if err = runCommand(check, r.param.CommandTimeout); err != nil {
+		return errors.Wrap(err, "check rendered content failed")
+	}
+	if err = os.Rename(staged.Name(), r.param.Dest); err != nil {
+		return err
+	}
+	logger.Infof("[reconcile] render template to %s", r.param.Dest)
+	return runCommand(r.param.ReloadCommand, r.param.CommandTimeout)
+}
+
+// funcs return the functions of template, the contents are read under the lock held by Render
+func (r *TemplateRenderer) funcs() template.FuncMap {
+	funcs := template.FuncMap{
+		"config": func(dataId string, group ...string) (string, error) {
+			g := constant.DEFAULT_GROUP
+			if len(group) > 0 {
+				g = group[0]
+			}
+			content, ok := r.contents[util.GetConfigCacheKey(dataId, g, "")]
+			if !ok {
+				return "", errors.Errorf("config %s of group %s is not listed in Configs", dataId, g)
+			}
+			return content, nil
+		},
+		"json": func(content string) (interface{}, error) {
+			var v interface{}
+			err := json.Unmarshal([]byte(content), &v)
+			return v, err
+		},
+		"split": strings.Split,
+		"join":  strings.Join,
+		"trim":  strings.TrimSpace,
+	}
+	for name, fn := range r.param.Funcs {
+		funcs[name] = fn
+	}
+	return funcs
+}
@@ -0,0 +1,245 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package webhook forwards the changes of configs to webhooks, such as chat-ops and external audit systems.
+// The events are posted in json with the HMAC-SHA256 signature of body, and the failed deliveries are retried
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// SignatureHeader is the header of the signature of body, its value is sha256=<hex of HMAC-SHA256>
+const SignatureHeader = "X-Nacos-Signature"
+
+const (
+	defaultMaxRetries   = 3
+	defaultRetryBackoff = time.Second
+	defaultTimeout      = 5 * time.Second
+	// the events waiting for delivery, the newer events are dropped when the webhooks are too slow
+	eventQueueSize = 1024
+)
+
+// Forwarder listens the configs and posts their changes to the webhooks in order
+type Forwarder struct {
+	client     config_client.IConfigClient
+	param      vo.WebhookParam
+	httpClient *http.Client
+	events     chan model.ConfigWebhookEvent
+	ctx        context.Context
+	cancel     context.CancelFunc
+	done       chan struct{}
+	// mux guards contents
+	mux sync.Mutex
+	// contents is the last known content of configs, keyed by the config cache key
+	contents map[string]string
+}
+
+// NewForwarder listen the configs of param and post their changes to the webhooks until Close
+func NewForwarder(client config_client.IConfigClient, param vo.WebhookParam) (*Forwarder, error) {
+	if client == nil {
+		return nil, errors.New("[webhook.NewForwarder] client can not be nil")
+	}
+	if len(param.Configs) == 0 {
+		return nil, errors.New("[webhook.NewForwarder] Configs can not be empty")
+	}
+	if len(param.Urls) == 0 {
+		return nil, errors.New("[webhook.NewForwarder] Urls can not be empty")
+	}
+	for i := range param.Configs {
+		if len(param.Configs[i].DataId) == 0 {
+			return nil, errors.New("[webhook.NewForwarder] DataId of configs can not be empty")
+		}
+		if len(param.Configs[i].Group) == 0 {
+			param.Configs[i].Group = constant.DEFAULT_GROUP
+		}
+	}
+	if param.MaxRetries <= 0 {
+		param.MaxRetries = defaultMaxRetries
+	}
+	if param.RetryBackoff <= 0 {
+		param.RetryBackoff = defaultRetryBackoff
+	}
+	if param.Timeout <= 0 {
+		param.Timeout = defaultTimeout
+	}
+	f := &Forwarder{
+		client:     client,
+		param:      param,
+		httpClient: &http.Client{Timeout: param.Timeout},
+		events:     make(chan model.ConfigWebhookEvent, eventQueueSize),
+		done:       make(chan struct{}),
+		contents:   make(map[string]string, len(param.Configs)),
+	}
+	f.ctx, f.cancel = context.WithCancel(context.Background())
+
+	for _, config := range param.Configs {
+		content, err := client.GetConfig(vo.ConfigParam{DataId: config.DataId, Group: config.Group, NamespaceId: config.NamespaceId})
+		if err != nil {
+			return nil, errors.Wrapf(err, "[webhook.NewForwarder] get config %s failed", config.DataId)
+		}
+		f.contents[util.GetConfigCacheKey(config.DataId, config.Group, config.NamespaceId)] = content
+	}
+	go f.deliverLoop()
+	for _, config := range param.Configs {
+		config := config
+		err := client.ListenConfig(vo.ConfigParam{
+			DataId:      config.DataId,
+			Group:       config.Group,
+			NamespaceId: config.NamespaceId,
+			OnChange: func(namespace, group, dataId, data string) {
+				f.onChange(config, namespace, data)
+			},
+		})
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Close cancel the listening of configs and stop delivering, the events not delivered yet are dropped
+func (f *Forwarder) Close() {
+	for _, config := range f.param.Configs {
+		_ = f.client.CancelListenConfig(vo.ConfigParam{DataId: config.DataId, Group: config.Group, NamespaceId: config.NamespaceId})
+	}
+	f.cancel()
+	<-f.done
+}
+
+func (f *Forwarder) onChange(config vo.ConfigParam, namespace, content string) {
+	key := util.GetConfigCacheKey(config.DataId, config.Group, config.NamespaceId)
+	f.mux.Lock()
+	old := f.contents[key]
+	f.contents[key] = content
+	f.mux.Unlock()
+	if old == content {
+		return
+	}
+	event := model.ConfigWebhookEvent{
+		Type:      model.ConfigEventChange,
+		Namespace: namespace,
+		Group:     config.Group,
+		DataId:    config.DataId,
+		OldMd5:    util.Md5(old),
+		NewMd5:    util.Md5(content),
+		Timestamp: time.Now().UnixNano() / int64(time.Millisecond),
+	}
+	if len(content) == 0 {
+		event.Type = model.ConfigEventDelete
+	}
+	if f.param.IncludeContent {
+		event.OldContent, event.NewContent = old, content
+	}
+	select {
+	case f.events <- event:
+	default:
+		logger.Warnf("[webhook] too many events waiting for delivery, drop the change of config dataId:%s group:%s", config.DataId, config.Group)
+	}
+}
+
+func (f *Forwarder) deliverLoop() {
+	defer close(f.done)
+	for {
+		select {
+		case <-f.ctx.Done():
+			return
+		case event := <-f.events:
+			for _, url := range f.param.Urls {
+				err := f.deliver(url, event)
+				if err != nil {
+					logger.Warnf("[webhook] deliver the change of config dataId:%s group:%s to %s failed, err:%v", event.DataId, event.Group, url, err)
+				}
+				if f.param.OnDeliver != nil {
+					f.param.OnDeliver(url, event, err)
+				}
+			}
+		}
+	}
+}
+
+// deliver post the event to url, the network errors, 429 and 5xx are retried with exponential backoff
+func (f *Forwarder) deliver(url string, event model.ConfigWebhookEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	backoff := f.param.RetryBackoff
+	for retries := 0; ; retries++ {
+		retryable, err := f.post(url, body)
+		if err == nil || !retryable || retries >= f.param.MaxRetries {
+			return err
+		}
+		select {
+		case <-f.ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (f *Forwarder) post(url string, body []byte) (retryable bool, err error) {
+	request, err := http.NewRequestWithContext(f.ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for k, v := range f.param.Headers {
+		request.Header.Set(k, v)
+	}
+	if len(f.param.Secret) > 0 {
+		request.Header.Set(SignatureHeader, Sign(f.param.Secret, body))
+	}
+	response, err := f.httpClient.Do(request)
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return false, nil
+	}
+	retryable = response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500
+	return retryable, errors.Errorf("webhook responds status %d", response.StatusCode)
+}
+
+// Sign return the signature of body in SignatureHeader, the webhooks verify the requests with it
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
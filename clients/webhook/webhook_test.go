@@ -0,0 +1,172 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+type listenConfigClient struct {
+	config_client.IConfigClient
+	mux       sync.Mutex
+	configs   map[string]string
+	listeners map[string]vo.Listener
+}
+
+func (c *listenConfigClient) GetConfig(param vo.ConfigParam) (string, error) {
+	return c.configs[param.DataId], nil
+}
+
+func (c *listenConfigClient) ListenConfig(param vo.ConfigParam) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.listeners[param.DataId] = param.OnChange
+	return nil
+}
+
+func (c *listenConfigClient) CancelListenConfig(param vo.ConfigParam) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	delete(c.listeners, param.DataId)
+	return nil
+}
+
+func (c *listenConfigClient) change(dataId, content string) {
+	c.mux.Lock()
+	listener := c.listeners[dataId]
+	c.mux.Unlock()
+	listener("", "DEFAULT_GROUP", dataId, content)
+}
+
+func TestForwarder(t *testing.T) {
+	var (
+		mux       sync.Mutex
+		calls     int
+		signature string
+		events    []model.ConfigWebhookEvent
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.Lock()
+		defer mux.Unlock()
+		calls++
+		// the first delivery fails and is retried
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		var event model.ConfigWebhookEvent
+		_ = json.Unmarshal(body, &event)
+		events = append(events, event)
+		signature = r.Header.Get(SignatureHeader)
+		assert.Equal(t, Sign("secret", body), signature)
+		assert.Equal(t, "token", r.Header.Get("X-Token"))
+	}))
+	defer server.Close()
+
+	client := &listenConfigClient{configs: map[string]string{"app": "a=1"}, listeners: map[string]vo.Listener{}}
+	delivered := make(chan error, 4)
+	forwarder, err := NewForwarder(client, vo.WebhookParam{
+		Configs:        []vo.ConfigParam{{DataId: "app"}},
+		Urls:           []string{server.URL},
+		Secret:         "secret",
+		IncludeContent: true,
+		Headers:        map[string]string{"X-Token": "token"},
+		RetryBackoff:   time.Millisecond,
+		OnDeliver: func(url string, event model.ConfigWebhookEvent, err error) {
+			delivered <- err
+		},
+	})
+	assert.Nil(t, err)
+	defer forwarder.Close()
+
+	// the unchanged content is not forwarded
+	client.change("app", "a=1")
+	client.change("app", "a=2")
+	client.change("app", "")
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-delivered:
+			assert.Nil(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the event is not delivered")
+		}
+	}
+
+	mux.Lock()
+	defer mux.Unlock()
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, model.ConfigEventChange, events[0].Type)
+	assert.Equal(t, util.Md5("a=1"), events[0].OldMd5)
+	assert.Equal(t, util.Md5("a=2"), events[0].NewMd5)
+	assert.Equal(t, "a=2", events[0].NewContent)
+	assert.Equal(t, model.ConfigEventDelete, events[1].Type)
+	assert.Equal(t, "a=2", events[1].OldContent)
+}
+
+func TestForwarder_NotRetryClientError(t *testing.T) {
+	var calls int32
+	var mux sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.Lock()
+		calls++
+		mux.Unlock()
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client := &listenConfigClient{configs: map[string]string{}, listeners: map[string]vo.Listener{}}
+	delivered := make(chan error, 1)
+	forwarder, err := NewForwarder(client, vo.WebhookParam{
+		Configs:      []vo.ConfigParam{{DataId: "app"}},
+		Urls:         []string{server.URL},
+		RetryBackoff: time.Millisecond,
+		OnDeliver: func(url string, event model.ConfigWebhookEvent, err error) {
+			delivered <- err
+		},
+	})
+	assert.Nil(t, err)
+	defer forwarder.Close()
+
+	client.change("app", "a=1")
+	select {
+	case err := <-delivered:
+		assert.NotNil(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the event is not delivered")
+	}
+	mux.Lock()
+	defer mux.Unlock()
+	assert.Equal(t, int32(1), calls)
+
+	_, err = NewForwarder(client, vo.WebhookParam{Configs: []vo.ConfigParam{{DataId: "app"}}})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// nacos-agent is the local agent shared by the processes on a host, see package clients/agent.
+//
+//	nacos-agent -socket /var/run/nacos-agent.sock -server 10.0.0.1:8848,10.0.0.2:8848 -namespace public
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/agent"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+func main() {
+	var (
+		socketPath  = flag.String("socket", "/var/run/nacos-agent.sock", "the unix socket the processes connect to")
+		servers     = flag.String("server", "127.0.0.1:8848", "comma separated server addresses host:port")
+		contextPath = flag.String("context-path", "", "the context path of server, default is /nacos")
+		namespace   = flag.String("namespace", "", "the namespace id used when the processes don't give it")
+		username    = flag.String("username", "", "the username for nacos auth")
+		password    = flag.String("password", "", "the password for nacos auth")
+		logDir      = flag.String("log-dir", "/tmp/nacos-agent/log", "the log directory")
+		cacheDir    = flag.String("cache-dir", "/tmp/nacos-agent/cache", "the cache directory")
+		idleTimeout = flag.Duration("idle-timeout", 0, "the configs not listened by any process for it are no longer listened on server, default is 5m")
+	)
+	flag.Parse()
+
+	serverConfigs, err := parseServers(*servers, *contextPath)
+	if err != nil {
+		fatal(err)
+	}
+	client, err := clients.NewConfigClient(vo.NacosClientParam{
+		ClientConfig: constant.NewClientConfig(
+			constant.WithNamespaceId(*namespace),
+			constant.WithUsername(*username),
+			constant.WithPassword(*password),
+			constant.WithLogDir(*logDir),
+			constant.WithCacheDir(*cacheDir),
+			constant.WithNotLoadCacheAtStart(true),
+		),
+		ServerConfigs: serverConfigs,
+	})
+	if err != nil {
+		fatal(err)
+	}
+	defer client.CloseClient()
+
+	server := agent.NewServer(client, *idleTimeout)
+	go func() {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+		<-ch
+		_ = server.Close()
+	}()
+	if err = server.ListenAndServe(*socketPath); err != nil {
+		fatal(err)
+	}
+	_ = os.Remove(*socketPath)
+}
+
+func parseServers(servers, contextPath string) ([]constant.ServerConfig, error) {
+	var configs []constant.ServerConfig
+	for _, addr := range strings.Split(servers, ",") {
+		host, port := addr, uint64(8848)
+		if i := strings.LastIndex(addr, ":"); i > 0 {
+			var err error
+			host = addr[:i]
+			if port, err = strconv.ParseUint(addr[i+1:], 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid server address %s", addr)
+			}
+		}
+		var opts []constant.ServerOption
+		if contextPath != "" {
+			opts = append(opts, constant.WithContextPath(contextPath))
+		}
+		configs = append(configs, *constant.NewServerConfig(host, port, opts...))
+	}
+	return configs, nil
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "nacos-agent:", err)
+	os.Exit(1)
+}
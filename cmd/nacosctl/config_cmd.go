@@ -0,0 +1,182 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+func runConfigCommand(profile *Profile, command string, args []string) error {
+	var (
+		dataId  string
+		group   string
+		content string
+		file    string
+		typ     string
+		desc    string
+		tags    string
+		binary  bool
+	)
+	fs := flag.NewFlagSet("config "+command, flag.ExitOnError)
+	fs.StringVar(&dataId, "dataId", "", "the dataId of config, required")
+	fs.StringVar(&group, "group", "", "the group of config, default is the default group of client")
+	if command == "get" || command == "publish" {
+		fs.BoolVar(&binary, "binary", false, "the content is binary, it's base64 encoded on publish and decoded on get")
+	}
+	if command == "publish" {
+		fs.StringVar(&content, "content", "", "the content to publish")
+		fs.StringVar(&file, "file", "", "read the content to publish from file, - means stdin")
+		fs.StringVar(&typ, "type", "", "the type of config, such as text, json, yaml, properties")
+		fs.StringVar(&desc, "desc", "", "the description of config")
+		fs.StringVar(&tags, "tags", "", "the comma separated tags of config")
+	}
+	_ = fs.Parse(args)
+	if dataId == "" {
+		fs.Usage()
+		return fmt.Errorf("dataId can not be empty")
+	}
+
+	client, err := newConfigClient(profile)
+	if err != nil {
+		return err
+	}
+	defer client.CloseClient()
+	group = client.GroupOf(dataId, group)
+	param := vo.ConfigParam{DataId: dataId, Group: group}
+
+	switch command {
+	case "get":
+		if binary {
+			data, err := client.GetBinaryConfig(param)
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		stream, err := client.GetConfigStream(param)
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+		if _, err = io.Copy(os.Stdout, stream); err != nil {
+			return err
+		}
+		fmt.Println()
+	case "publish":
+		reader, err := openContent(content, file)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		param.Type = typ
+		param.Desc = desc
+		param.ConfigTags = tags
+		var (
+			published bool
+			data      []byte
+		)
+		if binary {
+			if data, err = ioutil.ReadAll(reader); err != nil {
+				return err
+			}
+			published, err = client.PublishBinaryConfig(param, data)
+		} else {
+			published, err = client.PublishConfigStream(param, reader)
+		}
+		if err != nil {
+			return err
+		}
+		if !published {
+			return fmt.Errorf("publish config %s@%s failed", dataId, group)
+		}
+		fmt.Printf("published %s@%s\n", dataId, group)
+	case "delete":
+		deleted, err := client.DeleteConfig(param)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return fmt.Errorf("delete config %s@%s failed", dataId, group)
+		}
+		fmt.Printf("deleted %s@%s\n", dataId, group)
+	case "listen":
+		param.OnChange = func(namespace, group, dataId, data string) {
+			fmt.Printf("%s changed namespace:%s group:%s dataId:%s size:%d\n",
+				time.Now().Format(time.RFC3339), namespace, group, dataId, len(data))
+		}
+		param.OnDelete = func(namespace, group, dataId, lastContent string) {
+			fmt.Printf("%s deleted namespace:%s group:%s dataId:%s\n",
+				time.Now().Format(time.RFC3339), namespace, group, dataId)
+		}
+		if err = client.ListenConfig(param); err != nil {
+			return err
+		}
+		waitForSignal()
+	case "tail":
+		ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer cancel()
+		events, err := client.Tail(ctx, param)
+		if err != nil {
+			return err
+		}
+		for event := range events {
+			if event.Type == model.ConfigEventDelete {
+				fmt.Printf("--- %s %s@%s deleted\n", time.Now().Format(time.RFC3339), event.DataId, event.Group)
+				continue
+			}
+			fmt.Printf("--- %s %s@%s\n%s\n", time.Now().Format(time.RFC3339), event.DataId, event.Group, event.Content)
+		}
+	default:
+		return fmt.Errorf("unknown config command %s", command)
+	}
+	return nil
+}
+
+// newConfigClient is replaced by the mocked client in tests
+var newConfigClient = func(profile *Profile) (config_client.IConfigClient, error) {
+	param, err := profile.clientParam()
+	if err != nil {
+		return nil, err
+	}
+	return clients.NewConfigClient(param)
+}
+
+func openContent(content, file string) (io.ReadCloser, error) {
+	switch file {
+	case "":
+		return ioutil.NopCloser(strings.NewReader(content)), nil
+	case "-":
+		return ioutil.NopCloser(os.Stdin), nil
+	default:
+		return os.Open(file)
+	}
+}
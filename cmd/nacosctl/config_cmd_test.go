@@ -0,0 +1,142 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/mock"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// withConfigClient run the command on the mocked config client
+func withConfigClient(t *testing.T, client config_client.IConfigClient) {
+	origin := newConfigClient
+	newConfigClient = func(profile *Profile) (config_client.IConfigClient, error) {
+		return client, nil
+	}
+	t.Cleanup(func() {
+		newConfigClient = origin
+	})
+}
+
+// captureStdout return what fn writes to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	r, w, err := os.Pipe()
+	assert.Nil(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() {
+		os.Stdout = stdout
+	}()
+	fn()
+	assert.Nil(t, w.Close())
+	out, err := ioutil.ReadAll(r)
+	assert.Nil(t, err)
+	return string(out)
+}
+
+func TestConfigCommand_PublishDefaultGroup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock.NewMockIConfigClient(ctrl)
+	withConfigClient(t, client)
+	// the group is resolved by client when -group is not set
+	client.EXPECT().GroupOf("app.yaml", "").Return("APP_GROUP")
+	client.EXPECT().PublishConfigStream(gomock.Any(), gomock.Any()).DoAndReturn(func(param vo.ConfigParam, r io.Reader) (bool, error) {
+		assert.Equal(t, "APP_GROUP", param.Group)
+		assert.Equal(t, "yaml", param.Type)
+		assert.Equal(t, "a,b", param.ConfigTags)
+		content, err := ioutil.ReadAll(r)
+		assert.Nil(t, err)
+		assert.Equal(t, "a: 1", string(content))
+		return true, nil
+	})
+	client.EXPECT().CloseClient()
+
+	var err error
+	out := captureStdout(t, func() {
+		err = runConfigCommand(&Profile{}, "publish", []string{"-dataId", "app.yaml", "-content", "a: 1", "-type", "yaml", "-tags", "a,b"})
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "published app.yaml@APP_GROUP\n", out)
+}
+
+func TestConfigCommand_Get(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock.NewMockIConfigClient(ctrl)
+	withConfigClient(t, client)
+	client.EXPECT().GroupOf("app.yaml", "group").Return("group")
+	client.EXPECT().GetConfigStream(vo.ConfigParam{DataId: "app.yaml", Group: "group"}).
+		Return(ioutil.NopCloser(strings.NewReader("a: 1")), nil)
+	client.EXPECT().CloseClient()
+
+	var err error
+	out := captureStdout(t, func() {
+		err = runConfigCommand(&Profile{}, "get", []string{"-dataId", "app.yaml", "-group", "group"})
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "a: 1\n", out)
+}
+
+func TestConfigCommand_DeleteFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock.NewMockIConfigClient(ctrl)
+	withConfigClient(t, client)
+	client.EXPECT().GroupOf("app.yaml", "").Return("DEFAULT_GROUP")
+	client.EXPECT().DeleteConfig(vo.ConfigParam{DataId: "app.yaml", Group: "DEFAULT_GROUP"}).Return(false, nil)
+	client.EXPECT().CloseClient()
+
+	err := runConfigCommand(&Profile{}, "delete", []string{"-dataId", "app.yaml"})
+	assert.EqualError(t, err, "delete config app.yaml@DEFAULT_GROUP failed")
+}
+
+func TestConfigCommand_RequireDataId(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	// the client is not created without dataId
+	withConfigClient(t, mock.NewMockIConfigClient(ctrl))
+	err := runConfigCommand(&Profile{}, "get", []string{"-group", "group"})
+	assert.EqualError(t, err, "dataId can not be empty")
+}
+
+func TestOpenContent(t *testing.T) {
+	file := t.TempDir() + "/content"
+	assert.Nil(t, ioutil.WriteFile(file, []byte("from file"), 0600))
+	for content, expected := range map[string]string{"": "from file", "inline": "inline"} {
+		fileName := file
+		if content != "" {
+			fileName = ""
+		}
+		reader, err := openContent(content, fileName)
+		assert.Nil(t, err)
+		data, err := ioutil.ReadAll(reader)
+		assert.Nil(t, err)
+		assert.Nil(t, reader.Close())
+		assert.Equal(t, expected, string(data))
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+func runInstanceCommand(profile *Profile, command string, args []string) error {
+	var (
+		serviceName string
+		group       string
+		clusters    string
+		ip          string
+		port        uint64
+		weight      float64
+		metadata    string
+		ephemeral   bool
+	)
+	fs := flag.NewFlagSet("instance "+command, flag.ExitOnError)
+	fs.StringVar(&serviceName, "service", "", "the service name, required")
+	fs.StringVar(&group, "group", "", "the group name, default is the default service group of client")
+	fs.StringVar(&clusters, "cluster", "", "comma separated cluster names")
+	if command == "register" || command == "deregister" {
+		fs.StringVar(&ip, "ip", "", "the ip of instance, required")
+		fs.Uint64Var(&port, "port", 0, "the port of instance, required")
+		fs.BoolVar(&ephemeral, "ephemeral", true, "register an ephemeral instance, it is removed when nacosctl exits")
+	}
+	if command == "register" {
+		fs.Float64Var(&weight, "weight", 1, "the weight of instance")
+		fs.StringVar(&metadata, "metadata", "", "comma separated metadata k=v pairs")
+	}
+	_ = fs.Parse(args)
+	if serviceName == "" {
+		fs.Usage()
+		return fmt.Errorf("service can not be empty")
+	}
+
+	client, err := newNamingClient(profile)
+	if err != nil {
+		return err
+	}
+	defer client.CloseClient()
+	group = client.GroupOf(group)
+
+	switch command {
+	case "list":
+		var clusterList []string
+		if clusters != "" {
+			clusterList = strings.Split(clusters, ",")
+		}
+		instances, err := client.SelectAllInstances(vo.SelectAllInstancesParam{
+			ServiceName: serviceName,
+			GroupName:   group,
+			Clusters:    clusterList,
+		})
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "IP\tPORT\tCLUSTER\tWEIGHT\tHEALTHY\tENABLED\tEPHEMERAL\tMETADATA")
+		for _, ins := range instances {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%g\t%t\t%t\t%t\t%v\n", ins.Ip, ins.Port, ins.ClusterName, ins.Weight,
+				ins.Healthy, ins.Enable, ins.Ephemeral, ins.Metadata)
+		}
+		return w.Flush()
+	case "register":
+		if ip == "" || port == 0 {
+			return fmt.Errorf("ip and port can not be empty")
+		}
+		success, err := client.RegisterInstance(vo.RegisterInstanceParam{
+			Ip:          ip,
+			Port:        port,
+			Weight:      weight,
+			Enable:      true,
+			Healthy:     true,
+			Metadata:    parseMetadata(metadata),
+			ClusterName: clusters,
+			ServiceName: serviceName,
+			GroupName:   group,
+			Ephemeral:   ephemeral,
+		})
+		if err != nil {
+			return err
+		}
+		if !success {
+			return fmt.Errorf("register instance %s:%d failed", ip, port)
+		}
+		fmt.Printf("registered %s:%d to %s@@%s\n", ip, port, group, serviceName)
+		if ephemeral {
+			fmt.Println("ephemeral instance is kept until interrupted")
+			waitForSignal()
+		}
+	case "deregister":
+		if ip == "" || port == 0 {
+			return fmt.Errorf("ip and port can not be empty")
+		}
+		success, err := client.DeregisterInstance(vo.DeregisterInstanceParam{
+			Ip:          ip,
+			Port:        port,
+			Cluster:     clusters,
+			ServiceName: serviceName,
+			GroupName:   group,
+			Ephemeral:   ephemeral,
+		})
+		if err != nil {
+			return err
+		}
+		if !success {
+			return fmt.Errorf("deregister instance %s:%d failed", ip, port)
+		}
+		fmt.Printf("deregistered %s:%d from %s@@%s\n", ip, port, group, serviceName)
+	default:
+		return fmt.Errorf("unknown instance command %s", command)
+	}
+	return nil
+}
+
+// newNamingClient is replaced by the mocked client in tests
+var newNamingClient = func(profile *Profile) (naming_client.INamingClient, error) {
+	param, err := profile.clientParam()
+	if err != nil {
+		return nil, err
+	}
+	return clients.NewNamingClient(param)
+}
+
+func parseMetadata(metadata string) map[string]string {
+	result := map[string]string{}
+	for _, kv := range strings.Split(metadata, ",") {
+		if kv == "" {
+			continue
+		}
+		pair := strings.SplitN(kv, "=", 2)
+		if len(pair) == 2 {
+			result[pair[0]] = pair[1]
+		} else {
+			result[pair[0]] = ""
+		}
+	}
+	return result
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/mock"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+// withNamingClient run the command on the mocked naming client
+func withNamingClient(t *testing.T, client naming_client.INamingClient) {
+	origin := newNamingClient
+	newNamingClient = func(profile *Profile) (naming_client.INamingClient, error) {
+		return client, nil
+	}
+	t.Cleanup(func() {
+		newNamingClient = origin
+	})
+}
+
+func TestInstanceCommand_ListDefaultGroup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock.NewMockINamingClient(ctrl)
+	withNamingClient(t, client)
+	// the group is resolved by client when -group is not set
+	client.EXPECT().GroupOf("").Return("SERVICE_GROUP")
+	client.EXPECT().SelectAllInstances(vo.SelectAllInstancesParam{
+		ServiceName: "demo",
+		GroupName:   "SERVICE_GROUP",
+		Clusters:    []string{"a", "b"},
+	}).Return([]model.Instance{{Ip: "10.0.0.1", Port: 80, ClusterName: "a", Weight: 1, Healthy: true}}, nil)
+	client.EXPECT().CloseClient()
+
+	var err error
+	out := captureStdout(t, func() {
+		err = runInstanceCommand(&Profile{}, "list", []string{"-service", "demo", "-cluster", "a,b"})
+	})
+	assert.Nil(t, err)
+	assert.Contains(t, out, "10.0.0.1")
+	assert.Contains(t, out, "CLUSTER")
+}
+
+func TestInstanceCommand_Deregister(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock.NewMockINamingClient(ctrl)
+	withNamingClient(t, client)
+	client.EXPECT().GroupOf("group").Return("group")
+	client.EXPECT().DeregisterInstance(vo.DeregisterInstanceParam{
+		Ip:          "10.0.0.1",
+		Port:        80,
+		ServiceName: "demo",
+		GroupName:   "group",
+		Ephemeral:   false,
+	}).Return(true, nil)
+	client.EXPECT().CloseClient()
+
+	var err error
+	out := captureStdout(t, func() {
+		err = runInstanceCommand(&Profile{}, "deregister",
+			[]string{"-service", "demo", "-group", "group", "-ip", "10.0.0.1", "-port", "80", "-ephemeral=false"})
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "deregistered 10.0.0.1:80 from group@@demo\n", out)
+}
+
+func TestInstanceCommand_RequireIpAndPort(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock.NewMockINamingClient(ctrl)
+	withNamingClient(t, client)
+	client.EXPECT().GroupOf("").Return("DEFAULT_GROUP")
+	client.EXPECT().CloseClient()
+
+	err := runInstanceCommand(&Profile{}, "register", []string{"-service", "demo", "-port", "80"})
+	assert.EqualError(t, err, "ip and port can not be empty")
+}
+
+func TestParseMetadata(t *testing.T) {
+	assert.Equal(t, map[string]string{"zone": "hz", "canary": "", "k": "a=b"}, parseMetadata("zone=hz,canary,,k=a=b"))
+	assert.Empty(t, parseMetadata(""))
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// nacosctl is a small command line tool built on nacos-sdk-go for debugging and scripting.
+//
+//	nacosctl [global flags] config get|publish|delete|listen|tail [flags]
+//	nacosctl [global flags] instance list|register|deregister [flags]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+const usage = `Usage: nacosctl [global flags] <command> <subcommand> [flags]
+
+Commands:
+  config get|publish|delete|listen|tail
+  instance list|register|deregister
+
+Global flags:
+`
+
+type globalOptions struct {
+	profileFile string
+	profile     string
+	servers     string
+	namespace   string
+	username    string
+	password    string
+}
+
+func main() {
+	opts := globalOptions{}
+	fs := flag.NewFlagSet("nacosctl", flag.ExitOnError)
+	fs.StringVar(&opts.profileFile, "profile-file", defaultProfileFile(), "the profile file, env "+profileFileEnv)
+	fs.StringVar(&opts.profile, "profile", "", "the profile name, default is the default profile of the profile file")
+	fs.StringVar(&opts.servers, "server", "", "comma separated server addresses host:port, overrides the profile")
+	fs.StringVar(&opts.namespace, "namespace", "", "the namespace id, overrides the profile")
+	fs.StringVar(&opts.username, "username", "", "the username for nacos auth, overrides the profile")
+	fs.StringVar(&opts.password, "password", "", "the password for nacos auth, overrides the profile")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(os.Args[1:])
+	args := fs.Args()
+	if len(args) < 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	profile, err := opts.resolveProfile()
+	if err != nil {
+		fatal(err)
+	}
+
+	switch args[0] {
+	case "config":
+		err = runConfigCommand(profile, args[1], args[2:])
+	case "instance":
+		err = runInstanceCommand(profile, args[1], args[2:])
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fatal(err)
+	}
+}
+
+func (opts globalOptions) resolveProfile() (*Profile, error) {
+	profile, err := loadProfile(opts.profileFile, opts.profile)
+	if err != nil {
+		return nil, err
+	}
+	if opts.servers != "" {
+		profile.Servers = strings.Split(opts.servers, ",")
+	}
+	if opts.namespace != "" {
+		profile.Namespace = opts.namespace
+	}
+	if opts.username != "" {
+		profile.Username = opts.username
+	}
+	if opts.password != "" {
+		profile.Password = opts.password
+	}
+	if len(profile.Servers) == 0 && profile.Endpoint == "" {
+		return nil, fmt.Errorf("no server configured, use -server or a profile")
+	}
+	return profile, nil
+}
+
+// waitForSignal block until the process is interrupted
+func waitForSignal() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+	<-ch
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "nacosctl:", err)
+	os.Exit(1)
+}
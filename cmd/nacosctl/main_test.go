@@ -0,0 +1,55 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveProfile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	assert.Nil(t, ioutil.WriteFile(file, []byte(`{"default": "dev", "profiles": {
+		"dev": {"servers": ["127.0.0.1:8848"], "namespace": "dev", "username": "nacos"},
+		"prod": {"endpoint": "nacos.example.com"}}}`), 0600))
+
+	profile, err := globalOptions{profileFile: file}.resolveProfile()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"127.0.0.1:8848"}, profile.Servers)
+	assert.Equal(t, "dev", profile.Namespace)
+
+	// the flags override the profile
+	profile, err = globalOptions{profileFile: file, servers: "10.0.0.1:8848,10.0.0.2:8848", namespace: "test",
+		password: "secret"}.resolveProfile()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.0.0.1:8848", "10.0.0.2:8848"}, profile.Servers)
+	assert.Equal(t, "test", profile.Namespace)
+	assert.Equal(t, "nacos", profile.Username)
+	assert.Equal(t, "secret", profile.Password)
+
+	profile, err = globalOptions{profileFile: file, profile: "prod"}.resolveProfile()
+	assert.Nil(t, err)
+	assert.Equal(t, "nacos.example.com", profile.Endpoint)
+
+	_, err = globalOptions{profileFile: file, profile: "missing"}.resolveProfile()
+	assert.Error(t, err)
+	_, err = globalOptions{profileFile: filepath.Join(t.TempDir(), "absent.json")}.resolveProfile()
+	assert.EqualError(t, err, "no server configured, use -server or a profile")
+}
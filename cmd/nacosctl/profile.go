@@ -0,0 +1,159 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+)
+
+const profileFileEnv = "NACOSCTL_CONFIG"
+
+// ProfileFile is the content of the profile file, default is $HOME/.nacosctl/config.json
+//
+//	{
+//	  "default": "dev",
+//	  "profiles": {
+//	    "dev": {"servers": ["127.0.0.1:8848"], "username": "nacos", "password": "nacos"}
+//	  }
+//	}
+type ProfileFile struct {
+	Default  string              `json:"default"`
+	Profiles map[string]*Profile `json:"profiles"`
+}
+
+// Profile holds the server and auth settings of one nacos cluster
+type Profile struct {
+	Servers     []string `json:"servers"`
+	Endpoint    string   `json:"endpoint"`
+	ContextPath string   `json:"contextPath"`
+	Namespace   string   `json:"namespace"`
+	Username    string   `json:"username"`
+	Password    string   `json:"password"`
+	AccessKey   string   `json:"accessKey"`
+	SecretKey   string   `json:"secretKey"`
+	TimeoutMs   uint64   `json:"timeoutMs"`
+	LogDir      string   `json:"logDir"`
+	CacheDir    string   `json:"cacheDir"`
+	LogLevel    string   `json:"logLevel"`
+}
+
+func defaultProfileFile() string {
+	if f := os.Getenv(profileFileEnv); f != "" {
+		return f
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".nacosctl", "config.json")
+}
+
+// loadProfile read the named profile, a missing profile file is not an error so that flags alone are enough
+func loadProfile(fileName, name string) (*Profile, error) {
+	profile := &Profile{}
+	if fileName == "" {
+		return profile, nil
+	}
+	b, err := ioutil.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return profile, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var pf ProfileFile
+	if err = json.Unmarshal(b, &pf); err != nil {
+		return nil, fmt.Errorf("parse profile file %s failed: %v", fileName, err)
+	}
+	if name == "" {
+		name = pf.Default
+	}
+	if name == "" {
+		return profile, nil
+	}
+	p, ok := pf.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %s not found in %s", name, fileName)
+	}
+	return p, nil
+}
+
+func (p *Profile) serverConfigs() ([]constant.ServerConfig, error) {
+	var configs []constant.ServerConfig
+	for _, addr := range p.Servers {
+		host, port := addr, uint64(8848)
+		if i := strings.LastIndex(addr, ":"); i > 0 && !strings.HasSuffix(addr, "]") {
+			var err error
+			host = addr[:i]
+			if port, err = strconv.ParseUint(addr[i+1:], 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid server address %s", addr)
+			}
+		}
+		var opts []constant.ServerOption
+		if p.ContextPath != "" {
+			opts = append(opts, constant.WithContextPath(p.ContextPath))
+		}
+		configs = append(configs, *constant.NewServerConfig(host, port, opts...))
+	}
+	return configs, nil
+}
+
+func (p *Profile) clientParam() (vo.NacosClientParam, error) {
+	serverConfigs, err := p.serverConfigs()
+	if err != nil {
+		return vo.NacosClientParam{}, err
+	}
+	workDir := filepath.Join(os.TempDir(), "nacosctl")
+	logDir, cacheDir, logLevel := p.LogDir, p.CacheDir, p.LogLevel
+	if logDir == "" {
+		logDir = filepath.Join(workDir, "log")
+	}
+	if cacheDir == "" {
+		cacheDir = filepath.Join(workDir, "cache")
+	}
+	if logLevel == "" {
+		logLevel = "warn"
+	}
+	opts := []constant.ClientOption{
+		constant.WithNamespaceId(p.Namespace),
+		constant.WithUsername(p.Username),
+		constant.WithPassword(p.Password),
+		constant.WithAccessKey(p.AccessKey),
+		constant.WithSecretKey(p.SecretKey),
+		constant.WithEndpoint(p.Endpoint),
+		constant.WithNotLoadCacheAtStart(true),
+		constant.WithLogDir(logDir),
+		constant.WithCacheDir(cacheDir),
+		constant.WithLogLevel(logLevel),
+	}
+	if p.TimeoutMs > 0 {
+		opts = append(opts, constant.WithTimeoutMs(p.TimeoutMs))
+	}
+	return vo.NacosClientParam{
+		ClientConfig:  constant.NewClientConfig(opts...),
+		ServerConfigs: serverConfigs,
+	}, nil
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package accesslog logs the operations of sdk in single structured lines, such as
+// {"time":"...","op":"ConfigQueryRequest","key":"dataId+group+tenant","latencyMs":3.2,"result":"ok","server":"127.0.0.1:8848","bytes":120}
+// The lines are json so they can be fed into ELK directly, and they are sampled by the rate of each operation
+package accesslog
+
+import (
+	"encoding/json"
+	"io"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+)
+
+// The results of operations other than the error codes
+const (
+	ResultOk    = "ok"
+	ResultError = "error"
+)
+
+// Entry is an operation logged in a line
+type Entry struct {
+	Op      string        // the type of grpc requests, or the api path of http requests
+	Key     string        // the config or service key the operation works on
+	Latency time.Duration // the time the operation takes
+	Result  string        // ResultOk, ResultError or the error code of server
+	Server  string        // the address of server
+	Bytes   int           // the size of response body
+}
+
+type line struct {
+	Time      string  `json:"time"`
+	Op        string  `json:"op"`
+	Key       string  `json:"key,omitempty"`
+	LatencyMs float64 `json:"latencyMs"`
+	Result    string  `json:"result"`
+	Server    string  `json:"server,omitempty"`
+	Bytes     int     `json:"bytes"`
+}
+
+// Logger write the sampled operations to out, it's safe to change the sampling rates while the client is running
+type Logger struct {
+	mutex sync.Mutex
+	out   io.Writer
+	rate  float64
+	rates map[string]float64
+	rand  *rand.Rand
+}
+
+// NewLogger create the logger writing to out, os.Stdout is used when out is nil. rate is the sampling rate of
+// the operations without their own rate, 1 means all operations are logged and 0 means none
+func NewLogger(out io.Writer, rate float64) *Logger {
+	if out == nil {
+		out = os.Stdout
+	}
+	return &Logger{out: out, rate: rate, rates: make(map[string]float64), rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// SetSampleRate set the sampling rate of the operation, it's in [0, 1]
+func (l *Logger) SetSampleRate(operation string, rate float64) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.rates[operation] = rate
+}
+
+// Sampled return true if this operation should be logged, nil logger logs nothing. It's checked before building
+// the entry, so the operations not sampled cost nothing
+func (l *Logger) Sampled(operation string) bool {
+	if l == nil {
+		return false
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	rate, ok := l.rates[operation]
+	if !ok {
+		rate = l.rate
+	}
+	return rate >= 1 || (rate > 0 && l.rand.Float64() < rate)
+}
+
+// Log write the entry in a line
+func (l *Logger) Log(entry Entry) {
+	bytes, err := json.Marshal(line{
+		Time:      time.Now().Format(time.RFC3339Nano),
+		Op:        entry.Op,
+		Key:       entry.Key,
+		LatencyMs: float64(entry.Latency.Microseconds()) / 1000,
+		Result:    entry.Result,
+		Server:    entry.Server,
+		Bytes:     entry.Bytes,
+	})
+	if err != nil {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	_, _ = l.out.Write(append(bytes, '\n'))
+}
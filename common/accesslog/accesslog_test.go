@@ -0,0 +1,66 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_Sampled(t *testing.T) {
+	var nilLogger *Logger
+	assert.False(t, nilLogger.Sampled("ConfigQueryRequest"))
+
+	logger := NewLogger(&bytes.Buffer{}, 1)
+	assert.True(t, logger.Sampled("ConfigQueryRequest"))
+	logger.SetSampleRate("ConfigBatchListenRequest", 0)
+	assert.False(t, logger.Sampled("ConfigBatchListenRequest"))
+
+	logger.SetSampleRate("InstanceRequest", 0.5)
+	sampled := 0
+	for i := 0; i < 1000; i++ {
+		if logger.Sampled("InstanceRequest") {
+			sampled++
+		}
+	}
+	assert.InDelta(t, 500, sampled, 100)
+}
+
+func TestLogger_Log(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := NewLogger(out, 1)
+	logger.Log(Entry{Op: "ConfigQueryRequest", Key: "a+b+c", Latency: 1500 * time.Microsecond, Result: ResultOk, Server: "127.0.0.1:9848", Bytes: 10})
+	logger.Log(Entry{Op: "/v1/ns/instance", Result: "500"})
+
+	lines := bytes.Split(bytes.TrimSuffix(out.Bytes(), []byte("\n")), []byte("\n"))
+	assert.Equal(t, 2, len(lines))
+	var fields map[string]interface{}
+	assert.Nil(t, json.Unmarshal(lines[0], &fields))
+	assert.Equal(t, "ConfigQueryRequest", fields["op"])
+	assert.Equal(t, "a+b+c", fields["key"])
+	assert.Equal(t, 1.5, fields["latencyMs"])
+	assert.Equal(t, "ok", fields["result"])
+	assert.Equal(t, "127.0.0.1:9848", fields["server"])
+	assert.Equal(t, float64(10), fields["bytes"])
+	assert.NotEmpty(t, fields["time"])
+	assert.Nil(t, json.Unmarshal(lines[1], &fields))
+	assert.Equal(t, "500", fields["result"])
+}
@@ -0,0 +1,151 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package audit records the mutating operations of sdk, such as publishing configs and registering instances, with
+// who did them, when and the result. The records are appended to a local file or a custom sink, so each node keeps
+// its own trail independent of the logs of server
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/file"
+)
+
+// The audited operations
+const (
+	OpPublishConfig         = "PublishConfig"
+	OpDeleteConfig          = "DeleteConfig"
+	OpRegisterInstance      = "RegisterInstance"
+	OpBatchRegisterInstance = "BatchRegisterInstance"
+	OpDeregisterInstance    = "DeregisterInstance"
+	OpUpdateInstance        = "UpdateInstance"
+)
+
+// The results of operations
+const (
+	ResultOk      = "ok"
+	ResultRefused = "refused" // the server returns false without error
+	ResultError   = "error"
+)
+
+// Record is an operation in the trail
+type Record struct {
+	Time      string `json:"time"`
+	Op        string `json:"op"`
+	Who       string `json:"who,omitempty"` // the Username or AccessKey of client
+	App       string `json:"app,omitempty"` // the AppName of client
+	Namespace string `json:"namespace"`
+	Group     string `json:"group"`
+	Key       string `json:"key"`              // the dataId or serviceName
+	Target    string `json:"target,omitempty"` // the md5 of published content or the addresses of instances
+	Result    string `json:"result"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Sink receive the records, it's called by the operations synchronously so it should not block long
+type Sink interface {
+	Write(record Record) error
+}
+
+// SinkFunc adapt the function to Sink
+type SinkFunc func(record Record) error
+
+func (f SinkFunc) Write(record Record) error {
+	return f(record)
+}
+
+// FileSink append the records to a file in json lines, the file is only appended so the trail can't be rewritten
+// by the client
+type FileSink struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+// NewFileSink open the file to append the records, it's created with the mode of secret files when it doesn't exist
+func NewFileSink(path string) (*FileSink, error) {
+	if err := file.MkdirIfNecessary(filepath.Dir(path)); err != nil {
+		return nil, errors.Wrapf(err, "[audit.NewFileSink] create dir of %s failed", path)
+	}
+	if err := file.TouchFile(path, file.FileMode(true)); err != nil {
+		return nil, errors.Wrapf(err, "[audit.NewFileSink] create %s failed", path)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[audit.NewFileSink] open %s failed", path)
+	}
+	return &FileSink{file: f}, nil
+}
+
+// Write append the record in a line
+func (s *FileSink) Write(record Record) error {
+	bytes, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err = s.file.Write(append(bytes, '\n'))
+	return err
+}
+
+// Close close the file, the records written later are failed
+func (s *FileSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.file.Close()
+}
+
+// Recorder fill the identity of client and the time of the records and write them to the sink
+type Recorder struct {
+	sink  Sink
+	who   string
+	app   string
+	clock clock.Clock
+}
+
+// NewRecorder create the recorder of client, nil is returned when sink is nil and it records nothing
+func NewRecorder(sink Sink, who, app string, clk clock.Clock) *Recorder {
+	if sink == nil {
+		return nil
+	}
+	return &Recorder{sink: sink, who: who, app: app, clock: clock.OrReal(clk)}
+}
+
+// Record write the record of the operation done with err, the error of sink is returned
+func (r *Recorder) Record(record Record, done bool, err error) error {
+	if r == nil {
+		return nil
+	}
+	record.Time = r.clock.Now().Format(time.RFC3339Nano)
+	record.Who, record.App = r.who, r.app
+	switch {
+	case err != nil:
+		record.Result, record.Error = ResultError, err.Error()
+	case !done:
+		record.Result = ResultRefused
+	default:
+		record.Result = ResultOk
+	}
+	return r.sink.Write(record)
+}
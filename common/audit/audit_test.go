@@ -0,0 +1,78 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
+)
+
+func TestRecorder_Record(t *testing.T) {
+	var nilRecorder *Recorder
+	assert.Nil(t, nilRecorder.Record(Record{Op: OpPublishConfig}, true, nil))
+	assert.Nil(t, NewRecorder(nil, "nacos", "app", nil))
+
+	var records []Record
+	clk := clock.NewFakeClock(time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC))
+	recorder := NewRecorder(SinkFunc(func(record Record) error {
+		records = append(records, record)
+		return nil
+	}), "nacos", "app", clk)
+
+	_ = recorder.Record(Record{Op: OpPublishConfig, Namespace: "public", Group: "DEFAULT_GROUP", Key: "app.yaml"}, true, nil)
+	_ = recorder.Record(Record{Op: OpDeleteConfig}, false, nil)
+	_ = recorder.Record(Record{Op: OpRegisterInstance, Target: "10.0.0.1:8080"}, false, errors.New("connection refused"))
+
+	assert.Equal(t, 3, len(records))
+	assert.Equal(t, Record{Time: "2022-05-01T10:00:00Z", Op: OpPublishConfig, Who: "nacos", App: "app",
+		Namespace: "public", Group: "DEFAULT_GROUP", Key: "app.yaml", Result: ResultOk}, records[0])
+	assert.Equal(t, ResultRefused, records[1].Result)
+	assert.Equal(t, ResultError, records[2].Result)
+	assert.Equal(t, "connection refused", records[2].Error)
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "nacos-audit.log")
+	sink, err := NewFileSink(path)
+	assert.Nil(t, err)
+	assert.Nil(t, sink.Write(Record{Op: OpPublishConfig, Key: "a"}))
+	assert.Nil(t, sink.Close())
+
+	// the records are appended after reopening
+	sink, err = NewFileSink(path)
+	assert.Nil(t, err)
+	assert.Nil(t, sink.Write(Record{Op: OpDeleteConfig, Key: "a"}))
+	assert.Nil(t, sink.Close())
+	assert.NotNil(t, sink.Write(Record{Op: OpDeleteConfig, Key: "b"}))
+
+	content, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	lines := bytes.Split(bytes.TrimSuffix(content, []byte("\n")), []byte("\n"))
+	assert.Equal(t, 2, len(lines))
+	var record Record
+	assert.Nil(t, json.Unmarshal(lines[1], &record))
+	assert.Equal(t, OpDeleteConfig, record.Op)
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package chaos injects faults into the requests to server, so the applications can test how they handle the
+// degradation of config and naming service without a proxy like toxiproxy. It's intended for testing only
+package chaos
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The operations are the type of grpc requests, or the api path of http requests
+const (
+	OpConfigQuery       = "ConfigQueryRequest"
+	OpConfigPublish     = "ConfigPublishRequest"
+	OpConfigRemove      = "ConfigRemoveRequest"
+	OpConfigListen      = "ConfigBatchListenRequest"
+	OpInstance          = "InstanceRequest"
+	OpBatchInstance     = "BatchInstanceRequest"
+	OpServiceQuery      = "ServiceQueryRequest"
+	OpSubscribeService  = "SubscribeServiceRequest"
+	OpServiceList       = "ServiceListRequest"
+	OpConfigSearch      = "/v1/cs/configs"
+	OpInstanceHttp      = "/v1/ns/instance"
+	OpServiceListHttp   = "/v1/ns/service/list"
+	OpInstanceBeatHttp  = "/v1/ns/instance/beat"
+	OpInstanceQueryHttp = "/v1/ns/instance/list"
+	OpServiceInfoHttp   = "/v1/ns/service"
+)
+
+// MalformedBody is the response body of Fault.Malformed, which fails to decode
+const MalformedBody = `{"resultCode":200,"errorCode":0,"success":tr`
+
+// Fault is the fault injected into an operation. The request is delayed by Latency, then it fails as timeout when
+// Timeout is set, or it's responded with Code or MalformedBody instead of sending to server. Otherwise the request is
+// sent to server after the delay
+type Fault struct {
+	Latency     time.Duration // delay the request, it's bounded by the timeout of request
+	Timeout     bool          // fail the request as timeout
+	Code        int           // respond with the error code, such as 403 and 500, the 404 of config query is responded as config not found
+	Malformed   bool          // respond with a malformed body
+	Probability float64       // the probability the fault is injected, 0 means always
+}
+
+// ErrTimeout is returned by the requests failed by Fault.Timeout
+var ErrTimeout = errors.New("[chaos] request timeout")
+
+// Injector keep the faults of operations, it's safe to change the faults while the client is running
+type Injector struct {
+	mutex  sync.RWMutex
+	faults map[string]Fault
+	rand   *rand.Rand
+}
+
+func NewInjector() *Injector {
+	return &Injector{faults: make(map[string]Fault), rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// Set inject the fault into the operation, the previous fault of it is replaced
+func (i *Injector) Set(operation string, fault Fault) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.faults[operation] = fault
+}
+
+// Remove the fault of the operation
+func (i *Injector) Remove(operation string) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	delete(i.faults, operation)
+}
+
+// Reset remove the faults of all operations
+func (i *Injector) Reset() {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.faults = make(map[string]Fault)
+}
+
+// Fault return the fault of the operation if it's injected into this request, nil injector injects nothing
+func (i *Injector) Fault(operation string) (Fault, bool) {
+	if i == nil {
+		return Fault{}, false
+	}
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	fault, ok := i.faults[operation]
+	if !ok || (fault.Probability > 0 && i.rand.Float64() >= fault.Probability) {
+		return Fault{}, false
+	}
+	return fault, true
+}
+
+// Wait delay the request by Latency, bounded by timeout, and return ErrTimeout when Timeout is set
+func (f Fault) Wait(timeout time.Duration) error {
+	delay := f.Latency
+	if f.Timeout && (delay == 0 || delay > timeout) {
+		delay = timeout
+	}
+	if timeout > 0 && delay > timeout {
+		delay = timeout
+	}
+	time.Sleep(delay)
+	if f.Timeout || (timeout > 0 && f.Latency > timeout) {
+		return ErrTimeout
+	}
+	return nil
+}
+
+// Responds return true if the request is responded by the fault instead of server
+func (f Fault) Responds() bool {
+	return f.Code != 0 || f.Malformed
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chaos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInjector(t *testing.T) {
+	var nilInjector *Injector
+	_, ok := nilInjector.Fault(OpConfigQuery)
+	assert.False(t, ok)
+
+	injector := NewInjector()
+	injector.Set(OpConfigQuery, Fault{Code: 500})
+	fault, ok := injector.Fault(OpConfigQuery)
+	assert.True(t, ok)
+	assert.True(t, fault.Responds())
+	_, ok = injector.Fault(OpConfigPublish)
+	assert.False(t, ok)
+
+	injector.Set(OpConfigPublish, Fault{Code: 500, Probability: 0.5})
+	injected := 0
+	for i := 0; i < 1000; i++ {
+		if _, ok = injector.Fault(OpConfigPublish); ok {
+			injected++
+		}
+	}
+	assert.InDelta(t, 500, injected, 100)
+
+	injector.Remove(OpConfigQuery)
+	_, ok = injector.Fault(OpConfigQuery)
+	assert.False(t, ok)
+	injector.Reset()
+	_, ok = injector.Fault(OpConfigPublish)
+	assert.False(t, ok)
+}
+
+func TestFault_Wait(t *testing.T) {
+	start := time.Now()
+	assert.Nil(t, Fault{Latency: 20 * time.Millisecond}.Wait(time.Second))
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+
+	// the latency is bounded by the timeout of request
+	start = time.Now()
+	assert.Equal(t, ErrTimeout, Fault{Latency: time.Minute}.Wait(20*time.Millisecond))
+	assert.True(t, time.Since(start) < time.Second)
+
+	assert.Equal(t, ErrTimeout, Fault{Timeout: true}.Wait(10*time.Millisecond))
+	assert.False(t, Fault{Latency: time.Millisecond}.Responds())
+}
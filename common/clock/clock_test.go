@@ -0,0 +1,81 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrReal(t *testing.T) {
+	assert.Equal(t, Real, OrReal(nil))
+	fake := NewFakeClock(time.Unix(0, 0))
+	assert.Equal(t, fake, OrReal(fake))
+}
+
+func TestFakeClock_Timer(t *testing.T) {
+	start := time.Unix(1000, 0)
+	c := NewFakeClock(start)
+	timer := c.NewTimer(time.Second)
+	c.Advance(500 * time.Millisecond)
+	assert.Empty(t, timer.C())
+	c.Advance(500 * time.Millisecond)
+	assert.Equal(t, start.Add(time.Second), <-timer.C())
+	assert.Equal(t, 0, c.WaiterCount())
+
+	assert.False(t, timer.Reset(time.Second))
+	assert.True(t, timer.Stop())
+	c.Advance(time.Second)
+	assert.Empty(t, timer.C())
+	assert.Equal(t, start.Add(2*time.Second), c.Now())
+	assert.Equal(t, time.Second, c.Until(start.Add(3*time.Second)))
+}
+
+func TestFakeClock_TickerAndAfterFunc(t *testing.T) {
+	c := NewFakeClock(time.Unix(0, 0))
+	var fired []time.Time
+	c.AfterFunc(1500*time.Millisecond, func() {
+		fired = append(fired, c.Now())
+	})
+	ticker := c.NewTicker(time.Second)
+	defer ticker.Stop()
+	c.Advance(time.Second)
+	assert.Equal(t, time.Unix(1, 0), <-ticker.C())
+	// the ticks are dropped when the receiver is slow
+	c.Advance(3 * time.Second)
+	assert.Equal(t, time.Unix(2, 0), <-ticker.C())
+	assert.Empty(t, ticker.C())
+	assert.Equal(t, []time.Time{time.Unix(1, 500000000)}, fired)
+}
+
+func TestFakeClock_BlockUntil(t *testing.T) {
+	c := NewFakeClock(time.Unix(0, 0))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-c.NewTimer(time.Minute).C()
+	}()
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("timer is not fired")
+	}
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FakeClock is the clock for tests, the time only moves forward by Advance, which fires the timers and tickers
+// due in order. The functions of AfterFunc are called synchronously by Advance
+type FakeClock struct {
+	mux     sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	changed chan struct{}
+}
+
+type fakeWaiter struct {
+	clock    *FakeClock
+	deadline time.Time
+	period   time.Duration // the period of ticker, 0 for timer
+	ch       chan time.Time
+	f        func()
+}
+
+// NewFakeClock create the fake clock starting at now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now, changed: make(chan struct{})}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *FakeClock) Until(t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{clock: c, ch: make(chan time.Time, 1)}
+	c.add(w, d)
+	return w
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	w := &fakeWaiter{clock: c, period: d, ch: make(chan time.Time, 1)}
+	c.add(w, d)
+	return fakeTicker{w}
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	w := &fakeWaiter{clock: c, f: f}
+	c.add(w, d)
+	return w
+}
+
+// Advance move the time forward by d, and fire the timers and tickers due in order of their deadlines
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mux.Lock()
+	target := c.now.Add(d)
+	for {
+		sort.SliceStable(c.waiters, func(i, j int) bool {
+			return c.waiters[i].deadline.Before(c.waiters[j].deadline)
+		})
+		if len(c.waiters) == 0 || c.waiters[0].deadline.After(target) {
+			break
+		}
+		w := c.waiters[0]
+		c.now = w.deadline
+		if w.period > 0 {
+			w.deadline = w.deadline.Add(w.period)
+		} else {
+			c.waiters = c.waiters[1:]
+		}
+		if w.f != nil {
+			c.mux.Unlock()
+			w.f()
+			c.mux.Lock()
+			continue
+		}
+		select {
+		case w.ch <- c.now:
+		default:
+			// drop the tick like time.Ticker when the receiver is slow
+		}
+	}
+	c.now = target
+	c.notify()
+	c.mux.Unlock()
+}
+
+// WaiterCount return the number of active timers and tickers
+func (c *FakeClock) WaiterCount() int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil block until there are at least n active timers and tickers, so the test advances the clock after
+// the goroutine under test starts waiting
+func (c *FakeClock) BlockUntil(n int) {
+	for {
+		c.mux.Lock()
+		if len(c.waiters) >= n {
+			c.mux.Unlock()
+			return
+		}
+		changed := c.changed
+		c.mux.Unlock()
+		<-changed
+	}
+}
+
+func (c *FakeClock) add(w *fakeWaiter, d time.Duration) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	w.deadline = c.now.Add(d)
+	c.waiters = append(c.waiters, w)
+	c.notify()
+}
+
+// remove the waiter and return true if it was active
+func (c *FakeClock) remove(w *fakeWaiter) bool {
+	for i, item := range c.waiters {
+		if item == w {
+			c.waiters = append(c.waiters[:i:i], c.waiters[i+1:]...)
+			c.notify()
+			return true
+		}
+	}
+	return false
+}
+
+// notify wake up the goroutines blocked in BlockUntil, it must be called with mux held
+func (c *FakeClock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+type fakeTicker struct {
+	*fakeWaiter
+}
+
+func (t fakeTicker) Stop() {
+	t.fakeWaiter.Stop()
+}
+
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.ch
+}
+
+func (w *fakeWaiter) Stop() bool {
+	w.clock.mux.Lock()
+	defer w.clock.mux.Unlock()
+	return w.clock.remove(w)
+}
+
+func (w *fakeWaiter) Reset(d time.Duration) bool {
+	w.clock.mux.Lock()
+	active := w.clock.remove(w)
+	w.clock.mux.Unlock()
+	w.clock.add(w, d)
+	return active
+}
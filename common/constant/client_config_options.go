@@ -20,6 +20,10 @@ import (
 	"os"
 	"time"
 
+	"github.com/nacos-group/nacos-sdk-go/v2/common/accesslog"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/audit"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/chaos"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/file"
 )
 
@@ -30,6 +34,7 @@ func NewClientConfig(opts ...ClientOption) *ClientConfig {
 		OpenKMS:              false,
 		CacheDir:             file.GetCurrentPath() + string(os.PathSeparator) + "cache",
 		UpdateThreadNum:      20,
+		ConfigFetchThreadNum: 8,
 		NotLoadCacheAtStart:  false,
 		UpdateCacheWhenEmpty: false,
 		LogDir:               file.GetCurrentPath() + string(os.PathSeparator) + "log",
@@ -123,6 +128,13 @@ func WithDisableUseSnapShot(disableUseSnapShot bool) ClientOption {
 	}
 }
 
+// WithStrictRead ...
+func WithStrictRead(strictRead bool) ClientOption {
+	return func(config *ClientConfig) {
+		config.StrictRead = strictRead
+	}
+}
+
 // WithUpdateThreadNum ...
 func WithUpdateThreadNum(updateThreadNum int) ClientOption {
 	return func(config *ClientConfig) {
@@ -191,3 +203,263 @@ func WithTLS(tlsCfg TLSConfig) ClientOption {
 		config.TLSCfg = tlsCfg
 	}
 }
+
+// WithConfigSign ...
+func WithConfigSign(signCfg *ConfigSignConfig) ClientOption {
+	return func(config *ClientConfig) {
+		config.ConfigSignCfg = signCfg
+	}
+}
+
+// WithConfigHistorySize ...
+func WithConfigHistorySize(configHistorySize int) ClientOption {
+	return func(config *ClientConfig) {
+		config.ConfigHistorySize = configHistorySize
+	}
+}
+
+// WithLoadBalancer ...
+func WithLoadBalancer(loadBalancer string) ClientOption {
+	return func(config *ClientConfig) {
+		config.LoadBalancer = loadBalancer
+	}
+}
+
+// WithServiceLoadBalancers ... the keys are groupName@@serviceName such as DEFAULT_GROUP@@demo
+func WithServiceLoadBalancers(serviceLoadBalancers map[string]string) ClientOption {
+	return func(config *ClientConfig) {
+		config.ServiceLoadBalancers = serviceLoadBalancers
+	}
+}
+
+// WithIdentity ...
+func WithIdentity(identityCfg *IdentityConfig) ClientOption {
+	return func(config *ClientConfig) {
+		config.IdentityCfg = identityCfg
+	}
+}
+
+// WithKeepSnapshotOnDelete ...
+func WithKeepSnapshotOnDelete(keepSnapshotOnDelete bool) ClientOption {
+	return func(config *ClientConfig) {
+		config.KeepSnapshotOnDelete = keepSnapshotOnDelete
+	}
+}
+
+// WithIpDetect ...
+func WithIpDetect(ipDetectCfg *IpDetectConfig) ClientOption {
+	return func(config *ClientConfig) {
+		config.IpDetectCfg = ipDetectCfg
+	}
+}
+
+// WithPersistSubscriptions ...
+func WithPersistSubscriptions(persistSubscriptions bool) ClientOption {
+	return func(config *ClientConfig) {
+		config.PersistSubscriptions = persistSubscriptions
+	}
+}
+
+// WithConfigFetchThreadNum ...
+func WithConfigFetchThreadNum(configFetchThreadNum int) ClientOption {
+	return func(config *ClientConfig) {
+		config.ConfigFetchThreadNum = configFetchThreadNum
+	}
+}
+
+// WithPublishWorkflow ...
+func WithPublishWorkflow(publishWorkflowCfg *PublishWorkflowConfig) ClientOption {
+	return func(config *ClientConfig) {
+		config.PublishWorkflowCfg = publishWorkflowCfg
+	}
+}
+
+// WithResync ...
+func WithResync(resyncCfg *ResyncConfig) ClientOption {
+	return func(config *ClientConfig) {
+		config.ResyncCfg = resyncCfg
+	}
+}
+
+// WithSubsetKey ...
+func WithSubsetKey(subsetKey string) ClientOption {
+	return func(config *ClientConfig) {
+		config.SubsetKey = subsetKey
+	}
+}
+
+// WithRegistrationHooks ...
+func WithRegistrationHooks(registrationHooks *RegistrationHooks) ClientOption {
+	return func(config *ClientConfig) {
+		config.RegistrationHooks = registrationHooks
+	}
+}
+
+// WithServerDnsName ...
+func WithServerDnsName(serverDnsName string) ClientOption {
+	return func(config *ClientConfig) {
+		config.ServerDnsName = serverDnsName
+	}
+}
+
+// WithServerDnsPort ...
+func WithServerDnsPort(serverDnsPort uint64) ClientOption {
+	return func(config *ClientConfig) {
+		config.ServerDnsPort = serverDnsPort
+	}
+}
+
+// WithDnsRefreshInterval ...
+func WithDnsRefreshInterval(dnsRefreshInterval time.Duration) ClientOption {
+	return func(config *ClientConfig) {
+		config.DnsRefreshInterval = dnsRefreshInterval
+	}
+}
+
+// WithServerSelection ...
+func WithServerSelection(serverSelection string) ClientOption {
+	return func(config *ClientConfig) {
+		config.ServerSelection = serverSelection
+	}
+}
+
+// WithHttpCacheSize ...
+func WithHttpCacheSize(httpCacheSize int) ClientOption {
+	return func(config *ClientConfig) {
+		config.HttpCacheSize = httpCacheSize
+	}
+}
+
+// WithDnsCache ...
+func WithDnsCache(dnsCacheCfg *DnsCacheConfig) ClientOption {
+	return func(config *ClientConfig) {
+		config.DnsCacheCfg = dnsCacheCfg
+	}
+}
+
+// WithListenerBreaker ...
+func WithListenerBreaker(listenerBreakerCfg *ListenerBreakerConfig) ClientOption {
+	return func(config *ClientConfig) {
+		config.ListenerBreakerCfg = listenerBreakerCfg
+	}
+}
+
+// WithGrpcCompressors ...
+func WithGrpcCompressors(grpcCompressors ...string) ClientOption {
+	return func(config *ClientConfig) {
+		config.GrpcCompressors = grpcCompressors
+	}
+}
+
+// WithConfigMemoryBudget ...
+func WithConfigMemoryBudget(configMemoryBudget int) ClientOption {
+	return func(config *ClientConfig) {
+		config.ConfigMemoryBudget = configMemoryBudget
+	}
+}
+
+// WithFilePerm ...
+func WithFilePerm(filePermCfg *file.PermConfig) ClientOption {
+	return func(config *ClientConfig) {
+		config.FilePermCfg = filePermCfg
+	}
+}
+
+// WithQuota ...
+func WithQuota(quotaCfg *QuotaConfig) ClientOption {
+	return func(config *ClientConfig) {
+		config.QuotaCfg = quotaCfg
+	}
+}
+
+// WithBeatSmoothing ...
+func WithBeatSmoothing(beatSmoothingCfg *BeatSmoothingConfig) ClientOption {
+	return func(config *ClientConfig) {
+		config.BeatSmoothingCfg = beatSmoothingCfg
+	}
+}
+
+// WithAuditSink ...
+func WithAuditSink(auditSink audit.Sink) ClientOption {
+	return func(config *ClientConfig) {
+		config.AuditSink = auditSink
+	}
+}
+
+// WithInstanceFlapDamping ...
+func WithInstanceFlapDamping(instanceFlapDamping int) ClientOption {
+	return func(config *ClientConfig) {
+		config.InstanceFlapDamping = instanceFlapDamping
+	}
+}
+
+// WithClock ...
+func WithClock(clock clock.Clock) ClientOption {
+	return func(config *ClientConfig) {
+		config.Clock = clock
+	}
+}
+
+// WithListenBatchSize ...
+func WithListenBatchSize(listenBatchSize int) ClientOption {
+	return func(config *ClientConfig) {
+		config.ListenBatchSize = listenBatchSize
+	}
+}
+
+// WithListenMaxBytes ...
+func WithListenMaxBytes(listenMaxBytes int) ClientOption {
+	return func(config *ClientConfig) {
+		config.ListenMaxBytes = listenMaxBytes
+	}
+}
+
+// WithServiceCache ...
+func WithServiceCache(serviceCacheTTL, serviceStaleTTL time.Duration) ClientOption {
+	return func(config *ClientConfig) {
+		config.ServiceCacheTTL = serviceCacheTTL
+		config.ServiceStaleTTL = serviceStaleTTL
+	}
+}
+
+// WithUserAgent ...
+func WithUserAgent(userAgent string) ClientOption {
+	return func(config *ClientConfig) {
+		config.UserAgent = userAgent
+	}
+}
+
+// WithAccessLog ...
+func WithAccessLog(accessLog *accesslog.Logger) ClientOption {
+	return func(config *ClientConfig) {
+		config.AccessLog = accessLog
+	}
+}
+
+// WithDefaultGroup ...
+func WithDefaultGroup(defaultGroup string) ClientOption {
+	return func(config *ClientConfig) {
+		config.DefaultGroup = defaultGroup
+	}
+}
+
+// WithDefaultServiceGroup ...
+func WithDefaultServiceGroup(defaultServiceGroup string) ClientOption {
+	return func(config *ClientConfig) {
+		config.DefaultServiceGroup = defaultServiceGroup
+	}
+}
+
+// WithGroupResolver ...
+func WithGroupResolver(groupResolver func(dataId string) string) ClientOption {
+	return func(config *ClientConfig) {
+		config.GroupResolver = groupResolver
+	}
+}
+
+// WithFaultInjector ...
+func WithFaultInjector(faultInjector *chaos.Injector) ClientOption {
+	return func(config *ClientConfig) {
+		config.FaultInjector = faultInjector
+	}
+}
@@ -43,6 +43,8 @@ const (
 	KEY_TOKEN_TTL               = "tokenTtl"
 	KEY_GLOBAL_ADMIN            = "globalAdmin"
 	KEY_TOKEN_REFRESH_WINDOW    = "tokenRefreshWindow"
+	IDENTITY_HEADER             = "Authorization"
+	IDENTITY_TOKEN_PREFIX       = "Bearer "
 	WEB_CONTEXT                 = "/nacos"
 	CONFIG_BASE_PATH            = "/v1/cs"
 	CONFIG_PATH                 = CONFIG_BASE_PATH + "/configs"
@@ -68,7 +70,8 @@ const (
 	KEY_BEAT                    = "beat"
 	KEY_DOM                     = "dom"
 	DEFAULT_CONTEXT_PATH        = "/nacos"
-	CLIENT_VERSION              = "Nacos-Go-Client:v2.2.2"
+	SDK_VERSION                 = "v2.2.2"
+	CLIENT_VERSION              = "Nacos-Go-Client:" + SDK_VERSION
 	REQUEST_DOMAIN_RETRY_TIME   = 3
 	SERVICE_INFO_SPLITER        = "@@"
 	CONFIG_INFO_SPLITER         = "@@"
@@ -85,8 +88,12 @@ const (
 	LABEL_MODULE_NAMING         = "naming"
 	RESPONSE_CODE_SUCCESS       = 200
 	UN_REGISTER                 = 301
+	NO_HANDLER                  = 302
+	CONFIG_NOT_FOUND            = 300
+	NO_RIGHT                    = 403
 	KEEP_ALIVE_TIME             = 5
 	DEFAULT_TIMEOUT_MILLS       = 3000
+	ABILITY_NEGOTIATION_TIMEOUT = 3 * time.Second
 	ALL_SYNC_INTERNAL           = 5 * time.Minute
 	CLIENT_APPNAME_HEADER       = "Client-AppName"
 	APPNAME_HEADER              = "AppName"
@@ -99,4 +106,27 @@ const (
 	GRPC                        = "grpc"
 	FAILOVER_FILE_SUFFIX        = "_failover"
 	RpcPortOffset               = 1000
+	SIGNATURE_DATAID_SUFFIX     = ".sig"
+	PUBLISH_IDEMPOTENCY_TTL     = 10 * time.Minute
+	BINARY_CONTENT_PREFIX       = "data:application/octet-stream;base64,"
+	MUTEX_METADATA_OWNER        = "nacos.mutex.owner"
+	STAGING_DATAID_SUFFIX       = ".staging"
+	SCHEDULED_PUBLISH_ATTEMPTS  = 3
+	DEFAULT_LISTEN_BATCH_SIZE   = 3000
+	MIN_LISTEN_BATCH_SIZE       = 100
+	MAX_LISTEN_BATCH_SIZE       = 10000
+	LABEL_SDK_VERSION           = "sdkVersion"
+	LABEL_GO_VERSION            = "goVersion"
+	LABEL_PLATFORM              = "platform"
+	API_GROUP_CONFIG            = "config"
+	API_GROUP_NAMING            = "naming"
+	API_GROUP_AUTH              = "auth"
+	SERVER_SELECTION_RANDOM     = "random"
+	SERVER_SELECTION_RW_SPLIT   = "read-write-split"
+	SERVER_SELECTION_STICKY     = "sticky"
+	SEARCH_RATE_LIMIT_RETRY     = 5
+	SEARCH_BACKOFF_BASE         = time.Second
+	SEARCH_BACKOFF_MAX          = 30 * time.Second
+	WAIT_CONFIG_BACKOFF_BASE    = 500 * time.Millisecond
+	WAIT_CONFIG_BACKOFF_MAX     = 30 * time.Second
 )
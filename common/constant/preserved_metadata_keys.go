@@ -20,4 +20,5 @@ const (
 	HEART_BEAT_TIMEOUT  = "preserved.heart.beat.timeout"
 	IP_DELETE_TIMEOUT   = "preserved.ip.delete.timeout"
 	HEART_BEAT_INTERVAL = "preserved.heart.beat.interval"
+	LOAD_BALANCER       = "preserved.load.balancer"
 )
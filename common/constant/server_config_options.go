@@ -34,37 +34,68 @@ func NewServerConfig(ipAddr string, port uint64, opts ...ServerOption) *ServerCo
 // ServerOption ...
 type ServerOption func(*ServerConfig)
 
-//WithScheme set Scheme for server
+// WithScheme set Scheme for server
 func WithScheme(scheme string) ServerOption {
 	return func(config *ServerConfig) {
 		config.Scheme = scheme
 	}
 }
 
-//WithContextPath set contextPath for server
+// WithContextPath set contextPath for server
 func WithContextPath(contextPath string) ServerOption {
 	return func(config *ServerConfig) {
 		config.ContextPath = contextPath
 	}
 }
 
-//WithIpAddr set ip address for server
+// WithApiContextPath set contextPath of the api group for server
+func WithApiContextPath(apiGroup, contextPath string) ServerOption {
+	return func(config *ServerConfig) {
+		if config.ApiContextPaths == nil {
+			config.ApiContextPaths = make(map[string]string)
+		}
+		config.ApiContextPaths[apiGroup] = contextPath
+	}
+}
+
+// WithUrlRewriter set the rewriter of http request url for server
+func WithUrlRewriter(urlRewriter func(apiGroup, url string) string) ServerOption {
+	return func(config *ServerConfig) {
+		config.UrlRewriter = urlRewriter
+	}
+}
+
+// WithIpAddr set ip address for server
 func WithIpAddr(ipAddr string) ServerOption {
 	return func(config *ServerConfig) {
 		config.IpAddr = ipAddr
 	}
 }
 
-//WithPort set port for server
+// WithPort set port for server
 func WithPort(port uint64) ServerOption {
 	return func(config *ServerConfig) {
 		config.Port = port
 	}
 }
 
-//WithGrpcPort set grpc port for server
+// WithGrpcPort set grpc port for server
 func WithGrpcPort(port uint64) ServerOption {
 	return func(config *ServerConfig) {
 		config.GrpcPort = port
 	}
 }
+
+// WithUnixSocket set the unix domain socket to reach the server
+func WithUnixSocket(socketPath string) ServerOption {
+	return func(config *ServerConfig) {
+		config.UnixSocket = socketPath
+	}
+}
+
+// WithSocks5Proxy set the SOCKS5 proxy to reach the server
+func WithSocks5Proxy(proxyUrl string) ServerOption {
+	return func(config *ServerConfig) {
+		config.Socks5Proxy = proxyUrl
+	}
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package dataid builds and parses the dataIds following the naming convention of an organization, such as
+// env-service-component.yaml, to stop the subtle typos of hand-built dataIds
+package dataid
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultSeparator = "-"
+	defaultExt       = "yaml"
+)
+
+// Key is the parts of the dataId env-service-component.ext, the service may contain the separator
+type Key struct {
+	Env       string
+	Service   string
+	Component string
+	Ext       string
+}
+
+// Convention is the naming convention of dataIds, the zero value is env-service-component.ext with any env and ext
+type Convention struct {
+	Separator  string   // the separator of the parts, default value is "-"
+	Envs       []string // the allowed envs, empty means any
+	Exts       []string // the allowed extensions, empty means any
+	DefaultExt string   // the extension used when it's not given, default value is yaml
+}
+
+// Default is the convention used by Format, Parse and Validate
+var Default = Convention{}
+
+// Format build the dataId of key with Default
+func Format(key Key) (string, error) {
+	return Default.Format(key)
+}
+
+// Parse split the dataId into key with Default
+func Parse(dataId string) (Key, error) {
+	return Default.Parse(dataId)
+}
+
+// Validate check whether the dataId follows Default
+func Validate(dataId string) error {
+	return Default.Validate(dataId)
+}
+
+func (c Convention) separator() string {
+	if len(c.Separator) == 0 {
+		return defaultSeparator
+	}
+	return c.Separator
+}
+
+// Format build the dataId of key, the error is returned when any part is invalid
+func (c Convention) Format(key Key) (string, error) {
+	if len(key.Ext) == 0 {
+		key.Ext = c.DefaultExt
+		if len(key.Ext) == 0 {
+			key.Ext = defaultExt
+		}
+	}
+	if err := c.validate(key); err != nil {
+		return "", errors.Wrapf(err, "[dataid.Format] invalid key %+v", key)
+	}
+	sep := c.separator()
+	return key.Env + sep + key.Service + sep + key.Component + "." + key.Ext, nil
+}
+
+// Parse split the dataId into key, the error is returned when it doesn't follow the convention
+func (c Convention) Parse(dataId string) (Key, error) {
+	dot := strings.LastIndex(dataId, ".")
+	if dot < 0 {
+		return Key{}, errors.Errorf("[dataid.Parse] dataId %s has no extension", dataId)
+	}
+	sep := c.separator()
+	name := dataId[:dot]
+	first, last := strings.Index(name, sep), strings.LastIndex(name, sep)
+	if first < 0 || first == last {
+		return Key{}, errors.Errorf("[dataid.Parse] dataId %s is not env%sservice%scomponent.ext", dataId, sep, sep)
+	}
+	key := Key{
+		Env:       name[:first],
+		Service:   name[first+len(sep) : last],
+		Component: name[last+len(sep):],
+		Ext:       dataId[dot+1:],
+	}
+	if err := c.validate(key); err != nil {
+		return Key{}, errors.Wrapf(err, "[dataid.Parse] invalid dataId %s", dataId)
+	}
+	return key, nil
+}
+
+// Validate check whether the dataId follows the convention
+func (c Convention) Validate(dataId string) error {
+	_, err := c.Parse(dataId)
+	return err
+}
+
+// GroupResolver return the resolver of ClientConfig.GroupResolver, which resolves the group of the dataIds following
+// the convention by group, the others are resolved as empty
+func (c Convention) GroupResolver(group func(key Key) string) func(dataId string) string {
+	return func(dataId string) string {
+		key, err := c.Parse(dataId)
+		if err != nil {
+			return ""
+		}
+		return group(key)
+	}
+}
+
+func (c Convention) validate(key Key) error {
+	sep := c.separator()
+	parts := []struct {
+		name, value string
+	}{{"env", key.Env}, {"service", key.Service}, {"component", key.Component}, {"ext", key.Ext}}
+	for _, part := range parts {
+		if len(part.value) == 0 {
+			return errors.Errorf("%s can not be empty", part.name)
+		}
+		if !isValidChars(part.value) {
+			return errors.Errorf("%s %s contains invalid chars, only letters, digits and -_: are allowed", part.name, part.value)
+		}
+	}
+	if strings.Contains(key.Env, sep) || strings.Contains(key.Component, sep) {
+		return errors.Errorf("env and component can not contain the separator %s", sep)
+	}
+	if strings.Contains(key.Ext, ".") {
+		return errors.New("ext can not contain .")
+	}
+	if len(c.Envs) > 0 && !contains(c.Envs, key.Env) {
+		return errors.Errorf("env %s is not one of %v", key.Env, c.Envs)
+	}
+	if len(c.Exts) > 0 && !contains(c.Exts, key.Ext) {
+		return errors.Errorf("ext %s is not one of %v", key.Ext, c.Exts)
+	}
+	return nil
+}
+
+// isValidChars check the chars allowed in dataId by server, the dot is only allowed before the extension
+func isValidChars(s string) bool {
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == ':') {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
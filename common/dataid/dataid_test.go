@@ -0,0 +1,57 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dataid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatAndParse(t *testing.T) {
+	dataId, err := Format(Key{Env: "prod", Service: "order-service", Component: "db"})
+	assert.Nil(t, err)
+	assert.Equal(t, "prod-order-service-db.yaml", dataId)
+
+	key, err := Parse(dataId)
+	assert.Nil(t, err)
+	assert.Equal(t, Key{Env: "prod", Service: "order-service", Component: "db", Ext: "yaml"}, key)
+
+	for _, invalid := range []string{"prod-order", "prod-order-db", "prod--db.yaml", "prod-order-db.", "prod-or der-db.yaml", "prod-order.v1-db.yaml"} {
+		assert.NotNil(t, Validate(invalid), invalid)
+	}
+	_, err = Format(Key{Env: "prod", Service: "order", Component: "db-primary"})
+	assert.NotNil(t, err)
+}
+
+func TestConvention(t *testing.T) {
+	convention := Convention{Separator: "_", Envs: []string{"dev", "prod"}, Exts: []string{"yaml", "properties"}, DefaultExt: "properties"}
+	dataId, err := convention.Format(Key{Env: "dev", Service: "order", Component: "cache"})
+	assert.Nil(t, err)
+	assert.Equal(t, "dev_order_cache.properties", dataId)
+
+	assert.Nil(t, convention.Validate("prod_order-service_db.yaml"))
+	// the typos of env and ext are rejected
+	assert.NotNil(t, convention.Validate("prd_order_db.yaml"))
+	assert.NotNil(t, convention.Validate("prod_order_db.yml"))
+
+	resolver := convention.GroupResolver(func(key Key) string {
+		return key.Service
+	})
+	assert.Equal(t, "order", resolver("prod_order_db.yaml"))
+	assert.Equal(t, "", resolver("application.yaml"))
+}
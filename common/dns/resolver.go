@@ -0,0 +1,165 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package dns caches the addresses of server hostnames, so that the hostnames are re-resolved when the ttl expires
+// or the dialing fails, instead of pinning the clients to the dead addresses after the server vip moves
+package dns
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+)
+
+const defaultTTL = 30 * time.Second
+
+// Resolver resolves the hostnames and caches their addresses within the ttl. The stale addresses are used
+// when the re-resolution fails, and the listeners are notified when the addresses of a hostname change
+type Resolver struct {
+	ttl             time.Duration
+	keepOnDialError bool
+	clock           clock.Clock
+	lookup          func(ctx context.Context, host string) ([]string, error)
+	dialer          *net.Dialer
+
+	mu        sync.Mutex
+	entries   map[string]*entry
+	listeners []func(host string)
+}
+
+type entry struct {
+	addrs    []string
+	expireAt time.Time
+}
+
+// NewResolver create the resolver by cfg, clk is the system clock when nil
+func NewResolver(cfg constant.DnsCacheConfig, clk clock.Clock) *Resolver {
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	return &Resolver{
+		ttl:             ttl,
+		keepOnDialError: cfg.KeepOnDialError,
+		clock:           clock.OrReal(clk),
+		lookup:          net.DefaultResolver.LookupHost,
+		dialer:          &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+		entries:         map[string]*entry{},
+	}
+}
+
+// OnChange add the listener called after the addresses of host change, such as closing the idle connections
+func (r *Resolver) OnChange(listener func(host string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, listener)
+}
+
+// Resolve returns the addresses of host, the ip is returned as it is
+func (r *Resolver) Resolve(ctx context.Context, host string) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+	r.mu.Lock()
+	cached := r.entries[host]
+	if cached != nil && r.clock.Now().Before(cached.expireAt) {
+		r.mu.Unlock()
+		return cached.addrs, nil
+	}
+	r.mu.Unlock()
+
+	addrs, err := r.lookup(ctx, host)
+	if err == nil && len(addrs) == 0 {
+		err = errors.Errorf("no address of host %s", host)
+	}
+	if err != nil {
+		if cached != nil {
+			logger.Warnf("re-resolve host %s failed, use the stale addresses %v, err:%v", host, cached.addrs, err)
+			return cached.addrs, nil
+		}
+		return nil, errors.Wrapf(err, "[dns.Resolve] resolve host %s failed", host)
+	}
+	sort.Strings(addrs)
+
+	r.mu.Lock()
+	r.entries[host] = &entry{addrs: addrs, expireAt: r.clock.Now().Add(r.ttl)}
+	listeners := r.listeners
+	r.mu.Unlock()
+	if cached != nil && !equal(cached.addrs, addrs) {
+		logger.Infof("addresses of host %s changed from %v to %v", host, cached.addrs, addrs)
+		for _, listener := range listeners {
+			listener(host)
+		}
+	}
+	return addrs, nil
+}
+
+// Invalidate expire the cached addresses of host, so that it's re-resolved on next dialing
+func (r *Resolver) Invalidate(host string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cached, ok := r.entries[host]; ok {
+		cached.expireAt = time.Time{}
+	}
+}
+
+// DialContext dial the addresses of the host in address in turn, it's used as the dialer of http transport
+// and grpc. The host is invalidated when all the addresses fail unless KeepOnDialError is set
+func (r *Resolver) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := r.Resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var lastErr error
+	for _, addr := range addrs {
+		conn, err := r.dialer.DialContext(ctx, network, net.JoinHostPort(addr, port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if !r.keepOnDialError {
+		r.Invalidate(host)
+	}
+	return nil, lastErr
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/clock"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+)
+
+type fakeLookup struct {
+	addrs []string
+	err   error
+	calls int
+}
+
+func (f *fakeLookup) lookupHost(ctx context.Context, host string) ([]string, error) {
+	f.calls++
+	return f.addrs, f.err
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	clk := clock.NewFakeClock(time.Unix(0, 0))
+	lookup := &fakeLookup{addrs: []string{"10.0.0.2", "10.0.0.1"}}
+	r := NewResolver(constant.DnsCacheConfig{TTL: time.Minute}, clk)
+	r.lookup = lookup.lookupHost
+	var changed []string
+	r.OnChange(func(host string) {
+		changed = append(changed, host)
+	})
+
+	addrs, err := r.Resolve(context.Background(), "nacos.local")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, addrs)
+	_, _ = r.Resolve(context.Background(), "nacos.local")
+	assert.Equal(t, 1, lookup.calls)
+
+	// the ip is not resolved
+	addrs, _ = r.Resolve(context.Background(), "127.0.0.1")
+	assert.Equal(t, []string{"127.0.0.1"}, addrs)
+	assert.Equal(t, 1, lookup.calls)
+
+	// the stale addresses are used when re-resolving fails
+	clk.Advance(2 * time.Minute)
+	lookup.err = errors.New("dns down")
+	addrs, err = r.Resolve(context.Background(), "nacos.local")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, addrs)
+	assert.Equal(t, 2, lookup.calls)
+
+	lookup.err = nil
+	lookup.addrs = []string{"10.0.0.3"}
+	addrs, _ = r.Resolve(context.Background(), "nacos.local")
+	assert.Equal(t, []string{"10.0.0.3"}, addrs)
+	assert.Equal(t, []string{"nacos.local"}, changed)
+
+	_, err = r.Resolve(context.Background(), "unknown.local")
+	assert.Nil(t, err)
+	lookup.addrs = nil
+	_, err = r.Resolve(context.Background(), "empty.local")
+	assert.NotNil(t, err)
+}
+
+func TestResolver_DialContext(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	lookup := &fakeLookup{addrs: []string{"127.0.0.1"}}
+	r := NewResolver(constant.DnsCacheConfig{TTL: time.Hour}, nil)
+	r.lookup = lookup.lookupHost
+
+	conn, err := r.DialContext(context.Background(), "tcp", net.JoinHostPort("nacos.local", port))
+	assert.Nil(t, err)
+	conn.Close()
+	assert.Equal(t, 1, lookup.calls)
+
+	// the host is re-resolved after dialing fails even within ttl
+	listener.Close()
+	_, err = r.DialContext(context.Background(), "tcp", net.JoinHostPort("nacos.local", port))
+	assert.NotNil(t, err)
+	_, _ = r.Resolve(context.Background(), "nacos.local")
+	assert.Equal(t, 2, lookup.calls)
+}
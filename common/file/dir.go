@@ -0,0 +1,89 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	DirReasonCreate      = "create"
+	DirReasonNotDir      = "not a directory"
+	DirReasonNotWritable = "not writable"
+)
+
+// DirError describes why a dir can not be used by the client, it's logged as a warning on startup
+// instead of failing later with an opaque error when a snapshot or a log is written
+type DirError struct {
+	Dir    string
+	Reason string
+	Err    error
+}
+
+func (e *DirError) Error() string {
+	return fmt.Sprintf("dir unusable, dir=%s, reason=%s, err=%v", e.Dir, e.Reason, e.Err)
+}
+
+func (e *DirError) Unwrap() error {
+	return e.Err
+}
+
+// PrepareDir make sure dir is a writable directory, a missing dir is created and a file occupying the path
+// is moved aside as <dir>.corrupt-<unix nano> before creating the dir
+func PrepareDir(dir string) error {
+	if len(dir) == 0 {
+		return nil
+	}
+	info, err := os.Stat(dir)
+	if err == nil && !info.IsDir() {
+		aside := dir + ".corrupt-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+		if err = os.Rename(dir, aside); err != nil {
+			return &DirError{Dir: dir, Reason: DirReasonNotDir, Err: err}
+		}
+		err = os.ErrNotExist
+	}
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return &DirError{Dir: dir, Reason: DirReasonCreate, Err: err}
+		}
+		if err = MkdirIfNecessary(dir); err != nil {
+			return &DirError{Dir: dir, Reason: DirReasonCreate, Err: err}
+		}
+	}
+	probe, err := ioutil.TempFile(dir, ".probe")
+	if err != nil {
+		return &DirError{Dir: dir, Reason: DirReasonNotWritable, Err: err}
+	}
+	_ = probe.Close()
+	_ = os.Remove(probe.Name())
+	return nil
+}
+
+// PrepareDirs prepare each of dirs, the dirs that can't be used are returned as *DirError
+func PrepareDirs(dirs ...string) []error {
+	var errs []error
+	for _, dir := range dirs {
+		if err := PrepareDir(dir); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
@@ -17,6 +17,7 @@
 package file
 
 import (
+	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
@@ -55,7 +56,10 @@ func MkdirIfNecessary(createDir string) (err error) {
 			d = dir + path + strings.Join(s[startIndex:i+1], path)
 		}
 		if _, e := os.Stat(d); os.IsNotExist(e) {
-			err = os.Mkdir(d, os.ModePerm) //在当前目录下生成md目录
+			err = os.Mkdir(d, DirMode()) //在当前目录下生成md目录
+			if err == nil {
+				err = ApplyPerm(d, DirMode())
+			}
 			if err != nil {
 				break
 			}
@@ -87,3 +91,34 @@ func IsExistFile(filePath string) bool {
 	}
 	return false
 }
+
+// WriteFileAtomic write the content to a temporary file in the same directory and rename it to filePath,
+// so the readers never see a partial file
+func WriteFileAtomic(filePath string, content []byte, perm os.FileMode) error {
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(filePath)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), perm); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = os.Rename(tmp.Name(), filePath); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
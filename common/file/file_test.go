@@ -17,7 +17,9 @@
 package file
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -28,3 +30,67 @@ func TestMkdirIfNecessaryForAbsPath(t *testing.T) {
 	err := MkdirIfNecessary(path)
 	assert.Nil(t, err)
 }
+
+func TestWriteFileAtomic(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "conf.d", "nginx.conf")
+	assert.Nil(t, WriteFileAtomic(filePath, []byte("worker_processes 1;"), 0600))
+	assert.Nil(t, WriteFileAtomic(filePath, []byte("worker_processes 2;"), 0600))
+	content, err := ioutil.ReadFile(filePath)
+	assert.Nil(t, err)
+	assert.Equal(t, "worker_processes 2;", string(content))
+	files, _ := ioutil.ReadDir(filepath.Dir(filePath))
+	assert.Len(t, files, 1)
+}
+
+func TestPrepareDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	assert.Nil(t, PrepareDir(dir))
+	info, err := os.Stat(dir)
+	assert.Nil(t, err)
+	assert.True(t, info.IsDir())
+
+	occupied := filepath.Join(t.TempDir(), "log")
+	assert.Nil(t, ioutil.WriteFile(occupied, []byte("x"), 0644))
+	assert.Nil(t, PrepareDir(occupied))
+	info, err = os.Stat(occupied)
+	assert.Nil(t, err)
+	assert.True(t, info.IsDir())
+	matches, _ := filepath.Glob(occupied + ".corrupt-*")
+	assert.Len(t, matches, 1)
+
+	parent := t.TempDir()
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(parent, "file"), []byte("x"), 0644))
+	errs := PrepareDirs(filepath.Join(parent, "file", "cache"), "")
+	assert.Len(t, errs, 1)
+	assert.Equal(t, DirReasonCreate, errs[0].(*DirError).Reason)
+}
+
+func TestPermConfig(t *testing.T) {
+	defer SetPermConfig(nil)
+	assert.Equal(t, DefaultDirMode, DirMode())
+	assert.Equal(t, DefaultFileMode, FileMode(false))
+	assert.Equal(t, DefaultSecretFileMode, FileMode(true))
+
+	SetPermConfig(&PermConfig{DirMode: 0770, FileMode: 0660, IgnoreUmask: true})
+	dir := filepath.Join(t.TempDir(), "cache", "naming")
+	assert.Nil(t, MkdirIfNecessary(dir))
+	info, err := os.Stat(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0770), info.Mode().Perm())
+
+	fileName := filepath.Join(dir, "snapshot")
+	assert.Nil(t, WriteFile(fileName, []byte("content"), FileMode(false)))
+	info, err = os.Stat(fileName)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0660), info.Mode().Perm())
+	assert.Equal(t, DefaultSecretFileMode, FileMode(true))
+
+	logFile := filepath.Join(dir, "nacos-sdk.log")
+	assert.Nil(t, TouchFile(logFile, FileMode(false)))
+	assert.Nil(t, ioutil.WriteFile(logFile, []byte("log"), 0600))
+	assert.Nil(t, TouchFile(logFile, FileMode(false)))
+	info, err = os.Stat(logFile)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0660), info.Mode().Perm())
+	assert.Equal(t, int64(3), info.Size())
+}
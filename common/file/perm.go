@@ -0,0 +1,125 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+const (
+	DefaultDirMode        os.FileMode = 0755
+	DefaultFileMode       os.FileMode = 0644
+	DefaultSecretFileMode os.FileMode = 0600
+)
+
+// PermConfig is the permission of the dirs and files written by the client, such as the snapshots and logs
+type PermConfig struct {
+	DirMode        os.FileMode // the mode of created dirs, default value is 0755
+	FileMode       os.FileMode // the mode of snapshots and logs, default value is 0644
+	SecretFileMode os.FileMode // the mode of snapshots of encrypted configs whose dataId starts with cipher- and the config history, default value is 0600
+	IgnoreUmask    bool        // chmod the created dirs and files, so the modes are kept exactly instead of being masked by the umask of process
+	Owner          *Owner      // chown the created dirs and files, default is nil means owned by the user of process
+}
+
+// Owner is the uid and gid of the created dirs and files, -1 keeps the one of process
+type Owner struct {
+	Uid int
+	Gid int
+}
+
+var (
+	permMux sync.RWMutex
+	perm    = PermConfig{}
+)
+
+// SetPermConfig set the permission of the dirs and files written afterwards, it's process wide
+func SetPermConfig(cfg *PermConfig) {
+	permMux.Lock()
+	defer permMux.Unlock()
+	if cfg == nil {
+		perm = PermConfig{}
+		return
+	}
+	perm = *cfg
+}
+
+func getPermConfig() PermConfig {
+	permMux.RLock()
+	defer permMux.RUnlock()
+	return perm
+}
+
+// DirMode is the mode of created dirs
+func DirMode() os.FileMode {
+	if mode := getPermConfig().DirMode; mode != 0 {
+		return mode
+	}
+	return DefaultDirMode
+}
+
+// FileMode is the mode of created files, secret is true for the snapshots of encrypted configs
+func FileMode(secret bool) os.FileMode {
+	cfg := getPermConfig()
+	if secret {
+		if cfg.SecretFileMode != 0 {
+			return cfg.SecretFileMode
+		}
+		return DefaultSecretFileMode
+	}
+	if cfg.FileMode != 0 {
+		return cfg.FileMode
+	}
+	return DefaultFileMode
+}
+
+// ApplyPerm chmod the created path when umask is ignored and chown it when the owner is set
+func ApplyPerm(path string, mode os.FileMode) error {
+	cfg := getPermConfig()
+	if cfg.IgnoreUmask {
+		if err := os.Chmod(path, mode); err != nil {
+			return err
+		}
+	}
+	if cfg.Owner != nil {
+		return os.Chown(path, cfg.Owner.Uid, cfg.Owner.Gid)
+	}
+	return nil
+}
+
+// WriteFile write the content with the mode and apply the permission, it's like ioutil.WriteFile
+func WriteFile(fileName string, content []byte, mode os.FileMode) error {
+	if err := ioutil.WriteFile(fileName, content, mode); err != nil {
+		return err
+	}
+	return ApplyPerm(fileName, mode)
+}
+
+// TouchFile create the empty file with the mode and apply the permission, the existing file is kept as is.
+// It's used to give the permission to files created by others which open the existing file, such as the log file
+func TouchFile(fileName string, mode os.FileMode) error {
+	f, err := os.OpenFile(fileName, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil
+		}
+		return err
+	}
+	_ = f.Close()
+	return ApplyPerm(fileName, mode)
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http_agent
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/proxy"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+)
+
+// DialFunc dial the address on the network, such as the DialContext of net.Dialer
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// ServerDialer dial the servers through the unix domain socket or SOCKS5 proxy set in their ServerConfig,
+// the other addresses are dialed by next
+type ServerDialer struct {
+	routes map[string]DialFunc // keyed by host:port of server
+	next   DialFunc
+}
+
+// NewServerDialer create the dialer of the servers, nil is returned when none of them sets UnixSocket or Socks5Proxy.
+// The addresses of the other servers are dialed by next, or directly when next is nil
+func NewServerDialer(serverConfigs []constant.ServerConfig, next DialFunc) (*ServerDialer, error) {
+	routes := make(map[string]DialFunc)
+	for i, serverConfig := range serverConfigs {
+		route, err := serverRoute(serverConfig)
+		if err != nil {
+			return nil, errors.Wrapf(err, "[http_agent.NewServerDialer] configs[%d] is invalid", i)
+		}
+		if route != nil {
+			routes[net.JoinHostPort(serverConfig.IpAddr, strconv.FormatUint(serverConfig.Port, 10))] = route
+		}
+	}
+	if len(routes) == 0 {
+		return nil, nil
+	}
+	if next == nil {
+		next = (&net.Dialer{}).DialContext
+	}
+	return &ServerDialer{routes: routes, next: next}, nil
+}
+
+// DialContext dial address through the route of its server, it's used as the dialer of http transport
+func (d *ServerDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if route, ok := d.routes[address]; ok {
+		return route(ctx, network, address)
+	}
+	return d.next(ctx, network, address)
+}
+
+// proxy wrap the proxy of http transport, the http proxy is bypassed for the servers with route
+func (d *ServerDialer) proxy(next func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if _, ok := d.routes[canonicalAddr(req.URL)]; ok || next == nil {
+			return nil, nil
+		}
+		return next(req)
+	}
+}
+
+// canonicalAddr return the host:port dialed for u, the port is the default one of scheme when it's absent
+func canonicalAddr(u *url.URL) string {
+	port := u.Port()
+	if len(port) == 0 {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+func serverRoute(serverConfig constant.ServerConfig) (DialFunc, error) {
+	if len(serverConfig.UnixSocket) > 0 && len(serverConfig.Socks5Proxy) > 0 {
+		return nil, errors.New("UnixSocket and Socks5Proxy can not be set together")
+	}
+	if len(serverConfig.UnixSocket) > 0 {
+		socketPath := serverConfig.UnixSocket
+		dialer := net.Dialer{}
+		return func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}, nil
+	}
+	if len(serverConfig.Socks5Proxy) > 0 {
+		return socks5Route(serverConfig.Socks5Proxy)
+	}
+	return nil, nil
+}
+
+func socks5Route(proxyUrl string) (DialFunc, error) {
+	u, err := url.Parse(proxyUrl)
+	if err != nil {
+		// the url is not in the error, it may carry the password
+		return nil, errors.New("parse Socks5Proxy failed")
+	}
+	if u.Scheme != "socks5" && u.Scheme != "socks5h" {
+		return nil, errors.Errorf("scheme of Socks5Proxy %s is not socks5", u.Redacted())
+	}
+	var auth *proxy.Auth
+	if u.User != nil {
+		auth = &proxy.Auth{User: u.User.Username()}
+		auth.Password, _ = u.User.Password()
+	}
+	dialer, err := proxy.SOCKS5("tcp", u.Host, auth, proxy.Direct)
+	if err != nil {
+		return nil, errors.Wrapf(err, "create dialer of Socks5Proxy %s failed", u.Redacted())
+	}
+	return dialer.(proxy.ContextDialer).DialContext, nil
+}
@@ -19,8 +19,11 @@ package http_agent
 import (
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"sync"
 
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/dns"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/tls"
 
 	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
@@ -30,6 +33,13 @@ import (
 
 type HttpAgent struct {
 	TlsConfig constant.TLSConfig
+	Cache     *ResponseCache // revalidate the cached GET responses instead of transferring them again, optional
+	Resolver  *dns.Resolver  // resolve the server hostnames by the dns cache, optional
+	Dialer    *ServerDialer  // dial the servers through unix domain socket or SOCKS5 proxy, optional
+
+	transportOnce sync.Once
+	transport     *http.Transport
+	transportErr  error
 }
 
 func (agent *HttpAgent) Get(path string, header http.Header, timeoutMs uint64,
@@ -38,7 +48,13 @@ func (agent *HttpAgent) Get(path string, header http.Header, timeoutMs uint64,
 	if err != nil {
 		return nil, err
 	}
-	return get(client, path, header, timeoutMs, params)
+	if agent.Cache != nil {
+		response, err = agent.Cache.get(client, path, header, timeoutMs, params)
+	} else {
+		response, err = get(client, path, header, timeoutMs, params)
+	}
+	agent.afterRequest(path, err)
+	return
 }
 
 func (agent *HttpAgent) RequestOnlyResult(method string, path string, header http.Header, timeoutMs uint64, params map[string]string) string {
@@ -104,7 +120,9 @@ func (agent *HttpAgent) Post(path string, header http.Header, timeoutMs uint64,
 	if err != nil {
 		return nil, err
 	}
-	return post(client, path, header, timeoutMs, params)
+	response, err = post(client, path, header, timeoutMs, params)
+	agent.afterRequest(path, err)
+	return
 }
 func (agent *HttpAgent) Delete(path string, header http.Header, timeoutMs uint64,
 	params map[string]string) (response *http.Response, err error) {
@@ -112,7 +130,9 @@ func (agent *HttpAgent) Delete(path string, header http.Header, timeoutMs uint64
 	if err != nil {
 		return nil, err
 	}
-	return delete(client, path, header, timeoutMs, params)
+	response, err = del(client, path, header, timeoutMs, params)
+	agent.afterRequest(path, err)
+	return
 }
 func (agent *HttpAgent) Put(path string, header http.Header, timeoutMs uint64,
 	params map[string]string) (response *http.Response, err error) {
@@ -120,10 +140,15 @@ func (agent *HttpAgent) Put(path string, header http.Header, timeoutMs uint64,
 	if err != nil {
 		return nil, err
 	}
-	return put(client, path, header, timeoutMs, params)
+	response, err = put(client, path, header, timeoutMs, params)
+	agent.afterRequest(path, err)
+	return
 }
 
 func (agent *HttpAgent) createClient() (*http.Client, error) {
+	if agent.Resolver != nil || agent.Dialer != nil {
+		return agent.sharedClient()
+	}
 	if !agent.TlsConfig.Enable {
 		return &http.Client{}, nil
 	}
@@ -134,3 +159,46 @@ func (agent *HttpAgent) createClient() (*http.Client, error) {
 	return &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}, nil
 
 }
+
+// sharedClient returns the client dialing by Dialer or Resolver, the transport is shared so that its idle connections
+// can be closed after the addresses of server change
+func (agent *HttpAgent) sharedClient() (*http.Client, error) {
+	agent.transportOnce.Do(func() {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if agent.Dialer != nil {
+			transport.Proxy = agent.Dialer.proxy(transport.Proxy)
+			transport.DialContext = agent.Dialer.DialContext
+		} else {
+			transport.DialContext = agent.Resolver.DialContext
+		}
+		if agent.TlsConfig.Enable {
+			cfg, err := tls.NewTLS(agent.TlsConfig)
+			if err != nil {
+				agent.transportErr = err
+				return
+			}
+			transport.TLSClientConfig = cfg
+		}
+		if agent.Resolver != nil {
+			agent.Resolver.OnChange(func(string) {
+				transport.CloseIdleConnections()
+			})
+		}
+		agent.transport = transport
+	})
+	if agent.transportErr != nil {
+		return nil, agent.transportErr
+	}
+	return &http.Client{Transport: agent.transport}, nil
+}
+
+// afterRequest re-resolve the host of the failed request, the kept-alive connections may be pinned to a dead address
+func (agent *HttpAgent) afterRequest(path string, err error) {
+	if err == nil || agent.Resolver == nil || agent.transport == nil {
+		return
+	}
+	if u, parseErr := url.Parse(path); parseErr == nil {
+		agent.Resolver.Invalidate(u.Hostname())
+	}
+	agent.transport.CloseIdleConnections()
+}
@@ -0,0 +1,202 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http_agent
+
+import (
+	"bytes"
+	"container/list"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+)
+
+// the params changing per request without changing the response, not part of the cache key
+var volatileParams = map[string]bool{
+	constant.KEY_ACCESS_TOKEN: true,
+	"signature":               true,
+	"data":                    true,
+}
+
+// ResponseCache caches the GET responses carrying ETag, Content-MD5 or Last-Modified, keyed by url and params.
+// The cached response is revalidated with If-None-Match or If-Modified-Since, and served again when
+// the server answers 304, so that the unchanged body is not transferred again
+type ResponseCache struct {
+	mu      sync.Mutex
+	size    int
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type cachedResponse struct {
+	key          string
+	etag         string
+	lastModified string
+	header       http.Header
+	body         []byte
+}
+
+// NewResponseCache create the cache keeping at most size responses, nil is returned when size is not positive
+func NewResponseCache(size int) *ResponseCache {
+	if size <= 0 {
+		return nil
+	}
+	return &ResponseCache{size: size, lru: list.New(), entries: map[string]*list.Element{}}
+}
+
+// Len returns the number of cached responses
+func (c *ResponseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *ResponseCache) get(client *http.Client, path string, header http.Header, timeoutMs uint64,
+	params map[string]string) (*http.Response, error) {
+	key := cacheKey(path, params)
+	cached := c.load(key)
+	if cached != nil {
+		header = cached.revalidate(header)
+	}
+	response, err := get(client, path, header, timeoutMs, params)
+	if err != nil {
+		return nil, err
+	}
+	switch response.StatusCode {
+	case http.StatusNotModified:
+		if cached == nil {
+			return response, nil
+		}
+		response.Body.Close()
+		return cached.response(response.Request), nil
+	case http.StatusOK:
+		entry := &cachedResponse{
+			key:          key,
+			etag:         response.Header.Get("ETag"),
+			lastModified: response.Header.Get("Last-Modified"),
+		}
+		if len(entry.etag) == 0 {
+			// nacos gives the md5 of the config content, which is revalidated as the entity tag
+			if md5 := response.Header.Get("Content-MD5"); len(md5) > 0 {
+				entry.etag = `"` + md5 + `"`
+			}
+		}
+		if len(entry.etag) == 0 && len(entry.lastModified) == 0 {
+			c.remove(key)
+			return response, nil
+		}
+		body, err := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		entry.header = response.Header.Clone()
+		entry.body = body
+		c.store(entry)
+		response.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return response, nil
+	case http.StatusNotFound:
+		c.remove(key)
+	}
+	return response, nil
+}
+
+func (c *ResponseCache) load(key string) *cachedResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*cachedResponse)
+	}
+	return nil
+}
+
+func (c *ResponseCache) store(entry *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[entry.key]; ok {
+		e.Value = entry
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedResponse).key)
+	}
+}
+
+func (c *ResponseCache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.lru.Remove(e)
+		delete(c.entries, key)
+	}
+}
+
+// revalidate returns a copy of header with the conditions of the cached response
+func (r *cachedResponse) revalidate(header http.Header) http.Header {
+	conditional := header.Clone()
+	if conditional == nil {
+		conditional = http.Header{}
+	}
+	if len(r.etag) > 0 {
+		conditional.Set("If-None-Match", r.etag)
+	}
+	if len(r.lastModified) > 0 {
+		conditional.Set("If-Modified-Since", r.lastModified)
+	}
+	return conditional
+}
+
+func (r *cachedResponse) response(request *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(r.body)),
+		ContentLength: int64(len(r.body)),
+		Request:       request,
+	}
+}
+
+func cacheKey(path string, params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		if !volatileParams[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	sb.WriteString(path)
+	for _, k := range keys {
+		sb.WriteString("&")
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(params[k])
+	}
+	return sb.String()
+}
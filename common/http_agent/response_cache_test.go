@@ -0,0 +1,78 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package http_agent
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseCache_Revalidate(t *testing.T) {
+	var full, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		md5 := "md5-" + r.URL.Query().Get("dataId")
+		if r.Header.Get("If-None-Match") == `"`+md5+`"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		w.Header().Set("Content-MD5", md5)
+		_, _ = w.Write([]byte("content of " + r.URL.Query().Get("dataId")))
+	}))
+	defer server.Close()
+
+	agent := &HttpAgent{Cache: NewResponseCache(1)}
+	read := func(dataId, token string) string {
+		response, err := agent.Get(server.URL, http.Header{}, 1000, map[string]string{"dataId": dataId, "accessToken": token})
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		body, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		return string(body)
+	}
+
+	assert.Equal(t, "content of a", read("a", "t1"))
+	assert.Equal(t, "content of a", read("a", "t2"))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&full))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&notModified))
+
+	// a is evicted by b
+	assert.Equal(t, "content of b", read("b", "t2"))
+	assert.Equal(t, "content of a", read("a", "t2"))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&full))
+	assert.Equal(t, 1, agent.Cache.Len())
+}
+
+func TestResponseCache_NoValidator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	agent := &HttpAgent{Cache: NewResponseCache(10)}
+	response, err := agent.Get(server.URL, nil, 1000, nil)
+	assert.Nil(t, err)
+	response.Body.Close()
+	assert.Equal(t, 0, agent.Cache.Len())
+	assert.Nil(t, NewResponseCache(0))
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package introspection
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// ConfigRefresher is a client which can fetch its listened configs from server again
+type ConfigRefresher interface {
+	// RefreshConfigs fetch the listened configs matching dataId and group, empty dataId or group matches all,
+	// the listeners are notified when the content changed. It returns the number of configs refreshed
+	RefreshConfigs(dataId, group string) (int, error)
+}
+
+// ServiceResubscriber is a client which can subscribe its subscribed services again
+type ServiceResubscriber interface {
+	// Resubscribe subscribe the services matching serviceName and groupName from server again,
+	// empty serviceName or groupName matches all. It returns the number of services resubscribed
+	Resubscribe(serviceName, groupName string) (int, error)
+}
+
+// AdminResult is the result of an admin operation on a client
+type AdminResult struct {
+	InstanceId string `json:"instanceId"`
+	Count      int    `json:"count"`
+	Error      string `json:"error,omitempty"`
+}
+
+// AdminHandler return a http handler which allows the host application to operate the clients over its own admin port,
+// it is not mounted by the sdk. The routes are relative to where it is mounted, use http.StripPrefix to mount it under a prefix
+//
+//	GET  /v1/state                                                 dump the snapshot of clients, the same as Handler
+//	POST /v1/configs:refresh?instanceId=&dataId=&group=            fetch the listened configs from server again
+//	POST /v1/services:resubscribe?instanceId=&serviceName=&groupName=  subscribe the subscribed services again
+//
+// The operations are applied to all clients unless instanceId is set, the status is 500 when any client fails
+func AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/v1/state", Handler())
+	mux.Handle("/v1/configs:refresh", adminAction(func(source Source, r *http.Request) (bool, int, error) {
+		refresher, ok := source.(ConfigRefresher)
+		if !ok {
+			return false, 0, nil
+		}
+		count, err := refresher.RefreshConfigs(r.FormValue("dataId"), r.FormValue("group"))
+		return true, count, err
+	}))
+	mux.Handle("/v1/services:resubscribe", adminAction(func(source Source, r *http.Request) (bool, int, error) {
+		resubscriber, ok := source.(ServiceResubscriber)
+		if !ok {
+			return false, 0, nil
+		}
+		count, err := resubscriber.Resubscribe(r.FormValue("serviceName"), r.FormValue("groupName"))
+		return true, count, err
+	}))
+	return mux
+}
+
+// adminAction apply the operation to the registered clients, the clients which do not support it are skipped
+func adminAction(operate func(source Source, r *http.Request) (bool, int, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		instanceId := r.FormValue("instanceId")
+		var targets []Source
+		sources.Range(func(key, value interface{}) bool {
+			if instanceId == "" || key.(string) == instanceId {
+				targets = append(targets, value.(Source))
+			}
+			return true
+		})
+		if instanceId != "" && len(targets) == 0 {
+			http.Error(w, "client instance not found", http.StatusNotFound)
+			return
+		}
+		sort.Slice(targets, func(i, j int) bool {
+			return targets[i].InstanceId() < targets[j].InstanceId()
+		})
+		status := http.StatusOK
+		results := make([]AdminResult, 0, len(targets))
+		for _, source := range targets {
+			handled, count, err := operate(source, r)
+			if !handled {
+				continue
+			}
+			result := AdminResult{InstanceId: source.InstanceId(), Count: count}
+			if err != nil {
+				result.Error = err.Error()
+				status = http.StatusInternalServerError
+			}
+			results = append(results, result)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+	})
+}
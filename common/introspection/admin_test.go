@@ -0,0 +1,77 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package introspection
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRefresher struct {
+	fakeSource
+	dataId string
+	err    error
+}
+
+func (f *fakeRefresher) RefreshConfigs(dataId, group string) (int, error) {
+	f.dataId = dataId
+	return 1, f.err
+}
+
+func TestAdminHandler(t *testing.T) {
+	refresher := &fakeRefresher{fakeSource: fakeSource{id: "admin-config"}}
+	other := &fakeSource{id: "admin-other"}
+	Register(refresher)
+	Register(other)
+	defer Deregister(refresher)
+	defer Deregister(other)
+	handler := AdminHandler()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/configs:refresh", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/configs:refresh?dataId=app.yaml", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Results []AdminResult `json:"results"`
+	}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, []AdminResult{{InstanceId: "admin-config", Count: 1}}, body.Results)
+	assert.Equal(t, "app.yaml", refresher.dataId)
+
+	refresher.err = errors.New("server unavailable")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/configs:refresh?instanceId=admin-config", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "server unavailable", body.Results[0].Error)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/services:resubscribe?instanceId=unknown", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/state?instanceId=admin-other", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package introspection
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const maxRecentErrors = 20
+
+// Source is a client which can dump its runtime state
+type Source interface {
+	// InstanceId return the unique id of the client instance
+	InstanceId() string
+	// Snapshot return the runtime state of the client, it must be json serializable
+	Snapshot() interface{}
+}
+
+// ErrorRecord is an error recorded by the client
+type ErrorRecord struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// ErrorRecorder keep the most recent errors of a client
+type ErrorRecorder struct {
+	mux     sync.Mutex
+	records []ErrorRecord
+}
+
+// Record add the error to recent errors, nil error is ignored
+func (r *ErrorRecorder) Record(err error) {
+	if err == nil {
+		return
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.records = append(r.records, ErrorRecord{Time: time.Now(), Message: err.Error()})
+	if len(r.records) > maxRecentErrors {
+		r.records = r.records[len(r.records)-maxRecentErrors:]
+	}
+}
+
+// Recent return the recent errors, the latest is the last one
+func (r *ErrorRecorder) Recent() []ErrorRecord {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	records := make([]ErrorRecord, len(r.records))
+	copy(records, r.records)
+	return records
+}
+
+var sources sync.Map
+
+// Register add the client to the introspection handler
+func Register(source Source) {
+	sources.Store(source.InstanceId(), source)
+}
+
+// Deregister remove the client from the introspection handler
+func Deregister(source Source) {
+	sources.Delete(source.InstanceId())
+}
+
+// Snapshots return the snapshot of all registered clients keyed by instance id
+func Snapshots() map[string]interface{} {
+	snapshots := make(map[string]interface{})
+	sources.Range(func(key, value interface{}) bool {
+		snapshots[key.(string)] = value.(Source).Snapshot()
+		return true
+	})
+	return snapshots
+}
+
+// Handler return a http handler dumping the snapshot of all clients in JSON,
+// it is not mounted by the sdk, the host application decides where to expose it.
+// The query parameter instanceId filter the clients to dump
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		snapshots := Snapshots()
+		if instanceId := r.URL.Query().Get("instanceId"); instanceId != "" {
+			snapshot, ok := snapshots[instanceId]
+			if !ok {
+				http.Error(w, "client instance not found", http.StatusNotFound)
+				return
+			}
+			snapshots = map[string]interface{}{instanceId: snapshot}
+		}
+		ids := make([]string, 0, len(snapshots))
+		for id := range snapshots {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(map[string]interface{}{
+			"time":        time.Now(),
+			"instanceIds": ids,
+			"clients":     snapshots,
+		})
+	})
+}
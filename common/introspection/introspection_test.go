@@ -0,0 +1,78 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package introspection
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSource struct {
+	id       string
+	recorder ErrorRecorder
+}
+
+func (f *fakeSource) InstanceId() string {
+	return f.id
+}
+
+func (f *fakeSource) Snapshot() interface{} {
+	return map[string]interface{}{"recentErrors": f.recorder.Recent()}
+}
+
+func TestErrorRecorder(t *testing.T) {
+	recorder := ErrorRecorder{}
+	recorder.Record(nil)
+	assert.Empty(t, recorder.Recent())
+	for i := 0; i < maxRecentErrors+5; i++ {
+		recorder.Record(errors.New(strconv.Itoa(i)))
+	}
+	records := recorder.Recent()
+	assert.Len(t, records, maxRecentErrors)
+	assert.Equal(t, "5", records[0].Message)
+	assert.Equal(t, strconv.Itoa(maxRecentErrors+4), records[len(records)-1].Message)
+}
+
+func TestHandler(t *testing.T) {
+	source := &fakeSource{id: "test-instance"}
+	source.recorder.Record(errors.New("request failed"))
+	Register(source)
+	defer Deregister(source)
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/nacos?instanceId=test-instance", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		InstanceIds []string `json:"instanceIds"`
+		Clients     map[string]struct {
+			RecentErrors []ErrorRecord `json:"recentErrors"`
+		} `json:"clients"`
+	}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, []string{"test-instance"}, body.InstanceIds)
+	assert.Equal(t, "request failed", body.Clients["test-instance"].RecentErrors[0].Message)
+
+	w = httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/nacos?instanceId=unknown", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,282 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package introspection
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+)
+
+const (
+	stateManifest   = "manifest.json"
+	stateClientsDir = "clients/"
+	redacted        = "******"
+	maxRedactDepth  = 8
+)
+
+// the fields of client config redacted in the dumped state, matched case-insensitively by field name
+var sensitiveFields = []string{"secret", "password", "token", "credential", "privatekey"}
+
+// StateDumper is a client which can dump its full state, including the content of configs and the instances of
+// services, so that the state can be reproduced locally
+type StateDumper interface {
+	Source
+	DumpState() ClientState
+}
+
+// ClientState is the full state of a client in the archive of DumpState
+type ClientState struct {
+	InstanceId    string              `json:"instanceId"`
+	Type          string              `json:"type"`
+	NamespaceId   string              `json:"namespaceId"`
+	ClientConfig  interface{}         `json:"clientConfig,omitempty"` // redacted by RedactConfig
+	Snapshot      json.RawMessage     `json:"snapshot,omitempty"`
+	Configs       []ConfigState       `json:"configs,omitempty"`
+	Services      []model.Service     `json:"services,omitempty"`
+	Subscriptions []SubscriptionState `json:"subscriptions,omitempty"`
+}
+
+// ConfigState is a listened config of client
+type ConfigState struct {
+	DataId        string `json:"dataId"`
+	Group         string `json:"group"`
+	Tenant        string `json:"tenant"`
+	Md5           string `json:"md5"`
+	ContentType   string `json:"contentType"`
+	Content       string `json:"content"`
+	ListenerCount int    `json:"listenerCount"`
+}
+
+// SubscriptionState is a subscribed service of client
+type SubscriptionState struct {
+	ServiceName string   `json:"serviceName"`
+	GroupName   string   `json:"groupName"`
+	Clusters    []string `json:"clusters,omitempty"`
+}
+
+// State is the state of clients loaded by LoadState
+type State struct {
+	Time    time.Time     `json:"time"`
+	Clients []ClientState `json:"-"`
+}
+
+// DumpState write the state of all registered clients into a single gzipped tar archive at path, for the support
+// to reproduce it locally. The clients which are not StateDumper are dumped by their snapshot only
+func DumpState(path string) (err error) {
+	var states []ClientState
+	sources.Range(func(key, value interface{}) bool {
+		source := value.(Source)
+		state := ClientState{InstanceId: source.InstanceId()}
+		if dumper, ok := source.(StateDumper); ok {
+			state = dumper.DumpState()
+		}
+		if len(state.Snapshot) == 0 {
+			state.Snapshot, _ = json.Marshal(source.Snapshot())
+		}
+		states = append(states, state)
+		return true
+	})
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].InstanceId < states[j].InstanceId
+	})
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "[introspection.DumpState] create %s failed", path)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil && closeErr != nil {
+			err = closeErr
+		}
+	}()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	if err = writeTarJson(tw, stateManifest, State{Time: time.Now()}); err != nil {
+		return err
+	}
+	for _, state := range states {
+		if err = writeTarJson(tw, stateClientsDir+state.InstanceId+".json", state); err != nil {
+			return err
+		}
+	}
+	if err = tw.Close(); err != nil {
+		return errors.Wrap(err, "[introspection.DumpState] close archive failed")
+	}
+	return gw.Close()
+}
+
+// LoadState read the archive written by DumpState
+func LoadState(path string) (*State, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[introspection.LoadState] open %s failed", path)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[introspection.LoadState] %s is not a state archive", path)
+	}
+	state := &State{}
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "[introspection.LoadState] read archive failed")
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "[introspection.LoadState] read %s failed", header.Name)
+		}
+		switch {
+		case header.Name == stateManifest:
+			err = json.Unmarshal(data, state)
+		case strings.HasPrefix(header.Name, stateClientsDir):
+			var client ClientState
+			if err = json.Unmarshal(data, &client); err == nil {
+				state.Clients = append(state.Clients, client)
+			}
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "[introspection.LoadState] decode %s failed", header.Name)
+		}
+	}
+	return state, nil
+}
+
+// WriteCache write the configs and services of the loaded state into cacheDir in the layout of the snapshots of clients,
+// so that the clients created with the cacheDir start from the state, such as when the server is unreachable
+func (s *State) WriteCache(cacheDir string) error {
+	for _, client := range s.Clients {
+		for _, config := range client.Configs {
+			dir := filepath.Join(cacheDir, "config")
+			if err := writeCacheFile(dir, util.GetConfigCacheKey(config.DataId, config.Group, config.Tenant), []byte(config.Content)); err != nil {
+				return err
+			}
+		}
+		for _, service := range client.Services {
+			dir := filepath.Join(cacheDir, "naming", client.NamespaceId)
+			data, err := json.Marshal(service)
+			if err != nil {
+				return errors.Wrapf(err, "[introspection.WriteCache] encode service %s failed", service.Name)
+			}
+			key := util.GetServiceCacheKey(util.GetGroupName(service.Name, service.GroupName), service.Clusters)
+			if err = writeCacheFile(dir, key, data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeCacheFile(dir, name string, data []byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrapf(err, "[introspection.WriteCache] mkdir %s failed", dir)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		return errors.Wrapf(err, "[introspection.WriteCache] write %s failed", name)
+	}
+	return nil
+}
+
+func writeTarJson(tw *tar.Writer, name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "[introspection.DumpState] encode %s failed", name)
+	}
+	header := &tar.Header{Name: path.Clean(name), Mode: 0600, Size: int64(len(data)), ModTime: time.Now()}
+	if err = tw.WriteHeader(header); err != nil {
+		return errors.Wrapf(err, "[introspection.DumpState] write %s failed", name)
+	}
+	_, err = tw.Write(data)
+	return errors.Wrapf(err, "[introspection.DumpState] write %s failed", name)
+}
+
+// RedactConfig convert the client config into a json serializable value, the fields of secrets are redacted, and
+// the functions and channels are dropped
+func RedactConfig(config interface{}) interface{} {
+	v, _ := redactValue(reflect.ValueOf(config), 0)
+	return v
+}
+
+func redactValue(v reflect.Value, depth int) (interface{}, bool) {
+	if !v.IsValid() || depth > maxRedactDepth {
+		return nil, false
+	}
+	switch v.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil, false
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, false
+		}
+		return redactValue(v.Elem(), depth+1)
+	case reflect.Struct:
+		if t, ok := v.Interface().(time.Time); ok {
+			return t, true
+		}
+		fields := make(map[string]interface{})
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			if isSensitive(field.Name) {
+				if !v.Field(i).IsZero() {
+					fields[field.Name] = redacted
+				}
+				continue
+			}
+			if value, ok := redactValue(v.Field(i), depth+1); ok {
+				fields[field.Name] = value
+			}
+		}
+		return fields, true
+	case reflect.Map, reflect.Slice, reflect.Array:
+		if _, err := json.Marshal(v.Interface()); err != nil {
+			return nil, false
+		}
+	}
+	return v.Interface(), true
+}
+
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range sensitiveFields {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package introspection
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
+type fakeDumper struct {
+	fakeSource
+}
+
+type fakeConfig struct {
+	Endpoint  string
+	SecretKey string
+	Password  string
+	OnChange  func()
+	Nested    *struct{ AccessToken string }
+}
+
+func (f *fakeDumper) DumpState() ClientState {
+	return ClientState{
+		InstanceId:   f.id,
+		Type:         "naming",
+		NamespaceId:  "public",
+		ClientConfig: RedactConfig(fakeConfig{Endpoint: "nacos:8848", SecretKey: "sk", OnChange: func() {}, Nested: &struct{ AccessToken string }{"token"}}),
+		Configs:      []ConfigState{{DataId: "app.yaml", Group: "DEFAULT_GROUP", Content: "a: 1"}},
+		Services:     []model.Service{{Name: "svc", GroupName: "DEFAULT_GROUP", Hosts: []model.Instance{{Ip: "10.0.0.1", Port: 80}}}},
+	}
+}
+
+func TestDumpAndLoadState(t *testing.T) {
+	dumper := &fakeDumper{fakeSource{id: "state-dumper"}}
+	other := &fakeSource{id: "state-other"}
+	Register(dumper)
+	Register(other)
+	defer Deregister(dumper)
+	defer Deregister(other)
+
+	dir, err := ioutil.TempDir("", "nacos-state")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.tar.gz")
+	assert.Nil(t, DumpState(path))
+
+	state, err := LoadState(path)
+	assert.Nil(t, err)
+	assert.False(t, state.Time.IsZero())
+	clients := map[string]ClientState{}
+	for _, client := range state.Clients {
+		clients[client.InstanceId] = client
+	}
+	assert.NotEmpty(t, clients["state-other"].Snapshot)
+	loaded := clients["state-dumper"]
+	assert.NotEmpty(t, loaded.Snapshot)
+	assert.Equal(t, map[string]interface{}{
+		"Endpoint":  "nacos:8848",
+		"SecretKey": redacted,
+		"Nested":    map[string]interface{}{"AccessToken": redacted},
+	}, loaded.ClientConfig)
+	assert.Equal(t, "10.0.0.1", loaded.Services[0].Hosts[0].Ip)
+
+	cacheDir := filepath.Join(dir, "cache")
+	assert.Nil(t, state.WriteCache(cacheDir))
+	content, err := ioutil.ReadFile(filepath.Join(cacheDir, "config", "app.yaml@@DEFAULT_GROUP@@"))
+	assert.Nil(t, err)
+	assert.Equal(t, "a: 1", string(content))
+	_, err = os.Stat(filepath.Join(cacheDir, "naming", "public", "DEFAULT_GROUP@@svc"))
+	assert.Nil(t, err)
+
+	_, err = LoadState(filepath.Join(dir, "missing"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logger
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+)
+
+// LogContext is the tenant a log line is about, it's added to the line as the fields namespace, group, dataId and
+// serviceName, so the lines of a client shared by tenants can be filtered by them
+type LogContext struct {
+	Namespace   string
+	Group       string
+	DataId      string
+	ServiceName string
+}
+
+// ConfigContext is the context of the lines about a config
+func ConfigContext(namespace, group, dataId string) LogContext {
+	return LogContext{Namespace: namespace, Group: group, DataId: dataId}
+}
+
+// ServiceContext is the context of the lines about a service
+func ServiceContext(namespace, group, serviceName string) LogContext {
+	return LogContext{Namespace: namespace, Group: group, ServiceName: serviceName}
+}
+
+// keyValues return the fields of the context, the namespace is always present and the others are present when they
+// are not empty
+func (c LogContext) keyValues() []interface{} {
+	namespace := c.Namespace
+	if len(namespace) == 0 {
+		namespace = constant.DEFAULT_NAMESPACE_ID
+	}
+	keyValues := []interface{}{"namespace", namespace}
+	if len(c.Group) > 0 {
+		keyValues = append(keyValues, "group", c.Group)
+	}
+	if len(c.DataId) > 0 {
+		keyValues = append(keyValues, "dataId", c.DataId)
+	}
+	if len(c.ServiceName) > 0 {
+		keyValues = append(keyValues, "serviceName", c.ServiceName)
+	}
+	return keyValues
+}
+
+// With return the logger adding the fields of c to every line. They are structured fields with the default logger,
+// and appended to the message as key=value with the logger set by SetLogger
+func With(c LogContext) Logger {
+	keyValues := c.keyValues()
+	l := GetLogger()
+	if nacosLogger, ok := l.(*NacosLogger); ok {
+		if sugar, ok := nacosLogger.Logger.(*zap.SugaredLogger); ok {
+			// the lines are logged by the caller directly instead of the package functions
+			return &NacosLogger{sugar.Desugar().WithOptions(zap.AddCallerSkip(-1)).Sugar().With(keyValues...)}
+		}
+	}
+	var fields strings.Builder
+	for i := 0; i < len(keyValues); i += 2 {
+		fmt.Fprintf(&fields, " %s=%s", keyValues[i], keyValues[i+1])
+	}
+	return &fieldLogger{Logger: l, fields: fields.String()}
+}
+
+// fieldLogger append the fields to the messages of the Logger
+type fieldLogger struct {
+	Logger
+	fields string
+}
+
+func (l *fieldLogger) Info(args ...interface{}) {
+	l.Logger.Info(fmt.Sprint(args...) + l.fields)
+}
+
+func (l *fieldLogger) Warn(args ...interface{}) {
+	l.Logger.Warn(fmt.Sprint(args...) + l.fields)
+}
+
+func (l *fieldLogger) Error(args ...interface{}) {
+	l.Logger.Error(fmt.Sprint(args...) + l.fields)
+}
+
+func (l *fieldLogger) Debug(args ...interface{}) {
+	l.Logger.Debug(fmt.Sprint(args...) + l.fields)
+}
+
+func (l *fieldLogger) Infof(format string, args ...interface{}) {
+	l.Logger.Info(fmt.Sprintf(format, args...) + l.fields)
+}
+
+func (l *fieldLogger) Warnf(format string, args ...interface{}) {
+	l.Logger.Warn(fmt.Sprintf(format, args...) + l.fields)
+}
+
+func (l *fieldLogger) Errorf(format string, args ...interface{}) {
+	l.Logger.Error(fmt.Sprintf(format, args...) + l.fields)
+}
+
+func (l *fieldLogger) Debugf(format string, args ...interface{}) {
+	l.Logger.Debug(fmt.Sprintf(format, args...) + l.fields)
+}
@@ -18,10 +18,12 @@ package logger
 
 import (
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/file"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -126,6 +128,7 @@ func InitLogger(config Config) (err error) {
 func InitNacosLogger(config Config) (Logger, error) {
 	logLevel := getLogLevel(config.Level)
 	encoder := getEncoder()
+	prepareLogFile(config.LogRollingConfig)
 	writer := config.getLogWriter()
 	if config.AppendToStdout {
 		writer = zapcore.NewMultiWriteSyncer(writer, zapcore.AddSync(os.Stdout))
@@ -157,7 +160,7 @@ func getEncoder() zapcore.EncoderConfig {
 	}
 }
 
-//SetLogger sets logger for sdk
+// SetLogger sets logger for sdk
 func SetLogger(log Logger) {
 	logLock.Lock()
 	defer logLock.Unlock()
@@ -170,6 +173,17 @@ func GetLogger() Logger {
 	return logger
 }
 
+// prepareLogFile create the log file with the permission of file.FileMode, the rotated files copy the mode
+// and owner of it. The failure is left to the writes, which report it on the log writer
+func prepareLogFile(rolling *lumberjack.Logger) {
+	if rolling == nil || len(rolling.Filename) == 0 {
+		return
+	}
+	if file.MkdirIfNecessary(filepath.Dir(rolling.Filename)) == nil {
+		_ = file.TouchFile(rolling.Filename, file.FileMode(false))
+	}
+}
+
 // getLogWriter get Lumberjack writer by LumberjackConfig
 func (c *Config) getLogWriter() zapcore.WriteSyncer {
 	return zapcore.AddSync(c.LogRollingConfig)
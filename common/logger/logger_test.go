@@ -134,3 +134,30 @@ func (m mockLogger) Errorf(fmt string, args ...interface{}) {
 func (m mockLogger) Debugf(fmt string, args ...interface{}) {
 	panic("implement me")
 }
+
+type recordLogger struct {
+	mockLogger
+	lines []string
+}
+
+func (r *recordLogger) Info(args ...interface{}) {
+	r.lines = append(r.lines, args[0].(string))
+}
+
+func (r *recordLogger) Warn(args ...interface{}) {
+	r.lines = append(r.lines, args[0].(string))
+}
+
+func TestWith(t *testing.T) {
+	log := &recordLogger{}
+	SetLogger(log)
+	defer reset()
+
+	With(ConfigContext("tenant-a", "DEFAULT_GROUP", "app.yaml")).Infof("config changed, md5:%s", "abc")
+	With(ServiceContext("", "DEFAULT_GROUP", "demo")).Warn("service changed")
+
+	assert.Equal(t, []string{
+		"config changed, md5:abc namespace=tenant-a group=DEFAULT_GROUP dataId=app.yaml",
+		"service changed namespace=public group=DEFAULT_GROUP serviceName=demo",
+	}, log.lines)
+}
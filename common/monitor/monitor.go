@@ -27,11 +27,16 @@ var (
 		Name: "nacos_client_request",
 		Help: "nacos_client_request",
 	}, []string{"module", "method", "url", "code"})
+	listenerLagMonitorVec = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Name:       "nacos_config_listener_lag_seconds",
+		Help:       "the time between the config changed on server and the listener completed",
+		Objectives: map[float64]float64{0.5: 0.05, 0.99: 0.001},
+	}, []string{"tenant", "group", "dataId"})
 )
 
 // register collectors vec
 func init() {
-	prometheus.MustRegister(gaugeMonitorVec, histogramMonitorVec)
+	prometheus.MustRegister(gaugeMonitorVec, histogramMonitorVec, listenerLagMonitorVec)
 }
 
 // get gauge with labels and use gaugeMonitorVec
@@ -51,6 +56,21 @@ func GetListenConfigCountMonitor() prometheus.Gauge {
 	return GetGaugeWithLabels("listenConfig", "listenConfigCount")
 }
 
+// GetCertExpiryMonitor return the gauge of the expiry unix time of the certificate loaded from config
+func GetCertExpiryMonitor(dataId string) prometheus.Gauge {
+	return GetGaugeWithLabels("certExpiry", dataId)
+}
+
+// GetListenStalenessMonitor return the gauge of the seconds since the last successful listen of the namespace
+func GetListenStalenessMonitor(tenant string) prometheus.Gauge {
+	return GetGaugeWithLabels("listenStaleness", tenant)
+}
+
+// GetListenerLagMonitor return the summary of the listener lag of the config, the p50 and p99 are exposed
+func GetListenerLagMonitor(tenant, group, dataId string) prometheus.Observer {
+	return listenerLagMonitorVec.WithLabelValues(tenant, group, dataId)
+}
+
 // get histogram with labels and use histogramMonitorVec
 func GetHistogramWithLabels(labels ...string) prometheus.Observer {
 	return histogramMonitorVec.WithLabelValues(labels...)
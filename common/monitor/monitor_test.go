@@ -37,3 +37,10 @@ func TestHistorgam(t *testing.T) {
 		assert.NotNil(t, monitor)
 	})
 }
+
+func TestListenerLag(t *testing.T) {
+	monitor := GetListenerLagMonitor("tenant", "group", "dataId")
+	assert.NotNil(t, monitor)
+	monitor.Observe(0.1)
+	assert.NotNil(t, GetListenStalenessMonitor("tenant"))
+}
@@ -52,3 +52,7 @@ func (err *NacosError) ErrorCode() string {
 		return err.errorCode
 	}
 }
+
+func (err *NacosError) ErrMsg() string {
+	return err.errMsg
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos_error
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	ActionRead  = "read"
+	ActionWrite = "write"
+)
+
+// PermissionDeniedError is returned when the server rejects a request with 403,
+// it carries the denied resource so that access requests can be filed automatically.
+type PermissionDeniedError struct {
+	Namespace string
+	Group     string
+	DataId    string
+	Action    string
+	Identity  string
+	Message   string
+}
+
+func (err *PermissionDeniedError) Error() string {
+	return fmt.Sprintf("[403] permission denied, identity=%s, action=%s, namespace=%s, group=%s, dataId=%s, message=%s",
+		err.Identity, err.Action, err.Namespace, err.Group, err.DataId, err.Message)
+}
+
+// permissionPayload is the resource/action payload returned along with 403,
+// resource may be an object or a string in the form of namespace:group:type/name.
+type permissionPayload struct {
+	Resource  json.RawMessage `json:"resource"`
+	Action    string          `json:"action"`
+	Identity  string          `json:"identity"`
+	Username  string          `json:"username"`
+	Namespace string          `json:"namespaceId"`
+	Group     string          `json:"group"`
+	DataId    string          `json:"dataId"`
+	Message   string          `json:"message"`
+}
+
+type permissionResource struct {
+	Namespace string `json:"namespaceId"`
+	Group     string `json:"group"`
+	Name      string `json:"name"`
+}
+
+// NewPermissionDeniedError parses the 403 message returned by server, the fields missing
+// from the message are taken from the given request info.
+func NewPermissionDeniedError(message string, request PermissionDeniedError) *PermissionDeniedError {
+	err := request
+	err.Message = message
+	var payload permissionPayload
+	if json.Unmarshal([]byte(message), &payload) != nil {
+		return &err
+	}
+	if len(payload.Resource) > 0 {
+		var resource permissionResource
+		var resourceStr string
+		if json.Unmarshal(payload.Resource, &resourceStr) == nil {
+			resource = parseResource(resourceStr)
+		} else {
+			_ = json.Unmarshal(payload.Resource, &resource)
+		}
+		payload.Namespace = firstNonEmpty(payload.Namespace, resource.Namespace)
+		payload.Group = firstNonEmpty(payload.Group, resource.Group)
+		payload.DataId = firstNonEmpty(payload.DataId, resource.Name)
+	}
+	err.Namespace = firstNonEmpty(payload.Namespace, err.Namespace)
+	err.Group = firstNonEmpty(payload.Group, err.Group)
+	err.DataId = firstNonEmpty(payload.DataId, err.DataId)
+	err.Action = firstNonEmpty(normalizeAction(payload.Action), err.Action)
+	err.Identity = firstNonEmpty(payload.Identity, payload.Username, err.Identity)
+	err.Message = firstNonEmpty(payload.Message, message)
+	return &err
+}
+
+func parseResource(resource string) permissionResource {
+	parts := strings.SplitN(resource, ":", 3)
+	if len(parts) != 3 {
+		return permissionResource{}
+	}
+	name := parts[2]
+	if idx := strings.Index(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return permissionResource{Namespace: parts[0], Group: parts[1], Name: name}
+}
+
+func normalizeAction(action string) string {
+	switch strings.ToLower(action) {
+	case "r", ActionRead:
+		return ActionRead
+	case "w", ActionWrite:
+		return ActionWrite
+	}
+	return action
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
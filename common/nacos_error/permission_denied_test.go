@@ -0,0 +1,59 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos_error
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var deniedRequest = PermissionDeniedError{
+	Namespace: "ns",
+	Group:     "DEFAULT_GROUP",
+	DataId:    "app.yaml",
+	Action:    ActionRead,
+	Identity:  "nacos",
+}
+
+func TestNewPermissionDeniedError_ResourceString(t *testing.T) {
+	err := NewPermissionDeniedError(`{"resource":"prod:PAY_GROUP:config/pay.yaml","action":"w","identity":"spiffe://example.org/pay","message":"no right"}`, deniedRequest)
+	assert.Equal(t, "prod", err.Namespace)
+	assert.Equal(t, "PAY_GROUP", err.Group)
+	assert.Equal(t, "pay.yaml", err.DataId)
+	assert.Equal(t, ActionWrite, err.Action)
+	assert.Equal(t, "spiffe://example.org/pay", err.Identity)
+	assert.Equal(t, "no right", err.Message)
+}
+
+func TestNewPermissionDeniedError_ResourceObject(t *testing.T) {
+	err := NewPermissionDeniedError(`{"resource":{"namespaceId":"prod","group":"PAY_GROUP","name":"pay.yaml"},"action":"read","username":"pay"}`, deniedRequest)
+	assert.Equal(t, "prod", err.Namespace)
+	assert.Equal(t, "PAY_GROUP", err.Group)
+	assert.Equal(t, "pay.yaml", err.DataId)
+	assert.Equal(t, ActionRead, err.Action)
+	assert.Equal(t, "pay", err.Identity)
+}
+
+func TestNewPermissionDeniedError_PlainMessage(t *testing.T) {
+	err := NewPermissionDeniedError("authorization failed!", deniedRequest)
+	assert.Equal(t, "ns", err.Namespace)
+	assert.Equal(t, "app.yaml", err.DataId)
+	assert.Equal(t, "nacos", err.Identity)
+	assert.Equal(t, "authorization failed!", err.Message)
+	assert.Contains(t, err.Error(), "dataId=app.yaml")
+}
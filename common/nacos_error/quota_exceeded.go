@@ -0,0 +1,41 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos_error
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// QuotaExceededError is returned when a client-side quota is exceeded, the request is not sent to server
+type QuotaExceededError struct {
+	Quota string
+	Limit int
+	Used  int
+	Key   string
+}
+
+func (err *QuotaExceededError) Error() string {
+	return fmt.Sprintf("client quota %s exceeded, limit=%d, used=%d, key=%s", err.Quota, err.Limit, err.Used, err.Key)
+}
+
+// IsQuotaExceeded return the QuotaExceededError that causes err
+func IsQuotaExceeded(err error) (*QuotaExceededError, bool) {
+	exceeded, ok := errors.Cause(err).(*QuotaExceededError)
+	return exceeded, ok
+}
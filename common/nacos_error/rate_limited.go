@@ -0,0 +1,69 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos_error
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RateLimitedError is returned when the server throttles a request with 429,
+// RetryAfter is the delay the server asks for, it's zero when the server doesn't tell.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+	Message    string
+}
+
+func (err *RateLimitedError) Error() string {
+	return fmt.Sprintf("[429] rate limited, retryAfter=%s, message=%s", err.RetryAfter, err.Message)
+}
+
+// NewRateLimitedError build the error from the headers of 429 response, Retry-After in seconds or http date
+// is preferred, X-RateLimit-Reset in seconds or unix time is used when it's absent
+func NewRateLimitedError(message string, header http.Header, now time.Time) *RateLimitedError {
+	err := &RateLimitedError{Message: message}
+	if v := header.Get("Retry-After"); len(v) > 0 {
+		if seconds, e := strconv.ParseInt(v, 10, 64); e == nil {
+			err.RetryAfter = time.Duration(seconds) * time.Second
+		} else if at, e := http.ParseTime(v); e == nil {
+			err.RetryAfter = at.Sub(now)
+		}
+	} else if v := header.Get("X-RateLimit-Reset"); len(v) > 0 {
+		if seconds, e := strconv.ParseInt(v, 10, 64); e == nil {
+			// the values larger than a year are regarded as unix time
+			if seconds > 365*24*3600 {
+				err.RetryAfter = time.Unix(seconds, 0).Sub(now)
+			} else {
+				err.RetryAfter = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	if err.RetryAfter < 0 {
+		err.RetryAfter = 0
+	}
+	return err
+}
+
+// IsRateLimited return the RateLimitedError that causes err
+func IsRateLimited(err error) (*RateLimitedError, bool) {
+	rateLimited, ok := errors.Cause(err).(*RateLimitedError)
+	return rateLimited, ok
+}
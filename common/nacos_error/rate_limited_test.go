@@ -0,0 +1,55 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos_error
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRateLimitedError(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	err := NewRateLimitedError("too many", http.Header{"Retry-After": []string{"3"}}, now)
+	assert.Equal(t, 3*time.Second, err.RetryAfter)
+	assert.Equal(t, "too many", err.Message)
+
+	err = NewRateLimitedError("", http.Header{"Retry-After": []string{now.Add(5 * time.Second).UTC().Format(http.TimeFormat)}}, now)
+	assert.Equal(t, 5*time.Second, err.RetryAfter)
+
+	err = NewRateLimitedError("", http.Header{"X-Ratelimit-Reset": []string{"1700000007"}}, now)
+	assert.Equal(t, 7*time.Second, err.RetryAfter)
+
+	err = NewRateLimitedError("", http.Header{"X-Ratelimit-Reset": []string{"2"}}, now)
+	assert.Equal(t, 2*time.Second, err.RetryAfter)
+
+	err = NewRateLimitedError("", http.Header{}, now)
+	assert.Equal(t, time.Duration(0), err.RetryAfter)
+}
+
+func TestIsRateLimited(t *testing.T) {
+	rateLimited, ok := IsRateLimited(errors.Wrap(&RateLimitedError{RetryAfter: time.Second}, "search failed"))
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, rateLimited.RetryAfter)
+	_, ok = IsRateLimited(errors.New("other"))
+	assert.False(t, ok)
+	_, ok = IsRateLimited(nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos_error
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// StaleCacheError is returned in strict read mode when the config can't be got from server, instead of the
+// snapshot in cache dir. Age is how long ago the snapshot was written
+type StaleCacheError struct {
+	DataId string
+	Group  string
+	Tenant string
+	Age    time.Duration
+	Err    error // the error of getting config from server
+}
+
+func (err *StaleCacheError) Error() string {
+	return fmt.Sprintf("config dataId=%s, group=%s, namespaceId=%s is only available from a snapshot of %s ago, err:%v",
+		err.DataId, err.Group, err.Tenant, err.Age.Truncate(time.Second), err.Err)
+}
+
+// IsStaleCache return the StaleCacheError that causes err
+func IsStaleCache(err error) (*StaleCacheError, bool) {
+	stale, ok := errors.Cause(err).(*StaleCacheError)
+	return stale, ok
+}
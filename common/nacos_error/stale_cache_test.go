@@ -0,0 +1,36 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos_error
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsStaleCache(t *testing.T) {
+	stale, ok := IsStaleCache(errors.Wrap(&StaleCacheError{DataId: "rules", Age: time.Minute}, "get config failed"))
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, stale.Age)
+	assert.Contains(t, stale.Error(), "snapshot of 1m0s ago")
+	_, ok = IsStaleCache(errors.New("other"))
+	assert.False(t, ok)
+	_, ok = IsStaleCache(nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,40 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos_error
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// UnsupportedRequestError is returned when the server has no handler of the request type, such as the server
+// of earlier version than the request, retrying the request or switching server doesn't help
+type UnsupportedRequestError struct {
+	RequestType string
+	Message     string
+}
+
+func (err *UnsupportedRequestError) Error() string {
+	return fmt.Sprintf("[302] request %s is not supported by server, message=%s", err.RequestType, err.Message)
+}
+
+// IsUnsupportedRequest return the UnsupportedRequestError that causes err
+func IsUnsupportedRequest(err error) (*UnsupportedRequestError, bool) {
+	unsupported, ok := errors.Cause(err).(*UnsupportedRequestError)
+	return unsupported, ok
+}
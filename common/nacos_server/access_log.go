@@ -0,0 +1,66 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos_server
+
+import (
+	"strings"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/accesslog"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+)
+
+// AccessLog return the logger of operations set by ClientConfig.AccessLog
+func (server *NacosServer) AccessLog() *accesslog.Logger {
+	return server.accessLog
+}
+
+func (server *NacosServer) logHttpAccess(api string, params map[string]string, curServer constant.ServerConfig,
+	start time.Time, result string, err error) {
+	if !server.accessLog.Sampled(api) {
+		return
+	}
+	server.accessLog.Log(accesslog.Entry{
+		Op:      api,
+		Key:     httpAccessKey(params),
+		Latency: time.Since(start),
+		Result:  AccessResult(err),
+		Server:  strings.TrimPrefix(strings.TrimPrefix(getAddress(curServer), "http://"), "https://"),
+		Bytes:   len(result),
+	})
+}
+
+// httpAccessKey return the config key or the grouped service name of the http request
+func httpAccessKey(params map[string]string) string {
+	if dataId, ok := params["dataId"]; ok {
+		return util.GetConfigCacheKey(dataId, params["group"], params["tenant"])
+	}
+	return params["serviceName"]
+}
+
+// AccessResult return the result of operation logged, it's the error code of server if there is
+func AccessResult(err error) string {
+	if err == nil {
+		return accesslog.ResultOk
+	}
+	if nacosErr, ok := err.(*nacos_error.NacosError); ok {
+		return nacosErr.ErrorCode()
+	}
+	return accesslog.ResultError
+}
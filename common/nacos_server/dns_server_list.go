@@ -0,0 +1,111 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos_server
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+)
+
+const (
+	defaultDnsServerPort      = 8848
+	defaultDnsRefreshInterval = 30 * time.Second
+)
+
+// dnsResolver is the part of net.Resolver resolving the server list, it's replaced in tests
+type dnsResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+var defaultDnsResolver dnsResolver = net.DefaultResolver
+
+// initDnsRefreshIfNeed resolve the server list from ServerDnsName and resolve it again every DnsRefreshInterval
+func (server *NacosServer) initDnsRefreshIfNeed(ctx context.Context, clientCfg constant.ClientConfig) {
+	if clientCfg.ServerDnsName == "" {
+		return
+	}
+	port := clientCfg.ServerDnsPort
+	if port == 0 {
+		port = defaultDnsServerPort
+	}
+	interval := clientCfg.DnsRefreshInterval
+	if interval <= 0 {
+		interval = defaultDnsRefreshInterval
+	}
+	refresh := func() {
+		servers, err := server.resolveServers(ctx, defaultDnsResolver, clientCfg.ServerDnsName, port)
+		if err != nil {
+			logger.Errorf("resolve server list from dns name %s failed, err:%v", clientCfg.ServerDnsName, err)
+			return
+		}
+		server.updateServerList(servers)
+	}
+	refresh()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				refresh()
+			}
+		}
+	}()
+}
+
+// resolveServers resolve the SRV records of name, or its A records on port when it has no SRV record. The servers
+// are sorted so that the unchanged records in different order are not regarded as changed
+func (server *NacosServer) resolveServers(ctx context.Context, resolver dnsResolver, name string, port uint64) ([]constant.ServerConfig, error) {
+	contextPath := server.contextPath
+	if len(contextPath) == 0 {
+		contextPath = constant.WEB_CONTEXT
+	}
+	var servers []constant.ServerConfig
+	if _, records, err := resolver.LookupSRV(ctx, "", "", name); err == nil && len(records) > 0 {
+		for _, record := range records {
+			servers = append(servers, constant.ServerConfig{Scheme: constant.DEFAULT_SERVER_SCHEME,
+				IpAddr: strings.TrimSuffix(record.Target, "."), Port: uint64(record.Port), ContextPath: contextPath})
+		}
+	} else {
+		hosts, err := resolver.LookupHost(ctx, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "lookup %s failed", name)
+		}
+		for _, host := range hosts {
+			servers = append(servers, constant.ServerConfig{Scheme: constant.DEFAULT_SERVER_SCHEME,
+				IpAddr: host, Port: port, ContextPath: contextPath})
+		}
+	}
+	sort.Slice(servers, func(i, j int) bool {
+		if servers[i].IpAddr == servers[j].IpAddr {
+			return servers[i].Port < servers[j].Port
+		}
+		return servers[i].IpAddr < servers[j].IpAddr
+	})
+	return servers, nil
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos_server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/chaos"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
+)
+
+// Fault return the fault injected into the operation by ClientConfig.FaultInjector
+func (server *NacosServer) Fault(operation string) (chaos.Fault, bool) {
+	return server.faultInjector.Fault(operation)
+}
+
+// injectHttpFault apply the fault to the http request, responded is false when the request should still be sent
+func injectHttpFault(fault chaos.Fault, timeout time.Duration) (result string, responded bool, err error) {
+	if err = fault.Wait(timeout); err != nil {
+		return "", true, err
+	}
+	if fault.Malformed {
+		return chaos.MalformedBody, true, nil
+	}
+	if fault.Code != 0 {
+		return "", true, nacos_error.NewNacosError(strconv.Itoa(fault.Code), "injected fault", nil)
+	}
+	return "", false, nil
+}
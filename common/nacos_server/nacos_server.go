@@ -37,7 +37,10 @@ import (
 
 	"github.com/nacos-group/nacos-sdk-go/v2/common/monitor"
 
+	"github.com/nacos-group/nacos-sdk-go/v2/common/accesslog"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/chaos"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/dns"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/http_agent"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
@@ -49,6 +52,7 @@ import (
 type NacosServer struct {
 	sync.RWMutex
 	securityLogin         security.AuthClient
+	identityClient        *security.IdentityClient
 	serverList            []constant.ServerConfig
 	httpAgent             http_agent.IHttpAgent
 	timeoutMs             uint64
@@ -57,13 +61,21 @@ type NacosServer struct {
 	vipSrvRefInterMills   int64
 	contextPath           string
 	currentIndex          int32
-	ServerSrcChangeSignal chan struct{}
+	ServerSrcChangeSignal chan struct{} // Deprecated: use ServerChangeSignal
+	serverChangeSignals   []chan struct{}
+	userAgent             string
+	clientLabels          map[string]string
+	faultInjector         *chaos.Injector
+	selector              *serverSelector
+	accessLog             *accesslog.Logger
+	hostResolver          *dns.Resolver
+	grpcCompressors       []string
 }
 
 func NewNacosServer(ctx context.Context, serverList []constant.ServerConfig, clientCfg constant.ClientConfig, httpAgent http_agent.IHttpAgent, timeoutMs uint64, endpoint string) (*NacosServer, error) {
 	severLen := len(serverList)
-	if severLen == 0 && endpoint == "" {
-		return &NacosServer{}, errors.New("both serverlist  and  endpoint are empty")
+	if severLen == 0 && endpoint == "" && clientCfg.ServerDnsName == "" {
+		return &NacosServer{}, errors.New("serverlist, endpoint and server dns name are all empty")
 	}
 
 	securityLogin := security.NewAuthClient(clientCfg, serverList, httpAgent)
@@ -77,12 +89,27 @@ func NewNacosServer(ctx context.Context, serverList []constant.ServerConfig, cli
 		vipSrvRefInterMills:   10000,
 		contextPath:           clientCfg.ContextPath,
 		ServerSrcChangeSignal: make(chan struct{}, 1),
+		userAgent:             buildUserAgent(clientCfg.AppName, clientCfg.UserAgent),
+		clientLabels:          buildClientLabels(clientCfg.AppName),
+		faultInjector:         clientCfg.FaultInjector,
+		selector:              newServerSelector(clientCfg.ServerSelection),
+		accessLog:             clientCfg.AccessLog,
+		grpcCompressors:       clientCfg.GrpcCompressors,
+	}
+	if clientCfg.DnsCacheCfg != nil {
+		ns.hostResolver = dns.NewResolver(*clientCfg.DnsCacheCfg, clientCfg.Clock)
 	}
 	if severLen > 0 {
 		ns.currentIndex = rand.Int31n(int32(severLen))
 	}
 
 	ns.initRefreshSrvIfNeed(ctx)
+	ns.initDnsRefreshIfNeed(ctx, clientCfg)
+	if severLen == 0 {
+		// the servers resolved from endpoint or dns name are known only now
+		securityLogin = security.NewAuthClient(clientCfg, ns.GetServerList(), httpAgent)
+		ns.securityLogin = securityLogin
+	}
 	_, err := securityLogin.Login()
 
 	if err != nil {
@@ -90,19 +117,28 @@ func NewNacosServer(ctx context.Context, serverList []constant.ServerConfig, cli
 	}
 
 	securityLogin.AutoRefresh(ctx)
+
+	if clientCfg.IdentityCfg != nil {
+		if ns.identityClient, err = security.NewIdentityClient(clientCfg.IdentityCfg); err != nil {
+			return &ns, err
+		}
+		if err = ns.identityClient.Refresh(ctx); err != nil {
+			logger.Errorf("fetch identity token err:%v", err)
+		}
+		ns.identityClient.AutoRefresh(ctx)
+	}
 	return &ns, nil
 }
 
 func (server *NacosServer) callConfigServer(api string, params map[string]string, newHeaders map[string]string,
-	method string, curServer string, contextPath string, timeoutMS uint64) (result string, err error) {
+	method string, curServer constant.ServerConfig, timeoutMS uint64) (result string, err error) {
 	start := time.Now()
-	if contextPath == "" {
-		contextPath = constant.WEB_CONTEXT
-	}
-
+	defer func() {
+		server.logHttpAccess(api, params, curServer, start, result, err)
+	}()
 	signHeaders := GetSignHeaders(params, newHeaders["secretKey"])
 
-	url := curServer + contextPath + api
+	url := buildUrl(curServer, constant.API_GROUP_CONFIG, api)
 
 	headers := map[string][]string{}
 	for k, v := range newHeaders {
@@ -111,7 +147,7 @@ func (server *NacosServer) callConfigServer(api string, params map[string]string
 		}
 	}
 	headers["Client-Version"] = []string{constant.CLIENT_VERSION}
-	headers["User-Agent"] = []string{constant.CLIENT_VERSION}
+	headers["User-Agent"] = []string{server.UserAgent()}
 	//headers["Accept-Encoding"] = []string{"gzip,deflate,sdch"}
 	headers["Connection"] = []string{"Keep-Alive"}
 	headers["exConfigInfo"] = []string{"true"}
@@ -125,7 +161,13 @@ func (server *NacosServer) callConfigServer(api string, params map[string]string
 	headers["Timestamp"] = []string{signHeaders["Timestamp"]}
 	headers["Spas-Signature"] = []string{signHeaders["Spas-Signature"]}
 	server.InjectSecurityInfo(params)
+	server.injectIdentityHttp(headers)
 
+	if fault, ok := server.Fault(api); ok {
+		if result, responded, err := injectHttpFault(fault, time.Duration(timeoutMS)*time.Millisecond); responded {
+			return result, err
+		}
+	}
 	var response *http.Response
 	response, err = server.httpAgent.Request(method, url, headers, timeoutMS, params)
 	monitor.GetConfigRequestMonitor(method, url, util.GetStatusCode(response)).Observe(float64(time.Now().Nanosecond() - start.Nanosecond()))
@@ -141,23 +183,25 @@ func (server *NacosServer) callConfigServer(api string, params map[string]string
 	result = string(bytes)
 	if response.StatusCode == constant.RESPONSE_CODE_SUCCESS {
 		return
+	} else if response.StatusCode == http.StatusTooManyRequests {
+		err = nacos_error.NewRateLimitedError(result, response.Header, time.Now())
+		return
 	} else {
 		err = nacos_error.NewNacosError(strconv.Itoa(response.StatusCode), string(bytes), nil)
 		return
 	}
 }
 
-func (server *NacosServer) callServer(api string, params map[string]string, method string, curServer string, contextPath string) (result string, err error) {
+func (server *NacosServer) callServer(api string, params map[string]string, method string, curServer constant.ServerConfig) (result string, err error) {
 	start := time.Now()
-	if contextPath == "" {
-		contextPath = constant.WEB_CONTEXT
-	}
-
-	url := curServer + contextPath + api
+	defer func() {
+		server.logHttpAccess(api, params, curServer, start, result, err)
+	}()
+	url := buildUrl(curServer, constant.API_GROUP_NAMING, api)
 
 	headers := map[string][]string{}
 	headers["Client-Version"] = []string{constant.CLIENT_VERSION}
-	headers["User-Agent"] = []string{constant.CLIENT_VERSION}
+	headers["User-Agent"] = []string{server.UserAgent()}
 	//headers["Accept-Encoding"] = []string{"gzip,deflate,sdch"}
 	headers["Connection"] = []string{"Keep-Alive"}
 	uid, err := uuid.NewV4()
@@ -169,7 +213,13 @@ func (server *NacosServer) callServer(api string, params map[string]string, meth
 	headers["Content-Type"] = []string{"application/x-www-form-urlencoded;charset=utf-8"}
 
 	server.InjectSecurityInfo(params)
+	server.injectIdentityHttp(headers)
 
+	if fault, ok := server.Fault(api); ok {
+		if result, responded, err := injectHttpFault(fault, time.Duration(server.timeoutMs)*time.Millisecond); responded {
+			return result, err
+		}
+	}
 	var response *http.Response
 	response, err = server.httpAgent.Request(method, url, headers, server.timeoutMs, params)
 	if err != nil {
@@ -204,20 +254,29 @@ func (server *NacosServer) ReqConfigApi(api string, params map[string]string, he
 	var result string
 	if len(srvs) == 1 {
 		for i := 0; i < constant.REQUEST_DOMAIN_RETRY_TIME; i++ {
-			result, err = server.callConfigServer(api, params, headers, method, getAddress(srvs[0]), srvs[0].ContextPath, timeoutMS)
+			result, err = server.callConfigServer(api, params, headers, method, srvs[0], timeoutMS)
 			if err == nil {
 				return result, nil
 			}
+			if _, ok := nacos_error.IsRateLimited(err); ok {
+				// retrying right away makes the throttling worse, the caller backs off as the server asks
+				return "", err
+			}
 			logger.Errorf("api<%s>,method:<%s>, params:<%s>, call domain error:<%+v> , result:<%s>", api, method, util.ToJsonString(params), err, result)
 		}
 	} else {
-		index := rand.Intn(len(srvs))
+		index := server.selector.first(srvs, method)
 		for i := 1; i <= len(srvs); i++ {
 			curServer := srvs[index]
-			result, err = server.callConfigServer(api, params, headers, method, getAddress(curServer), curServer.ContextPath, timeoutMS)
+			start := time.Now()
+			result, err = server.callConfigServer(api, params, headers, method, curServer, timeoutMS)
+			server.selector.observe(curServer, method, time.Since(start), err)
 			if err == nil {
 				return result, nil
 			}
+			if _, ok := nacos_error.IsRateLimited(err); ok {
+				return "", err
+			}
 			logger.Errorf("[ERROR] api<%s>,method:<%s>, params:<%s>, call domain error:<%+v> , result:<%s> \n", api, method, util.ToJsonString(params), err, result)
 			index = (index + i) % len(srvs)
 		}
@@ -239,17 +298,19 @@ func (server *NacosServer) ReqApi(api string, params map[string]string, method s
 	var result string
 	if len(srvs) == 1 {
 		for i := 0; i < constant.REQUEST_DOMAIN_RETRY_TIME; i++ {
-			result, err = server.callServer(api, params, method, getAddress(srvs[0]), srvs[0].ContextPath)
+			result, err = server.callServer(api, params, method, srvs[0])
 			if err == nil {
 				return result, nil
 			}
 			logger.Errorf("api<%s>,method:<%s>, params:<%s>, call domain error:<%+v> , result:<%s>", api, method, util.ToJsonString(params), err, result)
 		}
 	} else {
-		index := rand.Intn(len(srvs))
+		index := server.selector.first(srvs, method)
 		for i := 1; i <= len(srvs); i++ {
 			curServer := srvs[index]
-			result, err = server.callServer(api, params, method, getAddress(curServer), curServer.ContextPath)
+			start := time.Now()
+			result, err = server.callServer(api, params, method, curServer)
+			server.selector.observe(curServer, method, time.Since(start), err)
 			if err == nil {
 				return result, nil
 			}
@@ -311,18 +372,52 @@ func (server *NacosServer) refreshServerSrvIfNeed() {
 			servers = append(servers, constant.ServerConfig{Scheme: constant.DEFAULT_SERVER_SCHEME, IpAddr: splitLine[0], Port: uint64(port), ContextPath: contextPath})
 		}
 	}
+	server.updateServerList(servers)
+}
+
+// updateServerList replace the server list and signal the clients when it changes, empty servers are ignored
+func (server *NacosServer) updateServerList(servers []constant.ServerConfig) {
 	if len(servers) > 0 {
 		if !reflect.DeepEqual(server.serverList, servers) {
 			server.Lock()
 			logger.Infof("server list is updated, old: <%v>,new:<%v>", server.serverList, servers)
 			server.serverList = servers
-			server.ServerSrcChangeSignal <- struct{}{}
+			server.notifyServerChange()
 			server.lastSrvRefTime = util.CurrentMillis()
 			server.Unlock()
 		}
 
 	}
-	return
+}
+
+// ServerChangeSignal return the channel signaled when the server list refreshed from endpoint changes, each caller
+// has its own channel so that the clients sharing the server are all signaled
+func (server *NacosServer) ServerChangeSignal() <-chan struct{} {
+	signal := make(chan struct{}, 1)
+	server.Lock()
+	server.serverChangeSignals = append(server.serverChangeSignals, signal)
+	server.Unlock()
+	return signal
+}
+
+// notifyServerChange must be called with the lock held, the pending signals are merged
+func (server *NacosServer) notifyServerChange() {
+	for _, signal := range append(server.serverChangeSignals, server.ServerSrcChangeSignal) {
+		select {
+		case signal <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// HostResolver return the dns cache of server hostnames, nil when DnsCacheCfg is not set
+func (server *NacosServer) HostResolver() *dns.Resolver {
+	return server.hostResolver
+}
+
+// GrpcCompressors return the compressors of grpc payloads in preference order
+func (server *NacosServer) GrpcCompressors() []string {
+	return server.grpcCompressors
 }
 
 func (server *NacosServer) GetServerList() []constant.ServerConfig {
@@ -336,6 +431,21 @@ func (server *NacosServer) InjectSecurityInfo(param map[string]string) {
 	}
 }
 
+// InjectIdentity puts the workload identity token into headers when IdentityConfig is set.
+func (server *NacosServer) InjectIdentity(headers map[string]string) {
+	if server.identityClient != nil {
+		server.identityClient.InjectHeaders(headers)
+	}
+}
+
+func (server *NacosServer) injectIdentityHttp(headers map[string][]string) {
+	identityHeaders := map[string]string{}
+	server.InjectIdentity(identityHeaders)
+	for k, v := range identityHeaders {
+		headers[k] = []string{v}
+	}
+}
+
 func (server *NacosServer) InjectSignForNamingHttp(param map[string]string, clientConfig constant.ClientConfig) {
 	if clientConfig.AccessKey == "" || clientConfig.SecretKey == "" {
 		return
@@ -370,6 +480,15 @@ func (server *NacosServer) InjectSign(request rpc_request.IRequest, param map[st
 	param["ak"] = clientConfig.AccessKey
 }
 
+// buildUrl build the url of api with the context path of api group, and rewrite it by UrlRewriter
+func buildUrl(cfg constant.ServerConfig, apiGroup, api string) string {
+	contextPath := cfg.ContextPathOf(apiGroup)
+	if contextPath == "" {
+		contextPath = constant.WEB_CONTEXT
+	}
+	return cfg.RewriteUrl(apiGroup, getAddress(cfg)+contextPath+api)
+}
+
 func getAddress(cfg constant.ServerConfig) string {
 	if strings.Index(cfg.IpAddr, "http://") >= 0 || strings.Index(cfg.IpAddr, "https://") >= 0 {
 		return cfg.IpAddr + ":" + strconv.Itoa(int(cfg.Port))
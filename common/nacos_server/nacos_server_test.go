@@ -18,8 +18,14 @@ package nacos_server
 
 import (
 	"context"
+	"errors"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/http_agent"
+	"net"
+	"net/http"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
 	"github.com/stretchr/testify/assert"
@@ -49,6 +55,21 @@ func Test_getAddressWithoutScheme(t *testing.T) {
 
 }
 
+func Test_buildUrlWithApiContextPath(t *testing.T) {
+	serverConfigTest := *constant.NewServerConfig("console.nacos.io", 80,
+		constant.WithApiContextPath(constant.API_GROUP_CONFIG, "/config-ingress"))
+	assert.Equal(t, "http://console.nacos.io:80/config-ingress/v1/cs/configs",
+		buildUrl(serverConfigTest, constant.API_GROUP_CONFIG, "/v1/cs/configs"))
+	assert.Equal(t, "http://console.nacos.io:80/nacos/v1/ns/instance",
+		buildUrl(serverConfigTest, constant.API_GROUP_NAMING, "/v1/ns/instance"))
+
+	serverConfigTest.UrlRewriter = func(apiGroup, url string) string {
+		return strings.Replace(url, "/nacos/", "/"+apiGroup+"/nacos/", 1)
+	}
+	assert.Equal(t, "http://console.nacos.io:80/naming/nacos/v1/ns/instance",
+		buildUrl(serverConfigTest, constant.API_GROUP_NAMING, "/v1/ns/instance"))
+}
+
 func buildNacosServer(clientConfig constant.ClientConfig) (*NacosServer, error) {
 	return NewNacosServer(context.Background(),
 		[]constant.ServerConfig{*constant.NewServerConfig("http://console.nacos.io", 80)},
@@ -155,3 +176,106 @@ func TestNacosServer_InjectSignForNamingHttp_WithoutServiceNameAndGroup(t *testi
 	_, has := param["signature"]
 	assert.True(t, has)
 }
+
+func TestNacosServer_UserAgent(t *testing.T) {
+	assert.Equal(t, constant.CLIENT_VERSION, (&NacosServer{}).UserAgent())
+
+	userAgent := buildUserAgent("demo", " my-framework/1.0 ")
+	assert.True(t, strings.HasPrefix(userAgent, constant.CLIENT_VERSION+" ("+runtime.Version()+"; "))
+	assert.True(t, strings.HasSuffix(userAgent, ") app/demo my-framework/1.0"))
+	assert.Equal(t, constant.CLIENT_VERSION+" ("+runtime.Version()+"; "+runtime.GOOS+"/"+runtime.GOARCH+")", buildUserAgent("", ""))
+
+	labels := buildClientLabels("demo")
+	assert.Equal(t, constant.SDK_VERSION, labels[constant.LABEL_SDK_VERSION])
+	assert.Equal(t, runtime.Version(), labels[constant.LABEL_GO_VERSION])
+	assert.Equal(t, "demo", labels[constant.APPNAME_HEADER])
+	assert.NotContains(t, buildClientLabels(""), constant.APPNAME_HEADER)
+}
+
+func TestNacosServer_ServerChangeSignal(t *testing.T) {
+	server := &NacosServer{ServerSrcChangeSignal: make(chan struct{}, 1)}
+	config, naming := server.ServerChangeSignal(), server.ServerChangeSignal()
+	server.notifyServerChange()
+	// the pending signals are merged
+	server.notifyServerChange()
+	for _, signal := range []<-chan struct{}{config, naming} {
+		select {
+		case <-signal:
+		default:
+			t.Fatal("the client sharing the server is not signaled")
+		}
+		assert.Len(t, signal, 0)
+	}
+}
+
+type fakeDnsResolver struct {
+	srv   []*net.SRV
+	hosts []string
+}
+
+func (r *fakeDnsResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	if len(r.srv) == 0 {
+		return "", nil, errors.New("no such host")
+	}
+	return name, r.srv, nil
+}
+
+func (r *fakeDnsResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return r.hosts, nil
+}
+
+func TestNacosServer_resolveServers(t *testing.T) {
+	server := &NacosServer{ServerSrcChangeSignal: make(chan struct{}, 1)}
+	signal := server.ServerChangeSignal()
+	resolver := &fakeDnsResolver{srv: []*net.SRV{
+		{Target: "nacos-1.nacos-headless.default.svc.cluster.local.", Port: 8848},
+		{Target: "nacos-0.nacos-headless.default.svc.cluster.local.", Port: 8848},
+	}}
+	servers, err := server.resolveServers(context.Background(), resolver, "nacos-headless", 8848)
+	assert.Nil(t, err)
+	assert.Equal(t, "nacos-0.nacos-headless.default.svc.cluster.local", servers[0].IpAddr)
+	assert.Equal(t, "/nacos", servers[0].ContextPath)
+	server.updateServerList(servers)
+	assert.Len(t, signal, 1)
+	<-signal
+
+	// the A records are resolved without SRV records, the unchanged list is not signaled
+	resolver = &fakeDnsResolver{hosts: []string{"10.0.0.2", "10.0.0.1"}}
+	servers, err = server.resolveServers(context.Background(), resolver, "nacos-headless", 8848)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(8848), servers[1].Port)
+	server.updateServerList(servers)
+	<-signal
+	servers, _ = server.resolveServers(context.Background(), &fakeDnsResolver{hosts: []string{"10.0.0.1", "10.0.0.2"}}, "nacos-headless", 8848)
+	server.updateServerList(servers)
+	assert.Len(t, signal, 0)
+	assert.Equal(t, "10.0.0.1", server.GetServerList()[0].IpAddr)
+}
+
+func TestServerSelector(t *testing.T) {
+	servers := []constant.ServerConfig{{IpAddr: "10.0.0.1", Port: 8848}, {IpAddr: "10.0.0.2", Port: 8848}, {IpAddr: "10.0.0.3", Port: 8848}}
+	selector := newServerSelector(constant.SERVER_SELECTION_RW_SPLIT)
+	selector.observe(servers[0], http.MethodGet, 2*time.Millisecond, nil)
+	selector.observe(servers[1], http.MethodGet, 200*time.Millisecond, nil)
+	selector.observe(servers[2], http.MethodGet, 2*time.Millisecond, errors.New("timeout"))
+	counts := make([]int, len(servers))
+	for i := 0; i < 1000; i++ {
+		counts[selector.first(servers, http.MethodGet)]++
+	}
+	// the reads are spread in inverse proportion to latency
+	assert.Greater(t, counts[0], 900)
+
+	// the writes stick to the server succeeding the first write until it fails
+	selector.observe(servers[1], http.MethodPost, 200*time.Millisecond, nil)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, 1, selector.first(servers, http.MethodPost))
+	}
+	selector.observe(servers[1], http.MethodPost, time.Millisecond, errors.New("timeout"))
+	selector.observe(servers[0], http.MethodPost, time.Millisecond, nil)
+	assert.Equal(t, 0, selector.first(servers, http.MethodDelete))
+
+	// all requests stick to one server in sticky mode
+	selector = newServerSelector(constant.SERVER_SELECTION_STICKY)
+	selector.observe(servers[2], http.MethodGet, time.Millisecond, nil)
+	assert.Equal(t, 2, selector.first(servers, http.MethodGet))
+}
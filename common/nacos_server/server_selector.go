@@ -0,0 +1,134 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos_server
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+)
+
+const (
+	// latencyAlpha is the weight of the latest observation in the moving average of latency
+	latencyAlpha = 0.3
+	// failurePenalty is added to the latency of the server failing a request
+	failurePenalty = time.Second
+	// minLatency bounds the weight of the servers answering very fast or not observed yet
+	minLatency = time.Millisecond
+)
+
+// serverSelector choose the first server tried by the http requests, the others are tried in turn on failure
+type serverSelector struct {
+	mode string
+	mux  sync.Mutex
+	// latency is the moving average of the latency of each server, keyed by address
+	latency map[string]time.Duration
+	// sticky is the address of the server the writes, or all requests in sticky mode, are sent to
+	sticky string
+}
+
+func newServerSelector(mode string) *serverSelector {
+	return &serverSelector{mode: mode, latency: make(map[string]time.Duration)}
+}
+
+func serverAddress(server constant.ServerConfig) string {
+	return server.IpAddr + ":" + strconv.FormatUint(server.Port, 10)
+}
+
+func isWrite(method string) bool {
+	return method != http.MethodGet && method != http.MethodHead
+}
+
+// first return the index of the first server tried by the request of method
+func (s *serverSelector) first(servers []constant.ServerConfig, method string) int {
+	switch {
+	case s == nil || s.mode == "" || s.mode == constant.SERVER_SELECTION_RANDOM:
+		return rand.Intn(len(servers))
+	case s.mode == constant.SERVER_SELECTION_STICKY || isWrite(method):
+		if index := s.stickyIndex(servers); index >= 0 {
+			return index
+		}
+		return s.weighted(servers)
+	default:
+		return s.weighted(servers)
+	}
+}
+
+func (s *serverSelector) stickyIndex(servers []constant.ServerConfig) int {
+	s.mux.Lock()
+	sticky := s.sticky
+	s.mux.Unlock()
+	for i, server := range servers {
+		if serverAddress(server) == sticky {
+			return i
+		}
+	}
+	return -1
+}
+
+// weighted choose the server at random, the chance is in inverse proportion to its latency
+func (s *serverSelector) weighted(servers []constant.ServerConfig) int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	weights := make([]float64, len(servers))
+	var total float64
+	for i, server := range servers {
+		latency := s.latency[serverAddress(server)]
+		if latency < minLatency {
+			latency = minLatency
+		}
+		weights[i] = 1 / latency.Seconds()
+		total += weights[i]
+	}
+	r := rand.Float64() * total
+	for i, weight := range weights {
+		if r < weight {
+			return i
+		}
+		r -= weight
+	}
+	return len(servers) - 1
+}
+
+// observe record the latency of the request sent to server, the sticky server is kept while it succeeds, and
+// moved to the server succeeding next when it fails
+func (s *serverSelector) observe(server constant.ServerConfig, method string, latency time.Duration, err error) {
+	if s == nil || s.mode == "" || s.mode == constant.SERVER_SELECTION_RANDOM {
+		return
+	}
+	address := serverAddress(server)
+	if err != nil {
+		latency += failurePenalty
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if average, ok := s.latency[address]; ok {
+		s.latency[address] = time.Duration(latencyAlpha*float64(latency) + (1-latencyAlpha)*float64(average))
+	} else {
+		s.latency[address] = latency
+	}
+	switch {
+	case err != nil && s.sticky == address:
+		s.sticky = ""
+	case err == nil && s.sticky == "" && (s.mode == constant.SERVER_SELECTION_STICKY || isWrite(method)):
+		s.sticky = address
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nacos_server
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+)
+
+// buildUserAgent build the structured User-Agent, such as
+// Nacos-Go-Client:v2.2.2 (go1.18; linux/amd64) app/demo my-framework/1.0
+// the CLIENT_VERSION is kept as the first product, which is parsed by server as the version of client
+func buildUserAgent(appName, userAgent string) string {
+	var sb strings.Builder
+	sb.WriteString(constant.CLIENT_VERSION)
+	sb.WriteString(" (")
+	sb.WriteString(runtime.Version())
+	sb.WriteString("; ")
+	sb.WriteString(runtime.GOOS + "/" + runtime.GOARCH)
+	sb.WriteString(")")
+	if len(appName) > 0 {
+		sb.WriteString(" app/")
+		sb.WriteString(appName)
+	}
+	if userAgent = strings.TrimSpace(userAgent); len(userAgent) > 0 {
+		sb.WriteString(" ")
+		sb.WriteString(userAgent)
+	}
+	return sb.String()
+}
+
+// buildClientLabels build the metadata of client reported to server when the grpc connection is set up,
+// so that the apps running old sdk can be found by the labels of connections
+func buildClientLabels(appName string) map[string]string {
+	labels := map[string]string{
+		constant.LABEL_SDK_VERSION: constant.SDK_VERSION,
+		constant.LABEL_GO_VERSION:  runtime.Version(),
+		constant.LABEL_PLATFORM:    runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if len(appName) > 0 {
+		labels[constant.APPNAME_HEADER] = appName
+	}
+	return labels
+}
+
+// UserAgent return the User-Agent of requests
+func (server *NacosServer) UserAgent() string {
+	if len(server.userAgent) == 0 {
+		return constant.CLIENT_VERSION
+	}
+	return server.userAgent
+}
+
+// ClientLabels return the metadata of client reported to server
+func (server *NacosServer) ClientLabels() map[string]string {
+	return server.clientLabels
+}
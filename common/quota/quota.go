@@ -0,0 +1,115 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package quota enforces the client-side quotas of constant.QuotaConfig, protecting the host from the runaway
+// automation which listens or subscribes without bound
+package quota
+
+import (
+	"sync"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
+const defaultWarnRatio = 0.8
+
+// Guard checks the usage against the quotas, the nil Guard allows everything
+type Guard struct {
+	cfg    constant.QuotaConfig
+	mux    sync.Mutex
+	warned map[model.QuotaKind]bool
+}
+
+// NewGuard return nil when cfg is nil
+func NewGuard(cfg *constant.QuotaConfig) *Guard {
+	if cfg == nil {
+		return nil
+	}
+	g := &Guard{cfg: *cfg, warned: make(map[model.QuotaKind]bool)}
+	if g.cfg.WarnRatio <= 0 || g.cfg.WarnRatio > 1 {
+		g.cfg.WarnRatio = defaultWarnRatio
+	}
+	return g
+}
+
+// CheckContentSize return QuotaExceededError when the content of key is larger than MaxContentSize
+func (g *Guard) CheckContentSize(key string, size int) error {
+	if g == nil || g.cfg.MaxContentSize <= 0 || size <= g.cfg.MaxContentSize {
+		return nil
+	}
+	return g.exceeded(model.QuotaContentSize, g.cfg.MaxContentSize, size, key)
+}
+
+// MaxContentSize return the max size in bytes of the config content, 0 means no limit
+func (g *Guard) MaxContentSize() int {
+	if g == nil {
+		return 0
+	}
+	return g.cfg.MaxContentSize
+}
+
+// CheckListenedKeys check the number of listened configs including the new key
+func (g *Guard) CheckListenedKeys(key string, used int) error {
+	if g == nil {
+		return nil
+	}
+	return g.checkCount(model.QuotaListenedKeys, g.cfg.MaxListenedKeys, used, key)
+}
+
+// CheckSubscriptions check the number of subscribed services including the new key
+func (g *Guard) CheckSubscriptions(key string, used int) error {
+	if g == nil {
+		return nil
+	}
+	return g.checkCount(model.QuotaSubscriptions, g.cfg.MaxSubscriptions, used, key)
+}
+
+// checkCount emit the warning event once when used reaches the warning ratio of limit, it's emitted again
+// after the usage drops below it
+func (g *Guard) checkCount(kind model.QuotaKind, limit, used int, key string) error {
+	if limit <= 0 {
+		return nil
+	}
+	if used > limit {
+		return g.exceeded(kind, limit, used, key)
+	}
+	reached := float64(used) >= float64(limit)*g.cfg.WarnRatio
+	g.mux.Lock()
+	warn := reached && !g.warned[kind]
+	g.warned[kind] = reached
+	g.mux.Unlock()
+	if warn {
+		logger.Warnf("client quota %s reaching limit, limit=%d, used=%d, key=%s", kind, limit, used, key)
+		g.emit(model.QuotaEvent{Kind: kind, Limit: limit, Used: used, Key: key})
+	}
+	return nil
+}
+
+func (g *Guard) exceeded(kind model.QuotaKind, limit, used int, key string) error {
+	err := &nacos_error.QuotaExceededError{Quota: string(kind), Limit: limit, Used: used, Key: key}
+	logger.Warnf("%v", err)
+	g.emit(model.QuotaEvent{Kind: kind, Limit: limit, Used: used, Key: key, Exceeded: true})
+	return err
+}
+
+func (g *Guard) emit(event model.QuotaEvent) {
+	if g.cfg.OnEvent != nil {
+		g.cfg.OnEvent(event)
+	}
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package quota
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
+func TestGuard(t *testing.T) {
+	var nilGuard *Guard
+	assert.Nil(t, nilGuard.CheckContentSize("key", 1<<30))
+	assert.Nil(t, NewGuard(nil).CheckSubscriptions("key", 1<<30))
+
+	var events []model.QuotaEvent
+	g := NewGuard(&constant.QuotaConfig{MaxSubscriptions: 10, OnEvent: func(event model.QuotaEvent) {
+		events = append(events, event)
+	}})
+	for used := 1; used <= 10; used++ {
+		assert.Nil(t, g.CheckSubscriptions("service", used))
+	}
+	assert.Equal(t, []model.QuotaEvent{{Kind: model.QuotaSubscriptions, Limit: 10, Used: 8, Key: "service"}}, events)
+
+	// warned again after the usage drops below the ratio
+	assert.Nil(t, g.CheckSubscriptions("service", 5))
+	assert.Nil(t, g.CheckSubscriptions("service", 9))
+	assert.Len(t, events, 2)
+
+	err := g.CheckSubscriptions("service", 11)
+	exceeded, ok := nacos_error.IsQuotaExceeded(err)
+	assert.True(t, ok)
+	assert.Equal(t, 10, exceeded.Limit)
+	assert.True(t, events[2].Exceeded)
+	assert.Nil(t, g.CheckListenedKeys("config", 1<<30))
+}
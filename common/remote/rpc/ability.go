@@ -0,0 +1,207 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"sync"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+)
+
+// The abilities of server negotiated on connection setup, the keys are the same as the ones of nacos server
+const (
+	AbilityPersistentInstanceByGrpc = "supportPersistentInstanceByGrpc"
+)
+
+// The features of client which degrade to the compatible fallbacks on the servers without the abilities
+const (
+	FeaturePersistentInstanceByGrpc = "persistentInstanceByGrpc"
+	FeatureBatchRegisterInstance    = "batchRegisterInstance"
+)
+
+// AbilityStatus is the status of a server ability on current connection
+type AbilityStatus int
+
+const (
+	// AbilityUnknown means the server doesn't support negotiation, such as the server earlier than 2.3,
+	// or the negotiation isn't completed
+	AbilityUnknown AbilityStatus = iota
+	AbilitySupported
+	AbilityNotSupported
+)
+
+// FeatureDecision is whether the feature is used on current connection and why
+type FeatureDecision struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"`
+}
+
+// AbilityState is the result of ability negotiation on current connection, it's shown in the snapshot of clients
+type AbilityState struct {
+	ConnectionId string                     `json:"connectionId"`
+	Compressor   string                     `json:"compressor,omitempty"`
+	Negotiated   bool                       `json:"negotiated"`
+	Server       map[string]bool            `json:"server"`
+	Features     map[string]FeatureDecision `json:"features"`
+}
+
+// abilityTable keeps the abilities of the server currently connected, and the features decided against them
+type abilityTable struct {
+	mux          sync.Mutex
+	connectionId string
+	compressor   string
+	negotiated   bool
+	server       map[string]bool
+	features     map[string]FeatureDecision
+	acked        chan struct{}
+}
+
+// clientAbilityTable is reported on connection setup, it's not nil so that the server regards the client
+// as supporting negotiation
+func clientAbilityTable() map[string]bool {
+	return map[string]bool{}
+}
+
+// reset the table for the new connection, the features are decided again against the new server. The returned
+// channel is closed when the server acknowledges the setup with its abilities
+func (t *abilityTable) reset(connectionId, compressor string) <-chan struct{} {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.connectionId = connectionId
+	t.compressor = compressor
+	t.negotiated = false
+	t.server = nil
+	t.features = nil
+	t.acked = make(chan struct{})
+	return t.acked
+}
+
+func (t *abilityTable) setServer(table map[string]bool) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.server = make(map[string]bool, len(table))
+	for k, v := range table {
+		t.server[k] = v
+	}
+	t.negotiated = true
+	t.features = nil
+	if t.acked != nil {
+		select {
+		case <-t.acked:
+		default:
+			close(t.acked)
+		}
+	}
+}
+
+func (t *abilityTable) status(key string) AbilityStatus {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return t.statusLocked(key)
+}
+
+func (t *abilityTable) statusLocked(key string) AbilityStatus {
+	if !t.negotiated {
+		return AbilityUnknown
+	}
+	if t.server[key] {
+		return AbilitySupported
+	}
+	return AbilityNotSupported
+}
+
+// decide record the decision of feature, it's logged only when changed
+func (t *abilityTable) decide(feature string, decision FeatureDecision) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if last, ok := t.features[feature]; ok && last == decision {
+		return
+	}
+	if t.features == nil {
+		t.features = make(map[string]FeatureDecision, 4)
+	}
+	t.features[feature] = decision
+	if decision.Enabled {
+		logger.Infof("[ability] feature %s is enabled on connection %s, %s", feature, t.connectionId, decision.Reason)
+	} else {
+		logger.Warnf("[ability] feature %s degrades to fallback on connection %s, %s", feature, t.connectionId, decision.Reason)
+	}
+}
+
+func (t *abilityTable) decision(feature string) (FeatureDecision, bool) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	decision, ok := t.features[feature]
+	return decision, ok
+}
+
+func (t *abilityTable) snapshot() AbilityState {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	state := AbilityState{
+		ConnectionId: t.connectionId,
+		Compressor:   t.compressor,
+		Negotiated:   t.negotiated,
+		Server:       make(map[string]bool, len(t.server)),
+		Features:     make(map[string]FeatureDecision, len(t.features)),
+	}
+	for k, v := range t.server {
+		state.Server[k] = v
+	}
+	for k, v := range t.features {
+		state.Features[k] = v
+	}
+	return state
+}
+
+// ServerAbility return the status of the ability of the server currently connected
+func (r *RpcClient) ServerAbility(key string) AbilityStatus {
+	return r.abilities.status(key)
+}
+
+// UseFeature decide whether the feature depending on the server ability is used on current connection, the
+// decision is recorded for diagnostics. The feature is used only when the ability is negotiated as supported
+func (r *RpcClient) UseFeature(feature, ability string) bool {
+	var decision FeatureDecision
+	switch r.abilities.status(ability) {
+	case AbilitySupported:
+		decision = FeatureDecision{Enabled: true, Reason: "ability " + ability + " is supported by server"}
+	case AbilityNotSupported:
+		decision = FeatureDecision{Reason: "ability " + ability + " is not supported by server"}
+	default:
+		decision = FeatureDecision{Reason: "server doesn't negotiate abilities"}
+	}
+	r.abilities.decide(feature, decision)
+	return decision.Enabled
+}
+
+// DegradeFeature record the feature degraded to the fallback on current connection, such as the request is
+// rejected by the server without the handler
+func (r *RpcClient) DegradeFeature(feature, reason string) {
+	r.abilities.decide(feature, FeatureDecision{Reason: reason})
+}
+
+// FeatureDegraded return whether the feature is degraded to the fallback on current connection
+func (r *RpcClient) FeatureDegraded(feature string) bool {
+	decision, ok := r.abilities.decision(feature)
+	return ok && !decision.Enabled
+}
+
+// Abilities return the result of ability negotiation on current connection and the features decided
+func (r *RpcClient) Abilities() AbilityState {
+	return r.abilities.snapshot()
+}
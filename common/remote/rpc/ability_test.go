@@ -0,0 +1,86 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_request"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_response"
+)
+
+func TestAbilityNegotiation(t *testing.T) {
+	client := &RpcClient{}
+	acked := client.abilities.reset("conn-1", "")
+	assert.Equal(t, AbilityUnknown, client.ServerAbility(AbilityPersistentInstanceByGrpc))
+	assert.False(t, client.UseFeature(FeaturePersistentInstanceByGrpc, AbilityPersistentInstanceByGrpc))
+
+	response := (&SetupAckRequestHandler{}).RequestReply(&rpc_request.SetupAckRequest{
+		InternalRequest: rpc_request.NewInternalRequest(),
+		AbilityTable:    map[string]bool{AbilityPersistentInstanceByGrpc: true},
+	}, client)
+	assert.Equal(t, constant.RESPONSE_CODE_SUCCESS, response.GetResultCode())
+	select {
+	case <-acked:
+	default:
+		t.Fatal("setup ack is not signaled")
+	}
+	assert.Equal(t, AbilitySupported, client.ServerAbility(AbilityPersistentInstanceByGrpc))
+	assert.Equal(t, AbilityNotSupported, client.ServerAbility("fuzzyWatch"))
+	assert.True(t, client.UseFeature(FeaturePersistentInstanceByGrpc, AbilityPersistentInstanceByGrpc))
+
+	client.DegradeFeature(FeatureBatchRegisterInstance, "no handler")
+	assert.True(t, client.FeatureDegraded(FeatureBatchRegisterInstance))
+	state := client.Abilities()
+	assert.Equal(t, "conn-1", state.ConnectionId)
+	assert.True(t, state.Negotiated)
+	assert.Equal(t, map[string]FeatureDecision{
+		FeaturePersistentInstanceByGrpc: {Enabled: true, Reason: "ability supportPersistentInstanceByGrpc is supported by server"},
+		FeatureBatchRegisterInstance:    {Reason: "no handler"},
+	}, state.Features)
+
+	// the features are decided again on the new connection
+	client.abilities.reset("conn-2", "gzip")
+	assert.False(t, client.FeatureDegraded(FeatureBatchRegisterInstance))
+	assert.Equal(t, AbilityUnknown, client.ServerAbility(AbilityPersistentInstanceByGrpc))
+}
+
+type noHandlerConnection struct {
+	IConnection
+	requests int
+}
+
+func (c *noHandlerConnection) request(request rpc_request.IRequest, timeoutMills int64, client *RpcClient) (rpc_response.IResponse, error) {
+	c.requests++
+	return &rpc_response.ErrorResponse{Response: &rpc_response.Response{ErrorCode: constant.NO_HANDLER, Message: "RequestHandler Not Found"}}, nil
+}
+
+func TestRequestUnsupported(t *testing.T) {
+	connection := &noHandlerConnection{}
+	client := &RpcClient{currentConnection: connection, rpcClientStatus: RUNNING}
+	request := rpc_request.NewBatchInstanceRequest("", "demo", "DEFAULT_GROUP", "batchRegisterInstance", nil)
+	_, err := client.request(request, 3000)
+	unsupported, ok := nacos_error.IsUnsupportedRequest(err)
+	assert.True(t, ok)
+	assert.Equal(t, "BatchInstanceRequest", unsupported.RequestType)
+	assert.Equal(t, 1, connection.requests)
+	assert.True(t, client.IsRunning())
+}
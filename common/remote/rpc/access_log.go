@@ -0,0 +1,67 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/accesslog"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_server"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_request"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_response"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+)
+
+func (r *RpcClient) logAccess(request rpc_request.IRequest, start time.Time, response rpc_response.IResponse, err error) {
+	if r.nacosServer == nil || !r.nacosServer.AccessLog().Sampled(request.GetRequestType()) {
+		return
+	}
+	entry := accesslog.Entry{
+		Op:      request.GetRequestType(),
+		Key:     rpcAccessKey(request),
+		Latency: time.Since(start),
+		Result:  nacos_server.AccessResult(err),
+	}
+	if err == nil && response != nil {
+		if !response.IsSuccess() {
+			entry.Result = strconv.Itoa(response.GetErrorCode())
+		}
+		entry.Bytes = len(util.ToJsonString(response))
+	}
+	if conn := r.currentConnection; conn != nil {
+		serverInfo := conn.getServerInfo()
+		entry.Server = fmt.Sprintf("%s:%d", serverInfo.serverIp, serverInfo.serverGrpcPort)
+	}
+	r.nacosServer.AccessLog().Log(entry)
+}
+
+// rpcAccessKey return the config key or the grouped service name of the request
+func rpcAccessKey(request rpc_request.IRequest) string {
+	switch req := request.(type) {
+	case rpc_request.IConfigRequest:
+		if len(req.GetDataId()) > 0 {
+			return util.GetConfigCacheKey(req.GetDataId(), req.GetGroup(), req.GetTenant())
+		}
+	case rpc_request.INamingRequest:
+		if len(req.GetServiceName()) > 0 {
+			return util.GetGroupName(req.GetServiceName(), req.GetGroupName())
+		}
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip" // register the gzip compressor
+	"google.golang.org/grpc/status"
+
+	nacos_grpc_service "github.com/nacos-group/nacos-sdk-go/v2/api/grpc"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_response"
+)
+
+// registeredCompressors return the compressors registered in grpc in preference order, the unknown ones are skipped
+func registeredCompressors(names []string) []string {
+	var compressors []string
+	for _, name := range names {
+		if encoding.GetCompressor(name) == nil {
+			logger.Warnf("grpc compressor %s is not registered, register it by encoding.RegisterCompressor", name)
+			continue
+		}
+		compressors = append(compressors, name)
+	}
+	return compressors
+}
+
+// negotiateCompressor check the server with the compressors in preference order, the first one accepted by the
+// server is used by the connection. The payloads are not compressed when none is accepted
+func negotiateCompressor(client nacos_grpc_service.RequestClient, compressors []string) (string, rpc_response.IResponse, error) {
+	for _, name := range compressors {
+		response, err := serverCheck(client, grpc.UseCompressor(name))
+		if status.Code(err) == codes.Unimplemented {
+			logger.Infof("grpc compressor %s is not accepted by server, err:%v", name, err)
+			continue
+		}
+		return name, response, err
+	}
+	response, err := serverCheck(client)
+	return "", response, err
+}
+
+// compressorOptions return the call options compressing the payloads with the compressor of connection
+func compressorOptions(compressor string) []grpc.CallOption {
+	if compressor == "" {
+		return nil
+	}
+	return []grpc.CallOption{grpc.UseCompressor(compressor)}
+}
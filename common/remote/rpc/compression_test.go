@@ -0,0 +1,70 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	nacos_grpc_service "github.com/nacos-group/nacos-sdk-go/v2/api/grpc"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_response"
+)
+
+// compressionServer accepts the payloads compressed by the compressors in accepted only
+type compressionServer struct {
+	accepted map[string]bool
+	tried    []string
+}
+
+func (s *compressionServer) Request(ctx context.Context, in *nacos_grpc_service.Payload, opts ...grpc.CallOption) (*nacos_grpc_service.Payload, error) {
+	var compressor string
+	for _, opt := range opts {
+		if o, ok := opt.(grpc.CompressorCallOption); ok {
+			compressor = o.CompressorType
+		}
+	}
+	s.tried = append(s.tried, compressor)
+	if compressor != "" && !s.accepted[compressor] {
+		return nil, status.Errorf(codes.Unimplemented, "grpc: Decompressor is not installed for grpc-encoding %q", compressor)
+	}
+	return &nacos_grpc_service.Payload{Body: &any.Any{Value: []byte(`{"resultCode":200,"success":true,"connectionId":"conn-1"}`)}}, nil
+}
+
+func TestNegotiateCompressor(t *testing.T) {
+	assert.Equal(t, []string{"gzip"}, registeredCompressors([]string{"zstd", "gzip"}))
+
+	server := &compressionServer{accepted: map[string]bool{"gzip": true}}
+	compressor, response, err := negotiateCompressor(server, []string{"zstd", "gzip"})
+	assert.Nil(t, err)
+	assert.Equal(t, "gzip", compressor)
+	assert.Equal(t, "conn-1", response.(*rpc_response.ServerCheckResponse).ConnectionId)
+	assert.Equal(t, []string{"zstd", "gzip"}, server.tried)
+
+	// the payloads are not compressed when the server accepts none
+	server = &compressionServer{}
+	compressor, _, err = negotiateCompressor(server, []string{"gzip"})
+	assert.Nil(t, err)
+	assert.Equal(t, "", compressor)
+	assert.Equal(t, []string{"gzip", ""}, server.tried)
+	assert.Nil(t, compressorOptions(""))
+}
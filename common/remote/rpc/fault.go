@@ -0,0 +1,66 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/chaos"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_request"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_response"
+)
+
+// the response types not named after the request types
+var faultResponseTypes = map[string]string{
+	"ConfigBatchListenRequest": "ConfigChangeBatchListenResponse",
+	"ServiceQueryRequest":      "QueryServiceResponse",
+}
+
+// injectFault apply the fault to the request, the response is decoded from the fault body like the one from server,
+// so it goes through the same handling. responded is false when the request should still be sent
+func injectFault(request rpc_request.IRequest, fault chaos.Fault, timeoutMills int64) (response rpc_response.IResponse, responded bool, err error) {
+	if err = fault.Wait(time.Duration(timeoutMills) * time.Millisecond); err != nil {
+		return nil, true, err
+	}
+	if !fault.Responds() {
+		return nil, false, nil
+	}
+	response = newFaultResponse(request.GetRequestType())
+	if fault.Malformed {
+		return response, true, json.Unmarshal([]byte(chaos.MalformedBody), response)
+	}
+	code := fault.Code
+	if code == 404 && request.GetRequestType() == chaos.OpConfigQuery {
+		code = constant.CONFIG_NOT_FOUND
+	}
+	body, _ := json.Marshal(rpc_response.Response{ResultCode: 500, ErrorCode: code, Message: "injected fault"})
+	return response, true, json.Unmarshal(body, response)
+}
+
+func newFaultResponse(requestType string) rpc_response.IResponse {
+	responseType, ok := faultResponseTypes[requestType]
+	if !ok {
+		responseType = strings.TrimSuffix(requestType, "Request") + "Response"
+	}
+	if newResponse, ok := rpc_response.ClientResponseMapping[responseType]; ok {
+		return newResponse()
+	}
+	return &rpc_response.ErrorResponse{Response: &rpc_response.Response{}}
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/chaos"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_request"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_response"
+)
+
+func TestInjectFault(t *testing.T) {
+	query := rpc_request.NewConfigQueryRequest("group", "dataId", "")
+	response, responded, err := injectFault(query, chaos.Fault{Code: 404}, 1000)
+	assert.True(t, responded)
+	assert.Nil(t, err)
+	queryResponse, ok := response.(*rpc_response.ConfigQueryResponse)
+	assert.True(t, ok)
+	assert.Equal(t, constant.CONFIG_NOT_FOUND, queryResponse.GetErrorCode())
+	assert.False(t, queryResponse.IsSuccess())
+
+	response, _, err = injectFault(rpc_request.NewConfigBatchListenRequest(1), chaos.Fault{Code: 403}, 1000)
+	assert.Nil(t, err)
+	_, ok = response.(*rpc_response.ConfigChangeBatchListenResponse)
+	assert.True(t, ok)
+	assert.Equal(t, constant.NO_RIGHT, response.GetErrorCode())
+
+	_, responded, err = injectFault(query, chaos.Fault{Malformed: true}, 1000)
+	assert.True(t, responded)
+	assert.Error(t, err)
+
+	_, responded, err = injectFault(query, chaos.Fault{Timeout: true}, 10)
+	assert.True(t, responded)
+	assert.Equal(t, chaos.ErrTimeout, err)
+
+	// the request is still sent after the latency
+	_, responded, err = injectFault(query, chaos.Fault{}, 1000)
+	assert.False(t, responded)
+	assert.Nil(t, err)
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"net"
 	"os"
 	"strconv"
 	"sync"
@@ -56,6 +57,10 @@ func NewGrpcClient(ctx context.Context, clientName string, nacosServer *nacos_se
 			mux:              new(sync.Mutex),
 		},
 	}
+	if nacosServer != nil {
+		// reported along with the connection setup, the labels of module override them
+		rpcClient.putAllLabels(nacosServer.ClientLabels())
+	}
 	rpcClient.RpcClient.lastActiveTimestamp.Store(time.Now())
 	rpcClient.executeClient = rpcClient
 	listeners := make([]IConnectionEventListener, 0, 8)
@@ -117,6 +122,13 @@ func (c *GrpcClient) createNewConnection(serverInfo ServerInfo) (*grpc.ClientCon
 	opts = append(opts, grpc.WithInsecure())
 	opts = append(opts, grpc.WithInitialWindowSize(getInitialWindowSize()))
 	opts = append(opts, grpc.WithInitialConnWindowSize(getInitialConnWindowSize()))
+	if c.nacosServer != nil {
+		if resolver := c.nacosServer.HostResolver(); resolver != nil {
+			opts = append(opts, grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+				return resolver.DialContext(ctx, "tcp", address)
+			}))
+		}
+	}
 	rpcPort := serverInfo.serverGrpcPort
 	if rpcPort == 0 {
 		rpcPort = serverInfo.serverPort + c.rpcPortOffset()
@@ -135,7 +147,11 @@ func (c *GrpcClient) connectToServer(serverInfo ServerInfo) (IConnection, error)
 	}
 
 	client = nacos_grpc_service.NewRequestClient(conn)
-	response, err := serverCheck(client)
+	var compressors []string
+	if c.nacosServer != nil {
+		compressors = registeredCompressors(c.nacosServer.GrpcCompressors())
+	}
+	compressor, response, err := negotiateCompressor(client, compressors)
 	if err != nil {
 		_ = conn.Close()
 		return nil, errors.Errorf("server check request failed , err:%v", err)
@@ -143,13 +159,20 @@ func (c *GrpcClient) connectToServer(serverInfo ServerInfo) (IConnection, error)
 	serverCheckResponse := response.(*rpc_response.ServerCheckResponse)
 
 	biStreamClient = nacos_grpc_service.NewBiRequestStreamClient(conn)
-	biStreamRequestClient, err := biStreamClient.RequestBiStream(context.Background())
+	biStreamRequestClient, err := biStreamClient.RequestBiStream(context.Background(), compressorOptions(compressor)...)
 	if err != nil {
 		return nil, errors.Errorf("create biStreamRequestClient failed , err:%v", err)
 	}
 	grpcConn := NewGrpcConnection(serverInfo, serverCheckResponse.ConnectionId, conn, client, biStreamRequestClient)
+	grpcConn.compressor = compressor
+	acked := c.abilities.reset(serverCheckResponse.ConnectionId, compressor)
 	c.bindBiRequestStream(biStreamRequestClient, grpcConn)
 	err = c.sendConnectionSetupRequest(grpcConn)
+	if err == nil && serverCheckResponse.SupportAbilityNegotiation {
+		c.waitSetupAck(grpcConn, acked)
+	} else {
+		time.Sleep(100 * time.Millisecond)
+	}
 	return grpcConn, err
 }
 
@@ -159,14 +182,27 @@ func (c *GrpcClient) sendConnectionSetupRequest(grpcConn *GrpcConnection) error
 	csr.Tenant = c.Tenant
 	csr.Labels = c.labels
 	csr.ClientAbilities = c.clientAbilities
+	csr.AbilityTable = clientAbilityTable()
 	err := grpcConn.biStreamSend(convertRequest(csr))
 	if err != nil {
 		logger.Warnf("send connectionSetupRequest error:%v", err)
 	}
-	time.Sleep(100 * time.Millisecond)
 	return err
 }
 
+// waitSetupAck wait for the abilities of server, the server is regarded as not negotiating abilities if it
+// doesn't acknowledge in time, so that the features degrade to the fallbacks
+func (c *GrpcClient) waitSetupAck(grpcConn *GrpcConnection, acked <-chan struct{}) {
+	timer := time.NewTimer(constant.ABILITY_NEGOTIATION_TIMEOUT)
+	defer timer.Stop()
+	select {
+	case <-acked:
+		logger.Infof("%s ability negotiation completed, connectionId=%s", c.name, grpcConn.getConnectionId())
+	case <-timer.C:
+		logger.Warnf("%s ability negotiation timeout, connectionId=%s", c.name, grpcConn.getConnectionId())
+	}
+}
+
 func (c *GrpcClient) getConnectionType() ConnectionType {
 	return GRPC
 }
@@ -208,12 +244,12 @@ func (c *GrpcClient) bindBiRequestStream(streamClient nacos_grpc_service.BiReque
 	}()
 }
 
-func serverCheck(client nacos_grpc_service.RequestClient) (rpc_response.IResponse, error) {
+func serverCheck(client nacos_grpc_service.RequestClient, opts ...grpc.CallOption) (rpc_response.IResponse, error) {
 	var response rpc_response.ServerCheckResponse
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(getInitialGrpcTimeout())*time.Millisecond)
 	defer cancel()
 	for i := 0; i <= 30; i++ {
-		payload, err := client.Request(ctx, convertRequest(rpc_request.NewServerCheckRequest()))
+		payload, err := client.Request(ctx, convertRequest(rpc_request.NewServerCheckRequest()), opts...)
 		if err != nil {
 			return nil, err
 		}
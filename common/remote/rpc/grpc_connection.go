@@ -35,6 +35,7 @@ type GrpcConnection struct {
 	*Connection
 	client         nacos_grpc_service.RequestClient
 	biStreamClient nacos_grpc_service.BiRequestStream_RequestBiStreamClient
+	compressor     string // the compressor negotiated with server, empty means not compressed
 }
 
 func NewGrpcConnection(serverInfo ServerInfo, connectionId string, conn *grpc.ClientConn,
@@ -54,7 +55,7 @@ func (g *GrpcConnection) request(request rpc_request.IRequest, timeoutMills int6
 	p := convertRequest(request)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutMills)*time.Millisecond)
 	defer cancel()
-	responsePayload, err := g.client.Request(ctx, p)
+	responsePayload, err := g.client.Request(ctx, p, compressorOptions(g.compressor)...)
 	if err != nil {
 		return nil, err
 	}
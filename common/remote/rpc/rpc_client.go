@@ -28,6 +28,7 @@ import (
 
 	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_server"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_request"
 	"github.com/nacos-group/nacos-sdk-go/v2/common/remote/rpc/rpc_response"
@@ -108,6 +109,7 @@ type RpcClient struct {
 	serverRequestHandlerMapping sync.Map
 	mux                         *sync.Mutex
 	clientAbilities             rpc_request.ClientAbilities
+	abilities                   abilityTable
 	Tenant                      string
 }
 
@@ -147,6 +149,14 @@ func getClient(clientName string) IRpcClient {
 	return clientMap[clientName]
 }
 
+// LookupClient return the client created with clientName, the client is not created when it's absent
+func LookupClient(clientName string) (IRpcClient, bool) {
+	cMux.Lock()
+	defer cMux.Unlock()
+	client, ok := clientMap[clientName]
+	return client, ok
+}
+
 func CreateClient(ctx context.Context, clientName string, connectionType ConnectionType, labels map[string]string, nacosServer *nacos_server.NacosServer) (IRpcClient, error) {
 	cMux.Lock()
 	defer cMux.Unlock()
@@ -181,6 +191,7 @@ func (r *RpcClient) Start() {
 		}
 	}()
 
+	serverChange := r.nacosServer.ServerChangeSignal()
 	go func() {
 		timer := time.NewTimer(5 * time.Second)
 		for {
@@ -204,7 +215,7 @@ func (r *RpcClient) Start() {
 				r.reconnect(rc.serverInfo, rc.onRequestFail)
 			case <-timer.C:
 				r.healthCheck(timer)
-			case <-r.nacosServer.ServerSrcChangeSignal:
+			case <-serverChange:
 				r.notifyServerSrvChange()
 			case <-r.ctx.Done():
 				return
@@ -273,6 +284,11 @@ func (r *RpcClient) registerServerRequestHandlers() {
 	r.RegisterServerRequestHandler(func() rpc_request.IRequest {
 		return &rpc_request.ClientDetectionRequest{InternalRequest: rpc_request.NewInternalRequest()}
 	}, &ClientDetectionRequestHandler{})
+
+	// register setup ack request of ability negotiation.
+	r.RegisterServerRequestHandler(func() rpc_request.IRequest {
+		return &rpc_request.SetupAckRequest{InternalRequest: rpc_request.NewInternalRequest()}
+	}, &SetupAckRequestHandler{})
 }
 
 func (r *RpcClient) Shutdown() {
@@ -478,6 +494,20 @@ func (c *ConnectionEvent) toString() string {
 }
 
 func (r *RpcClient) Request(request rpc_request.IRequest, timeoutMills int64) (rpc_response.IResponse, error) {
+	start := time.Now()
+	response, err := r.request(request, timeoutMills)
+	r.logAccess(request, start, response, err)
+	return response, err
+}
+
+func (r *RpcClient) request(request rpc_request.IRequest, timeoutMills int64) (rpc_response.IResponse, error) {
+	if r.nacosServer != nil {
+		if fault, ok := r.nacosServer.Fault(request.GetRequestType()); ok {
+			if response, responded, err := injectFault(request, fault, timeoutMills); responded {
+				return response, err
+			}
+		}
+	}
 	retryTimes := 0
 	start := util.CurrentMillis()
 	var currentErr error
@@ -493,6 +523,10 @@ func (r *RpcClient) Request(request rpc_request.IRequest, timeoutMills int64) (r
 			continue
 		}
 		if resp, ok := response.(*rpc_response.ErrorResponse); ok {
+			if resp.GetErrorCode() == constant.NO_HANDLER {
+				// the server doesn't handle the request type, neither retrying nor switching server helps
+				return nil, &nacos_error.UnsupportedRequestError{RequestType: request.GetRequestType(), Message: resp.GetMessage()}
+			}
 			if resp.GetErrorCode() == constant.UN_REGISTER {
 				r.mux.Lock()
 				if atomic.CompareAndSwapInt32((*int32)(&r.rpcClientStatus), (int32)(RUNNING), (int32)(UNHEALTHY)) {
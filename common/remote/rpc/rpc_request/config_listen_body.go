@@ -0,0 +1,90 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc_request
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"sync"
+)
+
+// listenBodyPool keep the buffers used to build the body of batch listen requests, the request is sent
+// on every listen cycle and carries all listened configs of a task, so the buffers are reused among cycles
+var listenBodyPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// GetBody build the same json as json.Marshal does, writing into a pooled buffer
+// instead of reflecting on each listen context
+func (r *ConfigBatchListenRequest) GetBody(request IRequest) string {
+	buf := listenBodyPool.Get().(*bytes.Buffer)
+	defer listenBodyPool.Put(buf)
+	buf.Reset()
+	buf.Grow(128 + len(r.ConfigListenContexts)*96)
+
+	buf.WriteString(`{"requestId":`)
+	writeJsonString(buf, r.RequestId)
+	buf.WriteString(`,"group":`)
+	writeJsonString(buf, r.Group)
+	buf.WriteString(`,"dataId":`)
+	writeJsonString(buf, r.DataId)
+	buf.WriteString(`,"tenant":`)
+	writeJsonString(buf, r.Tenant)
+	buf.WriteString(`,"module":`)
+	writeJsonString(buf, r.Module)
+	buf.WriteString(`,"listen":`)
+	buf.WriteString(strconv.FormatBool(r.Listen))
+	buf.WriteString(`,"configListenContexts":`)
+	if r.ConfigListenContexts == nil {
+		buf.WriteString("null}")
+		return buf.String()
+	}
+	buf.WriteByte('[')
+	for i, c := range r.ConfigListenContexts {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`{"group":`)
+		writeJsonString(buf, c.Group)
+		buf.WriteString(`,"md5":`)
+		writeJsonString(buf, c.Md5)
+		buf.WriteString(`,"dataId":`)
+		writeJsonString(buf, c.DataId)
+		buf.WriteString(`,"tenant":`)
+		writeJsonString(buf, c.Tenant)
+		buf.WriteByte('}')
+	}
+	buf.WriteString("]}")
+	return buf.String()
+}
+
+// writeJsonString write s as json string, the strings which need escaping are rare and left to encoding/json
+func writeJsonString(buf *bytes.Buffer, s string) {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c >= 0x7f || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' {
+			b, _ := json.Marshal(s)
+			buf.Write(b)
+			return
+		}
+	}
+	buf.WriteByte('"')
+	buf.WriteString(s)
+	buf.WriteByte('"')
+}
@@ -0,0 +1,57 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc_request
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
+func TestConfigBatchListenRequest_GetBody(t *testing.T) {
+	request := NewConfigBatchListenRequest(3)
+	request.RequestId = "42"
+	request.Tenant = "public"
+	request.ConfigListenContexts = append(request.ConfigListenContexts,
+		model.ConfigListenContext{Group: "DEFAULT_GROUP", Md5: "fe01ce2a7fbac8fafaed7c982a04e229", DataId: "app.yaml", Tenant: "public"},
+		model.ConfigListenContext{Group: "组", DataId: "quote\\\"<&>\n"},
+	)
+	expected, err := json.Marshal(request)
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), request.GetBody(request))
+
+	request.ConfigListenContexts = nil
+	expected, _ = json.Marshal(request)
+	assert.Equal(t, string(expected), request.GetBody(request))
+}
+
+func BenchmarkConfigBatchListenRequest_GetBody(b *testing.B) {
+	request := NewConfigBatchListenRequest(3000)
+	for i := 0; i < 3000; i++ {
+		request.ConfigListenContexts = append(request.ConfigListenContexts, model.ConfigListenContext{
+			Group: "DEFAULT_GROUP", Md5: "fe01ce2a7fbac8fafaed7c982a04e229", DataId: "data-" + strconv.Itoa(i), Tenant: "public"})
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = request.GetBody(request)
+	}
+}
@@ -45,6 +45,18 @@ func NewNamingRequest(namespace, serviceName, groupName string) *NamingRequest {
 	}
 }
 
+func (r *NamingRequest) GetNamespace() string {
+	return r.Namespace
+}
+
+func (r *NamingRequest) GetServiceName() string {
+	return r.ServiceName
+}
+
+func (r *NamingRequest) GetGroupName() string {
+	return r.GroupName
+}
+
 func (r *NamingRequest) GetStringToSign() string {
 	data := strconv.FormatInt(time.Now().Unix()*1000, 10)
 	if r.ServiceName != "" || r.GroupName != "" {
@@ -89,6 +101,26 @@ func (r *BatchInstanceRequest) GetRequestType() string {
 	return "BatchInstanceRequest"
 }
 
+// PersistentInstanceRequest register or deregister the persistent instance, it's supported by the server
+// with the ability supportPersistentInstanceByGrpc
+type PersistentInstanceRequest struct {
+	*NamingRequest
+	Type     string         `json:"type"`
+	Instance model.Instance `json:"instance"`
+}
+
+func NewPersistentInstanceRequest(namespace, serviceName, groupName, Type string, instance model.Instance) *PersistentInstanceRequest {
+	return &PersistentInstanceRequest{
+		NamingRequest: NewNamingRequest(namespace, serviceName, groupName),
+		Type:          Type,
+		Instance:      instance,
+	}
+}
+
+func (r *PersistentInstanceRequest) GetRequestType() string {
+	return "PersistentInstanceRequest"
+}
+
 type NotifySubscriberRequest struct {
 	*NamingRequest
 	ServiceInfo model.Service `json:"serviceInfo"`
@@ -141,6 +173,7 @@ type ServiceQueryRequest struct {
 	Cluster     string `json:"cluster"`
 	HealthyOnly bool   `json:"healthyOnly"`
 	UdpPort     int    `json:"udpPort"`
+	Selector    string `json:"selector,omitempty"`
 }
 
 func NewServiceQueryRequest(namespace, serviceName, groupName, cluster string, healthyOnly bool, udpPort int) *ServiceQueryRequest {
@@ -38,6 +38,12 @@ type IConfigRequest interface {
 	GetTenant() string
 }
 
+type INamingRequest interface {
+	GetNamespace() string
+	GetServiceName() string
+	GetGroupName() string
+}
+
 func (r *Request) PutAllHeaders(headers map[string]string) {
 	for k, v := range headers {
 		r.Headers[k] = v
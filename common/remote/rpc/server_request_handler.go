@@ -79,6 +79,25 @@ func (c *ClientDetectionRequestHandler) RequestReply(request rpc_request.IReques
 	return nil
 }
 
+// SetupAckRequestHandler record the abilities of server negotiated on connection setup
+type SetupAckRequestHandler struct {
+}
+
+func (c *SetupAckRequestHandler) Name() string {
+	return "SetupAckRequestHandler"
+}
+
+func (c *SetupAckRequestHandler) RequestReply(request rpc_request.IRequest, rpcClient *RpcClient) rpc_response.IResponse {
+	setupAckRequest, ok := request.(*rpc_request.SetupAckRequest)
+	if ok {
+		rpcClient.abilities.setServer(setupAckRequest.AbilityTable)
+		return &rpc_response.SetupAckResponse{
+			Response: &rpc_response.Response{ResultCode: constant.RESPONSE_CODE_SUCCESS},
+		}
+	}
+	return nil
+}
+
 type NamingPushRequestHandler struct {
 	ServiceInfoHolder *naming_cache.ServiceInfoHolder
 }
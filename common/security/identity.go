@@ -0,0 +1,179 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package security
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+)
+
+const identityRetryInterval = 5 * time.Second
+
+type identityToken struct {
+	value    string
+	expireAt time.Time
+}
+
+// IdentityClient keeps a workload identity token fetched from IdentityConfig.Provider
+// and rotates it before it expires.
+type IdentityClient struct {
+	header        string
+	prefix        string
+	refreshWindow time.Duration
+	provider      func(ctx context.Context) (string, time.Time, error)
+	token         atomic.Value
+	refreshMutex  sync.Mutex
+}
+
+func NewIdentityClient(identityCfg *constant.IdentityConfig) (*IdentityClient, error) {
+	if identityCfg == nil || identityCfg.Provider == nil {
+		return nil, errors.New("identity provider can not be nil")
+	}
+	client := &IdentityClient{
+		header:        identityCfg.Header,
+		prefix:        identityCfg.TokenPrefix,
+		refreshWindow: identityCfg.RefreshWindow,
+		provider:      identityCfg.Provider,
+	}
+	if client.header == "" {
+		client.header = constant.IDENTITY_HEADER
+		if client.prefix == "" {
+			client.prefix = constant.IDENTITY_TOKEN_PREFIX
+		}
+	}
+	return client, nil
+}
+
+// GetToken returns the current token, empty if none has been fetched or it has expired.
+func (ic *IdentityClient) GetToken() string {
+	v, ok := ic.token.Load().(identityToken)
+	if !ok || (!v.expireAt.IsZero() && time.Now().After(v.expireAt)) {
+		return ""
+	}
+	return v.value
+}
+
+// InjectHeaders puts the identity header into headers when a valid token is present.
+func (ic *IdentityClient) InjectHeaders(headers map[string]string) {
+	if token := ic.GetToken(); token != "" {
+		headers[ic.header] = ic.prefix + token
+	}
+}
+
+// Refresh fetches a new token from the provider.
+func (ic *IdentityClient) Refresh(ctx context.Context) error {
+	ic.refreshMutex.Lock()
+	defer ic.refreshMutex.Unlock()
+	value, expireAt, err := ic.provider(ctx)
+	if err != nil {
+		return errors.Wrap(err, "fetch identity token failed")
+	}
+	if value == "" {
+		return errors.New("identity provider returned empty token")
+	}
+	if expireAt.IsZero() {
+		expireAt, _ = ParseJWTExpiry(value)
+	}
+	ic.token.Store(identityToken{value: value, expireAt: expireAt})
+	return nil
+}
+
+// AutoRefresh rotates the token in background until ctx is done.
+func (ic *IdentityClient) AutoRefresh(ctx context.Context) {
+	go func() {
+		timer := time.NewTimer(ic.nextRefresh())
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+				if err := ic.Refresh(ctx); err != nil {
+					logger.Errorf("refresh identity token has error %+v", err)
+					timer.Reset(identityRetryInterval)
+				} else {
+					timer.Reset(ic.nextRefresh())
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (ic *IdentityClient) nextRefresh() time.Duration {
+	v, ok := ic.token.Load().(identityToken)
+	if !ok {
+		return identityRetryInterval
+	}
+	if v.expireAt.IsZero() {
+		// the lifetime is unknown, re-read the token periodically
+		return time.Minute
+	}
+	ttl := time.Until(v.expireAt)
+	window := ic.refreshWindow
+	if window <= 0 {
+		window = ttl / 10
+	}
+	if next := ttl - window; next > 0 {
+		return next
+	}
+	return identityRetryInterval
+}
+
+// ParseJWTExpiry reads the exp claim of a JWT without verifying it.
+func ParseJWTExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, errors.New("token is not a jwt")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "decode jwt payload failed")
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, errors.Wrap(err, "unmarshal jwt claims failed")
+	}
+	if claims.Exp == 0 {
+		return time.Time{}, errors.New("jwt has no exp claim")
+	}
+	return time.Unix(claims.Exp, 0), nil
+}
+
+// FileTokenProvider reads the token from a file, such as the JWT-SVID written by
+// spiffe-helper or a projected service account token, on every refresh.
+func FileTokenProvider(path string) func(ctx context.Context) (string, time.Time, error) {
+	return func(ctx context.Context) (string, time.Time, error) {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		return strings.TrimSpace(string(b)), time.Time{}, nil
+	}
+}
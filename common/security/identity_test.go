@@ -0,0 +1,97 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package security
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+)
+
+func buildJWT(exp int64) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"spiffe://example.org/demo","exp":%d}`, exp)))
+	return "eyJhbGciOiJub25lIn0." + payload + ".sig"
+}
+
+func TestParseJWTExpiry(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+	expireAt, err := ParseJWTExpiry(buildJWT(exp))
+	assert.Nil(t, err)
+	assert.Equal(t, exp, expireAt.Unix())
+
+	_, err = ParseJWTExpiry("opaque-token")
+	assert.NotNil(t, err)
+}
+
+func TestIdentityClient_InjectHeaders(t *testing.T) {
+	tokens := []string{buildJWT(time.Now().Add(time.Hour).Unix()), "rotated"}
+	calls := 0
+	client, err := NewIdentityClient(&constant.IdentityConfig{
+		Provider: func(ctx context.Context) (string, time.Time, error) {
+			token := tokens[calls]
+			calls++
+			return token, time.Time{}, nil
+		},
+	})
+	assert.Nil(t, err)
+
+	headers := map[string]string{}
+	client.InjectHeaders(headers)
+	assert.Empty(t, headers)
+
+	assert.Nil(t, client.Refresh(context.Background()))
+	client.InjectHeaders(headers)
+	assert.Equal(t, constant.IDENTITY_TOKEN_PREFIX+tokens[0], headers[constant.IDENTITY_HEADER])
+	next := client.nextRefresh()
+	assert.True(t, next > 50*time.Minute && next < time.Hour)
+
+	assert.Nil(t, client.Refresh(context.Background()))
+	client.InjectHeaders(headers)
+	assert.Equal(t, constant.IDENTITY_TOKEN_PREFIX+"rotated", headers[constant.IDENTITY_HEADER])
+}
+
+func TestIdentityClient_Expired(t *testing.T) {
+	client, err := NewIdentityClient(&constant.IdentityConfig{
+		Header: "X-Identity",
+		Provider: func(ctx context.Context) (string, time.Time, error) {
+			return "token", time.Now().Add(-time.Second), nil
+		},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, client.Refresh(context.Background()))
+	assert.Equal(t, "", client.GetToken())
+	assert.Equal(t, identityRetryInterval, client.nextRefresh())
+}
+
+func TestFileTokenProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwt_svid.token")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("svid\n"), 0600))
+	client, err := NewIdentityClient(&constant.IdentityConfig{Header: "X-Identity", Provider: FileTokenProvider(path)})
+	assert.Nil(t, err)
+	assert.Nil(t, client.Refresh(context.Background()))
+	headers := map[string]string{}
+	client.InjectHeaders(headers)
+	assert.Equal(t, "svid", headers["X-Identity"])
+}
@@ -114,7 +114,7 @@ func (ac *AuthClient) Login() (bool, error) {
 
 func (ac *AuthClient) login(server constant.ServerConfig) (bool, error) {
 	if ac.username != "" {
-		contextPath := server.ContextPath
+		contextPath := server.ContextPathOf(constant.API_GROUP_AUTH)
 
 		if !strings.HasPrefix(contextPath, "/") {
 			contextPath = "/" + contextPath
@@ -129,6 +129,7 @@ func (ac *AuthClient) login(server constant.ServerConfig) (bool, error) {
 		}
 
 		reqUrl := server.Scheme + "://" + server.IpAddr + ":" + strconv.FormatInt(int64(server.Port), 10) + contextPath + "/v1/auth/users/login"
+		reqUrl = server.RewriteUrl(constant.API_GROUP_AUTH, reqUrl)
 
 		header := http.Header{
 			"content-type": []string{"application/x-www-form-urlencoded"},
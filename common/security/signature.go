@@ -0,0 +1,144 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package security
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrContentUnsigned  = errors.New("content is not signed")
+	ErrSignatureInvalid = errors.New("content signature verification failed")
+)
+
+// ContentSigner signs config content with a private key
+type ContentSigner struct {
+	key crypto.Signer
+}
+
+// ContentVerifier verifies config content against a set of trusted public keys
+type ContentVerifier struct {
+	keys []crypto.PublicKey
+}
+
+// NewContentSigner load a PEM encoded PKCS#8 private key(ed25519, ecdsa or rsa) from file
+func NewContentSigner(privateKeyFile string) (*ContentSigner, error) {
+	b, err := ioutil.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.Errorf("no PEM data found in %s", privateKeyFile)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse private key %s failed", privateKeyFile)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.Errorf("unsupported private key type %T", key)
+	}
+	return &ContentSigner{key: signer}, nil
+}
+
+// NewContentVerifier load PEM encoded PKIX public keys from files
+func NewContentVerifier(publicKeyFiles []string) (*ContentVerifier, error) {
+	verifier := &ContentVerifier{}
+	for _, f := range publicKeyFiles {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		for block, rest := pem.Decode(b); block != nil; block, rest = pem.Decode(rest) {
+			key, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse public key %s failed", f)
+			}
+			verifier.keys = append(verifier.keys, key)
+		}
+	}
+	if len(verifier.keys) == 0 {
+		return nil, errors.New("no public key found for content verification")
+	}
+	return verifier, nil
+}
+
+// Sign returns the base64 encoded signature of the content bound to the namespace, dataId and group
+func (s *ContentSigner) Sign(tenant, dataId, group, content string) (string, error) {
+	payload := signPayload(tenant, dataId, group, content)
+	var (
+		sig []byte
+		err error
+	)
+	if _, ok := s.key.(ed25519.PrivateKey); ok {
+		sig, err = s.key.Sign(rand.Reader, payload, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(payload)
+		sig, err = s.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// Verify check the base64 encoded signature against all trusted public keys
+func (v *ContentVerifier) Verify(tenant, dataId, group, content, signature string) error {
+	if signature == "" {
+		return ErrContentUnsigned
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return errors.Wrap(ErrSignatureInvalid, err.Error())
+	}
+	payload := signPayload(tenant, dataId, group, content)
+	digest := sha256.Sum256(payload)
+	for _, key := range v.keys {
+		switch k := key.(type) {
+		case ed25519.PublicKey:
+			if ed25519.Verify(k, payload, sig) {
+				return nil
+			}
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(k, digest[:], sig) {
+				return nil
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil {
+				return nil
+			}
+		}
+	}
+	return ErrSignatureInvalid
+}
+
+// signPayload bind the content to the namespace as well, so the signed content is not replayed to another namespace
+func signPayload(tenant, dataId, group, content string) []byte {
+	return []byte(tenant + "\n" + group + "\n" + dataId + "\n" + content)
+}
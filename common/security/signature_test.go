@@ -0,0 +1,69 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package security
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeKeyPair(t *testing.T, dir string, private, public interface{}) (string, string) {
+	privateBytes, err := x509.MarshalPKCS8PrivateKey(private)
+	assert.Nil(t, err)
+	publicBytes, err := x509.MarshalPKIXPublicKey(public)
+	assert.Nil(t, err)
+	privateFile := filepath.Join(dir, "private.pem")
+	publicFile := filepath.Join(dir, "public.pem")
+	assert.Nil(t, ioutil.WriteFile(privateFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateBytes}), 0600))
+	assert.Nil(t, ioutil.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes}), 0600))
+	return privateFile, publicFile
+}
+
+func TestContentSignature(t *testing.T) {
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	ecPrivate, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	keys := map[string][2]interface{}{
+		"ed25519": {edPrivate, edPublic},
+		"ecdsa":   {ecPrivate, &ecPrivate.PublicKey},
+	}
+	for name, pair := range keys {
+		t.Run(name, func(t *testing.T) {
+			privateFile, publicFile := writeKeyPair(t, t.TempDir(), pair[0], pair[1])
+			signer, err := NewContentSigner(privateFile)
+			assert.Nil(t, err)
+			verifier, err := NewContentVerifier([]string{publicFile})
+			assert.Nil(t, err)
+
+			signature, err := signer.Sign("tenant", "dataId", "group", "content")
+			assert.Nil(t, err)
+			assert.Nil(t, verifier.Verify("tenant", "dataId", "group", "content", signature))
+			assert.Equal(t, ErrSignatureInvalid, verifier.Verify("tenant", "dataId", "group", "tampered", signature))
+			assert.Equal(t, ErrSignatureInvalid, verifier.Verify("tenant", "otherId", "group", "content", signature))
+			assert.Equal(t, ErrSignatureInvalid, verifier.Verify("otherTenant", "dataId", "group", "content", signature))
+			assert.Equal(t, ErrContentUnsigned, verifier.Verify("tenant", "dataId", "group", "content", ""))
+		})
+	}
+}
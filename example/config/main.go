@@ -132,4 +132,15 @@ func main() {
 		PageSize: 10,
 	})
 	fmt.Printf("Search config:%+v \n", searchPage)
+
+	it := client.SearchConfigIterator(vo.SearchConfigParam{
+		Search:   "blur",
+		PageSize: 100,
+	})
+	for it.Next() {
+		fmt.Printf("Iterate config dataId:%s group:%s type:%s \n", it.Item().DataId, it.Item().Group, it.Item().Type)
+	}
+	if it.Err() != nil {
+		fmt.Printf("Iterate config failed:%+v \n", it.Err())
+	}
 }
@@ -21,36 +21,97 @@
 package mock
 
 import (
+	context "context"
+	io "io"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	config_client "github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	model "github.com/nacos-group/nacos-sdk-go/v2/model"
 	vo "github.com/nacos-group/nacos-sdk-go/v2/vo"
 )
 
-// MockIConfigClient is a mock of IConfigClient interface
+// MockIConfigClient is a mock of IConfigClient interface.
 type MockIConfigClient struct {
 	ctrl     *gomock.Controller
 	recorder *MockIConfigClientMockRecorder
 }
 
-// MockIConfigClientMockRecorder is the mock recorder for MockIConfigClient
+// MockIConfigClientMockRecorder is the mock recorder for MockIConfigClient.
 type MockIConfigClientMockRecorder struct {
 	mock *MockIConfigClient
 }
 
-// NewMockIConfigClient creates a new mock instance
+// NewMockIConfigClient creates a new mock instance.
 func NewMockIConfigClient(ctrl *gomock.Controller) *MockIConfigClient {
 	mock := &MockIConfigClient{ctrl: ctrl}
 	mock.recorder = &MockIConfigClientMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockIConfigClient) EXPECT() *MockIConfigClientMockRecorder {
 	return m.recorder
 }
 
-// GetConfig mocks base method
+// CancelListenConfig mocks base method.
+func (m *MockIConfigClient) CancelListenConfig(params vo.ConfigParam) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelListenConfig", params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelListenConfig indicates an expected call of CancelListenConfig.
+func (mr *MockIConfigClientMockRecorder) CancelListenConfig(params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelListenConfig", reflect.TypeOf((*MockIConfigClient)(nil).CancelListenConfig), params)
+}
+
+// CloseClient mocks base method.
+func (m *MockIConfigClient) CloseClient() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CloseClient")
+}
+
+// CloseClient indicates an expected call of CloseClient.
+func (mr *MockIConfigClientMockRecorder) CloseClient() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseClient", reflect.TypeOf((*MockIConfigClient)(nil).CloseClient))
+}
+
+// DeleteConfig mocks base method.
+func (m *MockIConfigClient) DeleteConfig(param vo.ConfigParam) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteConfig", param)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteConfig indicates an expected call of DeleteConfig.
+func (mr *MockIConfigClientMockRecorder) DeleteConfig(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteConfig", reflect.TypeOf((*MockIConfigClient)(nil).DeleteConfig), param)
+}
+
+// GetBinaryConfig mocks base method.
+func (m *MockIConfigClient) GetBinaryConfig(param vo.ConfigParam) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBinaryConfig", param)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBinaryConfig indicates an expected call of GetBinaryConfig.
+func (mr *MockIConfigClientMockRecorder) GetBinaryConfig(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBinaryConfig", reflect.TypeOf((*MockIConfigClient)(nil).GetBinaryConfig), param)
+}
+
+// GetConfig mocks base method.
 func (m *MockIConfigClient) GetConfig(param vo.ConfigParam) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetConfig", param)
@@ -59,93 +120,360 @@ func (m *MockIConfigClient) GetConfig(param vo.ConfigParam) (string, error) {
 	return ret0, ret1
 }
 
-// GetConfig indicates an expected call of GetConfig
+// GetConfig indicates an expected call of GetConfig.
 func (mr *MockIConfigClientMockRecorder) GetConfig(param interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfig", reflect.TypeOf((*MockIConfigClient)(nil).GetConfig), param)
 }
 
-// PublishConfig mocks base method
-func (m *MockIConfigClient) PublishConfig(param vo.ConfigParam) (bool, error) {
+// GetConfigHistory mocks base method.
+func (m *MockIConfigClient) GetConfigHistory(param vo.ConfigParam, at time.Time) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PublishConfig", param)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "GetConfigHistory", param, at)
+	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// PublishConfig indicates an expected call of PublishConfig
-func (mr *MockIConfigClientMockRecorder) PublishConfig(param interface{}) *gomock.Call {
+// GetConfigHistory indicates an expected call of GetConfigHistory.
+func (mr *MockIConfigClientMockRecorder) GetConfigHistory(param, at interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishConfig", reflect.TypeOf((*MockIConfigClient)(nil).PublishConfig), param)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigHistory", reflect.TypeOf((*MockIConfigClient)(nil).GetConfigHistory), param, at)
 }
 
-// DeleteConfig mocks base method
-func (m *MockIConfigClient) DeleteConfig(param vo.ConfigParam) (bool, error) {
+// GetConfigStream mocks base method.
+func (m *MockIConfigClient) GetConfigStream(param vo.ConfigParam) (io.ReadCloser, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteConfig", param)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "GetConfigStream", param)
+	ret0, _ := ret[0].(io.ReadCloser)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// DeleteConfig indicates an expected call of DeleteConfig
-func (mr *MockIConfigClientMockRecorder) DeleteConfig(param interface{}) *gomock.Call {
+// GetConfigStream indicates an expected call of GetConfigStream.
+func (mr *MockIConfigClientMockRecorder) GetConfigStream(param interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteConfig", reflect.TypeOf((*MockIConfigClient)(nil).DeleteConfig), param)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigStream", reflect.TypeOf((*MockIConfigClient)(nil).GetConfigStream), param)
 }
 
-// ListenConfig mocks base method
-func (m *MockIConfigClient) ListenConfig(params []vo.ConfigParam) error {
+// GetListenStatus mocks base method.
+func (m *MockIConfigClient) GetListenStatus() []model.ConfigListenStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetListenStatus")
+	ret0, _ := ret[0].([]model.ConfigListenStatus)
+	return ret0
+}
+
+// GetListenStatus indicates an expected call of GetListenStatus.
+func (mr *MockIConfigClientMockRecorder) GetListenStatus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListenStatus", reflect.TypeOf((*MockIConfigClient)(nil).GetListenStatus))
+}
+
+// GetNamespaceChecksum mocks base method.
+func (m *MockIConfigClient) GetNamespaceChecksum(namespace, group string) (*model.ConfigManifest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNamespaceChecksum", namespace, group)
+	ret0, _ := ret[0].(*model.ConfigManifest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNamespaceChecksum indicates an expected call of GetNamespaceChecksum.
+func (mr *MockIConfigClientMockRecorder) GetNamespaceChecksum(namespace, group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNamespaceChecksum", reflect.TypeOf((*MockIConfigClient)(nil).GetNamespaceChecksum), namespace, group)
+}
+
+// GetProperties mocks base method.
+func (m *MockIConfigClient) GetProperties(param vo.PropertiesParam) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProperties", param)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProperties indicates an expected call of GetProperties.
+func (mr *MockIConfigClientMockRecorder) GetProperties(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProperties", reflect.TypeOf((*MockIConfigClient)(nil).GetProperties), param)
+}
+
+// GroupOf mocks base method.
+func (m *MockIConfigClient) GroupOf(dataId, group string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GroupOf", dataId, group)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GroupOf indicates an expected call of GroupOf.
+func (mr *MockIConfigClientMockRecorder) GroupOf(dataId, group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GroupOf", reflect.TypeOf((*MockIConfigClient)(nil).GroupOf), dataId, group)
+}
+
+// InstanceId mocks base method.
+func (m *MockIConfigClient) InstanceId() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstanceId")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// InstanceId indicates an expected call of InstanceId.
+func (mr *MockIConfigClientMockRecorder) InstanceId() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstanceId", reflect.TypeOf((*MockIConfigClient)(nil).InstanceId))
+}
+
+// ListConfigHistory mocks base method.
+func (m *MockIConfigClient) ListConfigHistory(param vo.ConfigParam) ([]model.ConfigSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListConfigHistory", param)
+	ret0, _ := ret[0].([]model.ConfigSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListConfigHistory indicates an expected call of ListConfigHistory.
+func (mr *MockIConfigClientMockRecorder) ListConfigHistory(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConfigHistory", reflect.TypeOf((*MockIConfigClient)(nil).ListConfigHistory), param)
+}
+
+// ListConfigKeys mocks base method.
+func (m *MockIConfigClient) ListConfigKeys(param vo.ListConfigKeysParam) (*model.ConfigKeyPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListConfigKeys", param)
+	ret0, _ := ret[0].(*model.ConfigKeyPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListConfigKeys indicates an expected call of ListConfigKeys.
+func (mr *MockIConfigClientMockRecorder) ListConfigKeys(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConfigKeys", reflect.TypeOf((*MockIConfigClient)(nil).ListConfigKeys), param)
+}
+
+// ListenConfig mocks base method.
+func (m *MockIConfigClient) ListenConfig(params vo.ConfigParam) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListenConfig", params)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// ListenConfig indicates an expected call of ListenConfig
+// ListenConfig indicates an expected call of ListenConfig.
 func (mr *MockIConfigClientMockRecorder) ListenConfig(params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListenConfig", reflect.TypeOf((*MockIConfigClient)(nil).ListenConfig), params)
 }
 
-// AddConfigToListen mocks base method
-func (m *MockIConfigClient) AddConfigToListen(params []vo.ConfigParam) error {
+// ListenConfigPath mocks base method.
+func (m *MockIConfigClient) ListenConfigPath(params vo.ConfigParam, path string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddConfigToListen", params)
+	ret := m.ctrl.Call(m, "ListenConfigPath", params, path)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// AddConfigToListen indicates an expected call of AddConfigToListen
-func (mr *MockIConfigClientMockRecorder) AddConfigToListen(params interface{}) *gomock.Call {
+// ListenConfigPath indicates an expected call of ListenConfigPath.
+func (mr *MockIConfigClientMockRecorder) ListenConfigPath(params, path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListenConfigPath", reflect.TypeOf((*MockIConfigClient)(nil).ListenConfigPath), params, path)
+}
+
+// ListenConfigWithContext mocks base method.
+func (m *MockIConfigClient) ListenConfigWithContext(ctx context.Context, params vo.ConfigParam) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListenConfigWithContext", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListenConfigWithContext indicates an expected call of ListenConfigWithContext.
+func (mr *MockIConfigClientMockRecorder) ListenConfigWithContext(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListenConfigWithContext", reflect.TypeOf((*MockIConfigClient)(nil).ListenConfigWithContext), ctx, params)
+}
+
+// LocalConfigManifest mocks base method.
+func (m *MockIConfigClient) LocalConfigManifest(namespace, group string) *model.ConfigManifest {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LocalConfigManifest", namespace, group)
+	ret0, _ := ret[0].(*model.ConfigManifest)
+	return ret0
+}
+
+// LocalConfigManifest indicates an expected call of LocalConfigManifest.
+func (mr *MockIConfigClientMockRecorder) LocalConfigManifest(namespace, group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocalConfigManifest", reflect.TypeOf((*MockIConfigClient)(nil).LocalConfigManifest), namespace, group)
+}
+
+// Promote mocks base method.
+func (m *MockIConfigClient) Promote(param vo.ConfigParam) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Promote", param)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Promote indicates an expected call of Promote.
+func (mr *MockIConfigClientMockRecorder) Promote(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Promote", reflect.TypeOf((*MockIConfigClient)(nil).Promote), param)
+}
+
+// PublishBinaryConfig mocks base method.
+func (m *MockIConfigClient) PublishBinaryConfig(param vo.ConfigParam, data []byte) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishBinaryConfig", param, data)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishBinaryConfig indicates an expected call of PublishBinaryConfig.
+func (mr *MockIConfigClientMockRecorder) PublishBinaryConfig(param, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishBinaryConfig", reflect.TypeOf((*MockIConfigClient)(nil).PublishBinaryConfig), param, data)
+}
+
+// PublishConfig mocks base method.
+func (m *MockIConfigClient) PublishConfig(param vo.ConfigParam) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishConfig", param)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishConfig indicates an expected call of PublishConfig.
+func (mr *MockIConfigClientMockRecorder) PublishConfig(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishConfig", reflect.TypeOf((*MockIConfigClient)(nil).PublishConfig), param)
+}
+
+// PublishConfigAt mocks base method.
+func (m *MockIConfigClient) PublishConfigAt(param vo.ConfigParam, effectiveTime time.Time) (*config_client.ScheduledPublish, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishConfigAt", param, effectiveTime)
+	ret0, _ := ret[0].(*config_client.ScheduledPublish)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishConfigAt indicates an expected call of PublishConfigAt.
+func (mr *MockIConfigClientMockRecorder) PublishConfigAt(param, effectiveTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishConfigAt", reflect.TypeOf((*MockIConfigClient)(nil).PublishConfigAt), param, effectiveTime)
+}
+
+// PublishConfigStream mocks base method.
+func (m *MockIConfigClient) PublishConfigStream(param vo.ConfigParam, r io.Reader) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishConfigStream", param, r)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishConfigStream indicates an expected call of PublishConfigStream.
+func (mr *MockIConfigClientMockRecorder) PublishConfigStream(param, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishConfigStream", reflect.TypeOf((*MockIConfigClient)(nil).PublishConfigStream), param, r)
+}
+
+// PublishConfigWithResult mocks base method.
+func (m *MockIConfigClient) PublishConfigWithResult(param vo.ConfigParam) (model.PublishResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishConfigWithResult", param)
+	ret0, _ := ret[0].(model.PublishResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishConfigWithResult indicates an expected call of PublishConfigWithResult.
+func (mr *MockIConfigClientMockRecorder) PublishConfigWithResult(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishConfigWithResult", reflect.TypeOf((*MockIConfigClient)(nil).PublishConfigWithResult), param)
+}
+
+// RestoreSubscriptions mocks base method.
+func (m *MockIConfigClient) RestoreSubscriptions(param vo.ConfigParam) ([]vo.ConfigParam, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreSubscriptions", param)
+	ret0, _ := ret[0].([]vo.ConfigParam)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreSubscriptions indicates an expected call of RestoreSubscriptions.
+func (mr *MockIConfigClientMockRecorder) RestoreSubscriptions(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreSubscriptions", reflect.TypeOf((*MockIConfigClient)(nil).RestoreSubscriptions), param)
+}
+
+// SearchConfig mocks base method.
+func (m *MockIConfigClient) SearchConfig(param vo.SearchConfigParam) (*model.ConfigPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchConfig", param)
+	ret0, _ := ret[0].(*model.ConfigPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchConfig indicates an expected call of SearchConfig.
+func (mr *MockIConfigClientMockRecorder) SearchConfig(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchConfig", reflect.TypeOf((*MockIConfigClient)(nil).SearchConfig), param)
+}
+
+// SearchConfigIterator mocks base method.
+func (m *MockIConfigClient) SearchConfigIterator(param vo.SearchConfigParam) *config_client.ConfigIterator {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchConfigIterator", param)
+	ret0, _ := ret[0].(*config_client.ConfigIterator)
+	return ret0
+}
+
+// SearchConfigIterator indicates an expected call of SearchConfigIterator.
+func (mr *MockIConfigClientMockRecorder) SearchConfigIterator(param interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddConfigToListen", reflect.TypeOf((*MockIConfigClient)(nil).AddConfigToListen), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchConfigIterator", reflect.TypeOf((*MockIConfigClient)(nil).SearchConfigIterator), param)
 }
 
-// StopListenConfig mocks base method
-func (m *MockIConfigClient) StopListenConfig() {
+// Tail mocks base method.
+func (m *MockIConfigClient) Tail(ctx context.Context, param vo.ConfigParam) (<-chan model.ConfigChangeEvent, error) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "StopListenConfig")
+	ret := m.ctrl.Call(m, "Tail", ctx, param)
+	ret0, _ := ret[0].(<-chan model.ConfigChangeEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// StopListenConfig indicates an expected call of StopListenConfig
-func (mr *MockIConfigClientMockRecorder) StopListenConfig() *gomock.Call {
+// Tail indicates an expected call of Tail.
+func (mr *MockIConfigClientMockRecorder) Tail(ctx, param interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopListenConfig", reflect.TypeOf((*MockIConfigClient)(nil).StopListenConfig))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tail", reflect.TypeOf((*MockIConfigClient)(nil).Tail), ctx, param)
 }
 
-// GetConfigContent mocks base method
-func (m *MockIConfigClient) GetConfigContent(dataId, groupId string) (string, error) {
+// WaitForConfig mocks base method.
+func (m *MockIConfigClient) WaitForConfig(ctx context.Context, param vo.ConfigParam) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetConfigContent", dataId, groupId)
+	ret := m.ctrl.Call(m, "WaitForConfig", ctx, param)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetConfigContent indicates an expected call of GetConfigContent
-func (mr *MockIConfigClientMockRecorder) GetConfigContent(dataId, groupId interface{}) *gomock.Call {
+// WaitForConfig indicates an expected call of WaitForConfig.
+func (mr *MockIConfigClientMockRecorder) WaitForConfig(ctx, param interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigContent", reflect.TypeOf((*MockIConfigClient)(nil).GetConfigContent), dataId, groupId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForConfig", reflect.TypeOf((*MockIConfigClient)(nil).WaitForConfig), ctx, param)
 }
@@ -21,52 +21,83 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	naming_client "github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client"
+	routing "github.com/nacos-group/nacos-sdk-go/v2/clients/naming_client/routing"
 	model "github.com/nacos-group/nacos-sdk-go/v2/model"
 	vo "github.com/nacos-group/nacos-sdk-go/v2/vo"
 )
 
-// MockINamingClient is a mock of INamingClient interface
+// MockINamingClient is a mock of INamingClient interface.
 type MockINamingClient struct {
 	ctrl     *gomock.Controller
 	recorder *MockINamingClientMockRecorder
 }
 
-// MockINamingClientMockRecorder is the mock recorder for MockINamingClient
+// MockINamingClientMockRecorder is the mock recorder for MockINamingClient.
 type MockINamingClientMockRecorder struct {
 	mock *MockINamingClient
 }
 
-// NewMockINamingClient creates a new mock instance
+// NewMockINamingClient creates a new mock instance.
 func NewMockINamingClient(ctrl *gomock.Controller) *MockINamingClient {
 	mock := &MockINamingClient{ctrl: ctrl}
 	mock.recorder = &MockINamingClientMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockINamingClient) EXPECT() *MockINamingClientMockRecorder {
 	return m.recorder
 }
 
-// RegisterInstance mocks base method
-func (m *MockINamingClient) RegisterInstance(param vo.RegisterInstanceParam) (bool, error) {
+// BatchRegisterInstance mocks base method.
+func (m *MockINamingClient) BatchRegisterInstance(param vo.BatchRegisterInstanceParam) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RegisterInstance", param)
+	ret := m.ctrl.Call(m, "BatchRegisterInstance", param)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// RegisterInstance indicates an expected call of RegisterInstance
-func (mr *MockINamingClientMockRecorder) RegisterInstance(param interface{}) *gomock.Call {
+// BatchRegisterInstance indicates an expected call of BatchRegisterInstance.
+func (mr *MockINamingClientMockRecorder) BatchRegisterInstance(param interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterInstance", reflect.TypeOf((*MockINamingClient)(nil).RegisterInstance), param)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchRegisterInstance", reflect.TypeOf((*MockINamingClient)(nil).BatchRegisterInstance), param)
+}
+
+// CloseClient mocks base method.
+func (m *MockINamingClient) CloseClient() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CloseClient")
+}
+
+// CloseClient indicates an expected call of CloseClient.
+func (mr *MockINamingClientMockRecorder) CloseClient() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseClient", reflect.TypeOf((*MockINamingClient)(nil).CloseClient))
+}
+
+// CompareAndGetService mocks base method.
+func (m *MockINamingClient) CompareAndGetService(param vo.GetServiceParam, lastEpoch uint64) (model.Service, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompareAndGetService", param, lastEpoch)
+	ret0, _ := ret[0].(model.Service)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CompareAndGetService indicates an expected call of CompareAndGetService.
+func (mr *MockINamingClientMockRecorder) CompareAndGetService(param, lastEpoch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareAndGetService", reflect.TypeOf((*MockINamingClient)(nil).CompareAndGetService), param, lastEpoch)
 }
 
-// DeregisterInstance mocks base method
+// DeregisterInstance mocks base method.
 func (m *MockINamingClient) DeregisterInstance(param vo.DeregisterInstanceParam) (bool, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeregisterInstance", param)
@@ -75,13 +106,42 @@ func (m *MockINamingClient) DeregisterInstance(param vo.DeregisterInstanceParam)
 	return ret0, ret1
 }
 
-// DeregisterInstance indicates an expected call of DeregisterInstance
+// DeregisterInstance indicates an expected call of DeregisterInstance.
 func (mr *MockINamingClientMockRecorder) DeregisterInstance(param interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeregisterInstance", reflect.TypeOf((*MockINamingClient)(nil).DeregisterInstance), param)
 }
 
-// GetService mocks base method
+// GetAllServicesInfo mocks base method.
+func (m *MockINamingClient) GetAllServicesInfo(param vo.GetAllServiceInfoParam) (model.ServiceList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllServicesInfo", param)
+	ret0, _ := ret[0].(model.ServiceList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllServicesInfo indicates an expected call of GetAllServicesInfo.
+func (mr *MockINamingClientMockRecorder) GetAllServicesInfo(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllServicesInfo", reflect.TypeOf((*MockINamingClient)(nil).GetAllServicesInfo), param)
+}
+
+// GetRegistrations mocks base method.
+func (m *MockINamingClient) GetRegistrations() []model.Registration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRegistrations")
+	ret0, _ := ret[0].([]model.Registration)
+	return ret0
+}
+
+// GetRegistrations indicates an expected call of GetRegistrations.
+func (mr *MockINamingClientMockRecorder) GetRegistrations() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRegistrations", reflect.TypeOf((*MockINamingClient)(nil).GetRegistrations))
+}
+
+// GetService mocks base method.
 func (m *MockINamingClient) GetService(param vo.GetServiceParam) (model.Service, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetService", param)
@@ -90,13 +150,131 @@ func (m *MockINamingClient) GetService(param vo.GetServiceParam) (model.Service,
 	return ret0, ret1
 }
 
-// GetService indicates an expected call of GetService
+// GetService indicates an expected call of GetService.
 func (mr *MockINamingClientMockRecorder) GetService(param interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetService", reflect.TypeOf((*MockINamingClient)(nil).GetService), param)
 }
 
-// SelectInstances mocks base method
+// GroupOf mocks base method.
+func (m *MockINamingClient) GroupOf(groupName string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GroupOf", groupName)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GroupOf indicates an expected call of GroupOf.
+func (mr *MockINamingClientMockRecorder) GroupOf(groupName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GroupOf", reflect.TypeOf((*MockINamingClient)(nil).GroupOf), groupName)
+}
+
+// InstanceId mocks base method.
+func (m *MockINamingClient) InstanceId() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstanceId")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// InstanceId indicates an expected call of InstanceId.
+func (mr *MockINamingClientMockRecorder) InstanceId() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstanceId", reflect.TypeOf((*MockINamingClient)(nil).InstanceId))
+}
+
+// ListGroups mocks base method.
+func (m *MockINamingClient) ListGroups(namespace string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGroups", namespace)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGroups indicates an expected call of ListGroups.
+func (mr *MockINamingClientMockRecorder) ListGroups(namespace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGroups", reflect.TypeOf((*MockINamingClient)(nil).ListGroups), namespace)
+}
+
+// NewMutex mocks base method.
+func (m *MockINamingClient) NewMutex(param vo.MutexParam) (*naming_client.Mutex, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewMutex", param)
+	ret0, _ := ret[0].(*naming_client.Mutex)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewMutex indicates an expected call of NewMutex.
+func (mr *MockINamingClientMockRecorder) NewMutex(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewMutex", reflect.TypeOf((*MockINamingClient)(nil).NewMutex), param)
+}
+
+// RegisterInstance mocks base method.
+func (m *MockINamingClient) RegisterInstance(param vo.RegisterInstanceParam) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterInstance", param)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterInstance indicates an expected call of RegisterInstance.
+func (mr *MockINamingClientMockRecorder) RegisterInstance(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterInstance", reflect.TypeOf((*MockINamingClient)(nil).RegisterInstance), param)
+}
+
+// RegisterInstanceWithTTL mocks base method.
+func (m *MockINamingClient) RegisterInstanceWithTTL(param vo.RegisterInstanceWithTTLParam) (*naming_client.Lease, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterInstanceWithTTL", param)
+	ret0, _ := ret[0].(*naming_client.Lease)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterInstanceWithTTL indicates an expected call of RegisterInstanceWithTTL.
+func (mr *MockINamingClientMockRecorder) RegisterInstanceWithTTL(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterInstanceWithTTL", reflect.TypeOf((*MockINamingClient)(nil).RegisterInstanceWithTTL), param)
+}
+
+// RestoreSubscriptions mocks base method.
+func (m *MockINamingClient) RestoreSubscriptions(param vo.SubscribeParam) ([]*vo.SubscribeParam, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreSubscriptions", param)
+	ret0, _ := ret[0].([]*vo.SubscribeParam)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreSubscriptions indicates an expected call of RestoreSubscriptions.
+func (mr *MockINamingClientMockRecorder) RestoreSubscriptions(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreSubscriptions", reflect.TypeOf((*MockINamingClient)(nil).RestoreSubscriptions), param)
+}
+
+// SelectAllInstances mocks base method.
+func (m *MockINamingClient) SelectAllInstances(param vo.SelectAllInstancesParam) ([]model.Instance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectAllInstances", param)
+	ret0, _ := ret[0].([]model.Instance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectAllInstances indicates an expected call of SelectAllInstances.
+func (mr *MockINamingClientMockRecorder) SelectAllInstances(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectAllInstances", reflect.TypeOf((*MockINamingClient)(nil).SelectAllInstances), param)
+}
+
+// SelectInstances mocks base method.
 func (m *MockINamingClient) SelectInstances(param vo.SelectInstancesParam) ([]model.Instance, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SelectInstances", param)
@@ -105,13 +283,13 @@ func (m *MockINamingClient) SelectInstances(param vo.SelectInstancesParam) ([]mo
 	return ret0, ret1
 }
 
-// SelectInstances indicates an expected call of SelectInstances
+// SelectInstances indicates an expected call of SelectInstances.
 func (mr *MockINamingClientMockRecorder) SelectInstances(param interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectInstances", reflect.TypeOf((*MockINamingClient)(nil).SelectInstances), param)
 }
 
-// SelectOneHealthyInstance mocks base method
+// SelectOneHealthyInstance mocks base method.
 func (m *MockINamingClient) SelectOneHealthyInstance(param vo.SelectOneHealthInstanceParam) (*model.Instance, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SelectOneHealthyInstance", param)
@@ -120,13 +298,25 @@ func (m *MockINamingClient) SelectOneHealthyInstance(param vo.SelectOneHealthIns
 	return ret0, ret1
 }
 
-// SelectOneHealthyInstance indicates an expected call of SelectOneHealthyInstance
+// SelectOneHealthyInstance indicates an expected call of SelectOneHealthyInstance.
 func (mr *MockINamingClientMockRecorder) SelectOneHealthyInstance(param interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectOneHealthyInstance", reflect.TypeOf((*MockINamingClient)(nil).SelectOneHealthyInstance), param)
 }
 
-// Subscribe mocks base method
+// SetRouter mocks base method.
+func (m *MockINamingClient) SetRouter(router *routing.Router) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetRouter", router)
+}
+
+// SetRouter indicates an expected call of SetRouter.
+func (mr *MockINamingClientMockRecorder) SetRouter(router interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRouter", reflect.TypeOf((*MockINamingClient)(nil).SetRouter), router)
+}
+
+// Subscribe mocks base method.
 func (m *MockINamingClient) Subscribe(param *vo.SubscribeParam) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Subscribe", param)
@@ -134,13 +324,13 @@ func (m *MockINamingClient) Subscribe(param *vo.SubscribeParam) error {
 	return ret0
 }
 
-// Subscribe indicates an expected call of Subscribe
+// Subscribe indicates an expected call of Subscribe.
 func (mr *MockINamingClientMockRecorder) Subscribe(param interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockINamingClient)(nil).Subscribe), param)
 }
 
-// Unsubscribe mocks base method
+// Unsubscribe mocks base method.
 func (m *MockINamingClient) Unsubscribe(param *vo.SubscribeParam) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Unsubscribe", param)
@@ -148,8 +338,53 @@ func (m *MockINamingClient) Unsubscribe(param *vo.SubscribeParam) error {
 	return ret0
 }
 
-// Unsubscribe indicates an expected call of Unsubscribe
+// Unsubscribe indicates an expected call of Unsubscribe.
 func (mr *MockINamingClientMockRecorder) Unsubscribe(param interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockINamingClient)(nil).Unsubscribe), param)
 }
+
+// UpdateInstance mocks base method.
+func (m *MockINamingClient) UpdateInstance(param vo.UpdateInstanceParam) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateInstance", param)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateInstance indicates an expected call of UpdateInstance.
+func (mr *MockINamingClientMockRecorder) UpdateInstance(param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInstance", reflect.TypeOf((*MockINamingClient)(nil).UpdateInstance), param)
+}
+
+// WaitForService mocks base method.
+func (m *MockINamingClient) WaitForService(ctx context.Context, param vo.WaitForServiceParam) ([]model.Instance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForService", ctx, param)
+	ret0, _ := ret[0].([]model.Instance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WaitForService indicates an expected call of WaitForService.
+func (mr *MockINamingClientMockRecorder) WaitForService(ctx, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForService", reflect.TypeOf((*MockINamingClient)(nil).WaitForService), ctx, param)
+}
+
+// Watch mocks base method.
+func (m *MockINamingClient) Watch(ctx context.Context, param vo.WatchParam) (<-chan model.InstanceChangeEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", ctx, param)
+	ret0, _ := ret[0].(<-chan model.InstanceChangeEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockINamingClientMockRecorder) Watch(ctx, param interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockINamingClient)(nil).Watch), ctx, param)
+}
@@ -16,7 +16,10 @@
 
 package model
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 type ConfigItem struct {
 	Id      json.Number `param:"id"`
@@ -26,6 +29,7 @@ type ConfigItem struct {
 	Md5     string      `param:"md5"`
 	Tenant  string      `param:"tenant"`
 	Appname string      `param:"appname"`
+	Type    string      `param:"type"`
 }
 type ConfigPage struct {
 	TotalCount     int          `param:"totalCount"`
@@ -46,3 +50,219 @@ type ConfigContext struct {
 	DataId string `json:"dataId"`
 	Tenant string `json:"tenant"`
 }
+
+type ConfigEventType string
+
+const (
+	ConfigEventChange ConfigEventType = "change"
+	ConfigEventDelete ConfigEventType = "delete"
+)
+
+type ConfigChangeEvent struct {
+	Type      ConfigEventType `json:"type"`
+	Namespace string          `json:"namespace"`
+	Group     string          `json:"group"`
+	DataId    string          `json:"dataId"`
+	Content   string          `json:"content"` // the last known content before deletion for delete event
+}
+
+// ConfigWebhookEvent is the change event posted to webhooks
+type ConfigWebhookEvent struct {
+	Type       ConfigEventType `json:"type"`
+	Namespace  string          `json:"namespace"`
+	Group      string          `json:"group"`
+	DataId     string          `json:"dataId"`
+	OldMd5     string          `json:"oldMd5"`
+	NewMd5     string          `json:"newMd5"`
+	OldContent string          `json:"oldContent,omitempty"`
+	NewContent string          `json:"newContent,omitempty"`
+	Timestamp  int64           `json:"timestamp"` // the unix milliseconds the change is observed
+}
+
+type ConfigKey struct {
+	DataId string `json:"dataId"`
+	Group  string `json:"group"`
+	Md5    string `json:"md5"`
+	Tenant string `json:"tenant"`
+	Type   string `json:"type"`
+}
+
+type ConfigKeyPage struct {
+	TotalCount     int         `json:"totalCount"`
+	PageNumber     int         `json:"pageNumber"`
+	PagesAvailable int         `json:"pagesAvailable"`
+	PageItems      []ConfigKey `json:"pageItems"`
+}
+
+type ConfigSnapshot struct {
+	Time time.Time `json:"time"`
+	Md5  string    `json:"md5"`
+	Size int       `json:"size"`
+}
+
+type ConfigListenStatus struct {
+	NamespaceId     string    `json:"namespaceId"`
+	ListenedCount   int       `json:"listenedCount"`
+	LastSuccessTime time.Time `json:"lastSuccessTime"`
+	LastFailTime    time.Time `json:"lastFailTime"`
+	LastError       string    `json:"lastError"`
+}
+
+type ListenerBreakerState string
+
+const (
+	ListenerBreakerClosed   ListenerBreakerState = "closed"    // the changes are delivered to the listener
+	ListenerBreakerOpen     ListenerBreakerState = "open"      // the listener keeps failing, the changes are held until it's retried
+	ListenerBreakerHalfOpen ListenerBreakerState = "half-open" // the latest held change is delivered as a trial, the breaker closes when it succeeds
+)
+
+type PublishOperation string
+
+const (
+	PublishCreate PublishOperation = "create"
+	PublishUpdate PublishOperation = "update"
+)
+
+// PublishResult is the result of PublishConfigWithResult
+type PublishResult struct {
+	Published    bool             `json:"published"`
+	DataId       string           `json:"dataId"`
+	Group        string           `json:"group"`
+	NamespaceId  string           `json:"namespaceId"`
+	Md5          string           `json:"md5"`                    // the md5 of the content stored on server, it's the CasMd5 of the next publish
+	LastModified time.Time        `json:"lastModified,omitempty"` // the modified time assigned by server, zero when it's not known
+	CasMd5       string           `json:"casMd5,omitempty"`       // the md5 the server compared with, empty when it's not a CAS publish
+	Operation    PublishOperation `json:"operation,omitempty"`    // empty when the config can't be queried before publishing
+	Overwrote    string           `json:"overwrote,omitempty"`    // the md5 of the publish queued before on the same config and overwritten by this one
+}
+
+type QuotaKind string
+
+const (
+	QuotaContentSize   QuotaKind = "content-size"  // the size in bytes of a config content
+	QuotaListenedKeys  QuotaKind = "listened-keys" // the number of configs listened
+	QuotaSubscriptions QuotaKind = "subscriptions" // the number of services subscribed
+)
+
+// QuotaEvent is emitted when the usage of a count quota reaches the warning ratio or a quota is exceeded
+type QuotaEvent struct {
+	Kind     QuotaKind `json:"kind"`
+	Limit    int       `json:"limit"`
+	Used     int       `json:"used"`
+	Key      string    `json:"key"`
+	Exceeded bool      `json:"exceeded"`
+}
+
+// ListenerHealth is the health of a config listener, the panics and the errors of OnChangeErr count as failures
+type ListenerHealth struct {
+	ListenerId          int64                `json:"listenerId"`
+	NamespaceId         string               `json:"namespaceId"`
+	Group               string               `json:"group"`
+	DataId              string               `json:"dataId"`
+	State               ListenerBreakerState `json:"state"`
+	ConsecutiveFailures int                  `json:"consecutiveFailures"`
+	TotalFailures       int64                `json:"totalFailures"`
+	LastError           string               `json:"lastError,omitempty"`
+	LastFailTime        time.Time            `json:"lastFailTime,omitempty"`
+}
+
+// ConfigDelivery is a change delivered to OnChangeAck, it's delivered again until Ack is called
+type ConfigDelivery struct {
+	NamespaceId string `json:"namespaceId"`
+	Group       string `json:"group"`
+	DataId      string `json:"dataId"`
+	Content     string `json:"content"`
+	Attempt     int    `json:"attempt"` // starts from 1, it continues after the client restarts
+	Ack         func() `json:"-"`
+}
+
+type ReconcileActionType string
+
+const (
+	ReconcileCreate ReconcileActionType = "create"
+	ReconcileUpdate ReconcileActionType = "update"
+	ReconcileDelete ReconcileActionType = "delete"
+)
+
+type ReconcileAction struct {
+	Type    ReconcileActionType `json:"type"`
+	DataId  string              `json:"dataId"`
+	Group   string              `json:"group"`
+	Content string              `json:"content,omitempty"` // the desired content, empty for delete
+	Md5     string              `json:"md5,omitempty"`     // the md5 of content on server, empty for create
+}
+
+type ConflictPolicy string
+
+const (
+	ConflictOverwrite ConflictPolicy = "overwrite" // the local content overwrites the config changed by others
+	ConflictSkip      ConflictPolicy = "skip"      // the config changed by others is kept, the local change is skipped
+)
+
+type ConfigManifest struct {
+	Namespace string            `json:"namespace"`
+	Group     string            `json:"group"`
+	Checksum  string            `json:"checksum"` // the md5 of sorted dataId:md5 pairs
+	Md5s      map[string]string `json:"md5s"`     // the md5 of each config by dataId
+}
+
+type ConfigManifestDiff struct {
+	Missing  []string `json:"missing"`  // the dataIds on server but not in the local manifest
+	Extra    []string `json:"extra"`    // the dataIds in the local manifest but not on server
+	Mismatch []string `json:"mismatch"` // the dataIds whose md5 differs
+}
+
+// Consistent return true when the local manifest is the same as the one on server
+func (diff ConfigManifestDiff) Consistent() bool {
+	return len(diff.Missing) == 0 && len(diff.Extra) == 0 && len(diff.Mismatch) == 0
+}
+
+// BackupManifest is the manifest of the archive written by Backup, the entries are sorted by group and dataId
+type BackupManifest struct {
+	NamespaceId string        `json:"namespaceId"`
+	Time        time.Time     `json:"time"`
+	Entries     []BackupEntry `json:"entries"`
+}
+
+type BackupEntry struct {
+	DataId string `json:"dataId"`
+	Group  string `json:"group"`
+	Type   string `json:"type,omitempty"`
+	Size   int    `json:"size"`
+	Sha256 string `json:"sha256"` // the checksum of content verified by Restore
+}
+
+type ScheduledPublishEventType string
+
+const (
+	ScheduledPublishScheduled ScheduledPublishEventType = "scheduled"
+	ScheduledPublishFailed    ScheduledPublishEventType = "failed" // an attempt failed, it will be retried unless it's the last one
+	ScheduledPublishPublished ScheduledPublishEventType = "published"
+	ScheduledPublishCanceled  ScheduledPublishEventType = "canceled"
+	ScheduledPublishAborted   ScheduledPublishEventType = "aborted" // all the attempts failed
+)
+
+type ScheduledPublishEvent struct {
+	Type          ScheduledPublishEventType `json:"type"`
+	Id            string                    `json:"id"`
+	DataId        string                    `json:"dataId"`
+	Group         string                    `json:"group"`
+	EffectiveTime time.Time                 `json:"effectiveTime"`
+	Attempt       int                       `json:"attempt"`
+	Error         string                    `json:"error,omitempty"`
+	Time          time.Time                 `json:"time"`
+}
+
+// PropertySource is the flat properties of a config, Name is "dataId,group" like the property sources of Spring Cloud Alibaba
+type PropertySource struct {
+	Name   string            `json:"name"`
+	Source map[string]string `json:"source"`
+}
+
+// SpringEnvironment is the property sources of an application in the shape of the environment of Spring Cloud Config,
+// the property sources are in the order of precedence, the former overrides the latter
+type SpringEnvironment struct {
+	Name            string           `json:"name"`
+	Profiles        []string         `json:"profiles"`
+	PropertySources []PropertySource `json:"propertySources"`
+}
@@ -50,6 +50,12 @@ type Service struct {
 	Valid                    bool       `json:"valid"`
 	AllIPs                   bool       `json:"allIPs"`
 	ReachProtectionThreshold bool       `json:"reachProtectionThreshold"`
+	// the service-level settings, they are filled for the ServiceCallback of subscription
+	Metadata         map[string]string `json:"metadata,omitempty"`
+	ProtectThreshold float64           `json:"protectThreshold,omitempty"`
+	// Epoch is the version of the snapshot assigned by client, it's increased only when the instances or the settings
+	// change, so the callers polling the service detect no change by comparing it, see CompareAndGetService
+	Epoch uint64 `json:"epoch,omitempty"`
 }
 
 type ServiceDetail struct {
@@ -106,3 +112,67 @@ type ServiceList struct {
 	Count int64    `json:"count"`
 	Doms  []string `json:"doms"`
 }
+
+type InstanceChangeEvent struct {
+	ServiceName string     `json:"serviceName"`
+	GroupName   string     `json:"groupName"`
+	Clusters    string     `json:"clusters"`
+	Instances   []Instance `json:"instances"`
+	Added       []Instance `json:"added"`
+	Removed     []Instance `json:"removed"`
+	Modified    []Instance `json:"modified"`
+}
+
+type RegistrationState string
+
+const (
+	RegistrationRegistered RegistrationState = "registered" // the instance is registered and kept alive
+	RegistrationDegraded   RegistrationState = "degraded"   // the connection keeping the ephemeral instance alive is lost, it's registered again once reconnected
+	RegistrationLost       RegistrationState = "lost"       // the connection is not recovered in time, the instance is regarded as removed by server
+)
+
+// Registration is the state of an instance registered by the client
+type Registration struct {
+	ServiceName string            `json:"serviceName"`
+	GroupName   string            `json:"groupName"`
+	Instance    Instance          `json:"instance"`
+	State       RegistrationState `json:"state"`
+	Since       time.Time         `json:"since"` // the time the registration entered the state
+}
+
+// RegistrationConflict is the same ip:port of a registered instance observed with different metadata, which
+// usually means another process registers it, such as a misconfigured deployment double-registering
+type RegistrationConflict struct {
+	ServiceName string    `json:"serviceName"`
+	GroupName   string    `json:"groupName"`
+	Registered  Instance  `json:"registered"` // the instance registered by the client
+	Observed    Instance  `json:"observed"`   // the instance on server
+	DetectedAt  time.Time `json:"detectedAt"`
+}
+
+// ServiceDriftEvent is the difference between the cached service and the one on server found by full resync
+type ServiceDriftEvent struct {
+	ServiceName string     `json:"serviceName"`
+	GroupName   string     `json:"groupName"`
+	Clusters    string     `json:"clusters"`
+	Added       []Instance `json:"added"`    // on server but missing in cache
+	Removed     []Instance `json:"removed"`  // in cache but gone on server
+	Modified    []Instance `json:"modified"` // the instances on server differ from the cached
+}
+
+type InstanceEventType string
+
+const (
+	InstanceUp       InstanceEventType = "up"       // the instance is added or becomes healthy and enabled
+	InstanceDown     InstanceEventType = "down"     // the instance is removed or becomes unhealthy or disabled
+	InstanceModified InstanceEventType = "modified" // the instance is modified but still up or down as before
+)
+
+// InstanceEvent is the change of one instance forwarded to the event sinks
+type InstanceEvent struct {
+	Type        InstanceEventType `json:"type"`
+	ServiceName string            `json:"serviceName"`
+	GroupName   string            `json:"groupName"`
+	Instance    Instance          `json:"instance"`
+	Timestamp   int64             `json:"timestamp"` // the unix milliseconds the change is observed
+}
@@ -18,7 +18,6 @@ package util
 
 import (
 	"encoding/json"
-	"net"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -66,39 +65,6 @@ func GetConfigCacheKey(dataId string, group string, tenant string) string {
 	return dataId + constant.CONFIG_INFO_SPLITER + group + constant.CONFIG_INFO_SPLITER + tenant
 }
 
-var localIP = ""
-
-func LocalIP() string {
-	if localIP == "" {
-		netInterfaces, err := net.Interfaces()
-		if err != nil {
-			logger.Errorf("get Interfaces failed,err:%+v", err)
-			return ""
-		}
-
-		for i := 0; i < len(netInterfaces); i++ {
-			if ((netInterfaces[i].Flags & net.FlagUp) != 0) && ((netInterfaces[i].Flags & net.FlagLoopback) == 0) {
-				addrs, err := netInterfaces[i].Addrs()
-				if err != nil {
-					logger.Errorf("get InterfaceAddress failed,err:%+v", err)
-					return ""
-				}
-				for _, address := range addrs {
-					if ipnet, ok := address.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
-						localIP = ipnet.IP.String()
-						break
-					}
-				}
-			}
-		}
-
-		if len(localIP) > 0 {
-			logger.Infof("Local IP:%s", localIP)
-		}
-	}
-	return localIP
-}
-
 func GetDurationWithDefault(metadata map[string]string, key string, defaultDuration time.Duration) time.Duration {
 	data, ok := metadata[key]
 	if ok {
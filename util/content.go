@@ -16,6 +16,14 @@
 
 package util
 
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/pkg/errors"
+)
+
 const SHOW_CONTENT_SIZE = 100
 
 func TruncateContent(content string) string {
@@ -27,3 +35,27 @@ func TruncateContent(content string) string {
 	}
 	return content[0:SHOW_CONTENT_SIZE]
 }
+
+// EncodeBinaryContent encode the binary data as base64 config content, the content is marked by
+// constant.BINARY_CONTENT_PREFIX so that it can be told from text content
+func EncodeBinaryContent(data []byte) string {
+	return constant.BINARY_CONTENT_PREFIX + base64.StdEncoding.EncodeToString(data)
+}
+
+// IsBinaryContent return true when the content is encoded by EncodeBinaryContent
+func IsBinaryContent(content string) bool {
+	return strings.HasPrefix(content, constant.BINARY_CONTENT_PREFIX)
+}
+
+// DecodeBinaryContent decode the content encoded by EncodeBinaryContent, the content which is not marked
+// as binary is returned as is
+func DecodeBinaryContent(content string) ([]byte, error) {
+	if !IsBinaryContent(content) {
+		return []byte(content), nil
+	}
+	data, err := base64.StdEncoding.DecodeString(content[len(constant.BINARY_CONTENT_PREFIX):])
+	if err != nil {
+		return nil, errors.Wrap(err, "decode binary content failed")
+	}
+	return data, nil
+}
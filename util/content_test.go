@@ -0,0 +1,40 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinaryContent(t *testing.T) {
+	data := []byte{0x00, 0xff, 0x10, 'p', 'e', 'm'}
+	content := EncodeBinaryContent(data)
+	assert.True(t, IsBinaryContent(content))
+	decoded, err := DecodeBinaryContent(content)
+	assert.Nil(t, err)
+	assert.Equal(t, data, decoded)
+
+	assert.False(t, IsBinaryContent("plain"))
+	decoded, err = DecodeBinaryContent("plain")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("plain"), decoded)
+
+	_, err = DecodeBinaryContent("data:application/octet-stream;base64,!invalid")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,264 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// JsonPath is a compiled JSONPath expression, the subset of root $, child .name and ['name'], index [n],
+// wildcard .* and [*], and recursive descent ..name is supported
+type JsonPath struct {
+	expr     string
+	segments []pathSegment
+}
+
+type pathSegment struct {
+	name      string
+	index     int
+	isIndex   bool
+	wildcard  bool
+	recursive bool
+}
+
+// CompileJsonPath compile the JSONPath expression such as $.features.checkout.*
+func CompileJsonPath(expr string) (*JsonPath, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, errors.Errorf("jsonpath %s must start with $", expr)
+	}
+	p := &JsonPath{expr: expr}
+	rest := expr[1:]
+	for len(rest) > 0 {
+		var segment pathSegment
+		if strings.HasPrefix(rest, "..") {
+			segment.recursive = true
+			rest = rest[1:]
+			if strings.HasPrefix(rest, ".[") {
+				rest = rest[1:]
+			}
+		}
+		switch {
+		case strings.HasPrefix(rest, "."):
+			rest = parseDotName(rest[1:], &segment)
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, errors.Errorf("jsonpath %s has unclosed [", expr)
+			}
+			if err := parseBracket(strings.TrimSpace(rest[1:end]), &segment); err != nil {
+				return nil, errors.Wrapf(err, "jsonpath %s is invalid", expr)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, errors.Errorf("jsonpath %s is invalid at %s", expr, rest)
+		}
+		if segment.isEmpty() {
+			return nil, errors.Errorf("jsonpath %s has empty name", expr)
+		}
+		p.segments = append(p.segments, segment)
+	}
+	return p, nil
+}
+
+func (s pathSegment) isEmpty() bool {
+	return !s.wildcard && !s.isIndex && len(s.name) == 0
+}
+
+func parseDotName(rest string, segment *pathSegment) string {
+	end := strings.IndexAny(rest, ".[")
+	if end < 0 {
+		end = len(rest)
+	}
+	if name := rest[:end]; name == "*" {
+		segment.wildcard = true
+	} else {
+		segment.name = name
+	}
+	return rest[end:]
+}
+
+func parseBracket(selector string, segment *pathSegment) error {
+	switch {
+	case selector == "*":
+		segment.wildcard = true
+	case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+		segment.name = selector[1 : len(selector)-1]
+	default:
+		index, err := strconv.Atoi(selector)
+		if err != nil {
+			return errors.Errorf("unsupported selector [%s]", selector)
+		}
+		segment.index = index
+		segment.isIndex = true
+	}
+	return nil
+}
+
+// String returns the expression
+func (p *JsonPath) String() string {
+	return p.expr
+}
+
+// Definite returns whether the path selects one value at most, which has no wildcard or recursive descent
+func (p *JsonPath) Definite() bool {
+	for _, segment := range p.segments {
+		if segment.wildcard || segment.recursive {
+			return false
+		}
+	}
+	return true
+}
+
+// Select returns the value selected from doc decoded from json or yaml, the definite path returns the value or nil
+// when it's absent, the others return the slice of matched values in document order with the keys of maps sorted
+func (p *JsonPath) Select(doc interface{}) interface{} {
+	nodes := []interface{}{doc}
+	for _, segment := range p.segments {
+		var next []interface{}
+		for _, node := range nodes {
+			if segment.recursive {
+				for _, descendant := range descendants(node) {
+					next = append(next, segment.match(descendant)...)
+				}
+				continue
+			}
+			next = append(next, segment.match(node)...)
+		}
+		nodes = next
+	}
+	if !p.Definite() {
+		if nodes == nil {
+			return []interface{}{}
+		}
+		return nodes
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+	return nodes[0]
+}
+
+// SelectContent select from the json or yaml content and returns the json of the selected value,
+// empty content is regarded as null
+func (p *JsonPath) SelectContent(content string) (string, error) {
+	doc, err := decodeDocument(content)
+	if err != nil {
+		return "", err
+	}
+	selected, err := json.Marshal(p.Select(doc))
+	if err != nil {
+		return "", errors.Wrapf(err, "encode the value selected by %s failed", p.expr)
+	}
+	return string(selected), nil
+}
+
+// decodeDocument decode the json or yaml content, empty content is regarded as null
+func decodeDocument(content string) (interface{}, error) {
+	var doc interface{}
+	if len(strings.TrimSpace(content)) > 0 {
+		if err := json.Unmarshal([]byte(content), &doc); err != nil {
+			if yamlErr := yaml.Unmarshal([]byte(content), &doc); yamlErr != nil {
+				return nil, errors.Wrap(yamlErr, "content is neither json nor yaml")
+			}
+			doc = normalizeYaml(doc)
+		}
+	}
+	return doc, nil
+}
+
+func (s pathSegment) match(node interface{}) []interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if s.wildcard {
+			var values []interface{}
+			for _, key := range sortedKeys(v) {
+				values = append(values, v[key])
+			}
+			return values
+		}
+		if value, ok := v[s.name]; ok && !s.isIndex {
+			return []interface{}{value}
+		}
+	case []interface{}:
+		if s.wildcard {
+			return append([]interface{}(nil), v...)
+		}
+		if s.isIndex {
+			index := s.index
+			if index < 0 {
+				index += len(v)
+			}
+			if index >= 0 && index < len(v) {
+				return []interface{}{v[index]}
+			}
+		}
+	}
+	return nil
+}
+
+// descendants returns node and all the values nested in it
+func descendants(node interface{}) []interface{} {
+	result := []interface{}{node}
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			result = append(result, descendants(v[key])...)
+		}
+	case []interface{}:
+		for _, item := range v {
+			result = append(result, descendants(item)...)
+		}
+	}
+	return result
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// normalizeYaml convert the maps of non-string keys decoded from yaml into the maps of string keys like json
+func normalizeYaml(node interface{}) interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = normalizeYaml(value)
+		}
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = normalizeYaml(value)
+		}
+		return m
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYaml(item)
+		}
+	}
+	return node
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJsonPath_SelectContent(t *testing.T) {
+	content := `{"features":{"checkout":{"enabled":true,"limit":3},"search":{"enabled":false}},"items":[{"id":1},{"id":2}]}`
+	cases := map[string]string{
+		"$":                            content,
+		"$.features.checkout.*":        `[true,3]`,
+		"$.features.checkout.enabled":  `true`,
+		"$['features']['search']":      `{"enabled":false}`,
+		"$.items[1].id":                `2`,
+		"$.items[-1].id":               `2`,
+		"$.items[*].id":                `[1,2]`,
+		"$..enabled":                   `[true,false]`,
+		"$..[0]":                       `[{"id":1}]`,
+		"$.features.missing":           `null`,
+		"$.features.missing.*":         `[]`,
+		"$.features.checkout.limit[0]": `null`,
+	}
+	for expr, expected := range cases {
+		p, err := CompileJsonPath(expr)
+		assert.Nil(t, err, expr)
+		selected, err := p.SelectContent(content)
+		assert.Nil(t, err, expr)
+		if expr == "$" {
+			assert.JSONEq(t, expected, selected)
+			continue
+		}
+		assert.Equal(t, expected, selected, expr)
+	}
+}
+
+func TestJsonPath_Yaml(t *testing.T) {
+	p, err := CompileJsonPath("$.features.checkout")
+	assert.Nil(t, err)
+	selected, err := p.SelectContent("features:\n  checkout:\n    enabled: true\n    1: one\n")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"1":"one","enabled":true}`, selected)
+	selected, err = p.SelectContent("")
+	assert.Nil(t, err)
+	assert.Equal(t, "null", selected)
+}
+
+func TestCompileJsonPath_Invalid(t *testing.T) {
+	for _, expr := range []string{"features", "$.", "$[abc]", "$[0", "$x"} {
+		_, err := CompileJsonPath(expr)
+		assert.NotNil(t, err, expr)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+)
+
+// the prefixes of virtual interfaces created by container runtimes and hypervisors
+var virtualInterfacePrefixes = []string{"docker", "veth", "br-", "cni", "flannel", "cali", "vxlan", "virbr", "vmnet", "tun", "tap"}
+
+var (
+	localIP     = ""
+	localIPMux  sync.Mutex
+	ipDetectCfg *constant.IpDetectConfig
+)
+
+type ipCandidate struct {
+	iface string
+	ip    net.IP
+}
+
+// SetIpDetectConfig set the strategy used by LocalIP to determine the ip of client,
+// the ip is detected again afterwards, it's process wide like the detected ip
+func SetIpDetectConfig(cfg *constant.IpDetectConfig) {
+	localIPMux.Lock()
+	defer localIPMux.Unlock()
+	ipDetectCfg = cfg
+	localIP = ""
+}
+
+func LocalIP() string {
+	localIPMux.Lock()
+	defer localIPMux.Unlock()
+	if localIP == "" {
+		if ipDetectCfg != nil && ipDetectCfg.LocalIp != "" {
+			localIP = ipDetectCfg.LocalIp
+			return localIP
+		}
+		candidates, err := localIPCandidates()
+		if err != nil {
+			logger.Errorf("get InterfaceAddress failed,err:%+v", err)
+			return ""
+		}
+		localIP = selectLocalIP(ipDetectCfg, candidates)
+		if len(localIP) > 0 {
+			logger.Infof("Local IP:%s", localIP)
+		}
+	}
+	return localIP
+}
+
+func localIPCandidates() ([]ipCandidate, error) {
+	netInterfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	var candidates []ipCandidate
+	for i := 0; i < len(netInterfaces); i++ {
+		if ((netInterfaces[i].Flags & net.FlagUp) != 0) && ((netInterfaces[i].Flags & net.FlagLoopback) == 0) {
+			addrs, err := netInterfaces[i].Addrs()
+			if err != nil {
+				return nil, err
+			}
+			for _, address := range addrs {
+				if ipnet, ok := address.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+					candidates = append(candidates, ipCandidate{iface: netInterfaces[i].Name, ip: ipnet.IP})
+				}
+			}
+		}
+	}
+	return candidates, nil
+}
+
+// selectLocalIP pick the ip on the preferred interfaces first, then the ip in the preferred networks,
+// otherwise the first ip of the last interface is used as before
+func selectLocalIP(cfg *constant.IpDetectConfig, candidates []ipCandidate) string {
+	if cfg != nil {
+		var filtered []ipCandidate
+		for _, c := range candidates {
+			if !isIgnoredInterface(cfg, c.iface) {
+				filtered = append(filtered, c)
+			}
+		}
+		candidates = filtered
+		for _, name := range cfg.PreferredInterfaces {
+			for _, c := range candidates {
+				if c.iface == name {
+					return c.ip.String()
+				}
+			}
+		}
+		for _, cidr := range cfg.PreferredNetworks {
+			_, network, err := net.ParseCIDR(cidr)
+			if err != nil {
+				logger.Warnf("invalid preferred network %s, err:%v", cidr, err)
+				continue
+			}
+			for _, c := range candidates {
+				if network.Contains(c.ip) {
+					return c.ip.String()
+				}
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	last := candidates[len(candidates)-1]
+	for _, c := range candidates {
+		if c.iface == last.iface {
+			return c.ip.String()
+		}
+	}
+	return last.ip.String()
+}
+
+func isIgnoredInterface(cfg *constant.IpDetectConfig, iface string) bool {
+	prefixes := cfg.IgnoredInterfaces
+	if cfg.SkipVirtualInterfaces {
+		prefixes = append(prefixes[:len(prefixes):len(prefixes)], virtualInterfacePrefixes...)
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(iface, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+)
+
+func testCandidates() []ipCandidate {
+	return []ipCandidate{
+		{iface: "eth0", ip: net.ParseIP("10.0.0.5")},
+		{iface: "eth1", ip: net.ParseIP("192.168.1.5")},
+		{iface: "eth1", ip: net.ParseIP("192.168.1.6")},
+		{iface: "docker0", ip: net.ParseIP("172.17.0.1")},
+	}
+}
+
+func TestSelectLocalIP_Default(t *testing.T) {
+	assert.Equal(t, "172.17.0.1", selectLocalIP(nil, testCandidates()))
+	assert.Equal(t, "", selectLocalIP(nil, nil))
+}
+
+func TestSelectLocalIP_SkipVirtualInterfaces(t *testing.T) {
+	cfg := &constant.IpDetectConfig{SkipVirtualInterfaces: true}
+	assert.Equal(t, "192.168.1.5", selectLocalIP(cfg, testCandidates()))
+}
+
+func TestSelectLocalIP_PreferredInterfaces(t *testing.T) {
+	cfg := &constant.IpDetectConfig{PreferredInterfaces: []string{"wlan0", "eth0"}}
+	assert.Equal(t, "10.0.0.5", selectLocalIP(cfg, testCandidates()))
+}
+
+func TestSelectLocalIP_PreferredNetworks(t *testing.T) {
+	cfg := &constant.IpDetectConfig{PreferredNetworks: []string{"invalid", "192.168.0.0/16"}}
+	assert.Equal(t, "192.168.1.5", selectLocalIP(cfg, testCandidates()))
+}
+
+func TestSelectLocalIP_IgnoredInterfaces(t *testing.T) {
+	cfg := &constant.IpDetectConfig{IgnoredInterfaces: []string{"docker", "eth1"}, PreferredInterfaces: []string{"eth1"}}
+	assert.Equal(t, "10.0.0.5", selectLocalIP(cfg, testCandidates()))
+}
+
+func TestLocalIP_Explicit(t *testing.T) {
+	SetIpDetectConfig(&constant.IpDetectConfig{LocalIp: "10.1.1.1"})
+	defer SetIpDetectConfig(nil)
+	assert.Equal(t, "10.1.1.1", LocalIP())
+}
@@ -18,15 +18,17 @@ package util
 
 import (
 	"crypto/md5"
-	"fmt"
-	"io"
+	"encoding/hex"
 )
 
+// Md5 return the hex md5 of content, it's called for every listened config on each refresh,
+// so the digest is hex encoded on stack and the only allocation is the returned string
 func Md5(content string) (md string) {
 	if content != "" {
-		h := md5.New()
-		_, _ = io.WriteString(h, content)
-		md = fmt.Sprintf("%x", h.Sum(nil))
+		sum := md5.Sum([]byte(content))
+		var buf [md5.Size * 2]byte
+		hex.Encode(buf[:], sum[:])
+		md = string(buf[:])
 	}
 	return
 }
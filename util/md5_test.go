@@ -17,6 +17,7 @@
 package util
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -25,4 +26,13 @@ import (
 func TestMd5(t *testing.T) {
 	md5 := Md5("demo")
 	assert.Equal(t, "fe01ce2a7fbac8fafaed7c982a04e229", md5)
+	assert.Equal(t, "", Md5(""))
+}
+
+func BenchmarkMd5(b *testing.B) {
+	content := strings.Repeat("key=value\n", 100)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = Md5(content)
+	}
 }
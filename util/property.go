@@ -0,0 +1,301 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeProperties decode the properties or yaml content to the flat properties, the yaml keys are joined
+// by dot and the list items are indexed such as servers[0], the same as Spring does
+func DecodeProperties(configType, content string) (map[string]string, error) {
+	switch strings.ToLower(configType) {
+	case "yaml", "yml":
+		return ParseYamlProperties(content)
+	case "properties", "":
+		return ParseProperties(content), nil
+	default:
+		return nil, errors.Errorf("config type %s can not be decoded to properties", configType)
+	}
+}
+
+// MergeProperties merge the properties in order, the latter overrides the former
+func MergeProperties(sets ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, set := range sets {
+		for k, v := range set {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// ParseProperties parse the content in the format of java properties
+func ParseProperties(content string) map[string]string {
+	props := make(map[string]string)
+	reader := bufio.NewReader(strings.NewReader(content))
+	var logical strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		trimmed := strings.TrimLeft(line, " \t\f")
+		if logical.Len() == 0 && (len(trimmed) == 0 || trimmed[0] == '#' || trimmed[0] == '!') {
+			if err != nil {
+				break
+			}
+			continue
+		}
+		// the line ends with odd backslashes continues on the next line
+		backslashes := len(trimmed) - len(strings.TrimRight(trimmed, "\\"))
+		if backslashes%2 == 1 {
+			logical.WriteString(trimmed[:len(trimmed)-1])
+			if err == nil {
+				continue
+			}
+		} else {
+			logical.WriteString(trimmed)
+		}
+		key, value := splitProperty(logical.String())
+		props[key] = value
+		logical.Reset()
+		if err != nil {
+			break
+		}
+	}
+	return props
+}
+
+// splitProperty split the logical line at the first unescaped separator of '=', ':' or whitespace
+func splitProperty(line string) (key, value string) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			end = i
+			break
+		}
+	}
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if len(rest) > 0 && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	return unescapeProperty(line[:end]), unescapeProperty(rest)
+}
+
+func unescapeProperty(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i == len(s)-1 {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+4 < len(s) {
+				if r, err := strconv.ParseUint(s[i+1:i+5], 16, 32); err == nil {
+					b.WriteRune(rune(r))
+					i += 4
+					continue
+				}
+			}
+			b.WriteByte('u')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// ParseYamlProperties flatten the yaml content to properties, the documents separated by --- are merged in order
+func ParseYamlProperties(content string) (map[string]string, error) {
+	props := make(map[string]string)
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			if err == io.EOF {
+				return props, nil
+			}
+			return nil, errors.Wrap(err, "parse yaml failed")
+		}
+		flattenYaml("", &doc, props)
+	}
+}
+
+func flattenYaml(prefix string, node *yaml.Node, props map[string]string) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			flattenYaml(prefix, child, props)
+		}
+	case yaml.AliasNode:
+		flattenYaml(prefix, node.Alias, props)
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			// the merge key << inlines the mapping of alias
+			if key.Tag == "!!merge" {
+				flattenYaml(prefix, value, props)
+				continue
+			}
+			name := key.Value
+			if len(prefix) > 0 {
+				name = prefix + "." + name
+			}
+			flattenYaml(name, value, props)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			flattenYaml(prefix+"["+strconv.Itoa(i)+"]", child, props)
+		}
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			props[prefix] = ""
+			return
+		}
+		props[prefix] = node.Value
+	}
+}
+
+// ResolvePlaceholders resolve the Spring-style placeholders such as ${key} and ${key:default} in the values,
+// the placeholders can be nested such as ${${env}.url}. The key is looked up by lookupEnv before the properties
+// when it's not nil, with the names relaxed like Spring such as SERVER_PORT for server.port, so the environment
+// variables override the properties. It fails when a placeholder without default can not be resolved
+func ResolvePlaceholders(props map[string]string, lookupEnv func(key string) (string, bool)) (map[string]string, error) {
+	r := &placeholderResolver{props: props, lookupEnv: lookupEnv, resolving: make(map[string]bool)}
+	resolved := make(map[string]string, len(props))
+	for k, v := range props {
+		value, err := r.resolve(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolve property %s failed", k)
+		}
+		resolved[k] = value
+	}
+	return resolved, nil
+}
+
+type placeholderResolver struct {
+	props     map[string]string
+	lookupEnv func(key string) (string, bool)
+	// resolving is the keys being resolved, to detect the circular reference
+	resolving map[string]bool
+}
+
+func (r *placeholderResolver) resolve(value string) (string, error) {
+	start := strings.Index(value, "${")
+	if start < 0 {
+		return value, nil
+	}
+	var b strings.Builder
+	for start >= 0 {
+		end := matchPlaceholderEnd(value, start+2)
+		if end < 0 {
+			break
+		}
+		b.WriteString(value[:start])
+		placeholder, err := r.resolve(value[start+2 : end])
+		if err != nil {
+			return "", err
+		}
+		resolved, err := r.lookup(placeholder)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(resolved)
+		value = value[end+1:]
+		start = strings.Index(value, "${")
+	}
+	b.WriteString(value)
+	return b.String(), nil
+}
+
+// matchPlaceholderEnd return the index of '}' matching the placeholder started before from, -1 if it's not closed
+func matchPlaceholderEnd(value string, from int) int {
+	depth := 0
+	for i := from; i < len(value); i++ {
+		switch {
+		case strings.HasPrefix(value[i:], "${"):
+			depth++
+			i++
+		case value[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+func (r *placeholderResolver) lookup(placeholder string) (string, error) {
+	key, defaultValue, hasDefault := placeholder, "", false
+	if i := strings.Index(placeholder, ":"); i >= 0 {
+		key, defaultValue, hasDefault = placeholder[:i], placeholder[i+1:], true
+	}
+	if r.resolving[key] {
+		return "", errors.Errorf("circular placeholder reference of %s", key)
+	}
+	value, ok := r.lookupEnvRelaxed(key)
+	if !ok {
+		value, ok = r.props[key]
+	}
+	if !ok {
+		if hasDefault {
+			return defaultValue, nil
+		}
+		return "", errors.Errorf("could not resolve placeholder ${%s}", placeholder)
+	}
+	r.resolving[key] = true
+	defer delete(r.resolving, key)
+	return r.resolve(value)
+}
+
+func (r *placeholderResolver) lookupEnvRelaxed(key string) (string, bool) {
+	if r.lookupEnv == nil {
+		return "", false
+	}
+	underscored := strings.NewReplacer(".", "_", "-", "_").Replace(key)
+	for _, name := range []string{key, underscored, strings.ToUpper(underscored)} {
+		if value, ok := r.lookupEnv(name); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
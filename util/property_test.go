@@ -0,0 +1,100 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProperties(t *testing.T) {
+	props := ParseProperties("# comment\n! comment\n\nserver.port=8080\nspring.application.name : demo\n" +
+		"  key\\ with\\ space value with space\nmulti=a,\\\n    b,\\\n    c\nunicode=\\u4e2d\\u6587\\tend\nempty=\nlast")
+	assert.Equal(t, map[string]string{
+		"server.port":             "8080",
+		"spring.application.name": "demo",
+		"key with space":          "value with space",
+		"multi":                   "a,b,c",
+		"unicode":                 "中文\tend",
+		"empty":                   "",
+		"last":                    "",
+	}, props)
+}
+
+func TestParseYamlProperties(t *testing.T) {
+	props, err := ParseYamlProperties(`
+defaults: &defaults
+  timeout: 1.0
+server:
+  port: 8080
+  <<: *defaults
+spring:
+  datasource:
+    url: jdbc:mysql://${db.host:localhost}/demo
+    password: ~
+servers:
+  - name: a
+  - b
+---
+server:
+  port: 9090
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"defaults.timeout":           "1.0",
+		"server.port":                "9090",
+		"server.timeout":             "1.0",
+		"spring.datasource.url":      "jdbc:mysql://${db.host:localhost}/demo",
+		"spring.datasource.password": "",
+		"servers[0].name":            "a",
+		"servers[1]":                 "b",
+	}, props)
+	_, err = ParseYamlProperties("a: [")
+	assert.NotNil(t, err)
+	_, err = DecodeProperties("json", "{}")
+	assert.NotNil(t, err)
+}
+
+func TestResolvePlaceholders(t *testing.T) {
+	props := map[string]string{
+		"profile":     "prod",
+		"prod.host":   "10.0.0.1",
+		"url":         "jdbc:mysql://${${profile}.host}:${db.port:3306}/demo",
+		"name":        "${spring.application.name:demo}",
+		"port":        "${server.port}",
+		"server.port": "8080",
+	}
+	env := map[string]string{"SERVER_PORT": "9090"}
+	resolved, err := ResolvePlaceholders(props, func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "jdbc:mysql://10.0.0.1:3306/demo", resolved["url"])
+	assert.Equal(t, "demo", resolved["name"])
+	assert.Equal(t, "9090", resolved["port"])
+
+	resolved, err = ResolvePlaceholders(props, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "8080", resolved["port"])
+
+	_, err = ResolvePlaceholders(map[string]string{"a": "${b}"}, nil)
+	assert.NotNil(t, err)
+	_, err = ResolvePlaceholders(map[string]string{"a": "${b}", "b": "${a}"}, nil)
+	assert.NotNil(t, err)
+}
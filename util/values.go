@@ -0,0 +1,456 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+)
+
+// ValueMode is how the typed getters of Values treat the values not in the canonical form
+type ValueMode int
+
+const (
+	// StrictValues requires the values in the canonical form, such as the numbers for Int, "500ms" for Duration,
+	// "64MiB" for Size and the arrays for lists, the others are returned as error along with the default
+	StrictValues ValueMode = iota
+	// LenientValues additionally accepts the numbers and booleans in strings, the bare numbers as milliseconds of
+	// Duration and bytes of Size, the units of Size in any case, and the comma separated strings as lists. The values
+	// still invalid are logged and the default is returned without error
+	LenientValues
+)
+
+var (
+	sizeUnits = map[string]int64{
+		"B":   1,
+		"KB":  1000,
+		"MB":  1000 * 1000,
+		"GB":  1000 * 1000 * 1000,
+		"TB":  1000 * 1000 * 1000 * 1000,
+		"KiB": 1 << 10,
+		"MiB": 1 << 20,
+		"GiB": 1 << 30,
+		"TiB": 1 << 40,
+	}
+	sizePattern = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*([A-Za-z]*)$`)
+)
+
+// Values is the typed accessor of the values of json, yaml or properties config by key path. The path is the
+// JSONPath selecting one value at most such as $.server.timeout, or the dotted key such as server.timeout which
+// is the same as $.server.timeout. The getters return the default when the value is absent or null
+type Values struct {
+	mode  ValueMode
+	doc   interface{}
+	props map[string]string
+}
+
+// ParseValues decode the json or yaml content as Values, empty content has no value
+func ParseValues(content string, mode ValueMode) (*Values, error) {
+	doc, err := decodeDocument(content)
+	if err != nil {
+		return nil, err
+	}
+	return &Values{mode: mode, doc: doc}, nil
+}
+
+// NewPropertiesValues create Values of the flat properties such as the ones of GetProperties, the values are
+// always strings so they are parsed in both modes, the lists are the indexed keys such as servers[0]
+func NewPropertiesValues(props map[string]string, mode ValueMode) *Values {
+	return &Values{mode: mode, props: props}
+}
+
+// ParseSize parse the byte size with unit such as "64MiB" and "1.5GB", the units are B, KB, MB, GB and TB
+// of powers of 1000, and KiB, MiB, GiB and TiB of powers of 1024
+func ParseSize(s string) (int64, error) {
+	return parseSize(s, false)
+}
+
+func parseSize(s string, lenient bool) (int64, error) {
+	s = strings.TrimSpace(s)
+	match := sizePattern.FindStringSubmatch(s)
+	if match == nil || (!lenient && strings.ContainsAny(s, " \t")) {
+		return 0, errors.Errorf("invalid size %q", s)
+	}
+	unit := match[2]
+	if lenient {
+		unit = canonicalSizeUnit(unit)
+	}
+	multiplier, ok := sizeUnits[unit]
+	if !ok {
+		return 0, errors.Errorf("unknown unit of size %q", s)
+	}
+	number, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid size %q", s)
+	}
+	size := number * float64(multiplier)
+	if size > math.MaxInt64 {
+		return 0, errors.Errorf("size %q overflows", s)
+	}
+	return int64(size), nil
+}
+
+// canonicalSizeUnit return the unit regardless of case, empty unit is bytes
+func canonicalSizeUnit(unit string) string {
+	if unit == "" {
+		return "B"
+	}
+	for canonical := range sizeUnits {
+		if strings.EqualFold(canonical, unit) {
+			return canonical
+		}
+	}
+	return unit
+}
+
+// Has return whether the value of path is present and not null
+func (v *Values) Has(path string) bool {
+	value, ok, err := v.get(path)
+	return err == nil && ok && value != nil
+}
+
+// Get return the raw value of path, which is decoded from json or yaml, or the string of properties
+func (v *Values) Get(path string) (interface{}, bool) {
+	value, ok, err := v.get(path)
+	return value, err == nil && ok && value != nil
+}
+
+// String return the string value, the numbers and booleans are formatted in lenient mode
+func (v *Values) String(path string, def string) (string, error) {
+	result, err := v.convert(path, "string", func(value interface{}) (interface{}, error) {
+		return v.toString(value)
+	})
+	if err != nil || result == nil {
+		return def, err
+	}
+	return result.(string), nil
+}
+
+// Int return the integer value
+func (v *Values) Int(path string, def int64) (int64, error) {
+	result, err := v.convert(path, "integer", func(value interface{}) (interface{}, error) {
+		return v.toInt(value)
+	})
+	if err != nil || result == nil {
+		return def, err
+	}
+	return result.(int64), nil
+}
+
+// Float return the number value
+func (v *Values) Float(path string, def float64) (float64, error) {
+	result, err := v.convert(path, "number", func(value interface{}) (interface{}, error) {
+		return v.toFloat(value)
+	})
+	if err != nil || result == nil {
+		return def, err
+	}
+	return result.(float64), nil
+}
+
+// Bool return the boolean value, yes, no, on and off are accepted as well in lenient mode
+func (v *Values) Bool(path string, def bool) (bool, error) {
+	result, err := v.convert(path, "boolean", func(value interface{}) (interface{}, error) {
+		return v.toBool(value)
+	})
+	if err != nil || result == nil {
+		return def, err
+	}
+	return result.(bool), nil
+}
+
+// Duration return the Go-style duration such as "500ms" and "2h45m"
+func (v *Values) Duration(path string, def time.Duration) (time.Duration, error) {
+	result, err := v.convert(path, "duration", func(value interface{}) (interface{}, error) {
+		return v.toDuration(value)
+	})
+	if err != nil || result == nil {
+		return def, err
+	}
+	return result.(time.Duration), nil
+}
+
+// Size return the byte size such as "64MiB", see ParseSize
+func (v *Values) Size(path string, def int64) (int64, error) {
+	result, err := v.convert(path, "size", func(value interface{}) (interface{}, error) {
+		return v.toSize(value)
+	})
+	if err != nil || result == nil {
+		return def, err
+	}
+	return result.(int64), nil
+}
+
+// Strings return the list of strings
+func (v *Values) Strings(path string, def []string) ([]string, error) {
+	items, err := v.list(path, "string", func(value interface{}) (interface{}, error) {
+		return v.toString(value)
+	})
+	if err != nil || items == nil {
+		return def, err
+	}
+	result := make([]string, len(items))
+	for i, item := range items {
+		result[i] = item.(string)
+	}
+	return result, nil
+}
+
+// Ints return the list of integers
+func (v *Values) Ints(path string, def []int64) ([]int64, error) {
+	items, err := v.list(path, "integer", func(value interface{}) (interface{}, error) {
+		return v.toInt(value)
+	})
+	if err != nil || items == nil {
+		return def, err
+	}
+	result := make([]int64, len(items))
+	for i, item := range items {
+		result[i] = item.(int64)
+	}
+	return result, nil
+}
+
+// Durations return the list of durations
+func (v *Values) Durations(path string, def []time.Duration) ([]time.Duration, error) {
+	items, err := v.list(path, "duration", func(value interface{}) (interface{}, error) {
+		return v.toDuration(value)
+	})
+	if err != nil || items == nil {
+		return def, err
+	}
+	result := make([]time.Duration, len(items))
+	for i, item := range items {
+		result[i] = item.(time.Duration)
+	}
+	return result, nil
+}
+
+// get return the raw value of path, ok is false when it's absent
+func (v *Values) get(path string) (interface{}, bool, error) {
+	if v.props != nil {
+		value, ok := v.props[strings.TrimPrefix(path, "$.")]
+		return value, ok, nil
+	}
+	if !strings.HasPrefix(path, "$") {
+		path = "$." + path
+	}
+	jsonPath, err := CompileJsonPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if !jsonPath.Definite() {
+		return nil, false, errors.Errorf("path %s selects more than one value", path)
+	}
+	value := jsonPath.Select(v.doc)
+	return value, value != nil, nil
+}
+
+// convert the value of path, the result is nil when the value is absent or invalid in lenient mode
+func (v *Values) convert(path, kind string, convert func(value interface{}) (interface{}, error)) (interface{}, error) {
+	value, ok, err := v.get(path)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || value == nil {
+		return nil, nil
+	}
+	result, err := convert(value)
+	if err != nil {
+		return nil, v.invalid(path, kind, err)
+	}
+	return result, nil
+}
+
+// list convert the list of path, the result is nil when the list is absent or invalid in lenient mode
+func (v *Values) list(path, kind string, convert func(value interface{}) (interface{}, error)) ([]interface{}, error) {
+	items, ok, err := v.items(path)
+	if err != nil {
+		return nil, v.invalid(path, "list of "+kind, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	result := make([]interface{}, 0, len(items))
+	for i, item := range items {
+		converted, err := convert(item)
+		if err != nil {
+			return nil, v.invalid(fmt.Sprintf("%s[%d]", path, i), kind, err)
+		}
+		result = append(result, converted)
+	}
+	return result, nil
+}
+
+// items return the items of the list of path, the comma separated string and the scalar are lists in lenient mode
+func (v *Values) items(path string) ([]interface{}, bool, error) {
+	if v.props != nil {
+		key := strings.TrimPrefix(path, "$.")
+		var items []interface{}
+		for i := 0; ; i++ {
+			item, ok := v.props[key+"["+strconv.Itoa(i)+"]"]
+			if !ok {
+				break
+			}
+			items = append(items, item)
+		}
+		if len(items) > 0 {
+			return items, true, nil
+		}
+	}
+	value, ok, err := v.get(path)
+	if err != nil || !ok || value == nil {
+		return nil, false, err
+	}
+	if items, isList := value.([]interface{}); isList {
+		return items, true, nil
+	}
+	if v.mode == StrictValues {
+		return nil, false, errors.Errorf("%v is not a list", value)
+	}
+	if s, isString := value.(string); isString {
+		var items []interface{}
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); len(item) > 0 {
+				items = append(items, item)
+			}
+		}
+		return items, true, nil
+	}
+	return []interface{}{value}, true, nil
+}
+
+// invalid return the error of the invalid value in strict mode, or log it in lenient mode
+func (v *Values) invalid(path, kind string, err error) error {
+	if v.mode == LenientValues {
+		logger.Warnf("[values] %s is not a valid %s, the default is used, err:%v", path, kind, err)
+		return nil
+	}
+	return errors.Wrapf(err, "%s is not a valid %s", path, kind)
+}
+
+// parseStrings return whether the strings are parsed into the other types, it's always true for properties
+func (v *Values) parseStrings() bool {
+	return v.mode == LenientValues || v.props != nil
+}
+
+func (v *Values) toString(value interface{}) (string, error) {
+	switch s := value.(type) {
+	case string:
+		return s, nil
+	case []interface{}, map[string]interface{}:
+		return "", errors.Errorf("%v is not a scalar", value)
+	}
+	if v.mode == StrictValues {
+		return "", errors.Errorf("%v is not a string", value)
+	}
+	return fmt.Sprint(value), nil
+}
+
+func (v *Values) toInt(value interface{}) (int64, error) {
+	switch n := value.(type) {
+	case int:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case float64:
+		if n != math.Trunc(n) || math.Abs(n) > math.MaxInt64 {
+			return 0, errors.Errorf("%v is not an integer", value)
+		}
+		return int64(n), nil
+	case string:
+		if v.parseStrings() {
+			return strconv.ParseInt(strings.TrimSpace(n), 10, 64)
+		}
+	}
+	return 0, errors.Errorf("%v is not an integer", value)
+}
+
+func (v *Values) toFloat(value interface{}) (float64, error) {
+	switch n := value.(type) {
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	case string:
+		if v.parseStrings() {
+			return strconv.ParseFloat(strings.TrimSpace(n), 64)
+		}
+	}
+	return 0, errors.Errorf("%v is not a number", value)
+}
+
+func (v *Values) toBool(value interface{}) (bool, error) {
+	switch b := value.(type) {
+	case bool:
+		return b, nil
+	case string:
+		if !v.parseStrings() {
+			break
+		}
+		if v.mode == LenientValues {
+			switch strings.ToLower(strings.TrimSpace(b)) {
+			case "yes", "on":
+				return true, nil
+			case "no", "off":
+				return false, nil
+			}
+		}
+		return strconv.ParseBool(strings.TrimSpace(b))
+	}
+	return false, errors.Errorf("%v is not a boolean", value)
+}
+
+// toDuration parse the duration, the bare numbers are milliseconds in lenient mode
+func (v *Values) toDuration(value interface{}) (time.Duration, error) {
+	if s, ok := value.(string); ok {
+		s = strings.TrimSpace(s)
+		if v.mode == LenientValues {
+			if ms, err := strconv.ParseFloat(s, 64); err == nil {
+				return time.Duration(ms * float64(time.Millisecond)), nil
+			}
+		}
+		return time.ParseDuration(s)
+	}
+	if v.mode == LenientValues {
+		if ms, err := v.toFloat(value); err == nil {
+			return time.Duration(ms * float64(time.Millisecond)), nil
+		}
+	}
+	return 0, errors.Errorf("%v is not a duration", value)
+}
+
+// toSize parse the byte size, the bare numbers are bytes in lenient mode
+func (v *Values) toSize(value interface{}) (int64, error) {
+	if s, ok := value.(string); ok {
+		return parseSize(s, v.mode == LenientValues)
+	}
+	if v.mode == LenientValues {
+		return v.toInt(value)
+	}
+	return 0, errors.Errorf("%v is not a size", value)
+}
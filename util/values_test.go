@@ -0,0 +1,159 @@
+/*
+ * Copyright 1999-2020 Alibaba Group Holding Ltd.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSize(t *testing.T) {
+	cases := map[string]int64{
+		"0B":     0,
+		"512B":   512,
+		"64KB":   64 * 1000,
+		"64KiB":  64 * 1024,
+		"64MiB":  64 << 20,
+		"1.5GB":  1500 * 1000 * 1000,
+		"2TiB":   2 << 40,
+		" 1GiB ": 1 << 30,
+	}
+	for s, expected := range cases {
+		size, err := ParseSize(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, expected, size, s)
+	}
+	for _, s := range []string{"", "0", "64mib", "64 MiB", "-1B", "64XB", "MiB"} {
+		_, err := ParseSize(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestValues_Strict(t *testing.T) {
+	values, err := ParseValues(`{"server":{"timeout":"500ms","maxBody":"64MiB","port":8848,"ratio":0.5,"enabled":true,
+"hosts":["a","b"],"ports":[80,443],"retries":["1s","2s"],"portText":"8848","bareTimeout":500}}`, StrictValues)
+	assert.Nil(t, err)
+
+	timeout, err := values.Duration("server.timeout", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 500*time.Millisecond, timeout)
+	maxBody, err := values.Size("$.server.maxBody", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(64<<20), maxBody)
+	port, err := values.Int("server.port", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(8848), port)
+	ratio, err := values.Float("server.ratio", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0.5, ratio)
+	enabled, err := values.Bool("server.enabled", false)
+	assert.Nil(t, err)
+	assert.True(t, enabled)
+	hosts, err := values.Strings("server.hosts", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, hosts)
+	ports, err := values.Ints("server.ports", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{80, 443}, ports)
+	retries, err := values.Durations("server.retries", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, retries)
+	host, err := values.String("server.hosts[1]", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "b", host)
+
+	missing, err := values.Duration("server.missing", 3*time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 3*time.Second, missing)
+	assert.False(t, values.Has("server.missing"))
+
+	port, err = values.Int("server.portText", 1)
+	assert.NotNil(t, err)
+	assert.Equal(t, int64(1), port)
+	timeout, err = values.Duration("server.bareTimeout", time.Second)
+	assert.NotNil(t, err)
+	assert.Equal(t, time.Second, timeout)
+	_, err = values.Strings("server.timeout", nil)
+	assert.NotNil(t, err)
+	_, err = values.Int("server.ratio", 0)
+	assert.NotNil(t, err)
+	_, err = values.Int("$..port", 0)
+	assert.NotNil(t, err)
+}
+
+func TestValues_Lenient(t *testing.T) {
+	values, err := ParseValues(`server:
+  timeout: 500
+  maxBody: 64 mib
+  port: "8848"
+  enabled: "on"
+  hosts: a, b
+  retries: [1s, 2000]
+  invalid: abc
+`, LenientValues)
+	assert.Nil(t, err)
+
+	timeout, err := values.Duration("server.timeout", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 500*time.Millisecond, timeout)
+	maxBody, err := values.Size("server.maxBody", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(64<<20), maxBody)
+	port, err := values.Int("server.port", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(8848), port)
+	enabled, err := values.Bool("server.enabled", false)
+	assert.Nil(t, err)
+	assert.True(t, enabled)
+	hosts, err := values.Strings("server.hosts", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, hosts)
+	retries, err := values.Durations("server.retries", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, retries)
+	timeoutText, err := values.String("server.timeout", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "500", timeoutText)
+
+	invalid, err := values.Duration("server.invalid", time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Second, invalid)
+}
+
+func TestValues_Properties(t *testing.T) {
+	values := NewPropertiesValues(map[string]string{
+		"server.timeout":  "2h",
+		"server.port":     "8848",
+		"server.hosts[0]": "a",
+		"server.hosts[1]": "b",
+		"server.maxBody":  "64mib",
+	}, StrictValues)
+
+	timeout, err := values.Duration("server.timeout", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 2*time.Hour, timeout)
+	port, err := values.Int("$.server.port", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(8848), port)
+	hosts, err := values.Strings("server.hosts", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, hosts)
+	_, err = values.Size("server.maxBody", 0)
+	assert.NotNil(t, err)
+}
@@ -22,3 +22,14 @@ type NacosClientParam struct {
 	ClientConfig  *constant.ClientConfig  // optional
 	ServerConfigs []constant.ServerConfig // optional
 }
+
+type MigrationParam struct {
+	CutoverKeys  []string                                    //optional,the keys read from the new cluster initially, group@@dataId of configs and group@@serviceName of services
+	OnWriteError func(cluster string, key string, err error) //optional,callback after the write to the secondary cluster fails, the write to primary cluster has succeeded
+}
+
+type CompatParam struct {
+	NamespaceId string //optional,override the namespace of clients
+	Group       string //optional,the group of configs and services, default is DEFAULT_GROUP
+	PageSize    int    //optional,the page size to list the configs by prefix, default is 100
+}
@@ -16,28 +16,212 @@
 
 package vo
 
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"os"
+	"text/template"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
+
 type Listener func(namespace, group, dataId, data string)
 
+type DeleteListener func(namespace, group, dataId, lastContent string)
+
+// ConfigPriority is the priority of config operations
+type ConfigPriority int
+
+const (
+	ConfigPriorityNormal ConfigPriority = iota
+	// ConfigPriorityHigh is for the critical reads such as the configs required on startup, they wait for the token
+	// of the client side rate limiter of queries ahead of the normal ones instead of being refused
+	ConfigPriorityHigh
+)
+
 type ConfigParam struct {
-	DataId           string `param:"dataId"`  //required
-	Group            string `param:"group"`   //required
-	Content          string `param:"content"` //required
-	Tag              string `param:"tag"`
-	AppName          string `param:"appName"`
-	BetaIps          string `param:"betaIps"`
-	CasMd5           string `param:"casMd5"`
-	Type             string `param:"type"`
-	SrcUser          string `param:"srcUser"`
-	EncryptedDataKey string `param:"encryptedDataKey"`
+	DataId           string         `param:"dataId"`  //required
+	Group            string         `param:"group"`   //required
+	Content          string         `param:"content"` //required
+	Tag              string         `param:"tag"`
+	AppName          string         `param:"appName"`
+	BetaIps          string         `param:"betaIps"`
+	CasMd5           string         `param:"casMd5"`
+	Type             string         `param:"type"`
+	SrcUser          string         `param:"srcUser"`
+	EncryptedDataKey string         `param:"encryptedDataKey"`
+	Desc             string         `param:"desc"`
+	Use              string         `param:"use"`
+	Effect           string         `param:"effect"`
+	Schema           string         `param:"schema"`
+	ConfigTags       string         `param:"config_tags"` //optional,comma separated
+	IdempotencyKey   string         `param:"-"`           //optional,dedupe retried publishes with the same key
+	NamespaceId      string         `param:"-"`           //optional,override the namespace of client on get, publish, delete and listen
+	Stage            bool           `param:"-"`           //optional,publish to the staging dataId, it takes effect after Promote
+	TimeoutMs        uint64         `param:"-"`           //optional,the timeout of get, publish and delete, default is ClientConfig.TimeoutMs for get and 3000 for the others
+	Priority         ConfigPriority `param:"-"`           //optional,the priority of get, default is ConfigPriorityNormal
 	OnChange         func(namespace, group, dataId, data string)
+	// OnChangeErr is called instead of OnChange when it's set, the errors returned count as the failures of listener
+	// which open its breaker when ListenerBreakerCfg of client is set
+	OnChangeErr func(namespace, group, dataId, data string) error
+	// OnDelete is called with the last known content when the listened config is removed, OnChange is called with
+	// empty content instead when it's not set. Listening a config which does not exist yet is allowed, OnChange is
+	// called once it's created
+	OnDelete func(namespace, group, dataId, lastContent string)
+	// OnBinaryChange is called with the decoded data of binary config when OnChange is not set
+	OnBinaryChange func(namespace, group, dataId string, data []byte)
+	// OnScheduleEvent is called with the audit events of the publication scheduled by PublishConfigAt, optional
+	OnScheduleEvent func(event model.ScheduledPublishEvent)
+	// DeliverLatestOnly drop intermediate changes while OnChange is still processing,
+	// only the newest content is delivered next
+	DeliverLatestOnly bool
+	// FallbackGroups are tried in order by GetConfig when the config doesn't exist in Group, such as the team default
+	// and then the global default. ListenConfig listens the whole chain and calls OnChange with the group in effect
+	FallbackGroups []string
+	// OnChangeAck is called instead of OnChange when it's set, the change is delivered again every AckTimeout until Ack
+	// of the delivery is called, up to AckMaxAttempts times. The md5 of the unacknowledged change is persisted in the
+	// cache dir, and the change is delivered again after the client restarts and listens the config if the config is
+	// still the same. A newer change replaces the unacknowledged one
+	OnChangeAck    func(delivery *model.ConfigDelivery)
+	AckTimeout     time.Duration //optional,the interval of delivering the unacknowledged change again, default is 10s
+	AckMaxAttempts int           //optional,the times a change is delivered at most, default is 10, negative means no limit
+	AckId          string        //optional,identify the OnChangeAck listener among the ones of the config across restarts, default is the order it's listened in
 }
 
 type SearchConfigParam struct {
-	Search   string `param:"search"`
-	DataId   string `param:"dataId"`
-	Group    string `param:"group"`
-	Tag      string `param:"tag"`
-	AppName  string `param:"appName"`
-	PageNo   int    `param:"pageNo"`
-	PageSize int    `param:"pageSize"`
+	Search      string `param:"search"`
+	DataId      string `param:"dataId"`
+	Group       string `param:"group"`
+	Tag         string `param:"tag"`
+	AppName     string `param:"appName"`
+	ConfigTags  string `param:"config_tags"`   //optional,comma separated
+	Content     string `param:"config_detail"` //optional,keyword of content
+	NamespaceId string `param:"-"`             //optional,override the namespace of client
+	PageNo      int    `param:"pageNo"`
+	PageSize    int    `param:"pageSize"`
+	MaxRetries  int    `param:"-"` //optional,the times SearchConfigIterator backs off and retries when server throttles, default is 5
+}
+
+type ListConfigKeysParam struct {
+	NamespaceId string //optional,override the namespace of client
+	Group       string //optional,list configs of all groups when empty
+	PageNo      int    //optional,default is 1
+	PageSize    int    //optional,default is 10
+}
+
+type CertParam struct {
+	CertDataId  string //required,the dataId of PEM encoded certificate chain
+	KeyDataId   string //required,the dataId of PEM encoded private key
+	CaDataId    string //optional,the dataId of PEM encoded CA bundle used to verify peers
+	Group       string //optional,default is DEFAULT_GROUP
+	NamespaceId string //optional,override the namespace of client
+	// OnReload is called after the certificate is reloaded, err is not nil when the new content is invalid
+	// and the previous certificate is kept
+	OnReload func(cert *tls.Certificate, err error)
+}
+
+type ElectionParam struct {
+	DataId        string        //required,the dataId of lock config
+	Group         string        //optional,default is DEFAULT_GROUP
+	NamespaceId   string        //optional,override the namespace of client
+	Identity      string        //optional,the identity of candidate, default is hostname with a random suffix
+	TTL           time.Duration //optional,the leadership is lost when it's not renewed within ttl, default is 15s
+	RenewInterval time.Duration //optional,the interval to renew leadership and observe the leader, default is 1/3 ttl
+	// OnStartedLeading is called in a new goroutine when the leadership is acquired, ctx is done once it's lost
+	OnStartedLeading func(ctx context.Context)
+	// OnStoppedLeading is called when the leadership is lost or released
+	OnStoppedLeading func()
+	// OnNewLeader is called when the observed leader changed, identity is empty when there is no leader
+	OnNewLeader func(identity string)
+}
+
+type ReconcileParam struct {
+	Group       string //optional,the group of desired configs, default is DEFAULT_GROUP
+	NamespaceId string //optional,override the namespace of client
+	Type        string //optional,the type of created and updated configs, such as yaml
+	Prune       bool   //optional,delete the configs on server which are not desired, default is false
+	DryRun      bool   //optional,only plan the actions without applying them
+	PageSize    int    //optional,the page size to list the configs on server, default is 100
+	// Confirm is called before each action is applied, the action is skipped when it returns false, optional
+	Confirm func(action model.ReconcileAction) bool
+}
+
+type RestoreParam struct {
+	NamespaceId string    //optional,the namespace restored to, default is the namespace of the backup
+	Prune       bool      //optional,delete the configs on server which are not in the backup, default is false
+	DryRun      bool      //optional,only plan the actions without applying them
+	Diff        io.Writer //optional,the diff of each planned action against server is written to it
+	ResumeAfter string    //optional,the Last of the result of an interrupted restore, the entries up to it are skipped
+	// Confirm is called before each action is applied, the action is skipped when it returns false, optional
+	Confirm func(action model.ReconcileAction) bool
+}
+
+type DirSyncParam struct {
+	Dir            string               //required,the directory watched, the file name is the dataId
+	Group          string               //optional,default is DEFAULT_GROUP
+	NamespaceId    string               //optional,override the namespace of client
+	Interval       time.Duration        //optional,the interval to scan the directory, default is 1s
+	Debounce       time.Duration        //optional,the file is published after it's not changed within debounce, default is 500ms
+	ConflictPolicy model.ConflictPolicy //optional,how to deal with the config changed by others since last sync, default is overwrite
+	DeleteOnRemove bool                 //optional,delete the config when the file is removed, default is false
+	// OnSync is called after each file is synced, err is not nil when it fails or conflicts, optional
+	OnSync func(action model.ReconcileAction, err error)
+}
+
+type FileExportParam struct {
+	Configs        []ConfigParam //required,the configs exported, DataId and Group require, NamespaceId optional
+	PathTemplate   string        //required,the path of file, {dataId}, {group} and {namespaceId} are replaced, such as /etc/nginx/conf.d/{dataId}
+	FileMode       os.FileMode   //optional,the mode of file, default is 0644
+	PidFile        string        //optional,the pid file of process which the signal is sent to after a file changed
+	Signal         os.Signal     //optional,the signal sent to the process of PidFile, default is SIGHUP
+	Command        []string      //optional,the hook command executed after a file changed, such as nginx -s reload
+	CommandTimeout time.Duration //optional,the timeout of hook command, default is 30s
+	// OnExport is called after a file is exported and the process is notified, err is not nil when it fails, optional
+	OnExport func(path string, err error)
+}
+
+type TemplateParam struct {
+	Configs        []ConfigParam    //required,the configs rendered by template, DataId and Group require, NamespaceId optional
+	Template       string           //required,the text/template rendered, the config is read by {{config "dataId"}} or {{config "dataId" "group"}}
+	Dest           string           //required,the destination file of rendered content
+	FileMode       os.FileMode      //optional,the mode of destination file, default is 0644
+	CheckCommand   []string         //optional,the command to check the rendered content before it's placed, {{.src}} is replaced by the staged file
+	ReloadCommand  []string         //optional,the command executed after the destination file changed
+	CommandTimeout time.Duration    //optional,the timeout of commands, default is 30s
+	Funcs          template.FuncMap //optional,the additional functions of template
+	// OnRender is called after the template is rendered and reloaded, err is not nil when it fails, optional
+	OnRender func(dest string, err error)
+}
+
+type WebhookParam struct {
+	Configs        []ConfigParam     //required,the configs of which the changes are forwarded, DataId and Group require, NamespaceId optional
+	Urls           []string          //required,the webhooks the change events are posted to
+	Secret         string            //optional,the key of the HMAC-SHA256 signature of body, sent in header X-Nacos-Signature
+	IncludeContent bool              //optional,include the old and new content in the change events
+	Headers        map[string]string //optional,the additional headers of requests, such as the token of chat-ops
+	MaxRetries     int               //optional,the max retries of failed delivery, default is 3
+	RetryBackoff   time.Duration     //optional,the wait before the first retry, it's doubled on each retry, default is 1s
+	Timeout        time.Duration     //optional,the timeout of each request, default is 5s
+	// OnDeliver is called after the event is delivered to the url or given up, err is not nil when it fails, optional
+	OnDeliver func(url string, event model.ConfigWebhookEvent, err error)
+}
+
+type PropertiesParam struct {
+	Configs             []ConfigParam //required,the properties or yaml configs merged in order, the latter overrides the former
+	ResolvePlaceholders bool          //optional,resolve the Spring-style placeholders such as ${key:default} against the merged properties
+	LookupEnv           bool          //optional,the environment variables override the properties when resolving placeholders
+}
+
+type SpringConfigParam struct {
+	Application         string        //required,spring.application.name, the prefix of the dataIds of application configs
+	Profiles            []string      //optional,spring.profiles.active, the latter overrides the former
+	FileExtension       string        //optional,the extension of the dataIds of application configs, properties or yaml, default is properties
+	Group               string        //optional,the group of application configs, default is DEFAULT_GROUP
+	NamespaceId         string        //optional,override the namespace of client
+	SharedConfigs       []ConfigParam //optional,the configs of the lowest precedence, the latter overrides the former
+	ExtensionConfigs    []ConfigParam //optional,the configs overriding the shared configs, the latter overrides the former
+	ResolvePlaceholders bool          //optional,resolve the Spring-style placeholders such as ${key:default} against the merged properties
+	LookupEnv           bool          //optional,the environment variables override the properties when resolving placeholders
 }
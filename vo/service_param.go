@@ -16,10 +16,15 @@
 
 package vo
 
-import "github.com/nacos-group/nacos-sdk-go/v2/model"
+import (
+	"context"
+	"time"
+
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+)
 
 type RegisterInstanceParam struct {
-	Ip          string            `param:"ip"`          //required
+	Ip          string            `param:"ip"`          //optional,default is the detected ip of client
 	Port        uint64            `param:"port"`        //required
 	Weight      float64           `param:"weight"`      //required,it must be lager than 0
 	Enable      bool              `param:"enabled"`     //required,the instance can be access or not
@@ -27,27 +32,55 @@ type RegisterInstanceParam struct {
 	Metadata    map[string]string `param:"metadata"`    //optional
 	ClusterName string            `param:"clusterName"` //optional
 	ServiceName string            `param:"serviceName"` //required
-	GroupName   string            `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	GroupName   string            `param:"groupName"`   //optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
 	Ephemeral   bool              `param:"ephemeral"`   //optional
+	// MetadataProvider is polled every MetadataRefreshInterval after the instance is registered, the returned metadata
+	// overrides Metadata and the instance is updated when it changed, such as current load or build version, optional
+	MetadataProvider        func() map[string]string
+	MetadataRefreshInterval time.Duration //optional,default is 10s
+}
+
+type RegisterInstanceWithTTLParam struct {
+	RegisterInstanceParam
+	TTL         time.Duration                                // required,the instance is deregistered when the lease is not renewed within ttl
+	AutoRenew   bool                                         // optional,renew the lease by sdk every 1/3 ttl, otherwise the caller must renew it
+	OnLeaseLost func(param RegisterInstanceParam, err error) // optional,called once when the lease is expired or the connection is lost
+}
+
+// ConfigStore is the part of config client keeping the records written with compare-and-swap, such as the holder
+// and fencing token of distributed mutex
+type ConfigStore interface {
+	GetConfig(param ConfigParam) (string, error)
+	PublishConfig(param ConfigParam) (bool, error)
+}
+
+type MutexParam struct {
+	Name          string                                    // required,the name of mutex, it's the serviceName of the instances registered by contenders
+	Store         ConfigStore                               // required,the config client keeping the record of holder and fencing token, its dataId is Name with .mutex suffix
+	GroupName     string                                    // optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
+	Identity      string                                    // optional,the identity of contender, default is hostname with a random suffix
+	TTL           time.Duration                             // optional,the ttl of the lease of registration, default is 15s
+	RetryInterval time.Duration                             // optional,the interval to check the holder, default is 1s
+	OnLost        func(name string, token int64, err error) // optional,called once when the held mutex is lost
 }
 
 type BatchRegisterInstanceParam struct {
 	ServiceName string                  `param:"serviceName"` //required
-	GroupName   string                  `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	GroupName   string                  `param:"groupName"`   //optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
 	Instances   []RegisterInstanceParam //required
 }
 
 type DeregisterInstanceParam struct {
-	Ip          string `param:"ip"`          //required
+	Ip          string `param:"ip"`          //optional,default is the detected ip of client
 	Port        uint64 `param:"port"`        //required
 	Cluster     string `param:"cluster"`     //optional
 	ServiceName string `param:"serviceName"` //required
-	GroupName   string `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	GroupName   string `param:"groupName"`   //optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
 	Ephemeral   bool   `param:"ephemeral"`   //optional
 }
 
 type UpdateInstanceParam struct {
-	Ip          string            `param:"ip"`          //required
+	Ip          string            `param:"ip"`          //optional,default is the detected ip of client
 	Port        uint64            `param:"port"`        //required
 	Weight      float64           `param:"weight"`      //required,it must be lager than 0
 	Enable      bool              `param:"enabled"`     //required,the instance can be access or not
@@ -55,19 +88,19 @@ type UpdateInstanceParam struct {
 	Metadata    map[string]string `param:"metadata"`    //optional
 	ClusterName string            `param:"clusterName"` //optional
 	ServiceName string            `param:"serviceName"` //required
-	GroupName   string            `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	GroupName   string            `param:"groupName"`   //optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
 	Ephemeral   bool              `param:"ephemeral"`   //optional
 }
 
 type GetServiceParam struct {
 	Clusters    []string `param:"clusters"`    //optional
 	ServiceName string   `param:"serviceName"` //required
-	GroupName   string   `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	GroupName   string   `param:"groupName"`   //optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
 }
 
 type GetAllServiceInfoParam struct {
 	NameSpace string `param:"nameSpace"` //optional, namespaceId default:public
-	GroupName string `param:"groupName"` //optional,default:DEFAULT_GROUP
+	GroupName string `param:"groupName"` //optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
 	PageNo    uint32 `param:"pageNo"`    //optional,default:1
 	PageSize  uint32 `param:"pageSize"`  //optional,default:10
 }
@@ -75,25 +108,63 @@ type GetAllServiceInfoParam struct {
 type SubscribeParam struct {
 	ServiceName       string                                     `param:"serviceName"` //required
 	Clusters          []string                                   `param:"clusters"`    //optional
-	GroupName         string                                     `param:"groupName"`   //optional,default:DEFAULT_GROUP
-	SubscribeCallback func(services []model.Instance, err error) //required
+	GroupName         string                                     `param:"groupName"`   //optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
+	SubscribeCallback func(services []model.Instance, err error) //required unless ServiceCallback is set
+	// optional, called with the whole service including its metadata, protectThreshold, clusters and reachProtectionThreshold,
+	// it's called as well when only these service-level settings change
+	ServiceCallback func(service model.Service, err error)
+	Labels          map[string]string `param:"-"` //optional,request labels used by the router
+	ExcludeSelf     bool              `param:"-"` //optional,exclude the instances registered by this client, such as for the peer-to-peer services
 }
 
-type SelectAllInstancesParam struct {
+type WatchParam struct {
+	ServiceName string   `param:"serviceName"` //required
 	Clusters    []string `param:"clusters"`    //optional
+	GroupName   string   `param:"groupName"`   //optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
+}
+
+type WaitForServiceParam struct {
 	ServiceName string   `param:"serviceName"` //required
-	GroupName   string   `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	Clusters    []string `param:"clusters"`    //optional
+	GroupName   string   `param:"groupName"`   //optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
+	MinHealthy  int      `param:"minHealthy"`  //optional,the number of healthy instances waited for,default:1
+}
+
+type EventForwardParam struct {
+	Services     []WatchParam //required,the services of which the instance events are forwarded
+	SkipSnapshot bool         //optional,don't forward the instances of the initial snapshot as up events
+	// OnError is called when the sink fails to send the event, optional
+	OnError func(event model.InstanceEvent, err error)
+}
+
+type SelectAllInstancesParam struct {
+	Clusters    []string          `param:"clusters"`    //optional
+	ServiceName string            `param:"serviceName"` //required
+	GroupName   string            `param:"groupName"`   //optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
+	Labels      map[string]string `param:"-"`           //optional,request labels used by the router
+	Selector    string            `param:"selector"`    //optional,label expression on instance metadata such as "version=v2,zone in (a,b)"
+	SubsetSize  int               `param:"-"`           //optional,choose a stable subset of instances of the size for this client, default 0 means all, see ClientConfig.SubsetKey
+	ExcludeSelf bool              `param:"-"`           //optional,exclude the instances registered by this client
 }
 
 type SelectInstancesParam struct {
-	Clusters    []string `param:"clusters"`    //optional
-	ServiceName string   `param:"serviceName"` //required
-	GroupName   string   `param:"groupName"`   //optional,default:DEFAULT_GROUP
-	HealthyOnly bool     `param:"healthyOnly"` //optional,value = true return only healthy instance, value = false return only unHealthy instance
+	Clusters    []string          `param:"clusters"`    //optional
+	ServiceName string            `param:"serviceName"` //required
+	GroupName   string            `param:"groupName"`   //optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
+	HealthyOnly bool              `param:"healthyOnly"` //optional,value = true return only healthy instance, value = false return only unHealthy instance
+	Labels      map[string]string `param:"-"`           //optional,request labels used by the router
+	Selector    string            `param:"selector"`    //optional,label expression on instance metadata such as "version=v2,zone in (a,b)"
+	SubsetSize  int               `param:"-"`           //optional,choose a stable subset of instances of the size for this client, default 0 means all, see ClientConfig.SubsetKey
+	ExcludeSelf bool              `param:"-"`           //optional,exclude the instances registered by this client
 }
 
 type SelectOneHealthInstanceParam struct {
-	Clusters    []string `param:"clusters"`    //optional
-	ServiceName string   `param:"serviceName"` //required
-	GroupName   string   `param:"groupName"`   //optional,default:DEFAULT_GROUP
+	Clusters    []string          `param:"clusters"`    //optional
+	ServiceName string            `param:"serviceName"` //required
+	GroupName   string            `param:"groupName"`   //optional,default:DefaultServiceGroup of ClientConfig or DEFAULT_GROUP
+	Balancer    string            `param:"-"`           //optional,the name of registered balancer
+	Context     context.Context   `param:"-"`           //optional,passed to the balancer
+	Labels      map[string]string `param:"-"`           //optional,request labels used by the router
+	SubsetSize  int               `param:"-"`           //optional,choose a stable subset of instances of the size for this client, default 0 means all, see ClientConfig.SubsetKey
+	ExcludeSelf bool              `param:"-"`           //optional,exclude the instances registered by this client
 }